@@ -156,7 +156,7 @@ func (MyExecutor) HttpRequest(ctx context.Context, a *coreauth.Auth, req *http.R
 }
 
 func (MyExecutor) CountTokens(context.Context, *coreauth.Auth, clipexec.Request, clipexec.Options) (clipexec.Response, error) {
-	return clipexec.Response{}, errors.New("count tokens not implemented")
+	return clipexec.Response{}, clipexec.ErrCountTokensUnsupported
 }
 
 func (MyExecutor) ExecuteStream(ctx context.Context, a *coreauth.Auth, req clipexec.Request, opts clipexec.Options) (*clipexec.StreamResult, error) {
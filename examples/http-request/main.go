@@ -67,7 +67,7 @@ func (EchoExecutor) Refresh(context.Context, *coreauth.Auth) (*coreauth.Auth, er
 }
 
 func (EchoExecutor) CountTokens(context.Context, *coreauth.Auth, clipexec.Request, clipexec.Options) (clipexec.Response, error) {
-	return clipexec.Response{}, errors.New("echo executor: CountTokens not implemented")
+	return clipexec.Response{}, fmt.Errorf("echo executor: %w", clipexec.ErrCountTokensUnsupported)
 }
 
 func main() {
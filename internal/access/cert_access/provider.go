@@ -0,0 +1,42 @@
+package certaccess
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+)
+
+// Register makes the client-certificate provider available to the access manager when
+// tls.client-cert-auth is enabled with a client CA, and removes it otherwise.
+func Register(cfg config.TLSConfig) {
+	if !cfg.Enable || !cfg.ClientCertAuth || strings.TrimSpace(cfg.ClientCA) == "" {
+		sdkaccess.UnregisterProvider(sdkaccess.AccessProviderTypeClientCert)
+		return
+	}
+	sdkaccess.RegisterProvider(sdkaccess.AccessProviderTypeClientCert, &provider{})
+}
+
+// provider authenticates callers by the verified TLS client certificate. The handshake has
+// already checked the certificate against tls.client-ca, so any identity it carries is trusted.
+type provider struct{}
+
+func (p *provider) Identifier() string {
+	return sdkaccess.AccessProviderTypeClientCert
+}
+
+func (p *provider) Authenticate(_ context.Context, r *http.Request) (*sdkaccess.Result, *sdkaccess.AuthError) {
+	identity := sdkaccess.ClientCertIdentity(r)
+	if identity == "" {
+		return nil, sdkaccess.NewNoCredentialsError()
+	}
+	return &sdkaccess.Result{
+		Provider:  p.Identifier(),
+		Principal: identity,
+		Metadata: map[string]string{
+			"source": "client-cert",
+		},
+	}, nil
+}
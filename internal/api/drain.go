@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DrainStats summarizes how the requests in flight at shutdown ended.
+type DrainStats struct {
+	// InFlightAtSignal is the number of requests running when shutdown began.
+	InFlightAtSignal int64 `json:"in_flight_at_signal"`
+	// Completed counts those requests that finished within the grace period.
+	Completed int64 `json:"completed"`
+	// Aborted counts those requests that were cancelled or still running when the grace
+	// period ended.
+	Aborted int64 `json:"aborted"`
+}
+
+// drainTracker counts in-flight requests and classifies the ones caught by shutdown.
+// The zero value is ready to use.
+type drainTracker struct {
+	mu       sync.Mutex
+	inflight int64
+	draining bool
+	finished bool
+	// pending is the number of requests from before the signal that are still running.
+	pending int64
+	stats   DrainStats
+}
+
+// track registers a starting request. The returned function must be called once the
+// request ends, reporting whether it was cancelled.
+func (d *drainTracker) track() func(aborted bool) {
+	d.mu.Lock()
+	d.inflight++
+	caught := !d.draining
+	d.mu.Unlock()
+
+	var once sync.Once
+	return func(aborted bool) {
+		once.Do(func() {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			d.inflight--
+			if !d.draining || !caught || d.finished {
+				return
+			}
+			d.pending--
+			if aborted {
+				d.stats.Aborted++
+			} else {
+				d.stats.Completed++
+			}
+		})
+	}
+}
+
+// begin marks the start of shutdown and snapshots the requests in flight.
+func (d *drainTracker) begin() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return
+	}
+	d.draining = true
+	d.pending = d.inflight
+	d.stats.InFlightAtSignal = d.inflight
+}
+
+// finish closes the drain window; requests still running count as aborted.
+func (d *drainTracker) finish() DrainStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining && !d.finished {
+		d.finished = true
+		d.stats.Aborted += d.pending
+		d.pending = 0
+	}
+	return d.stats
+}
+
+// snapshot returns whether shutdown began, the requests in flight and the drain stats.
+func (d *drainTracker) snapshot() (bool, int64, DrainStats) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.draining, d.inflight, d.stats
+}
+
+// middleware tracks every request passing through the engine.
+func (d *drainTracker) middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		done := d.track()
+		defer func() { done(c.Request.Context().Err() != nil) }()
+		c.Next()
+	}
+}
+
+// healthHandler reports "ok" while serving and "draining" with the drain stats, as a 503,
+// once shutdown began.
+func (d *drainTracker) healthHandler(c *gin.Context) {
+	draining, inflight, stats := d.snapshot()
+	if draining {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "draining", "in_flight": inflight, "drain": stats})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "in_flight": inflight})
+}
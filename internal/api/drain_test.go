@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDrainTrackerSummary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var tracker drainTracker
+
+	finishedEarly := tracker.track()
+	finishedEarly(false) // done before the signal, not part of the drain
+	completed := tracker.track()
+	cancelled := tracker.track()
+	_ = tracker.track() // still running when the grace period ends
+
+	tracker.begin()
+	lateArrival := tracker.track() // started after the signal, not counted
+	completed(false)
+	cancelled(true)
+	lateArrival(false)
+
+	stats := tracker.finish()
+	want := DrainStats{InFlightAtSignal: 3, Completed: 1, Aborted: 2}
+	if stats != want {
+		t.Fatalf("drain stats = %+v, want %+v", stats, want)
+	}
+
+	engine := gin.New()
+	engine.GET("/healthz", tracker.healthHandler)
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("health status = %d, want 503 while draining", rec.Code)
+	}
+	var body struct {
+		Status string     `json:"status"`
+		Drain  DrainStats `json:"drain"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode health: %v", err)
+	}
+	if body.Status != "draining" || body.Drain != want {
+		t.Fatalf("health body = %s", rec.Body.String())
+	}
+}
+
+func TestServerStopReportsDrainingBeforeClosingListeners(t *testing.T) {
+	server := newTestServer(t)
+	server.cfg.ShutdownDrainDelaySeconds = 1
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- server.Stop(context.Background()) }()
+
+	deadline := time.Now().Add(900 * time.Millisecond)
+	for {
+		rec := httptest.NewRecorder()
+		server.engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		if rec.Code == http.StatusServiceUnavailable {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("health status = %d during the drain delay, want 503", rec.Code)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case err := <-stopped:
+		t.Fatalf("Stop returned before the drain delay passed: %v", err)
+	default:
+	}
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Fatalf("Stop: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop did not return after the drain delay")
+	}
+}
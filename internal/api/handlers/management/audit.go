@@ -0,0 +1,125 @@
+package management
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// Context keys describing the credential that authenticated a management request.
+const (
+	managementCallerContextKey = "managementCaller"
+	managementKeyIDContextKey  = "managementKeyID"
+)
+
+// Management credential kinds recorded as the audit caller.
+const (
+	auditCallerLocalPassword = "local-password"
+	auditCallerEnvSecret     = "env-secret"
+	auditCallerSecretKey     = "secret-key"
+	auditCallerAccessToken   = "access-token"
+)
+
+// AuditEvent is one JSONL record describing a mutating management call.
+type AuditEvent struct {
+	Time     time.Time `json:"time"`
+	Caller   string    `json:"caller"`
+	KeyID    string    `json:"key_id,omitempty"`
+	Role     string    `json:"role,omitempty"`
+	ClientIP string    `json:"client_ip,omitempty"`
+	Method   string    `json:"method"`
+	Endpoint string    `json:"endpoint"`
+	Action   string    `json:"action"`
+	Target   string    `json:"target,omitempty"`
+	Status   int       `json:"status"`
+}
+
+// auditLog appends audit events as JSON lines to a file, reopening it when the
+// configured path changes.
+type auditLog struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+func (a *auditLog) write(path string, event AuditEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.file == nil || a.path != path {
+		if a.file != nil {
+			_ = a.file.Close()
+			a.file = nil
+		}
+		if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return fmt.Errorf("create audit log directory: %w", err)
+		}
+		file, errOpen := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if errOpen != nil {
+			return fmt.Errorf("open audit log: %w", errOpen)
+		}
+		a.file = file
+		a.path = path
+	}
+	_, err = a.file.Write(append(line, '\n'))
+	return err
+}
+
+// managementKeyID returns a short fingerprint identifying a management key without
+// revealing it.
+func managementKeyID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// setManagementCaller records the authenticated credential for later audit records.
+func setManagementCaller(c *gin.Context, caller, key, role string) {
+	c.Set(managementCallerContextKey, caller)
+	c.Set(managementKeyIDContextKey, managementKeyID(key))
+	c.Set(managementRoleContextKey, role)
+}
+
+// audit records a mutating management call once the handler has written its response.
+// It is a no-op unless remote-management.audit-log is configured.
+func (h *Handler) audit(c *gin.Context, action, target string) {
+	if h == nil || h.cfg == nil || c == nil {
+		return
+	}
+	path := strings.TrimSpace(h.cfg.RemoteManagement.AuditLog)
+	if path == "" {
+		return
+	}
+	event := AuditEvent{
+		Time:     time.Now().UTC(),
+		Caller:   c.GetString(managementCallerContextKey),
+		KeyID:    c.GetString(managementKeyIDContextKey),
+		Role:     c.GetString(managementRoleContextKey),
+		ClientIP: c.ClientIP(),
+		Method:   c.Request.Method,
+		Endpoint: c.FullPath(),
+		Action:   action,
+		Target:   target,
+		Status:   c.Writer.Status(),
+	}
+	if event.Endpoint == "" {
+		event.Endpoint = c.Request.URL.Path
+	}
+	if identity := c.GetString("clientCertIdentity"); identity != "" && event.Caller == "" {
+		event.Caller = identity
+	}
+	if err := h.auditLog.write(path, event); err != nil {
+		log.WithError(err).Warn("management: failed to write audit record")
+	}
+}
@@ -0,0 +1,83 @@
+package management
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestDeleteAuthFile_WritesAuditRecord(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	auditPath := filepath.Join(t.TempDir(), "audit", "management.jsonl")
+	cfg := &config.Config{AuthDir: dir}
+	cfg.RemoteManagement.AuditLog = auditPath
+	h := &Handler{
+		cfg:            cfg,
+		authManager:    coreauth.NewManager(nil, nil, nil),
+		tokenStore:     sdkAuth.NewFileTokenStore(),
+		envSecret:      "audit-secret",
+		failedAttempts: make(map[string]*attemptInfo),
+	}
+
+	path := filepath.Join(dir, "claude-audit.json")
+	if err := os.WriteFile(path, []byte(`{"type":"claude","email":"a@example.com"}`), 0o600); err != nil {
+		t.Fatalf("write auth file: %v", err)
+	}
+	if err := h.registerAuthFromFile(context.Background(), path, nil); err != nil {
+		t.Fatalf("register auth file: %v", err)
+	}
+
+	router := gin.New()
+	router.DELETE("/v0/management/auth-files", h.Middleware(), h.DeleteAuthFile)
+	req := httptest.NewRequest(http.MethodDelete, "/v0/management/auth-files?name=claude-audit.json", nil)
+	req.RemoteAddr = "127.0.0.1:40000"
+	req.Header.Set("Authorization", "Bearer audit-secret")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+
+	file, err := os.Open(auditPath)
+	if err != nil {
+		t.Fatalf("open audit log: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+	var events []AuditEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event AuditEvent
+		if errUnmarshal := json.Unmarshal(scanner.Bytes(), &event); errUnmarshal != nil {
+			t.Fatalf("decode audit line %q: %v", scanner.Text(), errUnmarshal)
+		}
+		events = append(events, event)
+	}
+	if len(events) != 1 {
+		t.Fatalf("audit records = %d, want 1", len(events))
+	}
+	event := events[0]
+	if event.Action != "delete-auth-file" || event.Target != "claude-audit.json" {
+		t.Fatalf("audit action/target = %q/%q", event.Action, event.Target)
+	}
+	if event.Caller != auditCallerEnvSecret || event.KeyID != managementKeyID("audit-secret") {
+		t.Fatalf("audit caller = %q key_id = %q", event.Caller, event.KeyID)
+	}
+	if event.Method != http.MethodDelete || event.Endpoint != "/v0/management/auth-files" || event.Status != http.StatusOK {
+		t.Fatalf("audit request fields = %+v", event)
+	}
+	if event.Time.IsZero() {
+		t.Fatalf("audit record has no timestamp")
+	}
+}
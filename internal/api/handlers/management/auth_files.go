@@ -30,6 +30,7 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/geminicli"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
@@ -42,14 +43,14 @@ import (
 var lastRefreshKeys = []string{"last_refresh", "lastRefresh", "last_refreshed_at", "lastRefreshedAt"}
 
 const (
-	anthropicCallbackPort   = 54545
-	geminiCallbackPort      = 8085
-	codexCallbackPort       = 1455
-	geminiCLIEndpoint       = "https://cloudcode-pa.googleapis.com"
-	geminiCLIVersion        = "v1internal"
-	geminiCLIUserAgent      = "google-api-nodejs-client/9.15.1"
-	geminiCLIApiClient      = "gl-node/22.17.0"
-	geminiCLIClientMetadata = "ideType=IDE_UNSPECIFIED,platform=PLATFORM_UNSPECIFIED,pluginType=GEMINI"
+	anthropicCallbackPort = 54545
+	geminiCallbackPort    = 8085
+	codexCallbackPort     = 1455
+	geminiCLIEndpoint     = "https://cloudcode-pa.googleapis.com"
+	geminiCLIVersion      = "v1internal"
+
+	// defaultGeminiOnboardConcurrency bounds parallel project setups when onboarding ALL projects.
+	defaultGeminiOnboardConcurrency = 4
 )
 
 type callbackForwarder struct {
@@ -258,8 +259,12 @@ func (h *Handler) ListAuthFiles(c *gin.Context) {
 	}
 	auths := h.authManager.List()
 	files := make([]gin.H, 0, len(auths))
+	readOnly := isReadOnlyCaller(c)
 	for _, auth := range auths {
 		if entry := h.buildAuthFileEntry(auth); entry != nil {
+			if account, ok := entry["account"].(string); ok && readOnly && entry["account_type"] == "api_key" {
+				entry["account"] = util.HideAPIKey(account)
+			}
 			files = append(files, entry)
 		}
 	}
@@ -299,6 +304,11 @@ func (h *Handler) GetAuthFileModels(c *gin.Context) {
 	reg := registry.GetGlobalRegistry()
 	models := reg.GetModelsForClient(authID)
 
+	c.JSON(200, gin.H{"models": authFileModelEntries(models)})
+}
+
+// authFileModelEntries renders registry models for the auth file model endpoints.
+func authFileModelEntries(models []*registry.ModelInfo) []gin.H {
 	result := make([]gin.H, 0, len(models))
 	for _, m := range models {
 		entry := gin.H{
@@ -315,8 +325,7 @@ func (h *Handler) GetAuthFileModels(c *gin.Context) {
 		}
 		result = append(result, entry)
 	}
-
-	c.JSON(200, gin.H{"models": result})
+	return result
 }
 
 // List auth files from disk when the auth manager is unavailable.
@@ -340,12 +349,20 @@ func (h *Handler) listAuthFilesFromDisk(c *gin.Context) {
 
 			// Read file to get type field
 			full := filepath.Join(h.cfg.AuthDir, name)
+			typeValue := ""
 			if data, errRead := os.ReadFile(full); errRead == nil {
-				typeValue := gjson.GetBytes(data, "type").String()
+				typeValue = gjson.GetBytes(data, "type").String()
 				emailValue := gjson.GetBytes(data, "email").String()
 				fileData["type"] = typeValue
 				fileData["email"] = emailValue
 			}
+			// Encrypted or non-standard files may lack a type; fall back to the filename prefix.
+			if strings.TrimSpace(typeValue) == "" && !h.cfg.RemoteManagement.DisableAuthTypeInference {
+				if inferred := inferAuthTypeFromFileName(name); inferred != "" {
+					fileData["type"] = inferred
+					fileData["type_inferred"] = true
+				}
+			}
 
 			files = append(files, fileData)
 		}
@@ -353,6 +370,21 @@ func (h *Handler) listAuthFilesFromDisk(c *gin.Context) {
 	c.JSON(200, gin.H{"files": files})
 }
 
+// authFileTypePrefixes maps auth file name prefixes to the provider type they usually hold.
+var authFileTypePrefixes = []string{"claude", "codex", "gemini", "iflow", "qwen", "kimi"}
+
+// inferAuthTypeFromFileName guesses the provider type from a file name such as
+// "claude-user@example.com.json". It returns "" when no known prefix matches.
+func inferAuthTypeFromFileName(name string) string {
+	lower := strings.ToLower(filepath.Base(name))
+	for _, prefix := range authFileTypePrefixes {
+		if strings.HasPrefix(lower, prefix+"-") {
+			return prefix
+		}
+	}
+	return ""
+}
+
 func (h *Handler) buildAuthFileEntry(auth *coreauth.Auth) gin.H {
 	if auth == nil {
 		return nil
@@ -382,6 +414,7 @@ func (h *Handler) buildAuthFileEntry(auth *coreauth.Auth) gin.H {
 		"disabled":       auth.Disabled,
 		"unavailable":    auth.Unavailable,
 		"runtime_only":   runtimeOnly,
+		"standby":        isStandbyAuth(auth),
 		"source":         "memory",
 		"size":           int64(0),
 	}
@@ -502,6 +535,25 @@ func isRuntimeOnlyAuth(auth *coreauth.Auth) bool {
 	return strings.EqualFold(strings.TrimSpace(auth.Attributes["runtime_only"]), "true")
 }
 
+// isStandbyAuth mirrors the selector's standby check: the "standby" attribute, falling back
+// to the auth file metadata.
+func isStandbyAuth(auth *coreauth.Auth) bool {
+	if auth == nil {
+		return false
+	}
+	if standby, err := strconv.ParseBool(strings.TrimSpace(authAttribute(auth, "standby"))); err == nil {
+		return standby
+	}
+	switch v := auth.Metadata["standby"].(type) {
+	case bool:
+		return v
+	case string:
+		standby, _ := strconv.ParseBool(strings.TrimSpace(v))
+		return standby
+	}
+	return false
+}
+
 // Download single auth file by name
 func (h *Handler) DownloadAuthFile(c *gin.Context) {
 	name := c.Query("name")
@@ -523,12 +575,20 @@ func (h *Handler) DownloadAuthFile(c *gin.Context) {
 		}
 		return
 	}
+	if isReadOnlyCaller(c) {
+		if data, err = redactAuthJSON(data); err != nil {
+			c.JSON(500, gin.H{"error": fmt.Sprintf("failed to redact file: %v", err)})
+			return
+		}
+	}
 	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", name))
 	c.Data(200, "application/json", data)
 }
 
 // Upload auth file: multipart or raw JSON with ?name=
 func (h *Handler) UploadAuthFile(c *gin.Context) {
+	target := c.Query("name")
+	defer func() { h.audit(c, "upload-auth-file", target) }()
 	if h.authManager == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
 		return
@@ -536,6 +596,7 @@ func (h *Handler) UploadAuthFile(c *gin.Context) {
 	ctx := c.Request.Context()
 	if file, err := c.FormFile("file"); err == nil && file != nil {
 		name := filepath.Base(file.Filename)
+		target = name
 		if !strings.HasSuffix(strings.ToLower(name), ".json") {
 			c.JSON(400, gin.H{"error": "file must be .json"})
 			return
@@ -595,12 +656,19 @@ func (h *Handler) UploadAuthFile(c *gin.Context) {
 
 // Delete auth files: single by name or all
 func (h *Handler) DeleteAuthFile(c *gin.Context) {
+	all := c.Query("all")
+	deleteAll := all == "true" || all == "1" || all == "*"
+	target := c.Query("name")
+	if deleteAll {
+		target = "*"
+	}
+	defer h.audit(c, "delete-auth-file", target)
 	if h.authManager == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
 		return
 	}
 	ctx := c.Request.Context()
-	if all := c.Query("all"); all == "true" || all == "1" || all == "*" {
+	if deleteAll {
 		entries, err := os.ReadDir(h.cfg.AuthDir)
 		if err != nil {
 			c.JSON(500, gin.H{"error": fmt.Sprintf("failed to read auth dir: %v", err)})
@@ -744,6 +812,8 @@ func (h *Handler) registerAuthFromFile(ctx context.Context, path string, data []
 
 // PatchAuthFileStatus toggles the disabled state of an auth file
 func (h *Handler) PatchAuthFileStatus(c *gin.Context) {
+	var target string
+	defer func() { h.audit(c, "patch-auth-file-status", target) }()
 	if h.authManager == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
 		return
@@ -759,6 +829,7 @@ func (h *Handler) PatchAuthFileStatus(c *gin.Context) {
 	}
 
 	name := strings.TrimSpace(req.Name)
+	target = name
 	if name == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
 		return
@@ -808,8 +879,11 @@ func (h *Handler) PatchAuthFileStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "ok", "disabled": *req.Disabled})
 }
 
-// PatchAuthFileFields updates editable fields (prefix, proxy_url, priority) of an auth file.
+// PatchAuthFileFields updates editable fields (prefix, proxy_url, priority, label, standby)
+// of an auth file. A standby auth stays enabled but is only selected when a request pins it.
 func (h *Handler) PatchAuthFileFields(c *gin.Context) {
+	var target string
+	defer func() { h.audit(c, "patch-auth-file-fields", target) }()
 	if h.authManager == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
 		return
@@ -820,6 +894,8 @@ func (h *Handler) PatchAuthFileFields(c *gin.Context) {
 		Prefix   *string `json:"prefix"`
 		ProxyURL *string `json:"proxy_url"`
 		Priority *int    `json:"priority"`
+		Label    *string `json:"label"`
+		Standby  *bool   `json:"standby"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
@@ -827,6 +903,7 @@ func (h *Handler) PatchAuthFileFields(c *gin.Context) {
 	}
 
 	name := strings.TrimSpace(req.Name)
+	target = name
 	if name == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
 		return
@@ -855,7 +932,21 @@ func (h *Handler) PatchAuthFileFields(c *gin.Context) {
 
 	changed := false
 	if req.Prefix != nil {
-		targetAuth.Prefix = *req.Prefix
+		prefix := strings.Trim(strings.TrimSpace(*req.Prefix), "/")
+		if strings.Contains(prefix, "/") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "prefix must not contain '/'"})
+			return
+		}
+		if targetAuth.Metadata == nil {
+			targetAuth.Metadata = make(map[string]any)
+		}
+		// The prefix is read back from the auth file metadata on reload.
+		if prefix == "" {
+			delete(targetAuth.Metadata, "prefix")
+		} else {
+			targetAuth.Metadata["prefix"] = prefix
+		}
+		targetAuth.Prefix = prefix
 		changed = true
 	}
 	if req.ProxyURL != nil {
@@ -873,6 +964,32 @@ func (h *Handler) PatchAuthFileFields(c *gin.Context) {
 		}
 		changed = true
 	}
+	if req.Label != nil {
+		if targetAuth.Metadata == nil {
+			targetAuth.Metadata = make(map[string]any)
+		}
+		label := strings.TrimSpace(*req.Label)
+		if label == "" {
+			delete(targetAuth.Metadata, "label")
+			targetAuth.Label = defaultAuthLabel(targetAuth)
+		} else {
+			targetAuth.Metadata["label"] = label
+			targetAuth.Label = label
+		}
+		changed = true
+	}
+	if req.Standby != nil {
+		if targetAuth.Metadata == nil {
+			targetAuth.Metadata = make(map[string]any)
+		}
+		if *req.Standby {
+			targetAuth.Metadata["standby"] = true
+		} else {
+			delete(targetAuth.Metadata, "standby")
+		}
+		delete(targetAuth.Attributes, "standby")
+		changed = true
+	}
 
 	if !changed {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "no fields to update"})
@@ -889,6 +1006,18 @@ func (h *Handler) PatchAuthFileFields(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
 
+// defaultAuthLabel mirrors the label derived when an auth file is loaded: the email
+// when present, otherwise the provider.
+func defaultAuthLabel(auth *coreauth.Auth) string {
+	if auth == nil {
+		return ""
+	}
+	if email, ok := auth.Metadata["email"].(string); ok && strings.TrimSpace(email) != "" {
+		return email
+	}
+	return auth.Provider
+}
+
 func (h *Handler) disableAuth(ctx context.Context, id string) {
 	if h == nil || h.authManager == nil {
 		return
@@ -949,6 +1078,7 @@ func (h *Handler) saveTokenRecord(ctx context.Context, record *coreauth.Auth) (s
 }
 
 func (h *Handler) RequestAnthropicToken(c *gin.Context) {
+	defer h.audit(c, "request-auth-token", "claude")
 	ctx := context.Background()
 
 	fmt.Println("Initializing Claude authentication...")
@@ -1093,12 +1223,17 @@ func (h *Handler) RequestAnthropicToken(c *gin.Context) {
 }
 
 func (h *Handler) RequestGeminiCLIToken(c *gin.Context) {
+	defer h.audit(c, "request-auth-token", "gemini-cli")
 	ctx := context.Background()
 	proxyHTTPClient := util.SetProxy(&h.cfg.SDKConfig, &http.Client{})
 	ctx = context.WithValue(ctx, oauth2.HTTPClient, proxyHTTPClient)
+	ctx = withGeminiCLIEndpoint(ctx, h.cfg.GeminiCLI.Endpoint(""))
 
 	// Optional project ID from query
 	projectID := c.Query("project_id")
+	// Optional async Cloud AI API enablement: the token is saved first and the session
+	// stays pending in the "enabling_api" phase until enablement finishes.
+	asyncEnable, _ := strconv.ParseBool(strings.TrimSpace(c.Query("async_enable")))
 
 	fmt.Println("Initializing Google authentication...")
 
@@ -1185,16 +1320,15 @@ func (h *Handler) RequestGeminiCLIToken(c *gin.Context) {
 
 		// Create token storage (mirrors internal/auth/gemini createTokenStorage)
 		authHTTPClient := conf.Client(ctx, token)
-		req, errNewRequest := http.NewRequestWithContext(ctx, "GET", "https://www.googleapis.com/oauth2/v1/userinfo?alt=json", nil)
-		if errNewRequest != nil {
-			log.Errorf("Could not get user info: %v", errNewRequest)
-			SetOAuthSessionError(state, "Could not get user info")
-			return
-		}
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token.AccessToken))
-
-		resp, errDo := authHTTPClient.Do(req)
+		resp, errDo := geminiAuth.DoWithRetry(ctx, authHTTPClient, "userinfo", func() (*http.Request, error) {
+			req, errNewRequest := http.NewRequestWithContext(ctx, "GET", "https://www.googleapis.com/oauth2/v1/userinfo?alt=json", nil)
+			if errNewRequest != nil {
+				return nil, errNewRequest
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token.AccessToken))
+			return req, nil
+		})
 		if errDo != nil {
 			log.Errorf("Failed to execute request: %v", errDo)
 			SetOAuthSessionError(state, "Failed to execute request")
@@ -1254,21 +1388,29 @@ func (h *Handler) RequestGeminiCLIToken(c *gin.Context) {
 		}
 		fmt.Println("Authentication successful.")
 
+		var enableProjects []string
 		if strings.EqualFold(requestedProjectID, "ALL") {
 			ts.Auto = false
-			projects, errAll := onboardAllGeminiProjects(ctx, gemClient, &ts)
+			projects, errAll := onboardAllGeminiProjects(ctx, gemClient, &ts, h.cfg.GeminiCLI.OnboardConcurrency)
 			if errAll != nil {
-				log.Errorf("Failed to complete Gemini CLI onboarding: %v", errAll)
-				SetOAuthSessionError(state, "Failed to complete Gemini CLI onboarding")
-				return
+				if len(projects) == 0 {
+					log.Errorf("Failed to complete Gemini CLI onboarding: %v", errAll)
+					SetOAuthSessionError(state, "Failed to complete Gemini CLI onboarding")
+					return
+				}
+				log.Warnf("Gemini CLI onboarding partially failed; continuing with %d project(s): %v", len(projects), errAll)
+				fmt.Printf("Activated projects %s; some projects could not be onboarded: %v\n", strings.Join(projects, ","), errAll)
 			}
-			if errVerify := ensureGeminiProjectsEnabled(ctx, gemClient, projects); errVerify != nil {
-				log.Errorf("Failed to verify Cloud AI API status: %v", errVerify)
-				SetOAuthSessionError(state, "Failed to verify Cloud AI API status")
-				return
+			if !asyncEnable {
+				if errVerify := ensureGeminiProjectsEnabled(ctx, gemClient, projects); errVerify != nil {
+					log.Errorf("Failed to verify Cloud AI API status: %v", errVerify)
+					SetOAuthSessionError(state, "Failed to verify Cloud AI API status")
+					return
+				}
 			}
 			ts.ProjectID = strings.Join(projects, ",")
-			ts.Checked = true
+			ts.Checked = !asyncEnable
+			enableProjects = projects
 		} else if strings.EqualFold(requestedProjectID, "GOOGLE_ONE") {
 			ts.Auto = false
 			if errSetup := performGeminiCLISetup(ctx, gemClient, &ts, ""); errSetup != nil {
@@ -1281,17 +1423,20 @@ func (h *Handler) RequestGeminiCLIToken(c *gin.Context) {
 				SetOAuthSessionError(state, "Google One auto-discovery returned empty project ID")
 				return
 			}
-			isChecked, errCheck := checkCloudAPIIsEnabled(ctx, gemClient, ts.ProjectID)
-			if errCheck != nil {
-				log.Errorf("Failed to verify Cloud AI API status: %v", errCheck)
-				SetOAuthSessionError(state, "Failed to verify Cloud AI API status")
-				return
-			}
-			ts.Checked = isChecked
-			if !isChecked {
-				log.Error("Cloud AI API is not enabled for the auto-discovered project")
-				SetOAuthSessionError(state, "Cloud AI API not enabled")
-				return
+			enableProjects = []string{ts.ProjectID}
+			if !asyncEnable {
+				isChecked, errCheck := checkCloudAPIIsEnabled(ctx, gemClient, ts.ProjectID)
+				if errCheck != nil {
+					log.Errorf("Failed to verify Cloud AI API status: %v", errCheck)
+					SetOAuthSessionError(state, "Failed to verify Cloud AI API status")
+					return
+				}
+				ts.Checked = isChecked
+				if !isChecked {
+					log.Error("Cloud AI API is not enabled for the auto-discovered project")
+					SetOAuthSessionError(state, "Cloud AI API not enabled")
+					return
+				}
 			}
 		} else {
 			if errEnsure := ensureGeminiProjectAndOnboard(ctx, gemClient, &ts, requestedProjectID); errEnsure != nil {
@@ -1306,17 +1451,20 @@ func (h *Handler) RequestGeminiCLIToken(c *gin.Context) {
 				return
 			}
 
-			isChecked, errCheck := checkCloudAPIIsEnabled(ctx, gemClient, ts.ProjectID)
-			if errCheck != nil {
-				log.Errorf("Failed to verify Cloud AI API status: %v", errCheck)
-				SetOAuthSessionError(state, "Failed to verify Cloud AI API status")
-				return
-			}
-			ts.Checked = isChecked
-			if !isChecked {
-				log.Error("Cloud AI API is not enabled for the selected project")
-				SetOAuthSessionError(state, "Cloud AI API not enabled")
-				return
+			enableProjects = []string{ts.ProjectID}
+			if !asyncEnable {
+				isChecked, errCheck := checkCloudAPIIsEnabled(ctx, gemClient, ts.ProjectID)
+				if errCheck != nil {
+					log.Errorf("Failed to verify Cloud AI API status: %v", errCheck)
+					SetOAuthSessionError(state, "Failed to verify Cloud AI API status")
+					return
+				}
+				ts.Checked = isChecked
+				if !isChecked {
+					log.Error("Cloud AI API is not enabled for the selected project")
+					SetOAuthSessionError(state, "Cloud AI API not enabled")
+					return
+				}
 			}
 		}
 
@@ -1342,6 +1490,14 @@ func (h *Handler) RequestGeminiCLIToken(c *gin.Context) {
 			return
 		}
 
+		if asyncEnable {
+			fmt.Printf("Token saved to %s; enabling Cloud AI API in the background\n", savedPath)
+			h.finishGeminiCloudAPIEnable(ctx, state, record, &ts, func(ctx context.Context) error {
+				return ensureGeminiProjectsEnabled(ctx, gemClient, enableProjects)
+			})
+			return
+		}
+
 		CompleteOAuthSession(state)
 		CompleteOAuthSessionsByProvider("gemini")
 		fmt.Printf("You can now use Gemini CLI services through this CLI; token saved to %s\n", savedPath)
@@ -1351,6 +1507,7 @@ func (h *Handler) RequestGeminiCLIToken(c *gin.Context) {
 }
 
 func (h *Handler) RequestCodexToken(c *gin.Context) {
+	defer h.audit(c, "request-auth-token", "codex")
 	ctx := context.Background()
 
 	fmt.Println("Initializing Codex authentication...")
@@ -1496,6 +1653,7 @@ func (h *Handler) RequestCodexToken(c *gin.Context) {
 }
 
 func (h *Handler) RequestAntigravityToken(c *gin.Context) {
+	defer h.audit(c, "request-auth-token", "antigravity")
 	ctx := context.Background()
 
 	fmt.Println("Initializing Antigravity authentication...")
@@ -1660,6 +1818,7 @@ func (h *Handler) RequestAntigravityToken(c *gin.Context) {
 }
 
 func (h *Handler) RequestQwenToken(c *gin.Context) {
+	defer h.audit(c, "request-auth-token", "qwen")
 	ctx := context.Background()
 
 	fmt.Println("Initializing Qwen authentication...")
@@ -1715,6 +1874,7 @@ func (h *Handler) RequestQwenToken(c *gin.Context) {
 }
 
 func (h *Handler) RequestKimiToken(c *gin.Context) {
+	defer h.audit(c, "request-auth-token", "kimi")
 	ctx := context.Background()
 
 	fmt.Println("Initializing Kimi authentication...")
@@ -1791,6 +1951,7 @@ func (h *Handler) RequestKimiToken(c *gin.Context) {
 }
 
 func (h *Handler) RequestIFlowToken(c *gin.Context) {
+	defer h.audit(c, "request-auth-token", "iflow")
 	ctx := context.Background()
 
 	fmt.Println("Initializing iFlow authentication...")
@@ -1904,6 +2065,7 @@ func (h *Handler) RequestIFlowToken(c *gin.Context) {
 }
 
 func (h *Handler) RequestIFlowCookieToken(c *gin.Context) {
+	defer h.audit(c, "request-auth-token", "iflow-cookie")
 	ctx := context.Background()
 
 	var payload struct {
@@ -2038,7 +2200,11 @@ func ensureGeminiProjectAndOnboard(ctx context.Context, httpClient *http.Client,
 	return nil
 }
 
-func onboardAllGeminiProjects(ctx context.Context, httpClient *http.Client, storage *geminiAuth.GeminiTokenStorage) ([]string, error) {
+// onboardAllGeminiProjects onboards every project of the account, running up to concurrency
+// setups at once (<= 0 uses defaultGeminiOnboardConcurrency). Activated project IDs are
+// returned in project list order; failures are aggregated into a single error, returned
+// alongside the projects that did activate so callers can keep a partial login.
+func onboardAllGeminiProjects(ctx context.Context, httpClient *http.Client, storage *geminiAuth.GeminiTokenStorage, concurrency int) ([]string, error) {
 	projects, errProjects := fetchGCPProjects(ctx, httpClient)
 	if errProjects != nil {
 		return nil, fmt.Errorf("fetch project list: %w", errProjects)
@@ -2046,7 +2212,7 @@ func onboardAllGeminiProjects(ctx context.Context, httpClient *http.Client, stor
 	if len(projects) == 0 {
 		return nil, fmt.Errorf("no Google Cloud projects available for this account")
 	}
-	activated := make([]string, 0, len(projects))
+	candidates := make([]string, 0, len(projects))
 	seen := make(map[string]struct{}, len(projects))
 	for _, project := range projects {
 		candidate := strings.TrimSpace(project.ProjectID)
@@ -2056,20 +2222,114 @@ func onboardAllGeminiProjects(ctx context.Context, httpClient *http.Client, stor
 		if _, dup := seen[candidate]; dup {
 			continue
 		}
-		if err := performGeminiCLISetup(ctx, httpClient, storage, candidate); err != nil {
-			return nil, fmt.Errorf("onboard project %s: %w", candidate, err)
-		}
-		finalID := strings.TrimSpace(storage.ProjectID)
-		if finalID == "" {
-			finalID = candidate
-		}
-		activated = append(activated, finalID)
 		seen[candidate] = struct{}{}
+		candidates = append(candidates, candidate)
 	}
+
+	if concurrency <= 0 {
+		concurrency = defaultGeminiOnboardConcurrency
+	}
+	results := make([]string, len(candidates))
+	errs := make([]error, len(candidates))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for idx, candidate := range candidates {
+		wg.Add(1)
+		go func(idx int, candidate string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[idx] = fmt.Errorf("onboard project %s: %w", candidate, ctx.Err())
+				return
+			}
+			defer func() { <-sem }()
+
+			// Each setup writes its resolved project ID into its own storage copy.
+			local := *storage
+			if err := performGeminiCLISetup(ctx, httpClient, &local, candidate); err != nil {
+				errs[idx] = fmt.Errorf("onboard project %s: %w", candidate, err)
+				return
+			}
+			finalID := strings.TrimSpace(local.ProjectID)
+			if finalID == "" {
+				finalID = candidate
+			}
+			results[idx] = finalID
+		}(idx, candidate)
+	}
+	wg.Wait()
+
+	activated := make([]string, 0, len(candidates))
+	for idx := range candidates {
+		if errs[idx] == nil {
+			activated = append(activated, results[idx])
+		}
+	}
+	errJoined := errors.Join(errs...)
 	if len(activated) == 0 {
+		if errJoined != nil {
+			return nil, errJoined
+		}
 		return nil, fmt.Errorf("no Google Cloud projects available for this account")
 	}
-	return activated, nil
+	storage.ProjectID = activated[len(activated)-1]
+	return activated, errJoined
+}
+
+// finishGeminiCloudAPIEnable runs enable for a Gemini login whose token was already saved
+// unchecked. The OAuth session stays pending in the "enabling_api" phase meanwhile; on
+// success the record is re-saved as checked and the session completes. On failure the
+// error is saved on the record as check_error, the auth is marked with an error status
+// and the failure is reported on the session.
+func (h *Handler) finishGeminiCloudAPIEnable(ctx context.Context, state string, record *coreauth.Auth, ts *geminiAuth.GeminiTokenStorage, enable func(context.Context) error) {
+	SetOAuthSessionPhase(state, "enabling_api")
+	if record.Metadata == nil {
+		record.Metadata = make(map[string]any)
+	}
+	if errEnable := enable(ctx); errEnable != nil {
+		log.Errorf("Failed to enable Cloud AI API: %v", errEnable)
+		h.recordGeminiCloudAPIEnableFailure(ctx, record, ts, errEnable)
+		SetOAuthSessionError(state, "Failed to enable Cloud AI API")
+		return
+	}
+	ts.Checked = true
+	ts.CheckError = ""
+	record.Metadata["checked"] = true
+	delete(record.Metadata, "check_error")
+	if _, errSave := h.saveTokenRecord(ctx, record); errSave != nil {
+		log.Errorf("Failed to save token after enabling Cloud AI API: %v", errSave)
+		SetOAuthSessionError(state, "Failed to save token to file")
+		return
+	}
+	CompleteOAuthSession(state)
+	CompleteOAuthSessionsByProvider("gemini")
+	log.Infof("Cloud AI API enabled for %s", ts.ProjectID)
+}
+
+// recordGeminiCloudAPIEnableFailure saves a failed async enablement on the token file and
+// marks the auth, when already registered, with an error status.
+func (h *Handler) recordGeminiCloudAPIEnableFailure(ctx context.Context, record *coreauth.Auth, ts *geminiAuth.GeminiTokenStorage, errEnable error) {
+	ts.Checked = false
+	ts.CheckError = errEnable.Error()
+	record.Metadata["checked"] = false
+	record.Metadata["check_error"] = ts.CheckError
+	record.Status = coreauth.StatusError
+	record.StatusMessage = "Cloud AI API enablement failed"
+	if _, errSave := h.saveTokenRecord(ctx, record); errSave != nil {
+		log.Errorf("Failed to save Cloud AI API enablement failure: %v", errSave)
+	}
+	if h.authManager == nil {
+		return
+	}
+	if current, ok := h.authManager.GetByID(record.ID); ok {
+		current.Status = coreauth.StatusError
+		current.StatusMessage = record.StatusMessage
+		current.UpdatedAt = time.Now()
+		if _, errUpdate := h.authManager.Update(ctx, current); errUpdate != nil {
+			log.Errorf("Failed to mark auth %s after Cloud AI API enablement failure: %v", record.ID, errUpdate)
+		}
+	}
 }
 
 func ensureGeminiProjectsEnabled(ctx context.Context, httpClient *http.Client, projectIDs []string) error {
@@ -2250,34 +2510,68 @@ func performGeminiCLISetup(ctx context.Context, httpClient *http.Client, storage
 	}
 }
 
+type geminiCLIEndpointKey struct{}
+
+// withGeminiCLIEndpoint makes callGeminiCLI use endpoint instead of geminiCLIEndpoint.
+// An empty endpoint keeps the default.
+func withGeminiCLIEndpoint(ctx context.Context, endpoint string) context.Context {
+	if endpoint == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, geminiCLIEndpointKey{}, endpoint)
+}
+
+func geminiCLIEndpointFrom(ctx context.Context) string {
+	if endpoint, ok := ctx.Value(geminiCLIEndpointKey{}).(string); ok && endpoint != "" {
+		return endpoint
+	}
+	return geminiCLIEndpoint
+}
+
 func callGeminiCLI(ctx context.Context, httpClient *http.Client, endpoint string, body any, result any) error {
-	endPointURL := fmt.Sprintf("%s/%s:%s", geminiCLIEndpoint, geminiCLIVersion, endpoint)
+	baseURL := geminiCLIEndpointFrom(ctx)
+	endPointURL := fmt.Sprintf("%s/%s:%s", baseURL, geminiCLIVersion, endpoint)
 	if strings.HasPrefix(endpoint, "operations/") {
-		endPointURL = fmt.Sprintf("%s/%s", geminiCLIEndpoint, endpoint)
+		endPointURL = fmt.Sprintf("%s/%s", baseURL, endpoint)
 	}
 
-	var reader io.Reader
+	var rawBody []byte
 	if body != nil {
-		rawBody, errMarshal := json.Marshal(body)
+		var errMarshal error
+		rawBody, errMarshal = json.Marshal(body)
 		if errMarshal != nil {
 			return fmt.Errorf("marshal request body: %w", errMarshal)
 		}
-		reader = bytes.NewReader(rawBody)
 	}
 
-	req, errRequest := http.NewRequestWithContext(ctx, http.MethodPost, endPointURL, reader)
-	if errRequest != nil {
-		return fmt.Errorf("create request: %w", errRequest)
+	// onboardUser provisions resources, so it is only replayed when the request never left.
+	do := geminiAuth.DoWithRetry
+	if endpoint == "onboardUser" {
+		do = geminiAuth.DoNonIdempotentWithRetry
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", geminiCLIUserAgent)
-	req.Header.Set("X-Goog-Api-Client", geminiCLIApiClient)
-	req.Header.Set("Client-Metadata", geminiCLIClientMetadata)
-
-	resp, errDo := httpClient.Do(req)
+	resp, errDo := do(ctx, httpClient, "cli "+endpoint, func() (*http.Request, error) {
+		var reader io.Reader
+		if rawBody != nil {
+			reader = bytes.NewReader(rawBody)
+		}
+		req, errRequest := http.NewRequestWithContext(ctx, http.MethodPost, endPointURL, reader)
+		if errRequest != nil {
+			return nil, fmt.Errorf("create request: %w", errRequest)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", geminicli.UserAgent)
+		req.Header.Set("X-Goog-Api-Client", geminicli.APIClient)
+		req.Header.Set("Client-Metadata", geminicli.ClientMetadata)
+		return req, nil
+	})
 	if errDo != nil {
 		return fmt.Errorf("execute request: %w", errDo)
 	}
+
+	return decodeGeminiCLIResponse(resp, result)
+}
+
+func decodeGeminiCLIResponse(resp *http.Response, result any) error {
 	defer func() {
 		if errClose := resp.Body.Close(); errClose != nil {
 			log.Errorf("response body close error: %v", errClose)
@@ -2302,12 +2596,13 @@ func callGeminiCLI(ctx context.Context, httpClient *http.Client, endpoint string
 }
 
 func fetchGCPProjects(ctx context.Context, httpClient *http.Client) ([]interfaces.GCPProjectProjects, error) {
-	req, errRequest := http.NewRequestWithContext(ctx, http.MethodGet, "https://cloudresourcemanager.googleapis.com/v1/projects", nil)
-	if errRequest != nil {
-		return nil, fmt.Errorf("could not create project list request: %w", errRequest)
-	}
-
-	resp, errDo := httpClient.Do(req)
+	resp, errDo := geminiAuth.DoWithRetry(ctx, httpClient, "project list", func() (*http.Request, error) {
+		req, errRequest := http.NewRequestWithContext(ctx, http.MethodGet, "https://cloudresourcemanager.googleapis.com/v1/projects", nil)
+		if errRequest != nil {
+			return nil, fmt.Errorf("could not create project list request: %w", errRequest)
+		}
+		return req, nil
+	})
 	if errDo != nil {
 		return nil, fmt.Errorf("failed to execute project list request: %w", errDo)
 	}
@@ -2342,7 +2637,7 @@ func checkCloudAPIIsEnabled(ctx context.Context, httpClient *http.Client, projec
 			return false, fmt.Errorf("failed to create request: %w", errRequest)
 		}
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("User-Agent", geminiCLIUserAgent)
+		req.Header.Set("User-Agent", geminicli.UserAgent)
 		resp, errDo := httpClient.Do(req)
 		if errDo != nil {
 			return false, fmt.Errorf("failed to execute request: %w", errDo)
@@ -2363,7 +2658,7 @@ func checkCloudAPIIsEnabled(ctx context.Context, httpClient *http.Client, projec
 			return false, fmt.Errorf("failed to create request: %w", errRequest)
 		}
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("User-Agent", geminiCLIUserAgent)
+		req.Header.Set("User-Agent", geminicli.UserAgent)
 		resp, errDo = httpClient.Do(req)
 		if errDo != nil {
 			return false, fmt.Errorf("failed to execute request: %w", errDo)
@@ -2410,5 +2705,9 @@ func (h *Handler) GetAuthStatus(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "error", "error": status})
 		return
 	}
+	if phase := GetOAuthSessionPhase(state); phase != "" {
+		c.JSON(http.StatusOK, gin.H{"status": "wait", "phase": phase})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"status": "wait"})
 }
@@ -0,0 +1,163 @@
+package management
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+const redactedValue = "[REDACTED]"
+
+// sensitiveKeyMarkers identifies credential fields replaced during redacted exports.
+var sensitiveKeyMarkers = []string{"token", "secret", "password", "cookie", "api_key", "apikey", "private_key", "credential"}
+
+// ExportAuthFiles returns a zip archive of every credential listed by ListAuthFiles.
+// The snapshot is taken from the auth manager so memory-only auths are included.
+// Pass ?redact=true to replace token and secret values; read-only callers always get a
+// redacted archive.
+func (h *Handler) ExportAuthFiles(c *gin.Context) {
+	if h == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "handler not initialized"})
+		return
+	}
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	redact, _ := strconv.ParseBool(strings.TrimSpace(c.Query("redact")))
+	redact = redact || isReadOnlyCaller(c)
+
+	auths := h.authManager.List()
+	sort.Slice(auths, func(i, j int) bool { return auths[i].ID < auths[j].ID })
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	used := make(map[string]int, len(auths))
+	for _, auth := range auths {
+		if h.buildAuthFileEntry(auth) == nil {
+			continue
+		}
+		data, err := exportAuthJSON(auth, redact)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to export %s: %v", auth.ID, err)})
+			return
+		}
+		w, err := archive.Create(exportAuthFileName(auth, used))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to build archive: %v", err)})
+			return
+		}
+		if _, err = w.Write(data); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to build archive: %v", err)})
+			return
+		}
+	}
+	if err := archive.Close(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to build archive: %v", err)})
+		return
+	}
+
+	name := fmt.Sprintf("auth-files-%s.zip", time.Now().UTC().Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", name))
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// exportAuthJSON returns the credential JSON for an auth. File-backed auths without
+// metadata are read from disk; otherwise the in-memory metadata is serialized.
+func exportAuthJSON(auth *coreauth.Auth, redact bool) ([]byte, error) {
+	var payload map[string]any
+	if len(auth.Metadata) > 0 {
+		// Round-trip through JSON so redaction never touches the nested maps shared with
+		// the live auth.
+		raw, err := json.Marshal(auth.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(raw, &payload); err != nil {
+			return nil, err
+		}
+	} else if path := strings.TrimSpace(authAttribute(auth, "path")); path != "" {
+		raw, err := os.ReadFile(path)
+		if err == nil {
+			if errUnmarshal := json.Unmarshal(raw, &payload); errUnmarshal != nil {
+				return nil, errUnmarshal
+			}
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if payload == nil {
+		payload = map[string]any{"type": strings.TrimSpace(auth.Provider)}
+	}
+	if redact {
+		redactSensitiveFields(payload)
+	}
+	return json.MarshalIndent(payload, "", "  ")
+}
+
+// redactAuthJSON returns a credential JSON document with token and secret values replaced.
+func redactAuthJSON(data []byte) ([]byte, error) {
+	var payload any
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+	redactSensitiveFields(payload)
+	return json.MarshalIndent(payload, "", "  ")
+}
+
+// exportAuthFileName derives a unique archive entry name for an auth.
+func exportAuthFileName(auth *coreauth.Auth, used map[string]int) string {
+	name := filepath.Base(strings.TrimSpace(auth.FileName))
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		name = filepath.Base(auth.ID)
+	}
+	if !strings.HasSuffix(strings.ToLower(name), ".json") {
+		name += ".json"
+	}
+	count := used[name]
+	used[name] = count + 1
+	if count == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s-%d.json", strings.TrimSuffix(name, ".json"), count+1)
+}
+
+func redactSensitiveFields(value any) {
+	switch typed := value.(type) {
+	case map[string]any:
+		for k, v := range typed {
+			if isSensitiveKey(k) {
+				if _, isString := v.(string); isString {
+					typed[k] = redactedValue
+					continue
+				}
+			}
+			redactSensitiveFields(v)
+		}
+	case []any:
+		for _, item := range typed {
+			redactSensitiveFields(item)
+		}
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, marker := range sensitiveKeyMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
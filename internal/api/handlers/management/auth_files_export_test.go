@@ -0,0 +1,110 @@
+package management
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestExportAuthFiles_ContainsEveryListedAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "claude-user.json")
+	if err := os.WriteFile(filePath, []byte(`{"type":"claude","access_token":"secret-token"}`), 0o600); err != nil {
+		t.Fatalf("write auth file: %v", err)
+	}
+
+	manager := coreauth.NewManager(nil, nil, nil)
+	ctx := context.Background()
+	if _, err := manager.Register(ctx, &coreauth.Auth{
+		ID:         "claude-user.json",
+		FileName:   "claude-user.json",
+		Provider:   "claude",
+		Attributes: map[string]string{"path": filePath},
+		Metadata:   map[string]any{"type": "claude", "access_token": "secret-token"},
+	}); err != nil {
+		t.Fatalf("register file auth: %v", err)
+	}
+	if _, err := manager.Register(ctx, &coreauth.Auth{
+		ID:         "runtime-gemini",
+		Provider:   "gemini-cli",
+		Attributes: map[string]string{"runtime_only": "true"},
+		Metadata:   map[string]any{"type": "gemini-cli", "refresh_token": "secret-refresh", "token": map[string]any{"access_token": "nested-secret"}},
+	}); err != nil {
+		t.Fatalf("register runtime auth: %v", err)
+	}
+
+	h := &Handler{authManager: manager}
+
+	listRec := httptest.NewRecorder()
+	listCtx, _ := gin.CreateTestContext(listRec)
+	listCtx.Request = httptest.NewRequest(http.MethodGet, "/v0/management/auth-files", nil)
+	h.ListAuthFiles(listCtx)
+	var listed struct {
+		Files []map[string]any `json:"files"`
+	}
+	if err := json.Unmarshal(listRec.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("decode list response: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v0/management/auth-files/export?redact=true", nil)
+	h.ExportAuthFiles(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body=%s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatalf("open archive: %v", err)
+	}
+	entries := make(map[string]map[string]any, len(reader.File))
+	for _, f := range reader.File {
+		rc, errOpen := f.Open()
+		if errOpen != nil {
+			t.Fatalf("open entry %s: %v", f.Name, errOpen)
+		}
+		data, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		var payload map[string]any
+		if errUnmarshal := json.Unmarshal(data, &payload); errUnmarshal != nil {
+			t.Fatalf("decode entry %s: %v", f.Name, errUnmarshal)
+		}
+		entries[f.Name] = payload
+	}
+
+	if len(entries) != len(listed.Files) || len(entries) != 2 {
+		t.Fatalf("archive has %d entries, listed %d auths", len(entries), len(listed.Files))
+	}
+	for _, file := range listed.Files {
+		name, _ := file["name"].(string)
+		if filepath.Ext(name) != ".json" {
+			name += ".json"
+		}
+		if _, ok := entries[name]; !ok {
+			t.Fatalf("listed auth %q missing from archive", name)
+		}
+	}
+	if got := entries["claude-user.json"]["access_token"]; got != redactedValue {
+		t.Fatalf("access_token = %v, want redacted", got)
+	}
+	if got := entries["runtime-gemini.json"]["refresh_token"]; got != redactedValue {
+		t.Fatalf("refresh_token = %v, want redacted", got)
+	}
+	live, _ := manager.GetByID("runtime-gemini")
+	if nested, _ := live.Metadata["token"].(map[string]any); nested["access_token"] != "nested-secret" {
+		t.Fatalf("redacted export modified the live auth: token = %v", live.Metadata["token"])
+	}
+}
@@ -0,0 +1,311 @@
+package management
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Import limits. Credential files are small, so the bounds are generous for real archives
+// while keeping oversized uploads and archive bombs out of memory.
+const (
+	// maxImportArchiveSize bounds the uploaded archive and the total extracted size.
+	maxImportArchiveSize = 32 << 20
+	// maxImportEntrySize bounds a single credential file read from an import archive.
+	maxImportEntrySize = 1 << 20
+	// maxImportEntries bounds the number of credential files in one archive.
+	maxImportEntries = 1000
+)
+
+// Import result statuses reported per archive entry.
+const (
+	importStatusImported = "imported"
+	importStatusSkipped  = "skipped"
+	importStatusFailed   = "failed"
+)
+
+type archiveEntry struct {
+	name string
+	data []byte
+	err  error
+}
+
+// ImportAuthFiles restores credentials from a zip or tar(.gz) archive of JSON files.
+// The archive is read from the multipart "file" field or the raw request body.
+// Existing files are skipped unless ?overwrite=true is set.
+func (h *Handler) ImportAuthFiles(c *gin.Context) {
+	var imported []string
+	defer func() { h.audit(c, "import-auth-files", strings.Join(imported, ",")) }()
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	overwrite, _ := strconv.ParseBool(strings.TrimSpace(c.Query("overwrite")))
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportArchiveSize)
+	var raw []byte
+	file, err := c.FormFile("file")
+	switch {
+	case err == nil && file != nil:
+		src, errOpen := file.Open()
+		if errOpen != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("failed to open archive: %v", errOpen)})
+			return
+		}
+		raw, err = io.ReadAll(src)
+		_ = src.Close()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("failed to read archive: %v", err)})
+			return
+		}
+	case isMaxBytesError(err):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "archive exceeds size limit"})
+		return
+	default:
+		raw, err = io.ReadAll(c.Request.Body)
+		if isMaxBytesError(err) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "archive exceeds size limit"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read body"})
+			return
+		}
+	}
+
+	entries, err := readAuthArchive(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	results := make([]gin.H, 0, len(entries))
+	counts := map[string]int{importStatusImported: 0, importStatusSkipped: 0, importStatusFailed: 0}
+	record := func(name, status, reason string) {
+		entry := gin.H{"name": name, "status": status}
+		if reason != "" {
+			entry["reason"] = reason
+		}
+		results = append(results, entry)
+		counts[status]++
+	}
+
+	for _, entry := range entries {
+		if entry.err != nil {
+			record(entry.name, importStatusFailed, entry.err.Error())
+			continue
+		}
+		if errValidate := validateImportedAuth(entry.data); errValidate != nil {
+			record(entry.name, importStatusFailed, errValidate.Error())
+			continue
+		}
+		dst := filepath.Join(h.cfg.AuthDir, entry.name)
+		if !filepath.IsAbs(dst) {
+			if abs, errAbs := filepath.Abs(dst); errAbs == nil {
+				dst = abs
+			}
+		}
+		if _, errStat := os.Stat(dst); errStat == nil && !overwrite {
+			record(entry.name, importStatusSkipped, "file already exists")
+			continue
+		}
+		if errWrite := os.WriteFile(dst, entry.data, 0o600); errWrite != nil {
+			record(entry.name, importStatusFailed, fmt.Sprintf("failed to write file: %v", errWrite))
+			continue
+		}
+		if errReg := h.registerAuthFromFile(ctx, dst, entry.data); errReg != nil {
+			record(entry.name, importStatusFailed, errReg.Error())
+			continue
+		}
+		record(entry.name, importStatusImported, "")
+		imported = append(imported, entry.name)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"files":    results,
+		"imported": counts[importStatusImported],
+		"skipped":  counts[importStatusSkipped],
+		"failed":   counts[importStatusFailed],
+	})
+}
+
+// isMaxBytesError reports whether err was caused by http.MaxBytesReader.
+func isMaxBytesError(err error) bool {
+	var maxErr *http.MaxBytesError
+	return errors.As(err, &maxErr)
+}
+
+// importBudget tracks the entry count and extracted bytes left for one archive.
+type importBudget struct {
+	entries int
+	bytes   int64
+}
+
+func newImportBudget() *importBudget {
+	return &importBudget{entries: maxImportEntries, bytes: maxImportArchiveSize}
+}
+
+// take reserves one entry, failing once the archive holds too many.
+func (b *importBudget) take() error {
+	if b.entries <= 0 {
+		return fmt.Errorf("archive has more than %d credential files", maxImportEntries)
+	}
+	b.entries--
+	return nil
+}
+
+// readAuthArchive extracts JSON entries from a zip, tar or gzip-compressed tar archive.
+// Directories and non-JSON entries are ignored; nested paths are flattened to the base name.
+// Archives with too many entries or too much extracted data are rejected.
+func readAuthArchive(raw []byte) ([]archiveEntry, error) {
+	if len(raw) == 0 {
+		return nil, errors.New("archive is empty")
+	}
+	if bytes.HasPrefix(raw, []byte("PK\x03\x04")) || bytes.HasPrefix(raw, []byte("PK\x05\x06")) {
+		return readZipAuthArchive(raw, newImportBudget())
+	}
+	if bytes.HasPrefix(raw, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip archive: %w", err)
+		}
+		defer func() { _ = gz.Close() }()
+		return readTarAuthArchive(gz, newImportBudget())
+	}
+	return readTarAuthArchive(bytes.NewReader(raw), newImportBudget())
+}
+
+func readZipAuthArchive(raw []byte, budget *importBudget) ([]archiveEntry, error) {
+	reader, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+	entries := make([]archiveEntry, 0, len(reader.File))
+	for _, f := range reader.File {
+		name, ok := importEntryName(f.Name, f.FileInfo().IsDir())
+		if !ok {
+			continue
+		}
+		if err = budget.take(); err != nil {
+			return nil, err
+		}
+		rc, errOpen := f.Open()
+		if errOpen != nil {
+			entries = append(entries, archiveEntry{name: name, err: errOpen})
+			continue
+		}
+		data, errRead := readImportEntry(rc, budget)
+		_ = rc.Close()
+		if errors.Is(errRead, errImportArchiveTooLarge) {
+			return nil, errRead
+		}
+		entries = append(entries, archiveEntry{name: name, data: data, err: errRead})
+	}
+	return entries, nil
+}
+
+func readTarAuthArchive(r io.Reader, budget *importBudget) ([]archiveEntry, error) {
+	reader := tar.NewReader(r)
+	var entries []archiveEntry
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid tar archive: %w", err)
+		}
+		name, ok := importEntryName(header.Name, header.Typeflag != tar.TypeReg)
+		if !ok {
+			continue
+		}
+		if err = budget.take(); err != nil {
+			return nil, err
+		}
+		data, errRead := readImportEntry(reader, budget)
+		if errors.Is(errRead, errImportArchiveTooLarge) {
+			return nil, errRead
+		}
+		entries = append(entries, archiveEntry{name: name, data: data, err: errRead})
+	}
+	return entries, nil
+}
+
+func importEntryName(raw string, skip bool) (string, bool) {
+	if skip {
+		return "", false
+	}
+	name := filepath.Base(filepath.FromSlash(raw))
+	if name == "" || strings.HasPrefix(name, ".") || !strings.HasSuffix(strings.ToLower(name), ".json") {
+		return "", false
+	}
+	return name, true
+}
+
+// errImportArchiveTooLarge aborts an import whose entries extract to more than
+// maxImportArchiveSize in total.
+var errImportArchiveTooLarge = fmt.Errorf("archive extracts to more than %d bytes", maxImportArchiveSize)
+
+func readImportEntry(r io.Reader, budget *importBudget) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxImportEntrySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxImportEntrySize {
+		return nil, errors.New("file exceeds size limit")
+	}
+	budget.bytes -= int64(len(data))
+	if budget.bytes < 0 {
+		return nil, errImportArchiveTooLarge
+	}
+	return data, nil
+}
+
+// validateImportedAuth checks that data is a credential JSON object with a provider type
+// and was not produced by a redacted export.
+func validateImportedAuth(data []byte) error {
+	var metadata map[string]any
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return fmt.Errorf("invalid auth file: %w", err)
+	}
+	if provider, _ := metadata["type"].(string); strings.TrimSpace(provider) == "" {
+		return errors.New("invalid auth file: missing type")
+	}
+	if containsRedactedValue(metadata) {
+		return errors.New("auth file contains redacted values")
+	}
+	return nil
+}
+
+func containsRedactedValue(value any) bool {
+	switch typed := value.(type) {
+	case map[string]any:
+		for _, v := range typed {
+			if containsRedactedValue(v) {
+				return true
+			}
+		}
+	case []any:
+		for _, item := range typed {
+			if containsRedactedValue(item) {
+				return true
+			}
+		}
+	case string:
+		return typed == redactedValue
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package management
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+type importResponse struct {
+	Files []struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	} `json:"files"`
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+	Failed   int `json:"failed"`
+}
+
+func buildTestZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("create zip entry: %v", err)
+		}
+		if _, err = f.Write([]byte(content)); err != nil {
+			t.Fatalf("write zip entry: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func postImport(t *testing.T, h *Handler, query string, archive []byte) importResponse {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v0/management/auth-files/import"+query, bytes.NewReader(archive))
+	h.ImportAuthFiles(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var resp importResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return resp
+}
+
+func TestImportAuthFiles_TwoFileArchive(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	manager := coreauth.NewManager(nil, nil, nil)
+	h := &Handler{cfg: &config.Config{AuthDir: dir}, authManager: manager}
+
+	archive := buildTestZip(t, map[string]string{
+		"claude-a.json":       `{"type":"claude","email":"a@example.com","access_token":"tok-a"}`,
+		"nested/codex-b.json": `{"type":"codex","email":"b@example.com","access_token":"tok-b"}`,
+	})
+
+	resp := postImport(t, h, "", archive)
+	if resp.Imported != 2 || resp.Skipped != 0 || resp.Failed != 0 {
+		t.Fatalf("unexpected result: %+v", resp)
+	}
+	for _, name := range []string{"claude-a.json", "codex-b.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("expected %s to be written: %v", name, err)
+		}
+		if _, ok := manager.GetByID(name); !ok {
+			t.Fatalf("expected %s to be registered", name)
+		}
+	}
+
+	// Re-importing without overwrite skips existing files; invalid entries fail.
+	archive = buildTestZip(t, map[string]string{
+		"claude-a.json": `{"type":"claude","email":"a@example.com","access_token":"tok-a2"}`,
+		"broken.json":   `{"access_token":"[REDACTED]"}`,
+	})
+	resp = postImport(t, h, "", archive)
+	if resp.Imported != 0 || resp.Skipped != 1 || resp.Failed != 1 {
+		t.Fatalf("unexpected result without overwrite: %+v", resp)
+	}
+
+	resp = postImport(t, h, "?overwrite=true", archive)
+	if resp.Imported != 1 || resp.Failed != 1 {
+		t.Fatalf("unexpected result with overwrite: %+v", resp)
+	}
+	auth, ok := manager.GetByID("claude-a.json")
+	if !ok || auth.Metadata["access_token"] != "tok-a2" {
+		t.Fatalf("expected overwritten credential to be registered, got %+v", auth)
+	}
+}
+
+func TestImportAuthFiles_RejectsOversizedArchives(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := &Handler{cfg: &config.Config{AuthDir: t.TempDir()}, authManager: coreauth.NewManager(nil, nil, nil)}
+	post := func(body []byte) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v0/management/auth-files/import", bytes.NewReader(body))
+		h.ImportAuthFiles(c)
+		return rec
+	}
+
+	if rec := post(bytes.Repeat([]byte("x"), maxImportArchiveSize+1)); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized body status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+
+	files := make(map[string]string, maxImportEntries+1)
+	for i := 0; i <= maxImportEntries; i++ {
+		files[fmt.Sprintf("claude-%d.json", i)] = `{"type":"claude"}`
+	}
+	if rec := post(buildTestZip(t, files)); rec.Code != http.StatusBadRequest {
+		t.Fatalf("too many entries status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	oversized := `{"type":"claude","pad":"` + strings.Repeat("x", maxImportEntrySize) + `"}`
+	resp := postImport(t, h, "", buildTestZip(t, map[string]string{"claude-big.json": oversized}))
+	if resp.Imported != 0 || resp.Failed != 1 {
+		t.Fatalf("oversized entry result = %+v, want it failed", resp)
+	}
+}
@@ -0,0 +1,91 @@
+package management
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func listAuthFilesFromDiskForTest(t *testing.T, cfg *config.Config) map[string]map[string]any {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	h := &Handler{cfg: cfg}
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v0/management/auth-files", nil)
+	h.ListAuthFiles(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Files []map[string]any `json:"files"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	byName := make(map[string]map[string]any, len(resp.Files))
+	for _, f := range resp.Files {
+		name, _ := f["name"].(string)
+		byName[name] = f
+	}
+	return byName
+}
+
+func TestListAuthFilesFromDiskInfersMissingType(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"claude-a@example.com.json": `{"email":"a@example.com"}`,
+		"codex-b.json":              `{}`,
+		"gemini-c.json":             `not-json-encrypted-blob`,
+		"iflow-d.json":              `{}`,
+		"qwen-e.json":               `{}`,
+		"kimi-f.json":               `{}`,
+		"custom-g.json":             `{}`,
+		"qwen-typed.json":           `{"type":"codex"}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	listed := listAuthFilesFromDiskForTest(t, &config.Config{AuthDir: dir})
+	want := map[string]string{
+		"claude-a@example.com.json": "claude",
+		"codex-b.json":              "codex",
+		"gemini-c.json":             "gemini",
+		"iflow-d.json":              "iflow",
+		"qwen-e.json":               "qwen",
+		"kimi-f.json":               "kimi",
+		"custom-g.json":             "",
+		"qwen-typed.json":           "codex",
+	}
+	for name, wantType := range want {
+		entry, ok := listed[name]
+		if !ok {
+			t.Fatalf("missing entry %s", name)
+		}
+		if got, _ := entry["type"].(string); got != wantType {
+			t.Fatalf("%s type = %q, want %q", name, got, wantType)
+		}
+	}
+	if inferred, _ := listed["claude-a@example.com.json"]["type_inferred"].(bool); !inferred {
+		t.Fatalf("expected type_inferred for claude file: %v", listed["claude-a@example.com.json"])
+	}
+	if _, ok := listed["qwen-typed.json"]["type_inferred"]; ok {
+		t.Fatalf("explicit type must not be marked inferred")
+	}
+
+	disabled := &config.Config{AuthDir: dir}
+	disabled.RemoteManagement.DisableAuthTypeInference = true
+	listed = listAuthFilesFromDiskForTest(t, disabled)
+	if got, _ := listed["codex-b.json"]["type"].(string); got != "" {
+		t.Fatalf("inference disabled: type = %q, want empty", got)
+	}
+}
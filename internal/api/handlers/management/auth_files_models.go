@@ -0,0 +1,71 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// authFileModelsRefreshAction is the custom method segment of
+// GET /auth-files/:name/models:refresh.
+const authFileModelsRefreshAction = "models:refresh"
+
+// ModelRefresher re-queries the upstream model list for an auth, rebinds it in the
+// global model registry and returns the models now registered for it.
+type ModelRefresher func(auth *coreauth.Auth) []*registry.ModelInfo
+
+// AuthFileAction dispatches GET /auth-files/:name/:action. Gin cannot route a literal
+// colon inside a segment, so custom methods such as "models:refresh" are matched here.
+func (h *Handler) AuthFileAction(c *gin.Context) {
+	switch c.Param("action") {
+	case authFileModelsRefreshAction:
+		h.RefreshAuthFileModels(c)
+	default:
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown auth file action"})
+	}
+}
+
+// RefreshAuthFileModels re-probes the provider model list of one auth file, updates
+// the global model registry and returns the refreshed list.
+func (h *Handler) RefreshAuthFileModels(c *gin.Context) {
+	name := strings.TrimSpace(c.Param("name"))
+	defer h.audit(c, "refresh-auth-file-models", name)
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	if h.modelRefresher == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "model refresh unavailable"})
+		return
+	}
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	var target *coreauth.Auth
+	if auth, ok := h.authManager.GetByID(name); ok {
+		target = auth
+	} else {
+		for _, auth := range h.authManager.List() {
+			if auth.FileName == name {
+				target = auth
+				break
+			}
+		}
+	}
+	if target == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "auth file not found"})
+		return
+	}
+	if target.Disabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "auth file is disabled"})
+		return
+	}
+
+	models := h.modelRefresher(target)
+	c.JSON(http.StatusOK, gin.H{"id": target.ID, "models": authFileModelEntries(models)})
+}
@@ -0,0 +1,93 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestRefreshAuthFileModels_UpdatesRegistry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	h := &Handler{cfg: &config.Config{AuthDir: dir}, authManager: coreauth.NewManager(nil, nil, nil)}
+	path := filepath.Join(dir, "antigravity-refresh.json")
+	if err := os.WriteFile(path, []byte(`{"type":"antigravity","email":"a@example.com"}`), 0o600); err != nil {
+		t.Fatalf("write auth file: %v", err)
+	}
+	if err := h.registerAuthFromFile(context.Background(), path, nil); err != nil {
+		t.Fatalf("register auth file: %v", err)
+	}
+
+	reg := registry.GetGlobalRegistry()
+	const authID = "antigravity-refresh.json"
+	reg.RegisterClient(authID, "antigravity", []*registry.ModelInfo{{ID: "refresh-model-a"}})
+	t.Cleanup(func() { reg.UnregisterClient(authID) })
+
+	// The stub plays the upstream that has since added a model.
+	var refreshed string
+	h.SetModelRefresher(func(auth *coreauth.Auth) []*registry.ModelInfo {
+		refreshed = auth.ID
+		reg.RegisterClient(auth.ID, "antigravity", []*registry.ModelInfo{{ID: "refresh-model-a"}, {ID: "refresh-model-b"}})
+		return reg.GetModelsForClient(auth.ID)
+	})
+
+	router := gin.New()
+	router.GET("/v0/management/auth-files/models", h.GetAuthFileModels)
+	router.GET("/v0/management/auth-files/:name/:action", h.AuthFileAction)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v0/management/auth-files/antigravity-refresh.json/models:refresh", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("refresh status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if refreshed != authID {
+		t.Fatalf("refresher called for %q, want %q", refreshed, authID)
+	}
+
+	var body struct {
+		Models []struct {
+			ID string `json:"id"`
+		} `json:"models"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	var got []string
+	for _, m := range body.Models {
+		got = append(got, m.ID)
+	}
+	slices.Sort(got)
+	if want := []string{"refresh-model-a", "refresh-model-b"}; !slices.Equal(got, want) {
+		t.Fatalf("refreshed models = %v, want %v", got, want)
+	}
+	if !reg.ClientSupportsModel(authID, "refresh-model-b") {
+		t.Fatalf("registry does not reflect the refreshed model list")
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v0/management/auth-files/missing.json/models:refresh", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("missing auth status = %d, want 404", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v0/management/auth-files/antigravity-refresh.json/models:reload", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown action status = %d, want 404", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v0/management/auth-files/models?name=antigravity-refresh.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("static models route status = %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,210 @@
+package management
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// PinAuthFile persists a runtime-only auth to the token store so it survives a restart,
+// and clears its runtime_only attribute. The auth file holds the metadata plus the settings
+// kept in attributes (priority, group, api_key, base_url, ...) in the form the file loader
+// restores. Auths that cannot be rebuilt from a file are rejected: live channels such as
+// aistudio, secret directory credentials that are reloaded from their mount, and auths
+// without metadata. Tokens, keys or other secrets are only written when include_secrets
+// is true, otherwise the request is rejected and the offending fields are listed.
+// Body: {"name": "<auth id>", "file_name": "optional.json", "include_secrets": false}.
+func (h *Handler) PinAuthFile(c *gin.Context) {
+	var target string
+	defer func() { h.audit(c, "pin-auth-file", target) }()
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+
+	var req struct {
+		Name           string `json:"name"`
+		FileName       string `json:"file_name"`
+		IncludeSecrets bool   `json:"include_secrets"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	target = name
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	auth, ok := h.authManager.GetByID(name)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "auth not found"})
+		return
+	}
+	if !isRuntimeOnlyAuth(auth) {
+		c.JSON(http.StatusConflict, gin.H{"error": "auth is already file-backed"})
+		return
+	}
+	if authAttribute(auth, "gemini_virtual_parent") != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "virtual project auths are derived from their parent auth file"})
+		return
+	}
+	if reason := unpinnableAuthReason(auth); reason != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": reason})
+		return
+	}
+
+	fileName := strings.TrimSpace(req.FileName)
+	if fileName == "" {
+		fileName = pinnedAuthFileName(auth)
+	}
+	if errName := validateAuthFileName(fileName); errName != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid file_name: %v", errName)})
+		return
+	}
+	path := h.authFilePath(fileName)
+	if _, errStat := os.Stat(path); errStat == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "target file already exists"})
+		return
+	}
+
+	metadata := pinnedAuthMetadata(auth)
+	if secrets := sensitiveMetadataFields(metadata); len(secrets) > 0 && !req.IncludeSecrets {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":         "auth metadata contains secrets; set include_secrets to persist them",
+			"secret_fields": secrets,
+		})
+		return
+	}
+
+	pinned := auth.Clone()
+	delete(pinned.Attributes, "runtime_only")
+	pinned.Attributes["path"] = path
+	pinned.Attributes["source"] = path
+	pinned.FileName = fileName
+	pinned.Metadata = metadata
+	pinned.UpdatedAt = time.Now()
+
+	ctx := c.Request.Context()
+	if _, errSave := h.saveTokenRecord(ctx, pinned); errSave != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to persist auth: %v", errSave)})
+		return
+	}
+	// The record was just written; only the in-memory copy needs refreshing.
+	if _, errUpdate := h.authManager.Update(coreauth.WithSkipPersist(ctx), pinned); errUpdate != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to update auth: %v", errUpdate)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "id": pinned.ID, "name": fileName})
+}
+
+// unpinnableAuthReason explains why auth cannot be rebuilt from an auth file holding its
+// metadata, or returns "" when it can.
+func unpinnableAuthReason(auth *coreauth.Auth) string {
+	if strings.EqualFold(strings.TrimSpace(auth.Provider), "aistudio") {
+		return "aistudio auths are live websocket channels and cannot be restored from a file"
+	}
+	if sdkAuth.IsSecretDirAuthID(auth.ID) {
+		return "secret directory auths are reloaded from their mounted file and would be duplicated by an auth file"
+	}
+	if strings.EqualFold(strings.TrimSpace(auth.Provider), "gemini") {
+		return "gemini API key auths would be restored as gemini-cli from an auth file"
+	}
+	if len(auth.Metadata) == 0 {
+		return "auth has no metadata to persist"
+	}
+	return ""
+}
+
+// derivedAuthAttributes are rebuilt whenever an auth file is loaded and are not persisted.
+var derivedAuthAttributes = map[string]struct{}{
+	"runtime_only":         {},
+	"path":                 {},
+	"source":               {},
+	"excluded_models":      {},
+	"excluded_models_hash": {},
+	"auth_kind":            {},
+}
+
+// pinnedAuthMetadata returns the auth file content for auth: its metadata plus the settings
+// held in auth fields and attributes, under the keys the file synthesizer restores them from.
+func pinnedAuthMetadata(auth *coreauth.Auth) map[string]any {
+	metadata := make(map[string]any, len(auth.Metadata)+4)
+	for k, v := range auth.Metadata {
+		metadata[k] = v
+	}
+	setDefault := func(key string, value string) {
+		if _, exists := metadata[key]; !exists && strings.TrimSpace(value) != "" {
+			metadata[key] = value
+		}
+	}
+	setDefault("type", strings.TrimSpace(auth.Provider))
+	setDefault("prefix", auth.Prefix)
+	setDefault("proxy_url", auth.ProxyURL)
+
+	attributes := make(map[string]any)
+	for key, value := range auth.Attributes {
+		if _, derived := derivedAuthAttributes[key]; derived || strings.TrimSpace(value) == "" {
+			continue
+		}
+		switch key {
+		case "priority", "group":
+			setDefault(key, value)
+		default:
+			attributes[key] = value
+		}
+	}
+	if len(attributes) > 0 {
+		metadata["attributes"] = attributes
+	}
+	return metadata
+}
+
+// pinnedAuthFileName derives an auth file name from the provider and auth ID, following
+// the "<provider>-<account>.json" layout used by the login flows.
+func pinnedAuthFileName(auth *coreauth.Auth) string {
+	base := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', ' ':
+			return '_'
+		}
+		return r
+	}, strings.TrimSuffix(auth.ID, ".json"))
+	if provider := strings.TrimSpace(auth.Provider); provider != "" && !strings.HasPrefix(base, provider+"-") {
+		base = provider + "-" + base
+	}
+	return base + ".json"
+}
+
+// sensitiveMetadataFields lists the top-level metadata keys, and the persisted attributes as
+// "attributes.<key>", holding non-empty secret values.
+func sensitiveMetadataFields(metadata map[string]any) []string {
+	var fields []string
+	for k, v := range metadata {
+		if nested, ok := v.(map[string]any); ok && k == "attributes" {
+			for _, field := range sensitiveMetadataFields(nested) {
+				fields = append(fields, k+"."+field)
+			}
+			continue
+		}
+		if !isSensitiveKey(k) {
+			continue
+		}
+		if s, isString := v.(string); v == nil || (isString && strings.TrimSpace(s) == "") {
+			continue
+		}
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+	return fields
+}
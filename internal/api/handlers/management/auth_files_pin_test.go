@@ -0,0 +1,155 @@
+package management
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher/synthesizer"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func postPin(t *testing.T, h *Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v0/management/auth-files/pin", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	h.PinAuthFile(c)
+	return rec
+}
+
+func TestPinAuthFile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	manager := coreauth.NewManager(nil, nil, nil)
+	h := &Handler{cfg: &config.Config{AuthDir: dir}, authManager: manager, tokenStore: sdkAuth.NewFileTokenStore()}
+
+	for _, auth := range []*coreauth.Auth{
+		{
+			ID:         "ws-channel",
+			Provider:   "aistudio",
+			Status:     coreauth.StatusActive,
+			Attributes: map[string]string{"runtime_only": "true"},
+			Metadata:   map[string]any{"email": "ws-channel"},
+		},
+		{
+			ID:         "attribute-key",
+			Provider:   "claude",
+			Status:     coreauth.StatusActive,
+			Attributes: map[string]string{"runtime_only": "true", "api_key": "sk-attr", "base_url": "https://example.com", "priority": "5", "group": "team-a"},
+			Metadata:   map[string]any{"email": "attr@example.com"},
+		},
+		{
+			ID:         "secret:mounted.json",
+			Provider:   "claude",
+			Status:     coreauth.StatusActive,
+			Attributes: map[string]string{"runtime_only": "true"},
+			Metadata:   map[string]any{"email": "mounted@example.com"},
+		},
+		{
+			ID:         "oauth-account",
+			Provider:   "claude",
+			Status:     coreauth.StatusActive,
+			Attributes: map[string]string{"runtime_only": "true"},
+			Metadata:   map[string]any{"email": "oauth@example.com"},
+		},
+		{
+			ID:         "injected-key",
+			Provider:   "openai",
+			Status:     coreauth.StatusActive,
+			Attributes: map[string]string{"runtime_only": "true"},
+			Metadata:   map[string]any{"api_key": "sk-test"},
+		},
+	} {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register %s: %v", auth.ID, err)
+		}
+	}
+
+	rec := postPin(t, h, `{"name":"injected-key"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("secret pin status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "openai-injected-key.json")); !os.IsNotExist(err) {
+		t.Fatalf("secret auth was written without include_secrets: %v", err)
+	}
+	if rec = postPin(t, h, `{"name":"injected-key","include_secrets":true}`); rec.Code != http.StatusOK {
+		t.Fatalf("secret pin with include_secrets status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+
+	// Auths a file cannot rebuild are rejected rather than pinned half-way.
+	for _, name := range []string{"ws-channel", "secret:mounted.json"} {
+		if rec = postPin(t, h, `{"name":"`+name+`"}`); rec.Code != http.StatusBadRequest {
+			t.Fatalf("pin %s status = %d, body=%s", name, rec.Code, rec.Body.String())
+		}
+		if auth, _ := manager.GetByID(name); !isRuntimeOnlyAuth(auth) {
+			t.Fatalf("rejected auth %s lost its runtime_only attribute", name)
+		}
+	}
+
+	// Attribute-backed settings are persisted where the file loader restores them.
+	if rec = postPin(t, h, `{"name":"attribute-key"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("attribute api_key pin without include_secrets status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if rec = postPin(t, h, `{"name":"attribute-key","include_secrets":true}`); rec.Code != http.StatusOK {
+		t.Fatalf("attribute pin status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	attrPath := filepath.Join(dir, "claude-attribute-key.json")
+	attrRaw, err := os.ReadFile(attrPath)
+	if err != nil {
+		t.Fatalf("pinned attribute file missing: %v", err)
+	}
+	restored, err := synthesizer.SynthesizeAuthFile(&config.Config{}, "claude-attribute-key.json", attrPath, attrRaw, time.Now())
+	if err != nil || len(restored) != 1 {
+		t.Fatalf("reload pinned attribute file: %v (%d auths)", err, len(restored))
+	}
+	for key, want := range map[string]string{"api_key": "sk-attr", "base_url": "https://example.com", "priority": "5", "group": "team-a"} {
+		if got := restored[0].Attributes[key]; got != want {
+			t.Fatalf("restored attribute %s = %q, want %q; file=%s", key, got, want, attrRaw)
+		}
+	}
+	if _, persisted := restored[0].Attributes["runtime_only"]; persisted {
+		t.Fatalf("runtime_only was persisted: %s", attrRaw)
+	}
+
+	rec = postPin(t, h, `{"name":"oauth-account"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("pin status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	path := filepath.Join(dir, "claude-oauth-account.json")
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("pinned file missing: %v", err)
+	}
+	var written map[string]any
+	if err = json.Unmarshal(raw, &written); err != nil {
+		t.Fatalf("pinned file is not JSON: %v", err)
+	}
+	if written["type"] != "claude" || written["email"] != "oauth@example.com" {
+		t.Fatalf("pinned file content = %s", raw)
+	}
+
+	pinned, ok := manager.GetByID("oauth-account")
+	if !ok {
+		t.Fatalf("pinned auth missing from manager")
+	}
+	entry := h.buildAuthFileEntry(pinned)
+	if entry == nil || entry["runtime_only"] != false || entry["source"] != "file" || entry["path"] != path {
+		t.Fatalf("pinned auth entry = %v, want file-backed at %s", entry, path)
+	}
+
+	if rec = postPin(t, h, `{"name":"oauth-account"}`); rec.Code != http.StatusConflict {
+		t.Fatalf("repeat pin status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,141 @@
+package management
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// RenameAuthFile moves an auth file to a new name inside the auth directory and
+// re-registers the credential under the new ID, keeping its runtime fields.
+// Body: {"name": "old.json", "new_name": "new.json"}.
+func (h *Handler) RenameAuthFile(c *gin.Context) {
+	var target string
+	defer func() { h.audit(c, "rename-auth-file", target) }()
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+
+	var req struct {
+		Name    string `json:"name"`
+		NewName string `json:"new_name"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	newName := strings.TrimSpace(req.NewName)
+	target = name + " -> " + newName
+	if errName := validateAuthFileName(name); errName != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid name: %v", errName)})
+		return
+	}
+	if errName := validateAuthFileName(newName); errName != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid new_name: %v", errName)})
+		return
+	}
+	if name == newName {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "new_name must differ from name"})
+		return
+	}
+
+	src := h.authFilePath(name)
+	dst := h.authFilePath(newName)
+	if _, errStat := os.Stat(src); errStat != nil {
+		if os.IsNotExist(errStat) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "file not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to stat file: %v", errStat)})
+		}
+		return
+	}
+	newID := h.authIDForPath(dst)
+	if _, errStat := os.Stat(dst); errStat == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "target file already exists"})
+		return
+	}
+	if existing, ok := h.authManager.GetByID(newID); ok && !existing.Disabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "target auth already registered"})
+		return
+	}
+
+	if errRename := os.Rename(src, dst); errRename != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to rename file: %v", errRename)})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if errDel := h.deleteTokenRecord(ctx, src); errDel != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errDel.Error()})
+		return
+	}
+
+	old, ok := h.authManager.GetByID(h.authIDForPath(src))
+	if !ok {
+		if errReg := h.registerAuthFromFile(ctx, dst, nil); errReg != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": errReg.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok", "name": newName, "id": newID})
+		return
+	}
+
+	renamed := old.Clone()
+	renamed.ID = newID
+	renamed.Index = ""
+	renamed.FileName = filepath.Base(dst)
+	if renamed.Attributes == nil {
+		renamed.Attributes = make(map[string]string)
+	}
+	renamed.Attributes["path"] = dst
+	renamed.Attributes["source"] = dst
+	renamed.UpdatedAt = time.Now()
+	if _, errReg := h.authManager.Register(ctx, renamed); errReg != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to register renamed auth: %v", errReg)})
+		return
+	}
+
+	// The old record no longer exists on disk or in the store. Retire it without persisting
+	// so hooks release its models, then drop it so no stale disabled entry is left behind.
+	old.Disabled = true
+	old.Status = coreauth.StatusDisabled
+	old.StatusMessage = "renamed to " + newName
+	old.UpdatedAt = time.Now()
+	_, _ = h.authManager.Update(coreauth.WithSkipPersist(ctx), old)
+	h.authManager.Remove(old.ID)
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "name": newName, "id": newID})
+}
+
+// validateAuthFileName accepts plain .json file names that stay inside the auth directory.
+func validateAuthFileName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("name is required")
+	case strings.ContainsAny(name, `/\`) || name != filepath.Base(name) || name == "." || name == "..":
+		return errors.New("name must not contain path separators")
+	case !strings.HasSuffix(strings.ToLower(name), ".json"):
+		return errors.New("name must end with .json")
+	}
+	return nil
+}
+
+// authFilePath resolves an auth file name to an absolute path inside the auth directory.
+func (h *Handler) authFilePath(name string) string {
+	full := filepath.Join(h.cfg.AuthDir, name)
+	if !filepath.IsAbs(full) {
+		if abs, errAbs := filepath.Abs(full); errAbs == nil {
+			full = abs
+		}
+	}
+	return full
+}
@@ -0,0 +1,88 @@
+package management
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func postRename(t *testing.T, h *Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v0/management/auth-files/rename", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	h.RenameAuthFile(c)
+	return rec
+}
+
+func TestRenameAuthFile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	manager := coreauth.NewManager(nil, nil, nil)
+	h := &Handler{cfg: &config.Config{AuthDir: dir}, authManager: manager}
+
+	for name, content := range map[string]string{
+		"claude-old.json":   `{"type":"claude","email":"a@example.com"}`,
+		"claude-taken.json": `{"type":"claude","email":"b@example.com"}`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		if err := h.registerAuthFromFile(context.Background(), path, nil); err != nil {
+			t.Fatalf("register %s: %v", name, err)
+		}
+	}
+	old, _ := manager.GetByID("claude-old.json")
+	old.Prefix = "team"
+	if _, err := manager.Update(context.Background(), old); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	if rec := postRename(t, h, `{"name":"claude-old.json","new_name":"../escape.json"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("traversal status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if rec := postRename(t, h, `{"name":"claude-old.json","new_name":"claude-taken.json"}`); rec.Code != http.StatusConflict {
+		t.Fatalf("collision status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec := postRename(t, h, `{"name":"claude-old.json","new_name":"claude-work.json"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("rename status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "claude-old.json")); !os.IsNotExist(err) {
+		t.Fatalf("old file still present: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "claude-work.json")); err != nil {
+		t.Fatalf("new file missing: %v", err)
+	}
+
+	renamed, ok := manager.GetByID("claude-work.json")
+	if !ok {
+		t.Fatalf("renamed auth not registered")
+	}
+	if renamed.FileName != "claude-work.json" || renamed.Attributes["path"] != filepath.Join(dir, "claude-work.json") {
+		t.Fatalf("renamed auth not updated: file=%q path=%q", renamed.FileName, renamed.Attributes["path"])
+	}
+	if renamed.Prefix != "team" || renamed.Disabled {
+		t.Fatalf("renamed auth lost state: prefix=%q disabled=%v", renamed.Prefix, renamed.Disabled)
+	}
+	if _, ok := manager.GetByID("claude-old.json"); ok {
+		t.Fatalf("old auth should be removed from the manager")
+	}
+	for _, auth := range manager.List() {
+		if auth.Disabled {
+			t.Fatalf("stale disabled auth %s left after rename", auth.ID)
+		}
+	}
+}
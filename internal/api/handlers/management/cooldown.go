@@ -0,0 +1,110 @@
+package management
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// cooldownModelEntry describes one model of an auth that is cooling down.
+type cooldownModelEntry struct {
+	Model            string    `json:"model"`
+	Reason           string    `json:"reason,omitempty"`
+	NextRetryAfter   time.Time `json:"next_retry_after"`
+	RemainingSeconds int64     `json:"remaining_seconds"`
+}
+
+// cooldownAuthEntry describes an auth with at least one active cooldown.
+type cooldownAuthEntry struct {
+	ID               string               `json:"id"`
+	Provider         string               `json:"provider"`
+	Label            string               `json:"label,omitempty"`
+	Reason           string               `json:"reason,omitempty"`
+	NextRetryAfter   *time.Time           `json:"next_retry_after,omitempty"`
+	RemainingSeconds int64                `json:"remaining_seconds,omitempty"`
+	Models           []cooldownModelEntry `json:"models,omitempty"`
+}
+
+// GetCooldown returns the global quota cooldown switch and the auths currently cooling down.
+func (h *Handler) GetCooldown(c *gin.Context) {
+	entries := make([]cooldownAuthEntry, 0)
+	if h.authManager != nil {
+		entries = cooledDownAuths(h.authManager.List(), time.Now())
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"disabled":    coreauth.QuotaCooldownDisabled(),
+		"cooled-down": entries,
+	})
+}
+
+// PutCooldown toggles quota cooldown at runtime. Disabling it also lifts the quota
+// cooldowns already in effect, so cooling auths are selectable immediately. The change is
+// not written to the config file; disable-cooling is applied again on the next config
+// reload or restart.
+func (h *Handler) PutCooldown(c *gin.Context) {
+	var body struct {
+		Value *bool `json:"value"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Value == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	coreauth.SetQuotaCooldownDisabled(*body.Value)
+	cleared := 0
+	if *body.Value && h.authManager != nil {
+		cleared = h.authManager.ClearQuotaCooldowns(c.Request.Context())
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "disabled": *body.Value, "cleared": cleared})
+}
+
+// cooledDownAuths lists auths whose retry time, or that of any of their models, is still
+// in the future, sorted by ID.
+func cooledDownAuths(auths []*coreauth.Auth, now time.Time) []cooldownAuthEntry {
+	entries := make([]cooldownAuthEntry, 0)
+	for _, auth := range auths {
+		if auth == nil || auth.Disabled {
+			continue
+		}
+		entry := cooldownAuthEntry{ID: auth.ID, Provider: auth.Provider, Label: auth.Label}
+		if auth.NextRetryAfter.After(now) {
+			next := auth.NextRetryAfter
+			entry.NextRetryAfter = &next
+			entry.RemainingSeconds = remainingSeconds(next, now)
+			entry.Reason = auth.Quota.Reason
+			if entry.Reason == "" && auth.LastError != nil {
+				entry.Reason = auth.LastError.Code
+			}
+		}
+		for model, state := range auth.ModelStates {
+			if state == nil || !state.NextRetryAfter.After(now) {
+				continue
+			}
+			reason := state.Quota.Reason
+			if reason == "" && state.LastError != nil {
+				reason = state.LastError.Code
+			}
+			entry.Models = append(entry.Models, cooldownModelEntry{
+				Model:            model,
+				Reason:           reason,
+				NextRetryAfter:   state.NextRetryAfter,
+				RemainingSeconds: remainingSeconds(state.NextRetryAfter, now),
+			})
+		}
+		if entry.NextRetryAfter == nil && len(entry.Models) == 0 {
+			continue
+		}
+		sort.Slice(entry.Models, func(i, j int) bool { return entry.Models[i].Model < entry.Models[j].Model })
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries
+}
+
+// remainingSeconds rounds the time left until next up to whole seconds.
+func remainingSeconds(next, now time.Time) int64 {
+	remaining := next.Sub(now)
+	return int64((remaining + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,106 @@
+package management
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestCooldownToggleAndList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	previous := coreauth.QuotaCooldownDisabled()
+	t.Cleanup(func() { coreauth.SetQuotaCooldownDisabled(previous) })
+	coreauth.SetQuotaCooldownDisabled(false)
+
+	manager := coreauth.NewManager(nil, nil, nil)
+	now := time.Now()
+	for _, auth := range []*coreauth.Auth{
+		{
+			ID:       "cooling",
+			Provider: "gemini",
+			Status:   coreauth.StatusError,
+			ModelStates: map[string]*coreauth.ModelState{
+				"gemini-2.5-pro": {
+					Unavailable:    true,
+					NextRetryAfter: now.Add(90 * time.Second),
+					Quota:          coreauth.QuotaState{Exceeded: true, Reason: "quota"},
+				},
+				"gemini-2.5-flash": {NextRetryAfter: now.Add(-time.Minute)},
+			},
+		},
+		{ID: "healthy", Provider: "gemini", Status: coreauth.StatusActive},
+	} {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register %s: %v", auth.ID, err)
+		}
+	}
+	h := &Handler{cfg: &config.Config{}, authManager: manager}
+
+	put := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = httptest.NewRequest(http.MethodPut, "/v0/management/cooldown", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		h.PutCooldown(c)
+		return rec
+	}
+	get := func() (bool, []cooldownAuthEntry) {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v0/management/cooldown", nil)
+		h.GetCooldown(c)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET status = %d, body=%s", rec.Code, rec.Body.String())
+		}
+		var resp struct {
+			Disabled   bool                `json:"disabled"`
+			CooledDown []cooldownAuthEntry `json:"cooled-down"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return resp.Disabled, resp.CooledDown
+	}
+
+	disabled, entries := get()
+	if disabled {
+		t.Fatal("cooldown reported disabled before toggling")
+	}
+	if len(entries) != 1 || entries[0].ID != "cooling" {
+		t.Fatalf("cooled-down auths = %+v, want only cooling", entries)
+	}
+	models := entries[0].Models
+	if len(models) != 1 || models[0].Model != "gemini-2.5-pro" || models[0].Reason != "quota" {
+		t.Fatalf("cooled-down models = %+v", models)
+	}
+	if models[0].RemainingSeconds <= 0 || models[0].RemainingSeconds > 90 {
+		t.Fatalf("remaining seconds = %d, want within (0, 90]", models[0].RemainingSeconds)
+	}
+
+	if rec := put(`{"value":true}`); rec.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if !coreauth.QuotaCooldownDisabled() {
+		t.Fatal("PUT did not disable quota cooldown")
+	}
+	if disabled, entries = get(); !disabled {
+		t.Fatal("GET does not report the toggled state")
+	}
+	if len(entries) != 0 {
+		t.Fatalf("disabling cooldown left auths cooling down: %+v", entries)
+	}
+	if rec := put(`{"value":false}`); rec.Code != http.StatusOK || coreauth.QuotaCooldownDisabled() {
+		t.Fatalf("re-enabling cooldown failed: status=%d", rec.Code)
+	}
+	if rec := put(`{}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("PUT without value status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,105 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	geminiAuth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/gemini"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func getAuthStatus(t *testing.T, h *Handler, state string) map[string]string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v0/management/get-auth-status?state="+state, nil)
+	h.GetAuthStatus(c)
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	return body
+}
+
+func TestFinishGeminiCloudAPIEnable_SessionWaitsThenCompletes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := &memoryAuthStore{}
+	h := &Handler{cfg: &config.Config{AuthDir: t.TempDir()}, tokenStore: store}
+	state := "gem-async-enable-test"
+	RegisterOAuthSession(state, "gemini")
+	t.Cleanup(func() { CompleteOAuthSession(state) })
+
+	ts := &geminiAuth.GeminiTokenStorage{ProjectID: "proj-1", Email: "user@example.com"}
+	record := &coreauth.Auth{ID: "gemini-user.json", Provider: "gemini", Storage: ts, Metadata: map[string]any{"checked": false}}
+
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.finishGeminiCloudAPIEnable(context.Background(), state, record, ts, func(context.Context) error {
+			<-release
+			return nil
+		})
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for GetOAuthSessionPhase(state) == "" {
+		if time.Now().After(deadline) {
+			t.Fatal("session never entered the enabling phase")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := getAuthStatus(t, h, state); got["status"] != "wait" || got["phase"] != "enabling_api" {
+		t.Fatalf("status during enablement = %v, want wait/enabling_api", got)
+	}
+
+	close(release)
+	<-done
+
+	if got := getAuthStatus(t, h, state); got["status"] != "ok" {
+		t.Fatalf("status after enablement = %v, want ok", got)
+	}
+	if !ts.Checked {
+		t.Fatal("expected token storage to be marked checked")
+	}
+	saved, _ := store.List(context.Background())
+	if len(saved) != 1 || saved[0].Metadata["checked"] != true {
+		t.Fatalf("expected saved record marked checked, got %+v", saved)
+	}
+}
+
+func TestFinishGeminiCloudAPIEnable_RecordsFailureOnAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := &memoryAuthStore{}
+	h := &Handler{cfg: &config.Config{AuthDir: t.TempDir()}, tokenStore: store}
+	state := "gem-async-enable-failure-test"
+	RegisterOAuthSession(state, "gemini")
+	t.Cleanup(func() { CompleteOAuthSession(state) })
+
+	ts := &geminiAuth.GeminiTokenStorage{ProjectID: "proj-1", Email: "user@example.com"}
+	record := &coreauth.Auth{ID: "gemini-user.json", Provider: "gemini", Storage: ts, Metadata: map[string]any{"checked": false}}
+
+	h.finishGeminiCloudAPIEnable(context.Background(), state, record, ts, func(context.Context) error {
+		return errors.New("permission denied")
+	})
+
+	if got := getAuthStatus(t, h, state); got["status"] != "error" {
+		t.Fatalf("status after failed enablement = %v, want error", got)
+	}
+	if ts.Checked || ts.CheckError != "permission denied" {
+		t.Fatalf("token storage = checked %v, check_error %q; want unchecked with the failure", ts.Checked, ts.CheckError)
+	}
+	saved, _ := store.List(context.Background())
+	if len(saved) != 1 || saved[0].Status != coreauth.StatusError || saved[0].Metadata["check_error"] != "permission denied" {
+		t.Fatalf("expected saved record with the enablement failure, got %+v", saved)
+	}
+}
@@ -0,0 +1,194 @@
+package management
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	geminiAuth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/gemini"
+)
+
+// sequenceTransport replays scripted outcomes in order; the last one repeats.
+type sequenceTransport struct {
+	statuses []int
+	errs     []error
+	calls    int32
+}
+
+func (s *sequenceTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	idx := int(atomic.AddInt32(&s.calls, 1)) - 1
+	if idx >= len(s.statuses) {
+		idx = len(s.statuses) - 1
+	}
+	if idx < len(s.errs) && s.errs[idx] != nil {
+		return nil, s.errs[idx]
+	}
+	if s.statuses[idx] == http.StatusOK {
+		return stubResponse(http.StatusOK, `{"projects":[{"projectId":"p1"}]}`), nil
+	}
+	return stubResponse(s.statuses[idx], `{"error":"scripted"}`), nil
+}
+
+func useFastGeminiRetries(t *testing.T) {
+	t.Helper()
+	prevDelay := geminiAuth.RetryBaseDelay
+	geminiAuth.RetryBaseDelay = time.Millisecond
+	t.Cleanup(func() { geminiAuth.RetryBaseDelay = prevDelay })
+}
+
+func TestFetchGCPProjects_RetriesServiceUnavailable(t *testing.T) {
+	useFastGeminiRetries(t)
+	stub := &sequenceTransport{statuses: []int{http.StatusServiceUnavailable, http.StatusOK}}
+
+	projects, err := fetchGCPProjects(context.Background(), &http.Client{Transport: stub})
+	if err != nil {
+		t.Fatalf("fetchGCPProjects error: %v", err)
+	}
+	if len(projects) != 1 || projects[0].ProjectID != "p1" {
+		t.Fatalf("projects = %+v, want [p1]", projects)
+	}
+	if stub.calls != 2 {
+		t.Fatalf("calls = %d, want 2", stub.calls)
+	}
+}
+
+func TestFetchGCPProjects_FailsFastOnUnauthorized(t *testing.T) {
+	useFastGeminiRetries(t)
+	stub := &sequenceTransport{statuses: []int{http.StatusUnauthorized, http.StatusOK}}
+
+	_, err := fetchGCPProjects(context.Background(), &http.Client{Transport: stub})
+	if err == nil || !strings.Contains(err.Error(), "status 401") {
+		t.Fatalf("error = %v, want status 401", err)
+	}
+	if stub.calls != 1 {
+		t.Fatalf("calls = %d, want 1", stub.calls)
+	}
+}
+
+func TestCallGeminiCLI_RetriesConnectionReset(t *testing.T) {
+	useFastGeminiRetries(t)
+	stub := &sequenceTransport{
+		statuses: []int{0, http.StatusBadGateway, http.StatusOK},
+		errs:     []error{syscall.ECONNRESET},
+	}
+
+	if err := callGeminiCLI(context.Background(), &http.Client{Transport: stub}, "loadCodeAssist", map[string]string{}, nil); err != nil {
+		t.Fatalf("callGeminiCLI error: %v", err)
+	}
+	if stub.calls != 3 {
+		t.Fatalf("calls = %d, want 3", stub.calls)
+	}
+}
+
+func TestCallGeminiCLI_GivesUpAfterMaxRetries(t *testing.T) {
+	useFastGeminiRetries(t)
+	stub := &sequenceTransport{statuses: []int{http.StatusInternalServerError}}
+
+	err := callGeminiCLI(context.Background(), &http.Client{Transport: stub}, "loadCodeAssist", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "status 500") {
+		t.Fatalf("error = %v, want status 500", err)
+	}
+	if want := int32(geminiAuth.MaxRetries + 1); stub.calls != want {
+		t.Fatalf("calls = %d, want %d", stub.calls, want)
+	}
+}
+
+func TestDoWithRetry_StopsOnCanceledContext(t *testing.T) {
+	useFastGeminiRetries(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stub := &sequenceTransport{statuses: []int{0}, errs: []error{context.Canceled}}
+
+	_, err := geminiAuth.DoWithRetry(ctx, &http.Client{Transport: stub}, "test", func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+	if stub.calls > 1 {
+		t.Fatalf("calls = %d, want at most 1", stub.calls)
+	}
+}
+
+// retryAfterTransport answers 429 with Retry-After: 0 once, then 200.
+type retryAfterTransport struct{ calls int32 }
+
+func (r *retryAfterTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	if atomic.AddInt32(&r.calls, 1) == 1 {
+		resp := stubResponse(http.StatusTooManyRequests, `{"error":"slow down"}`)
+		resp.Header.Set("Retry-After", "0")
+		return resp, nil
+	}
+	return stubResponse(http.StatusOK, `{}`), nil
+}
+
+func TestDoWithRetry_HonorsRetryAfter(t *testing.T) {
+	// A backoff this long would time the test out; the Retry-After hint must replace it.
+	prevDelay := geminiAuth.RetryBaseDelay
+	geminiAuth.RetryBaseDelay = time.Hour
+	t.Cleanup(func() { geminiAuth.RetryBaseDelay = prevDelay })
+	stub := &retryAfterTransport{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := callGeminiCLI(ctx, &http.Client{Transport: stub}, "loadCodeAssist", nil, nil); err != nil {
+		t.Fatalf("callGeminiCLI error: %v", err)
+	}
+	if stub.calls != 2 {
+		t.Fatalf("calls = %d, want 2", stub.calls)
+	}
+}
+
+// urlRecorder answers 200 and records the requested URLs.
+type urlRecorder struct{ urls []string }
+
+func (r *urlRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.urls = append(r.urls, req.URL.String())
+	return stubResponse(http.StatusOK, `{}`), nil
+}
+
+func TestCallGeminiCLI_UsesConfiguredEndpoint(t *testing.T) {
+	rec := &urlRecorder{}
+	client := &http.Client{Transport: rec}
+
+	ctx := withGeminiCLIEndpoint(context.Background(), "https://eu-cloudcode.example.com")
+	if err := callGeminiCLI(ctx, client, "loadCodeAssist", nil, nil); err != nil {
+		t.Fatalf("callGeminiCLI error: %v", err)
+	}
+	if err := callGeminiCLI(context.Background(), client, "loadCodeAssist", nil, nil); err != nil {
+		t.Fatalf("callGeminiCLI error: %v", err)
+	}
+	want := []string{
+		"https://eu-cloudcode.example.com/" + geminiCLIVersion + ":loadCodeAssist",
+		geminiCLIEndpoint + "/" + geminiCLIVersion + ":loadCodeAssist",
+	}
+	if strings.Join(rec.urls, " ") != strings.Join(want, " ") {
+		t.Fatalf("urls = %v, want %v", rec.urls, want)
+	}
+}
+
+func TestCallGeminiCLI_OnboardUserRetriesOnlyConnectErrors(t *testing.T) {
+	useFastGeminiRetries(t)
+	reset := &sequenceTransport{statuses: []int{0, http.StatusOK}, errs: []error{syscall.ECONNRESET}}
+	if err := callGeminiCLI(context.Background(), &http.Client{Transport: reset}, "onboardUser", nil, nil); err == nil {
+		t.Fatal("expected connection reset to fail onboardUser")
+	}
+	if reset.calls != 1 {
+		t.Fatalf("calls after reset = %d, want 1", reset.calls)
+	}
+
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	refused := &sequenceTransport{statuses: []int{0, http.StatusOK}, errs: []error{dialErr}}
+	if err := callGeminiCLI(context.Background(), &http.Client{Transport: refused}, "onboardUser", nil, nil); err != nil {
+		t.Fatalf("callGeminiCLI error: %v", err)
+	}
+	if refused.calls != 2 {
+		t.Fatalf("calls after refused dial = %d, want 2", refused.calls)
+	}
+}
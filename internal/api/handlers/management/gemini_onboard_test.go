@@ -0,0 +1,133 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	geminiAuth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/gemini"
+)
+
+// onboardStubTransport serves the project list and Gemini CLI setup endpoints.
+type onboardStubTransport struct {
+	projects  []string
+	failing   map[string]bool
+	rateLimit map[string]int
+
+	mu          sync.Mutex
+	inFlight    int32
+	maxInFlight int32
+	onboarded   map[string]int
+}
+
+func (s *onboardStubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.Contains(req.URL.Host, "cloudresourcemanager") {
+		items := make([]map[string]string, 0, len(s.projects))
+		for _, id := range s.projects {
+			items = append(items, map[string]string{"projectId": id})
+		}
+		raw, _ := json.Marshal(map[string]any{"projects": items})
+		return stubResponse(http.StatusOK, string(raw)), nil
+	}
+
+	var body map[string]any
+	if req.Body != nil {
+		_ = json.NewDecoder(req.Body).Decode(&body)
+	}
+	project, _ := body["cloudaicompanionProject"].(string)
+
+	if strings.HasSuffix(req.URL.Path, ":loadCodeAssist") {
+		return stubResponse(http.StatusOK, `{}`), nil
+	}
+
+	current := atomic.AddInt32(&s.inFlight, 1)
+	defer atomic.AddInt32(&s.inFlight, -1)
+	s.mu.Lock()
+	if current > s.maxInFlight {
+		s.maxInFlight = current
+	}
+	s.onboarded[project]++
+	calls := s.onboarded[project]
+	s.mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+
+	if calls <= s.rateLimit[project] {
+		return stubResponse(http.StatusTooManyRequests, `{"error":"rate limited"}`), nil
+	}
+	if s.failing[project] {
+		return stubResponse(http.StatusInternalServerError, `{"error":"boom"}`), nil
+	}
+	raw, _ := json.Marshal(map[string]any{"done": true, "response": map[string]any{"cloudaicompanionProject": project}})
+	return stubResponse(http.StatusOK, string(raw)), nil
+}
+
+func stubResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestOnboardAllGeminiProjects_BoundedConcurrency(t *testing.T) {
+	prevDelay := geminiAuth.RetryBaseDelay
+	geminiAuth.RetryBaseDelay = time.Millisecond
+	t.Cleanup(func() { geminiAuth.RetryBaseDelay = prevDelay })
+
+	stub := &onboardStubTransport{
+		projects:  []string{"p1", "p2", "p3", "p4", "p5", "p1", ""},
+		rateLimit: map[string]int{"p3": 1},
+		onboarded: make(map[string]int),
+	}
+	client := &http.Client{Transport: stub}
+	storage := &geminiAuth.GeminiTokenStorage{}
+
+	projects, err := onboardAllGeminiProjects(context.Background(), client, storage, 2)
+	if err != nil {
+		t.Fatalf("onboardAllGeminiProjects error: %v", err)
+	}
+	if got := strings.Join(projects, ","); got != "p1,p2,p3,p4,p5" {
+		t.Fatalf("projects = %s, want p1,p2,p3,p4,p5", got)
+	}
+	if stub.maxInFlight > 2 {
+		t.Fatalf("max concurrent setups = %d, want <= 2", stub.maxInFlight)
+	}
+	if stub.onboarded["p1"] != 1 {
+		t.Fatalf("duplicate project onboarded %d times, want 1", stub.onboarded["p1"])
+	}
+	if stub.onboarded["p3"] != 2 {
+		t.Fatalf("rate limited project called %d times, want 2", stub.onboarded["p3"])
+	}
+}
+
+func TestOnboardAllGeminiProjects_AggregatesFailures(t *testing.T) {
+	prevDelay := geminiAuth.RetryBaseDelay
+	geminiAuth.RetryBaseDelay = time.Millisecond
+	t.Cleanup(func() { geminiAuth.RetryBaseDelay = prevDelay })
+
+	stub := &onboardStubTransport{
+		projects:  []string{"ok-1", "bad-1", "ok-2", "bad-2"},
+		failing:   map[string]bool{"bad-1": true, "bad-2": true},
+		onboarded: make(map[string]int),
+	}
+	client := &http.Client{Transport: stub}
+
+	projects, err := onboardAllGeminiProjects(context.Background(), client, &geminiAuth.GeminiTokenStorage{}, 4)
+	if err == nil {
+		t.Fatal("expected aggregated error")
+	}
+	for _, want := range []string{"onboard project bad-1", "onboard project bad-2"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error %q does not mention %q", err.Error(), want)
+		}
+	}
+	if got := strings.Join(projects, ","); got != "ok-1,ok-2" {
+		t.Fatalf("activated projects = %s, want ok-1,ok-2", got)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/buildinfo"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	"golang.org/x/crypto/bcrypt"
@@ -27,6 +28,9 @@ type attemptInfo struct {
 	lastActivity time.Time // track last activity for cleanup
 }
 
+// managementRoleContextKey stores the authenticated management role on the gin context.
+const managementRoleContextKey = "managementRole"
+
 // attemptCleanupInterval controls how often stale IP entries are purged
 const attemptCleanupInterval = 1 * time.Hour
 
@@ -47,6 +51,11 @@ type Handler struct {
 	allowRemoteOverride bool
 	envSecret           string
 	logDir              string
+	// assetUpdater overrides the process-wide management asset updater; nil uses it.
+	assetUpdater assetUpdater
+	auditLog     auditLog
+	// modelRefresher re-registers an auth's models; see SetModelRefresher.
+	modelRefresher ModelRefresher
 }
 
 // NewHandler creates a new management handler instance.
@@ -112,6 +121,9 @@ func (h *Handler) SetAuthManager(manager *coreauth.Manager) { h.authManager = ma
 // SetUsageStatistics allows replacing the usage statistics reference.
 func (h *Handler) SetUsageStatistics(stats *usage.RequestStatistics) { h.usageStats = stats }
 
+// SetModelRefresher installs the callback used to re-probe an auth's upstream model list.
+func (h *Handler) SetModelRefresher(refresher ModelRefresher) { h.modelRefresher = refresher }
+
 // SetLocalPassword configures the runtime-local password accepted for localhost requests.
 func (h *Handler) SetLocalPassword(password string) { h.localPassword = password }
 
@@ -139,6 +151,9 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 		c.Header("X-CPA-VERSION", buildinfo.Version)
 		c.Header("X-CPA-COMMIT", buildinfo.Commit)
 		c.Header("X-CPA-BUILD-DATE", buildinfo.BuildDate)
+		if identity := sdkaccess.ClientCertIdentity(c.Request); identity != "" {
+			c.Set("clientCertIdentity", identity)
+		}
 
 		clientIP := c.ClientIP()
 		localClient := clientIP == "127.0.0.1" || clientIP == "::1"
@@ -201,12 +216,14 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 			return
 		}
 
-		// Accept either Authorization: Bearer <key> or X-Management-Key
+		// Accept Authorization: Bearer <key>, Basic auth (key as password) or X-Management-Key
 		var provided string
 		if ah := c.GetHeader("Authorization"); ah != "" {
 			parts := strings.SplitN(ah, " ", 2)
 			if len(parts) == 2 && strings.ToLower(parts[0]) == "bearer" {
 				provided = parts[1]
+			} else if _, password, ok := c.Request.BasicAuth(); ok {
+				provided = password
 			} else {
 				provided = ah
 			}
@@ -226,6 +243,7 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 		if localClient {
 			if lp := h.localPassword; lp != "" {
 				if subtle.ConstantTimeCompare([]byte(provided), []byte(lp)) == 1 {
+					setManagementCaller(c, auditCallerLocalPassword, provided, config.ManagementRoleAdmin)
 					c.Next()
 					return
 				}
@@ -241,16 +259,21 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 				}
 				h.attemptsMu.Unlock()
 			}
+			setManagementCaller(c, auditCallerEnvSecret, provided, config.ManagementRoleAdmin)
 			c.Next()
 			return
 		}
 
+		role, caller := config.ManagementRoleAdmin, auditCallerSecretKey
 		if secretHash == "" || bcrypt.CompareHashAndPassword([]byte(secretHash), []byte(provided)) != nil {
-			if !localClient {
-				fail()
+			role, caller = matchManagementToken(cfg, provided), auditCallerAccessToken
+			if role == "" {
+				if !localClient {
+					fail()
+				}
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid management key"})
+				return
 			}
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid management key"})
-			return
 		}
 
 		if !localClient {
@@ -262,10 +285,85 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 			h.attemptsMu.Unlock()
 		}
 
+		setManagementCaller(c, caller, provided, role)
 		c.Next()
 	}
 }
 
+// readOnlyRoutes lists the management routes a read-only token may call. Everything else,
+// including GET routes that return raw config or start OAuth flows, requires the admin role.
+// Handlers redact credentials from what they return to read-only callers.
+var readOnlyRoutes = map[string]bool{
+	"/usage":                                    true,
+	"/usage/export":                             true,
+	"/latest-version":                           true,
+	"/updater/status":                           true,
+	"/debug":                                    true,
+	"/logging-to-file":                          true,
+	"/logs-max-total-size-mb":                   true,
+	"/error-logs-max-files":                     true,
+	"/usage-statistics-enabled":                 true,
+	"/cooldown":                                 true,
+	"/providers/:name/enabled":                  true,
+	"/quota-exceeded/switch-project":            true,
+	"/quota-exceeded/switch-preview-model":      true,
+	"/request-log":                              true,
+	"/request-retry":                            true,
+	"/max-retry-interval":                       true,
+	"/force-model-prefix":                       true,
+	"/routing/strategy":                         true,
+	"/auth-files":                               true,
+	"/auth-files/models":                        true,
+	"/auth-files/download":                      true,
+	"/auth-files/export":                        true,
+	"/model-definitions/:channel":               true,
+	"/models/:id/thinking":                      true,
+	"/ampcode/restrict-management-to-localhost": true,
+}
+
+// RoleMiddleware restricts non-admin management tokens to the read routes in
+// readOnlyRoutes, so mutating handlers such as UploadAuthFile, DeleteAuthFile and
+// PatchAuthFile* require the admin role. It must run after Middleware.
+func (h *Handler) RoleMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString(managementRoleContextKey) == config.ManagementRoleAdmin {
+			c.Next()
+			return
+		}
+		route := strings.TrimPrefix(c.FullPath(), "/v0/management")
+		if (c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead) && readOnlyRoutes[route] {
+			c.Next()
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin role required"})
+	}
+}
+
+// isReadOnlyCaller reports whether the request was authenticated with a read-only token,
+// whose responses must not include credentials.
+func isReadOnlyCaller(c *gin.Context) bool {
+	role := c.GetString(managementRoleContextKey)
+	return role != "" && role != config.ManagementRoleAdmin
+}
+
+// matchManagementToken returns the role bound to the provided token, or "" when no
+// configured access token matches.
+func matchManagementToken(cfg *config.Config, provided string) string {
+	if cfg == nil {
+		return ""
+	}
+	for _, entry := range cfg.RemoteManagement.AccessTokens {
+		if entry.Token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(entry.Token)) != 1 {
+			continue
+		}
+		if entry.Role == config.ManagementRoleAdmin {
+			return config.ManagementRoleAdmin
+		}
+		return config.ManagementRoleReadOnly
+	}
+	return ""
+}
+
 // persist saves the current in-memory config to disk.
 func (h *Handler) persist(c *gin.Context) bool {
 	h.mu.Lock()
@@ -0,0 +1,141 @@
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newRoleTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("admin-secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash secret: %v", err)
+	}
+	cfg := &config.Config{
+		AuthDir: t.TempDir(),
+		RemoteManagement: config.RemoteManagement{
+			AllowRemote: true,
+			SecretKey:   string(hash),
+			AccessTokens: []config.ManagementToken{
+				{Token: "viewer-token", Role: config.ManagementRoleReadOnly},
+				{Token: "ops-token", Role: config.ManagementRoleAdmin},
+			},
+		},
+	}
+	h := NewHandler(cfg, "", nil)
+	credential := `{"type":"claude","email":"a@example.com","access_token":"secret-token"}`
+	if err = os.WriteFile(filepath.Join(cfg.AuthDir, "claude-a.json"), []byte(credential), 0o600); err != nil {
+		t.Fatalf("write auth file: %v", err)
+	}
+
+	r := gin.New()
+	mgmt := r.Group("/v0/management")
+	mgmt.Use(h.Middleware(), h.RoleMiddleware())
+	mgmt.GET("/auth-files", h.ListAuthFiles)
+	mgmt.DELETE("/auth-files", h.DeleteAuthFile)
+	mgmt.GET("/auth-files/download", h.DownloadAuthFile)
+	mgmt.GET("/config.yaml", h.GetConfigYAML)
+	mgmt.GET("/codex-auth-url", h.RequestCodexToken)
+	return r
+}
+
+func TestManagementRoles(t *testing.T) {
+	r := newRoleTestRouter(t)
+
+	cases := []struct {
+		name      string
+		method    string
+		authorize func(*http.Request)
+		want      int
+	}{
+		{
+			name:      "read-only list allowed",
+			method:    http.MethodGet,
+			authorize: func(req *http.Request) { req.Header.Set("Authorization", "Bearer viewer-token") },
+			want:      http.StatusOK,
+		},
+		{
+			name:      "read-only delete rejected",
+			method:    http.MethodDelete,
+			authorize: func(req *http.Request) { req.Header.Set("Authorization", "Bearer viewer-token") },
+			want:      http.StatusForbidden,
+		},
+		{
+			name:      "read-only basic auth delete rejected",
+			method:    http.MethodDelete,
+			authorize: func(req *http.Request) { req.SetBasicAuth("viewer", "viewer-token") },
+			want:      http.StatusForbidden,
+		},
+		{
+			// No auth manager is configured, so an authorized delete reaches the handler.
+			name:      "admin token delete allowed",
+			method:    http.MethodDelete,
+			authorize: func(req *http.Request) { req.Header.Set("X-Management-Key", "ops-token") },
+			want:      http.StatusServiceUnavailable,
+		},
+		{
+			name:      "secret key basic auth delete allowed",
+			method:    http.MethodDelete,
+			authorize: func(req *http.Request) { req.SetBasicAuth("admin", "admin-secret") },
+			want:      http.StatusServiceUnavailable,
+		},
+		{
+			name:      "unknown token rejected",
+			method:    http.MethodGet,
+			authorize: func(req *http.Request) { req.Header.Set("Authorization", "Bearer nope") },
+			want:      http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/v0/management/auth-files?name=a.json", nil)
+			tc.authorize(req)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Fatalf("status = %d, want %d, body=%s", rec.Code, tc.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestManagementRoles_ReadOnlyAllowlist(t *testing.T) {
+	r := newRoleTestRouter(t)
+	get := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Read routes outside the allowlist expose secrets or change state.
+	for _, path := range []string{"/v0/management/config.yaml", "/v0/management/codex-auth-url"} {
+		if rec := get(path, "viewer-token"); rec.Code != http.StatusForbidden {
+			t.Fatalf("read-only GET %s status = %d, want %d", path, rec.Code, http.StatusForbidden)
+		}
+	}
+
+	rec := get("/v0/management/auth-files/download?name=claude-a.json", "viewer-token")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("read-only download status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if body := rec.Body.String(); strings.Contains(body, "secret-token") || !strings.Contains(body, redactedValue) {
+		t.Fatalf("read-only download not redacted: %s", body)
+	}
+	rec = get("/v0/management/auth-files/download?name=claude-a.json", "ops-token")
+	if !strings.Contains(rec.Body.String(), "secret-token") {
+		t.Fatalf("admin download redacted: %s", rec.Body.String())
+	}
+}
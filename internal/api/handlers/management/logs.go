@@ -22,7 +22,8 @@ const (
 	logScannerMaxBuffer     = 8 * 1024 * 1024
 )
 
-// GetLogs returns log lines with optional incremental loading.
+// GetLogs returns log lines with optional incremental loading. With a file query
+// parameter it serves a single log file instead; see getLogFile.
 func (h *Handler) GetLogs(c *gin.Context) {
 	if h == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "handler unavailable"})
@@ -32,6 +33,10 @@ func (h *Handler) GetLogs(c *gin.Context) {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "configuration unavailable"})
 		return
 	}
+	if _, ok := c.GetQuery("file"); ok {
+		h.getLogFile(c)
+		return
+	}
 	if !h.cfg.LoggingToFile {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "logging to file disabled"})
 		return
@@ -0,0 +1,185 @@
+package management
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+)
+
+const defaultLogTailLines = 200
+
+// logSecretPatterns match credentials written into log lines; group 1 is kept and group 2
+// is masked.
+var logSecretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(\bbearer\s+)([^\s"',]+)`),
+	regexp.MustCompile(`(?i)((?:token|secret|password|api[-_]?key|\bkey)"?\s*[:=]\s*"?)([^\s"',&}]+)`),
+}
+
+// logFileInfo describes one file served by the single-file logs endpoint.
+type logFileInfo struct {
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	Modified int64  `json:"modified"`
+}
+
+// getLogFile serves GET /logs?file=NAME from the log directory. An empty file lists the
+// available log files; otherwise the last tail lines (default 200) are returned, or the
+// whole file as an attachment with download=true. Credentials are masked either way.
+func (h *Handler) getLogFile(c *gin.Context) {
+	dir := h.logDirectory()
+	if strings.TrimSpace(dir) == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "log directory not configured"})
+		return
+	}
+
+	name := strings.TrimSpace(c.Query("file"))
+	if name == "" {
+		files, err := listLogFiles(dir)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to list log files: %v", err)})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"files": files})
+		return
+	}
+	if strings.ContainsAny(name, "/\\") || name == "." || name == ".." || !isServableLogFile(name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid log file name"})
+		return
+	}
+
+	dirAbs, errAbs := filepath.Abs(dir)
+	if errAbs != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to resolve log directory: %v", errAbs)})
+		return
+	}
+	fullPath := filepath.Clean(filepath.Join(dirAbs, name))
+	if !strings.HasPrefix(fullPath, dirAbs+string(os.PathSeparator)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid log file path"})
+		return
+	}
+	info, errStat := os.Stat(fullPath)
+	if errStat != nil {
+		if os.IsNotExist(errStat) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "log file not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to read log file: %v", errStat)})
+		return
+	}
+	if info.IsDir() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid log file"})
+		return
+	}
+
+	if download, _ := strconv.ParseBool(c.Query("download")); download {
+		downloadLogFile(c, fullPath, name)
+		return
+	}
+
+	tail := defaultLogTailLines
+	if raw := c.Query("tail"); raw != "" {
+		limit, errLimit := parseLimit(raw)
+		if errLimit != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid tail: %v", errLimit)})
+			return
+		}
+		tail = limit
+	}
+	acc := newLogAccumulator(0, tail)
+	if errRead := acc.consumeFile(fullPath); errRead != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to read log file %s: %v", name, errRead)})
+		return
+	}
+	lines, total, _ := acc.result()
+	for i := range lines {
+		lines[i] = redactLogLine(lines[i])
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"file":       name,
+		"lines":      lines,
+		"line-count": total,
+	})
+}
+
+// downloadLogFile streams a redacted copy of the log file as an attachment.
+func downloadLogFile(c *gin.Context, path, name string) {
+	file, err := os.Open(path)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to open log file: %v", err)})
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Status(http.StatusOK)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, logScannerInitialBuffer), logScannerMaxBuffer)
+	for scanner.Scan() {
+		if _, errWrite := fmt.Fprintln(c.Writer, redactLogLine(scanner.Text())); errWrite != nil {
+			return
+		}
+	}
+}
+
+// listLogFiles returns the servable log files in dir, newest first.
+func listLogFiles(dir string) ([]logFileInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []logFileInfo{}, nil
+		}
+		return nil, err
+	}
+	files := make([]logFileInfo, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !isServableLogFile(entry.Name()) {
+			continue
+		}
+		info, errInfo := entry.Info()
+		if errInfo != nil {
+			return nil, errInfo
+		}
+		files = append(files, logFileInfo{Name: entry.Name(), Size: info.Size(), Modified: info.ModTime().Unix()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Modified > files[j].Modified })
+	return files, nil
+}
+
+// isServableLogFile reports whether name is a plain-text log: *.log files and numbered
+// rotations of main.log. Compressed rotations are skipped because they cannot be redacted.
+func isServableLogFile(name string) bool {
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	if strings.HasSuffix(name, ".log") {
+		return true
+	}
+	_, ok := numericRotationOrder(name)
+	return ok
+}
+
+// redactLogLine masks bearer tokens, API keys and other secrets in one log line.
+func redactLogLine(line string) string {
+	for _, pattern := range logSecretPatterns {
+		line = pattern.ReplaceAllStringFunc(line, func(match string) string {
+			groups := pattern.FindStringSubmatch(match)
+			if len(groups) < 3 {
+				return match
+			}
+			return groups[1] + util.HideAPIKey(groups[2])
+		})
+	}
+	return line
+}
@@ -0,0 +1,57 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+)
+
+// modelThinkingEntry is one provider's resolution, with the validation error a request
+// would be rejected with.
+type modelThinkingEntry struct {
+	*thinking.Resolution
+	Error string `json:"error,omitempty"`
+}
+
+// GetModelThinking returns the effective thinking configuration of a model.
+// The model id may carry a thinking suffix (e.g. "gpt-5(high)"); ?suffix= supplies one
+// when it does not. Every provider serving the model is resolved unless ?provider= selects one.
+func (h *Handler) GetModelThinking(c *gin.Context) {
+	model := strings.TrimSpace(c.Param("id"))
+	if model == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "model id is required"})
+		return
+	}
+	if suffix := strings.TrimSpace(c.Query("suffix")); suffix != "" && !thinking.ParseSuffix(model).HasSuffix {
+		model += "(" + suffix + ")"
+	}
+	baseModel := thinking.ParseSuffix(model).ModelName
+
+	var providers []string
+	if provider := strings.ToLower(strings.TrimSpace(c.Query("provider"))); provider != "" {
+		providers = []string{provider}
+	} else {
+		providers = registry.GetGlobalRegistry().GetModelProviders(baseModel)
+	}
+	if len(providers) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "model not found", "model": baseModel})
+		return
+	}
+
+	entries := make([]modelThinkingEntry, 0, len(providers))
+	for _, provider := range providers {
+		res, err := thinking.ResolveThinking(model, provider)
+		entry := modelThinkingEntry{Resolution: res}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		entries = append(entries, entry)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"model":     baseModel,
+		"providers": entries,
+	})
+}
@@ -0,0 +1,80 @@
+package management
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/thinking/provider/codex"
+)
+
+func TestGetModelThinking_ResolvesSuffix(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reg := registry.GetGlobalRegistry()
+	const clientID = "model-thinking-test-client"
+	reg.RegisterClient(clientID, "codex", []*registry.ModelInfo{{
+		ID:       "level-model",
+		Thinking: &registry.ThinkingSupport{Levels: []string{"minimal", "low", "medium", "high"}},
+	}})
+	t.Cleanup(func() { reg.UnregisterClient(clientID) })
+
+	h := &Handler{}
+	router := gin.New()
+	router.GET("/v0/management/models/:id/thinking", h.GetModelThinking)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v0/management/models/"+url.PathEscape("level-model(none)")+"/thinking", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+
+	var body struct {
+		Model     string `json:"model"`
+		Providers []struct {
+			Provider string `json:"provider"`
+			Source   string `json:"source"`
+			Thinking struct {
+				Levels []string `json:"levels"`
+			} `json:"thinking"`
+			Requested struct {
+				Mode string `json:"mode"`
+			} `json:"requested"`
+			Resolved struct {
+				Mode  string `json:"mode"`
+				Level string `json:"level"`
+			} `json:"resolved"`
+			Error string `json:"error"`
+		} `json:"providers"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Model != "level-model" || len(body.Providers) != 1 {
+		t.Fatalf("unexpected response: %s", rec.Body.String())
+	}
+	got := body.Providers[0]
+	if got.Provider != "codex" || got.Source != "suffix" || got.Requested.Mode != "none" || len(got.Thinking.Levels) != 4 {
+		t.Fatalf("unexpected resolution: %s", rec.Body.String())
+	}
+	if got.Error != "" || got.Resolved.Mode != "level" || got.Resolved.Level != "minimal" {
+		t.Fatalf("resolved = %+v (error %q), want level minimal", got.Resolved, got.Error)
+	}
+}
+
+func TestGetModelThinking_UnknownModel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/v0/management/models/:id/thinking", (&Handler{}).GetModelThinking)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v0/management/models/no-such-model/thinking", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", rec.Code)
+	}
+}
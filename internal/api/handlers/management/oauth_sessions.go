@@ -23,8 +23,11 @@ var (
 )
 
 type oauthSession struct {
-	Provider  string
-	Status    string
+	Provider string
+	Status   string
+	// Phase describes post-login work still in progress while the session is pending,
+	// e.g. "enabling_api". Empty while waiting for the OAuth callback.
+	Phase     string
 	CreatedAt time.Time
 	ExpiresAt time.Time
 }
@@ -97,6 +100,26 @@ func (s *oauthSessionStore) SetError(state, message string) {
 	s.sessions[state] = session
 }
 
+func (s *oauthSessionStore) SetPhase(state, phase string) {
+	state = strings.TrimSpace(state)
+	if state == "" {
+		return
+	}
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purgeExpiredLocked(now)
+	session, ok := s.sessions[state]
+	if !ok {
+		return
+	}
+	session.Phase = strings.TrimSpace(phase)
+	session.ExpiresAt = now.Add(s.ttl)
+	s.sessions[state] = session
+}
+
 func (s *oauthSessionStore) Complete(state string) {
 	state = strings.TrimSpace(state)
 	if state == "" {
@@ -172,6 +195,8 @@ func RegisterOAuthSession(state, provider string) { oauthSessions.Register(state
 
 func SetOAuthSessionError(state, message string) { oauthSessions.SetError(state, message) }
 
+func SetOAuthSessionPhase(state, phase string) { oauthSessions.SetPhase(state, phase) }
+
 func CompleteOAuthSession(state string) { oauthSessions.Complete(state) }
 
 func CompleteOAuthSessionsByProvider(provider string) int {
@@ -186,6 +211,14 @@ func GetOAuthSession(state string) (provider string, status string, ok bool) {
 	return session.Provider, session.Status, true
 }
 
+func GetOAuthSessionPhase(state string) string {
+	session, ok := oauthSessions.Get(state)
+	if !ok {
+		return ""
+	}
+	return session.Phase
+}
+
 func IsOAuthSessionPending(state, provider string) bool {
 	return oauthSessions.IsPending(state, provider)
 }
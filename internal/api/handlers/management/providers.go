@@ -0,0 +1,45 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetProviderEnabled reports whether a provider is enabled for dispatch.
+func (h *Handler) GetProviderEnabled(c *gin.Context) {
+	name := strings.ToLower(strings.TrimSpace(c.Param("name")))
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing provider"})
+		return
+	}
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"provider": name, "enabled": h.authManager.ProviderEnabled(name)})
+}
+
+// PutProviderEnabled enables or disables every auth of a provider without deleting the
+// credentials. The switch is runtime-only and resets on restart.
+func (h *Handler) PutProviderEnabled(c *gin.Context) {
+	name := strings.ToLower(strings.TrimSpace(c.Param("name")))
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing provider"})
+		return
+	}
+	var body struct {
+		Value *bool `json:"value"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Value == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	h.authManager.SetProviderEnabled(name, *body.Value)
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "provider": name, "enabled": *body.Value})
+}
@@ -0,0 +1,63 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// routeExplainRequest is a sample request to trace through routing. Only headers that
+// influence routing (X-Force-Provider, X-Force-Auth) are considered.
+type routeExplainRequest struct {
+	Model   string            `json:"model"`
+	Headers map[string]string `json:"headers"`
+}
+
+// PostRouteExplain answers "why did this request go to account X?" by tracing the routing
+// decision for a sample request: the resolved model, every auth of the serving providers with
+// the reason it is or is not eligible, and the auth that would be selected. Nothing is sent
+// upstream.
+func (h *Handler) PostRouteExplain(c *gin.Context) {
+	var body routeExplainRequest
+	if err := c.ShouldBindJSON(&body); err != nil || strings.TrimSpace(body.Model) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body: model is required"})
+		return
+	}
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	header := http.Header{}
+	for name, value := range body.Headers {
+		header.Set(name, value)
+	}
+
+	requested := strings.TrimSpace(body.Model)
+	// Resolve and validate exactly as dispatch does, so the trace cannot drift from it.
+	var sdkCfg *sdkconfig.SDKConfig
+	if h.cfg != nil {
+		sdkCfg = &h.cfg.SDKConfig
+	}
+	resolver := handlers.NewBaseAPIHandlers(sdkCfg, h.authManager)
+	providers, resolved, errMsg := resolver.ResolveModel(requested)
+	if errMsg == nil {
+		providers, errMsg = resolver.ValidateForcedTarget(providers, resolved, header.Get(handlers.ForceProviderHeader), header.Get(handlers.ForceAuthHeader))
+	}
+	if errMsg != nil {
+		c.Data(errMsg.StatusCode, "application/json", handlers.BuildErrorResponseBody(errMsg.StatusCode, errMsg.Error.Error()))
+		return
+	}
+	baseModel := strings.TrimSpace(thinking.ParseSuffix(resolved).ModelName)
+
+	explanation := h.authManager.ExplainRoute(c.Request.Context(), providers, resolved, strings.TrimSpace(header.Get(handlers.ForceAuthHeader)))
+	c.JSON(http.StatusOK, gin.H{
+		"requested-model": requested,
+		"resolved-model":  resolved,
+		"base-model":      baseModel,
+		"route":           explanation,
+	})
+}
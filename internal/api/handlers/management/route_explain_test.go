@@ -0,0 +1,148 @@
+package management
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// countingExecutor counts every call so the test can assert nothing is sent upstream.
+type countingExecutor struct {
+	calls int32
+}
+
+func (e *countingExecutor) Identifier() string { return "codex" }
+
+func (e *countingExecutor) Execute(context.Context, *coreauth.Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	atomic.AddInt32(&e.calls, 1)
+	return cliproxyexecutor.Response{}, nil
+}
+
+func (e *countingExecutor) ExecuteStream(context.Context, *coreauth.Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (*cliproxyexecutor.StreamResult, error) {
+	atomic.AddInt32(&e.calls, 1)
+	return nil, errors.New("not implemented")
+}
+
+func (e *countingExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *countingExecutor) CountTokens(context.Context, *coreauth.Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	atomic.AddInt32(&e.calls, 1)
+	return cliproxyexecutor.Response{}, nil
+}
+
+func (e *countingExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&e.calls, 1)
+	return nil, errors.New("not implemented")
+}
+
+func TestPostRouteExplain_ReportsExclusionReasons(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	executor := &countingExecutor{}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	now := time.Now()
+	for _, auth := range []*coreauth.Auth{
+		{ID: "explain-active", Provider: "codex", Status: coreauth.StatusActive},
+		{ID: "explain-disabled", Provider: "codex", Status: coreauth.StatusDisabled, Disabled: true},
+		{
+			ID:       "explain-cooling",
+			Provider: "codex",
+			Status:   coreauth.StatusError,
+			ModelStates: map[string]*coreauth.ModelState{
+				"explain-model": {
+					Unavailable:    true,
+					NextRetryAfter: now.Add(time.Minute),
+					Quota:          coreauth.QuotaState{Exceeded: true, Reason: "quota"},
+				},
+			},
+		},
+	} {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register %s: %v", auth.ID, err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "explain-model"}})
+		id := auth.ID
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+	h := &Handler{cfg: &config.Config{}, authManager: manager}
+
+	explain := func(body string) (*httptest.ResponseRecorder, coreauth.RouteExplanation) {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v0/management/route/explain", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		h.PostRouteExplain(c)
+		var resp struct {
+			Route coreauth.RouteExplanation `json:"route"`
+		}
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+		}
+		return rec, resp.Route
+	}
+
+	rec, route := explain(`{"model":"explain-model"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	reasons := make(map[string]coreauth.RouteCandidate, len(route.Candidates))
+	for _, candidate := range route.Candidates {
+		reasons[candidate.ID] = candidate
+	}
+	if got := reasons["explain-disabled"]; got.Eligible || got.Reason != coreauth.RouteReasonDisabled {
+		t.Fatalf("disabled auth = %+v, want reason %q", got, coreauth.RouteReasonDisabled)
+	}
+	cooling := reasons["explain-cooling"]
+	if cooling.Eligible || cooling.Reason != coreauth.RouteReasonCooldown || cooling.RetryAfter == nil {
+		t.Fatalf("cooling auth = %+v, want reason %q with a retry time", cooling, coreauth.RouteReasonCooldown)
+	}
+	if got := reasons["explain-active"]; !got.Eligible || route.Selected != "explain-active" {
+		t.Fatalf("active auth = %+v, selected = %q; want explain-active selected", got, route.Selected)
+	}
+
+	// A forced auth narrows the choice; the others are reported as not pinned.
+	_, route = explain(`{"model":"explain-model","headers":{"X-Force-Auth":"explain-cooling"}}`)
+	if route.Selected != "" || route.PinnedAuth != "explain-cooling" {
+		t.Fatalf("pinned to a cooling auth: selected = %q, pinned = %q", route.Selected, route.PinnedAuth)
+	}
+	for _, candidate := range route.Candidates {
+		if candidate.ID == "explain-active" && candidate.Reason != coreauth.RouteReasonNotPinned {
+			t.Fatalf("active auth with another auth forced = %+v, want reason %q", candidate, coreauth.RouteReasonNotPinned)
+		}
+	}
+
+	if rec, _ = explain(`{"model":"explain-unknown-model"}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown model status = %d, want 404", rec.Code)
+	}
+	// Targets and models that dispatch rejects are rejected with the same status.
+	if rec, _ = explain(`{"model":"explain-model","headers":{"X-Force-Provider":"claude"}}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("forced provider without the model status = %d, want 400", rec.Code)
+	}
+	if rec, _ = explain(`{"model":"explain-model","headers":{"X-Force-Auth":"explain-missing"}}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("forced unknown auth status = %d, want 400", rec.Code)
+	}
+	registry.GetGlobalRegistry().SetDeclaredModels([]*registry.ModelInfo{{ID: "explain-declared-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().SetDeclaredModels(nil) })
+	if rec, _ = explain(`{"model":"explain-declared-model"}`); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("declared-unavailable model status = %d, want 503", rec.Code)
+	}
+	if calls := atomic.LoadInt32(&executor.calls); calls != 0 {
+		t.Fatalf("executor called %d times, want nothing sent upstream", calls)
+	}
+}
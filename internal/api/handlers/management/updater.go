@@ -0,0 +1,47 @@
+package management
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/managementasset"
+)
+
+// assetUpdater is the part of the management asset updater exposed over the API.
+type assetUpdater interface {
+	Status() managementasset.Status
+	CheckNow(ctx context.Context) (managementasset.Status, error)
+}
+
+// packageAssetUpdater forwards to the process-wide updater in managementasset.
+type packageAssetUpdater struct{}
+
+func (packageAssetUpdater) Status() managementasset.Status { return managementasset.CurrentStatus() }
+
+func (packageAssetUpdater) CheckNow(ctx context.Context) (managementasset.Status, error) {
+	return managementasset.CheckNow(ctx)
+}
+
+func (h *Handler) updater() assetUpdater {
+	if h.assetUpdater != nil {
+		return h.assetUpdater
+	}
+	return packageAssetUpdater{}
+}
+
+// GetUpdaterStatus reports the last management asset update check.
+func (h *Handler) GetUpdaterStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.updater().Status())
+}
+
+// PostUpdaterCheck runs a management asset update check immediately and returns the
+// resulting status.
+func (h *Handler) PostUpdaterCheck(c *gin.Context) {
+	status, err := h.updater().CheckNow(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "status": status})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
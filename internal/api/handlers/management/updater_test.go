@@ -0,0 +1,93 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/managementasset"
+)
+
+type stubAssetUpdater struct {
+	status   managementasset.Status
+	checkErr error
+	checks   int
+}
+
+func (s *stubAssetUpdater) Status() managementasset.Status { return s.status }
+
+func (s *stubAssetUpdater) CheckNow(context.Context) (managementasset.Status, error) {
+	s.checks++
+	if s.checkErr != nil {
+		return s.status, s.checkErr
+	}
+	s.status.LastCheck = s.status.LastCheck.Add(time.Hour)
+	s.status.LastResult = "updated"
+	s.status.CurrentVersion = s.status.AvailableVersion
+	return s.status, nil
+}
+
+func serveUpdaterRequest(t *testing.T, h *Handler, method string, handler gin.HandlerFunc) (int, managementasset.Status) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(method, "/v0/management/updater", nil)
+	handler(c)
+	var status managementasset.Status
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+			t.Fatalf("decode response: %v (body=%s)", err, rec.Body.String())
+		}
+	}
+	return rec.Code, status
+}
+
+func TestGetUpdaterStatusReportsStub(t *testing.T) {
+	checked := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	stub := &stubAssetUpdater{status: managementasset.Status{
+		AutoUpdaterRunning: true,
+		LastCheck:          checked,
+		LastResult:         "up-to-date",
+		CurrentVersion:     "v1.2.0",
+		AvailableVersion:   "v1.3.0",
+	}}
+	h := &Handler{assetUpdater: stub}
+
+	code, status := serveUpdaterRequest(t, h, http.MethodGet, h.GetUpdaterStatus)
+	if code != http.StatusOK {
+		t.Fatalf("status code = %d, want 200", code)
+	}
+	if !status.LastCheck.Equal(checked) || status.CurrentVersion != "v1.2.0" || status.AvailableVersion != "v1.3.0" || !status.AutoUpdaterRunning {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	if stub.checks != 0 {
+		t.Fatalf("status request triggered %d checks", stub.checks)
+	}
+}
+
+func TestPostUpdaterCheckTriggersCheck(t *testing.T) {
+	stub := &stubAssetUpdater{status: managementasset.Status{CurrentVersion: "v1.2.0", AvailableVersion: "v1.3.0"}}
+	h := &Handler{assetUpdater: stub}
+
+	code, status := serveUpdaterRequest(t, h, http.MethodPost, h.PostUpdaterCheck)
+	if code != http.StatusOK {
+		t.Fatalf("status code = %d, want 200", code)
+	}
+	if stub.checks != 1 {
+		t.Fatalf("checks = %d, want 1", stub.checks)
+	}
+	if status.LastResult != "updated" || status.CurrentVersion != "v1.3.0" {
+		t.Fatalf("unexpected status after check: %+v", status)
+	}
+
+	stub.checkErr = errors.New("control panel disabled")
+	if code, _ = serveUpdaterRequest(t, h, http.MethodPost, h.PostUpdaterCheck); code != http.StatusConflict {
+		t.Fatalf("status code = %d, want 409 when the check cannot run", code)
+	}
+}
@@ -2,11 +2,15 @@ package management
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
 )
 
 type usageExportPayload struct {
@@ -26,6 +30,9 @@ func (h *Handler) GetUsageStatistics(c *gin.Context) {
 	if h != nil && h.usageStats != nil {
 		snapshot = h.usageStats.Snapshot()
 	}
+	if isReadOnlyCaller(c) {
+		snapshot = redactUsageSnapshot(snapshot)
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"usage":           snapshot,
 		"failed_requests": snapshot.FailureCount,
@@ -38,6 +45,9 @@ func (h *Handler) ExportUsageStatistics(c *gin.Context) {
 	if h != nil && h.usageStats != nil {
 		snapshot = h.usageStats.Snapshot()
 	}
+	if isReadOnlyCaller(c) {
+		snapshot = redactUsageSnapshot(snapshot)
+	}
 	c.JSON(http.StatusOK, usageExportPayload{
 		Version:    1,
 		ExportedAt: time.Now().UTC(),
@@ -45,6 +55,41 @@ func (h *Handler) ExportUsageStatistics(c *gin.Context) {
 	})
 }
 
+// redactUsageSnapshot masks the client API keys that key the snapshot and the upstream
+// API keys recorded as request sources. Email and account sources are kept.
+func redactUsageSnapshot(snapshot usage.StatisticsSnapshot) usage.StatisticsSnapshot {
+	if len(snapshot.APIs) == 0 {
+		return snapshot
+	}
+	apis := make(map[string]usage.APISnapshot, len(snapshot.APIs))
+	for apiName, api := range snapshot.APIs {
+		models := make(map[string]usage.ModelSnapshot, len(api.Models))
+		for modelName, model := range api.Models {
+			details := make([]usage.RequestDetail, len(model.Details))
+			for i, detail := range model.Details {
+				if !strings.Contains(detail.Source, "@") && detail.Source != coreusage.SourceResponseCache {
+					detail.Source = util.HideAPIKey(detail.Source)
+				}
+				details[i] = detail
+			}
+			model.Details = details
+			models[modelName] = model
+		}
+		api.Models = models
+		// Distinct keys can mask to the same string; keep them apart.
+		masked := util.HideAPIKey(apiName)
+		for n := 2; ; n++ {
+			if _, taken := apis[masked]; !taken {
+				break
+			}
+			masked = fmt.Sprintf("%s#%d", util.HideAPIKey(apiName), n)
+		}
+		apis[masked] = api
+	}
+	snapshot.APIs = apis
+	return snapshot
+}
+
 // ImportUsageStatistics merges a previously exported usage snapshot into memory.
 func (h *Handler) ImportUsageStatistics(c *gin.Context) {
 	if h == nil || h.usageStats == nil {
@@ -15,6 +15,8 @@ import (
 
 // ImportVertexCredential handles uploading a Vertex service account JSON and saving it as an auth record.
 func (h *Handler) ImportVertexCredential(c *gin.Context) {
+	var target string
+	defer func() { h.audit(c, "import-vertex-credential", target) }()
 	if h == nil || h.cfg == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "config unavailable"})
 		return
@@ -72,6 +74,7 @@ func (h *Handler) ImportVertexCredential(c *gin.Context) {
 	}
 
 	fileName := fmt.Sprintf("vertex-%s.json", sanitizeVertexFilePart(projectID))
+	target = fileName
 	label := labelForVertex(projectID, email)
 	storage := &vertex.VertexCredentialStorage{
 		ServiceAccount: serviceAccount,
@@ -0,0 +1,167 @@
+// Package middleware provides HTTP middleware components for the CLI Proxy API server.
+// This file contains the trusted proxy middleware that resolves the real client address
+// from forwarding headers set by load balancers.
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrustedProxies holds the proxy networks whose forwarding headers are honoured.
+// It is safe for concurrent use and can be updated on configuration reload.
+type TrustedProxies struct {
+	networks atomic.Pointer[[]*net.IPNet]
+}
+
+// NewTrustedProxies parses entries (IP addresses or CIDR ranges) into a TrustedProxies.
+func NewTrustedProxies(entries []string) (*TrustedProxies, error) {
+	t := &TrustedProxies{}
+	if err := t.Set(entries); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Set replaces the trusted networks. On error the previous networks are kept.
+func (t *TrustedProxies) Set(entries []string) error {
+	networks, err := ParseTrustedProxies(entries)
+	if err != nil {
+		return err
+	}
+	t.networks.Store(&networks)
+	return nil
+}
+
+// ParseTrustedProxies parses IP addresses and CIDR ranges into networks.
+func ParseTrustedProxies(entries []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func (t *TrustedProxies) trusted(ip net.IP) bool {
+	if t == nil || ip == nil {
+		return false
+	}
+	networks := t.networks.Load()
+	if networks == nil {
+		return false
+	}
+	for _, network := range *networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware rewrites the request's RemoteAddr to the real client address when the direct
+// peer is a trusted proxy, so identity-based features (management access checks, bans,
+// logging) see the client rather than the load balancer. The client is the right-most
+// X-Forwarded-For (or Forwarded "for=") entry that is not itself a trusted proxy.
+// Forwarding headers from untrusted peers are ignored.
+func (t *TrustedProxies) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if clientIP := t.resolveClientIP(c.Request); clientIP != "" {
+			_, port, err := net.SplitHostPort(c.Request.RemoteAddr)
+			if err != nil {
+				port = "0"
+			}
+			c.Request.RemoteAddr = net.JoinHostPort(clientIP, port)
+		}
+		c.Next()
+	}
+}
+
+// resolveClientIP returns the forwarded client address, or "" when the request did not
+// come through a trusted proxy or carries no usable forwarding header.
+func (t *TrustedProxies) resolveClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !t.trusted(net.ParseIP(host)) {
+		return ""
+	}
+	hops := forwardedForHops(r.Header.Values("X-Forwarded-For"))
+	if len(hops) == 0 {
+		hops = forwardedHops(r.Header.Values("Forwarded"))
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(hops[i])
+		if ip == nil {
+			// An unparsable hop cannot be attributed; stop rather than trust what precedes it.
+			return ""
+		}
+		if !t.trusted(ip) {
+			return ip.String()
+		}
+	}
+	return ""
+}
+
+func forwardedForHops(values []string) []string {
+	var hops []string
+	for _, value := range values {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	return hops
+}
+
+// forwardedHops extracts the "for=" addresses of an RFC 7239 Forwarded header.
+func forwardedHops(values []string) []string {
+	var hops []string
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok || !strings.EqualFold(key, "for") {
+					continue
+				}
+				val = strings.Trim(val, `"`)
+				if strings.HasPrefix(val, "[") {
+					// Bracketed IPv6, optionally with a port: [2001:db8::1]:4711
+					if end := strings.Index(val, "]"); end > 0 {
+						val = val[1:end]
+					}
+				} else if host, _, err := net.SplitHostPort(val); err == nil {
+					val = host
+				}
+				hops = append(hops, val)
+			}
+		}
+	}
+	return hops
+}
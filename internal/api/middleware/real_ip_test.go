@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newRealIPEngine(t *testing.T, trusted ...string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	proxies, err := NewTrustedProxies(trusted)
+	if err != nil {
+		t.Fatalf("NewTrustedProxies: %v", err)
+	}
+	engine := gin.New()
+	if err := engine.SetTrustedProxies(nil); err != nil {
+		t.Fatalf("SetTrustedProxies: %v", err)
+	}
+	engine.Use(proxies.Middleware())
+	engine.GET("/ip", func(c *gin.Context) {
+		c.String(http.StatusOK, c.ClientIP())
+	})
+	return engine
+}
+
+func TestTrustedProxiesMiddleware(t *testing.T) {
+	cases := []struct {
+		name    string
+		trusted []string
+		peer    string
+		headers map[string]string
+		want    string
+	}{
+		{
+			name:    "trusted peer uses X-Forwarded-For",
+			trusted: []string{"10.0.0.0/8"},
+			peer:    "10.1.2.3:4567",
+			headers: map[string]string{"X-Forwarded-For": "198.51.100.9, 203.0.113.7, 10.0.0.2"},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "trusted peer uses Forwarded",
+			trusted: []string{"10.1.2.3"},
+			peer:    "10.1.2.3:4567",
+			headers: map[string]string{"Forwarded": `for="[2001:db8::1]:4711";proto=https`},
+			want:    "2001:db8::1",
+		},
+		{
+			name:    "untrusted peer ignores headers",
+			trusted: []string{"10.0.0.0/8"},
+			peer:    "192.0.2.50:4567",
+			headers: map[string]string{"X-Forwarded-For": "203.0.113.7", "Forwarded": "for=203.0.113.8"},
+			want:    "192.0.2.50",
+		},
+		{
+			name:    "no trusted proxies ignores headers",
+			peer:    "10.1.2.3:4567",
+			headers: map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			want:    "10.1.2.3",
+		},
+		{
+			name:    "unparsable hop keeps peer",
+			trusted: []string{"10.0.0.0/8"},
+			peer:    "10.1.2.3:4567",
+			headers: map[string]string{"X-Forwarded-For": "203.0.113.7, not-an-ip"},
+			want:    "10.1.2.3",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := newRealIPEngine(t, tc.trusted...)
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = tc.peer
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+			if got := rec.Body.String(); got != tc.want {
+				t.Fatalf("client IP = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTrustedProxiesRejectsInvalidEntries(t *testing.T) {
+	if _, err := NewTrustedProxies([]string{"10.0.0.0/33"}); err == nil {
+		t.Fatalf("expected an error for an invalid CIDR")
+	}
+	if _, err := NewTrustedProxies([]string{"proxy.internal"}); err == nil {
+		t.Fatalf("expected an error for a host name")
+	}
+}
@@ -0,0 +1,294 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/buildinfo"
+)
+
+// managementPathPrefix is the route group shared by every management endpoint.
+const managementPathPrefix = "/v0/management"
+
+// openAPIOperation holds the hand-written metadata for one route. Routes without an
+// entry still appear in the spec with a generic description and response.
+type openAPIOperation struct {
+	Summary     string
+	RequestBody map[string]any
+	Response    map[string]any
+}
+
+// Schemas referenced by the operation table below. They describe the envelope only;
+// provider-specific fields are passed through and left open.
+var (
+	openAPIObjectSchema = map[string]any{"type": "object", "additionalProperties": true}
+
+	openAPIChatCompletionRequest = map[string]any{
+		"type":                 "object",
+		"required":             []string{"model", "messages"},
+		"additionalProperties": true,
+		"properties": map[string]any{
+			"model":    map[string]any{"type": "string"},
+			"stream":   map[string]any{"type": "boolean"},
+			"messages": map[string]any{"type": "array", "items": map[string]any{"type": "object", "additionalProperties": true}},
+		},
+	}
+
+	openAPIClaudeMessagesRequest = map[string]any{
+		"type":                 "object",
+		"required":             []string{"model", "messages"},
+		"additionalProperties": true,
+		"properties": map[string]any{
+			"model":      map[string]any{"type": "string"},
+			"max_tokens": map[string]any{"type": "integer"},
+			"stream":     map[string]any{"type": "boolean"},
+			"system":     map[string]any{},
+			"messages":   map[string]any{"type": "array", "items": map[string]any{"type": "object", "additionalProperties": true}},
+		},
+	}
+
+	openAPIResponsesRequest = map[string]any{
+		"type":                 "object",
+		"required":             []string{"model"},
+		"additionalProperties": true,
+		"properties": map[string]any{
+			"model":  map[string]any{"type": "string"},
+			"stream": map[string]any{"type": "boolean"},
+			"input":  map[string]any{},
+		},
+	}
+
+	openAPIModelList = map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"object": map[string]any{"type": "string"},
+			"data": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type":                 "object",
+					"additionalProperties": true,
+					"properties": map[string]any{
+						"id":       map[string]any{"type": "string"},
+						"object":   map[string]any{"type": "string"},
+						"owned_by": map[string]any{"type": "string"},
+					},
+				},
+			},
+		},
+	}
+
+	openAPIAuthFileList = map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"files": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type":                 "object",
+					"additionalProperties": true,
+					"properties": map[string]any{
+						"id":       map[string]any{"type": "string"},
+						"name":     map[string]any{"type": "string"},
+						"provider": map[string]any{"type": "string"},
+						"status":   map[string]any{"type": "string"},
+						"disabled": map[string]any{"type": "boolean"},
+					},
+				},
+			},
+		},
+	}
+
+	openAPIErrorSchema = map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"error": map[string]any{
+				"type":                 "object",
+				"additionalProperties": true,
+				"properties": map[string]any{
+					"message": map[string]any{"type": "string"},
+					"type":    map[string]any{"type": "string"},
+					"code":    map[string]any{"type": "string"},
+				},
+			},
+		},
+	}
+)
+
+// openAPIOperations documents the routes integrators use most, keyed by "METHOD path"
+// with gin path syntax.
+var openAPIOperations = map[string]openAPIOperation{
+	"GET /v1/models":                         {Summary: "List models available to the caller", Response: openAPIModelList},
+	"POST /v1/chat/completions":              {Summary: "OpenAI Chat Completions", RequestBody: openAPIChatCompletionRequest, Response: openAPIObjectSchema},
+	"POST /v1/completions":                   {Summary: "OpenAI legacy Completions", RequestBody: openAPIObjectSchema, Response: openAPIObjectSchema},
+	"POST /v1/messages":                      {Summary: "Anthropic Messages", RequestBody: openAPIClaudeMessagesRequest, Response: openAPIObjectSchema},
+	"POST /v1/messages/count_tokens":         {Summary: "Anthropic token counting", RequestBody: openAPIClaudeMessagesRequest, Response: openAPIObjectSchema},
+	"GET /v1/responses":                      {Summary: "OpenAI Responses over websocket"},
+	"POST /v1/responses":                     {Summary: "OpenAI Responses", RequestBody: openAPIResponsesRequest, Response: openAPIObjectSchema},
+	"POST /v1/responses/compact":             {Summary: "OpenAI Responses compaction", RequestBody: openAPIResponsesRequest, Response: openAPIObjectSchema},
+	"GET /v1beta/models":                     {Summary: "List models in Gemini format", Response: openAPIObjectSchema},
+	"POST /v1beta/models/*action":            {Summary: "Gemini generateContent, streamGenerateContent and countTokens", RequestBody: openAPIObjectSchema, Response: openAPIObjectSchema},
+	"GET /v1beta/models/*action":             {Summary: "Get a Gemini model", Response: openAPIObjectSchema},
+	"GET /v0/management/config":              {Summary: "Get the running configuration as JSON", Response: openAPIObjectSchema},
+	"GET /v0/management/usage":               {Summary: "Get usage statistics", Response: openAPIObjectSchema},
+	"GET /v0/management/auth-files":          {Summary: "List auth files", Response: openAPIAuthFileList},
+	"POST /v0/management/auth-files":         {Summary: "Upload an auth file", RequestBody: openAPIObjectSchema, Response: openAPIObjectSchema},
+	"DELETE /v0/management/auth-files":       {Summary: "Delete auth files by name, or all with all=true", Response: openAPIObjectSchema},
+	"GET /v0/management/auth-files/models":   {Summary: "List the models provided by an auth file", Response: openAPIObjectSchema},
+	"GET /v0/management/auth-files/download": {Summary: "Download an auth file", Response: openAPIObjectSchema},
+	"PATCH /v0/management/auth-files/status": {Summary: "Enable or disable an auth file", RequestBody: openAPIObjectSchema, Response: openAPIObjectSchema},
+	"GET /v0/management/updater/status":      {Summary: "Get the management asset updater status", Response: openAPIObjectSchema},
+	"POST /v0/management/updater/check":      {Summary: "Check for a management asset update now", Response: openAPIObjectSchema},
+}
+
+// OpenAPISpec returns an OpenAPI 3 document, as JSON, describing every route registered
+// on the server's engine.
+func (s *Server) OpenAPISpec() ([]byte, error) {
+	return json.MarshalIndent(BuildOpenAPISpec(s.engine.Routes()), "", "  ")
+}
+
+// BuildOpenAPISpec converts gin routes into an OpenAPI 3 document. Request and response
+// shapes come from openAPIOperations; other routes get a generic JSON object.
+func BuildOpenAPISpec(routes gin.RoutesInfo) map[string]any {
+	paths := make(map[string]any)
+	for _, route := range routes {
+		openAPIPath, params := openAPIPathFromGin(route.Path)
+		item, ok := paths[openAPIPath].(map[string]any)
+		if !ok {
+			item = make(map[string]any)
+			paths[openAPIPath] = item
+		}
+		item[strings.ToLower(route.Method)] = buildOpenAPIOperation(route, params)
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "CLIProxyAPI",
+			"version": buildinfo.Version,
+		},
+		"tags": []map[string]any{
+			{"name": "proxy", "description": "Provider-compatible model endpoints"},
+			{"name": "management", "description": "Management API under " + managementPathPrefix},
+			{"name": "other", "description": "OAuth callbacks and miscellaneous routes"},
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": map[string]any{
+				"Error": openAPIErrorSchema,
+			},
+			"securitySchemes": map[string]any{
+				"apiKey":           map[string]any{"type": "http", "scheme": "bearer", "description": "A key from api-keys; x-api-key, x-goog-api-key and ?key= are also accepted"},
+				"managementKey":    map[string]any{"type": "http", "scheme": "bearer", "description": "The remote-management secret key"},
+				"managementHeader": map[string]any{"type": "apiKey", "in": "header", "name": "X-Management-Key"},
+			},
+		},
+	}
+}
+
+func buildOpenAPIOperation(route gin.RouteInfo, params []string) map[string]any {
+	meta, documented := openAPIOperations[route.Method+" "+route.Path]
+
+	tag := "other"
+	var security []map[string][]string
+	switch {
+	case strings.HasPrefix(route.Path, managementPathPrefix+"/"):
+		tag = "management"
+		security = []map[string][]string{{"managementKey": {}}, {"managementHeader": {}}}
+	case strings.HasPrefix(route.Path, "/v1/"), strings.HasPrefix(route.Path, "/v1beta/"):
+		tag = "proxy"
+		security = []map[string][]string{{"apiKey": {}}}
+	}
+
+	summary := meta.Summary
+	if summary == "" {
+		summary = route.Method + " " + route.Path
+	}
+	op := map[string]any{
+		"summary":     summary,
+		"operationId": openAPIOperationID(route.Method, route.Path),
+		"tags":        []string{tag},
+	}
+	if security != nil {
+		op["security"] = security
+	}
+	if len(params) > 0 {
+		parameters := make([]map[string]any, 0, len(params))
+		for _, name := range params {
+			parameters = append(parameters, map[string]any{
+				"name":     name,
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]any{"type": "string"},
+			})
+		}
+		op["parameters"] = parameters
+	}
+
+	requestBody := meta.RequestBody
+	if requestBody == nil && !documented && methodHasBody(route.Method) {
+		requestBody = openAPIObjectSchema
+	}
+	if requestBody != nil {
+		op["requestBody"] = map[string]any{
+			"required": true,
+			"content":  map[string]any{"application/json": map[string]any{"schema": requestBody}},
+		}
+	}
+
+	success := map[string]any{"description": "Success"}
+	response := meta.Response
+	if response == nil && !documented {
+		response = openAPIObjectSchema
+	}
+	if response != nil {
+		success["content"] = map[string]any{"application/json": map[string]any{"schema": response}}
+	}
+	errorResponse := map[string]any{
+		"description": "Error",
+		"content":     map[string]any{"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/Error"}}},
+	}
+	op["responses"] = map[string]any{"200": success, "default": errorResponse}
+	return op
+}
+
+// openAPIPathFromGin rewrites gin path parameters (:name, *name) into OpenAPI templates.
+func openAPIPathFromGin(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, segment := range segments {
+		if len(segment) > 1 && (segment[0] == ':' || segment[0] == '*') {
+			name := segment[1:]
+			params = append(params, name)
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+func openAPIOperationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	upperNext := true
+	for _, r := range path {
+		isAlnum := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+		if !isAlnum {
+			upperNext = true
+			continue
+		}
+		if upperNext && r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		upperNext = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func methodHasBody(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"path/filepath"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	proxyconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/tidwall/gjson"
+)
+
+func TestOpenAPISpecListsModelsAndAuthFiles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tmpDir := t.TempDir()
+	cfg := &proxyconfig.Config{AuthDir: filepath.Join(tmpDir, "auth"), Debug: true}
+	server := NewServer(cfg, auth.NewManager(nil, nil, nil), sdkaccess.NewManager(), filepath.Join(tmpDir, "config.yaml"),
+		WithLocalManagementPassword("secret"),
+		WithRequestLoggerFactory(func(*proxyconfig.Config, string) logging.RequestLogger { return nil }),
+	)
+
+	spec, err := server.OpenAPISpec()
+	if err != nil {
+		t.Fatalf("OpenAPISpec: %v", err)
+	}
+	doc := gjson.ParseBytes(spec)
+	if got := doc.Get("openapi").String(); got != "3.0.3" {
+		t.Fatalf("openapi = %q, want 3.0.3", got)
+	}
+
+	paths := doc.Get("paths")
+	models := paths.Get(`/v1/models.get`)
+	if !models.Exists() {
+		t.Fatalf("spec is missing GET /v1/models")
+	}
+	if got := models.Get("tags.0").String(); got != "proxy" {
+		t.Fatalf("/v1/models tag = %q, want proxy", got)
+	}
+	if !models.Get("responses.200.content.application/json.schema.properties.data").Exists() {
+		t.Fatalf("/v1/models response schema missing data: %s", models.Raw)
+	}
+
+	authFiles := paths.Get(`/v0/management/auth-files`)
+	for _, method := range []string{"get", "post", "delete"} {
+		if !authFiles.Get(method).Exists() {
+			t.Fatalf("spec is missing %s /v0/management/auth-files", method)
+		}
+	}
+	if got := authFiles.Get("get.tags.0").String(); got != "management" {
+		t.Fatalf("auth-files tag = %q, want management", got)
+	}
+	if !paths.Get(`/v0/management/auth-files/status.patch.requestBody`).Exists() {
+		t.Fatalf("PATCH /v0/management/auth-files/status should document a request body")
+	}
+
+	action := paths.Map()["/v1beta/models/{action}"].Get("post")
+	if got := action.Get("parameters.0.name").String(); got != "action" {
+		t.Fatalf("wildcard path parameter = %q, want action", got)
+	}
+}
@@ -137,6 +137,9 @@ type Server struct {
 	requestLogger logging.RequestLogger
 	loggerToggle  func(bool)
 
+	// trustedProxies resolves real client addresses behind configured load balancers.
+	trustedProxies *middleware.TrustedProxies
+
 	// configFilePath is the absolute path to the YAML config file for persistence.
 	configFilePath string
 
@@ -152,6 +155,9 @@ type Server struct {
 	// management handler
 	mgmt *managementHandlers.Handler
 
+	// drain counts in-flight requests and how they end during shutdown.
+	drain drainTracker
+
 	// ampModule is the Amp routing module for model mapping hot-reload
 	ampModule *ampmodule.AmpModule
 
@@ -200,7 +206,17 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 		optionState.engineConfigurator(engine)
 	}
 
+	// Client addresses are resolved by the trusted proxy middleware; gin itself trusts no
+	// forwarding headers so they cannot be spoofed by direct clients.
+	_ = engine.SetTrustedProxies(nil)
+	trustedProxies, errProxies := middleware.NewTrustedProxies(cfg.TrustedProxies)
+	if errProxies != nil {
+		log.Errorf("failed to parse trusted-proxies, ignoring forwarding headers: %v", errProxies)
+		trustedProxies, _ = middleware.NewTrustedProxies(nil)
+	}
+
 	// Add middleware
+	engine.Use(trustedProxies.Middleware())
 	engine.Use(logging.GinLogrusLogger())
 	engine.Use(logging.GinLogrusRecovery())
 	for _, mw := range optionState.extraMiddleware {
@@ -236,6 +252,7 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 	// Create server instance
 	s := &Server{
 		engine:              engine,
+		trustedProxies:      trustedProxies,
 		handlers:            handlers.NewBaseAPIHandlers(&cfg.SDKConfig, authManager),
 		cfg:                 cfg,
 		accessManager:       accessManager,
@@ -247,6 +264,8 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 		wsRoutes:            make(map[string]struct{}),
 	}
 	s.wsAuthEnabled.Store(cfg.WebsocketAuth)
+	// Count in-flight requests for shutdown drain accounting; registered before any route.
+	engine.Use(s.drain.middleware())
 	// Save initial YAML snapshot
 	s.oldConfigYaml, _ = yaml.Marshal(cfg)
 	s.applyAccessConfig(nil, cfg)
@@ -320,6 +339,7 @@ func (s *Server) setupRoutes() {
 	v1.Use(AuthMiddleware(s.accessManager))
 	{
 		v1.GET("/models", s.unifiedModelsHandler(openaiHandlers, claudeCodeHandlers))
+		v1.GET("/models/*id", openaiHandlers.OpenAIModel)
 		v1.POST("/chat/completions", openaiHandlers.ChatCompletions)
 		v1.POST("/completions", openaiHandlers.Completions)
 		v1.POST("/messages", claudeCodeHandlers.ClaudeMessages)
@@ -338,6 +358,9 @@ func (s *Server) setupRoutes() {
 		v1beta.GET("/models/*action", geminiHandlers.GeminiGetHandler)
 	}
 
+	// Health endpoint, reporting drain progress during shutdown
+	s.engine.GET("/healthz", s.drain.healthHandler)
+
 	// Root endpoint
 	s.engine.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -475,7 +498,7 @@ func (s *Server) registerManagementRoutes() {
 	log.Info("management routes registered after secret key configuration")
 
 	mgmt := s.engine.Group("/v0/management")
-	mgmt.Use(s.managementAvailabilityMiddleware(), s.mgmt.Middleware())
+	mgmt.Use(s.managementAvailabilityMiddleware(), s.mgmt.Middleware(), s.mgmt.RoleMiddleware())
 	{
 		mgmt.GET("/usage", s.mgmt.GetUsageStatistics)
 		mgmt.GET("/usage/export", s.mgmt.ExportUsageStatistics)
@@ -484,6 +507,8 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.GET("/config.yaml", s.mgmt.GetConfigYAML)
 		mgmt.PUT("/config.yaml", s.mgmt.PutConfigYAML)
 		mgmt.GET("/latest-version", s.mgmt.GetLatestVersion)
+		mgmt.GET("/updater/status", s.mgmt.GetUpdaterStatus)
+		mgmt.POST("/updater/check", s.mgmt.PostUpdaterCheck)
 
 		mgmt.GET("/debug", s.mgmt.GetDebug)
 		mgmt.PUT("/debug", s.mgmt.PutDebug)
@@ -512,6 +537,15 @@ func (s *Server) registerManagementRoutes() {
 
 		mgmt.POST("/api-call", s.mgmt.APICall)
 
+		mgmt.GET("/cooldown", s.mgmt.GetCooldown)
+		mgmt.PUT("/cooldown", s.mgmt.PutCooldown)
+		mgmt.PATCH("/cooldown", s.mgmt.PutCooldown)
+
+		mgmt.GET("/providers/:name/enabled", s.mgmt.GetProviderEnabled)
+		mgmt.PUT("/providers/:name/enabled", s.mgmt.PutProviderEnabled)
+		mgmt.PATCH("/providers/:name/enabled", s.mgmt.PutProviderEnabled)
+		mgmt.POST("/route/explain", s.mgmt.PostRouteExplain)
+
 		mgmt.GET("/quota-exceeded/switch-project", s.mgmt.GetSwitchProject)
 		mgmt.PUT("/quota-exceeded/switch-project", s.mgmt.PutSwitchProject)
 		mgmt.PATCH("/quota-exceeded/switch-project", s.mgmt.PutSwitchProject)
@@ -614,11 +648,17 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.GET("/auth-files", s.mgmt.ListAuthFiles)
 		mgmt.GET("/auth-files/models", s.mgmt.GetAuthFileModels)
 		mgmt.GET("/model-definitions/:channel", s.mgmt.GetStaticModelDefinitions)
+		mgmt.GET("/models/:id/thinking", s.mgmt.GetModelThinking)
 		mgmt.GET("/auth-files/download", s.mgmt.DownloadAuthFile)
+		mgmt.GET("/auth-files/export", s.mgmt.ExportAuthFiles)
 		mgmt.POST("/auth-files", s.mgmt.UploadAuthFile)
+		mgmt.POST("/auth-files/import", s.mgmt.ImportAuthFiles)
 		mgmt.DELETE("/auth-files", s.mgmt.DeleteAuthFile)
 		mgmt.PATCH("/auth-files/status", s.mgmt.PatchAuthFileStatus)
 		mgmt.PATCH("/auth-files/fields", s.mgmt.PatchAuthFileFields)
+		mgmt.POST("/auth-files/rename", s.mgmt.RenameAuthFile)
+		mgmt.POST("/auth-files/pin", s.mgmt.PinAuthFile)
+		mgmt.GET("/auth-files/:name/:action", s.mgmt.AuthFileAction)
 		mgmt.POST("/vertex/import", s.mgmt.ImportVertexCredential)
 
 		mgmt.GET("/anthropic-auth-url", s.mgmt.RequestAnthropicToken)
@@ -788,6 +828,13 @@ func (s *Server) Start() error {
 		if cert == "" || key == "" {
 			return fmt.Errorf("failed to start HTTPS server: tls.cert or tls.key is empty")
 		}
+		tlsConfig, errTLS := buildServerTLSConfig(s.cfg.TLS)
+		if errTLS != nil {
+			return fmt.Errorf("failed to start HTTPS server: %v", errTLS)
+		}
+		if tlsConfig != nil {
+			s.server.TLSConfig = tlsConfig
+		}
 		log.Debugf("Starting API server on %s with TLS", s.server.Addr)
 		if errServeTLS := s.server.ListenAndServeTLS(cert, key); errServeTLS != nil && !errors.Is(errServeTLS, http.ErrServerClosed) {
 			return fmt.Errorf("failed to start HTTPS server: %v", errServeTLS)
@@ -821,15 +868,44 @@ func (s *Server) Stop(ctx context.Context) error {
 		}
 	}
 
-	// Shutdown the HTTP server.
-	if err := s.server.Shutdown(ctx); err != nil {
-		return fmt.Errorf("failed to shutdown HTTP server: %v", err)
+	// Report "draining" on /healthz while the listeners are still open, then shut down the
+	// HTTP server, accounting for the requests it drains.
+	s.drain.begin()
+	s.waitDrainDelay(ctx)
+	errShutdown := s.server.Shutdown(ctx)
+	stats := s.drain.finish()
+	log.WithFields(log.Fields{
+		"in_flight_at_signal": stats.InFlightAtSignal,
+		"completed":           stats.Completed,
+		"aborted":             stats.Aborted,
+	}).Info("API server drain summary")
+	if errShutdown != nil {
+		return fmt.Errorf("failed to shutdown HTTP server: %v", errShutdown)
 	}
 
 	log.Debug("API server stopped")
 	return nil
 }
 
+// waitDrainDelay waits the configured shutdown drain delay, or until ctx is done.
+func (s *Server) waitDrainDelay(ctx context.Context) {
+	if s.cfg == nil || s.cfg.ShutdownDrainDelaySeconds <= 0 {
+		return
+	}
+	timer := time.NewTimer(time.Duration(s.cfg.ShutdownDrainDelaySeconds) * time.Second)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// DrainStats returns the shutdown drain summary. It is zero until Stop is called.
+func (s *Server) DrainStats() DrainStats {
+	_, _, stats := s.drain.snapshot()
+	return stats
+}
+
 // corsMiddleware returns a Gin middleware handler that adds CORS headers
 // to every response, allowing cross-origin requests.
 //
@@ -891,10 +967,28 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 		}
 	}
 
+	if oldCfg == nil || !reflect.DeepEqual(oldCfg.TrustedProxies, cfg.TrustedProxies) {
+		if err := s.trustedProxies.Set(cfg.TrustedProxies); err != nil {
+			log.Errorf("failed to update trusted-proxies: %v", err)
+		}
+	}
+
 	if oldCfg == nil || oldCfg.UsageStatisticsEnabled != cfg.UsageStatisticsEnabled {
 		usage.SetStatisticsEnabled(cfg.UsageStatisticsEnabled)
 	}
 
+	if oldCfg == nil || oldCfg.Usage.BucketGranularity != cfg.Usage.BucketGranularity || oldCfg.Usage.BucketRetentionHours != cfg.Usage.BucketRetentionHours {
+		usage.SetBucketing(usage.ParseBucketGranularity(cfg.Usage.BucketGranularity), time.Duration(cfg.Usage.BucketRetentionHours)*time.Hour)
+	}
+
+	if oldCfg == nil || !reflect.DeepEqual(oldCfg.Usage.Pricing, cfg.Usage.Pricing) {
+		usage.SetPricing(cfg.Usage.Pricing)
+	}
+
+	if oldCfg == nil || oldCfg.Usage.ToolCallWarnThreshold != cfg.Usage.ToolCallWarnThreshold {
+		usage.SetToolCallWarnThreshold(cfg.Usage.ToolCallWarnThreshold)
+	}
+
 	if s.requestLogger != nil && (oldCfg == nil || oldCfg.ErrorLogsMaxFiles != cfg.ErrorLogsMaxFiles) {
 		if setter, ok := s.requestLogger.(interface{ SetErrorLogsMaxFiles(int) }); ok {
 			setter.SetErrorLogsMaxFiles(cfg.ErrorLogsMaxFiles)
@@ -1011,6 +1105,14 @@ func (s *Server) SetWebsocketAuthChangeHandler(fn func(bool, bool)) {
 	s.wsAuthChanged = fn
 }
 
+// SetAuthModelRefresher installs the callback backing the management model refresh endpoint.
+func (s *Server) SetAuthModelRefresher(refresher managementHandlers.ModelRefresher) {
+	if s == nil || s.mgmt == nil {
+		return
+	}
+	s.mgmt.SetModelRefresher(refresher)
+}
+
 // (management handlers moved to internal/api/handlers/management)
 
 // AuthMiddleware returns a Gin middleware handler that authenticates requests
@@ -1032,6 +1134,9 @@ func AuthMiddleware(manager *sdkaccess.Manager) gin.HandlerFunc {
 					c.Set("accessMetadata", result.Metadata)
 				}
 			}
+			if identity := sdkaccess.ClientCertIdentity(c.Request); identity != "" {
+				c.Set("clientCertIdentity", identity)
+			}
 			c.Next()
 			return
 		}
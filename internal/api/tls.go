@@ -0,0 +1,42 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+// buildServerTLSConfig returns the TLS settings for client certificate verification.
+// It returns nil when no client CA is configured, keeping server-only TLS behaviour.
+func buildServerTLSConfig(cfg config.TLSConfig) (*tls.Config, error) {
+	caPath := strings.TrimSpace(cfg.ClientCA)
+	if caPath == "" {
+		if cfg.RequireClientCert {
+			return nil, fmt.Errorf("tls.require-client-cert is set but tls.client-ca is empty")
+		}
+		return nil, nil
+	}
+
+	pemData, errRead := os.ReadFile(caPath)
+	if errRead != nil {
+		return nil, fmt.Errorf("read tls.client-ca: %w", errRead)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemData) {
+		return nil, fmt.Errorf("tls.client-ca %s contains no PEM certificates", caPath)
+	}
+
+	clientAuth := tls.VerifyClientCertIfGiven
+	if cfg.RequireClientCert {
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+	return &tls.Config{
+		ClientCAs:  pool,
+		ClientAuth: clientAuth,
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	certaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/cert_access"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+)
+
+type testCertAuthority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCertAuthority(t *testing.T) *testCertAuthority {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate CA key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create CA cert: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCertAuthority{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func (ca *testCertAuthority) issueClient(t *testing.T, commonName string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate client key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("create client cert: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestServerTLS_RequireClientCert(t *testing.T) {
+	ca := newTestCertAuthority(t)
+	caPath := filepath.Join(t.TempDir(), "client-ca.pem")
+	if err := os.WriteFile(caPath, ca.pem, 0o600); err != nil {
+		t.Fatalf("write CA: %v", err)
+	}
+
+	tlsConfig, err := buildServerTLSConfig(config.TLSConfig{Enable: true, ClientCA: caPath, RequireClientCert: true})
+	if err != nil {
+		t.Fatalf("buildServerTLSConfig: %v", err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(sdkaccess.ClientCertIdentity(r)))
+	}))
+	srv.TLS = tlsConfig
+	srv.StartTLS()
+	defer srv.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(srv.Certificate())
+	newClient := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: rootCAs, Certificates: certs}}}
+	}
+
+	if resp, errGet := newClient().Get(srv.URL); errGet == nil {
+		resp.Body.Close()
+		t.Fatal("expected request without client certificate to be rejected")
+	}
+
+	rogue := newTestCertAuthority(t).issueClient(t, "intruder")
+	if resp, errGet := newClient(rogue).Get(srv.URL); errGet == nil {
+		resp.Body.Close()
+		t.Fatal("expected request with an untrusted client certificate to be rejected")
+	}
+
+	resp, err := newClient(ca.issueClient(t, "ops-team")).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("request with valid client certificate failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body := make([]byte, 64)
+	n, _ := resp.Body.Read(body)
+	if got := string(body[:n]); got != "ops-team" {
+		t.Fatalf("client identity = %q, want ops-team", got)
+	}
+}
+
+func TestBuildServerTLSConfig_ServerOnlyWhenUnset(t *testing.T) {
+	tlsConfig, err := buildServerTLSConfig(config.TLSConfig{Enable: true})
+	if err != nil || tlsConfig != nil {
+		t.Fatalf("buildServerTLSConfig() = %v, %v; want nil, nil", tlsConfig, err)
+	}
+	if _, err = buildServerTLSConfig(config.TLSConfig{Enable: true, RequireClientCert: true}); err == nil {
+		t.Fatal("expected error when require-client-cert is set without client-ca")
+	}
+}
+
+func TestClientCertAccessProvider_AuthenticatesVerifiedCertificate(t *testing.T) {
+	ca := newTestCertAuthority(t)
+	caPath := filepath.Join(t.TempDir(), "client-ca.pem")
+	if err := os.WriteFile(caPath, ca.pem, 0o600); err != nil {
+		t.Fatalf("write CA: %v", err)
+	}
+	tlsCfg := config.TLSConfig{Enable: true, ClientCA: caPath, ClientCertAuth: true}
+	tlsConfig, err := buildServerTLSConfig(tlsCfg)
+	if err != nil {
+		t.Fatalf("buildServerTLSConfig: %v", err)
+	}
+
+	certaccess.Register(tlsCfg)
+	t.Cleanup(func() { certaccess.Register(config.TLSConfig{}) })
+	manager := sdkaccess.NewManager()
+	manager.SetProviders(sdkaccess.RegisteredProviders())
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result, authErr := manager.Authenticate(r.Context(), r)
+		if authErr != nil {
+			w.WriteHeader(authErr.HTTPStatusCode())
+			return
+		}
+		_, _ = w.Write([]byte(result.Provider + ":" + result.Principal))
+	}))
+	srv.TLS = tlsConfig
+	srv.StartTLS()
+	defer srv.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(srv.Certificate())
+	newClient := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: rootCAs, Certificates: certs}}}
+	}
+
+	resp, err := newClient().Get(srv.URL)
+	if err != nil {
+		t.Fatalf("request without client certificate failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status without client certificate = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+
+	resp, err = newClient(ca.issueClient(t, "ops-team")).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("request with valid client certificate failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body := make([]byte, 64)
+	n, _ := resp.Body.Read(body)
+	if want := sdkaccess.AccessProviderTypeClientCert + ":ops-team"; resp.StatusCode != http.StatusOK || string(body[:n]) != want {
+		t.Fatalf("response = %d %q, want 200 %q", resp.StatusCode, body[:n], want)
+	}
+}
+
+func TestClientCertAccessProvider_NotRegisteredWithoutOptIn(t *testing.T) {
+	certaccess.Register(config.TLSConfig{Enable: true, ClientCA: "ca.pem"})
+	for _, provider := range sdkaccess.RegisteredProviders() {
+		if provider.Identifier() == sdkaccess.AccessProviderTypeClientCert {
+			t.Fatal("client-cert provider registered without tls.client-cert-auth")
+		}
+	}
+}
@@ -33,6 +33,10 @@ type GeminiTokenStorage struct {
 	// Checked indicates if the associated Cloud AI API has been verified as enabled.
 	Checked bool `json:"checked"`
 
+	// CheckError records why the last Cloud AI API check or enablement failed. It is
+	// cleared once the API is verified as enabled.
+	CheckError string `json:"check_error,omitempty"`
+
 	// Type indicates the authentication provider type, always "gemini" for this storage.
 	Type string `json:"type"`
 }
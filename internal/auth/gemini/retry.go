@@ -0,0 +1,119 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// MaxRetries caps retries of a Gemini login call that failed transiently
+// (network error, 429 or 5xx).
+const MaxRetries = 3
+
+// maxRetryAfter bounds how long a single Retry-After hint may pause a login.
+const maxRetryAfter = time.Minute
+
+// RetryBaseDelay is the first backoff delay after a transient failure; it doubles per retry.
+var RetryBaseDelay = time.Second
+
+// DoWithRetry sends the request built by newRequest and retries with exponential
+// backoff while the call fails transiently: a network error such as a connection reset,
+// or a 429/5xx response. A Retry-After header on such a response replaces the backoff
+// delay for that attempt. Any other response, including 4xx auth errors, is returned at once
+// and the caller owns its body. newRequest is invoked once per attempt so bodies can be replayed.
+func DoWithRetry(ctx context.Context, httpClient *http.Client, label string, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	return doWithRetry(ctx, httpClient, label, false, newRequest)
+}
+
+// DoNonIdempotentWithRetry behaves like DoWithRetry for calls that must not run twice,
+// such as onboardUser: a network error is retried only when it happened while connecting,
+// before any part of the request could reach the server.
+func DoNonIdempotentWithRetry(ctx context.Context, httpClient *http.Client, label string, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	return doWithRetry(ctx, httpClient, label, true, newRequest)
+}
+
+func doWithRetry(ctx context.Context, httpClient *http.Client, label string, connectOnly bool, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	delay := RetryBaseDelay
+	for attempt := 0; ; attempt++ {
+		req, errRequest := newRequest()
+		if errRequest != nil {
+			return nil, errRequest
+		}
+
+		wait := delay
+		resp, errDo := httpClient.Do(req)
+		if errDo != nil {
+			if ctx.Err() != nil || attempt >= MaxRetries || (connectOnly && !isConnectError(errDo)) {
+				return nil, errDo
+			}
+			log.Debugf("gemini %s request failed: %v, retrying in %s", label, errDo, wait)
+		} else if isRetryableStatus(resp.StatusCode) && attempt < MaxRetries {
+			if hinted, ok := retryAfter(resp.Header, time.Now()); ok {
+				wait = hinted
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			if errClose := resp.Body.Close(); errClose != nil {
+				log.Errorf("response body close error: %v", errClose)
+			}
+			log.Debugf("gemini %s returned status %d, retrying in %s", label, resp.StatusCode, wait)
+		} else {
+			return resp, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+}
+
+// isConnectError reports whether err happened while resolving or dialing the host,
+// so the request was never sent.
+func isConnectError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+// The result is capped at maxRetryAfter.
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	var wait time.Duration
+	if seconds, errAtoi := strconv.Atoi(value); errAtoi == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		wait = time.Duration(seconds) * time.Second
+	} else if at, errParse := http.ParseTime(value); errParse == nil {
+		wait = at.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+	} else {
+		return 0, false
+	}
+	if wait > maxRetryAfter {
+		wait = maxRetryAfter
+	}
+	return wait, true
+}
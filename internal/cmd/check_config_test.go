@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func loadCheckConfig(t *testing.T, body string) (*config.Config, string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	body = strings.ReplaceAll(body, "AUTH_DIR", filepath.ToSlash(filepath.Join(dir, "auths")))
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	return cfg, path
+}
+
+func TestCheckConfigGood(t *testing.T) {
+	cfg, path := loadCheckConfig(t, "port: 8317\nauth-dir: \"AUTH_DIR\"\n")
+	var out bytes.Buffer
+	if !CheckConfig(&out, cfg, path, StoreProbe{}) {
+		t.Fatalf("expected check to pass, report:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "Configuration check passed.") {
+		t.Fatalf("unexpected report:\n%s", out.String())
+	}
+}
+
+func TestCheckConfigBad(t *testing.T) {
+	cfg, path := loadCheckConfig(t, strings.Join([]string{
+		"port: 70000",
+		"auth-dir: \"AUTH_DIR\"",
+		"tls:",
+		"  enable: true",
+		"missing-translator-action: \"explode\"",
+		"",
+	}, "\n"))
+	var out bytes.Buffer
+	if CheckConfig(&out, cfg, path, StoreProbe{}) {
+		t.Fatalf("expected check to fail, report:\n%s", out.String())
+	}
+	report := out.String()
+	for _, want := range []string{"port: 70000", "tls.cert", "missing-translator-action", "Configuration check failed."} {
+		if !strings.Contains(report, want) {
+			t.Fatalf("report missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestCheckConfigProbesBackendAndReportsDegraded(t *testing.T) {
+	cfg, path := loadCheckConfig(t, "port: 8317\nauth-dir: \"AUTH_DIR\"\n")
+	probe := StoreProbe{
+		Name:     "postgres",
+		Probe:    func(context.Context) error { return errors.New("connection refused") },
+		Fallback: "read-only",
+	}
+	var out bytes.Buffer
+	if CheckConfig(&out, cfg, path, probe) {
+		t.Fatalf("expected an unreachable backend to fail the check, report:\n%s", out.String())
+	}
+	report := out.String()
+	for _, want := range []string{"token store (postgres): connection refused", "degraded", "read-only"} {
+		if !strings.Contains(report, want) {
+			t.Fatalf("report missing %q:\n%s", want, report)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Package cmd contains CLI helpers. This file implements the -export-openapi mode,
+// which writes an OpenAPI document for the server routes without starting the server.
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/api"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// openAPIExportPassword only exists to make the server register its management routes
+// so they are included in the document. The server built for the export never listens.
+const openAPIExportPassword = "openapi-export"
+
+// BuildOpenAPISpec constructs the HTTP server for cfg, with management routes enabled,
+// and returns the OpenAPI document for its routes.
+func BuildOpenAPISpec(cfg *config.Config, configFilePath string) ([]byte, error) {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	server := api.NewServer(cfg, coreauth.NewManager(nil, nil, nil), sdkaccess.NewManager(), configFilePath,
+		api.WithLocalManagementPassword(openAPIExportPassword),
+		api.WithRequestLoggerFactory(func(*config.Config, string) logging.RequestLogger { return nil }),
+	)
+	return server.OpenAPISpec()
+}
+
+// ExportOpenAPI writes the OpenAPI document to outputPath.
+func ExportOpenAPI(cfg *config.Config, configFilePath, outputPath string) error {
+	spec, errBuild := BuildOpenAPISpec(cfg, configFilePath)
+	if errBuild != nil {
+		return fmt.Errorf("build openapi spec: %w", errBuild)
+	}
+	spec = append(spec, '\n')
+	if errWrite := os.WriteFile(outputPath, spec, 0o644); errWrite != nil {
+		return fmt.Errorf("write openapi spec: %w", errWrite)
+	}
+	return nil
+}
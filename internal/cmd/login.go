@@ -20,6 +20,7 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/gemini"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/geminicli"
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
 	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	log "github.com/sirupsen/logrus"
@@ -27,11 +28,8 @@ import (
 )
 
 const (
-	geminiCLIEndpoint       = "https://cloudcode-pa.googleapis.com"
-	geminiCLIVersion        = "v1internal"
-	geminiCLIUserAgent      = "google-api-nodejs-client/9.15.1"
-	geminiCLIApiClient      = "gl-node/22.17.0"
-	geminiCLIClientMetadata = "ideType=IDE_UNSPECIFIED,platform=PLATFORM_UNSPECIFIED,pluginType=GEMINI"
+	geminiCLIEndpoint = "https://cloudcode-pa.googleapis.com"
+	geminiCLIVersion  = "v1internal"
 )
 
 type projectSelectionRequiredError struct{}
@@ -54,6 +52,9 @@ func DoLogin(cfg *config.Config, projectID string, options *LoginOptions) {
 	}
 
 	ctx := context.Background()
+	if cfg != nil {
+		ctx = withGeminiCLIEndpoint(ctx, cfg.GeminiCLI.Endpoint(""))
+	}
 
 	promptFn := options.Prompt
 	if promptFn == nil {
@@ -145,6 +146,7 @@ func DoLogin(cfg *config.Config, projectID string, options *LoginOptions) {
 		}
 
 		seenProjects := make(map[string]bool)
+		var failedProjects []string
 		for _, candidateID := range projectSelections {
 			log.Infof("Activating project %s", candidateID)
 			if errSetup := performGeminiCLISetup(ctx, httpClient, storage, candidateID); errSetup != nil {
@@ -153,8 +155,14 @@ func DoLogin(cfg *config.Config, projectID string, options *LoginOptions) {
 					showProjectSelectionHelp(storage.Email, projects)
 					return
 				}
-				log.Errorf("Failed to complete user setup: %v", errSetup)
-				return
+				if len(projectSelections) == 1 {
+					log.Errorf("Failed to complete user setup: %v", errSetup)
+					return
+				}
+				// With several projects selected, one failure must not discard the others.
+				log.Warnf("Failed to activate project %s: %v", candidateID, errSetup)
+				failedProjects = append(failedProjects, candidateID)
+				continue
 			}
 			finalID := strings.TrimSpace(storage.ProjectID)
 			if finalID == "" {
@@ -168,6 +176,13 @@ func DoLogin(cfg *config.Config, projectID string, options *LoginOptions) {
 			seenProjects[finalID] = true
 			activatedProjects = append(activatedProjects, finalID)
 		}
+		if len(activatedProjects) == 0 {
+			log.Error("Failed to activate any selected project; aborting login.")
+			return
+		}
+		if len(failedProjects) > 0 {
+			log.Warnf("Continuing with %d activated project(s); failed to activate: %s", len(activatedProjects), strings.Join(failedProjects, ", "))
+		}
 	}
 
 	storage.Auto = false
@@ -389,31 +404,60 @@ func performGeminiCLISetup(ctx context.Context, httpClient *http.Client, storage
 	}
 }
 
+type geminiCLIEndpointKey struct{}
+
+// withGeminiCLIEndpoint makes callGeminiCLI use endpoint instead of geminiCLIEndpoint.
+// An empty endpoint keeps the default.
+func withGeminiCLIEndpoint(ctx context.Context, endpoint string) context.Context {
+	if endpoint == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, geminiCLIEndpointKey{}, endpoint)
+}
+
+func geminiCLIEndpointFrom(ctx context.Context) string {
+	if endpoint, ok := ctx.Value(geminiCLIEndpointKey{}).(string); ok && endpoint != "" {
+		return endpoint
+	}
+	return geminiCLIEndpoint
+}
+
 func callGeminiCLI(ctx context.Context, httpClient *http.Client, endpoint string, body any, result any) error {
-	url := fmt.Sprintf("%s/%s:%s", geminiCLIEndpoint, geminiCLIVersion, endpoint)
+	baseURL := geminiCLIEndpointFrom(ctx)
+	url := fmt.Sprintf("%s/%s:%s", baseURL, geminiCLIVersion, endpoint)
 	if strings.HasPrefix(endpoint, "operations/") {
-		url = fmt.Sprintf("%s/%s", geminiCLIEndpoint, endpoint)
+		url = fmt.Sprintf("%s/%s", baseURL, endpoint)
 	}
 
-	var reader io.Reader
+	var rawBody []byte
 	if body != nil {
-		rawBody, errMarshal := json.Marshal(body)
+		var errMarshal error
+		rawBody, errMarshal = json.Marshal(body)
 		if errMarshal != nil {
 			return fmt.Errorf("marshal request body: %w", errMarshal)
 		}
-		reader = bytes.NewReader(rawBody)
 	}
 
-	req, errRequest := http.NewRequestWithContext(ctx, http.MethodPost, url, reader)
-	if errRequest != nil {
-		return fmt.Errorf("create request: %w", errRequest)
+	// onboardUser provisions resources, so it is only replayed when the request never left.
+	do := gemini.DoWithRetry
+	if endpoint == "onboardUser" {
+		do = gemini.DoNonIdempotentWithRetry
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", geminiCLIUserAgent)
-	req.Header.Set("X-Goog-Api-Client", geminiCLIApiClient)
-	req.Header.Set("Client-Metadata", geminiCLIClientMetadata)
-
-	resp, errDo := httpClient.Do(req)
+	resp, errDo := do(ctx, httpClient, "cli "+endpoint, func() (*http.Request, error) {
+		var reader io.Reader
+		if rawBody != nil {
+			reader = bytes.NewReader(rawBody)
+		}
+		req, errRequest := http.NewRequestWithContext(ctx, http.MethodPost, url, reader)
+		if errRequest != nil {
+			return nil, fmt.Errorf("create request: %w", errRequest)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", geminicli.UserAgent)
+		req.Header.Set("X-Goog-Api-Client", geminicli.APIClient)
+		req.Header.Set("Client-Metadata", geminicli.ClientMetadata)
+		return req, nil
+	})
 	if errDo != nil {
 		return fmt.Errorf("execute request: %w", errDo)
 	}
@@ -441,12 +485,13 @@ func callGeminiCLI(ctx context.Context, httpClient *http.Client, endpoint string
 }
 
 func fetchGCPProjects(ctx context.Context, httpClient *http.Client) ([]interfaces.GCPProjectProjects, error) {
-	req, errRequest := http.NewRequestWithContext(ctx, http.MethodGet, "https://cloudresourcemanager.googleapis.com/v1/projects", nil)
-	if errRequest != nil {
-		return nil, fmt.Errorf("could not create project list request: %w", errRequest)
-	}
-
-	resp, errDo := httpClient.Do(req)
+	resp, errDo := gemini.DoWithRetry(ctx, httpClient, "project list", func() (*http.Request, error) {
+		req, errRequest := http.NewRequestWithContext(ctx, http.MethodGet, "https://cloudresourcemanager.googleapis.com/v1/projects", nil)
+		if errRequest != nil {
+			return nil, fmt.Errorf("could not create project list request: %w", errRequest)
+		}
+		return req, nil
+	})
 	if errDo != nil {
 		return nil, fmt.Errorf("failed to execute project list request: %w", errDo)
 	}
@@ -630,7 +675,7 @@ func checkCloudAPIIsEnabled(ctx context.Context, httpClient *http.Client, projec
 			return false, fmt.Errorf("failed to create request: %w", errRequest)
 		}
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("User-Agent", geminiCLIUserAgent)
+		req.Header.Set("User-Agent", geminicli.UserAgent)
 		resp, errDo := httpClient.Do(req)
 		if errDo != nil {
 			return false, fmt.Errorf("failed to execute request: %w", errDo)
@@ -651,7 +696,7 @@ func checkCloudAPIIsEnabled(ctx context.Context, httpClient *http.Client, projec
 			return false, fmt.Errorf("failed to create request: %w", errRequest)
 		}
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("User-Agent", geminiCLIUserAgent)
+		req.Header.Set("User-Agent", geminicli.UserAgent)
 		resp, errDo = httpClient.Do(req)
 		if errDo != nil {
 			return false, fmt.Errorf("failed to execute request: %w", errDo)
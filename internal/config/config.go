@@ -35,21 +35,41 @@ type Config struct {
 	// TLS config controls HTTPS server settings.
 	TLS TLSConfig `yaml:"tls" json:"tls"`
 
+	// TrustedProxies lists the IP addresses or CIDR ranges of load balancers whose
+	// X-Forwarded-For/Forwarded headers identify the real client. Headers from other
+	// peers are ignored. Empty trusts no proxy.
+	TrustedProxies []string `yaml:"trusted-proxies,omitempty" json:"-"`
+
 	// RemoteManagement nests management-related options under 'remote-management'.
 	RemoteManagement RemoteManagement `yaml:"remote-management" json:"-"`
 
 	// AuthDir is the directory where authentication token files are stored.
 	AuthDir string `yaml:"auth-dir" json:"-"`
 
+	// AuthSecretDir is an optional directory of read-only credential files, such as a mounted
+	// Kubernetes secret. Files are loaded and unloaded as they appear and disappear, and
+	// refreshed tokens are never written back.
+	AuthSecretDir string `yaml:"auth-secret-dir,omitempty" json:"-"`
+
 	// Debug enables or disables debug-level logging and other debug features.
 	Debug bool `yaml:"debug" json:"debug"`
 
+	// StreamRecordDir, when set together with Debug, records the upstream stream payloads
+	// handed to the translators into this directory so they can be replayed offline.
+	// Every HTTP streaming executor records; the AI Studio and Codex websocket relays do not.
+	StreamRecordDir string `yaml:"stream-record-dir,omitempty" json:"stream-record-dir,omitempty"`
+
 	// Pprof config controls the optional pprof HTTP debug server.
 	Pprof PprofConfig `yaml:"pprof" json:"pprof"`
 
 	// CommercialMode disables high-overhead HTTP middleware features to minimize per-request memory usage.
 	CommercialMode bool `yaml:"commercial-mode" json:"commercial-mode"`
 
+	// ShutdownDrainDelaySeconds keeps the listeners open for this long after shutdown begins,
+	// with /healthz reporting "draining", so load balancers stop routing here before new
+	// connections are refused. 0 closes the listeners immediately.
+	ShutdownDrainDelaySeconds int `yaml:"shutdown-drain-delay-seconds,omitempty" json:"shutdown-drain-delay-seconds,omitempty"`
+
 	// LoggingToFile controls whether application logs are written to rotating files or stdout.
 	LoggingToFile bool `yaml:"logging-to-file" json:"logging-to-file"`
 
@@ -64,6 +84,9 @@ type Config struct {
 	// UsageStatisticsEnabled toggles in-memory usage aggregation; when false, usage data is discarded.
 	UsageStatisticsEnabled bool `yaml:"usage-statistics-enabled" json:"usage-statistics-enabled"`
 
+	// Usage controls the time-series aggregation of usage statistics.
+	Usage UsageConfig `yaml:"usage" json:"usage"`
+
 	// DisableCooling disables quota cooldown scheduling when true.
 	DisableCooling bool `yaml:"disable-cooling" json:"disable-cooling"`
 
@@ -71,6 +94,15 @@ type Config struct {
 	RequestRetry int `yaml:"request-retry" json:"request-retry"`
 	// MaxRetryInterval defines the maximum wait time in seconds before retrying a cooled-down credential.
 	MaxRetryInterval int `yaml:"max-retry-interval" json:"max-retry-interval"`
+	// RequestTimeout bounds each non-streaming upstream attempt in seconds. It is enforced
+	// independently of any deadline carried by the client request. 0 disables the limit.
+	RequestTimeout int `yaml:"request-timeout,omitempty" json:"request-timeout,omitempty"`
+	// ProviderRequestTimeouts overrides RequestTimeout for individual provider identifiers.
+	// A value of 0 disables the limit for that provider.
+	ProviderRequestTimeouts map[string]int `yaml:"provider-request-timeouts,omitempty" json:"provider-request-timeouts,omitempty"`
+	// UpstreamRetry retries transient upstream 5xx responses on the same credential inside
+	// every executor, before the failure reaches credential rotation (RequestRetry).
+	UpstreamRetry UpstreamRetryConfig `yaml:"upstream-retry,omitempty" json:"upstream-retry,omitempty"`
 
 	// QuotaExceeded defines the behavior when a quota is exceeded.
 	QuotaExceeded QuotaExceeded `yaml:"quota-exceeded" json:"quota-exceeded"`
@@ -94,6 +126,11 @@ type Config struct {
 	// These are used as fallbacks when the client does not send its own headers.
 	ClaudeHeaderDefaults ClaudeHeaderDefaults `yaml:"claude-header-defaults" json:"claude-header-defaults"`
 
+	// ProviderHeaders maps a provider identifier (claude, codex, gemini-cli, an
+	// openai-compatibility name, ...) to static headers added to every upstream request
+	// sent for that provider. Per-credential headers take precedence.
+	ProviderHeaders map[string]map[string]string `yaml:"provider-headers,omitempty" json:"provider-headers,omitempty"`
+
 	// OpenAICompatibility defines OpenAI API compatibility configurations for external providers.
 	OpenAICompatibility []OpenAICompatibility `yaml:"openai-compatibility" json:"openai-compatibility"`
 
@@ -118,6 +155,43 @@ type Config struct {
 	// Payload defines default and override rules for provider payload parameters.
 	Payload PayloadConfig `yaml:"payload" json:"payload"`
 
+	// Claude holds options for responses translated into the Claude format.
+	Claude ClaudeConfig `yaml:"claude" json:"claude"`
+
+	// Upstream holds settings applied to every outgoing upstream request.
+	Upstream UpstreamConfig `yaml:"upstream" json:"upstream"`
+
+	// GeminiCLI holds Gemini CLI executor settings.
+	GeminiCLI GeminiCLIConfig `yaml:"gemini-cli" json:"gemini-cli"`
+
+	// Vertex holds Vertex AI service-account endpoint settings.
+	Vertex VertexConfig `yaml:"vertex" json:"vertex"`
+
+	// Antigravity holds Antigravity executor settings.
+	Antigravity AntigravityConfig `yaml:"antigravity" json:"antigravity"`
+
+	// GeminiSafetySettings is the default safetySettings policy attached to requests
+	// translated into Gemini formats when the client does not send its own. Empty uses
+	// the built-in policy, which disables blocking.
+	GeminiSafetySettings []GeminiSafetySetting `yaml:"gemini-safety-settings,omitempty" json:"gemini-safety-settings,omitempty"`
+
+	// GeminiUpstreamEncoding selects the wire encoding of non-streaming Gemini API
+	// generateContent calls: "json" (default) or "protobuf". Requests using fields outside
+	// the supported protobuf subset, streams and countTokens always use JSON, and a response
+	// with fields outside the schema is fetched again as JSON rather than decoded partially.
+	GeminiUpstreamEncoding string `yaml:"gemini-upstream-encoding,omitempty" json:"gemini-upstream-encoding,omitempty"`
+
+	// Thinking holds global thinking configuration behavior.
+	Thinking ThinkingConfig `yaml:"thinking" json:"thinking"`
+
+	// ThinkingDefaults defines per-model default thinking applied when a request carries
+	// no thinking configuration. The first matching entry wins.
+	ThinkingDefaults []ThinkingDefault `yaml:"thinking-defaults,omitempty" json:"thinking-defaults,omitempty"`
+
+	// DeclaredModels lists models that always appear in model listings. Until a credential
+	// provides one of them it is listed with "available": false and requests for it fail with 503.
+	DeclaredModels []DeclaredModel `yaml:"declared-models,omitempty" json:"declared-models,omitempty"`
+
 	legacyMigrationPending bool `yaml:"-" json:"-"`
 }
 
@@ -138,6 +212,15 @@ type TLSConfig struct {
 	Cert string `yaml:"cert" json:"cert"`
 	// Key is the path to the TLS private key file.
 	Key string `yaml:"key" json:"key"`
+	// ClientCA is the path to a PEM bundle of CAs used to verify client certificates.
+	// When empty, client certificates are not requested.
+	ClientCA string `yaml:"client-ca,omitempty" json:"client-ca,omitempty"`
+	// RequireClientCert rejects TLS handshakes without a client certificate signed by ClientCA.
+	// When false, a presented certificate is still verified but not required.
+	RequireClientCert bool `yaml:"require-client-cert,omitempty" json:"require-client-cert,omitempty"`
+	// ClientCertAuth accepts a verified client certificate as request credentials, with the
+	// certificate identity as the principal. API keys remain valid alongside it.
+	ClientCertAuth bool `yaml:"client-cert-auth,omitempty" json:"client-cert-auth,omitempty"`
 }
 
 // PprofConfig holds pprof HTTP server settings.
@@ -159,6 +242,31 @@ type RemoteManagement struct {
 	// PanelGitHubRepository overrides the GitHub repository used to fetch the management panel asset.
 	// Accepts either a repository URL (https://github.com/org/repo) or an API releases endpoint.
 	PanelGitHubRepository string `yaml:"panel-github-repository"`
+	// DisableAuthTypeInference stops the auth file listing from inferring a missing "type"
+	// from the file name prefix (claude-, codex-, gemini-, iflow-, qwen-, kimi-).
+	DisableAuthTypeInference bool `yaml:"disable-auth-type-inference,omitempty"`
+	// AccessTokens lists additional plaintext management tokens with a role.
+	// They are only honoured while the management API is enabled via secret-key.
+	AccessTokens []ManagementToken `yaml:"access-tokens,omitempty"`
+	// AuditLog is the path of a JSONL file receiving one record per mutating auth file
+	// management call. Empty disables auditing.
+	AuditLog string `yaml:"audit-log,omitempty"`
+}
+
+// Management API roles. The secret key always grants ManagementRoleAdmin.
+const (
+	// ManagementRoleAdmin grants access to every management endpoint.
+	ManagementRoleAdmin = "admin"
+	// ManagementRoleReadOnly grants access to an allowlist of read endpoints, with credentials redacted.
+	ManagementRoleReadOnly = "read-only"
+)
+
+// ManagementToken is an additional management API token bound to a role.
+type ManagementToken struct {
+	// Token is the plaintext token accepted via Bearer, Basic or X-Management-Key.
+	Token string `yaml:"token"`
+	// Role is either "admin" or "read-only". Unknown values fall back to "read-only".
+	Role string `yaml:"role,omitempty"`
 }
 
 // QuotaExceeded defines the behavior when API quota limits are exceeded.
@@ -176,6 +284,50 @@ type RoutingConfig struct {
 	// Strategy selects the credential selection strategy.
 	// Supported values: "round-robin" (default), "fill-first".
 	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+
+	// Groups configures limits shared by credentials carrying the same "group" attribute.
+	// Credentials in higher-tier groups are preferred while their group has capacity.
+	Groups []AuthGroup `yaml:"groups,omitempty" json:"groups,omitempty"`
+
+	// ModelWeights sets how many group concurrency slots one request for a matching model
+	// occupies, so expensive models consume more of max-concurrency. The first match wins.
+	ModelWeights []ModelWeight `yaml:"model-weights,omitempty" json:"model-weights,omitempty"`
+
+	// Queue holds requests briefly when every credential group is at its limit instead of
+	// rejecting them immediately.
+	Queue RequestQueueConfig `yaml:"queue,omitempty" json:"queue,omitempty"`
+}
+
+// RequestQueueConfig bounds the per-provider FIFO queue in front of the group limits.
+type RequestQueueConfig struct {
+	// MaxDepth caps the requests waiting per provider. 0 disables queueing.
+	MaxDepth int `yaml:"max-depth,omitempty" json:"max-depth,omitempty"`
+	// MaxWaitMs is how long a queued request waits for capacity before failing with 503.
+	// 0 uses the default of 10000.
+	MaxWaitMs int `yaml:"max-wait-ms,omitempty" json:"max-wait-ms,omitempty"`
+}
+
+// ModelWeight assigns a concurrency weight to models matching a name pattern.
+type ModelWeight struct {
+	// Model is the model name or wildcard pattern (e.g., "*-thinking").
+	Model string `yaml:"model" json:"model"`
+	// Weight is the number of concurrency slots a request consumes. Must be at least 1.
+	Weight int `yaml:"weight" json:"weight"`
+}
+
+// AuthGroup defines the limits enforced across all credentials of one group.
+type AuthGroup struct {
+	// Name matches the "group" attribute of credentials.
+	Name string `yaml:"name" json:"name"`
+
+	// Tier orders groups during selection; higher tiers are tried first. Defaults to 0.
+	Tier int `yaml:"tier,omitempty" json:"tier,omitempty"`
+
+	// MaxConcurrency caps in-flight requests across the group. 0 means unlimited.
+	MaxConcurrency int `yaml:"max-concurrency,omitempty" json:"max-concurrency,omitempty"`
+
+	// RPM caps requests started per minute across the group. 0 means unlimited.
+	RPM int `yaml:"rpm,omitempty" json:"rpm,omitempty"`
 }
 
 // OAuthModelAlias defines a model ID alias for a specific channel.
@@ -245,6 +397,212 @@ type AmpUpstreamAPIKeyEntry struct {
 	APIKeys []string `yaml:"api-keys" json:"api-keys"`
 }
 
+// UsageConfig controls the time-series aggregation of usage statistics.
+type UsageConfig struct {
+	// BucketGranularity selects the bucket size: "minute" or "hour" (default).
+	// Finer buckets give better resolution at the cost of memory.
+	BucketGranularity string `yaml:"bucket-granularity,omitempty" json:"bucket-granularity,omitempty"`
+	// BucketRetentionHours controls how long buckets are kept. <= 0 keeps 168 hours (7 days).
+	BucketRetentionHours int `yaml:"bucket-retention-hours,omitempty" json:"bucket-retention-hours,omitempty"`
+	// Pricing lists per-model token prices used to compute request costs.
+	Pricing []ModelPricing `yaml:"pricing,omitempty" json:"pricing,omitempty"`
+	// ToolCallWarnThreshold logs a warning when one request emits more tool calls than this.
+	// 0 disables the warning; tool calls are counted in usage statistics either way.
+	ToolCallWarnThreshold int `yaml:"tool-call-warn-threshold,omitempty" json:"tool-call-warn-threshold,omitempty"`
+}
+
+// ModelPricing sets the USD price per million tokens for a model.
+type ModelPricing struct {
+	// Model is the model name; a trailing "*" matches any model with that prefix.
+	Model string `yaml:"model" json:"model"`
+	// Provider optionally restricts the entry to a single provider (e.g. "gemini").
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+	// InputPerMillion is the price of one million prompt tokens.
+	InputPerMillion float64 `yaml:"input-per-million" json:"input-per-million"`
+	// OutputPerMillion is the price of one million completion tokens.
+	OutputPerMillion float64 `yaml:"output-per-million" json:"output-per-million"`
+	// ThinkingPerMillion is the price of one million reasoning tokens. Leave it unset for
+	// providers that already include reasoning tokens in the completion count.
+	ThinkingPerMillion float64 `yaml:"thinking-per-million,omitempty" json:"thinking-per-million,omitempty"`
+}
+
+// ClaudeConfig holds options for responses translated into the Claude format.
+type ClaudeConfig struct {
+	// IncludeAvgLogprobs surfaces Gemini avgLogprobs in Claude responses for diagnostics.
+	// "usage" writes usage.avg_logprobs, "metadata" writes metadata.avg_logprobs.
+	// Empty drops the field (default).
+	IncludeAvgLogprobs string `yaml:"include-avg-logprobs,omitempty" json:"include-avg-logprobs,omitempty"`
+}
+
+// GeminiSafetySetting is one Gemini safetySettings entry.
+type GeminiSafetySetting struct {
+	// Category is the Gemini harm category, e.g. "HARM_CATEGORY_HARASSMENT".
+	Category string `yaml:"category" json:"category"`
+
+	// Threshold is the blocking threshold, e.g. "BLOCK_ONLY_HIGH" or "OFF".
+	Threshold string `yaml:"threshold" json:"threshold"`
+}
+
+// UpstreamConfig holds settings applied to every outgoing upstream request.
+type UpstreamConfig struct {
+	// InjectRequestIDField maps a provider identifier (e.g. "claude", "codex", or an
+	// openai-compatibility name) to a JSON path (sjson syntax) in the outgoing request body
+	// that receives the request correlation ID.
+	InjectRequestIDField map[string]string `yaml:"inject-request-id-field,omitempty" json:"inject-request-id-field,omitempty"`
+}
+
+// GeminiCLIConfig holds Gemini CLI executor and onboarding settings.
+// Empty header values keep the built-in Gemini CLI defaults. Per-auth metadata keys
+// "user_agent", "api_client" and "client_metadata" take precedence over these values.
+type GeminiCLIConfig struct {
+	UserAgent      string `yaml:"user-agent,omitempty" json:"user-agent,omitempty"`
+	APIClient      string `yaml:"api-client,omitempty" json:"api-client,omitempty"`
+	ClientMetadata string `yaml:"client-metadata,omitempty" json:"client-metadata,omitempty"`
+
+	// OnboardConcurrency bounds how many projects are set up in parallel when onboarding
+	// ALL projects of an account. <= 0 uses 4.
+	OnboardConcurrency int `yaml:"onboard-concurrency,omitempty" json:"onboard-concurrency,omitempty"`
+
+	// BaseURL replaces the Code Assist endpoint (https://cloudcode-pa.googleapis.com) used by
+	// the executor and during onboarding. Empty keeps the default.
+	BaseURL string `yaml:"base-url,omitempty" json:"base-url,omitempty"`
+
+	// Regions maps a region name to a Code Assist base URL. An auth selects one through its
+	// "region" attribute or metadata value; unknown or missing regions use BaseURL.
+	Regions map[string]string `yaml:"regions,omitempty" json:"regions,omitempty"`
+}
+
+// Endpoint returns the Code Assist base URL configured for region, or "" when neither a
+// matching region nor a base URL is configured.
+func (c GeminiCLIConfig) Endpoint(region string) string {
+	return regionalEndpoint(c.BaseURL, c.Regions, region)
+}
+
+// VertexConfig holds Vertex AI service-account endpoint settings. Vertex API-key entries
+// keep using their own base-url.
+type VertexConfig struct {
+	// BaseURL replaces the location-derived endpoint (https://{location}-aiplatform.googleapis.com).
+	// "{location}" is replaced with the credential location. Empty keeps the default.
+	BaseURL string `yaml:"base-url,omitempty" json:"base-url,omitempty"`
+
+	// Regions maps a region name to a Vertex base URL. An auth selects one through its
+	// "region" attribute or metadata value, falling back to its location.
+	Regions map[string]string `yaml:"regions,omitempty" json:"regions,omitempty"`
+}
+
+// Endpoint returns the Vertex base URL configured for region and location, or "" when
+// nothing matches and no base URL is configured.
+func (c VertexConfig) Endpoint(region, location string) string {
+	if strings.TrimSpace(region) == "" {
+		region = location
+	}
+	endpoint := regionalEndpoint(c.BaseURL, c.Regions, region)
+	return strings.ReplaceAll(endpoint, "{location}", strings.TrimSpace(location))
+}
+
+// regionalEndpoint picks the regions entry for region, falling back to base. Trailing
+// slashes are trimmed so callers can append paths.
+func regionalEndpoint(base string, regions map[string]string, region string) string {
+	if region = strings.TrimSpace(region); region != "" {
+		if endpoint := strings.TrimSpace(regions[region]); endpoint != "" {
+			return strings.TrimRight(endpoint, "/")
+		}
+	}
+	return strings.TrimRight(strings.TrimSpace(base), "/")
+}
+
+// UpstreamRetryConfig configures the per-request retry of transient upstream 5xx responses.
+// Antigravity "no capacity" responses are left to the Antigravity executor's own retry.
+type UpstreamRetryConfig struct {
+	// Attempts is the total number of upstream attempts per request, including the first.
+	// <= 1 disables the retry. Default is 0.
+	Attempts int `yaml:"attempts,omitempty" json:"attempts,omitempty"`
+
+	// StatusCodes lists the retryable statuses. Empty means 500, 502, 503 and 504.
+	StatusCodes []int `yaml:"status-codes,omitempty" json:"status-codes,omitempty"`
+
+	// BackoffMs is the wait before the first retry; it doubles on each further retry.
+	// <= 0 means 250.
+	BackoffMs int `yaml:"backoff-ms,omitempty" json:"backoff-ms,omitempty"`
+
+	// MaxBackoffMs caps the wait between retries. <= 0 means 2000.
+	MaxBackoffMs int `yaml:"max-backoff-ms,omitempty" json:"max-backoff-ms,omitempty"`
+}
+
+// AntigravityConfig holds Antigravity executor settings.
+type AntigravityConfig struct {
+	// UserAgent overrides the default Antigravity User-Agent. A per-auth "user_agent"
+	// attribute or metadata value takes precedence.
+	UserAgent string `yaml:"user-agent,omitempty" json:"user-agent,omitempty"`
+
+	// ClaudePathModels lists model name patterns (wildcards allowed, case-insensitive) whose
+	// non-streaming requests use the Claude-style streaming endpoint. Empty keeps the
+	// built-in detection ("*claude*", "*gemini-3-pro*").
+	ClaudePathModels []string `yaml:"claude-path-models,omitempty" json:"claude-path-models,omitempty"`
+
+	// StreamReconnect selects what happens when a stream drops on a network error: "none"
+	// (default) fails the stream, "restart" re-sends the request to the same base URL.
+	// Antigravity cannot resume a partial response, so a stream is only restarted while
+	// nothing has been sent to the client; a later drop always fails the stream.
+	StreamReconnect string `yaml:"stream-reconnect,omitempty" json:"stream-reconnect,omitempty"`
+
+	// StreamReconnectAttempts caps reconnects per stream when StreamReconnect is enabled. <= 0 means 1.
+	StreamReconnectAttempts int `yaml:"stream-reconnect-attempts,omitempty" json:"stream-reconnect-attempts,omitempty"`
+
+	// RepairTruncatedStream closes a non-streaming response whose upstream stream ended early
+	// into valid JSON and marks it truncated (finishReason MAX_TOKENS, or OTHER on a read error)
+	// instead of failing the request or dropping the incomplete chunk.
+	RepairTruncatedStream bool `yaml:"repair-truncated-stream,omitempty" json:"repair-truncated-stream,omitempty"`
+
+	// NoCapacityRetryJitter randomizes the wait between "no capacity" retries so instances
+	// do not retry in lockstep: "full" (default), "decorrelated" or "none".
+	NoCapacityRetryJitter string `yaml:"no-capacity-retry-jitter,omitempty" json:"no-capacity-retry-jitter,omitempty"`
+
+	// NoCapacityRetryMaxDelayMs caps the wait between "no capacity" retries. <= 0 means 2000.
+	NoCapacityRetryMaxDelayMs int `yaml:"no-capacity-retry-max-delay-ms,omitempty" json:"no-capacity-retry-max-delay-ms,omitempty"`
+}
+
+// ThinkingConfig holds global thinking configuration behavior.
+type ThinkingConfig struct {
+	// MalformedSuffix controls how unparsable model suffixes such as "model(med ium)" are handled:
+	// "ignore" parses them like any other suffix (default), "strip" removes the suffix with a
+	// warning, and "error" rejects the request.
+	MalformedSuffix string `yaml:"malformed-suffix,omitempty" json:"malformed-suffix,omitempty"`
+
+	// BudgetMaxTokensRatio caps Claude thinking budgets at this fraction of the effective
+	// max_tokens (e.g. 0.8). Budgets are always kept strictly below max_tokens; 0 applies
+	// only that constraint.
+	BudgetMaxTokensRatio float64 `yaml:"budget-max-tokens-ratio,omitempty" json:"budget-max-tokens-ratio,omitempty"`
+
+	// AutoFallback controls what "auto" (or -1) resolves to on models without dynamic
+	// thinking: "mid" uses medium or the mid-range budget (default), "max" the highest
+	// level or maximum budget, and "min" the lowest level or minimum budget.
+	AutoFallback string `yaml:"auto-fallback,omitempty" json:"auto-fallback,omitempty"`
+}
+
+// ThinkingDefault sets the default thinking for models matching a name pattern.
+type ThinkingDefault struct {
+	// Model is the model name or wildcard pattern (e.g., "gpt-5*", "*-thinking").
+	Model string `yaml:"model" json:"model"`
+	// Thinking uses model suffix syntax: a level ("low", "high"), a budget ("8192"),
+	// "none" or "auto". The value is clamped to the model's thinking support.
+	Thinking string `yaml:"thinking" json:"thinking"`
+}
+
+// DeclaredModel describes a model that is listed even when no credential provides it.
+type DeclaredModel struct {
+	// ID is the model name clients use in requests.
+	ID string `yaml:"id" json:"id"`
+	// OwnedBy is reported as the model owner (e.g., "google", "anthropic").
+	OwnedBy string `yaml:"owned-by,omitempty" json:"owned-by,omitempty"`
+	// Type is the model family reported in listings (e.g., "gemini", "claude", "openai").
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+	// DisplayName is the human-readable name shown in listings.
+	DisplayName string `yaml:"display-name,omitempty" json:"display-name,omitempty"`
+	// Description is an optional free-form description.
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
 // PayloadConfig defines default and override parameter rules applied to provider payloads.
 type PayloadConfig struct {
 	// Default defines rules that only set parameters when they are missing in the payload.
@@ -313,6 +671,9 @@ type ClaudeKey struct {
 	// Higher values are preferred; defaults to 0.
 	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
 
+	// Group assigns this credential to a routing group with shared limits (see routing.groups).
+	Group string `yaml:"group,omitempty" json:"group,omitempty"`
+
 	// Prefix optionally namespaces models for this credential (e.g., "teamA/claude-sonnet-4").
 	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
 
@@ -361,6 +722,9 @@ type CodexKey struct {
 	// Higher values are preferred; defaults to 0.
 	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
 
+	// Group assigns this credential to a routing group with shared limits (see routing.groups).
+	Group string `yaml:"group,omitempty" json:"group,omitempty"`
+
 	// Prefix optionally namespaces models for this credential (e.g., "teamA/gpt-5-codex").
 	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
 
@@ -409,6 +773,9 @@ type GeminiKey struct {
 	// Higher values are preferred; defaults to 0.
 	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
 
+	// Group assigns this credential to a routing group with shared limits (see routing.groups).
+	Group string `yaml:"group,omitempty" json:"group,omitempty"`
+
 	// Prefix optionally namespaces models for this credential (e.g., "teamA/gemini-3-pro-preview").
 	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
 
@@ -453,6 +820,9 @@ type OpenAICompatibility struct {
 	// Higher values are preferred; defaults to 0.
 	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
 
+	// Group assigns these credentials to a routing group with shared limits (see routing.groups).
+	Group string `yaml:"group,omitempty" json:"group,omitempty"`
+
 	// Prefix optionally namespaces model aliases for this provider (e.g., "teamA/kimi-k2").
 	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
 
@@ -549,7 +919,20 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 	cfg.Pprof.Addr = DefaultPprofAddr
 	cfg.AmpCode.RestrictManagementToLocalhost = false // Default to false: API key auth is sufficient
 	cfg.RemoteManagement.PanelGitHubRepository = DefaultPanelGitHubRepository
-	if err = yaml.Unmarshal(data, &cfg); err != nil {
+	var (
+		document        yaml.Node
+		resolvedSecrets map[string]bool
+	)
+	if err = yaml.Unmarshal(data, &document); err == nil {
+		// Expand ${env:...}, ${file:...} and other registered secret references before decoding.
+		if resolvedSecrets, err = resolveSecretNodes(&document); err != nil {
+			return nil, fmt.Errorf("failed to resolve config secrets: %w", err)
+		}
+		if document.Kind != 0 {
+			err = document.Decode(&cfg)
+		}
+	}
+	if err != nil {
 		if optional {
 			// In cloud deploy mode, if YAML parsing fails, return empty config instead of error.
 			return &Config{}, nil
@@ -583,8 +966,11 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 		cfg.RemoteManagement.SecretKey = hashed
 
 		// Persist the hashed value back to the config file to avoid re-hashing on next startup.
-		// Preserve YAML comments and ordering; update only the nested key.
-		_ = SaveConfigPreserveCommentsUpdateNestedScalar(configFile, []string{"remote-management", "secret-key"}, hashed)
+		// Preserve YAML comments and ordering; update only the nested key. A secret reference
+		// stays in the file and is hashed again on every load.
+		if !resolvedSecrets["remote-management.secret-key"] {
+			_ = SaveConfigPreserveCommentsUpdateNestedScalar(configFile, []string{"remote-management", "secret-key"}, hashed)
+		}
 	}
 
 	cfg.RemoteManagement.PanelGitHubRepository = strings.TrimSpace(cfg.RemoteManagement.PanelGitHubRepository)
@@ -629,6 +1015,15 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 	// Validate raw payload rules and drop invalid entries.
 	cfg.SanitizePayloadRules()
 
+	// Drop incomplete thinking defaults.
+	cfg.SanitizeThinkingDefaults()
+
+	// Normalize upstream request ID field mappings.
+	cfg.SanitizeUpstream()
+
+	// Drop empty management tokens and normalize their roles.
+	cfg.SanitizeManagementTokens()
+
 	// NOTE: Legacy migration persistence is intentionally disabled together with
 	// startup legacy migration to keep startup read-only for config.yaml.
 	// Re-enable the block below if automatic startup migration is needed again.
@@ -703,6 +1098,63 @@ func payloadRawString(value any) ([]byte, bool) {
 	}
 }
 
+// SanitizeUpstream lower-cases provider keys of upstream request ID field mappings
+// and drops entries with an empty provider or path.
+func (cfg *Config) SanitizeUpstream() {
+	if cfg == nil || len(cfg.Upstream.InjectRequestIDField) == 0 {
+		return
+	}
+	out := make(map[string]string, len(cfg.Upstream.InjectRequestIDField))
+	for provider, path := range cfg.Upstream.InjectRequestIDField {
+		provider = strings.ToLower(strings.TrimSpace(provider))
+		path = strings.TrimSpace(path)
+		if provider == "" || path == "" {
+			continue
+		}
+		out[provider] = path
+	}
+	cfg.Upstream.InjectRequestIDField = out
+}
+
+// SanitizeManagementTokens drops management tokens without a value and normalizes
+// roles so that anything other than "admin" is treated as "read-only".
+func (cfg *Config) SanitizeManagementTokens() {
+	if cfg == nil || len(cfg.RemoteManagement.AccessTokens) == 0 {
+		return
+	}
+	out := make([]ManagementToken, 0, len(cfg.RemoteManagement.AccessTokens))
+	for _, entry := range cfg.RemoteManagement.AccessTokens {
+		entry.Token = strings.TrimSpace(entry.Token)
+		if entry.Token == "" {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(entry.Role), ManagementRoleAdmin) {
+			entry.Role = ManagementRoleAdmin
+		} else {
+			entry.Role = ManagementRoleReadOnly
+		}
+		out = append(out, entry)
+	}
+	cfg.RemoteManagement.AccessTokens = out
+}
+
+// SanitizeThinkingDefaults trims thinking default entries and drops those missing a model or value.
+func (cfg *Config) SanitizeThinkingDefaults() {
+	if cfg == nil || len(cfg.ThinkingDefaults) == 0 {
+		return
+	}
+	out := make([]ThinkingDefault, 0, len(cfg.ThinkingDefaults))
+	for _, entry := range cfg.ThinkingDefaults {
+		entry.Model = strings.TrimSpace(entry.Model)
+		entry.Thinking = strings.ToLower(strings.TrimSpace(entry.Thinking))
+		if entry.Model == "" || entry.Thinking == "" {
+			continue
+		}
+		out = append(out, entry)
+	}
+	cfg.ThinkingDefaults = out
+}
+
 // SanitizeOAuthModelAlias normalizes and deduplicates global OAuth model name aliases.
 // It trims whitespace, normalizes channel keys to lower-case, drops empty entries,
 // allows multiple aliases per upstream name, and ensures aliases are unique within each channel.
@@ -1181,6 +1633,11 @@ func mergeNodePreserve(dst, src *yaml.Node, path ...[]string) {
 			dst.Content = dst.Content[:len(src.Content)]
 		}
 	case yaml.ScalarNode, yaml.AliasNode:
+		// Keep secret references such as ${env:NAME} whose expansion is unchanged, so
+		// saving the config never writes resolved secrets to disk.
+		if dst.Kind == yaml.ScalarNode && src.Kind == yaml.ScalarNode && dst.Value != src.Value && expandedScalarValue(dst.Value) == src.Value {
+			return
+		}
 		// For scalars, update Tag and Value but keep Style from dst to preserve quoting
 		dst.Kind = src.Kind
 		dst.Tag = src.Tag
@@ -1422,7 +1879,7 @@ func matchSequenceElement(original []*yaml.Node, used []bool, target *yaml.Node)
 				if used[i] || original[i] == nil || original[i].Kind != yaml.ScalarNode {
 					continue
 				}
-				if strings.TrimSpace(original[i].Value) == val {
+				if strings.TrimSpace(expandedScalarValue(original[i].Value)) == val {
 					return i
 				}
 			}
@@ -1457,7 +1914,7 @@ func sequenceElementIdentity(node *yaml.Node) string {
 		if keyNode == nil || valNode == nil || valNode.Kind != yaml.ScalarNode {
 			continue
 		}
-		val := strings.TrimSpace(valNode.Value)
+		val := strings.TrimSpace(expandedScalarValue(valNode.Value))
 		if val != "" {
 			return strings.ToLower(strings.TrimSpace(keyNode.Value)) + "=" + val
 		}
@@ -1477,7 +1934,7 @@ func mappingScalarValue(node *yaml.Node, key string) string {
 			continue
 		}
 		if strings.ToLower(strings.TrimSpace(keyNode.Value)) == lowerKey {
-			return strings.TrimSpace(valNode.Value)
+			return strings.TrimSpace(expandedScalarValue(valNode.Value))
 		}
 	}
 	return ""
@@ -7,6 +7,7 @@ package config
 // SDKConfig represents the application's configuration, loaded from a YAML file.
 type SDKConfig struct {
 	// ProxyURL is the URL of an optional proxy server to use for outbound requests.
+	// It is the global default; a per-auth or per-key proxy URL overrides it.
 	ProxyURL string `yaml:"proxy-url" json:"proxy-url"`
 
 	// ForceModelPrefix requires explicit model prefixes (e.g., "teamA/gemini-3-pro-preview")
@@ -24,22 +25,248 @@ type SDKConfig struct {
 	// Default is false (disabled).
 	PassthroughHeaders bool `yaml:"passthrough-headers" json:"passthrough-headers"`
 
+	// ForwardHeaders forwards selected upstream response headers to clients under a
+	// namespaced name, independently of PassthroughHeaders.
+	ForwardHeaders ForwardHeadersConfig `yaml:"forward-headers,omitempty" json:"forward-headers,omitempty"`
+
 	// Streaming configures server-side streaming behavior (keep-alives and safe bootstrap retries).
 	Streaming StreamingConfig `yaml:"streaming" json:"streaming"`
 
 	// NonStreamKeepAliveInterval controls how often blank lines are emitted for non-streaming responses.
 	// <= 0 disables keep-alives. Value is in seconds.
 	NonStreamKeepAliveInterval int `yaml:"nonstream-keepalive-interval,omitempty" json:"nonstream-keepalive-interval,omitempty"`
+
+	// MaxToolRounds limits how many tool-call round trips an incoming conversation may contain.
+	// <= 0 disables the check. Default is 0.
+	MaxToolRounds int `yaml:"max-tool-rounds,omitempty" json:"max-tool-rounds,omitempty"`
+
+	// ToolRoundsAction selects the behavior when MaxToolRounds is exceeded.
+	// Supported values: "reject" (default) returns 400, "warn" only logs a warning.
+	ToolRoundsAction string `yaml:"tool-rounds-action,omitempty" json:"tool-rounds-action,omitempty"`
+
+	// MaxPromptTokens rejects requests whose locally estimated prompt size exceeds this many
+	// tokens with 400 before any upstream call. <= 0 disables the check.
+	MaxPromptTokens int64 `yaml:"max-prompt-tokens,omitempty" json:"max-prompt-tokens,omitempty"`
+
+	// MaxPromptBytes rejects request bodies larger than this many bytes with 400 before any
+	// upstream call. <= 0 disables the check.
+	MaxPromptBytes int64 `yaml:"max-prompt-bytes,omitempty" json:"max-prompt-bytes,omitempty"`
+
+	// PromptLimits overrides MaxPromptTokens for matching models. The first match wins.
+	PromptLimits []PromptLimit `yaml:"prompt-limits,omitempty" json:"prompt-limits,omitempty"`
+
+	// APIKeyModelAccess restricts which models individual client API keys may request.
+	// Keys without an entry may use every model.
+	APIKeyModelAccess []APIKeyModelAccess `yaml:"api-key-model-access,omitempty" json:"api-key-model-access,omitempty"`
+
+	// AdminAPIKeys lists client API keys treated as admin callers. Only these keys may use
+	// the X-Force-Provider and X-Force-Auth headers to bypass credential selection.
+	AdminAPIKeys []string `yaml:"admin-api-keys,omitempty" json:"admin-api-keys,omitempty"`
+
+	// MissingTranslatorAction selects the behavior when no translator is registered for the
+	// client/provider format pair. Supported values: "passthrough" (default) forwards the payload
+	// untranslated, "reject" returns 501 listing the available targets.
+	MissingTranslatorAction string `yaml:"missing-translator-action,omitempty" json:"missing-translator-action,omitempty"`
+
+	// TranslatorSelfTest checks at startup that every client format can be translated to every
+	// built-in provider format and back. Supported values: "off" (default), "warn" logs the
+	// missing pairs, "fail" aborts startup.
+	TranslatorSelfTest string `yaml:"translator-self-test,omitempty" json:"translator-self-test,omitempty"`
+
+	// MergeConsecutiveRoles lists target formats (claude, openai, gemini, gemini-cli,
+	// antigravity) whose translated requests get adjacent same-role messages merged,
+	// for upstreams that require strictly alternating turns.
+	MergeConsecutiveRoles []string `yaml:"merge-consecutive-roles,omitempty" json:"merge-consecutive-roles,omitempty"`
+
+	// UnknownRequestFields maps provider keys to how translated request fields the target
+	// format does not define are handled: "passthrough", "strip" or "error" (HTTP 400).
+	// Providers without an entry strip when their upstream rejects unknown fields (claude,
+	// codex, gemini, vertex, aistudio) and pass through otherwise.
+	UnknownRequestFields map[string]string `yaml:"unknown-request-fields,omitempty" json:"unknown-request-fields,omitempty"`
+
+	// EmptyResponse selects how non-streaming responses without any assistant text or tool
+	// calls are handled: "pass" (default) returns them unchanged, "retry" repeats the request
+	// once, and "error" returns HTTP 502.
+	EmptyResponse string `yaml:"empty-response,omitempty" json:"empty-response,omitempty"`
+
+	// StripThinking removes reasoning/thought content from responses while keeping usage counts.
+	// Clients can override it per request with the X-Strip-Thinking header.
+	StripThinking bool `yaml:"strip-thinking,omitempty" json:"strip-thinking,omitempty"`
+
+	// StripHistoryThinking removes reasoning/thought content that clients echo back in
+	// earlier assistant turns before the request is translated, keeping final answers.
+	StripHistoryThinking bool `yaml:"strip-history-thinking,omitempty" json:"strip-history-thinking,omitempty"`
+
+	// Transforms lists request/response transforms, by registered name, applied in order
+	// to every proxied request. Transforms are registered through the SDK builder.
+	Transforms []string `yaml:"transforms,omitempty" json:"transforms,omitempty"`
+
+	// Moderation configures the inbound content check run before requests are forwarded.
+	Moderation ModerationConfig `yaml:"moderation,omitempty" json:"moderation,omitempty"`
+
+	// CountTokensCache caches token count responses by model and request content.
+	CountTokensCache CountTokensCacheConfig `yaml:"count-tokens-cache,omitempty" json:"count-tokens-cache,omitempty"`
+
+	// IdempotencyCache replays cached non-streaming responses for requests that repeat an
+	// Idempotency-Key header.
+	IdempotencyCache IdempotencyCacheConfig `yaml:"idempotency-cache,omitempty" json:"idempotency-cache,omitempty"`
+
+	// ResponseCache reuses non-streaming responses for identical deterministic requests.
+	ResponseCache ResponseCacheConfig `yaml:"response-cache,omitempty" json:"response-cache,omitempty"`
+
+	// ChoiceFanOut configures how OpenAI chat requests with n>1 are served by providers
+	// that return a single completion per call.
+	ChoiceFanOut ChoiceFanOutConfig `yaml:"choice-fan-out,omitempty" json:"choice-fan-out,omitempty"`
+
+	// ModelFallbacks lists ordered fallback targets per model, tried when the model has no
+	// usable credentials or its credentials are exhausted. The first matching entry wins.
+	ModelFallbacks []ModelFallback `yaml:"model-fallbacks,omitempty" json:"model-fallbacks,omitempty"`
+}
+
+// ForwardHeadersConfig selects upstream response headers forwarded to clients.
+type ForwardHeadersConfig struct {
+	// Names lists upstream header names to forward, case-insensitive, with "*" wildcards
+	// (e.g. "x-request-id", "anthropic-ratelimit-*"). Hop-by-hop and Set-Cookie headers
+	// are never forwarded.
+	Names []string `yaml:"names,omitempty" json:"names,omitempty"`
+
+	// Prefix is prepended to each forwarded header, after dropping a leading "X-" from the
+	// upstream name, so "x-request-id" becomes "X-Upstream-Request-Id". Default "X-Upstream-".
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+}
+
+// ModerationConfig selects the moderator applied to every proxied request.
+type ModerationConfig struct {
+	// Moderator names the moderator to run: "" disables moderation, "keywords" uses the
+	// keyword lists below, and any other name refers to a moderator registered through the
+	// SDK builder.
+	Moderator string `yaml:"moderator,omitempty" json:"moderator,omitempty"`
+
+	// BlockKeywords rejects requests whose text contains any entry (case-insensitive) with 403.
+	BlockKeywords []string `yaml:"block-keywords,omitempty" json:"block-keywords,omitempty"`
+
+	// FlagKeywords forwards matching requests but logs them as flagged.
+	FlagKeywords []string `yaml:"flag-keywords,omitempty" json:"flag-keywords,omitempty"`
 }
 
 // StreamingConfig holds server streaming behavior configuration.
 type StreamingConfig struct {
-	// KeepAliveSeconds controls how often the server emits SSE heartbeats (": keep-alive\n\n").
-	// <= 0 disables keep-alives. Default is 0.
+	// KeepAliveSeconds controls how long a stream may stay idle before the server emits an
+	// SSE heartbeat (": keep-alive\n\n"). <= 0 disables keep-alives. Default is 0.
 	KeepAliveSeconds int `yaml:"keepalive-seconds,omitempty" json:"keepalive-seconds,omitempty"`
 
 	// BootstrapRetries controls how many times the server may retry a streaming request before any bytes are sent,
 	// to allow auth rotation / transient recovery.
 	// <= 0 disables bootstrap retries. Default is 0.
 	BootstrapRetries int `yaml:"bootstrap-retries,omitempty" json:"bootstrap-retries,omitempty"`
+
+	// ToolArgsOnTruncation controls how streamed tool-call arguments that end mid-JSON are handled:
+	// "error" ends the stream with an error event (default), "close" closes the JSON so it parses.
+	// When the upstream stream fails in the middle of a tool call, "error" drops the call and
+	// "close" flushes it; either way the stream then ends with the upstream error, not a
+	// completion event. Only the streaming translators between Claude and OpenAI
+	// chat-completions formats (either direction) apply it; other translators pass tool
+	// arguments through as received.
+	ToolArgsOnTruncation string `yaml:"tool-args-on-truncation,omitempty" json:"tool-args-on-truncation,omitempty"`
+
+	// ClaudeEventFraming controls the SSE framing of Claude-format streams: "anthropic" (default)
+	// names every event ("event: content_block_delta"), "openai" emits bare "data:" lines for
+	// clients that only parse OpenAI-style streams.
+	ClaudeEventFraming string `yaml:"claude-event-framing,omitempty" json:"claude-event-framing,omitempty"`
+
+	// UsageProgressTokens emits a provisional usage event each time the locally estimated
+	// output of a stream grows by this many tokens, for upstreams that report usage only at
+	// the end. The final upstream usage stays authoritative. <= 0 disables it. Default is 0.
+	UsageProgressTokens int64 `yaml:"usage-progress-tokens,omitempty" json:"usage-progress-tokens,omitempty"`
+}
+
+// CountTokensCacheConfig configures the LRU cache in front of upstream token counting.
+type CountTokensCacheConfig struct {
+	// Size is the maximum number of cached counts. <= 0 disables the cache. Default is 0.
+	Size int `yaml:"size,omitempty" json:"size,omitempty"`
+
+	// TTLSeconds is how long a cached count stays valid. <= 0 uses 300 seconds.
+	TTLSeconds int `yaml:"ttl-seconds,omitempty" json:"ttl-seconds,omitempty"`
+}
+
+// IdempotencyCacheConfig configures the cache of completed non-streaming responses keyed
+// by the client's Idempotency-Key header.
+type IdempotencyCacheConfig struct {
+	// Size is the maximum number of cached responses. <= 0 disables the cache. Default is 0.
+	Size int `yaml:"size,omitempty" json:"size,omitempty"`
+
+	// TTLSeconds is how long a cached response can be replayed. <= 0 uses 60 seconds.
+	TTLSeconds int `yaml:"ttl-seconds,omitempty" json:"ttl-seconds,omitempty"`
+}
+
+// ResponseCacheConfig configures the cache of non-streaming responses keyed by client API key,
+// model and request content. Only deterministic requests (temperature 0 or top_k 1) are cached.
+type ResponseCacheConfig struct {
+	// Size is the maximum number of cached responses. <= 0 disables the cache. Default is 0.
+	Size int `yaml:"size,omitempty" json:"size,omitempty"`
+
+	// TTLSeconds is how long a cached response can be reused. <= 0 uses 300 seconds.
+	TTLSeconds int `yaml:"ttl-seconds,omitempty" json:"ttl-seconds,omitempty"`
+
+	// IncludeTools also caches requests that declare tools. Default is false.
+	IncludeTools bool `yaml:"include-tools,omitempty" json:"include-tools,omitempty"`
+}
+
+// ChoiceFanOutConfig configures the fan-out of OpenAI n>1 chat requests into parallel
+// single-choice upstream calls.
+type ChoiceFanOutConfig struct {
+	// Providers lists the providers that ignore n and return one completion per call.
+	// Requests served by any of them are fanned out. Empty uses claude and codex.
+	Providers []string `yaml:"providers,omitempty" json:"providers,omitempty"`
+
+	// MaxChoices is the largest n accepted for a fanned-out request. <= 0 uses 8.
+	MaxChoices int `yaml:"max-choices,omitempty" json:"max-choices,omitempty"`
+
+	// Concurrency bounds the upstream calls in flight for one fanned-out request.
+	// <= 0 runs every choice in parallel, up to MaxChoices.
+	Concurrency int `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+}
+
+// APIKeyModelAccess lists the model patterns a client API key is allowed or denied.
+// Patterns are matched case-insensitively, without the thinking suffix, against both the
+// requested model and every upstream model it resolves to through prefixes, renames and
+// aliases; a request is allowed only when all of them pass. "*" matches any sequence of
+// characters.
+type APIKeyModelAccess struct {
+	// APIKey is the client API key the rule applies to.
+	APIKey string `yaml:"api-key" json:"api-key"`
+	// Allow lists permitted model patterns. When empty, every model not denied is allowed.
+	Allow []string `yaml:"allow,omitempty" json:"allow,omitempty"`
+	// Deny lists forbidden model patterns. Deny takes precedence over Allow.
+	Deny []string `yaml:"deny,omitempty" json:"deny,omitempty"`
+}
+
+// PromptLimit caps the estimated prompt size for models matching a pattern.
+// Patterns are matched case-insensitively, without the thinking suffix, against the
+// requested model name only; the first matching entry wins and overrides
+// MaxPromptTokens. "*" matches any sequence of characters.
+type PromptLimit struct {
+	// Model is the model name or wildcard pattern (e.g., "gemini-2.5-flash*").
+	Model string `yaml:"model" json:"model"`
+	// MaxTokens is the estimated prompt token cap. <= 0 disables the check for the model.
+	MaxTokens int64 `yaml:"max-tokens" json:"max-tokens"`
+}
+
+// ModelFallback maps a model pattern to the targets tried, in order, once its own
+// credential pool is empty or exhausted. Patterns are matched case-insensitively against
+// the requested model name without its thinking suffix; "*" matches any sequence of characters.
+type ModelFallback struct {
+	// Model is the model name or wildcard pattern (e.g., "gpt-4o").
+	Model string `yaml:"model" json:"model"`
+	// Targets are tried in order until one serves the request.
+	Targets []ModelFallbackTarget `yaml:"targets" json:"targets"`
+}
+
+// ModelFallbackTarget is a provider/model pair serving a fallback request.
+type ModelFallbackTarget struct {
+	// Provider restricts the target to one provider key (e.g., "codex" or an
+	// openai-compatibility name). Empty allows every provider serving Model.
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+	// Model is the model requested from the target provider.
+	Model string `yaml:"model" json:"model"`
 }
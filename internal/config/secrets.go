@@ -0,0 +1,175 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SecretResolver resolves the reference part of a ${scheme:reference} config value,
+// e.g. "NAME" for ${env:NAME} or "path#key" for ${vault:path#key}.
+type SecretResolver interface {
+	ResolveSecret(reference string) (string, error)
+}
+
+// SecretResolverFunc adapts an ordinary function to SecretResolver.
+type SecretResolverFunc func(reference string) (string, error)
+
+// ResolveSecret calls f(reference).
+func (f SecretResolverFunc) ResolveSecret(reference string) (string, error) {
+	return f(reference)
+}
+
+var (
+	secretResolversMu sync.RWMutex
+	secretResolvers   = map[string]SecretResolver{
+		"env":  SecretResolverFunc(resolveEnvSecret),
+		"file": SecretResolverFunc(resolveFileSecret),
+	}
+)
+
+// secretReferencePattern matches ${scheme:reference}. References of unregistered schemes are
+// kept literally.
+var secretReferencePattern = regexp.MustCompile(`\$\{([A-Za-z][A-Za-z0-9_-]*):([^}]*)\}`)
+
+// RegisterSecretResolver registers resolver for ${scheme:...} references in config values,
+// replacing any resolver already registered for scheme. A nil resolver removes the scheme.
+// The built-in "env" and "file" resolvers may be overridden the same way.
+func RegisterSecretResolver(scheme string, resolver SecretResolver) {
+	scheme = strings.ToLower(strings.TrimSpace(scheme))
+	if scheme == "" {
+		return
+	}
+	secretResolversMu.Lock()
+	defer secretResolversMu.Unlock()
+	if resolver == nil {
+		delete(secretResolvers, scheme)
+		return
+	}
+	secretResolvers[scheme] = resolver
+}
+
+func lookupSecretResolver(scheme string) SecretResolver {
+	secretResolversMu.RLock()
+	defer secretResolversMu.RUnlock()
+	return secretResolvers[strings.ToLower(scheme)]
+}
+
+// ResolveSecretReferences expands every ${scheme:reference} of a registered scheme in value.
+// It reports whether anything was expanded; values without references are returned unchanged.
+func ResolveSecretReferences(value string) (string, bool, error) {
+	if !strings.Contains(value, "${") {
+		return value, false, nil
+	}
+	var (
+		resolveErr error
+		expanded   bool
+	)
+	out := secretReferencePattern.ReplaceAllStringFunc(value, func(match string) string {
+		if resolveErr != nil {
+			return match
+		}
+		parts := secretReferencePattern.FindStringSubmatch(match)
+		resolver := lookupSecretResolver(parts[1])
+		if resolver == nil {
+			return match
+		}
+		secret, err := resolver.ResolveSecret(strings.TrimSpace(parts[2]))
+		if err != nil {
+			resolveErr = fmt.Errorf("resolve %s: %w", match, err)
+			return match
+		}
+		expanded = true
+		return secret
+	})
+	if resolveErr != nil {
+		return value, false, resolveErr
+	}
+	return out, expanded, nil
+}
+
+// resolveSecretNodes expands secret references in the string values of a parsed config
+// document, before it is decoded. Mapping keys are never expanded. It returns the dotted
+// paths of the values that were expanded.
+func resolveSecretNodes(node *yaml.Node) (map[string]bool, error) {
+	resolved := make(map[string]bool)
+	var walk func(node *yaml.Node, path string) error
+	walk = func(node *yaml.Node, path string) error {
+		if node == nil {
+			return nil
+		}
+		switch node.Kind {
+		case yaml.DocumentNode:
+			for _, child := range node.Content {
+				if err := walk(child, path); err != nil {
+					return err
+				}
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				childPath := node.Content[i].Value
+				if path != "" {
+					childPath = path + "." + childPath
+				}
+				if err := walk(node.Content[i+1], childPath); err != nil {
+					return err
+				}
+			}
+		case yaml.SequenceNode:
+			for i, child := range node.Content {
+				if err := walk(child, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		case yaml.ScalarNode:
+			if node.ShortTag() != "!!str" {
+				return nil
+			}
+			value, expanded, err := ResolveSecretReferences(node.Value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if expanded {
+				// Let the expanded value be typed like a plain scalar so that, e.g.,
+				// port: ${env:PORT} still decodes into an int.
+				node.Value, node.Tag, node.Style = value, "", 0
+				resolved[path] = true
+			}
+		}
+		return nil
+	}
+	return resolved, walk(node, "")
+}
+
+// expandedScalarValue returns value with its secret references expanded, or value itself
+// when it has none or they cannot be resolved. Saving the config uses it to match file
+// values against the loaded (expanded) config.
+func expandedScalarValue(value string) string {
+	expanded, ok, err := ResolveSecretReferences(value)
+	if err != nil || !ok {
+		return value
+	}
+	return expanded
+}
+
+func resolveEnvSecret(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+// resolveFileSecret reads a secret file such as a Docker or Kubernetes secret mount,
+// dropping the trailing newline most tools write.
+func resolveFileSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigOptional_ResolvesSecretReferences(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "proxy-key")
+	if err := os.WriteFile(secretFile, []byte("file-secret\n"), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+	t.Setenv("CLIPROXY_TEST_API_KEY", "env-secret")
+	t.Setenv("CLIPROXY_TEST_PORT", "9123")
+
+	configFile := filepath.Join(dir, "config.yaml")
+	content := "port: ${env:CLIPROXY_TEST_PORT}\n" +
+		"api-keys:\n" +
+		"  - \"${env:CLIPROXY_TEST_API_KEY}\"\n" +
+		"  - \"${file:" + secretFile + "}\"\n" +
+		"  - \"literal-key\"\n" +
+		"  - \"prefix-${unknown:ref}\"\n"
+	if err := os.WriteFile(configFile, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := LoadConfigOptional(configFile, false)
+	if err != nil {
+		t.Fatalf("LoadConfigOptional: %v", err)
+	}
+	if cfg.Port != 9123 {
+		t.Fatalf("port = %d, want 9123", cfg.Port)
+	}
+	want := []string{"env-secret", "file-secret", "literal-key", "prefix-${unknown:ref}"}
+	if strings.Join(cfg.APIKeys, ",") != strings.Join(want, ",") {
+		t.Fatalf("api-keys = %q, want %q", cfg.APIKeys, want)
+	}
+
+	// Saving the loaded config keeps the references instead of writing the secrets.
+	if err = SaveConfigPreserveComments(configFile, cfg); err != nil {
+		t.Fatalf("SaveConfigPreserveComments: %v", err)
+	}
+	saved, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if strings.Contains(string(saved), "env-secret") || strings.Contains(string(saved), "file-secret") {
+		t.Fatalf("saved config contains resolved secrets:\n%s", saved)
+	}
+
+	if err = os.WriteFile(configFile, []byte("api-keys:\n  - \"${env:CLIPROXY_TEST_UNSET_KEY}\"\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err = LoadConfigOptional(configFile, false); err == nil || !strings.Contains(err.Error(), "CLIPROXY_TEST_UNSET_KEY") {
+		t.Fatalf("unset env reference error = %v", err)
+	}
+}
+
+func TestRegisterSecretResolver(t *testing.T) {
+	RegisterSecretResolver("vault", SecretResolverFunc(func(reference string) (string, error) {
+		return "vault:" + reference, nil
+	}))
+	t.Cleanup(func() { RegisterSecretResolver("vault", nil) })
+
+	value, expanded, err := ResolveSecretReferences("${vault:kv/proxy#api-key}")
+	if err != nil || !expanded || value != "vault:kv/proxy#api-key" {
+		t.Fatalf("ResolveSecretReferences = %q, %v, %v", value, expanded, err)
+	}
+	if value, expanded, _ = ResolveSecretReferences("plain $value"); expanded || value != "plain $value" {
+		t.Fatalf("literal value changed: %q", value)
+	}
+}
@@ -0,0 +1,316 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Validate reports configuration problems that would prevent the server from starting
+// or from reaching its upstreams. It expects a config returned by LoadConfig, so entries
+// already dropped by the Sanitize* helpers are not reported again. All problems are
+// returned joined; nil means the configuration is usable.
+func (cfg *Config) Validate() error {
+	if cfg == nil {
+		return errors.New("config is nil")
+	}
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if cfg.Port <= 0 || cfg.Port > 65535 {
+		add("port: %d is out of range 1-65535", cfg.Port)
+	}
+	if strings.TrimSpace(cfg.AuthDir) == "" {
+		add("auth-dir: must not be empty")
+	}
+	if cfg.TLS.Enable {
+		errs = append(errs, validateFile("tls.cert", cfg.TLS.Cert)...)
+		errs = append(errs, validateFile("tls.key", cfg.TLS.Key)...)
+		if strings.TrimSpace(cfg.TLS.ClientCA) != "" {
+			errs = append(errs, validateFile("tls.client-ca", cfg.TLS.ClientCA)...)
+		} else if cfg.TLS.RequireClientCert {
+			add("tls.client-ca: must be set when tls.require-client-cert is true")
+		} else if cfg.TLS.ClientCertAuth {
+			add("tls.client-ca: must be set when tls.client-cert-auth is true")
+		}
+	}
+	if cfg.RequestRetry < 0 {
+		add("request-retry: must not be negative")
+	}
+	if cfg.MaxRetryInterval < 0 {
+		add("max-retry-interval: must not be negative")
+	}
+	if cfg.RequestTimeout < 0 {
+		add("request-timeout: must not be negative")
+	}
+	if cfg.ShutdownDrainDelaySeconds < 0 {
+		add("shutdown-drain-delay-seconds: must not be negative")
+	}
+	if cfg.CountTokensCache.Size < 0 {
+		add("count-tokens-cache.size: must not be negative")
+	}
+	if cfg.CountTokensCache.TTLSeconds < 0 {
+		add("count-tokens-cache.ttl-seconds: must not be negative")
+	}
+	if cfg.Usage.ToolCallWarnThreshold < 0 {
+		add("usage.tool-call-warn-threshold: must not be negative")
+	}
+	if cfg.IdempotencyCache.Size < 0 {
+		add("idempotency-cache.size: must not be negative")
+	}
+	if cfg.IdempotencyCache.TTLSeconds < 0 {
+		add("idempotency-cache.ttl-seconds: must not be negative")
+	}
+	if cfg.ResponseCache.Size < 0 {
+		add("response-cache.size: must not be negative")
+	}
+	if cfg.ResponseCache.TTLSeconds < 0 {
+		add("response-cache.ttl-seconds: must not be negative")
+	}
+	if cfg.ChoiceFanOut.MaxChoices < 0 {
+		add("choice-fan-out.max-choices: must not be negative")
+	}
+	if cfg.ChoiceFanOut.Concurrency < 0 {
+		add("choice-fan-out.concurrency: must not be negative")
+	}
+	groupNames := make(map[string]struct{}, len(cfg.Routing.Groups))
+	for i, group := range cfg.Routing.Groups {
+		name := strings.TrimSpace(group.Name)
+		if name == "" {
+			add("routing.groups[%d]: name is required", i)
+		} else if _, dup := groupNames[name]; dup {
+			add("routing.groups[%d]: duplicate group %q", i, name)
+		}
+		groupNames[name] = struct{}{}
+		if group.MaxConcurrency < 0 {
+			add("routing.groups[%d].max-concurrency: must not be negative", i)
+		}
+		if group.RPM < 0 {
+			add("routing.groups[%d].rpm: must not be negative", i)
+		}
+	}
+	if cfg.Routing.Queue.MaxDepth < 0 {
+		add("routing.queue.max-depth: must not be negative")
+	}
+	if cfg.Routing.Queue.MaxWaitMs < 0 {
+		add("routing.queue.max-wait-ms: must not be negative")
+	}
+	for i, weight := range cfg.Routing.ModelWeights {
+		if strings.TrimSpace(weight.Model) == "" {
+			add("routing.model-weights[%d]: model is required", i)
+		}
+		if weight.Weight < 1 {
+			add("routing.model-weights[%d].weight: must be at least 1", i)
+		}
+	}
+	for i, setting := range cfg.GeminiSafetySettings {
+		if strings.TrimSpace(setting.Category) == "" || strings.TrimSpace(setting.Threshold) == "" {
+			add("gemini-safety-settings[%d]: category and threshold are required", i)
+		}
+	}
+	for i, code := range cfg.UpstreamRetry.StatusCodes {
+		if code < 500 || code > 599 {
+			add("upstream-retry.status-codes[%d]: must be a 5xx status, got %d", i, code)
+		}
+	}
+	for _, provider := range slices.Sorted(maps.Keys(cfg.ProviderRequestTimeouts)) {
+		if strings.TrimSpace(provider) == "" {
+			add("provider-request-timeouts: provider name must not be empty")
+		}
+		if cfg.ProviderRequestTimeouts[provider] < 0 {
+			add("provider-request-timeouts.%s: must not be negative", provider)
+		}
+	}
+	for i, entry := range cfg.TrustedProxies {
+		if !validTrustedProxy(entry) {
+			add("trusted-proxies[%d]: %q is not an IP address or CIDR range", i, entry)
+		}
+	}
+	errs = append(errs, validateURL("proxy-url", cfg.ProxyURL)...)
+	if strings.ContainsAny(cfg.ForwardHeaders.Prefix, " \t:") {
+		add("forward-headers.prefix: %q is not a valid header name prefix", cfg.ForwardHeaders.Prefix)
+	}
+	errs = append(errs, validateURL("gemini-cli.base-url", cfg.GeminiCLI.BaseURL)...)
+	for _, region := range slices.Sorted(maps.Keys(cfg.GeminiCLI.Regions)) {
+		errs = append(errs, validateURL("gemini-cli.regions."+region, cfg.GeminiCLI.Regions[region])...)
+	}
+	errs = append(errs, validateURL("vertex.base-url", strings.ReplaceAll(cfg.Vertex.BaseURL, "{location}", "us-central1"))...)
+	for _, region := range slices.Sorted(maps.Keys(cfg.Vertex.Regions)) {
+		errs = append(errs, validateURL("vertex.regions."+region, strings.ReplaceAll(cfg.Vertex.Regions[region], "{location}", "us-central1"))...)
+	}
+
+	for i, key := range cfg.GeminiKey {
+		field := fmt.Sprintf("gemini-api-key[%d]", i)
+		if strings.TrimSpace(key.APIKey) == "" {
+			add("%s.api-key: must not be empty", field)
+		}
+		errs = append(errs, validateURL(field+".base-url", key.BaseURL)...)
+		errs = append(errs, validateURL(field+".proxy-url", key.ProxyURL)...)
+	}
+	for i, key := range cfg.ClaudeKey {
+		field := fmt.Sprintf("claude-api-key[%d]", i)
+		if strings.TrimSpace(key.APIKey) == "" {
+			add("%s.api-key: must not be empty", field)
+		}
+		errs = append(errs, validateURL(field+".base-url", key.BaseURL)...)
+		errs = append(errs, validateURL(field+".proxy-url", key.ProxyURL)...)
+	}
+	for i, key := range cfg.CodexKey {
+		field := fmt.Sprintf("codex-api-key[%d]", i)
+		if strings.TrimSpace(key.APIKey) == "" {
+			add("%s.api-key: must not be empty", field)
+		}
+		errs = append(errs, validateURL(field+".base-url", key.BaseURL)...)
+		errs = append(errs, validateURL(field+".proxy-url", key.ProxyURL)...)
+	}
+	for i, key := range cfg.VertexCompatAPIKey {
+		field := fmt.Sprintf("vertex-api-key[%d]", i)
+		if strings.TrimSpace(key.APIKey) == "" {
+			add("%s.api-key: must not be empty", field)
+		}
+		errs = append(errs, validateURL(field+".base-url", key.BaseURL)...)
+		errs = append(errs, validateURL(field+".proxy-url", key.ProxyURL)...)
+	}
+	for i, compat := range cfg.OpenAICompatibility {
+		field := fmt.Sprintf("openai-compatibility[%d]", i)
+		if strings.TrimSpace(compat.Name) == "" {
+			add("%s.name: must not be empty", field)
+		}
+		errs = append(errs, validateURL(field+".base-url", compat.BaseURL)...)
+	}
+
+	errs = append(errs, validateEnum("thinking.malformed-suffix", cfg.Thinking.MalformedSuffix, "ignore", "strip", "error")...)
+	errs = append(errs, validateEnum("thinking.auto-fallback", cfg.Thinking.AutoFallback, "mid", "max", "min")...)
+	if ratio := cfg.Thinking.BudgetMaxTokensRatio; ratio < 0 || ratio >= 1 {
+		add("thinking.budget-max-tokens-ratio: must be between 0 and 1 (exclusive), got %v", ratio)
+	}
+	errs = append(errs, validateEnum("streaming.tool-args-on-truncation", cfg.Streaming.ToolArgsOnTruncation, "error", "close")...)
+	errs = append(errs, validateEnum("streaming.claude-event-framing", cfg.Streaming.ClaudeEventFraming, "anthropic", "openai")...)
+	if cfg.Streaming.UsageProgressTokens < 0 {
+		add("streaming.usage-progress-tokens: must not be negative")
+	}
+	errs = append(errs, validateEnum("antigravity.stream-reconnect", cfg.Antigravity.StreamReconnect, "none", "restart")...)
+	errs = append(errs, validateEnum("antigravity.no-capacity-retry-jitter", cfg.Antigravity.NoCapacityRetryJitter, "full", "decorrelated", "none")...)
+	errs = append(errs, validateEnum("missing-translator-action", cfg.MissingTranslatorAction, "passthrough", "reject")...)
+	errs = append(errs, validateEnum("translator-self-test", cfg.TranslatorSelfTest, "off", "warn", "fail")...)
+	errs = append(errs, validateEnum("empty-response", cfg.EmptyResponse, "pass", "retry", "error")...)
+	errs = append(errs, validateEnum("gemini-upstream-encoding", cfg.GeminiUpstreamEncoding, "json", "protobuf")...)
+	for _, provider := range slices.Sorted(maps.Keys(cfg.UnknownRequestFields)) {
+		errs = append(errs, validateEnum("unknown-request-fields."+provider, cfg.UnknownRequestFields[provider], "passthrough", "strip", "error")...)
+	}
+	for i, target := range cfg.MergeConsecutiveRoles {
+		errs = append(errs, validateEnum(fmt.Sprintf("merge-consecutive-roles[%d]", i), target, "claude", "openai", "gemini", "gemini-cli", "antigravity")...)
+	}
+	errs = append(errs, validateEnum("usage.bucket-granularity", cfg.Usage.BucketGranularity, "minute", "hour")...)
+	for i, rule := range cfg.APIKeyModelAccess {
+		if strings.TrimSpace(rule.APIKey) == "" {
+			add("api-key-model-access[%d].api-key: must not be empty", i)
+		}
+	}
+	for i, key := range cfg.AdminAPIKeys {
+		if strings.TrimSpace(key) == "" {
+			add("admin-api-keys[%d]: must not be empty", i)
+		}
+	}
+	for i, limit := range cfg.PromptLimits {
+		if strings.TrimSpace(limit.Model) == "" {
+			add("prompt-limits[%d].model: must not be empty", i)
+		}
+	}
+	for i, fallback := range cfg.ModelFallbacks {
+		if strings.TrimSpace(fallback.Model) == "" {
+			add("model-fallbacks[%d].model: must not be empty", i)
+		}
+		for j, target := range fallback.Targets {
+			if strings.TrimSpace(target.Model) == "" {
+				add("model-fallbacks[%d].targets[%d].model: must not be empty", i, j)
+			}
+		}
+	}
+	for i, model := range cfg.DeclaredModels {
+		if strings.TrimSpace(model.ID) == "" {
+			add("declared-models[%d].id: must not be empty", i)
+		}
+	}
+	for _, provider := range slices.Sorted(maps.Keys(cfg.ProviderHeaders)) {
+		if strings.TrimSpace(provider) == "" {
+			add("provider-headers: provider name must not be empty")
+		}
+		for _, name := range slices.Sorted(maps.Keys(cfg.ProviderHeaders[provider])) {
+			if strings.TrimSpace(name) == "" || strings.ContainsAny(name, " :\t\r\n") {
+				add("provider-headers.%s: invalid header name %q", provider, name)
+			}
+		}
+	}
+	for i, price := range cfg.Usage.Pricing {
+		field := fmt.Sprintf("usage.pricing[%d]", i)
+		if strings.TrimSpace(price.Model) == "" {
+			add("%s.model: must not be empty", field)
+		}
+		if price.InputPerMillion < 0 || price.OutputPerMillion < 0 || price.ThinkingPerMillion < 0 {
+			add("%s: prices must not be negative", field)
+		}
+	}
+	errs = append(errs, validateEnum("claude.include-avg-logprobs", cfg.Claude.IncludeAvgLogprobs, "usage", "metadata")...)
+
+	return errors.Join(errs...)
+}
+
+func validateURL(field, raw string) []error {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return []error{fmt.Errorf("%s: invalid URL %q: %w", field, raw, err)}
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return []error{fmt.Errorf("%s: URL %q must include scheme and host", field, raw)}
+	}
+	return nil
+}
+
+func validateFile(field, path string) []error {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return []error{fmt.Errorf("%s: must be set when tls.enable is true", field)}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return []error{fmt.Errorf("%s: %w", field, err)}
+	}
+	if info.IsDir() {
+		return []error{fmt.Errorf("%s: %s is a directory", field, path)}
+	}
+	return nil
+}
+
+func validateEnum(field, value string, allowed ...string) []error {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return nil
+	}
+	for _, candidate := range allowed {
+		if value == candidate {
+			return nil
+		}
+	}
+	return []error{fmt.Errorf("%s: unsupported value %q (expected one of %s)", field, value, strings.Join(allowed, ", "))}
+}
+
+func validTrustedProxy(entry string) bool {
+	entry = strings.TrimSpace(entry)
+	if strings.Contains(entry, "/") {
+		_, _, err := net.ParseCIDR(entry)
+		return err == nil
+	}
+	return net.ParseIP(entry) != nil
+}
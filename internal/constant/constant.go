@@ -24,4 +24,7 @@ const (
 
 	// Antigravity represents the Antigravity response format identifier.
 	Antigravity = "antigravity"
+
+	// OllamaChat represents the Ollama /api/chat format identifier.
+	OllamaChat = "ollama.chat"
 )
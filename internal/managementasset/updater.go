@@ -42,9 +42,73 @@ var (
 	currentConfigPtr    atomic.Pointer[config.Config]
 	schedulerOnce       sync.Once
 	schedulerConfigPath atomic.Value
+	schedulerRunning    atomic.Bool
 	sfGroup             singleflight.Group
+	lastStatusMu        sync.RWMutex
+	lastStatus          Status
 )
 
+// Status describes the outcome of the most recent management asset update check.
+type Status struct {
+	// AutoUpdaterRunning reports whether the periodic background check has been started.
+	AutoUpdaterRunning bool `json:"auto_updater_running"`
+	// LastCheck is when the last check ran; zero when no check has run yet.
+	LastCheck time.Time `json:"last_check"`
+	// LastResult is "updated", "up-to-date", "fallback" or "failed".
+	LastResult string `json:"last_result,omitempty"`
+	LastError  string `json:"last_error,omitempty"`
+	// CurrentVersion is the release tag of the local asset, known once it matched or was
+	// downloaded from a release. CurrentHash is the SHA-256 of the local file.
+	CurrentVersion string `json:"current_version,omitempty"`
+	CurrentHash    string `json:"current_hash,omitempty"`
+	// AvailableVersion and AvailableHash describe the latest published release asset.
+	AvailableVersion string `json:"available_version,omitempty"`
+	AvailableHash    string `json:"available_hash,omitempty"`
+	// LastUpdated is when the local asset was last replaced by the updater.
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+// CurrentStatus returns the status recorded by the most recent update check.
+func CurrentStatus() Status {
+	lastStatusMu.RLock()
+	status := lastStatus
+	lastStatusMu.RUnlock()
+	status.AutoUpdaterRunning = schedulerRunning.Load()
+	return status
+}
+
+func recordStatus(status Status) {
+	lastStatusMu.Lock()
+	defer lastStatusMu.Unlock()
+	if status.LastUpdated.IsZero() {
+		status.LastUpdated = lastStatus.LastUpdated
+	}
+	if status.CurrentVersion == "" && status.CurrentHash == lastStatus.CurrentHash {
+		status.CurrentVersion = lastStatus.CurrentVersion
+	}
+	lastStatus = status
+}
+
+// CheckNow runs an update check immediately, bypassing the minimum interval between
+// checks, and returns the resulting status. It uses the configuration stored through
+// SetCurrentConfig and the static directory of the config path given to StartAutoUpdater.
+func CheckNow(ctx context.Context) (Status, error) {
+	cfg := currentConfigPtr.Load()
+	if cfg == nil {
+		return CurrentStatus(), errors.New("management asset updater: config not yet available")
+	}
+	if cfg.RemoteManagement.DisableControlPanel {
+		return CurrentStatus(), errors.New("management asset updater: control panel disabled")
+	}
+	configPath, _ := schedulerConfigPath.Load().(string)
+	staticDir := StaticDir(configPath)
+	if strings.TrimSpace(staticDir) == "" {
+		return CurrentStatus(), errors.New("management asset updater: static directory unavailable")
+	}
+	ensureLatestManagementHTML(ctx, staticDir, cfg.ProxyURL, cfg.RemoteManagement.PanelGitHubRepository, true)
+	return CurrentStatus(), nil
+}
+
 // SetCurrentConfig stores the latest configuration snapshot for management asset decisions.
 func SetCurrentConfig(cfg *config.Config) {
 	if cfg == nil {
@@ -66,6 +130,7 @@ func StartAutoUpdater(ctx context.Context, configFilePath string) {
 	schedulerConfigPath.Store(configFilePath)
 
 	schedulerOnce.Do(func() {
+		schedulerRunning.Store(true)
 		go runAutoUpdater(ctx)
 	})
 }
@@ -77,6 +142,7 @@ func runAutoUpdater(ctx context.Context) {
 
 	ticker := time.NewTicker(updateCheckInterval)
 	defer ticker.Stop()
+	defer schedulerRunning.Store(false)
 
 	runOnce := func() {
 		cfg := currentConfigPtr.Load()
@@ -122,7 +188,8 @@ type releaseAsset struct {
 }
 
 type releaseResponse struct {
-	Assets []releaseAsset `json:"assets"`
+	TagName string         `json:"tag_name"`
+	Assets  []releaseAsset `json:"assets"`
 }
 
 // StaticDir resolves the directory that stores the management control panel asset.
@@ -175,6 +242,12 @@ func FilePath(configFilePath string) string {
 // EnsureLatestManagementHTML checks the latest management.html asset and updates the local copy when needed.
 // It coalesces concurrent sync attempts and returns whether the asset exists after the sync attempt.
 func EnsureLatestManagementHTML(ctx context.Context, staticDir string, proxyURL string, panelRepository string) bool {
+	return ensureLatestManagementHTML(ctx, staticDir, proxyURL, panelRepository, false)
+}
+
+// ensureLatestManagementHTML implements EnsureLatestManagementHTML; force skips the
+// minimum interval between checks.
+func ensureLatestManagementHTML(ctx context.Context, staticDir string, proxyURL string, panelRepository string, force bool) bool {
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -190,7 +263,7 @@ func EnsureLatestManagementHTML(ctx context.Context, staticDir string, proxyURL
 		lastUpdateCheckMu.Lock()
 		now := time.Now()
 		timeSinceLastAttempt := now.Sub(lastUpdateCheckTime)
-		if !lastUpdateCheckTime.IsZero() && timeSinceLastAttempt < managementSyncMinInterval {
+		if !force && !lastUpdateCheckTime.IsZero() && timeSinceLastAttempt < managementSyncMinInterval {
 			lastUpdateCheckMu.Unlock()
 			log.Debugf(
 				"management asset sync skipped by throttle: last attempt %v ago (interval %v)",
@@ -202,6 +275,9 @@ func EnsureLatestManagementHTML(ctx context.Context, staticDir string, proxyURL
 		lastUpdateCheckTime = now
 		lastUpdateCheckMu.Unlock()
 
+		status := Status{LastCheck: now, LastResult: "failed"}
+		defer func() { recordStatus(status) }()
+
 		localFileMissing := false
 		if _, errStat := os.Stat(localPath); errStat != nil {
 			if errors.Is(errStat, os.ErrNotExist) {
@@ -213,6 +289,7 @@ func EnsureLatestManagementHTML(ctx context.Context, staticDir string, proxyURL
 
 		if errMkdirAll := os.MkdirAll(staticDir, 0o755); errMkdirAll != nil {
 			log.WithError(errMkdirAll).Warn("failed to prepare static directory for management asset")
+			status.LastError = errMkdirAll.Error()
 			return nil, nil
 		}
 
@@ -226,31 +303,42 @@ func EnsureLatestManagementHTML(ctx context.Context, staticDir string, proxyURL
 			}
 			localHash = ""
 		}
+		status.CurrentHash = localHash
 
-		asset, remoteHash, err := fetchLatestAsset(ctx, client, releaseURL)
+		asset, remoteHash, version, err := fetchLatestAsset(ctx, client, releaseURL)
 		if err != nil {
+			status.LastError = err.Error()
 			if localFileMissing {
 				log.WithError(err).Warn("failed to fetch latest management release information, trying fallback page")
 				if ensureFallbackManagementHTML(ctx, client, localPath) {
-					return nil, nil
+					status.LastResult = "fallback"
+					status.CurrentHash, _ = fileSHA256(localPath)
+					status.LastUpdated = time.Now()
 				}
 				return nil, nil
 			}
 			log.WithError(err).Warn("failed to fetch latest management release information")
 			return nil, nil
 		}
+		status.AvailableVersion = version
+		status.AvailableHash = remoteHash
 
 		if remoteHash != "" && localHash != "" && strings.EqualFold(remoteHash, localHash) {
 			log.Debug("management asset is already up to date")
+			status.LastResult = "up-to-date"
+			status.CurrentVersion = version
 			return nil, nil
 		}
 
 		data, downloadedHash, err := downloadAsset(ctx, client, asset.BrowserDownloadURL)
 		if err != nil {
+			status.LastError = err.Error()
 			if localFileMissing {
 				log.WithError(err).Warn("failed to download management asset, trying fallback page")
 				if ensureFallbackManagementHTML(ctx, client, localPath) {
-					return nil, nil
+					status.LastResult = "fallback"
+					status.CurrentHash, _ = fileSHA256(localPath)
+					status.LastUpdated = time.Now()
 				}
 				return nil, nil
 			}
@@ -264,10 +352,15 @@ func EnsureLatestManagementHTML(ctx context.Context, staticDir string, proxyURL
 
 		if err = atomicWriteFile(localPath, data); err != nil {
 			log.WithError(err).Warn("failed to update management asset on disk")
+			status.LastError = err.Error()
 			return nil, nil
 		}
 
 		log.Infof("management asset updated successfully (hash=%s)", downloadedHash)
+		status.LastResult = "updated"
+		status.CurrentHash = downloadedHash
+		status.CurrentVersion = version
+		status.LastUpdated = time.Now()
 		return nil, nil
 	})
 
@@ -323,14 +416,14 @@ func resolveReleaseURL(repo string) string {
 	return defaultManagementReleaseURL
 }
 
-func fetchLatestAsset(ctx context.Context, client *http.Client, releaseURL string) (*releaseAsset, string, error) {
+func fetchLatestAsset(ctx context.Context, client *http.Client, releaseURL string) (*releaseAsset, string, string, error) {
 	if strings.TrimSpace(releaseURL) == "" {
 		releaseURL = defaultManagementReleaseURL
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, releaseURL, nil)
 	if err != nil {
-		return nil, "", fmt.Errorf("create release request: %w", err)
+		return nil, "", "", fmt.Errorf("create release request: %w", err)
 	}
 	req.Header.Set("Accept", "application/vnd.github+json")
 	req.Header.Set("User-Agent", httpUserAgent)
@@ -341,7 +434,7 @@ func fetchLatestAsset(ctx context.Context, client *http.Client, releaseURL strin
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, "", fmt.Errorf("execute release request: %w", err)
+		return nil, "", "", fmt.Errorf("execute release request: %w", err)
 	}
 	defer func() {
 		_ = resp.Body.Close()
@@ -349,23 +442,23 @@ func fetchLatestAsset(ctx context.Context, client *http.Client, releaseURL strin
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		return nil, "", fmt.Errorf("unexpected release status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return nil, "", "", fmt.Errorf("unexpected release status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
 	}
 
 	var release releaseResponse
 	if err = json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return nil, "", fmt.Errorf("decode release response: %w", err)
+		return nil, "", "", fmt.Errorf("decode release response: %w", err)
 	}
 
 	for i := range release.Assets {
 		asset := &release.Assets[i]
 		if strings.EqualFold(asset.Name, managementAssetName) {
 			remoteHash := parseDigest(asset.Digest)
-			return asset, remoteHash, nil
+			return asset, remoteHash, release.TagName, nil
 		}
 	}
 
-	return nil, "", fmt.Errorf("management asset %s not found in latest release", managementAssetName)
+	return nil, "", "", fmt.Errorf("management asset %s not found in latest release", managementAssetName)
 }
 
 func downloadAsset(ctx context.Context, client *http.Client, downloadURL string) ([]byte, string, error) {
@@ -0,0 +1,64 @@
+package registry
+
+import "strings"
+
+// SetDeclaredModels replaces the set of models that are always listed, even when no
+// client provides them. Declared models without a registered client are reported by
+// GetAvailableModels with "available": false. Entries with an empty ID are ignored.
+func (r *ModelRegistry) SetDeclaredModels(models []*ModelInfo) {
+	declared := make(map[string]*ModelInfo, len(models))
+	order := make([]string, 0, len(models))
+	for _, model := range models {
+		if model == nil {
+			continue
+		}
+		id := strings.TrimSpace(model.ID)
+		if id == "" {
+			continue
+		}
+		if _, dup := declared[id]; !dup {
+			order = append(order, id)
+		}
+		info := cloneModelInfo(model)
+		info.ID = id
+		if info.Object == "" {
+			info.Object = "model"
+		}
+		declared[id] = info
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.declaredModels = declared
+	r.declaredOrder = order
+}
+
+// IsDeclaredUnavailable reports whether modelID is a declared model that no registered
+// client currently provides.
+func (r *ModelRegistry) IsDeclaredUnavailable(modelID string) bool {
+	modelID = strings.TrimSpace(modelID)
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if _, ok := r.declaredModels[modelID]; !ok {
+		return false
+	}
+	registration, ok := r.models[modelID]
+	return !ok || registration == nil || registration.Count <= 0
+}
+
+// appendDeclaredModels adds declared models missing from listed, in declaration order,
+// flagged with "available": false. The caller must hold r.mutex.
+func (r *ModelRegistry) appendDeclaredModels(models []map[string]any, listed map[string]struct{}, handlerType string) []map[string]any {
+	for _, id := range r.declaredOrder {
+		if _, ok := listed[id]; ok {
+			continue
+		}
+		model := r.convertModelToMap(r.declaredModels[id], handlerType)
+		if model == nil {
+			continue
+		}
+		model["available"] = false
+		models = append(models, model)
+	}
+	return models
+}
@@ -0,0 +1,57 @@
+package registry
+
+import "testing"
+
+func findListedModel(models []map[string]any, id string) map[string]any {
+	for _, model := range models {
+		if model["id"] == id {
+			return model
+		}
+	}
+	return nil
+}
+
+func TestGetAvailableModels_DeclaredModelWithoutCredential(t *testing.T) {
+	r := newTestModelRegistry()
+	r.SetDeclaredModels([]*ModelInfo{{ID: "declared-model", OwnedBy: "google"}})
+
+	model := findListedModel(r.GetAvailableModels("openai"), "declared-model")
+	if model == nil {
+		t.Fatal("declared model missing from listing")
+	}
+	if available, ok := model["available"].(bool); !ok || available {
+		t.Fatalf("available = %v, want false", model["available"])
+	}
+	if model["owned_by"] != "google" {
+		t.Fatalf("owned_by = %v, want google", model["owned_by"])
+	}
+	if !r.IsDeclaredUnavailable("declared-model") {
+		t.Fatal("IsDeclaredUnavailable = false, want true")
+	}
+	if _, err := r.GetFirstAvailableModel("openai"); err == nil {
+		t.Fatal("GetFirstAvailableModel picked an unavailable declared model")
+	}
+}
+
+func TestGetAvailableModels_DeclaredModelWithCredential(t *testing.T) {
+	r := newTestModelRegistry()
+	r.SetDeclaredModels([]*ModelInfo{{ID: "declared-model"}})
+	r.RegisterClient("client-1", "gemini", []*ModelInfo{{ID: "declared-model"}})
+
+	models := r.GetAvailableModels("openai")
+	if len(models) != 1 {
+		t.Fatalf("listed %d models, want 1", len(models))
+	}
+	if _, flagged := models[0]["available"]; flagged {
+		t.Fatalf("credentialed model flagged unavailable: %v", models[0])
+	}
+	if r.IsDeclaredUnavailable("declared-model") {
+		t.Fatal("IsDeclaredUnavailable = true, want false")
+	}
+
+	r.UnregisterClient("client-1")
+	model := findListedModel(r.GetAvailableModels("openai"), "declared-model")
+	if model == nil || model["available"] != false {
+		t.Fatalf("after unregister model = %v, want available=false", model)
+	}
+}
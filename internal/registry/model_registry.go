@@ -52,12 +52,46 @@ type ModelInfo struct {
 	// This is optional and currently used for Gemini thinking budget normalization.
 	Thinking *ThinkingSupport `json:"thinking,omitempty"`
 
+	// DefaultThinking is the thinking effort applied when a request carries neither a model
+	// suffix nor a thinking parameter. It accepts the same values as a model suffix
+	// (e.g. "low", "high", "8192", "auto") and is clamped to Thinking before use.
+	// Empty keeps the provider default behavior.
+	DefaultThinking string `json:"-"`
+
+	// ConcurrencyWeight is the number of routing-group concurrency slots one request for
+	// this model occupies. Values below 1 count as a single slot.
+	ConcurrencyWeight int `json:"-"`
+
 	// UserDefined indicates this model was defined through config file's models[]
 	// array (e.g., openai-compatibility.*.models[], *-api-key.models[]).
 	// UserDefined models have thinking configuration passed through without validation.
 	UserDefined bool `json:"-"`
 }
 
+// ConcurrencySlots returns how many concurrency slots a request for the model consumes.
+func (m *ModelInfo) ConcurrencySlots() int {
+	if m == nil || m.ConcurrencyWeight < 1 {
+		return 1
+	}
+	return m.ConcurrencyWeight
+}
+
+// MaxOutputTokens returns the model's output-token cap.
+// OutputTokenLimit (Gemini-style) takes precedence over MaxCompletionTokens.
+// Zero means the limit is unknown and requests should not be clamped.
+func (m *ModelInfo) MaxOutputTokens() int {
+	if m == nil {
+		return 0
+	}
+	if m.OutputTokenLimit > 0 {
+		return m.OutputTokenLimit
+	}
+	if m.MaxCompletionTokens > 0 {
+		return m.MaxCompletionTokens
+	}
+	return 0
+}
+
 // ThinkingSupport describes a model family's supported internal reasoning budget range.
 // Values are interpreted in provider-native token units.
 type ThinkingSupport struct {
@@ -114,6 +148,10 @@ type ModelRegistry struct {
 	mutex *sync.RWMutex
 	// hook is an optional callback sink for model registration changes
 	hook ModelRegistryHook
+	// declaredModels holds config-declared models listed even without a providing client
+	declaredModels map[string]*ModelInfo
+	// declaredOrder preserves the configured order of declaredModels
+	declaredOrder []string
 }
 
 // Global model registry instance
@@ -694,7 +732,8 @@ func (r *ModelRegistry) ClientSupportsModel(clientID, modelID string) bool {
 	return false
 }
 
-// GetAvailableModels returns all models that have at least one available client
+// GetAvailableModels returns all models that have at least one available client,
+// followed by declared models that are not currently provided (marked "available": false).
 // Parameters:
 //   - handlerType: The handler type to filter models for (e.g., "openai", "claude", "gemini")
 //
@@ -705,9 +744,10 @@ func (r *ModelRegistry) GetAvailableModels(handlerType string) []map[string]any
 	defer r.mutex.RUnlock()
 
 	models := make([]map[string]any, 0)
+	listed := make(map[string]struct{})
 	quotaExpiredDuration := 5 * time.Minute
 
-	for _, registration := range r.models {
+	for modelID, registration := range r.models {
 		// Check if model has any non-quota-exceeded clients
 		availableClients := registration.Count
 		now := time.Now()
@@ -742,11 +782,12 @@ func (r *ModelRegistry) GetAvailableModels(handlerType string) []map[string]any
 			model := r.convertModelToMap(registration.Info, handlerType)
 			if model != nil {
 				models = append(models, model)
+				listed[modelID] = struct{}{}
 			}
 		}
 	}
 
-	return models
+	return r.appendDeclaredModels(models, listed, handlerType)
 }
 
 // GetAvailableModelsByProvider returns models available for the given provider identifier.
@@ -0,0 +1,84 @@
+package registry
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxModelSuggestions caps how many "did you mean" candidates are returned.
+const maxModelSuggestions = 3
+
+// SuggestModels returns registered model IDs closest to modelID by edit distance, for use
+// in "model not found" errors. Only models with at least one registered client are
+// considered, so configured aliases are suggested alongside upstream names. Comparison is
+// case-insensitive and candidates further than a third of the requested name's length
+// (minimum 2 edits) are dropped.
+func (r *ModelRegistry) SuggestModels(modelID string) []string {
+	target := strings.ToLower(strings.TrimSpace(modelID))
+	if target == "" {
+		return nil
+	}
+	maxDistance := len(target) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	type candidate struct {
+		id       string
+		distance int
+	}
+	r.mutex.RLock()
+	candidates := make([]candidate, 0)
+	for id, registration := range r.models {
+		if registration == nil || registration.Count <= 0 {
+			continue
+		}
+		if d := levenshtein(target, strings.ToLower(id)); d <= maxDistance {
+			candidates = append(candidates, candidate{id: id, distance: d})
+		}
+	}
+	r.mutex.RUnlock()
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance == candidates[j].distance {
+			return candidates[i].id < candidates[j].id
+		}
+		return candidates[i].distance < candidates[j].distance
+	})
+	if len(candidates) > maxModelSuggestions {
+		candidates = candidates[:maxModelSuggestions]
+	}
+	out := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		out = append(out, c.id)
+	}
+	return out
+}
+
+// levenshtein returns the edit distance between a and b, counted in runes.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
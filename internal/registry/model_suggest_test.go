@@ -0,0 +1,35 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSuggestModels(t *testing.T) {
+	r := newTestModelRegistry()
+	r.RegisterClient("client-gemini", "gemini", []*ModelInfo{
+		{ID: "gemini-2.5-pro"},
+		{ID: "gemini-2.5-flash"},
+		{ID: "gemini-2.5-flash-lite"},
+	})
+	r.RegisterClient("client-openai", "codex", []*ModelInfo{{ID: "gpt-5"}, {ID: "gpt-5.1"}})
+
+	cases := []struct {
+		name  string
+		model string
+		want  []string
+	}{
+		{name: "missing dash", model: "gemini-2.5pro", want: []string{"gemini-2.5-pro"}},
+		{name: "case insensitive", model: "Gemini-2.5-Flsh", want: []string{"gemini-2.5-flash", "gemini-2.5-pro"}},
+		{name: "ordered by distance", model: "gpt-5.2", want: []string{"gpt-5.1", "gpt-5"}},
+		{name: "no close match", model: "totally-different-model", want: []string{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := r.SuggestModels(tc.model); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("SuggestModels(%q) = %v, want %v", tc.model, got, tc.want)
+			}
+		})
+	}
+}
@@ -88,6 +88,7 @@ func (e *AntigravityExecutor) PrepareRequest(req *http.Request, auth *cliproxyau
 		return statusErr{code: http.StatusUnauthorized, msg: "missing access token"}
 	}
 	req.Header.Set("Authorization", "Bearer "+token)
+	applyUpstreamHeaders(req, e.cfg, e.Identifier(), auth)
 	return nil
 }
 
@@ -113,9 +114,7 @@ func (e *AntigravityExecutor) Execute(ctx context.Context, auth *cliproxyauth.Au
 		return resp, statusErr{code: http.StatusNotImplemented, msg: "/responses/compact not supported"}
 	}
 	baseModel := thinking.ParseSuffix(req.Model).ModelName
-	isClaude := strings.Contains(strings.ToLower(baseModel), "claude")
-
-	if isClaude || strings.Contains(baseModel, "gemini-3-pro") {
+	if antigravityUsesClaudePath(e.cfg, baseModel) {
 		return e.executeClaudeNonStream(ctx, auth, req, opts)
 	}
 
@@ -129,9 +128,13 @@ func (e *AntigravityExecutor) Execute(ctx context.Context, auth *cliproxyauth.Au
 
 	reporter := newUsageReporter(ctx, e.Identifier(), baseModel, auth)
 	defer reporter.trackFailure(ctx, &err)
+	ctx = reporter.trackToolCalls(ctx)
 
 	from := opts.SourceFormat
 	to := sdktranslator.FromString("antigravity")
+	if err := checkTranslatorPair(e.cfg, from, to, false); err != nil {
+		return resp, err
+	}
 
 	originalPayloadSource := req.Payload
 	if len(opts.OriginalRequest) > 0 {
@@ -139,8 +142,12 @@ func (e *AntigravityExecutor) Execute(ctx context.Context, auth *cliproxyauth.Au
 	}
 	originalPayload := originalPayloadSource
 	originalTranslated := sdktranslator.TranslateRequest(from, to, baseModel, originalPayload, false)
-	translated := sdktranslator.TranslateRequest(from, to, baseModel, req.Payload, false)
+	translated, err := sdktranslator.TranslateRequestForProvider(e.Identifier(), from, to, baseModel, req.Payload, false)
+	if err != nil {
+		return resp, err
+	}
 
+	translated = applyMaxTokensClamp(translated, req.Model, to.String(), e.Identifier())
 	translated, err = thinking.ApplyThinking(translated, req.Model, from.String(), to.String(), e.Identifier())
 	if err != nil {
 		return resp, err
@@ -148,12 +155,15 @@ func (e *AntigravityExecutor) Execute(ctx context.Context, auth *cliproxyauth.Au
 
 	requestedModel := payloadRequestedModel(opts, req.Model)
 	translated = applyPayloadConfigWithRoot(e.cfg, baseModel, "antigravity", "request", translated, originalTranslated, requestedModel)
+	translated = applyRequestIDField(ctx, e.cfg, e.Identifier(), translated)
+	translated = applyGeminiCachedContent(ctx, translated, "request.cachedContent")
 
 	baseURLs := antigravityBaseURLFallbackOrder(auth)
 	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
 
 	attempts := antigravityRetryAttempts(auth, e.cfg)
 
+	var noCapacityDelay time.Duration
 attemptLoop:
 	for attempt := 0; attempt < attempts; attempt++ {
 		var lastStatus int
@@ -211,7 +221,8 @@ attemptLoop:
 						continue
 					}
 					if attempt+1 < attempts {
-						delay := antigravityNoCapacityRetryDelay(attempt)
+						delay := antigravityNoCapacityRetryDelay(e.cfg, attempt, noCapacityDelay)
+						noCapacityDelay = delay
 						log.Debugf("antigravity executor: no capacity for model %s, retrying in %s (attempt %d/%d)", baseModel, delay, attempt+1, attempts)
 						if errWait := antigravityWait(ctx, delay); errWait != nil {
 							return resp, errWait
@@ -271,6 +282,8 @@ func (e *AntigravityExecutor) executeClaudeNonStream(ctx context.Context, auth *
 
 	reporter := newUsageReporter(ctx, e.Identifier(), baseModel, auth)
 	defer reporter.trackFailure(ctx, &err)
+	ctx = reporter.trackToolCalls(ctx)
+	reporter.setPromptPayload(req.Payload)
 
 	from := opts.SourceFormat
 	to := sdktranslator.FromString("antigravity")
@@ -281,8 +294,12 @@ func (e *AntigravityExecutor) executeClaudeNonStream(ctx context.Context, auth *
 	}
 	originalPayload := originalPayloadSource
 	originalTranslated := sdktranslator.TranslateRequest(from, to, baseModel, originalPayload, true)
-	translated := sdktranslator.TranslateRequest(from, to, baseModel, req.Payload, true)
+	translated, err := sdktranslator.TranslateRequestForProvider(e.Identifier(), from, to, baseModel, req.Payload, true)
+	if err != nil {
+		return resp, err
+	}
 
+	translated = applyMaxTokensClamp(translated, req.Model, to.String(), e.Identifier())
 	translated, err = thinking.ApplyThinking(translated, req.Model, from.String(), to.String(), e.Identifier())
 	if err != nil {
 		return resp, err
@@ -290,12 +307,15 @@ func (e *AntigravityExecutor) executeClaudeNonStream(ctx context.Context, auth *
 
 	requestedModel := payloadRequestedModel(opts, req.Model)
 	translated = applyPayloadConfigWithRoot(e.cfg, baseModel, "antigravity", "request", translated, originalTranslated, requestedModel)
+	translated = applyRequestIDField(ctx, e.cfg, e.Identifier(), translated)
+	translated = applyGeminiCachedContent(ctx, translated, "request.cachedContent")
 
 	baseURLs := antigravityBaseURLFallbackOrder(auth)
 	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
 
 	attempts := antigravityRetryAttempts(auth, e.cfg)
 
+	var noCapacityDelay time.Duration
 attemptLoop:
 	for attempt := 0; attempt < attempts; attempt++ {
 		var lastStatus int
@@ -365,7 +385,8 @@ attemptLoop:
 						continue
 					}
 					if attempt+1 < attempts {
-						delay := antigravityNoCapacityRetryDelay(attempt)
+						delay := antigravityNoCapacityRetryDelay(e.cfg, attempt, noCapacityDelay)
+						noCapacityDelay = delay
 						log.Debugf("antigravity executor: no capacity for model %s, retrying in %s (attempt %d/%d)", baseModel, delay, attempt+1, attempts)
 						if errWait := antigravityWait(ctx, delay); errWait != nil {
 							return resp, errWait
@@ -406,6 +427,7 @@ attemptLoop:
 						continue
 					}
 
+					reporter.observeOutput(payload)
 					if detail, ok := parseAntigravityStreamUsage(payload); ok {
 						reporter.publish(ctx, detail)
 					}
@@ -422,16 +444,25 @@ attemptLoop:
 			}(httpResp)
 
 			var buffer bytes.Buffer
+			var streamErr error
+			repair := antigravityRepairTruncatedStream(e.cfg)
 			for chunk := range out {
 				if chunk.Err != nil {
-					return resp, chunk.Err
+					if !repair || buffer.Len() == 0 {
+						return resp, chunk.Err
+					}
+					streamErr = chunk.Err
+					continue
 				}
 				if len(chunk.Payload) > 0 {
 					_, _ = buffer.Write(chunk.Payload)
 					_, _ = buffer.Write([]byte("\n"))
 				}
 			}
-			resp = cliproxyexecutor.Response{Payload: e.convertStreamToNonStream(buffer.Bytes())}
+			if streamErr != nil {
+				log.Warnf("antigravity executor: returning repaired partial response after stream error: %v", streamErr)
+			}
+			resp = cliproxyexecutor.Response{Payload: e.convertStreamToNonStream(buffer.Bytes(), repair, streamErr)}
 
 			reporter.publish(ctx, parseAntigravityUsage(resp.Payload))
 			var param any
@@ -462,8 +493,13 @@ attemptLoop:
 	return resp, err
 }
 
-func (e *AntigravityExecutor) convertStreamToNonStream(stream []byte) []byte {
+// convertStreamToNonStream reassembles streamed Antigravity chunks into a single response.
+// When repair is true, a trailing chunk cut off mid-JSON is closed instead of dropped, and a
+// response that ended early (no finishReason, unparsable chunk, or streamErr) is marked as
+// truncated with finishReason MAX_TOKENS, or OTHER when the stream failed with an error.
+func (e *AntigravityExecutor) convertStreamToNonStream(stream []byte, repair bool, streamErr error) []byte {
 	responseTemplate := ""
+	truncated := false
 	var traceID string
 	var finishReason string
 	var modelVersion string
@@ -531,9 +567,20 @@ func (e *AntigravityExecutor) convertStreamToNonStream(stream []byte) []byte {
 
 	for _, line := range bytes.Split(stream, []byte("\n")) {
 		trimmed := bytes.TrimSpace(line)
-		if len(trimmed) == 0 || !gjson.ValidBytes(trimmed) {
+		if len(trimmed) == 0 {
 			continue
 		}
+		if !gjson.ValidBytes(trimmed) {
+			if !repair {
+				continue
+			}
+			repaired, ok := util.CloseTruncatedJSON(string(trimmed))
+			if !ok {
+				continue
+			}
+			trimmed = []byte(repaired)
+			truncated = true
+		}
 
 		root := gjson.ParseBytes(trimmed)
 		responseNode := root.Get("response")
@@ -614,6 +661,16 @@ func (e *AntigravityExecutor) convertStreamToNonStream(stream []byte) []byte {
 		responseTemplate = `{"candidates":[{"content":{"role":"model","parts":[]}}]}`
 	}
 
+	if repair && (truncated || streamErr != nil || finishReason == "") {
+		finishReason = "MAX_TOKENS"
+		finishMessage := "upstream stream ended before the response was complete"
+		if streamErr != nil {
+			finishReason = "OTHER"
+			finishMessage = "upstream stream failed: " + streamErr.Error()
+		}
+		responseTemplate, _ = sjson.Set(responseTemplate, "candidates.0.finishMessage", finishMessage)
+	}
+
 	partsJSON, _ := json.Marshal(parts)
 	responseTemplate, _ = sjson.SetRaw(responseTemplate, "candidates.0.content.parts", string(partsJSON))
 	if role != "" {
@@ -663,9 +720,14 @@ func (e *AntigravityExecutor) ExecuteStream(ctx context.Context, auth *cliproxya
 
 	reporter := newUsageReporter(ctx, e.Identifier(), baseModel, auth)
 	defer reporter.trackFailure(ctx, &err)
+	ctx = reporter.trackStreamToolCalls(ctx)
+	reporter.setPromptPayload(req.Payload)
 
 	from := opts.SourceFormat
 	to := sdktranslator.FromString("antigravity")
+	if err := checkTranslatorPair(e.cfg, from, to, true); err != nil {
+		return nil, err
+	}
 
 	originalPayloadSource := req.Payload
 	if len(opts.OriginalRequest) > 0 {
@@ -673,8 +735,12 @@ func (e *AntigravityExecutor) ExecuteStream(ctx context.Context, auth *cliproxya
 	}
 	originalPayload := originalPayloadSource
 	originalTranslated := sdktranslator.TranslateRequest(from, to, baseModel, originalPayload, true)
-	translated := sdktranslator.TranslateRequest(from, to, baseModel, req.Payload, true)
+	translated, err := sdktranslator.TranslateRequestForProvider(e.Identifier(), from, to, baseModel, req.Payload, true)
+	if err != nil {
+		return nil, err
+	}
 
+	translated = applyMaxTokensClamp(translated, req.Model, to.String(), e.Identifier())
 	translated, err = thinking.ApplyThinking(translated, req.Model, from.String(), to.String(), e.Identifier())
 	if err != nil {
 		return nil, err
@@ -682,12 +748,15 @@ func (e *AntigravityExecutor) ExecuteStream(ctx context.Context, auth *cliproxya
 
 	requestedModel := payloadRequestedModel(opts, req.Model)
 	translated = applyPayloadConfigWithRoot(e.cfg, baseModel, "antigravity", "request", translated, originalTranslated, requestedModel)
+	translated = applyRequestIDField(ctx, e.cfg, e.Identifier(), translated)
+	translated = applyGeminiCachedContent(ctx, translated, "request.cachedContent")
 
 	baseURLs := antigravityBaseURLFallbackOrder(auth)
 	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
 
 	attempts := antigravityRetryAttempts(auth, e.cfg)
 
+	var noCapacityDelay time.Duration
 attemptLoop:
 	for attempt := 0; attempt < attempts; attempt++ {
 		var lastStatus int
@@ -756,7 +825,8 @@ attemptLoop:
 						continue
 					}
 					if attempt+1 < attempts {
-						delay := antigravityNoCapacityRetryDelay(attempt)
+						delay := antigravityNoCapacityRetryDelay(e.cfg, attempt, noCapacityDelay)
+						noCapacityDelay = delay
 						log.Debugf("antigravity executor: no capacity for model %s, retrying in %s (attempt %d/%d)", baseModel, delay, attempt+1, attempts)
 						if errWait := antigravityWait(ctx, delay); errWait != nil {
 							return nil, errWait
@@ -774,44 +844,82 @@ attemptLoop:
 				return nil, err
 			}
 
+			recorder := newStreamRecorder(e.cfg, e.Identifier(), sdktranslator.StreamRecordingHeader{
+				SourceFormat:    from,
+				ProviderFormat:  to,
+				Model:           req.Model,
+				OriginalRequest: string(opts.OriginalRequest),
+				Request:         string(translated),
+			})
 			out := make(chan cliproxyexecutor.StreamChunk)
 			go func(resp *http.Response) {
 				defer close(out)
-				defer func() {
-					if errClose := resp.Body.Close(); errClose != nil {
-						log.Errorf("antigravity executor: close response body error: %v", errClose)
-					}
-				}()
-				scanner := bufio.NewScanner(resp.Body)
-				scanner.Buffer(nil, streamScannerBuffer)
+				defer recorder.close()
+				defer reporter.publishHeld(ctx)
 				var param any
-				for scanner.Scan() {
-					line := scanner.Bytes()
-					appendAPIResponseChunk(ctx, e.cfg, line)
-
-					// Filter usage metadata for all models
-					// Only retain usage statistics in the terminal chunk
-					line = FilterSSEUsageMetadata(line)
-
-					payload := jsonPayload(line)
-					if payload == nil {
-						continue
-					}
-
-					if detail, ok := parseAntigravityStreamUsage(payload); ok {
-						reporter.publish(ctx, detail)
+				maxReconnects := antigravityStreamReconnectAttempts(e.cfg)
+				var errScan error
+				// forwarded is set once a chunk reaches the client. Antigravity cannot resume a
+				// response, so only a stream that dropped before that is re-sent; a later drop
+				// ends the stream with the error instead of repeating delivered content.
+				forwarded := false
+				for reconnects := 0; ; reconnects++ {
+					errScan = func() error {
+						defer func() {
+							if errClose := resp.Body.Close(); errClose != nil {
+								log.Errorf("antigravity executor: close response body error: %v", errClose)
+							}
+						}()
+						scanner := bufio.NewScanner(resp.Body)
+						scanner.Buffer(nil, streamScannerBuffer)
+						for scanner.Scan() {
+							line := scanner.Bytes()
+							appendAPIResponseChunk(ctx, e.cfg, line)
+
+							// Filter usage metadata for all models
+							// Only retain usage statistics in the terminal chunk
+							line = FilterSSEUsageMetadata(line)
+
+							payload := jsonPayload(line)
+							if payload == nil {
+								continue
+							}
+
+							reporter.observeOutput(payload)
+							if detail, ok := parseAntigravityStreamUsage(payload); ok {
+								reporter.publish(ctx, detail)
+							}
+
+							recorder.record(payload)
+							chunks := sdktranslator.TranslateStream(ctx, to, from, req.Model, opts.OriginalRequest, translated, bytes.Clone(payload), &param)
+							for i := range chunks {
+								out <- cliproxyexecutor.StreamChunk{Payload: []byte(chunks[i])}
+								forwarded = true
+							}
+						}
+						return scanner.Err()
+					}()
+					if errScan == nil || forwarded || reconnects >= maxReconnects || ctx.Err() != nil {
+						break
 					}
-
-					chunks := sdktranslator.TranslateStream(ctx, to, from, req.Model, opts.OriginalRequest, translated, bytes.Clone(payload), &param)
-					for i := range chunks {
-						out <- cliproxyexecutor.StreamChunk{Payload: []byte(chunks[i])}
+					recordAPIResponseError(ctx, e.cfg, errScan)
+					log.Debugf("antigravity executor: stream dropped on base url %s (%v), reconnecting (%d/%d)", baseURL, errScan, reconnects+1, maxReconnects)
+					next, errReconnect := e.reconnectStream(ctx, httpClient, auth, token, baseModel, translated, opts.Alt, baseURL)
+					if errReconnect != nil {
+						log.Debugf("antigravity executor: stream reconnect failed: %v", errReconnect)
+						break
 					}
+					reporter.restartStream()
+					param = nil
+					recorder.reset()
+					resp = next
 				}
+				recorder.record([]byte("[DONE]"))
 				tail := sdktranslator.TranslateStream(ctx, to, from, req.Model, opts.OriginalRequest, translated, []byte("[DONE]"), &param)
 				for i := range tail {
 					out <- cliproxyexecutor.StreamChunk{Payload: []byte(tail[i])}
 				}
-				if errScan := scanner.Err(); errScan != nil {
+				if errScan != nil {
 					recordAPIResponseError(ctx, e.cfg, errScan)
 					reporter.publishFailure(ctx)
 					out <- cliproxyexecutor.StreamChunk{Err: errScan}
@@ -842,6 +950,46 @@ attemptLoop:
 	return nil, err
 }
 
+// reconnectStream re-sends a streaming request to the same base URL after the previous
+// stream dropped on a network error.
+func (e *AntigravityExecutor) reconnectStream(ctx context.Context, httpClient *http.Client, auth *cliproxyauth.Auth, token, baseModel string, payload []byte, alt, baseURL string) (*http.Response, error) {
+	httpReq, errReq := e.buildRequest(ctx, auth, token, baseModel, payload, true, alt, baseURL)
+	if errReq != nil {
+		return nil, errReq
+	}
+	httpResp, errDo := httpClient.Do(httpReq)
+	if errDo != nil {
+		recordAPIResponseError(ctx, e.cfg, errDo)
+		return nil, errDo
+	}
+	recordAPIResponseMetadata(ctx, e.cfg, httpResp.StatusCode, httpResp.Header.Clone())
+	if httpResp.StatusCode < http.StatusOK || httpResp.StatusCode >= http.StatusMultipleChoices {
+		bodyBytes, _ := io.ReadAll(httpResp.Body)
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("antigravity executor: close response body error: %v", errClose)
+		}
+		appendAPIResponseChunk(ctx, e.cfg, bodyBytes)
+		return nil, statusErr{code: httpResp.StatusCode, msg: string(bodyBytes)}
+	}
+	return httpResp, nil
+}
+
+// antigravityStreamReconnectAttempts returns how many times a dropped stream may be
+// re-sent, or 0 when antigravity.stream-reconnect is not "restart".
+func antigravityStreamReconnectAttempts(cfg *config.Config) int {
+	if cfg == nil || !strings.EqualFold(strings.TrimSpace(cfg.Antigravity.StreamReconnect), "restart") {
+		return 0
+	}
+	if cfg.Antigravity.StreamReconnectAttempts <= 0 {
+		return 1
+	}
+	return cfg.Antigravity.StreamReconnectAttempts
+}
+
+func antigravityRepairTruncatedStream(cfg *config.Config) bool {
+	return cfg != nil && cfg.Antigravity.RepairTruncatedStream
+}
+
 // Refresh refreshes the authentication credentials using the refresh token.
 func (e *AntigravityExecutor) Refresh(ctx context.Context, auth *cliproxyauth.Auth) (*cliproxyauth.Auth, error) {
 	if auth == nil {
@@ -874,9 +1022,12 @@ func (e *AntigravityExecutor) CountTokens(ctx context.Context, auth *cliproxyaut
 	respCtx := context.WithValue(ctx, "alt", opts.Alt)
 
 	// Prepare payload once (doesn't depend on baseURL)
-	payload := sdktranslator.TranslateRequest(from, to, baseModel, req.Payload, false)
+	payload, err := sdktranslator.TranslateRequestForProvider(e.Identifier(), from, to, baseModel, req.Payload, false)
+	if err != nil {
+		return cliproxyexecutor.Response{}, err
+	}
 
-	payload, err := thinking.ApplyThinking(payload, req.Model, from.String(), to.String(), e.Identifier())
+	payload, err = thinking.ApplyThinking(payload, req.Model, from.String(), to.String(), e.Identifier())
 	if err != nil {
 		return cliproxyexecutor.Response{}, err
 	}
@@ -919,8 +1070,9 @@ func (e *AntigravityExecutor) CountTokens(ctx context.Context, auth *cliproxyaut
 		}
 		httpReq.Header.Set("Content-Type", "application/json")
 		httpReq.Header.Set("Authorization", "Bearer "+token)
-		httpReq.Header.Set("User-Agent", resolveUserAgent(auth))
+		httpReq.Header.Set("User-Agent", resolveUserAgent(e.cfg, auth))
 		httpReq.Header.Set("Accept", "application/json")
+		applyUpstreamHeaders(httpReq, e.cfg, e.Identifier(), auth)
 		if host := resolveHost(base); host != "" {
 			httpReq.Host = host
 		}
@@ -1024,7 +1176,7 @@ func FetchAntigravityModels(ctx context.Context, auth *cliproxyauth.Auth, cfg *c
 		}
 		httpReq.Header.Set("Content-Type", "application/json")
 		httpReq.Header.Set("Authorization", "Bearer "+token)
-		httpReq.Header.Set("User-Agent", resolveUserAgent(auth))
+		httpReq.Header.Set("User-Agent", resolveUserAgent(cfg, auth))
 		if host := resolveHost(baseURL); host != "" {
 			httpReq.Host = host
 		}
@@ -1321,12 +1473,13 @@ func (e *AntigravityExecutor) buildRequest(ctx context.Context, auth *cliproxyau
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Authorization", "Bearer "+token)
-	httpReq.Header.Set("User-Agent", resolveUserAgent(auth))
+	httpReq.Header.Set("User-Agent", resolveUserAgent(e.cfg, auth))
 	if stream {
 		httpReq.Header.Set("Accept", "text/event-stream")
 	} else {
 		httpReq.Header.Set("Accept", "application/json")
 	}
+	applyUpstreamHeaders(httpReq, e.cfg, e.Identifier(), auth)
 	if host := resolveHost(base); host != "" {
 		httpReq.Host = host
 	}
@@ -1432,7 +1585,7 @@ func resolveHost(base string) string {
 	return strings.TrimPrefix(strings.TrimPrefix(base, "https://"), "http://")
 }
 
-func resolveUserAgent(auth *cliproxyauth.Auth) string {
+func resolveUserAgent(cfg *config.Config, auth *cliproxyauth.Auth) string {
 	if auth != nil {
 		if auth.Attributes != nil {
 			if ua := strings.TrimSpace(auth.Attributes["user_agent"]); ua != "" {
@@ -1445,9 +1598,33 @@ func resolveUserAgent(auth *cliproxyauth.Auth) string {
 			}
 		}
 	}
+	if cfg != nil {
+		if ua := strings.TrimSpace(cfg.Antigravity.UserAgent); ua != "" {
+			return ua
+		}
+	}
 	return defaultAntigravityAgent
 }
 
+// defaultAntigravityClaudePathModels is used when antigravity.claude-path-models is not configured.
+var defaultAntigravityClaudePathModels = []string{"*claude*", "*gemini-3-pro*"}
+
+// antigravityUsesClaudePath reports whether non-streaming requests for the model should be
+// served through executeClaudeNonStream.
+func antigravityUsesClaudePath(cfg *config.Config, baseModel string) bool {
+	patterns := defaultAntigravityClaudePathModels
+	if cfg != nil && len(cfg.Antigravity.ClaudePathModels) > 0 {
+		patterns = cfg.Antigravity.ClaudePathModels
+	}
+	model := strings.ToLower(strings.TrimSpace(baseModel))
+	for _, pattern := range patterns {
+		if matchModelPattern(strings.ToLower(pattern), model) {
+			return true
+		}
+	}
+	return false
+}
+
 func antigravityRetryAttempts(auth *cliproxyauth.Auth, cfg *config.Config) int {
 	retry := 0
 	if cfg != nil {
@@ -1479,15 +1656,61 @@ func antigravityShouldRetryNoCapacity(statusCode int, body []byte) bool {
 	return strings.Contains(msg, "no capacity available")
 }
 
-func antigravityNoCapacityRetryDelay(attempt int) time.Duration {
+const (
+	antigravityNoCapacityRetryStep     = 250 * time.Millisecond
+	antigravityNoCapacityRetryMaxDelay = 2 * time.Second
+)
+
+// antigravityNoCapacityRetryDelay returns the wait before the next no-capacity retry.
+// The base delay grows by 250ms per attempt up to the configured maximum. With "full"
+// jitter (the default) the delay is drawn from [0, base]; "decorrelated" draws from
+// [step, 3*previous] so instances retrying in lockstep drift apart; "none" keeps base.
+func antigravityNoCapacityRetryDelay(cfg *config.Config, attempt int, previous time.Duration) time.Duration {
 	if attempt < 0 {
 		attempt = 0
 	}
-	delay := time.Duration(attempt+1) * 250 * time.Millisecond
-	if delay > 2*time.Second {
-		delay = 2 * time.Second
+	maxDelay := antigravityNoCapacityRetryMaxDelay
+	jitter := ""
+	if cfg != nil {
+		if cfg.Antigravity.NoCapacityRetryMaxDelayMs > 0 {
+			maxDelay = time.Duration(cfg.Antigravity.NoCapacityRetryMaxDelayMs) * time.Millisecond
+		}
+		jitter = strings.ToLower(strings.TrimSpace(cfg.Antigravity.NoCapacityRetryJitter))
 	}
-	return delay
+	delay := time.Duration(attempt+1) * antigravityNoCapacityRetryStep
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	switch jitter {
+	case "none":
+		return delay
+	case "decorrelated":
+		if previous < antigravityNoCapacityRetryStep {
+			previous = antigravityNoCapacityRetryStep
+		}
+		upper := previous * 3
+		if upper > maxDelay {
+			upper = maxDelay
+		}
+		if upper <= antigravityNoCapacityRetryStep {
+			return upper
+		}
+		return antigravityNoCapacityRetryStep + randomDuration(upper-antigravityNoCapacityRetryStep)
+	default:
+		return randomDuration(delay)
+	}
+}
+
+// randomDuration returns a uniformly distributed duration in [0, limit].
+func randomDuration(limit time.Duration) time.Duration {
+	if limit <= 0 {
+		return 0
+	}
+	randSourceMutex.Lock()
+	n := randSource.Int63n(int64(limit) + 1)
+	randSourceMutex.Unlock()
+	return time.Duration(n)
 }
 
 func antigravityWait(ctx context.Context, wait time.Duration) error {
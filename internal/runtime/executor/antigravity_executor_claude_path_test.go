@@ -0,0 +1,41 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestAntigravityUsesClaudePath_DefaultDetection(t *testing.T) {
+	cases := map[string]bool{
+		"claude-sonnet-4-5":      true,
+		"Claude-Opus-4-6":        true,
+		"gemini-3-pro-high":      true,
+		"gemini-2.5-flash":       false,
+		"gemini-3-flash-preview": false,
+	}
+	for model, want := range cases {
+		if got := antigravityUsesClaudePath(nil, model); got != want {
+			t.Fatalf("antigravityUsesClaudePath(nil, %q) = %v, want %v", model, got, want)
+		}
+	}
+}
+
+func TestAntigravityUsesClaudePath_ConfiguredModels(t *testing.T) {
+	cfg := &config.Config{
+		Antigravity: config.AntigravityConfig{
+			ClaudePathModels: []string{"gemini-3-flash-*", "claude-opus-*"},
+		},
+	}
+	cases := map[string]bool{
+		"gemini-3-flash-preview": true,
+		"claude-opus-4-6":        true,
+		"claude-sonnet-4-5":      false,
+		"gemini-3-pro-high":      false,
+	}
+	for model, want := range cases {
+		if got := antigravityUsesClaudePath(cfg, model); got != want {
+			t.Fatalf("antigravityUsesClaudePath(cfg, %q) = %v, want %v", model, got, want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+// newDroppingAntigravityServer drops the first stream after sending firstChunk, which may
+// be empty, and serves the second one completely.
+func newDroppingAntigravityServer(t *testing.T, requests *atomic.Int32, firstChunk string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			hijacker, ok := w.(http.Hijacker)
+			if !ok {
+				t.Errorf("response writer does not support hijacking")
+				return
+			}
+			conn, buf, err := hijacker.Hijack()
+			if err != nil {
+				t.Errorf("hijack: %v", err)
+				return
+			}
+			_, _ = fmt.Fprint(buf, "HTTP/1.1 200 OK\r\nContent-Type: text/event-stream\r\nTransfer-Encoding: chunked\r\n\r\n")
+			if firstChunk != "" {
+				_, _ = fmt.Fprintf(buf, "%x\r\n%s\r\n", len(firstChunk), firstChunk)
+			}
+			_ = buf.Flush()
+			_ = conn.Close()
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, "data: {\"response\":{\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"hello\"}]},\"finishReason\":\"STOP\"}]}}\n\n")
+	}))
+}
+
+// droppedChunk is the partial content sent before the first stream drops.
+const droppedChunk = "data: {\"response\":{\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"hel\"}]}}]}}\n\n"
+
+func runDroppingAntigravityStream(t *testing.T, cfg *config.Config, firstChunk string) (int32, []string, error) {
+	t.Helper()
+	var requests atomic.Int32
+	server := newDroppingAntigravityServer(t, &requests, firstChunk)
+	defer server.Close()
+
+	exec := NewAntigravityExecutor(cfg)
+	auth := &cliproxyauth.Auth{
+		Attributes: map[string]string{"base_url": server.URL},
+		Metadata: map[string]any{
+			"access_token": "token",
+			"expired":      time.Now().Add(time.Hour).Format(time.RFC3339),
+		},
+	}
+	result, err := exec.ExecuteStream(context.Background(), auth, cliproxyexecutor.Request{
+		Model:   "gemini-2.5-pro",
+		Payload: []byte(`{"request":{"contents":[{"role":"user","parts":[{"text":"hi"}]}]}}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("antigravity"), Stream: true})
+	if err != nil {
+		t.Fatalf("ExecuteStream error: %v", err)
+	}
+	var payloads []string
+	var streamErr error
+	for chunk := range result.Chunks {
+		if chunk.Err != nil {
+			streamErr = chunk.Err
+			continue
+		}
+		payloads = append(payloads, string(chunk.Payload))
+	}
+	return requests.Load(), payloads, streamErr
+}
+
+func TestAntigravityExecuteStream_ReconnectsAfterDropBeforeContent(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Antigravity.StreamReconnect = "restart"
+
+	requests, payloads, streamErr := runDroppingAntigravityStream(t, cfg, "")
+	if streamErr != nil {
+		t.Fatalf("unexpected stream error after reconnect: %v", streamErr)
+	}
+	if requests != 2 {
+		t.Fatalf("upstream requests = %d, want 2", requests)
+	}
+	if !strings.Contains(strings.Join(payloads, "\n"), `"hello"`) {
+		t.Fatalf("reconnected stream content missing: %v", payloads)
+	}
+}
+
+func TestAntigravityExecuteStream_DoesNotRestartAfterContentWasForwarded(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Antigravity.StreamReconnect = "restart"
+
+	requests, payloads, streamErr := runDroppingAntigravityStream(t, cfg, droppedChunk)
+	if streamErr == nil {
+		t.Fatal("expected the stream to end with an error once content was forwarded")
+	}
+	if requests != 1 {
+		t.Fatalf("upstream requests = %d, want 1: a restart would repeat delivered content", requests)
+	}
+	joined := strings.Join(payloads, "\n")
+	if !strings.Contains(joined, `"hel"`) || strings.Contains(joined, `"hello"`) {
+		t.Fatalf("stream payloads = %v, want only the content sent before the drop", payloads)
+	}
+}
+
+func TestUsageReporterRestartStreamDropsFirstAttempt(t *testing.T) {
+	reporter := &usageReporter{}
+	ctx := reporter.trackStreamToolCalls(context.Background())
+	passthrough := sdktranslator.FromString("restart-test")
+	dropped := []byte(`{"response":{"candidates":[{"content":{"parts":[{"text":"hel"},{"functionCall":{"name":"f","args":{}}}]}}]}}`)
+	restarted := []byte(`{"response":{"candidates":[{"content":{"parts":[{"text":"hello"},{"functionCall":{"name":"f","args":{}}}]},"finishReason":"STOP"}]}}`)
+
+	reporter.observeOutput(dropped)
+	sdktranslator.TranslateStream(ctx, passthrough, passthrough, "m", nil, nil, dropped, nil)
+	reporter.restartStream()
+	reporter.observeOutput(restarted)
+	sdktranslator.TranslateStream(ctx, passthrough, passthrough, "m", nil, nil, restarted, nil)
+
+	if got := reporter.toolCalls.Load(); got != 1 {
+		t.Fatalf("tool calls after restart = %d, want 1", got)
+	}
+	if got, want := reporter.outputTokens.Load(), estimateStreamOutputTokens(restarted); got != want {
+		t.Fatalf("output tokens after restart = %d, want %d (restarted attempt only)", got, want)
+	}
+}
+
+func TestAntigravityExecuteStream_NoReconnectByDefault(t *testing.T) {
+	requests, _, streamErr := runDroppingAntigravityStream(t, &config.Config{}, droppedChunk)
+	if streamErr == nil {
+		t.Fatalf("expected stream error without reconnect")
+	}
+	if requests != 1 {
+		t.Fatalf("upstream requests = %d, want 1", requests)
+	}
+}
@@ -0,0 +1,54 @@
+package executor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+const truncatedAntigravityStream = `{"response":{"candidates":[{"content":{"role":"model","parts":[{"text":"hello "}]}}]},"traceId":"t-1"}
+{"response":{"candidates":[{"content":{"role":"model","parts":[{"text":"wor`
+
+func TestAntigravityConvertStreamToNonStream_RepairsTruncatedStream(t *testing.T) {
+	exec := NewAntigravityExecutor(nil)
+
+	cases := []struct {
+		name       string
+		streamErr  error
+		wantFinish string
+	}{
+		{name: "clean eof", wantFinish: "MAX_TOKENS"},
+		{name: "read error", streamErr: errors.New("unexpected EOF"), wantFinish: "OTHER"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := exec.convertStreamToNonStream([]byte(truncatedAntigravityStream), true, tc.streamErr)
+			if !gjson.ValidBytes(out) {
+				t.Fatalf("expected valid JSON, got %s", out)
+			}
+			if got := gjson.GetBytes(out, "response.candidates.0.content.parts.0.text").String(); got != "hello wor" {
+				t.Fatalf("text = %q, want %q", got, "hello wor")
+			}
+			if got := gjson.GetBytes(out, "response.candidates.0.finishReason").String(); got != tc.wantFinish {
+				t.Fatalf("finishReason = %q, want %q", got, tc.wantFinish)
+			}
+			if !gjson.GetBytes(out, "response.candidates.0.finishMessage").Exists() {
+				t.Fatalf("expected finishMessage marking truncation, got %s", out)
+			}
+		})
+	}
+}
+
+func TestAntigravityConvertStreamToNonStream_NoRepairDropsPartialChunk(t *testing.T) {
+	exec := NewAntigravityExecutor(nil)
+	out := exec.convertStreamToNonStream([]byte(truncatedAntigravityStream), false, nil)
+
+	if got := gjson.GetBytes(out, "response.candidates.0.content.parts.0.text").String(); got != "hello " {
+		t.Fatalf("text = %q, want %q", got, "hello ")
+	}
+	if gjson.GetBytes(out, "response.candidates.0.finishMessage").Exists() {
+		t.Fatalf("expected no truncation marker without repair, got %s", out)
+	}
+}
@@ -0,0 +1,59 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestAntigravityNoCapacityRetryDelayFullJitterBounds(t *testing.T) {
+	cfg := &config.Config{}
+	for attempt := 0; attempt < 12; attempt++ {
+		base := time.Duration(attempt+1) * 250 * time.Millisecond
+		if base > 2*time.Second {
+			base = 2 * time.Second
+		}
+		for i := 0; i < 200; i++ {
+			delay := antigravityNoCapacityRetryDelay(cfg, attempt, 0)
+			if delay < 0 || delay > base {
+				t.Fatalf("attempt %d: delay %s outside [0, %s]", attempt, delay, base)
+			}
+		}
+	}
+}
+
+func TestAntigravityNoCapacityRetryDelayDecorrelatedBounds(t *testing.T) {
+	cfg := &config.Config{Antigravity: config.AntigravityConfig{
+		NoCapacityRetryJitter:     "decorrelated",
+		NoCapacityRetryMaxDelayMs: 1500,
+	}}
+	maxDelay := 1500 * time.Millisecond
+	for run := 0; run < 100; run++ {
+		var previous time.Duration
+		for attempt := 0; attempt < 8; attempt++ {
+			delay := antigravityNoCapacityRetryDelay(cfg, attempt, previous)
+			upper := 3 * max(previous, 250*time.Millisecond)
+			if upper > maxDelay {
+				upper = maxDelay
+			}
+			if delay < 250*time.Millisecond || delay > upper {
+				t.Fatalf("attempt %d (previous %s): delay %s outside [250ms, %s]", attempt, previous, delay, upper)
+			}
+			previous = delay
+		}
+	}
+}
+
+func TestAntigravityNoCapacityRetryDelayWithoutJitter(t *testing.T) {
+	cfg := &config.Config{Antigravity: config.AntigravityConfig{
+		NoCapacityRetryJitter:     "none",
+		NoCapacityRetryMaxDelayMs: 600,
+	}}
+	want := []time.Duration{250 * time.Millisecond, 500 * time.Millisecond, 600 * time.Millisecond, 600 * time.Millisecond}
+	for attempt, expected := range want {
+		if got := antigravityNoCapacityRetryDelay(cfg, attempt, 0); got != expected {
+			t.Fatalf("attempt %d: delay = %s, want %s", attempt, got, expected)
+		}
+	}
+}
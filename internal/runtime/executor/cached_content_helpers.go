@@ -0,0 +1,38 @@
+package executor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// geminiCachedContentHeader lets clients reference a Gemini context cache
+// ("cachedContents/<id>") without changing the request body.
+const geminiCachedContentHeader = "X-Gemini-Cached-Content"
+
+// applyGeminiCachedContent sets path to the cached-content handle from the inbound
+// X-Gemini-Cached-Content header. A handle already present in the body wins.
+// Tokens served from the cache come back as usageMetadata.cachedContentTokenCount and are
+// recorded as cache reads. Caches are created through the separate cachedContents API, so
+// generate responses never carry cache-creation tokens.
+func applyGeminiCachedContent(ctx context.Context, body []byte, path string) []byte {
+	if ctx == nil || len(body) == 0 || gjson.GetBytes(body, path).Exists() {
+		return body
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil || ginCtx.Request == nil {
+		return body
+	}
+	handle := strings.TrimSpace(ginCtx.GetHeader(geminiCachedContentHeader))
+	if handle == "" {
+		return body
+	}
+	updated, errSet := sjson.SetBytes(body, path, handle)
+	if errSet != nil {
+		return body
+	}
+	return updated
+}
@@ -0,0 +1,103 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/geminicli"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+func TestAntigravityExecuteStream_SendsConfiguredUserAgent(t *testing.T) {
+	userAgents := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents <- r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, "data: {\"response\":{\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"ok\"}]},\"finishReason\":\"STOP\"}]}}\n\n")
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.Antigravity.UserAgent = "antigravity/9.9.9 linux/amd64"
+	exec := NewAntigravityExecutor(cfg)
+	auth := &cliproxyauth.Auth{
+		Attributes: map[string]string{"base_url": server.URL},
+		Metadata: map[string]any{
+			"access_token": "token",
+			"expired":      time.Now().Add(time.Hour).Format(time.RFC3339),
+		},
+	}
+	result, err := exec.ExecuteStream(context.Background(), auth, cliproxyexecutor.Request{
+		Model:   "gemini-2.5-pro",
+		Payload: []byte(`{"request":{"contents":[{"role":"user","parts":[{"text":"hi"}]}]}}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("antigravity"), Stream: true})
+	if err != nil {
+		t.Fatalf("ExecuteStream error: %v", err)
+	}
+	for range result.Chunks {
+	}
+
+	if got := <-userAgents; got != "antigravity/9.9.9 linux/amd64" {
+		t.Fatalf("User-Agent = %q, want configured override", got)
+	}
+}
+
+func TestApplyGeminiCLIHeaders_Overrides(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.GeminiCLI.UserAgent = "config-agent"
+	cfg.GeminiCLI.APIClient = "config-client"
+
+	cases := []struct {
+		name         string
+		cfg          *config.Config
+		auth         *cliproxyauth.Auth
+		wantAgent    string
+		wantClient   string
+		wantMetadata string
+	}{
+		{
+			name:         "defaults",
+			wantAgent:    geminicli.UserAgent,
+			wantClient:   geminicli.APIClient,
+			wantMetadata: geminicli.ClientMetadata,
+		},
+		{
+			name:         "config",
+			cfg:          cfg,
+			wantAgent:    "config-agent",
+			wantClient:   "config-client",
+			wantMetadata: geminicli.ClientMetadata,
+		},
+		{
+			name:         "auth metadata wins over config",
+			cfg:          cfg,
+			auth:         &cliproxyauth.Auth{Metadata: map[string]any{"user_agent": "auth-agent", "client_metadata": "ideType=VSCODE"}},
+			wantAgent:    "auth-agent",
+			wantClient:   "config-client",
+			wantMetadata: "ideType=VSCODE",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "https://cloudcode-pa.googleapis.com/v1internal:generateContent", nil)
+			applyGeminiCLIHeaders(req, tc.cfg, tc.auth)
+			if got := req.Header.Get("User-Agent"); got != tc.wantAgent {
+				t.Fatalf("User-Agent = %q, want %q", got, tc.wantAgent)
+			}
+			if got := req.Header.Get("X-Goog-Api-Client"); got != tc.wantClient {
+				t.Fatalf("X-Goog-Api-Client = %q, want %q", got, tc.wantClient)
+			}
+			if got := req.Header.Get("Client-Metadata"); got != tc.wantMetadata {
+				t.Fatalf("Client-Metadata = %q, want %q", got, tc.wantMetadata)
+			}
+		})
+	}
+}
@@ -81,7 +81,7 @@ func (e *GeminiCLIExecutor) PrepareRequest(req *http.Request, auth *cliproxyauth
 		return statusErr{code: http.StatusUnauthorized, msg: "missing access token"}
 	}
 	req.Header.Set("Authorization", "Bearer "+tok.AccessToken)
-	applyGeminiCLIHeaders(req)
+	applyGeminiCLIHeaders(req, e.cfg, auth)
 	return nil
 }
 
@@ -115,9 +115,13 @@ func (e *GeminiCLIExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth
 
 	reporter := newUsageReporter(ctx, e.Identifier(), baseModel, auth)
 	defer reporter.trackFailure(ctx, &err)
+	ctx = reporter.trackToolCalls(ctx)
 
 	from := opts.SourceFormat
 	to := sdktranslator.FromString("gemini-cli")
+	if err := checkTranslatorPair(e.cfg, from, to, false); err != nil {
+		return resp, err
+	}
 
 	originalPayloadSource := req.Payload
 	if len(opts.OriginalRequest) > 0 {
@@ -125,8 +129,12 @@ func (e *GeminiCLIExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth
 	}
 	originalPayload := originalPayloadSource
 	originalTranslated := sdktranslator.TranslateRequest(from, to, baseModel, originalPayload, false)
-	basePayload := sdktranslator.TranslateRequest(from, to, baseModel, req.Payload, false)
+	basePayload, err := sdktranslator.TranslateRequestForProvider(e.Identifier(), from, to, baseModel, req.Payload, false)
+	if err != nil {
+		return resp, err
+	}
 
+	basePayload = applyMaxTokensClamp(basePayload, req.Model, to.String(), e.Identifier())
 	basePayload, err = thinking.ApplyThinking(basePayload, req.Model, from.String(), to.String(), e.Identifier())
 	if err != nil {
 		return resp, err
@@ -135,6 +143,8 @@ func (e *GeminiCLIExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth
 	basePayload = fixGeminiCLIImageAspectRatio(baseModel, basePayload)
 	requestedModel := payloadRequestedModel(opts, req.Model)
 	basePayload = applyPayloadConfigWithRoot(e.cfg, baseModel, "gemini", "request", basePayload, originalTranslated, requestedModel)
+	basePayload = applyRequestIDField(ctx, e.cfg, e.Identifier(), basePayload)
+	basePayload = applyGeminiCachedContent(ctx, basePayload, "request.cachedContent")
 
 	action := "generateContent"
 	if req.Metadata != nil {
@@ -177,7 +187,7 @@ func (e *GeminiCLIExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth
 		}
 		updateGeminiCLITokenMetadata(auth, baseTokenData, tok)
 
-		url := fmt.Sprintf("%s/%s:%s", codeAssistEndpoint, codeAssistVersion, action)
+		url := fmt.Sprintf("%s/%s:%s", geminiCLIBaseURL(e.cfg, auth), codeAssistVersion, action)
 		if opts.Alt != "" && action != "countTokens" {
 			url = url + fmt.Sprintf("?$alt=%s", opts.Alt)
 		}
@@ -189,7 +199,7 @@ func (e *GeminiCLIExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth
 		}
 		reqHTTP.Header.Set("Content-Type", "application/json")
 		reqHTTP.Header.Set("Authorization", "Bearer "+tok.AccessToken)
-		applyGeminiCLIHeaders(reqHTTP)
+		applyGeminiCLIHeaders(reqHTTP, e.cfg, auth)
 		reqHTTP.Header.Set("Accept", "application/json")
 		recordAPIRequest(ctx, e.cfg, upstreamRequestLog{
 			URL:       url,
@@ -269,9 +279,14 @@ func (e *GeminiCLIExecutor) ExecuteStream(ctx context.Context, auth *cliproxyaut
 
 	reporter := newUsageReporter(ctx, e.Identifier(), baseModel, auth)
 	defer reporter.trackFailure(ctx, &err)
+	ctx = reporter.trackStreamToolCalls(ctx)
+	reporter.setPromptPayload(req.Payload)
 
 	from := opts.SourceFormat
 	to := sdktranslator.FromString("gemini-cli")
+	if err := checkTranslatorPair(e.cfg, from, to, true); err != nil {
+		return nil, err
+	}
 
 	originalPayloadSource := req.Payload
 	if len(opts.OriginalRequest) > 0 {
@@ -279,8 +294,12 @@ func (e *GeminiCLIExecutor) ExecuteStream(ctx context.Context, auth *cliproxyaut
 	}
 	originalPayload := originalPayloadSource
 	originalTranslated := sdktranslator.TranslateRequest(from, to, baseModel, originalPayload, true)
-	basePayload := sdktranslator.TranslateRequest(from, to, baseModel, req.Payload, true)
+	basePayload, err := sdktranslator.TranslateRequestForProvider(e.Identifier(), from, to, baseModel, req.Payload, true)
+	if err != nil {
+		return nil, err
+	}
 
+	basePayload = applyMaxTokensClamp(basePayload, req.Model, to.String(), e.Identifier())
 	basePayload, err = thinking.ApplyThinking(basePayload, req.Model, from.String(), to.String(), e.Identifier())
 	if err != nil {
 		return nil, err
@@ -289,6 +308,8 @@ func (e *GeminiCLIExecutor) ExecuteStream(ctx context.Context, auth *cliproxyaut
 	basePayload = fixGeminiCLIImageAspectRatio(baseModel, basePayload)
 	requestedModel := payloadRequestedModel(opts, req.Model)
 	basePayload = applyPayloadConfigWithRoot(e.cfg, baseModel, "gemini", "request", basePayload, originalTranslated, requestedModel)
+	basePayload = applyRequestIDField(ctx, e.cfg, e.Identifier(), basePayload)
+	basePayload = applyGeminiCachedContent(ctx, basePayload, "request.cachedContent")
 
 	projectID := resolveGeminiProjectID(auth)
 
@@ -320,7 +341,7 @@ func (e *GeminiCLIExecutor) ExecuteStream(ctx context.Context, auth *cliproxyaut
 		}
 		updateGeminiCLITokenMetadata(auth, baseTokenData, tok)
 
-		url := fmt.Sprintf("%s/%s:%s", codeAssistEndpoint, codeAssistVersion, "streamGenerateContent")
+		url := fmt.Sprintf("%s/%s:%s", geminiCLIBaseURL(e.cfg, auth), codeAssistVersion, "streamGenerateContent")
 		if opts.Alt == "" {
 			url = url + "?alt=sse"
 		} else {
@@ -334,7 +355,7 @@ func (e *GeminiCLIExecutor) ExecuteStream(ctx context.Context, auth *cliproxyaut
 		}
 		reqHTTP.Header.Set("Content-Type", "application/json")
 		reqHTTP.Header.Set("Authorization", "Bearer "+tok.AccessToken)
-		applyGeminiCLIHeaders(reqHTTP)
+		applyGeminiCLIHeaders(reqHTTP, e.cfg, auth)
 		reqHTTP.Header.Set("Accept", "text/event-stream")
 		recordAPIRequest(ctx, e.cfg, upstreamRequestLog{
 			URL:       url,
@@ -381,9 +402,18 @@ func (e *GeminiCLIExecutor) ExecuteStream(ctx context.Context, auth *cliproxyaut
 			return nil, err
 		}
 
+		recorder := newStreamRecorder(e.cfg, e.Identifier(), sdktranslator.StreamRecordingHeader{
+			SourceFormat:    from,
+			ProviderFormat:  to,
+			Model:           attemptModel,
+			OriginalRequest: string(opts.OriginalRequest),
+			Request:         string(payload),
+		})
 		out := make(chan cliproxyexecutor.StreamChunk)
 		go func(resp *http.Response, reqBody []byte, attemptModel string) {
 			defer close(out)
+			defer recorder.close()
+			defer reporter.publishHeld(ctx)
 			defer func() {
 				if errClose := resp.Body.Close(); errClose != nil {
 					log.Errorf("gemini cli executor: close response body error: %v", errClose)
@@ -396,10 +426,12 @@ func (e *GeminiCLIExecutor) ExecuteStream(ctx context.Context, auth *cliproxyaut
 				for scanner.Scan() {
 					line := scanner.Bytes()
 					appendAPIResponseChunk(ctx, e.cfg, line)
+					reporter.observeOutput(line)
 					if detail, ok := parseGeminiCLIStreamUsage(line); ok {
 						reporter.publish(ctx, detail)
 					}
 					if bytes.HasPrefix(line, dataTag) {
+						recorder.record(line)
 						segments := sdktranslator.TranslateStream(respCtx, to, from, attemptModel, opts.OriginalRequest, reqBody, bytes.Clone(line), &param)
 						for i := range segments {
 							out <- cliproxyexecutor.StreamChunk{Payload: []byte(segments[i])}
@@ -407,6 +439,7 @@ func (e *GeminiCLIExecutor) ExecuteStream(ctx context.Context, auth *cliproxyaut
 					}
 				}
 
+				recorder.record([]byte("[DONE]"))
 				segments := sdktranslator.TranslateStream(respCtx, to, from, attemptModel, opts.OriginalRequest, reqBody, []byte("[DONE]"), &param)
 				for i := range segments {
 					out <- cliproxyexecutor.StreamChunk{Payload: []byte(segments[i])}
@@ -415,6 +448,8 @@ func (e *GeminiCLIExecutor) ExecuteStream(ctx context.Context, auth *cliproxyaut
 					recordAPIResponseError(ctx, e.cfg, errScan)
 					reporter.publishFailure(ctx)
 					out <- cliproxyexecutor.StreamChunk{Err: errScan}
+				} else {
+					reporter.ensurePublished(ctx)
 				}
 				return
 			}
@@ -429,11 +464,13 @@ func (e *GeminiCLIExecutor) ExecuteStream(ctx context.Context, auth *cliproxyaut
 			appendAPIResponseChunk(ctx, e.cfg, data)
 			reporter.publish(ctx, parseGeminiCLIUsage(data))
 			var param any
+			recorder.record(data)
 			segments := sdktranslator.TranslateStream(respCtx, to, from, attemptModel, opts.OriginalRequest, reqBody, data, &param)
 			for i := range segments {
 				out <- cliproxyexecutor.StreamChunk{Payload: []byte(segments[i])}
 			}
 
+			recorder.record([]byte("[DONE]"))
 			segments = sdktranslator.TranslateStream(respCtx, to, from, attemptModel, opts.OriginalRequest, reqBody, []byte("[DONE]"), &param)
 			for i := range segments {
 				out <- cliproxyexecutor.StreamChunk{Payload: []byte(segments[i])}
@@ -486,7 +523,10 @@ func (e *GeminiCLIExecutor) CountTokens(ctx context.Context, auth *cliproxyauth.
 	// The loop variable attemptModel is only used as the concrete model id sent to the upstream
 	// Gemini CLI endpoint when iterating fallback variants.
 	for range models {
-		payload := sdktranslator.TranslateRequest(from, to, baseModel, req.Payload, false)
+		payload, err := sdktranslator.TranslateRequestForProvider(e.Identifier(), from, to, baseModel, req.Payload, false)
+		if err != nil {
+			return cliproxyexecutor.Response{}, err
+		}
 
 		payload, err = thinking.ApplyThinking(payload, req.Model, from.String(), to.String(), e.Identifier())
 		if err != nil {
@@ -504,7 +544,7 @@ func (e *GeminiCLIExecutor) CountTokens(ctx context.Context, auth *cliproxyauth.
 		}
 		updateGeminiCLITokenMetadata(auth, baseTokenData, tok)
 
-		url := fmt.Sprintf("%s/%s:%s", codeAssistEndpoint, codeAssistVersion, "countTokens")
+		url := fmt.Sprintf("%s/%s:%s", geminiCLIBaseURL(e.cfg, auth), codeAssistVersion, "countTokens")
 		if opts.Alt != "" {
 			url = url + fmt.Sprintf("?$alt=%s", opts.Alt)
 		}
@@ -515,7 +555,7 @@ func (e *GeminiCLIExecutor) CountTokens(ctx context.Context, auth *cliproxyauth.
 		}
 		reqHTTP.Header.Set("Content-Type", "application/json")
 		reqHTTP.Header.Set("Authorization", "Bearer "+tok.AccessToken)
-		applyGeminiCLIHeaders(reqHTTP)
+		applyGeminiCLIHeaders(reqHTTP, e.cfg, auth)
 		reqHTTP.Header.Set("Accept", "application/json")
 		recordAPIRequest(ctx, e.cfg, upstreamRequestLog{
 			URL:       url,
@@ -738,21 +778,59 @@ func stringValue(m map[string]any, key string) string {
 }
 
 // applyGeminiCLIHeaders sets required headers for the Gemini CLI upstream.
-func applyGeminiCLIHeaders(r *http.Request) {
+// Client-supplied headers win, then per-auth metadata, then config, then built-in defaults.
+func applyGeminiCLIHeaders(r *http.Request, cfg *config.Config, auth *cliproxyauth.Auth) {
 	var ginHeaders http.Header
 	if ginCtx, ok := r.Context().Value("gin").(*gin.Context); ok && ginCtx != nil && ginCtx.Request != nil {
 		ginHeaders = ginCtx.Request.Header
 	}
 
-	misc.EnsureHeader(r.Header, ginHeaders, "User-Agent", "google-api-nodejs-client/9.15.1")
-	misc.EnsureHeader(r.Header, ginHeaders, "X-Goog-Api-Client", "gl-node/22.17.0")
-	misc.EnsureHeader(r.Header, ginHeaders, "Client-Metadata", geminiCLIClientMetadata())
+	var userAgent, apiClient, clientMetadata string
+	if cfg != nil {
+		userAgent = cfg.GeminiCLI.UserAgent
+		apiClient = cfg.GeminiCLI.APIClient
+		clientMetadata = cfg.GeminiCLI.ClientMetadata
+	}
+	misc.EnsureHeader(r.Header, ginHeaders, "User-Agent", geminiCLIHeaderValue(auth, "user_agent", userAgent, geminicli.UserAgent))
+	misc.EnsureHeader(r.Header, ginHeaders, "X-Goog-Api-Client", geminiCLIHeaderValue(auth, "api_client", apiClient, geminicli.APIClient))
+	misc.EnsureHeader(r.Header, ginHeaders, "Client-Metadata", geminiCLIHeaderValue(auth, "client_metadata", clientMetadata, geminicli.ClientMetadata))
+	applyUpstreamHeaders(r, cfg, "gemini-cli", auth)
+}
+
+// geminiCLIBaseURL returns the Code Assist endpoint for auth: the configured entry for the
+// auth's region, else the configured base URL, else the default endpoint.
+func geminiCLIBaseURL(cfg *config.Config, auth *cliproxyauth.Auth) string {
+	if cfg != nil {
+		if endpoint := cfg.GeminiCLI.Endpoint(authRegion(auth)); endpoint != "" {
+			return endpoint
+		}
+	}
+	return codeAssistEndpoint
 }
 
-// geminiCLIClientMetadata returns a compact metadata string required by upstream.
-func geminiCLIClientMetadata() string {
-	// Keep parity with CLI client defaults
-	return "ideType=IDE_UNSPECIFIED,platform=PLATFORM_UNSPECIFIED,pluginType=GEMINI"
+// authRegion returns the "region" attribute of auth, falling back to its metadata.
+func authRegion(auth *cliproxyauth.Auth) string {
+	if auth == nil {
+		return ""
+	}
+	if region := strings.TrimSpace(auth.Attributes["region"]); region != "" {
+		return region
+	}
+	return strings.TrimSpace(stringValue(auth.Metadata, "region"))
+}
+
+// geminiCLIHeaderValue resolves a header value from auth metadata, the configured value
+// and the built-in default, in that order.
+func geminiCLIHeaderValue(auth *cliproxyauth.Auth, metadataKey, configured, fallback string) string {
+	if auth != nil {
+		if v := strings.TrimSpace(stringValue(auth.Metadata, metadataKey)); v != "" {
+			return v
+		}
+	}
+	if v := strings.TrimSpace(configured); v != "" {
+		return v
+	}
+	return fallback
 }
 
 // cliPreviewFallbackOrder returns preview model candidates for a base model.
@@ -0,0 +1,54 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestGeminiCLIBaseURL_Regions(t *testing.T) {
+	cfg := &config.Config{GeminiCLI: config.GeminiCLIConfig{
+		BaseURL: "https://proxy.example.com/",
+		Regions: map[string]string{"eu": "https://eu-cloudcode.example.com"},
+	}}
+	cases := []struct {
+		name string
+		cfg  *config.Config
+		auth *cliproxyauth.Auth
+		want string
+	}{
+		{"unconfigured", &config.Config{}, &cliproxyauth.Auth{Metadata: map[string]any{"region": "eu"}}, codeAssistEndpoint},
+		{"base url", cfg, &cliproxyauth.Auth{}, "https://proxy.example.com"},
+		{"metadata region", cfg, &cliproxyauth.Auth{Metadata: map[string]any{"region": "eu"}}, "https://eu-cloudcode.example.com"},
+		{"attribute region", cfg, &cliproxyauth.Auth{Attributes: map[string]string{"region": "eu"}}, "https://eu-cloudcode.example.com"},
+		{"unknown region", cfg, &cliproxyauth.Auth{Metadata: map[string]any{"region": "asia"}}, "https://proxy.example.com"},
+	}
+	for _, tc := range cases {
+		if got := geminiCLIBaseURL(tc.cfg, tc.auth); got != tc.want {
+			t.Errorf("%s: geminiCLIBaseURL = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestVertexBaseURL_Regions(t *testing.T) {
+	auth := &cliproxyauth.Auth{Metadata: map[string]any{"location": "europe-west4"}}
+	if got := vertexBaseURL(&config.Config{}, auth, "europe-west4"); got != "https://europe-west4-aiplatform.googleapis.com" {
+		t.Fatalf("default vertexBaseURL = %q", got)
+	}
+
+	cfg := &config.Config{Vertex: config.VertexConfig{
+		BaseURL: "https://{location}-vertex.example.com",
+		Regions: map[string]string{"europe-west4": "https://eu.vertex.example.com"},
+	}}
+	if got := vertexBaseURL(cfg, auth, "europe-west4"); got != "https://eu.vertex.example.com" {
+		t.Fatalf("regional vertexBaseURL = %q, want the europe-west4 entry", got)
+	}
+	if got := vertexBaseURL(cfg, auth, "us-east5"); got != "https://us-east5-vertex.example.com" {
+		t.Fatalf("templated vertexBaseURL = %q", got)
+	}
+	pinned := &cliproxyauth.Auth{Attributes: map[string]string{"region": "europe-west4"}}
+	if got := vertexBaseURL(cfg, pinned, "us-central1"); got != "https://eu.vertex.example.com" {
+		t.Fatalf("vertexBaseURL with region attribute = %q, want the europe-west4 entry", got)
+	}
+}
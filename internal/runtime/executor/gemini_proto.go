@@ -0,0 +1,412 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	_ "google.golang.org/protobuf/types/known/structpb" // registers google/protobuf/struct.proto
+)
+
+// geminiProtoContentType is the content type of protobuf-encoded Gemini API bodies.
+const geminiProtoContentType = "application/x-protobuf"
+
+// errGeminiProtoIncomplete reports a protobuf response carrying fields the schema below
+// does not describe; decoding it would silently drop them.
+var errGeminiProtoIncomplete = errors.New("gemini protobuf: response has fields outside the known schema")
+
+// geminiProtobufEnabled reports whether gemini-upstream-encoding selects protobuf.
+func geminiProtobufEnabled(cfg *config.Config) bool {
+	return cfg != nil && strings.EqualFold(strings.TrimSpace(cfg.GeminiUpstreamEncoding), "protobuf")
+}
+
+// isGeminiProtoResponse reports whether an upstream response body is protobuf-encoded.
+func isGeminiProtoResponse(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && mediaType == geminiProtoContentType
+}
+
+// encodeGeminiProtoRequest converts a translated Gemini generateContent JSON body to its
+// protobuf wire form. It fails for bodies using fields outside the supported subset of
+// the v1beta schema (tools, response schemas, ...), which are then sent as JSON.
+func encodeGeminiProtoRequest(body []byte) ([]byte, error) {
+	desc, err := geminiProtoMessage("GenerateContentRequest")
+	if err != nil {
+		return nil, err
+	}
+	msg := dynamicpb.NewMessage(desc)
+	if err = protojson.Unmarshal(body, msg); err != nil {
+		return nil, fmt.Errorf("gemini protobuf: encode request: %w", err)
+	}
+	return proto.Marshal(msg)
+}
+
+// decodeGeminiProtoResponse converts a protobuf GenerateContentResponse to the JSON the
+// Gemini translators expect. It returns errGeminiProtoIncomplete instead of a partial
+// result when the response has fields the schema does not know.
+func decodeGeminiProtoResponse(data []byte) ([]byte, error) {
+	desc, err := geminiProtoMessage("GenerateContentResponse")
+	if err != nil {
+		return nil, err
+	}
+	msg := dynamicpb.NewMessage(desc)
+	if err = proto.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("gemini protobuf: decode GenerateContentResponse: %w", err)
+	}
+	if hasUnknownProtoFields(msg) {
+		return nil, errGeminiProtoIncomplete
+	}
+	return protojson.Marshal(msg)
+}
+
+// hasUnknownProtoFields reports whether msg or any message nested in it kept fields that
+// are not in its descriptor.
+func hasUnknownProtoFields(msg protoreflect.Message) bool {
+	if len(msg.GetUnknown()) > 0 {
+		return true
+	}
+	unknown := false
+	msg.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		switch {
+		case field.IsMap():
+			if field.MapValue().Message() != nil {
+				value.Map().Range(func(_ protoreflect.MapKey, entry protoreflect.Value) bool {
+					unknown = hasUnknownProtoFields(entry.Message())
+					return !unknown
+				})
+			}
+		case field.Message() == nil:
+		case field.IsList():
+			list := value.List()
+			for i := 0; i < list.Len() && !unknown; i++ {
+				unknown = hasUnknownProtoFields(list.Get(i).Message())
+			}
+		default:
+			unknown = hasUnknownProtoFields(value.Message())
+		}
+		return !unknown
+	})
+	return unknown
+}
+
+// decodeGeminiProtoError converts a protobuf google.rpc.Status error body to the JSON
+// error envelope of the Gemini REST API.
+func decodeGeminiProtoError(data []byte) ([]byte, error) {
+	status, err := decodeGeminiProto("Status", data)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte(`{"error":`), status...), '}'), nil
+}
+
+func decodeGeminiProto(name string, data []byte) ([]byte, error) {
+	desc, err := geminiProtoMessage(name)
+	if err != nil {
+		return nil, err
+	}
+	msg := dynamicpb.NewMessage(desc)
+	if err = proto.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("gemini protobuf: decode %s: %w", name, err)
+	}
+	return protojson.Marshal(msg)
+}
+
+var (
+	geminiProtoOnce sync.Once
+	geminiProtoFile protoreflect.FileDescriptor
+	geminiProtoErr  error
+)
+
+// geminiProtoMessage returns the descriptor of a message in the Gemini protobuf schema.
+func geminiProtoMessage(name string) (protoreflect.MessageDescriptor, error) {
+	geminiProtoOnce.Do(func() {
+		geminiProtoFile, geminiProtoErr = protodesc.NewFile(geminiProtoSchema(), protoregistry.GlobalFiles)
+	})
+	if geminiProtoErr != nil {
+		return nil, fmt.Errorf("gemini protobuf: build schema: %w", geminiProtoErr)
+	}
+	desc := geminiProtoFile.Messages().ByName(protoreflect.Name(name))
+	if desc == nil {
+		return nil, fmt.Errorf("gemini protobuf: unknown message %s", name)
+	}
+	return desc, nil
+}
+
+// geminiProtoSchema describes the subset of google.ai.generativelanguage.v1beta used for
+// text, media, function call and code execution exchanges, with the full candidate
+// metadata (citations, grounding, logprobs) of responses. Field numbers follow the
+// published protos.
+func geminiProtoSchema() *descriptorpb.FileDescriptorProto {
+	const pkg = ".google.ai.generativelanguage.v1beta."
+	var (
+		tString  = descriptorpb.FieldDescriptorProto_TYPE_STRING
+		tBytes   = descriptorpb.FieldDescriptorProto_TYPE_BYTES
+		tBool    = descriptorpb.FieldDescriptorProto_TYPE_BOOL
+		tInt32   = descriptorpb.FieldDescriptorProto_TYPE_INT32
+		tFloat   = descriptorpb.FieldDescriptorProto_TYPE_FLOAT
+		tDouble  = descriptorpb.FieldDescriptorProto_TYPE_DOUBLE
+		tMessage = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+		tEnum    = descriptorpb.FieldDescriptorProto_TYPE_ENUM
+	)
+	harmCategory := protoEnum("HarmCategory", "HARM_CATEGORY_UNSPECIFIED", "HARM_CATEGORY_DEROGATORY",
+		"HARM_CATEGORY_TOXICITY", "HARM_CATEGORY_VIOLENCE", "HARM_CATEGORY_SEXUAL", "HARM_CATEGORY_MEDICAL",
+		"HARM_CATEGORY_DANGEROUS", "HARM_CATEGORY_HARASSMENT", "HARM_CATEGORY_HATE_SPEECH",
+		"HARM_CATEGORY_SEXUALLY_EXPLICIT", "HARM_CATEGORY_DANGEROUS_CONTENT", "HARM_CATEGORY_CIVIC_INTEGRITY")
+
+	safetySetting := protoMessage("SafetySetting",
+		protoField("category", 3, tEnum, pkg+"HarmCategory"),
+		protoField("threshold", 4, tEnum, pkg+"SafetySetting.HarmBlockThreshold"))
+	safetySetting.EnumType = []*descriptorpb.EnumDescriptorProto{protoEnum("HarmBlockThreshold",
+		"HARM_BLOCK_THRESHOLD_UNSPECIFIED", "BLOCK_LOW_AND_ABOVE", "BLOCK_MEDIUM_AND_ABOVE", "BLOCK_ONLY_HIGH",
+		"BLOCK_NONE", "OFF")}
+
+	safetyRating := protoMessage("SafetyRating",
+		protoField("category", 3, tEnum, pkg+"HarmCategory"),
+		protoField("probability", 4, tEnum, pkg+"SafetyRating.HarmProbability"),
+		protoField("blocked", 5, tBool, ""))
+	safetyRating.EnumType = []*descriptorpb.EnumDescriptorProto{protoEnum("HarmProbability",
+		"HARM_PROBABILITY_UNSPECIFIED", "NEGLIGIBLE", "LOW", "MEDIUM", "HIGH")}
+
+	part := protoMessage("Part",
+		protoField("text", 2, tString, ""),
+		protoField("inline_data", 3, tMessage, pkg+"Blob"),
+		protoField("function_call", 4, tMessage, pkg+"FunctionCall"),
+		protoField("function_response", 5, tMessage, pkg+"FunctionResponse"),
+		protoField("file_data", 6, tMessage, pkg+"FileData"),
+		protoField("executable_code", 9, tMessage, pkg+"ExecutableCode"),
+		protoField("code_execution_result", 10, tMessage, pkg+"CodeExecutionResult"),
+		protoField("thought", 11, tBool, ""),
+		protoField("thought_signature", 13, tBytes, ""))
+	part.OneofDecl = []*descriptorpb.OneofDescriptorProto{{Name: proto.String("data")}}
+	for _, field := range part.Field[:7] {
+		field.OneofIndex = proto.Int32(0)
+	}
+
+	executableCode := protoMessage("ExecutableCode",
+		protoField("language", 1, tEnum, pkg+"ExecutableCode.Language"),
+		protoField("code", 2, tString, ""))
+	executableCode.EnumType = []*descriptorpb.EnumDescriptorProto{protoEnum("Language", "LANGUAGE_UNSPECIFIED", "PYTHON")}
+
+	codeExecutionResult := protoMessage("CodeExecutionResult",
+		protoField("outcome", 1, tEnum, pkg+"CodeExecutionResult.Outcome"),
+		protoField("output", 2, tString, ""))
+	codeExecutionResult.EnumType = []*descriptorpb.EnumDescriptorProto{protoEnum("Outcome",
+		"OUTCOME_UNSPECIFIED", "OUTCOME_OK", "OUTCOME_FAILED", "OUTCOME_DEADLINE_EXCEEDED")}
+
+	generationConfig := protoMessage("GenerationConfig",
+		protoOptional(protoField("candidate_count", 1, tInt32, "")),
+		protoRepeated(protoField("stop_sequences", 2, tString, "")),
+		protoOptional(protoField("max_output_tokens", 4, tInt32, "")),
+		protoOptional(protoField("temperature", 5, tFloat, "")),
+		protoOptional(protoField("top_p", 6, tFloat, "")),
+		protoOptional(protoField("top_k", 7, tInt32, "")),
+		protoField("response_mime_type", 13, tString, ""),
+		protoOptional(protoField("presence_penalty", 15, tFloat, "")),
+		protoOptional(protoField("frequency_penalty", 16, tFloat, "")),
+		protoOptional(protoField("thinking_config", 22, tMessage, pkg+"ThinkingConfig")))
+
+	candidate := protoMessage("Candidate",
+		protoOptional(protoField("index", 3, tInt32, "")),
+		protoField("content", 1, tMessage, pkg+"Content"),
+		protoField("finish_reason", 2, tEnum, pkg+"Candidate.FinishReason"),
+		protoRepeated(protoField("safety_ratings", 5, tMessage, pkg+"SafetyRating")),
+		protoField("citation_metadata", 6, tMessage, pkg+"CitationMetadata"),
+		protoField("token_count", 7, tInt32, ""),
+		protoField("grounding_metadata", 9, tMessage, pkg+"GroundingMetadata"),
+		protoField("avg_logprobs", 10, tDouble, ""),
+		protoField("logprobs_result", 11, tMessage, pkg+"LogprobsResult"))
+	candidate.EnumType = []*descriptorpb.EnumDescriptorProto{protoEnum("FinishReason",
+		"FINISH_REASON_UNSPECIFIED", "STOP", "MAX_TOKENS", "SAFETY", "RECITATION", "OTHER", "LANGUAGE",
+		"BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "MALFORMED_FUNCTION_CALL", "IMAGE_SAFETY")}
+
+	promptFeedback := protoMessage("PromptFeedback",
+		protoField("block_reason", 1, tEnum, pkg+"PromptFeedback.BlockReason"),
+		protoRepeated(protoField("safety_ratings", 2, tMessage, pkg+"SafetyRating")))
+	promptFeedback.EnumType = []*descriptorpb.EnumDescriptorProto{protoEnum("BlockReason",
+		"BLOCK_REASON_UNSPECIFIED", "SAFETY", "OTHER", "BLOCKLIST", "PROHIBITED_CONTENT", "IMAGE_SAFETY")}
+
+	citationMetadata := []*descriptorpb.DescriptorProto{
+		protoMessage("CitationSource",
+			protoOptional(protoField("start_index", 1, tInt32, "")),
+			protoOptional(protoField("end_index", 2, tInt32, "")),
+			protoOptional(protoField("uri", 3, tString, "")),
+			protoOptional(protoField("license", 4, tString, ""))),
+		protoMessage("CitationMetadata",
+			protoRepeated(protoField("citation_sources", 1, tMessage, pkg+"CitationSource"))),
+	}
+
+	groundingChunk := protoMessage("GroundingChunk",
+		protoField("web", 1, tMessage, pkg+"GroundingChunk.Web"))
+	groundingChunk.OneofDecl = []*descriptorpb.OneofDescriptorProto{{Name: proto.String("chunk_type")}}
+	groundingChunk.Field[0].OneofIndex = proto.Int32(0)
+	groundingChunk.NestedType = []*descriptorpb.DescriptorProto{protoMessage("Web",
+		protoOptional(protoField("uri", 1, tString, "")),
+		protoOptional(protoField("title", 2, tString, "")))}
+	groundingMetadata := []*descriptorpb.DescriptorProto{
+		protoMessage("SearchEntryPoint",
+			protoField("rendered_content", 1, tString, ""),
+			protoField("sdk_blob", 2, tBytes, "")),
+		groundingChunk,
+		protoMessage("Segment",
+			protoField("part_index", 1, tInt32, ""),
+			protoField("start_index", 2, tInt32, ""),
+			protoField("end_index", 3, tInt32, ""),
+			protoField("text", 4, tString, "")),
+		protoMessage("GroundingSupport",
+			protoOptional(protoField("segment", 1, tMessage, pkg+"Segment")),
+			protoRepeated(protoField("grounding_chunk_indices", 2, tInt32, "")),
+			protoRepeated(protoField("confidence_scores", 3, tFloat, ""))),
+		protoMessage("RetrievalMetadata",
+			protoField("google_search_dynamic_retrieval_score", 2, tFloat, "")),
+		protoMessage("GroundingMetadata",
+			protoOptional(protoField("search_entry_point", 1, tMessage, pkg+"SearchEntryPoint")),
+			protoRepeated(protoField("grounding_chunks", 2, tMessage, pkg+"GroundingChunk")),
+			protoRepeated(protoField("grounding_supports", 3, tMessage, pkg+"GroundingSupport")),
+			protoOptional(protoField("retrieval_metadata", 4, tMessage, pkg+"RetrievalMetadata")),
+			protoRepeated(protoField("web_search_queries", 5, tString, ""))),
+	}
+
+	logprobsResult := protoMessage("LogprobsResult",
+		protoRepeated(protoField("top_candidates", 1, tMessage, pkg+"LogprobsResult.TopCandidates")),
+		protoRepeated(protoField("chosen_candidates", 2, tMessage, pkg+"LogprobsResult.Candidate")))
+	logprobsResult.NestedType = []*descriptorpb.DescriptorProto{
+		protoMessage("Candidate",
+			protoOptional(protoField("token", 1, tString, "")),
+			protoOptional(protoField("token_id", 3, tInt32, "")),
+			protoOptional(protoField("log_probability", 2, tFloat, ""))),
+		protoMessage("TopCandidates",
+			protoRepeated(protoField("candidates", 1, tMessage, pkg+"LogprobsResult.Candidate"))),
+	}
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("cliproxy/gemini/generative_service.proto"),
+		Package:    proto.String("google.ai.generativelanguage.v1beta"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/struct.proto"},
+		EnumType:   []*descriptorpb.EnumDescriptorProto{harmCategory},
+		MessageType: []*descriptorpb.DescriptorProto{
+			protoMessage("Content",
+				protoRepeated(protoField("parts", 1, tMessage, pkg+"Part")),
+				protoField("role", 2, tString, "")),
+			part,
+			protoMessage("Blob",
+				protoField("mime_type", 1, tString, ""),
+				protoField("data", 2, tBytes, "")),
+			protoMessage("FileData",
+				protoField("mime_type", 1, tString, ""),
+				protoField("file_uri", 2, tString, "")),
+			protoMessage("FunctionCall",
+				protoField("id", 3, tString, ""),
+				protoField("name", 1, tString, ""),
+				protoField("args", 2, tMessage, ".google.protobuf.Struct")),
+			protoMessage("FunctionResponse",
+				protoField("id", 3, tString, ""),
+				protoField("name", 1, tString, ""),
+				protoField("response", 2, tMessage, ".google.protobuf.Struct")),
+			executableCode,
+			codeExecutionResult,
+			safetySetting,
+			safetyRating,
+			protoMessage("ThinkingConfig",
+				protoOptional(protoField("include_thoughts", 1, tBool, "")),
+				protoOptional(protoField("thinking_budget", 2, tInt32, ""))),
+			generationConfig,
+			protoMessage("GenerateContentRequest",
+				protoField("model", 1, tString, ""),
+				protoOptional(protoField("system_instruction", 8, tMessage, pkg+"Content")),
+				protoRepeated(protoField("contents", 2, tMessage, pkg+"Content")),
+				protoRepeated(protoField("safety_settings", 3, tMessage, pkg+"SafetySetting")),
+				protoOptional(protoField("generation_config", 4, tMessage, pkg+"GenerationConfig")),
+				protoOptional(protoField("cached_content", 9, tString, ""))),
+			candidate,
+			logprobsResult,
+			promptFeedback,
+			protoMessage("UsageMetadata",
+				protoField("prompt_token_count", 1, tInt32, ""),
+				protoField("candidates_token_count", 2, tInt32, ""),
+				protoField("total_token_count", 3, tInt32, ""),
+				protoField("cached_content_token_count", 4, tInt32, ""),
+				protoField("tool_use_prompt_token_count", 8, tInt32, ""),
+				protoField("thoughts_token_count", 10, tInt32, "")),
+			protoMessage("GenerateContentResponse",
+				protoRepeated(protoField("candidates", 1, tMessage, pkg+"Candidate")),
+				protoField("prompt_feedback", 2, tMessage, pkg+"PromptFeedback"),
+				protoField("usage_metadata", 3, tMessage, pkg+"UsageMetadata"),
+				protoField("model_version", 4, tString, ""),
+				protoField("response_id", 5, tString, "")),
+			protoMessage("Status",
+				protoField("code", 1, tInt32, ""),
+				protoField("message", 2, tString, "")),
+		},
+	}
+	file.MessageType = append(file.MessageType, citationMetadata...)
+	file.MessageType = append(file.MessageType, groundingMetadata...)
+	for _, message := range file.MessageType {
+		addSyntheticOneofs(message)
+		for _, nested := range message.NestedType {
+			addSyntheticOneofs(nested)
+		}
+	}
+	return file
+}
+
+func protoField(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+	field := &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:   typ.Enum(),
+	}
+	if typeName != "" {
+		field.TypeName = proto.String(typeName)
+	}
+	return field
+}
+
+func protoRepeated(field *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+	field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	return field
+}
+
+// protoOptional marks a proto3 field as explicitly optional so zero values are sent; the
+// synthetic oneof is added by addSyntheticOneofs.
+func protoOptional(field *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+	field.Proto3Optional = proto.Bool(true)
+	return field
+}
+
+func protoMessage(name string, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{Name: proto.String(name), Field: fields}
+}
+
+// addSyntheticOneofs declares the oneof wrapping each optional field, after the message's
+// real oneofs as protoc does.
+func addSyntheticOneofs(message *descriptorpb.DescriptorProto) {
+	for _, field := range message.Field {
+		if !field.GetProto3Optional() {
+			continue
+		}
+		field.OneofIndex = proto.Int32(int32(len(message.OneofDecl)))
+		message.OneofDecl = append(message.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String("_" + field.GetName())})
+	}
+}
+
+// protoEnum builds an enum whose values are numbered in declaration order from zero.
+func protoEnum(name string, values ...string) *descriptorpb.EnumDescriptorProto {
+	enum := &descriptorpb.EnumDescriptorProto{Name: proto.String(name)}
+	for i, value := range values {
+		enum.Value = append(enum.Value, &descriptorpb.EnumValueDescriptorProto{Name: proto.String(value), Number: proto.Int32(int32(i))})
+	}
+	return enum
+}
@@ -0,0 +1,172 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/openai/chat-completions"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const geminiProtoTestResponse = `{"candidates":[{"content":{"role":"model","parts":[{"text":"hello there"}]},"finishReason":"STOP","index":0}],"usageMetadata":{"promptTokenCount":7,"candidatesTokenCount":2,"totalTokenCount":9},"modelVersion":"gemini-2.5-flash","responseId":"resp-1"}`
+
+func TestGeminiExecutor_ProtobufUpstreamEncoding(t *testing.T) {
+	var gotContentType, gotAlt, gotContents string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotContentType, gotAlt = r.Header.Get("Content-Type"), r.URL.Query().Get("$alt")
+		if gotContentType != geminiProtoContentType {
+			gotContents = gjson.GetBytes(body, "contents.0.parts.0.text").String()
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(geminiProtoTestResponse))
+			return
+		}
+		request, err := decodeGeminiProto("GenerateContentRequest", body)
+		if err != nil {
+			t.Errorf("decode protobuf request: %v", err)
+		}
+		gotContents = gjson.GetBytes(request, "contents.0.parts.0.text").String()
+		desc, _ := geminiProtoMessage("GenerateContentResponse")
+		msg := dynamicpb.NewMessage(desc)
+		if err = protojson.Unmarshal([]byte(geminiProtoTestResponse), msg); err != nil {
+			t.Errorf("build protobuf response: %v", err)
+		}
+		out, _ := proto.Marshal(msg)
+		w.Header().Set("Content-Type", geminiProtoContentType)
+		_, _ = w.Write(out)
+	}))
+	defer server.Close()
+
+	auth := &cliproxyauth.Auth{ID: "gemini-proto", Provider: "gemini", Attributes: map[string]string{"api_key": "key", "base_url": server.URL}}
+	payload := []byte(`{"model":"gemini-2.5-flash","temperature":0,"messages":[{"role":"user","content":"hi"}]}`)
+	execute := func(encoding string) []byte {
+		t.Helper()
+		executor := NewGeminiExecutor(&config.Config{GeminiUpstreamEncoding: encoding})
+		resp, err := executor.Execute(context.Background(), auth, cliproxyexecutor.Request{Model: "gemini-2.5-flash", Payload: payload}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("openai")})
+		if err != nil {
+			t.Fatalf("Execute(%s): %v", encoding, err)
+		}
+		if got := resp.Headers.Get("Content-Type"); got != "application/json" {
+			t.Fatalf("Execute(%s) Content-Type = %q, want application/json", encoding, got)
+		}
+		out, _ := sjson.DeleteBytes(resp.Payload, "created")
+		return out
+	}
+
+	jsonOut := execute("")
+	if gotContentType != "application/json" || gotAlt != "" {
+		t.Fatalf("default encoding sent Content-Type %q, $alt %q", gotContentType, gotAlt)
+	}
+	protoOut := execute("protobuf")
+	if gotContentType != geminiProtoContentType || gotAlt != "proto" {
+		t.Fatalf("protobuf encoding sent Content-Type %q, $alt %q", gotContentType, gotAlt)
+	}
+	if gotContents != "hi" {
+		t.Fatalf("protobuf request contents = %q, want hi", gotContents)
+	}
+	if got := gjson.GetBytes(protoOut, "choices.0.message.content").String(); got != "hello there" {
+		t.Fatalf("protobuf result content = %q, want hello there: %s", got, protoOut)
+	}
+	if string(protoOut) != string(jsonOut) {
+		t.Fatalf("protobuf result = %s\nwant the JSON result %s", protoOut, jsonOut)
+	}
+}
+
+func TestEncodeGeminiProtoRequest_FallsBackOnUnsupportedFields(t *testing.T) {
+	body := []byte(`{"contents":[{"role":"user","parts":[{"text":"hi"}]}],"generationConfig":{"temperature":0},"tools":[{"functionDeclarations":[{"name":"f"}]}]}`)
+	if _, err := encodeGeminiProtoRequest(body); err == nil {
+		t.Fatal("encodeGeminiProtoRequest accepted tools outside the protobuf subset")
+	}
+
+	body, _ = sjson.DeleteBytes(body, "tools")
+	encoded, err := encodeGeminiProtoRequest(body)
+	if err != nil {
+		t.Fatalf("encodeGeminiProtoRequest: %v", err)
+	}
+	decoded, err := decodeGeminiProto("GenerateContentRequest", encoded)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if temp := gjson.GetBytes(decoded, "generationConfig.temperature"); !temp.Exists() || temp.Float() != 0 {
+		t.Fatalf("temperature 0 lost in protobuf encoding: %s", decoded)
+	}
+}
+
+func TestDecodeGeminiProtoResponse_RoundTripsCandidateMetadata(t *testing.T) {
+	response := `{"candidates":[{"content":{"role":"model","parts":[{"text":"Paris."},` +
+		`{"executableCode":{"language":"PYTHON","code":"print(1)"}},{"codeExecutionResult":{"outcome":"OUTCOME_OK","output":"1"}}]},` +
+		`"finishReason":"STOP","index":0,` +
+		`"citationMetadata":{"citationSources":[{"startIndex":0,"endIndex":6,"uri":"https://example.com/paris","license":"mit"}]},` +
+		`"groundingMetadata":{"searchEntryPoint":{"renderedContent":"<div/>"},"groundingChunks":[{"web":{"uri":"https://example.com","title":"Example"}}],` +
+		`"groundingSupports":[{"segment":{"endIndex":6,"text":"Paris."},"groundingChunkIndices":[0],"confidenceScores":[0.5]}],"webSearchQueries":["capital of france"]},` +
+		`"avgLogprobs":-0.25,"logprobsResult":{"topCandidates":[{"candidates":[{"token":"Paris","tokenId":42,"logProbability":-0.25}]}],"chosenCandidates":[{"token":"Paris","tokenId":42,"logProbability":-0.25}]}}]}`
+	desc, err := geminiProtoMessage("GenerateContentResponse")
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	msg := dynamicpb.NewMessage(desc)
+	if err = protojson.Unmarshal([]byte(response), msg); err != nil {
+		t.Fatalf("build protobuf response: %v", err)
+	}
+	encoded, _ := proto.Marshal(msg)
+
+	decoded, err := decodeGeminiProtoResponse(encoded)
+	if err != nil {
+		t.Fatalf("decodeGeminiProtoResponse: %v", err)
+	}
+	var want, got any
+	_ = json.Unmarshal([]byte(response), &want)
+	_ = json.Unmarshal(decoded, &got)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("decoded response = %s\nwant %s", decoded, response)
+	}
+}
+
+func TestGeminiExecutor_ProtobufFallsBackToJSONOnUnknownResponseFields(t *testing.T) {
+	var contentTypes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+		if r.Header.Get("Content-Type") != geminiProtoContentType {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(geminiProtoTestResponse))
+			return
+		}
+		desc, _ := geminiProtoMessage("GenerateContentResponse")
+		msg := dynamicpb.NewMessage(desc)
+		_ = protojson.Unmarshal([]byte(geminiProtoTestResponse), msg)
+		out, _ := proto.Marshal(msg)
+		// A field the schema does not know: number 99, varint 1.
+		out = protowire.AppendVarint(protowire.AppendTag(out, 99, protowire.VarintType), 1)
+		w.Header().Set("Content-Type", geminiProtoContentType)
+		_, _ = w.Write(out)
+	}))
+	defer server.Close()
+
+	auth := &cliproxyauth.Auth{ID: "gemini-proto-unknown", Provider: "gemini", Attributes: map[string]string{"api_key": "key", "base_url": server.URL}}
+	executor := NewGeminiExecutor(&config.Config{GeminiUpstreamEncoding: "protobuf"})
+	payload := []byte(`{"model":"gemini-2.5-flash","messages":[{"role":"user","content":"hi"}]}`)
+	resp, err := executor.Execute(context.Background(), auth, cliproxyexecutor.Request{Model: "gemini-2.5-flash", Payload: payload}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("openai")})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if len(contentTypes) != 2 || contentTypes[0] != geminiProtoContentType || contentTypes[1] != "application/json" {
+		t.Fatalf("request content types = %v, want protobuf then the JSON fallback", contentTypes)
+	}
+	if got := gjson.GetBytes(resp.Payload, "choices.0.message.content").String(); got != "hello there" {
+		t.Fatalf("result content = %q, want hello there: %s", got, resp.Payload)
+	}
+}
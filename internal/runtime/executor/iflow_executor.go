@@ -51,6 +51,7 @@ func (e *IFlowExecutor) PrepareRequest(req *http.Request, auth *cliproxyauth.Aut
 	if strings.TrimSpace(apiKey) != "" {
 		req.Header.Set("Authorization", "Bearer "+apiKey)
 	}
+	applyUpstreamHeaders(req, e.cfg, e.Identifier(), auth)
 	return nil
 }
 
@@ -88,18 +89,26 @@ func (e *IFlowExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, re
 
 	reporter := newUsageReporter(ctx, e.Identifier(), baseModel, auth)
 	defer reporter.trackFailure(ctx, &err)
+	ctx = reporter.trackToolCalls(ctx)
 
 	from := opts.SourceFormat
 	to := sdktranslator.FromString("openai")
+	if err := checkTranslatorPair(e.cfg, from, to, false); err != nil {
+		return resp, err
+	}
 	originalPayloadSource := req.Payload
 	if len(opts.OriginalRequest) > 0 {
 		originalPayloadSource = opts.OriginalRequest
 	}
 	originalPayload := originalPayloadSource
 	originalTranslated := sdktranslator.TranslateRequest(from, to, baseModel, originalPayload, false)
-	body := sdktranslator.TranslateRequest(from, to, baseModel, req.Payload, false)
+	body, err := sdktranslator.TranslateRequestForProvider(e.Identifier(), from, to, baseModel, req.Payload, false)
+	if err != nil {
+		return resp, err
+	}
 	body, _ = sjson.SetBytes(body, "model", baseModel)
 
+	body = applyMaxTokensClamp(body, req.Model, "iflow", e.Identifier())
 	body, err = thinking.ApplyThinking(body, req.Model, from.String(), "iflow", e.Identifier())
 	if err != nil {
 		return resp, err
@@ -108,6 +117,7 @@ func (e *IFlowExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, re
 	body = preserveReasoningContentInMessages(body)
 	requestedModel := payloadRequestedModel(opts, req.Model)
 	body = applyPayloadConfigWithRoot(e.cfg, baseModel, to.String(), "", body, originalTranslated, requestedModel)
+	body = applyRequestIDField(ctx, e.cfg, e.Identifier(), body)
 
 	endpoint := strings.TrimSuffix(baseURL, "/") + iflowDefaultEndpoint
 
@@ -116,6 +126,7 @@ func (e *IFlowExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, re
 		return resp, err
 	}
 	applyIFlowHeaders(httpReq, apiKey, false)
+	applyUpstreamHeaders(httpReq, e.cfg, e.Identifier(), auth)
 	var authID, authLabel, authType, authValue string
 	if auth != nil {
 		authID = auth.ID
@@ -162,6 +173,7 @@ func (e *IFlowExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, re
 	}
 	appendAPIResponseChunk(ctx, e.cfg, data)
 	reporter.publish(ctx, parseOpenAIUsage(data))
+	data = normalizeIFlowReasoning(data, "message")
 	// Ensure usage is recorded even if upstream omits usage metadata.
 	reporter.ensurePublished(ctx)
 
@@ -191,18 +203,27 @@ func (e *IFlowExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Au
 
 	reporter := newUsageReporter(ctx, e.Identifier(), baseModel, auth)
 	defer reporter.trackFailure(ctx, &err)
+	ctx = reporter.trackStreamToolCalls(ctx)
+	reporter.setPromptPayload(req.Payload)
 
 	from := opts.SourceFormat
 	to := sdktranslator.FromString("openai")
+	if err := checkTranslatorPair(e.cfg, from, to, true); err != nil {
+		return nil, err
+	}
 	originalPayloadSource := req.Payload
 	if len(opts.OriginalRequest) > 0 {
 		originalPayloadSource = opts.OriginalRequest
 	}
 	originalPayload := originalPayloadSource
 	originalTranslated := sdktranslator.TranslateRequest(from, to, baseModel, originalPayload, true)
-	body := sdktranslator.TranslateRequest(from, to, baseModel, req.Payload, true)
+	body, err := sdktranslator.TranslateRequestForProvider(e.Identifier(), from, to, baseModel, req.Payload, true)
+	if err != nil {
+		return nil, err
+	}
 	body, _ = sjson.SetBytes(body, "model", baseModel)
 
+	body = applyMaxTokensClamp(body, req.Model, "iflow", e.Identifier())
 	body, err = thinking.ApplyThinking(body, req.Model, from.String(), "iflow", e.Identifier())
 	if err != nil {
 		return nil, err
@@ -216,6 +237,7 @@ func (e *IFlowExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Au
 	}
 	requestedModel := payloadRequestedModel(opts, req.Model)
 	body = applyPayloadConfigWithRoot(e.cfg, baseModel, to.String(), "", body, originalTranslated, requestedModel)
+	body = applyRequestIDField(ctx, e.cfg, e.Identifier(), body)
 
 	endpoint := strings.TrimSuffix(baseURL, "/") + iflowDefaultEndpoint
 
@@ -224,6 +246,7 @@ func (e *IFlowExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Au
 		return nil, err
 	}
 	applyIFlowHeaders(httpReq, apiKey, true)
+	applyUpstreamHeaders(httpReq, e.cfg, e.Identifier(), auth)
 	var authID, authLabel, authType, authValue string
 	if auth != nil {
 		authID = auth.ID
@@ -261,9 +284,18 @@ func (e *IFlowExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Au
 		return nil, err
 	}
 
+	recorder := newStreamRecorder(e.cfg, e.Identifier(), sdktranslator.StreamRecordingHeader{
+		SourceFormat:    from,
+		ProviderFormat:  to,
+		Model:           req.Model,
+		OriginalRequest: string(opts.OriginalRequest),
+		Request:         string(body),
+	})
 	out := make(chan cliproxyexecutor.StreamChunk)
 	go func() {
 		defer close(out)
+		defer recorder.close()
+		defer reporter.publishHeld(ctx)
 		defer func() {
 			if errClose := httpResp.Body.Close(); errClose != nil {
 				log.Errorf("iflow executor: close response body error: %v", errClose)
@@ -276,10 +308,13 @@ func (e *IFlowExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Au
 		for scanner.Scan() {
 			line := scanner.Bytes()
 			appendAPIResponseChunk(ctx, e.cfg, line)
+			reporter.observeOutput(line)
 			if detail, ok := parseOpenAIStreamUsage(line); ok {
 				reporter.publish(ctx, detail)
 			}
-			chunks := sdktranslator.TranslateStream(ctx, to, from, req.Model, opts.OriginalRequest, body, bytes.Clone(line), &param)
+			normalized := normalizeIFlowStreamLine(line)
+			recorder.record(normalized)
+			chunks := sdktranslator.TranslateStream(ctx, to, from, req.Model, opts.OriginalRequest, body, normalized, &param)
 			for i := range chunks {
 				out <- cliproxyexecutor.StreamChunk{Payload: []byte(chunks[i])}
 			}
@@ -572,3 +607,45 @@ func preserveReasoningContentInMessages(body []byte) []byte {
 
 	return body
 }
+
+// normalizeIFlowReasoning folds the reasoning_details array that MiniMax models return
+// when reasoning_split is enabled into reasoning_content, the field the OpenAI response
+// translators read. field is "message" for complete responses and "delta" for chunks.
+func normalizeIFlowReasoning(data []byte, field string) []byte {
+	choices := gjson.GetBytes(data, "choices")
+	if !choices.IsArray() {
+		return data
+	}
+	for i := range choices.Array() {
+		base := fmt.Sprintf("choices.%d.%s", i, field)
+		details := gjson.GetBytes(data, base+".reasoning_details")
+		if !details.Exists() {
+			continue
+		}
+		var reasoning strings.Builder
+		details.ForEach(func(_, detail gjson.Result) bool {
+			text := detail.Get("text")
+			if !text.Exists() {
+				text = detail.Get("summary")
+			}
+			reasoning.WriteString(text.String())
+			return true
+		})
+		if reasoning.Len() > 0 && gjson.GetBytes(data, base+".reasoning_content").String() == "" {
+			data, _ = sjson.SetBytes(data, base+".reasoning_content", reasoning.String())
+		}
+		data, _ = sjson.DeleteBytes(data, base+".reasoning_details")
+	}
+	return data
+}
+
+// normalizeIFlowStreamLine applies normalizeIFlowReasoning to one SSE line and returns
+// a copy that is safe to hand to the translators.
+func normalizeIFlowStreamLine(line []byte) []byte {
+	payload := jsonPayload(line)
+	if len(payload) == 0 || !bytes.Contains(payload, []byte("reasoning_details")) {
+		return bytes.Clone(line)
+	}
+	normalized := normalizeIFlowReasoning(bytes.Clone(payload), "delta")
+	return append([]byte("data: "), normalized...)
+}
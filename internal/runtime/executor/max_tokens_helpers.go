@@ -0,0 +1,71 @@
+package executor
+
+import (
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// maxTokensPaths returns the JSON paths carrying the requested output-token limit
+// for the given translator format.
+func maxTokensPaths(format string) []string {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "claude":
+		return []string{"max_tokens"}
+	case "openai", "iflow", "kimi":
+		return []string{"max_tokens", "max_completion_tokens"}
+	case "codex", "openai-response":
+		return []string{"max_output_tokens"}
+	case "gemini":
+		return []string{"generationConfig.maxOutputTokens"}
+	case "gemini-cli", "antigravity":
+		return []string{"request.generationConfig.maxOutputTokens"}
+	default:
+		return nil
+	}
+}
+
+// applyMaxTokensClamp lowers the requested output-token limit to the model's cap.
+//
+// It must run before thinking.ApplyThinking so that provider appliers which derive
+// thinking budgets from max_tokens (Claude, Antigravity) see the clamped value and
+// keep budget_tokens below it. Unknown models and models without a known cap are
+// passed through unchanged.
+func applyMaxTokensClamp(body []byte, model, format, providerKey string) []byte {
+	paths := maxTokensPaths(format)
+	if len(paths) == 0 || len(body) == 0 {
+		return body
+	}
+	baseModel := thinking.ParseSuffix(model).ModelName
+	modelInfo := registry.LookupModelInfo(baseModel, providerKey)
+	limit := modelInfo.MaxOutputTokens()
+	if limit <= 0 {
+		return body
+	}
+	for _, path := range paths {
+		requested := gjson.GetBytes(body, path)
+		if !requested.Exists() || requested.Type != gjson.Number {
+			continue
+		}
+		if requested.Int() <= int64(limit) {
+			continue
+		}
+		updated, errSet := sjson.SetBytes(body, path, limit)
+		if errSet != nil {
+			continue
+		}
+		log.WithFields(log.Fields{
+			"provider":   providerKey,
+			"model":      baseModel,
+			"field":      path,
+			"requested":  requested.Int(),
+			"clamped_to": limit,
+		}).Debug("max tokens clamped to model limit |")
+		body = updated
+	}
+	return body
+}
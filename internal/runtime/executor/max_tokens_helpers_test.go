@@ -0,0 +1,95 @@
+package executor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/tidwall/gjson"
+)
+
+func registerMaxTokensTestModel(t *testing.T, provider string, model *registry.ModelInfo) {
+	t.Helper()
+	reg := registry.GetGlobalRegistry()
+	clientID := fmt.Sprintf("max-tokens-test-%d", time.Now().UnixNano())
+	reg.RegisterClient(clientID, provider, []*registry.ModelInfo{model})
+	t.Cleanup(func() { reg.UnregisterClient(clientID) })
+}
+
+func TestApplyMaxTokensClamp_ClampsOverLimitRequest(t *testing.T) {
+	registerMaxTokensTestModel(t, "claude", &registry.ModelInfo{
+		ID:                  "clamp-claude-model",
+		MaxCompletionTokens: 64000,
+	})
+
+	body := []byte(`{"model":"clamp-claude-model","max_tokens":200000}`)
+	out := applyMaxTokensClamp(body, "clamp-claude-model", "claude", "claude")
+
+	if got := gjson.GetBytes(out, "max_tokens").Int(); got != 64000 {
+		t.Fatalf("max_tokens = %d, want %d", got, 64000)
+	}
+}
+
+func TestApplyMaxTokensClamp_LeavesWithinLimitRequest(t *testing.T) {
+	registerMaxTokensTestModel(t, "gemini", &registry.ModelInfo{
+		ID:               "clamp-gemini-model",
+		OutputTokenLimit: 65536,
+	})
+
+	body := []byte(`{"generationConfig":{"maxOutputTokens":1024}}`)
+	out := applyMaxTokensClamp(body, "clamp-gemini-model", "gemini", "gemini")
+
+	if got := gjson.GetBytes(out, "generationConfig.maxOutputTokens").Int(); got != 1024 {
+		t.Fatalf("maxOutputTokens = %d, want %d", got, 1024)
+	}
+}
+
+func TestApplyMaxTokensClamp_GeminiCLIEnvelope(t *testing.T) {
+	registerMaxTokensTestModel(t, "gemini-cli", &registry.ModelInfo{
+		ID:               "clamp-gemini-cli-model",
+		OutputTokenLimit: 65536,
+	})
+
+	body := []byte(`{"request":{"generationConfig":{"maxOutputTokens":100000}}}`)
+	out := applyMaxTokensClamp(body, "clamp-gemini-cli-model(high)", "gemini-cli", "gemini-cli")
+
+	if got := gjson.GetBytes(out, "request.generationConfig.maxOutputTokens").Int(); got != 65536 {
+		t.Fatalf("maxOutputTokens = %d, want %d", got, 65536)
+	}
+}
+
+func TestApplyMaxTokensClamp_ComposesWithClaudeThinking(t *testing.T) {
+	registerMaxTokensTestModel(t, "claude", &registry.ModelInfo{
+		ID:                  "clamp-claude-thinking-model",
+		MaxCompletionTokens: 32000,
+		Thinking:            &registry.ThinkingSupport{Min: 1024, Max: 128000, ZeroAllowed: true},
+	})
+
+	model := "clamp-claude-thinking-model(100000)"
+	body := []byte(`{"model":"clamp-claude-thinking-model","max_tokens":200000}`)
+	body = applyMaxTokensClamp(body, model, "claude", "claude")
+	body, err := thinking.ApplyThinking(body, model, "claude", "claude", "claude")
+	if err != nil {
+		t.Fatalf("ApplyThinking error: %v", err)
+	}
+
+	maxTokens := gjson.GetBytes(body, "max_tokens").Int()
+	budget := gjson.GetBytes(body, "thinking.budget_tokens").Int()
+	if maxTokens != 32000 {
+		t.Fatalf("max_tokens = %d, want %d", maxTokens, 32000)
+	}
+	if budget >= maxTokens {
+		t.Fatalf("budget_tokens = %d, want < max_tokens (%d)", budget, maxTokens)
+	}
+}
+
+func TestApplyMaxTokensClamp_UnknownModelPassthrough(t *testing.T) {
+	body := []byte(`{"max_tokens":999999}`)
+	out := applyMaxTokensClamp(body, "clamp-unknown-model", "openai", "openai")
+
+	if got := gjson.GetBytes(out, "max_tokens").Int(); got != 999999 {
+		t.Fatalf("max_tokens = %d, want %d", got, 999999)
+	}
+}
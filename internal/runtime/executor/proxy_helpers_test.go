@@ -0,0 +1,38 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func proxyHostFor(t *testing.T, client *http.Client) string {
+	t.Helper()
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok || transport.Proxy == nil {
+		t.Fatalf("expected proxy transport, got %T", client.Transport)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	proxyURL, err := transport.Proxy(req)
+	if err != nil || proxyURL == nil {
+		t.Fatalf("resolve proxy: %v", err)
+	}
+	return proxyURL.Host
+}
+
+func TestNewProxyAwareHTTPClient_GlobalProxyPrecedence(t *testing.T) {
+	cfg := &config.Config{SDKConfig: config.SDKConfig{ProxyURL: "http://global.example:3128"}}
+
+	client := newProxyAwareHTTPClient(context.Background(), cfg, &cliproxyauth.Auth{ID: "no-proxy"}, 0)
+	if got := proxyHostFor(t, client); got != "global.example:3128" {
+		t.Fatalf("proxy without auth override = %q, want global.example:3128", got)
+	}
+
+	client = newProxyAwareHTTPClient(context.Background(), cfg, &cliproxyauth.Auth{ID: "own-proxy", ProxyURL: "http://auth.example:8080"}, 0)
+	if got := proxyHostFor(t, client); got != "auth.example:8080" {
+		t.Fatalf("proxy with auth override = %q, want auth.example:8080", got)
+	}
+}
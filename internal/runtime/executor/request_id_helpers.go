@@ -0,0 +1,33 @@
+package executor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/tidwall/sjson"
+)
+
+// applyRequestIDField writes the request correlation ID into the outgoing body at the
+// JSON path configured for the provider under upstream.inject-request-id-field.
+// The body is returned unchanged when no field is configured or no request ID is known.
+func applyRequestIDField(ctx context.Context, cfg *config.Config, provider string, body []byte) []byte {
+	if cfg == nil || len(cfg.Upstream.InjectRequestIDField) == 0 || len(body) == 0 || ctx == nil {
+		return body
+	}
+	path := strings.TrimSpace(cfg.Upstream.InjectRequestIDField[strings.ToLower(strings.TrimSpace(provider))])
+	if path == "" {
+		return body
+	}
+	requestID := logging.GetRequestID(ctx)
+	if requestID == "" {
+		return body
+	}
+	updated, errSet := sjson.SetBytes(body, path, requestID)
+	if errSet != nil {
+		logWithRequestID(ctx).Debugf("failed to inject request id into %s: %v", path, errSet)
+		return body
+	}
+	return updated
+}
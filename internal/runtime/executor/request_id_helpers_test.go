@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func TestOpenAICompatExecutorInjectsRequestIDField(t *testing.T) {
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Upstream: config.UpstreamConfig{
+		InjectRequestIDField: map[string]string{"openai-compatibility": "metadata.request_id"},
+	}}
+	executor := NewOpenAICompatExecutor("openai-compatibility", cfg)
+	auth := &cliproxyauth.Auth{Attributes: map[string]string{
+		"base_url": server.URL + "/v1",
+		"api_key":  "test",
+	}}
+	ctx := logging.WithRequestID(context.Background(), "abcd1234")
+	_, err := executor.Execute(ctx, auth, cliproxyexecutor.Request{
+		Model:   "gpt-test",
+		Payload: []byte(`{"model":"gpt-test","messages":[{"role":"user","content":"hi"}]}`),
+	}, cliproxyexecutor.Options{
+		SourceFormat: sdktranslator.FromString("openai"),
+	})
+	if err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	if got := gjson.GetBytes(gotBody, "metadata.request_id").String(); got != "abcd1234" {
+		t.Fatalf("metadata.request_id = %q, want %q, body=%s", got, "abcd1234", string(gotBody))
+	}
+}
+
+func TestApplyRequestIDField_SkipsUnconfiguredProvider(t *testing.T) {
+	cfg := &config.Config{Upstream: config.UpstreamConfig{
+		InjectRequestIDField: map[string]string{"claude": "metadata.request_id"},
+	}}
+	ctx := logging.WithRequestID(context.Background(), "abcd1234")
+	body := []byte(`{"model":"gpt-test"}`)
+
+	if out := applyRequestIDField(ctx, cfg, "codex", body); string(out) != string(body) {
+		t.Fatalf("expected body unchanged for unconfigured provider, got %s", string(out))
+	}
+	if out := applyRequestIDField(context.Background(), cfg, "claude", body); string(out) != string(body) {
+		t.Fatalf("expected body unchanged without request id, got %s", string(out))
+	}
+}
@@ -0,0 +1,89 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/openai/claude"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+// newDyingToolCallServer streams the start of a tool call and drops the connection
+// halfway through its arguments.
+func newDyingToolCallServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"id":"chatcmpl-1","model":"gpt-test","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"read_file","arguments":""}}]}}]}` + "\n\n"))
+		_, _ = w.Write([]byte(`data: {"id":"chatcmpl-1","model":"gpt-test","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"path\":\"/tmp/fi"}}]}}]}` + "\n\n"))
+		w.(http.Flusher).Flush()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			_ = conn.Close()
+		}
+	}))
+}
+
+func runDyingToolCallStream(t *testing.T, action string) (string, error) {
+	t.Helper()
+	previous := util.ToolArgsOnTruncation()
+	util.SetToolArgsOnTruncation(action)
+	t.Cleanup(func() { util.SetToolArgsOnTruncation(previous) })
+
+	server := newDyingToolCallServer(t)
+	defer server.Close()
+
+	exec := NewOpenAICompatExecutor("openai-compatibility", &config.Config{})
+	auth := &cliproxyauth.Auth{Attributes: map[string]string{"base_url": server.URL + "/v1", "api_key": "test"}}
+	result, err := exec.ExecuteStream(context.Background(), auth, cliproxyexecutor.Request{
+		Model:   "gpt-test",
+		Payload: []byte(`{"model":"gpt-test","stream":true,"max_tokens":64,"messages":[{"role":"user","content":"hi"}]}`),
+	}, cliproxyexecutor.Options{
+		SourceFormat:    sdktranslator.FromString("claude"),
+		OriginalRequest: []byte(`{"model":"gpt-test","stream":true}`),
+		Stream:          true,
+	})
+	if err != nil {
+		t.Fatalf("ExecuteStream error: %v", err)
+	}
+	var events strings.Builder
+	var streamErr error
+	for chunk := range result.Chunks {
+		if chunk.Err != nil {
+			streamErr = chunk.Err
+			continue
+		}
+		events.Write(chunk.Payload)
+	}
+	return events.String(), streamErr
+}
+
+func TestOpenAICompatExecuteStream_InterruptedToolArgsClose(t *testing.T) {
+	events, streamErr := runDyingToolCallStream(t, "close")
+	if streamErr == nil {
+		t.Fatal("expected the dropped connection to surface as a stream error")
+	}
+	if !strings.Contains(events, `"partial_json":"{\"path\":\"/tmp/fi\"}"`) {
+		t.Fatalf("expected closed tool arguments, got %q", events)
+	}
+	if strings.Contains(events, "event: error") || strings.Contains(events, "message_stop") {
+		t.Fatalf("expected no error or terminal event in close mode, got %q", events)
+	}
+}
+
+func TestOpenAICompatExecuteStream_InterruptedToolArgsError(t *testing.T) {
+	events, streamErr := runDyingToolCallStream(t, "error")
+	if streamErr == nil {
+		t.Fatal("expected the dropped connection to surface as a stream error")
+	}
+	if strings.Contains(events, "input_json_delta") || strings.Contains(events, "message_stop") {
+		t.Fatalf("expected the stream error alone, without partial arguments or a terminal event, got %q", events)
+	}
+}
@@ -0,0 +1,99 @@
+package executor
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	log "github.com/sirupsen/logrus"
+)
+
+// streamRecorder writes every payload handed to the stream translator into a recording,
+// so a replay sees exactly what the live translation consumed: usage filtering, "data:"
+// stripping and synthetic tails such as [DONE] included.
+// A nil recorder is valid and records nothing.
+type streamRecorder struct {
+	file       *os.File
+	headerSize int64
+}
+
+// newStreamRecorder starts a recording under cfg.StreamRecordDir when debug mode is
+// enabled, and returns nil otherwise. Recording failures are logged and never interrupt
+// the live stream. Recordings can be replayed offline with sdktranslator.ReplayStreamFile.
+func newStreamRecorder(cfg *config.Config, provider string, header sdktranslator.StreamRecordingHeader) *streamRecorder {
+	if cfg == nil || !cfg.Debug {
+		return nil
+	}
+	dir := strings.TrimSpace(cfg.StreamRecordDir)
+	if dir == "" {
+		return nil
+	}
+	if errMkdir := os.MkdirAll(dir, 0o700); errMkdir != nil {
+		log.Warnf("stream recorder: create %s failed: %v", dir, errMkdir)
+		return nil
+	}
+	file, errCreate := os.CreateTemp(dir, provider+"-*.stream")
+	if errCreate != nil {
+		log.Warnf("stream recorder: create recording failed: %v", errCreate)
+		return nil
+	}
+	if errHeader := sdktranslator.WriteStreamRecordingHeader(file, header); errHeader != nil {
+		log.Warnf("stream recorder: write header failed: %v", errHeader)
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return nil
+	}
+	headerSize, errSeek := file.Seek(0, io.SeekCurrent)
+	if errSeek != nil {
+		log.Warnf("stream recorder: locate header end failed: %v", errSeek)
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return nil
+	}
+	log.Debugf("stream recorder: recording %s stream to %s", provider, file.Name())
+	return &streamRecorder{file: file, headerSize: headerSize}
+}
+
+// record appends one translator input to the recording.
+func (r *streamRecorder) record(payload []byte) {
+	if r == nil || r.file == nil {
+		return
+	}
+	if errWrite := sdktranslator.WriteStreamRecordingPayload(r.file, payload); errWrite != nil {
+		r.fail("write", errWrite)
+	}
+}
+
+// reset discards everything recorded after the header, for streams that restart from
+// scratch with fresh translator state.
+func (r *streamRecorder) reset() {
+	if r == nil || r.file == nil {
+		return
+	}
+	if errTruncate := r.file.Truncate(r.headerSize); errTruncate != nil {
+		r.fail("truncate", errTruncate)
+		return
+	}
+	if _, errSeek := r.file.Seek(r.headerSize, io.SeekStart); errSeek != nil {
+		r.fail("seek", errSeek)
+	}
+}
+
+// close finishes the recording.
+func (r *streamRecorder) close() {
+	if r == nil || r.file == nil {
+		return
+	}
+	if errClose := r.file.Close(); errClose != nil {
+		log.Warnf("stream recorder: close %s failed: %v", r.file.Name(), errClose)
+	}
+	r.file = nil
+}
+
+func (r *streamRecorder) fail(op string, err error) {
+	log.Warnf("stream recorder: %s %s failed: %v", op, r.file.Name(), err)
+	_ = r.file.Close()
+	r.file = nil
+}
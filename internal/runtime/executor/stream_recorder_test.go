@@ -0,0 +1,140 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func init() {
+	// Stateful translator so replay must thread params and request context like the executor.
+	sdktranslator.Register("test-record-client", "openai", nil, sdktranslator.ResponseTransform{
+		Stream: func(_ context.Context, model string, _, requestRawJSON, rawJSON []byte, param *any) []string {
+			n, _ := (*param).(int)
+			n++
+			*param = n
+			return []string{fmt.Sprintf("%d|%s|%s|%s", n, model, gjson.GetBytes(requestRawJSON, "model").String(), rawJSON)}
+		},
+	})
+}
+
+func TestRecordStream_ReplayReproducesTranslatedOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\n"))
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"lo\"}}]}\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	recordDir := filepath.Join(t.TempDir(), "recordings")
+	cfg := &config.Config{Debug: true, StreamRecordDir: recordDir}
+	exec := NewOpenAICompatExecutor("openai-compatibility", cfg)
+	auth := &cliproxyauth.Auth{Attributes: map[string]string{"base_url": server.URL + "/v1", "api_key": "test"}}
+
+	result, err := exec.ExecuteStream(context.Background(), auth, cliproxyexecutor.Request{
+		Model:   "record-model",
+		Payload: []byte(`{"model":"record-model","messages":[{"role":"user","content":"hi"}]}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("test-record-client"), Stream: true})
+	if err != nil {
+		t.Fatalf("ExecuteStream error: %v", err)
+	}
+	var live []string
+	for chunk := range result.Chunks {
+		if chunk.Err != nil {
+			t.Fatalf("stream error: %v", chunk.Err)
+		}
+		live = append(live, string(chunk.Payload))
+	}
+	if len(live) != 3 {
+		t.Fatalf("live chunks = %d, want 3: %v", len(live), live)
+	}
+
+	recordings, _ := filepath.Glob(filepath.Join(recordDir, "openai-compatibility-*.stream"))
+	if len(recordings) != 1 {
+		t.Fatalf("recordings = %v, want exactly one", recordings)
+	}
+	replayed, err := sdktranslator.ReplayStreamFile(context.Background(), recordings[0])
+	if err != nil {
+		t.Fatalf("ReplayStreamFile error: %v", err)
+	}
+	if !reflect.DeepEqual(replayed, live) {
+		t.Fatalf("replayed output differs:\n live: %q\n replay: %q", live, replayed)
+	}
+}
+
+func TestRecordStream_GeminiReplayMatchesServedStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		// The first chunk carries interim usage that the executor filters before translation.
+		_, _ = w.Write([]byte(`data: {"candidates":[{"content":{"role":"model","parts":[{"text":"Hel"}]}}],"usageMetadata":{"promptTokenCount":3,"totalTokenCount":3},"responseId":"r1","createTime":"2025-01-01T00:00:00Z","modelVersion":"gemini-2.5-flash"}` + "\n\n"))
+		_, _ = w.Write([]byte(`data: {"candidates":[{"content":{"role":"model","parts":[{"text":"lo"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":2,"totalTokenCount":5},"responseId":"r1","createTime":"2025-01-01T00:00:00Z","modelVersion":"gemini-2.5-flash"}` + "\n\n"))
+	}))
+	defer server.Close()
+
+	recordDir := filepath.Join(t.TempDir(), "recordings")
+	exec := NewGeminiExecutor(&config.Config{Debug: true, StreamRecordDir: recordDir})
+	auth := &cliproxyauth.Auth{ID: "gemini-record", Provider: "gemini", Attributes: map[string]string{"api_key": "key", "base_url": server.URL}}
+
+	result, err := exec.ExecuteStream(context.Background(), auth, cliproxyexecutor.Request{
+		Model:   "gemini-2.5-flash",
+		Payload: []byte(`{"model":"gemini-2.5-flash","stream":true,"messages":[{"role":"user","content":"hi"}]}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("openai"), Stream: true})
+	if err != nil {
+		t.Fatalf("ExecuteStream error: %v", err)
+	}
+	var live []string
+	for chunk := range result.Chunks {
+		if chunk.Err != nil {
+			t.Fatalf("stream error: %v", chunk.Err)
+		}
+		live = append(live, string(chunk.Payload))
+	}
+	if len(live) == 0 {
+		t.Fatal("expected translated chunks")
+	}
+
+	recordings, _ := filepath.Glob(filepath.Join(recordDir, "gemini-*.stream"))
+	if len(recordings) != 1 {
+		t.Fatalf("recordings = %v, want exactly one", recordings)
+	}
+	raw, err := os.ReadFile(recordings[0])
+	if err != nil {
+		t.Fatalf("read recording: %v", err)
+	}
+	if strings.Contains(string(raw), "data:") || !strings.Contains(string(raw), `"[DONE]"`) {
+		t.Fatalf("recording should hold translator inputs, got:\n%s", raw)
+	}
+	replayed, err := sdktranslator.ReplayStreamFile(context.Background(), recordings[0])
+	if err != nil {
+		t.Fatalf("ReplayStreamFile error: %v", err)
+	}
+	if !reflect.DeepEqual(replayed, live) {
+		t.Fatalf("replayed output differs:\n live: %q\n replay: %q", live, replayed)
+	}
+}
+
+func TestRecordStream_DisabledWithoutDebug(t *testing.T) {
+	recordDir := filepath.Join(t.TempDir(), "recordings")
+	recorder := newStreamRecorder(&config.Config{StreamRecordDir: recordDir}, "codex", sdktranslator.StreamRecordingHeader{})
+	if recorder != nil {
+		t.Fatal("expected no recorder when debug is off")
+	}
+	recorder.record([]byte("ignored"))
+	recorder.close()
+	if _, err := os.Stat(recordDir); !os.IsNotExist(err) {
+		t.Fatalf("record dir should not be created, stat err = %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package executor
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+// checkTranslatorPair reports a 501 error when missing-translator-action is "reject" and no
+// translator is registered between the client format (from) and the provider format (to).
+// Identical formats never need a translator.
+func checkTranslatorPair(cfg *config.Config, from, to sdktranslator.Format, stream bool) error {
+	if cfg == nil || !strings.EqualFold(strings.TrimSpace(cfg.MissingTranslatorAction), "reject") {
+		return nil
+	}
+	if from == "" || from == to {
+		return nil
+	}
+	if stream {
+		if sdktranslator.HasStreamTransformerByFormatName(from, to) {
+			return nil
+		}
+	} else if sdktranslator.HasResponseTransformerByFormatName(from, to) {
+		return nil
+	}
+
+	targets := sdktranslator.ResponseTargets(from)
+	available := make([]string, 0, len(targets))
+	for _, target := range targets {
+		available = append(available, target.String())
+	}
+	mode := "non-stream"
+	if stream {
+		mode = "stream"
+	}
+	msg := fmt.Sprintf("no %s translator registered for %s -> %s", mode, from, to)
+	if len(available) > 0 {
+		msg += "; available targets: " + strings.Join(available, ", ")
+	} else {
+		msg += "; no targets are registered for " + from.String()
+	}
+	return statusErr{code: http.StatusNotImplemented, msg: msg}
+}
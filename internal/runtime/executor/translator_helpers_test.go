@@ -0,0 +1,60 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+func init() {
+	sdktranslator.Register("test-missing-src", "test-known-target", nil, sdktranslator.ResponseTransform{})
+}
+
+func TestCheckTranslatorPair(t *testing.T) {
+	reject := &config.Config{SDKConfig: config.SDKConfig{MissingTranslatorAction: "reject"}}
+
+	if err := checkTranslatorPair(&config.Config{}, "test-missing-src", "openai", false); err != nil {
+		t.Fatalf("passthrough default returned error: %v", err)
+	}
+	if err := checkTranslatorPair(reject, "openai", "openai", true); err != nil {
+		t.Fatalf("identical formats returned error: %v", err)
+	}
+	if err := checkTranslatorPair(reject, "test-missing-src", "test-known-target", false); err != nil {
+		t.Fatalf("registered pair returned error: %v", err)
+	}
+
+	err := checkTranslatorPair(reject, "test-missing-src", "openai", false)
+	var se statusErr
+	if !errors.As(err, &se) || se.StatusCode() != http.StatusNotImplemented {
+		t.Fatalf("expected 501 statusErr, got %v", err)
+	}
+	if !strings.Contains(se.Error(), "test-missing-src -> openai") || !strings.Contains(se.Error(), "test-known-target") {
+		t.Fatalf("missing diagnostic detail: %q", se.Error())
+	}
+}
+
+func TestOpenAICompatExecutorRejectsUnsupportedPair(t *testing.T) {
+	cfg := &config.Config{SDKConfig: config.SDKConfig{MissingTranslatorAction: "reject"}}
+	exec := NewOpenAICompatExecutor("test-compat", cfg)
+	auth := &cliproxyauth.Auth{Attributes: map[string]string{"base_url": "http://127.0.0.1:1", "api_key": "k"}}
+
+	_, err := exec.Execute(context.Background(), auth, cliproxyexecutor.Request{
+		Model:   "some-model",
+		Payload: []byte(`{"model":"some-model"}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("test-missing-src")})
+
+	var se statusErr
+	if !errors.As(err, &se) || se.StatusCode() != http.StatusNotImplemented {
+		t.Fatalf("expected 501 statusErr, got %v", err)
+	}
+	if !strings.Contains(se.Error(), "available targets: test-known-target") {
+		t.Fatalf("missing available targets: %q", se.Error())
+	}
+}
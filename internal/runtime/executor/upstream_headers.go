@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// mergedListHeaders are comma-separated feature lists. Configured values are appended
+// to what the executor already set instead of replacing it, so required betas survive.
+var mergedListHeaders = map[string]bool{
+	"Anthropic-Beta": true,
+}
+
+// applyUpstreamHeaders adds the configured provider-headers for provider, then the
+// credential's own headers: "header:" attributes from its config entry followed by the
+// "headers" object in its metadata. Later sources override earlier ones.
+func applyUpstreamHeaders(r *http.Request, cfg *config.Config, provider string, auth *cliproxyauth.Auth) {
+	if r == nil {
+		return
+	}
+	if r.Header == nil {
+		r.Header = make(http.Header)
+	}
+	if cfg != nil && len(cfg.ProviderHeaders) > 0 {
+		for key, headers := range cfg.ProviderHeaders {
+			if strings.EqualFold(strings.TrimSpace(key), provider) {
+				setUpstreamHeaders(r.Header, headers)
+			}
+		}
+	}
+	if auth == nil {
+		return
+	}
+	util.ApplyCustomHeadersFromAttrs(r, auth.Attributes)
+	setUpstreamHeaders(r.Header, metadataHeaders(auth.Metadata))
+}
+
+func setUpstreamHeaders(dst http.Header, headers map[string]string) {
+	for name, value := range headers {
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+		if name == "" || value == "" {
+			continue
+		}
+		if mergedListHeaders[http.CanonicalHeaderKey(name)] {
+			value = mergeHeaderList(dst.Get(name), value)
+		}
+		dst.Set(name, value)
+	}
+}
+
+// mergeHeaderList appends the items of extra missing from the comma-separated list existing.
+func mergeHeaderList(existing, extra string) string {
+	if strings.TrimSpace(existing) == "" {
+		return extra
+	}
+	seen := make(map[string]bool)
+	items := make([]string, 0)
+	for _, list := range []string{existing, extra} {
+		for _, item := range strings.Split(list, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" || seen[item] {
+				continue
+			}
+			seen[item] = true
+			items = append(items, item)
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// metadataHeaders reads the optional "headers" object stored in an auth file.
+func metadataHeaders(metadata map[string]any) map[string]string {
+	raw, ok := metadata["headers"].(map[string]any)
+	if !ok || len(raw) == 0 {
+		return nil
+	}
+	headers := make(map[string]string, len(raw))
+	for name, value := range raw {
+		switch v := value.(type) {
+		case string:
+			headers[name] = v
+		case nil:
+		default:
+			headers[name] = fmt.Sprint(v)
+		}
+	}
+	return headers
+}
@@ -0,0 +1,85 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+func TestClaudeExecutorSendsConfiguredProviderHeaders(t *testing.T) {
+	var gotHeaders http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeaders = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","model":"claude-test","content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{ProviderHeaders: map[string]map[string]string{
+		"claude": {
+			"anthropic-beta": "context-1m-2025-08-07",
+			"X-Org-Id":       "org-config",
+		},
+	}}
+	executor := NewClaudeExecutor(cfg)
+	auth := &cliproxyauth.Auth{
+		Attributes: map[string]string{
+			"base_url": server.URL,
+			"api_key":  "test",
+		},
+		Metadata: map[string]any{
+			"headers": map[string]any{"X-Org-Id": "org-auth"},
+		},
+	}
+	_, err := executor.Execute(context.Background(), auth, cliproxyexecutor.Request{
+		Model:   "claude-test",
+		Payload: []byte(`{"model":"claude-test","max_tokens":16,"messages":[{"role":"user","content":"hi"}]}`),
+	}, cliproxyexecutor.Options{
+		SourceFormat: sdktranslator.FromString("claude"),
+	})
+	if err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+
+	betas := gotHeaders.Get("Anthropic-Beta")
+	if !strings.Contains(betas, "context-1m-2025-08-07") {
+		t.Fatalf("Anthropic-Beta = %q, want configured beta", betas)
+	}
+	if !strings.Contains(betas, "prompt-caching-2024-07-31") {
+		t.Fatalf("Anthropic-Beta = %q, built-in betas were dropped", betas)
+	}
+	if got := gotHeaders.Get("X-Org-Id"); got != "org-auth" {
+		t.Fatalf("X-Org-Id = %q, want auth metadata to override config", got)
+	}
+}
+
+func TestApplyUpstreamHeadersPrecedence(t *testing.T) {
+	cfg := &config.Config{ProviderHeaders: map[string]map[string]string{
+		"Codex": {"X-Team": "config", "X-Only-Config": "1"},
+		"qwen":  {"X-Other": "1"},
+	}}
+	auth := &cliproxyauth.Auth{
+		Attributes: map[string]string{"header:X-Team": "attrs"},
+		Metadata:   map[string]any{"headers": map[string]any{"X-Team": "metadata"}},
+	}
+	req, _ := http.NewRequest(http.MethodPost, "https://example.com", nil)
+
+	applyUpstreamHeaders(req, cfg, "codex", auth)
+
+	if got := req.Header.Get("X-Team"); got != "metadata" {
+		t.Fatalf("X-Team = %q, want metadata", got)
+	}
+	if got := req.Header.Get("X-Only-Config"); got != "1" {
+		t.Fatalf("X-Only-Config = %q, want 1", got)
+	}
+	if req.Header.Get("X-Other") != "" {
+		t.Fatalf("headers of another provider were applied")
+	}
+}
@@ -0,0 +1,129 @@
+package executor
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultUpstreamRetryBackoff    = 250 * time.Millisecond
+	defaultUpstreamRetryMaxBackoff = 2 * time.Second
+	// upstreamRetryBodyLimit bounds how much of a retryable error response is buffered.
+	upstreamRetryBodyLimit = 64 << 10
+)
+
+var defaultUpstreamRetryStatuses = []int{
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// upstreamRetryTransport re-sends a request on the same credential when the upstream
+// answers with a transient 5xx status, waiting with exponential backoff between attempts.
+// Responses that are not retried, including the last attempt's, are returned unchanged.
+type upstreamRetryTransport struct {
+	base       http.RoundTripper
+	attempts   int
+	statuses   map[int]struct{}
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+// wrapUpstreamRetry wraps base with the configured 5xx retry, or returns base unchanged
+// when the retry is disabled.
+func wrapUpstreamRetry(cfg *config.Config, base http.RoundTripper) http.RoundTripper {
+	if cfg == nil || cfg.UpstreamRetry.Attempts <= 1 {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	retry := cfg.UpstreamRetry
+	codes := retry.StatusCodes
+	if len(codes) == 0 {
+		codes = defaultUpstreamRetryStatuses
+	}
+	statuses := make(map[int]struct{}, len(codes))
+	for _, code := range codes {
+		statuses[code] = struct{}{}
+	}
+	backoff := defaultUpstreamRetryBackoff
+	if retry.BackoffMs > 0 {
+		backoff = time.Duration(retry.BackoffMs) * time.Millisecond
+	}
+	maxBackoff := defaultUpstreamRetryMaxBackoff
+	if retry.MaxBackoffMs > 0 {
+		maxBackoff = time.Duration(retry.MaxBackoffMs) * time.Millisecond
+	}
+	return &upstreamRetryTransport{
+		base:       base,
+		attempts:   retry.Attempts,
+		statuses:   statuses,
+		backoff:    backoff,
+		maxBackoff: maxBackoff,
+	}
+}
+
+func (t *upstreamRetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	delay := min(t.backoff, t.maxBackoff)
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 {
+			attemptReq = req.Clone(req.Context())
+			if req.Body != nil && req.Body != http.NoBody {
+				body, errBody := req.GetBody()
+				if errBody != nil {
+					return nil, errBody
+				}
+				attemptReq.Body = body
+			}
+		}
+		resp, err := t.base.RoundTrip(attemptReq)
+		if err != nil || attempt >= t.attempts || !t.retryable(req, resp) {
+			return resp, err
+		}
+
+		body, errRead := io.ReadAll(io.LimitReader(resp.Body, upstreamRetryBodyLimit))
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if errRead != nil || antigravityShouldRetryNoCapacity(resp.StatusCode, body) {
+			// "No capacity" has its own retry policy in the Antigravity executor.
+			return resp, nil
+		}
+
+		log.WithFields(log.Fields{
+			"url":     req.URL.Redacted(),
+			"status":  resp.StatusCode,
+			"attempt": attempt,
+			"delay":   delay,
+		}).Warn("upstream returned a transient error, retrying")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return resp, nil
+		case <-timer.C:
+		}
+		if delay *= 2; delay > t.maxBackoff {
+			delay = t.maxBackoff
+		}
+	}
+}
+
+// retryable reports whether resp has a retryable status and req can be sent again.
+func (t *upstreamRetryTransport) retryable(req *http.Request, resp *http.Response) bool {
+	if resp == nil {
+		return false
+	}
+	if _, ok := t.statuses[resp.StatusCode]; !ok {
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
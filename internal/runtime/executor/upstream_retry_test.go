@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+// statusSequenceServer answers with the given statuses in order, then 200, and records
+// how many requests arrived with the expected body.
+func statusSequenceServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		if body, _ := io.ReadAll(r.Body); string(body) != `{"prompt":"hi"}` {
+			t.Errorf("attempt %d body = %q", n, body)
+		}
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			_, _ = w.Write([]byte(`{"error":"upstream"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func postJSON(t *testing.T, client *http.Client, url string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, strings.NewReader(`{"prompt":"hi"}`))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("do request: %v", err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func TestUpstreamRetry_RetriesTransient5xx(t *testing.T) {
+	server, calls := statusSequenceServer(t, http.StatusServiceUnavailable)
+	cfg := &config.Config{UpstreamRetry: config.UpstreamRetryConfig{Attempts: 3, BackoffMs: 1}}
+
+	resp := postJSON(t, newProxyAwareHTTPClient(context.Background(), cfg, nil, 0), server.URL)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200 after a retry", resp.StatusCode)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("upstream calls = %d, want 2", got)
+	}
+}
+
+func TestUpstreamRetry_SingleAttemptFailsImmediately(t *testing.T) {
+	server, calls := statusSequenceServer(t, http.StatusInternalServerError)
+	cfg := &config.Config{UpstreamRetry: config.UpstreamRetryConfig{Attempts: 1, BackoffMs: 1}}
+
+	resp := postJSON(t, newProxyAwareHTTPClient(context.Background(), cfg, nil, 0), server.URL)
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", resp.StatusCode)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("upstream calls = %d, want 1", got)
+	}
+}
+
+func TestUpstreamRetry_LeavesNoCapacityToAntigravity(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":{"message":"No capacity available for model"}}`))
+	}))
+	t.Cleanup(server.Close)
+	cfg := &config.Config{UpstreamRetry: config.UpstreamRetryConfig{Attempts: 3, BackoffMs: 1}}
+
+	resp := postJSON(t, newProxyAwareHTTPClient(context.Background(), cfg, nil, 0), server.URL)
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusServiceUnavailable || !strings.Contains(string(body), "No capacity") {
+		t.Fatalf("status = %d body = %s, want the original no-capacity response", resp.StatusCode, body)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("upstream calls = %d, want 1", got)
+	}
+}
@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tokenize"
 	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -24,6 +27,21 @@ type usageReporter struct {
 	source      string
 	requestedAt time.Time
 	once        sync.Once
+	// toolCalls counts the tool calls the response translators emit for this request.
+	toolCalls sdktranslator.ToolCallCounter
+	// held defers publication until the response is complete, so the tool calls translated
+	// after the upstream's usage chunk are part of the record; stream marks responses that
+	// complete in ensurePublished rather than when the executor returns.
+	held   bool
+	stream bool
+	// ensure records an ensurePublished call deferred to trackFailure.
+	ensure  atomic.Bool
+	mu      sync.Mutex
+	pending *usage.Detail
+	// promptPayload and outputTokens feed the estimate published when a stream ends
+	// without usage.
+	promptPayload []byte
+	outputTokens  atomic.Int64
 }
 
 func newUsageReporter(ctx context.Context, provider, model string, auth *cliproxyauth.Auth) *usageReporter {
@@ -42,6 +60,26 @@ func newUsageReporter(ctx context.Context, provider, model string, auth *cliprox
 	return reporter
 }
 
+// trackToolCalls returns ctx carrying the reporter's tool-call counter for the response
+// translators, and holds usage back until trackFailure runs when the executor returns.
+func (r *usageReporter) trackToolCalls(ctx context.Context) context.Context {
+	if r == nil {
+		return ctx
+	}
+	r.held = true
+	return sdktranslator.WithToolCallCounter(ctx, &r.toolCalls)
+}
+
+// trackStreamToolCalls is trackToolCalls for a streamed response, whose usage is held
+// until ensurePublished or publishHeld runs when the stream goroutine ends.
+func (r *usageReporter) trackStreamToolCalls(ctx context.Context) context.Context {
+	if r == nil {
+		return ctx
+	}
+	r.stream = true
+	return r.trackToolCalls(ctx)
+}
+
 func (r *usageReporter) publish(ctx context.Context, detail usage.Detail) {
 	r.publishWithOutcome(ctx, detail, false)
 }
@@ -50,15 +88,60 @@ func (r *usageReporter) publishFailure(ctx context.Context) {
 	r.publishWithOutcome(ctx, usage.Detail{}, true)
 }
 
+// trackFailure publishes a failure record when the request failed. Otherwise it publishes
+// the usage held back for a non-streamed response, which is complete once the executor
+// returns.
 func (r *usageReporter) trackFailure(ctx context.Context, errPtr *error) {
 	if r == nil || errPtr == nil {
 		return
 	}
 	if *errPtr != nil {
 		r.publishFailure(ctx)
+		return
+	}
+	if !r.held || r.stream {
+		return
+	}
+	if r.ensure.Load() {
+		r.publishFinal(ctx)
+	} else {
+		r.publishHeld(ctx)
+	}
+}
+
+// setPromptPayload records the request used to estimate prompt tokens when the upstream
+// reports no usage.
+func (r *usageReporter) setPromptPayload(payload []byte) {
+	if r == nil {
+		return
+	}
+	r.promptPayload = payload
+}
+
+// observeOutput accumulates the estimated output tokens carried by one streamed upstream
+// event.
+func (r *usageReporter) observeOutput(line []byte) {
+	if r == nil {
+		return
+	}
+	if n := estimateStreamOutputTokens(jsonPayload(line)); n > 0 {
+		r.outputTokens.Add(n)
 	}
 }
 
+// restartStream discards what was accumulated for a stream that is being re-sent from the
+// start, so the restarted response is not counted on top of it.
+func (r *usageReporter) restartStream() {
+	if r == nil {
+		return
+	}
+	r.toolCalls.Reset()
+	r.outputTokens.Store(0)
+	r.mu.Lock()
+	r.pending = nil
+	r.mu.Unlock()
+}
+
 func (r *usageReporter) publishWithOutcome(ctx context.Context, detail usage.Detail, failed bool) {
 	if r == nil {
 		return
@@ -72,6 +155,42 @@ func (r *usageReporter) publishWithOutcome(ctx context.Context, detail usage.Det
 	if detail.InputTokens == 0 && detail.OutputTokens == 0 && detail.ReasoningTokens == 0 && detail.CachedTokens == 0 && detail.TotalTokens == 0 && !failed {
 		return
 	}
+	if r.held {
+		// The first authoritative usage wins, as it would if published right away; a
+		// failure after it still counts the response as served.
+		if !failed {
+			r.mu.Lock()
+			if r.pending == nil {
+				r.pending = &detail
+			}
+			r.mu.Unlock()
+			return
+		}
+		if r.publishHeld(ctx) {
+			return
+		}
+	}
+	r.publishRecord(ctx, detail, failed)
+}
+
+// publishHeld publishes the usage held back by trackToolCalls, reporting whether there
+// was any.
+func (r *usageReporter) publishHeld(ctx context.Context) bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	pending := r.pending
+	r.mu.Unlock()
+	if pending == nil {
+		return false
+	}
+	r.publishRecord(ctx, *pending, false)
+	return true
+}
+
+func (r *usageReporter) publishRecord(ctx context.Context, detail usage.Detail, failed bool) {
+	detail.ToolCalls = r.toolCalls.Load()
 	r.once.Do(func() {
 		usage.PublishRecord(ctx, usage.Record{
 			Provider:    r.provider,
@@ -90,24 +209,37 @@ func (r *usageReporter) publishWithOutcome(ctx context.Context, detail usage.Det
 // ensurePublished guarantees that a usage record is emitted exactly once.
 // It is safe to call multiple times; only the first call wins due to once.Do.
 // This is used to ensure request counting even when upstream responses do not
-// include any usage fields (tokens), especially for streaming paths.
+// include any usage fields (tokens), especially for streaming paths. When no
+// authoritative usage was published, the record carries the local estimate of the
+// prompt and the streamed output, flagged as estimated.
 func (r *usageReporter) ensurePublished(ctx context.Context) {
 	if r == nil {
 		return
 	}
-	r.once.Do(func() {
-		usage.PublishRecord(ctx, usage.Record{
-			Provider:    r.provider,
-			Model:       r.model,
-			Source:      r.source,
-			APIKey:      r.apiKey,
-			AuthID:      r.authID,
-			AuthIndex:   r.authIndex,
-			RequestedAt: r.requestedAt,
-			Failed:      false,
-			Detail:      usage.Detail{},
-		})
-	})
+	if r.held && !r.stream {
+		// A non-streamed response is complete once the executor returns.
+		r.ensure.Store(true)
+		return
+	}
+	r.publishFinal(ctx)
+}
+
+// publishFinal publishes the held usage, or the local estimate when there is none.
+func (r *usageReporter) publishFinal(ctx context.Context) {
+	if r.publishHeld(ctx) {
+		return
+	}
+	detail := usage.Detail{}
+	// EstimateJSON counts inline base64 attachments flat, as the prompt limit does, so
+	// multimodal requests are not billed for their encoded bytes.
+	input, output := tokenize.EstimateJSON(r.promptPayload), r.outputTokens.Load()
+	if input > 0 || output > 0 {
+		detail.InputTokens = input
+		detail.OutputTokens = output
+		detail.TotalTokens = input + output
+		detail.Estimated = true
+	}
+	r.publishRecord(ctx, detail, false)
 }
 
 func apiKeyFromContext(ctx context.Context) string {
@@ -258,13 +390,15 @@ func parseClaudeUsage(data []byte) usage.Detail {
 		return usage.Detail{}
 	}
 	detail := usage.Detail{
-		InputTokens:  usageNode.Get("input_tokens").Int(),
-		OutputTokens: usageNode.Get("output_tokens").Int(),
-		CachedTokens: usageNode.Get("cache_read_input_tokens").Int(),
+		InputTokens:         usageNode.Get("input_tokens").Int(),
+		OutputTokens:        usageNode.Get("output_tokens").Int(),
+		CachedTokens:        usageNode.Get("cache_read_input_tokens").Int(),
+		CacheReadTokens:     usageNode.Get("cache_read_input_tokens").Int(),
+		CacheCreationTokens: usageNode.Get("cache_creation_input_tokens").Int(),
 	}
 	if detail.CachedTokens == 0 {
 		// fall back to creation tokens when read tokens are absent
-		detail.CachedTokens = usageNode.Get("cache_creation_input_tokens").Int()
+		detail.CachedTokens = detail.CacheCreationTokens
 	}
 	detail.TotalTokens = detail.InputTokens + detail.OutputTokens
 	return detail
@@ -280,12 +414,14 @@ func parseClaudeStreamUsage(line []byte) (usage.Detail, bool) {
 		return usage.Detail{}, false
 	}
 	detail := usage.Detail{
-		InputTokens:  usageNode.Get("input_tokens").Int(),
-		OutputTokens: usageNode.Get("output_tokens").Int(),
-		CachedTokens: usageNode.Get("cache_read_input_tokens").Int(),
+		InputTokens:         usageNode.Get("input_tokens").Int(),
+		OutputTokens:        usageNode.Get("output_tokens").Int(),
+		CachedTokens:        usageNode.Get("cache_read_input_tokens").Int(),
+		CacheReadTokens:     usageNode.Get("cache_read_input_tokens").Int(),
+		CacheCreationTokens: usageNode.Get("cache_creation_input_tokens").Int(),
 	}
 	if detail.CachedTokens == 0 {
-		detail.CachedTokens = usageNode.Get("cache_creation_input_tokens").Int()
+		detail.CachedTokens = detail.CacheCreationTokens
 	}
 	detail.TotalTokens = detail.InputTokens + detail.OutputTokens
 	return detail, true
@@ -298,6 +434,7 @@ func parseGeminiFamilyUsageDetail(node gjson.Result) usage.Detail {
 		ReasoningTokens: node.Get("thoughtsTokenCount").Int(),
 		TotalTokens:     node.Get("totalTokenCount").Int(),
 		CachedTokens:    node.Get("cachedContentTokenCount").Int(),
+		CacheReadTokens: node.Get("cachedContentTokenCount").Int(),
 	}
 	if detail.TotalTokens == 0 {
 		detail.TotalTokens = detail.InputTokens + detail.OutputTokens + detail.ReasoningTokens
@@ -307,16 +444,34 @@ func parseGeminiFamilyUsageDetail(node gjson.Result) usage.Detail {
 
 func parseGeminiCLIUsage(data []byte) usage.Detail {
 	usageNode := gjson.ParseBytes(data)
-	node := usageNode.Get("response.usageMetadata")
-	if !node.Exists() {
-		node = usageNode.Get("response.usage_metadata")
+	if usageNode.IsArray() {
+		// alt=json streams return every chunk in one array; the last usage block is cumulative.
+		items := usageNode.Array()
+		for i := len(items) - 1; i >= 0; i-- {
+			if node := geminiCLIUsageNode(items[i]); node.Exists() {
+				return parseGeminiFamilyUsageDetail(node)
+			}
+		}
+		return usage.Detail{}
 	}
+	node := geminiCLIUsageNode(usageNode)
 	if !node.Exists() {
 		return usage.Detail{}
 	}
 	return parseGeminiFamilyUsageDetail(node)
 }
 
+// geminiCLIUsageNode finds usage metadata in a wrapped {"response":...} chunk, falling
+// back to a bare Gemini chunk.
+func geminiCLIUsageNode(root gjson.Result) gjson.Result {
+	for _, path := range []string{"response.usageMetadata", "response.usage_metadata", "usageMetadata", "usage_metadata"} {
+		if node := root.Get(path); node.Exists() {
+			return node
+		}
+	}
+	return gjson.Result{}
+}
+
 func parseGeminiUsage(data []byte) usage.Detail {
 	usageNode := gjson.ParseBytes(data)
 	node := usageNode.Get("usageMetadata")
@@ -329,6 +484,63 @@ func parseGeminiUsage(data []byte) usage.Detail {
 	return parseGeminiFamilyUsageDetail(node)
 }
 
+// estimateStreamOutputTokens estimates the output tokens carried by one streamed event in
+// OpenAI, Claude, Gemini or Responses format, including reasoning and tool arguments.
+func estimateStreamOutputTokens(payload []byte) int64 {
+	if len(payload) == 0 || !gjson.ValidBytes(payload) {
+		return 0
+	}
+	var text strings.Builder
+	collectStreamOutputText(gjson.ParseBytes(payload), &text)
+	return tokenize.Estimate(text.String())
+}
+
+func collectStreamOutputText(root gjson.Result, text *strings.Builder) {
+	write := func(value gjson.Result) {
+		if value.Exists() && value.String() != "" {
+			text.WriteString(value.String())
+			text.WriteByte(' ')
+		}
+	}
+	if root.IsArray() {
+		root.ForEach(func(_, item gjson.Result) bool {
+			collectStreamOutputText(item, text)
+			return true
+		})
+		return
+	}
+	root.Get("choices").ForEach(func(_, choice gjson.Result) bool {
+		delta := choice.Get("delta")
+		write(delta.Get("content"))
+		write(delta.Get("reasoning_content"))
+		delta.Get("tool_calls").ForEach(func(_, call gjson.Result) bool {
+			write(call.Get("function.arguments"))
+			return true
+		})
+		return true
+	})
+	switch root.Get("type").String() {
+	case "content_block_delta":
+		write(root.Get("delta.text"))
+		write(root.Get("delta.thinking"))
+		write(root.Get("delta.partial_json"))
+	case "response.output_text.delta", "response.reasoning_summary_text.delta", "response.function_call_arguments.delta":
+		write(root.Get("delta"))
+	}
+	candidates := root.Get("candidates")
+	if !candidates.Exists() {
+		candidates = root.Get("response.candidates")
+	}
+	candidates.ForEach(func(_, candidate gjson.Result) bool {
+		candidate.Get("content.parts").ForEach(func(_, part gjson.Result) bool {
+			write(part.Get("text"))
+			write(part.Get("functionCall.args"))
+			return true
+		})
+		return true
+	})
+}
+
 func parseGeminiStreamUsage(line []byte) (usage.Detail, bool) {
 	payload := jsonPayload(line)
 	if len(payload) == 0 || !gjson.ValidBytes(payload) {
@@ -349,10 +561,7 @@ func parseGeminiCLIStreamUsage(line []byte) (usage.Detail, bool) {
 	if len(payload) == 0 || !gjson.ValidBytes(payload) {
 		return usage.Detail{}, false
 	}
-	node := gjson.GetBytes(payload, "response.usageMetadata")
-	if !node.Exists() {
-		node = gjson.GetBytes(payload, "usage_metadata")
-	}
+	node := geminiCLIUsageNode(gjson.ParseBytes(payload))
 	if !node.Exists() {
 		return usage.Detail{}, false
 	}
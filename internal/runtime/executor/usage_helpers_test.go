@@ -1,6 +1,14 @@
 package executor
 
-import "testing"
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
 
 func TestParseOpenAIUsageChatCompletions(t *testing.T) {
 	data := []byte(`{"usage":{"prompt_tokens":1,"completion_tokens":2,"total_tokens":3,"prompt_tokens_details":{"cached_tokens":4},"completion_tokens_details":{"reasoning_tokens":5}}}`)
@@ -41,3 +49,163 @@ func TestParseOpenAIUsageResponses(t *testing.T) {
 		t.Fatalf("reasoning tokens = %d, want %d", detail.ReasoningTokens, 9)
 	}
 }
+
+func TestParseGeminiCLIUsageReportsCacheReads(t *testing.T) {
+	data := []byte(`{"response":{"usageMetadata":{"promptTokenCount":100,"candidatesTokenCount":4,"totalTokenCount":104,"cachedContentTokenCount":80}}}`)
+	detail := parseGeminiCLIUsage(data)
+	if detail.CacheReadTokens != 80 {
+		t.Fatalf("cache read tokens = %d, want %d", detail.CacheReadTokens, 80)
+	}
+	if detail.CachedTokens != 80 {
+		t.Fatalf("cached tokens = %d, want %d", detail.CachedTokens, 80)
+	}
+	if detail.CacheCreationTokens != 0 {
+		t.Fatalf("cache creation tokens = %d, want 0", detail.CacheCreationTokens)
+	}
+}
+
+func TestParseGeminiCLIUsageArray(t *testing.T) {
+	data := []byte(`[{"response":{"candidates":[{"content":{"parts":[{"text":"a"}]}}]}},{"response":{"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":4,"totalTokenCount":7}}}]`)
+	detail := parseGeminiCLIUsage(data)
+	if detail.InputTokens != 3 {
+		t.Fatalf("input tokens = %d, want %d", detail.InputTokens, 3)
+	}
+	if detail.OutputTokens != 4 {
+		t.Fatalf("output tokens = %d, want %d", detail.OutputTokens, 4)
+	}
+}
+
+func TestParseClaudeUsageCacheBreakdown(t *testing.T) {
+	data := []byte(`{"usage":{"input_tokens":10,"output_tokens":5,"cache_read_input_tokens":0,"cache_creation_input_tokens":1200}}`)
+	detail := parseClaudeUsage(data)
+	if detail.CacheReadTokens != 0 {
+		t.Fatalf("cache read tokens = %d, want %d", detail.CacheReadTokens, 0)
+	}
+	if detail.CacheCreationTokens != 1200 {
+		t.Fatalf("cache creation tokens = %d, want %d", detail.CacheCreationTokens, 1200)
+	}
+	if detail.CachedTokens != 1200 {
+		t.Fatalf("cached tokens = %d, want %d", detail.CachedTokens, 1200)
+	}
+
+	line := []byte(`data: {"type":"message_delta","usage":{"input_tokens":10,"output_tokens":5,"cache_read_input_tokens":800,"cache_creation_input_tokens":40}}`)
+	detail, ok := parseClaudeStreamUsage(line)
+	if !ok {
+		t.Fatal("expected stream usage to parse")
+	}
+	if detail.CacheReadTokens != 800 || detail.CacheCreationTokens != 40 {
+		t.Fatalf("cache read/creation = %d/%d, want 800/40", detail.CacheReadTokens, detail.CacheCreationTokens)
+	}
+	if detail.CachedTokens != 800 {
+		t.Fatalf("cached tokens = %d, want %d", detail.CachedTokens, 800)
+	}
+}
+
+// usageRecordCapture forwards records for one model to a channel.
+type usageRecordCapture struct {
+	model   string
+	records chan usage.Record
+}
+
+func (c *usageRecordCapture) HandleUsage(_ context.Context, record usage.Record) {
+	if record.Model == c.model {
+		c.records <- record
+	}
+}
+
+func TestEnsurePublishedEstimatesStreamWithoutUsage(t *testing.T) {
+	capture := &usageRecordCapture{model: "estimate-stream-model", records: make(chan usage.Record, 1)}
+	usage.RegisterPlugin(capture)
+
+	reporter := newUsageReporter(context.Background(), "antigravity", capture.model, nil)
+	reporter.setPromptPayload([]byte(`{"messages":[{"role":"user","content":"Write a short greeting please"}]}`))
+	for _, line := range []string{
+		`data: {"response":{"candidates":[{"content":{"parts":[{"text":"Hello there"}]}}]}}`,
+		`data: {"response":{"candidates":[{"content":{"parts":[{"text":", friend!"}]},"finishReason":"STOP"}]}}`,
+	} {
+		reporter.observeOutput([]byte(line))
+		if _, ok := parseAntigravityStreamUsage(jsonPayload([]byte(line))); ok {
+			t.Fatalf("test stream unexpectedly carries usage: %s", line)
+		}
+	}
+	reporter.ensurePublished(context.Background())
+
+	select {
+	case record := <-capture.records:
+		detail := record.Detail
+		if !detail.Estimated {
+			t.Fatalf("record not flagged as estimated: %+v", detail)
+		}
+		if detail.InputTokens <= 0 || detail.OutputTokens <= 0 {
+			t.Fatalf("estimated tokens = %d in / %d out, want both positive", detail.InputTokens, detail.OutputTokens)
+		}
+		if detail.TotalTokens != detail.InputTokens+detail.OutputTokens {
+			t.Fatalf("total tokens = %d, want %d", detail.TotalTokens, detail.InputTokens+detail.OutputTokens)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no usage record published for a stream without usage")
+	}
+}
+
+func TestEnsurePublishedEstimateIgnoresInlineImageBytes(t *testing.T) {
+	capture := &usageRecordCapture{model: "estimate-image-model", records: make(chan usage.Record, 2)}
+	usage.RegisterPlugin(capture)
+
+	prompt := `{"contents":[{"role":"user","parts":[{"text":"Describe this picture"}]}]}`
+	image := strings.Repeat("iVBORw0KGgoAAAANSUhEUgAA", 4096)
+	withImage := `{"contents":[{"role":"user","parts":[{"text":"Describe this picture"},{"inlineData":{"mimeType":"image/png","data":"` + image + `"}}]}]}`
+	estimate := func(payload string) int64 {
+		t.Helper()
+		reporter := newUsageReporter(context.Background(), "gemini", capture.model, nil)
+		reporter.setPromptPayload([]byte(payload))
+		reporter.ensurePublished(context.Background())
+		select {
+		case record := <-capture.records:
+			return record.Detail.InputTokens
+		case <-time.After(2 * time.Second):
+			t.Fatal("no usage record published")
+			return 0
+		}
+	}
+
+	textOnly, multimodal := estimate(prompt), estimate(withImage)
+	if extra := multimodal - textOnly; extra <= 0 || extra > 2000 {
+		t.Fatalf("image added %d estimated input tokens for %d base64 bytes, want a flat attachment estimate", extra, len(image))
+	}
+}
+
+func TestStreamUsagePublishedWithToolCallsAfterUsageChunk(t *testing.T) {
+	capture := &usageRecordCapture{model: "tool-call-stream-model", records: make(chan usage.Record, 1)}
+	usage.RegisterPlugin(capture)
+
+	reporter := newUsageReporter(context.Background(), "gemini", capture.model, nil)
+	ctx := reporter.trackStreamToolCalls(context.Background())
+	passthrough := sdktranslator.FromString("tool-call-test")
+	for _, line := range []string{
+		`{"candidates":[{"content":{"parts":[{"text":"Checking"}]}}],"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":3,"totalTokenCount":13}}`,
+		`{"candidates":[{"content":{"parts":[{"functionCall":{"name":"f","args":{}}},{"functionCall":{"name":"g","args":{}}}]},"finishReason":"STOP"}]}`,
+	} {
+		if detail, ok := parseGeminiStreamUsage([]byte(line)); ok {
+			reporter.publish(ctx, detail)
+		}
+		sdktranslator.TranslateStream(ctx, passthrough, passthrough, capture.model, nil, nil, []byte(line), nil)
+	}
+	select {
+	case record := <-capture.records:
+		t.Fatalf("usage published before the stream ended: %+v", record)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	reporter.ensurePublished(ctx)
+	select {
+	case record := <-capture.records:
+		if record.Detail.ToolCalls != 2 {
+			t.Fatalf("tool calls = %d, want 2", record.Detail.ToolCalls)
+		}
+		if record.Detail.InputTokens != 10 || record.Detail.Estimated {
+			t.Fatalf("usage = %+v, want the upstream usage", record.Detail)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no usage record published at the end of the stream")
+	}
+}
@@ -0,0 +1,9 @@
+package geminicli
+
+// Default client identity sent to the Gemini CLI (Cloud Code Assist) upstream by login,
+// onboarding and request execution.
+const (
+	UserAgent      = "google-api-nodejs-client/9.15.1"
+	APIClient      = "gl-node/22.17.0"
+	ClientMetadata = "ideType=IDE_UNSPECIFIED,platform=PLATFORM_UNSPECIFIED,pluginType=GEMINI"
+)
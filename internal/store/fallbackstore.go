@@ -0,0 +1,322 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrStoreReadOnly is returned for writes while a read-only fallback store is degraded.
+var ErrStoreReadOnly = errors.New("token store is read-only until the remote backend is reachable")
+
+// FallbackConnector opens the remote backend once it becomes reachable. It must not
+// resynchronize the local spool from the remote, because pending offline writes live there.
+type FallbackConnector func(ctx context.Context) (cliproxyauth.Store, error)
+
+// FallbackStore serves auth records from the local spool while the remote backend that
+// normally owns it is unreachable. In read-write mode offline writes are recorded and
+// replayed to the remote during reconciliation; afterwards every call goes to the remote.
+type FallbackStore struct {
+	mu          sync.Mutex
+	reconcileMu sync.Mutex
+	local       cliproxyauth.Store
+	remote      cliproxyauth.Store
+	connect     FallbackConnector
+	readOnly    bool
+
+	// pendingAuths maps auth IDs written offline to their latest state; nil marks a delete.
+	pendingAuths map[string]*cliproxyauth.Auth
+	// pendingFiles and pendingConfig hold the sequence number of the latest offline write,
+	// so a replay only forgets writes that were not repeated while it ran. Zero means none.
+	pendingFiles  map[string]uint64
+	pendingConfig uint64
+	writeSeq      uint64
+}
+
+// pendingWrites is a snapshot of the offline writes awaiting replay.
+type pendingWrites struct {
+	auths  map[string]*cliproxyauth.Auth
+	files  map[string]uint64
+	config uint64
+}
+
+// NewFallbackStore wraps the local spool store until connect succeeds.
+func NewFallbackStore(local cliproxyauth.Store, connect FallbackConnector, readOnly bool) *FallbackStore {
+	return &FallbackStore{
+		local:        local,
+		connect:      connect,
+		readOnly:     readOnly,
+		pendingAuths: make(map[string]*cliproxyauth.Auth),
+		pendingFiles: make(map[string]uint64),
+	}
+}
+
+// Degraded reports whether the store is still serving from the local spool.
+func (s *FallbackStore) Degraded() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.remote == nil
+}
+
+// List enumerates auth records from the remote backend, or the spool while degraded.
+func (s *FallbackStore) List(ctx context.Context) ([]*cliproxyauth.Auth, error) {
+	s.mu.Lock()
+	target := s.current()
+	s.mu.Unlock()
+	return target.List(ctx)
+}
+
+// Save persists auth to the remote backend, or to the spool while degraded.
+func (s *FallbackStore) Save(ctx context.Context, auth *cliproxyauth.Auth) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.remote != nil {
+		return s.remote.Save(ctx, auth)
+	}
+	if s.readOnly {
+		return "", ErrStoreReadOnly
+	}
+	path, err := s.local.Save(ctx, auth)
+	if err != nil {
+		return "", err
+	}
+	if auth != nil && auth.ID != "" {
+		s.pendingAuths[auth.ID] = auth.Clone()
+	}
+	return path, nil
+}
+
+// Delete removes the auth record from the remote backend, or from the spool while degraded.
+func (s *FallbackStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.remote != nil {
+		return s.remote.Delete(ctx, id)
+	}
+	if s.readOnly {
+		return ErrStoreReadOnly
+	}
+	if err := s.local.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.pendingAuths[id] = nil
+	return nil
+}
+
+// SetBaseDir forwards the auth directory to the spool store.
+func (s *FallbackStore) SetBaseDir(dir string) {
+	if dirSetter, ok := s.local.(interface{ SetBaseDir(string) }); ok {
+		dirSetter.SetBaseDir(dir)
+	}
+}
+
+// PersistConfig uploads the configuration, or defers it until reconciliation.
+func (s *FallbackStore) PersistConfig(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.remote == nil {
+		if s.readOnly {
+			return ErrStoreReadOnly
+		}
+		s.writeSeq++
+		s.pendingConfig = s.writeSeq
+		return nil
+	}
+	if persister, ok := s.remote.(interface{ PersistConfig(context.Context) error }); ok {
+		return persister.PersistConfig(ctx)
+	}
+	return nil
+}
+
+// PersistAuthFiles uploads auth files written into the spool, or defers them until reconciliation.
+func (s *FallbackStore) PersistAuthFiles(ctx context.Context, message string, paths ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.remote == nil {
+		if s.readOnly {
+			return ErrStoreReadOnly
+		}
+		s.writeSeq++
+		for _, path := range paths {
+			s.pendingFiles[path] = s.writeSeq
+		}
+		return nil
+	}
+	if persister, ok := s.remote.(interface {
+		PersistAuthFiles(context.Context, string, ...string) error
+	}); ok {
+		return persister.PersistAuthFiles(ctx, message, paths...)
+	}
+	return nil
+}
+
+// Flush flushes the active store when it buffers writes.
+func (s *FallbackStore) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	target := s.current()
+	s.mu.Unlock()
+	if flusher, ok := target.(cliproxyauth.StoreFlusher); ok {
+		return flusher.Flush(ctx)
+	}
+	return nil
+}
+
+// Reconcile connects to the remote backend and replays writes made while degraded.
+// Connecting and the bulk replay run without holding the store lock, so reads and
+// offline writes are not blocked by a slow backend; the lock is only taken to replay
+// writes that arrived meanwhile and switch to the remote. The store only switches once
+// every pending write has been applied; on failure the remaining writes are kept for
+// the next attempt.
+func (s *FallbackStore) Reconcile(ctx context.Context) error {
+	s.reconcileMu.Lock()
+	defer s.reconcileMu.Unlock()
+
+	s.mu.Lock()
+	connected, connect := s.remote != nil, s.connect
+	pending := s.pendingLocked()
+	s.mu.Unlock()
+	if connected {
+		return nil
+	}
+	if connect == nil {
+		return fmt.Errorf("fallback store: no remote connector configured")
+	}
+	remote, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	done, err := replayPending(ctx, remote, pending)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.forgetLocked(done)
+	if err == nil {
+		done, err = replayPending(ctx, remote, s.pendingLocked())
+		s.forgetLocked(done)
+	}
+	if err != nil {
+		if closer, ok := remote.(io.Closer); ok {
+			_ = closer.Close()
+		}
+		return err
+	}
+	s.remote = remote
+	return nil
+}
+
+// pendingLocked copies the writes awaiting replay. Callers must hold s.mu.
+func (s *FallbackStore) pendingLocked() pendingWrites {
+	pending := pendingWrites{
+		auths:  make(map[string]*cliproxyauth.Auth, len(s.pendingAuths)),
+		files:  make(map[string]uint64, len(s.pendingFiles)),
+		config: s.pendingConfig,
+	}
+	for id, auth := range s.pendingAuths {
+		pending.auths[id] = auth
+	}
+	for path, seq := range s.pendingFiles {
+		pending.files[path] = seq
+	}
+	return pending
+}
+
+// forgetLocked drops replayed writes that were not superseded while the replay ran.
+// Callers must hold s.mu.
+func (s *FallbackStore) forgetLocked(done pendingWrites) {
+	for id, auth := range done.auths {
+		if current, ok := s.pendingAuths[id]; ok && current == auth {
+			delete(s.pendingAuths, id)
+		}
+	}
+	for path, seq := range done.files {
+		if s.pendingFiles[path] == seq {
+			delete(s.pendingFiles, path)
+		}
+	}
+	if done.config != 0 && s.pendingConfig == done.config {
+		s.pendingConfig = 0
+	}
+}
+
+// replayPending applies pending to remote and returns the writes that succeeded.
+func replayPending(ctx context.Context, remote cliproxyauth.Store, pending pendingWrites) (pendingWrites, error) {
+	done := pendingWrites{auths: make(map[string]*cliproxyauth.Auth), files: make(map[string]uint64)}
+	var errs []error
+	for id, auth := range pending.auths {
+		var errReplay error
+		if auth == nil {
+			errReplay = remote.Delete(ctx, id)
+		} else {
+			_, errReplay = remote.Save(ctx, auth.Clone())
+		}
+		if errReplay != nil {
+			errs = append(errs, fmt.Errorf("replay auth %s: %w", id, errReplay))
+			continue
+		}
+		done.auths[id] = auth
+	}
+	if persister, ok := remote.(interface {
+		PersistAuthFiles(context.Context, string, ...string) error
+		PersistConfig(context.Context) error
+	}); ok {
+		if len(pending.files) > 0 {
+			paths := make([]string, 0, len(pending.files))
+			for path := range pending.files {
+				paths = append(paths, path)
+			}
+			if errPersist := persister.PersistAuthFiles(ctx, "Reconcile offline auth changes", paths...); errPersist != nil {
+				errs = append(errs, fmt.Errorf("replay auth files: %w", errPersist))
+			} else {
+				done.files = pending.files
+			}
+		}
+		if pending.config != 0 {
+			if errPersist := persister.PersistConfig(ctx); errPersist != nil {
+				errs = append(errs, fmt.Errorf("replay config: %w", errPersist))
+			} else {
+				done.config = pending.config
+			}
+		}
+	}
+	return done, errors.Join(errs...)
+}
+
+// Start retries Reconcile every interval in the background until it succeeds or ctx ends.
+func (s *FallbackStore) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			attemptCtx, cancel := context.WithTimeout(ctx, interval)
+			err := s.Reconcile(attemptCtx)
+			cancel()
+			if err != nil {
+				log.WithError(err).Debug("fallback store: remote backend still unavailable")
+				continue
+			}
+			log.Info("fallback store: remote backend reachable again, offline changes reconciled")
+			return
+		}
+	}()
+}
+
+func (s *FallbackStore) current() cliproxyauth.Store {
+	if s.remote != nil {
+		return s.remote
+	}
+	return s.local
+}
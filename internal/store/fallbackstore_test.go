@@ -0,0 +1,156 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func newSpoolStore(t *testing.T) (*sdkAuth.FileTokenStore, string) {
+	t.Helper()
+	authDir := filepath.Join(t.TempDir(), "pgstore", "auths")
+	if err := os.MkdirAll(authDir, 0o700); err != nil {
+		t.Fatalf("create spool: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(authDir, "cached.json"), []byte(`{"type":"claude","email":"cached@example.com"}`), 0o600); err != nil {
+		t.Fatalf("seed spool: %v", err)
+	}
+	local := sdkAuth.NewFileTokenStore()
+	local.SetBaseDir(authDir)
+	return local, authDir
+}
+
+func TestFallbackStoreServesSpoolWhileRemoteUnreachable(t *testing.T) {
+	local, authDir := newSpoolStore(t)
+	remote := newMemoryStore(authDir)
+	reachable := false
+	connect := func(context.Context) (cliproxyauth.Store, error) {
+		if !reachable {
+			return nil, errors.New("dial tcp: connection refused")
+		}
+		return remote, nil
+	}
+	fallback := NewFallbackStore(local, connect, false)
+	ctx := context.Background()
+
+	if err := fallback.Reconcile(ctx); err == nil {
+		t.Fatalf("Reconcile should fail while the remote is unreachable")
+	}
+	if !fallback.Degraded() {
+		t.Fatalf("store should be degraded at startup")
+	}
+	auths, err := fallback.List(ctx)
+	if err != nil {
+		t.Fatalf("List error: %v", err)
+	}
+	if len(auths) != 1 || auths[0].ID != "cached.json" {
+		t.Fatalf("List = %v, want the cached spool credential", auths)
+	}
+
+	offline := &cliproxyauth.Auth{ID: "offline.json", FileName: "offline.json", Provider: "codex", Metadata: map[string]any{"type": "codex"}}
+	if _, err = fallback.Save(ctx, offline); err != nil {
+		t.Fatalf("Save while degraded: %v", err)
+	}
+	if _, errStat := os.Stat(filepath.Join(authDir, "offline.json")); errStat != nil {
+		t.Fatalf("offline write did not reach the spool: %v", errStat)
+	}
+	if err = fallback.Delete(ctx, "cached.json"); err != nil {
+		t.Fatalf("Delete while degraded: %v", err)
+	}
+	if len(remote.saved) != 0 || len(remote.deleted) != 0 {
+		t.Fatalf("remote was written while unreachable")
+	}
+
+	reachable = true
+	if err = fallback.Reconcile(ctx); err != nil {
+		t.Fatalf("Reconcile error: %v", err)
+	}
+	if fallback.Degraded() {
+		t.Fatalf("store should use the remote after reconciliation")
+	}
+	if _, ok := remote.saved["offline.json"]; !ok {
+		t.Fatalf("offline save was not replayed to the remote")
+	}
+	if len(remote.deleted) != 1 || remote.deleted[0] != "cached.json" {
+		t.Fatalf("offline delete was not replayed: %v", remote.deleted)
+	}
+
+	online := &cliproxyauth.Auth{ID: "online.json", FileName: "online.json", Attributes: map[string]string{}}
+	if _, err = fallback.Save(ctx, online); err != nil {
+		t.Fatalf("Save after reconciliation: %v", err)
+	}
+	if _, ok := remote.saved["online.json"]; !ok {
+		t.Fatalf("writes after reconciliation should go to the remote")
+	}
+}
+
+func TestFallbackStoreReadOnlyRejectsWrites(t *testing.T) {
+	local, _ := newSpoolStore(t)
+	connect := func(context.Context) (cliproxyauth.Store, error) {
+		return nil, errors.New("unreachable")
+	}
+	fallback := NewFallbackStore(local, connect, true)
+	ctx := context.Background()
+
+	auths, err := fallback.List(ctx)
+	if err != nil || len(auths) != 1 {
+		t.Fatalf("List = %v, %v; want the cached spool credential", auths, err)
+	}
+	auth := &cliproxyauth.Auth{ID: "new.json", FileName: "new.json", Metadata: map[string]any{"type": "codex"}}
+	if _, err = fallback.Save(ctx, auth); !errors.Is(err, ErrStoreReadOnly) {
+		t.Fatalf("Save error = %v, want ErrStoreReadOnly", err)
+	}
+	if err = fallback.Delete(ctx, "cached.json"); !errors.Is(err, ErrStoreReadOnly) {
+		t.Fatalf("Delete error = %v, want ErrStoreReadOnly", err)
+	}
+}
+
+func TestFallbackStoreReconcileDoesNotBlockWhileConnecting(t *testing.T) {
+	local, authDir := newSpoolStore(t)
+	remote := newMemoryStore(authDir)
+	connecting := make(chan struct{})
+	release := make(chan struct{})
+	connect := func(context.Context) (cliproxyauth.Store, error) {
+		close(connecting)
+		<-release
+		return remote, nil
+	}
+	fallback := NewFallbackStore(local, connect, false)
+	ctx := context.Background()
+
+	reconciled := make(chan error, 1)
+	go func() { reconciled <- fallback.Reconcile(ctx) }()
+	<-connecting
+
+	written := make(chan error, 1)
+	go func() {
+		auth := &cliproxyauth.Auth{ID: "during.json", FileName: "during.json", Provider: "codex", Metadata: map[string]any{"type": "codex"}}
+		_, err := fallback.Save(ctx, auth)
+		written <- err
+	}()
+	select {
+	case err := <-written:
+		if err != nil {
+			t.Fatalf("Save while connecting: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Save blocked while the remote was connecting")
+	}
+	if !fallback.Degraded() {
+		t.Fatal("store should stay degraded until reconciliation finishes")
+	}
+
+	close(release)
+	if err := <-reconciled; err != nil {
+		t.Fatalf("Reconcile error: %v", err)
+	}
+	if _, ok := remote.saved["during.json"]; !ok {
+		t.Fatal("write made while connecting was not replayed to the remote")
+	}
+}
@@ -0,0 +1,240 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// MultiStore combines a primary token store with any number of mirrors.
+// Reads are served by the primary only; writes go to the primary first and are then
+// replicated to every mirror on a best-effort basis, so a failing mirror never fails
+// the operation.
+type MultiStore struct {
+	primary cliproxyauth.Store
+	mirrors []cliproxyauth.Store
+}
+
+// NewMultiStore wraps primary and mirrors into a single token store.
+func NewMultiStore(primary cliproxyauth.Store, mirrors ...cliproxyauth.Store) *MultiStore {
+	filtered := make([]cliproxyauth.Store, 0, len(mirrors))
+	for _, mirror := range mirrors {
+		if mirror != nil {
+			filtered = append(filtered, mirror)
+		}
+	}
+	return &MultiStore{primary: primary, mirrors: filtered}
+}
+
+// Primary returns the store used for reads.
+func (s *MultiStore) Primary() cliproxyauth.Store {
+	if s == nil {
+		return nil
+	}
+	return s.primary
+}
+
+// Mirrors returns the stores receiving replicated writes.
+func (s *MultiStore) Mirrors() []cliproxyauth.Store {
+	if s == nil {
+		return nil
+	}
+	return s.mirrors
+}
+
+// List enumerates auth records from the primary store.
+func (s *MultiStore) List(ctx context.Context) ([]*cliproxyauth.Auth, error) {
+	if s == nil || s.primary == nil {
+		return nil, fmt.Errorf("multi store: primary store not configured")
+	}
+	return s.primary.List(ctx)
+}
+
+// Save persists auth to the primary store and replicates it to the mirrors.
+func (s *MultiStore) Save(ctx context.Context, auth *cliproxyauth.Auth) (string, error) {
+	if s == nil || s.primary == nil {
+		return "", fmt.Errorf("multi store: primary store not configured")
+	}
+	path, err := s.primary.Save(ctx, auth)
+	if err != nil {
+		return "", err
+	}
+	for _, mirror := range s.mirrors {
+		if _, errMirror := mirror.Save(ctx, mirrorAuth(auth)); errMirror != nil {
+			log.WithError(errMirror).Warnf("multi store: mirror %T failed to save auth %s", mirror, authLabel(auth))
+		}
+	}
+	return path, nil
+}
+
+// Delete removes the auth record from the primary store and then from the mirrors.
+func (s *MultiStore) Delete(ctx context.Context, id string) error {
+	if s == nil || s.primary == nil {
+		return fmt.Errorf("multi store: primary store not configured")
+	}
+	if err := s.primary.Delete(ctx, id); err != nil {
+		return err
+	}
+	for _, mirror := range s.mirrors {
+		if errMirror := mirror.Delete(ctx, mirrorID(id)); errMirror != nil {
+			log.WithError(errMirror).Warnf("multi store: mirror %T failed to delete auth %s", mirror, id)
+		}
+	}
+	return nil
+}
+
+// SetBaseDir forwards the auth directory to the primary store. Mirrors manage their own
+// workspaces and are left untouched.
+func (s *MultiStore) SetBaseDir(dir string) {
+	if s == nil {
+		return
+	}
+	if dirSetter, ok := s.primary.(interface{ SetBaseDir(string) }); ok {
+		dirSetter.SetBaseDir(dir)
+	}
+}
+
+// AuthDir reports the primary store's auth directory when it exposes one.
+func (s *MultiStore) AuthDir() string {
+	if s == nil {
+		return ""
+	}
+	if provider, ok := s.primary.(interface{ AuthDir() string }); ok {
+		return provider.AuthDir()
+	}
+	return ""
+}
+
+// PersistConfig persists the configuration through the primary store.
+func (s *MultiStore) PersistConfig(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	if persister, ok := s.primary.(interface{ PersistConfig(context.Context) error }); ok {
+		return persister.PersistConfig(ctx)
+	}
+	return nil
+}
+
+// PersistAuthFiles persists auth files written directly into the primary workspace and
+// then replicates them to the mirrors by file name: files that still exist are saved from
+// their contents and removed files are deleted. Only a primary failure is returned.
+func (s *MultiStore) PersistAuthFiles(ctx context.Context, message string, paths ...string) error {
+	if s == nil {
+		return nil
+	}
+	if persister, ok := s.primary.(interface {
+		PersistAuthFiles(context.Context, string, ...string) error
+	}); ok {
+		if err := persister.PersistAuthFiles(ctx, message, paths...); err != nil {
+			return err
+		}
+	}
+	for _, path := range paths {
+		s.mirrorAuthFile(ctx, path)
+	}
+	return nil
+}
+
+// mirrorAuthFile replicates one auth file from the primary workspace to every mirror.
+func (s *MultiStore) mirrorAuthFile(ctx context.Context, path string) {
+	if len(s.mirrors) == 0 || strings.TrimSpace(path) == "" {
+		return
+	}
+	if !filepath.IsAbs(path) {
+		dir := s.AuthDir()
+		if dir == "" {
+			log.Warnf("multi store: cannot resolve auth file %s for mirrors", path)
+			return
+		}
+		path = filepath.Join(dir, path)
+	}
+	name := filepath.Base(path)
+	data, errRead := os.ReadFile(path)
+	if errors.Is(errRead, fs.ErrNotExist) {
+		for _, mirror := range s.mirrors {
+			if errMirror := mirror.Delete(ctx, name); errMirror != nil {
+				log.WithError(errMirror).Warnf("multi store: mirror %T failed to delete auth %s", mirror, name)
+			}
+		}
+		return
+	}
+	if errRead != nil {
+		log.WithError(errRead).Warnf("multi store: read auth file %s for mirrors", path)
+		return
+	}
+	var metadata map[string]any
+	if errUnmarshal := json.Unmarshal(data, &metadata); errUnmarshal != nil {
+		log.WithError(errUnmarshal).Warnf("multi store: auth file %s is not valid JSON, not mirrored", path)
+		return
+	}
+	auth := &cliproxyauth.Auth{ID: name, FileName: name, Attributes: map[string]string{}, Metadata: metadata}
+	if provider, _ := metadata["type"].(string); provider != "" {
+		auth.Provider = provider
+	}
+	for _, mirror := range s.mirrors {
+		if _, errMirror := mirror.Save(ctx, auth.Clone()); errMirror != nil {
+			log.WithError(errMirror).Warnf("multi store: mirror %T failed to save auth %s", mirror, name)
+		}
+	}
+}
+
+// Flush flushes every store that buffers writes. Only a primary failure is returned.
+func (s *MultiStore) Flush(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	for _, mirror := range s.mirrors {
+		if flusher, ok := mirror.(cliproxyauth.StoreFlusher); ok {
+			if errFlush := flusher.Flush(ctx); errFlush != nil {
+				log.WithError(errFlush).Warnf("multi store: mirror %T failed to flush", mirror)
+			}
+		}
+	}
+	if flusher, ok := s.primary.(cliproxyauth.StoreFlusher); ok {
+		return flusher.Flush(ctx)
+	}
+	return nil
+}
+
+// mirrorAuth returns a copy of auth suitable for a mirror. The absolute path assigned by
+// the primary is dropped so each mirror resolves the file inside its own workspace.
+func mirrorAuth(auth *cliproxyauth.Auth) *cliproxyauth.Auth {
+	clone := auth.Clone()
+	if clone == nil {
+		return nil
+	}
+	if path := strings.TrimSpace(clone.Attributes["path"]); path != "" && filepath.IsAbs(path) {
+		delete(clone.Attributes, "path")
+		if strings.TrimSpace(clone.FileName) == "" {
+			clone.FileName = filepath.Base(path)
+		}
+	}
+	return clone
+}
+
+// mirrorID maps an absolute primary path to the file name mirrors know it by.
+func mirrorID(id string) string {
+	if filepath.IsAbs(id) {
+		return filepath.Base(id)
+	}
+	return id
+}
+
+func authLabel(auth *cliproxyauth.Auth) string {
+	if auth == nil {
+		return ""
+	}
+	if auth.ID != "" {
+		return auth.ID
+	}
+	return auth.FileName
+}
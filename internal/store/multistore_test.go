@@ -0,0 +1,165 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+type memoryStore struct {
+	mu      sync.Mutex
+	root    string
+	saved   map[string]*cliproxyauth.Auth
+	deleted []string
+	saveErr error
+}
+
+func newMemoryStore(root string) *memoryStore {
+	return &memoryStore{root: root, saved: make(map[string]*cliproxyauth.Auth)}
+}
+
+func (s *memoryStore) List(context.Context) ([]*cliproxyauth.Auth, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*cliproxyauth.Auth, 0, len(s.saved))
+	for _, auth := range s.saved {
+		out = append(out, auth)
+	}
+	return out, nil
+}
+
+func (s *memoryStore) Save(_ context.Context, auth *cliproxyauth.Auth) (string, error) {
+	if s.saveErr != nil {
+		return "", s.saveErr
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := auth.Attributes["path"]
+	if path == "" {
+		path = s.root + "/" + auth.FileName
+	}
+	auth.Attributes["path"] = path
+	s.saved[auth.ID] = auth
+	return path, nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleted = append(s.deleted, id)
+	delete(s.saved, id)
+	return nil
+}
+
+func TestMultiStoreSaveReachesPrimaryAndMirror(t *testing.T) {
+	primary := newMemoryStore("/primary")
+	mirror := newMemoryStore("/mirror")
+	multi := NewMultiStore(primary, mirror)
+
+	auth := &cliproxyauth.Auth{ID: "a.json", FileName: "a.json", Attributes: map[string]string{}}
+	path, err := multi.Save(context.Background(), auth)
+	if err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	if path != "/primary/a.json" {
+		t.Fatalf("path = %q, want the primary path", path)
+	}
+	if _, ok := primary.saved["a.json"]; !ok {
+		t.Fatalf("primary did not receive the auth")
+	}
+	mirrored, ok := mirror.saved["a.json"]
+	if !ok {
+		t.Fatalf("mirror did not receive the auth")
+	}
+	if got := mirrored.Attributes["path"]; got != "/mirror/a.json" {
+		t.Fatalf("mirror path = %q, want it resolved inside the mirror", got)
+	}
+	if got := auth.Attributes["path"]; got != "/primary/a.json" {
+		t.Fatalf("caller auth path = %q, mirror must not overwrite it", got)
+	}
+
+	if err = multi.Delete(context.Background(), "a.json"); err != nil {
+		t.Fatalf("Delete error: %v", err)
+	}
+	if len(primary.deleted) != 1 || len(mirror.deleted) != 1 {
+		t.Fatalf("delete fan-out: primary=%v mirror=%v", primary.deleted, mirror.deleted)
+	}
+}
+
+func TestMultiStoreMirrorFailureDoesNotFailPrimary(t *testing.T) {
+	primary := newMemoryStore("/primary")
+	broken := newMemoryStore("/broken")
+	broken.saveErr = errors.New("mirror offline")
+	healthy := newMemoryStore("/healthy")
+	multi := NewMultiStore(primary, broken, healthy)
+
+	auth := &cliproxyauth.Auth{ID: "b.json", FileName: "b.json", Attributes: map[string]string{}}
+	if _, err := multi.Save(context.Background(), auth); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	if _, ok := primary.saved["b.json"]; !ok {
+		t.Fatalf("primary write was lost")
+	}
+	if _, ok := healthy.saved["b.json"]; !ok {
+		t.Fatalf("remaining mirrors were skipped after a mirror failure")
+	}
+
+	list, err := multi.List(context.Background())
+	if err != nil || len(list) != 1 {
+		t.Fatalf("List = %v, %v; want the primary record", list, err)
+	}
+}
+
+func TestMultiStorePrimaryFailureIsReturned(t *testing.T) {
+	primary := newMemoryStore("/primary")
+	primary.saveErr = errors.New("primary offline")
+	mirror := newMemoryStore("/mirror")
+	multi := NewMultiStore(primary, mirror)
+
+	auth := &cliproxyauth.Auth{ID: "c.json", FileName: "c.json", Attributes: map[string]string{}}
+	if _, err := multi.Save(context.Background(), auth); err == nil {
+		t.Fatalf("expected primary error")
+	}
+	if len(mirror.saved) != 0 {
+		t.Fatalf("mirror should not be written when the primary fails")
+	}
+}
+
+func TestMultiStorePersistAuthFilesReachesMirror(t *testing.T) {
+	primary := newMemoryStore("/primary")
+	mirror := newMemoryStore("/mirror")
+	multi := NewMultiStore(primary, mirror)
+
+	path := filepath.Join(t.TempDir(), "b.json")
+	if err := os.WriteFile(path, []byte(`{"type":"claude","email":"b@example.com"}`), 0o600); err != nil {
+		t.Fatalf("write auth file: %v", err)
+	}
+	if err := multi.PersistAuthFiles(context.Background(), "Sync auth b.json", path); err != nil {
+		t.Fatalf("PersistAuthFiles error: %v", err)
+	}
+	mirrored, ok := mirror.saved["b.json"]
+	if !ok {
+		t.Fatalf("mirror did not receive the auth file")
+	}
+	if mirrored.Provider != "claude" || mirrored.Metadata["email"] != "b@example.com" {
+		t.Fatalf("mirrored auth = %+v, want the file contents", mirrored)
+	}
+	if got := mirrored.Attributes["path"]; got != "/mirror/b.json" {
+		t.Fatalf("mirror path = %q, want it resolved inside the mirror", got)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("remove auth file: %v", err)
+	}
+	if err := multi.PersistAuthFiles(context.Background(), "Remove auth b.json", path); err != nil {
+		t.Fatalf("PersistAuthFiles error: %v", err)
+	}
+	if len(mirror.deleted) != 1 || mirror.deleted[0] != "b.json" {
+		t.Fatalf("mirror deletes = %v, want b.json", mirror.deleted)
+	}
+}
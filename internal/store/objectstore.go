@@ -9,6 +9,7 @@ import (
 	"io"
 	"io/fs"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -95,6 +96,68 @@ func NewObjectTokenStore(cfg ObjectStoreConfig) (*ObjectTokenStore, error) {
 		return nil, fmt.Errorf("object store: create auth directory: %w", err)
 	}
 
+	client, err := newObjectStoreClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ObjectTokenStore{
+		client:     client,
+		cfg:        cfg,
+		spoolRoot:  absRoot,
+		configPath: filepath.Join(configDir, "config.yaml"),
+		authDir:    authDir,
+	}, nil
+}
+
+// NewObjectTokenStoreFromEndpoint builds an object storage token store rooted at localRoot. The endpoint
+// may be a bare host or an http(s) URL; the scheme decides whether TLS is used.
+func NewObjectTokenStoreFromEndpoint(localRoot, endpoint, accessKey, secretKey, bucket string) (*ObjectTokenStore, error) {
+	cfg, err := objectStoreConfigFromEndpoint(endpoint, accessKey, secretKey, bucket)
+	if err != nil {
+		return nil, err
+	}
+	cfg.LocalRoot = localRoot
+	return NewObjectTokenStore(cfg)
+}
+
+// objectStoreConfigFromEndpoint builds a path-style config from a bare host or http(s) URL.
+func objectStoreConfigFromEndpoint(endpoint, accessKey, secretKey, bucket string) (ObjectStoreConfig, error) {
+	resolvedEndpoint := strings.TrimSpace(endpoint)
+	useSSL := true
+	if strings.Contains(resolvedEndpoint, "://") {
+		parsed, errParse := url.Parse(resolvedEndpoint)
+		if errParse != nil {
+			return ObjectStoreConfig{}, fmt.Errorf("parse object store endpoint %q: %w", endpoint, errParse)
+		}
+		switch strings.ToLower(parsed.Scheme) {
+		case "http":
+			useSSL = false
+		case "https":
+			useSSL = true
+		default:
+			return ObjectStoreConfig{}, fmt.Errorf("unsupported object store scheme %q (only http and https are allowed)", parsed.Scheme)
+		}
+		if parsed.Host == "" {
+			return ObjectStoreConfig{}, fmt.Errorf("object store endpoint %q is missing host information", endpoint)
+		}
+		resolvedEndpoint = parsed.Host
+		if parsed.Path != "" && parsed.Path != "/" {
+			resolvedEndpoint = strings.TrimSuffix(parsed.Host+parsed.Path, "/")
+		}
+	}
+	resolvedEndpoint = strings.TrimRight(resolvedEndpoint, "/")
+	return ObjectStoreConfig{
+		Endpoint:  resolvedEndpoint,
+		Bucket:    bucket,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		UseSSL:    useSSL,
+		PathStyle: true,
+	}, nil
+}
+
+func newObjectStoreClient(cfg ObjectStoreConfig) (*minio.Client, error) {
 	options := &minio.Options{
 		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
 		Secure: cfg.UseSSL,
@@ -103,25 +166,26 @@ func NewObjectTokenStore(cfg ObjectStoreConfig) (*ObjectTokenStore, error) {
 	if cfg.PathStyle {
 		options.BucketLookup = minio.BucketLookupPath
 	}
-
 	client, err := minio.New(cfg.Endpoint, options)
 	if err != nil {
 		return nil, fmt.Errorf("object store: create client: %w", err)
 	}
-
-	return &ObjectTokenStore{
-		client:     client,
-		cfg:        cfg,
-		spoolRoot:  absRoot,
-		configPath: filepath.Join(configDir, "config.yaml"),
-		authDir:    authDir,
-	}, nil
+	return client, nil
 }
 
 // SetBaseDir implements the optional interface used by authenticators; it is a no-op because
 // the object store controls its own workspace.
 func (s *ObjectTokenStore) SetBaseDir(string) {}
 
+// EnsureBucket verifies the backend is reachable and creates the bucket when missing,
+// without touching the local workspace.
+func (s *ObjectTokenStore) EnsureBucket(ctx context.Context) error {
+	if s == nil {
+		return fmt.Errorf("object store: not initialized")
+	}
+	return s.ensureBucket(ctx)
+}
+
 // ConfigPath returns the managed configuration file path inside the spool directory.
 func (s *ObjectTokenStore) ConfigPath() string {
 	if s == nil {
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"github.com/go-git/go-git/v6/storage/memory"
+)
+
+// The probes below check a remote backend directly. Unlike the store constructors and
+// Bootstrap they never create buckets, tables, spool directories or commits, so they are
+// safe to run from -check-config.
+
+// ProbePostgres connects to dsn and pings the database.
+func ProbePostgres(ctx context.Context, dsn string) error {
+	dsn = strings.TrimSpace(dsn)
+	if dsn == "" {
+		return fmt.Errorf("postgres store: DSN is required")
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return fmt.Errorf("postgres store: open database connection: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+	if err = db.PingContext(ctx); err != nil {
+		return fmt.Errorf("postgres store: ping database: %w", err)
+	}
+	return nil
+}
+
+// ProbeObjectStore checks that bucket exists at endpoint.
+func ProbeObjectStore(ctx context.Context, endpoint, accessKey, secretKey, bucket string) error {
+	cfg, err := objectStoreConfigFromEndpoint(endpoint, accessKey, secretKey, bucket)
+	if err != nil {
+		return err
+	}
+	client, err := newObjectStoreClient(cfg)
+	if err != nil {
+		return err
+	}
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return fmt.Errorf("object store: check bucket: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("object store: bucket %q does not exist", cfg.Bucket)
+	}
+	return nil
+}
+
+// ProbeGitRemote lists the references of remote. An empty repository is reachable.
+func ProbeGitRemote(ctx context.Context, remote, username, password string) error {
+	probe := &GitTokenStore{remote: remote, username: username, password: password}
+	rem := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: "origin", URLs: []string{remote}})
+	if _, err := rem.ListContext(ctx, &git.ListOptions{Auth: probe.gitAuth()}); err != nil && !errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return fmt.Errorf("git token store: list remote: %w", err)
+	}
+	return nil
+}
@@ -108,6 +108,17 @@ func ApplyThinking(body []byte, model string, fromFormat string, toFormat string
 	// 2. Parse suffix and get modelInfo
 	suffixResult := ParseSuffix(model)
 	baseModel := suffixResult.ModelName
+	if suffixResult.Malformed {
+		switch GetMalformedSuffixAction() {
+		case MalformedSuffixError:
+			return body, NewThinkingErrorWithModel(ErrInvalidSuffix, "malformed model suffix: "+model, baseModel)
+		case MalformedSuffixStrip:
+			log.WithFields(log.Fields{
+				"provider": providerFormat,
+				"model":    model,
+			}).Warn("thinking: malformed model suffix stripped")
+		}
+	}
 	// Use provider-specific lookup to handle capability differences across providers.
 	modelInfo := registry.LookupModelInfo(baseModel, providerKey)
 
@@ -157,6 +168,12 @@ func ApplyThinking(body []byte, model string, fromFormat string, toFormat string
 		}
 	}
 
+	// Fall back to the model's configured default when neither suffix nor body set thinking.
+	fromDefault := false
+	if !hasThinkingConfig(config) && !suffixResult.HasSuffix {
+		config, fromDefault = defaultThinkingConfig(modelInfo, providerFormat)
+	}
+
 	if !hasThinkingConfig(config) {
 		log.WithFields(log.Fields{
 			"provider": providerFormat,
@@ -166,7 +183,8 @@ func ApplyThinking(body []byte, model string, fromFormat string, toFormat string
 	}
 
 	// 5. Validate and normalize configuration
-	validated, err := ValidateConfig(config, modelInfo, fromFormat, providerFormat, suffixResult.HasSuffix)
+	// Model defaults are operator-provided, so they are clamped like suffix values instead of rejected.
+	validated, err := ValidateConfig(config, modelInfo, fromFormat, providerFormat, suffixResult.HasSuffix || fromDefault)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"provider": providerFormat,
@@ -241,6 +259,36 @@ func parseSuffixToConfig(rawSuffix, provider, model string) ThinkingConfig {
 	return ThinkingConfig{}
 }
 
+// defaultThinkingConfig returns the configured default thinking for a model.
+//
+// The default uses suffix syntax (see parseSuffixToConfig). Level defaults are clamped
+// to the nearest level supported by the model; budget defaults are clamped later by
+// ValidateConfig. The boolean is false when the model has no usable default.
+func defaultThinkingConfig(modelInfo *registry.ModelInfo, provider string) (ThinkingConfig, bool) {
+	if modelInfo == nil {
+		return ThinkingConfig{}, false
+	}
+	raw := strings.TrimSpace(modelInfo.DefaultThinking)
+	if raw == "" {
+		return ThinkingConfig{}, false
+	}
+	config := parseSuffixToConfig(raw, provider, modelInfo.ID)
+	if !hasThinkingConfig(config) {
+		return ThinkingConfig{}, false
+	}
+	if config.Mode == ModeLevel {
+		config.Level = clampLevel(config.Level, modelInfo, provider)
+	}
+	log.WithFields(log.Fields{
+		"provider": provider,
+		"model":    modelInfo.ID,
+		"mode":     config.Mode,
+		"budget":   config.Budget,
+		"level":    config.Level,
+	}).Debug("thinking: config from model default |")
+	return config, true
+}
+
 // applyUserDefinedModel applies thinking configuration for user-defined models
 // without ThinkingSupport validation.
 func applyUserDefinedModel(body []byte, modelInfo *registry.ModelInfo, fromFormat, toFormat string, suffixResult SuffixResult) ([]byte, error) {
@@ -258,6 +306,9 @@ func applyUserDefinedModel(body []byte, modelInfo *registry.ModelInfo, fromForma
 		config = parseSuffixToConfig(suffixResult.RawSuffix, toFormat, modelID)
 	} else {
 		config = extractThinkingConfig(body, toFormat)
+		if !hasThinkingConfig(config) {
+			config, _ = defaultThinkingConfig(modelInfo, toFormat)
+		}
 	}
 
 	if !hasThinkingConfig(config) {
@@ -0,0 +1,40 @@
+package thinking
+
+import "sync/atomic"
+
+var budgetMaxTokensRatio atomic.Value // float64
+
+// SetBudgetMaxTokensRatio caps thinking budgets at the given fraction of the request's
+// max_tokens for providers that require budget_tokens < max_tokens (Claude).
+// Values outside (0, 1) disable the fractional cap; budgets are then only kept strictly
+// below max_tokens.
+func SetBudgetMaxTokensRatio(ratio float64) {
+	if ratio <= 0 || ratio >= 1 {
+		ratio = 0
+	}
+	budgetMaxTokensRatio.Store(ratio)
+}
+
+// GetBudgetMaxTokensRatio returns the configured budget fraction of max_tokens, or 0 when unset.
+func GetBudgetMaxTokensRatio() float64 {
+	ratio, _ := budgetMaxTokensRatio.Load().(float64)
+	return ratio
+}
+
+// CapBudgetToMaxTokens returns budget reduced so it is strictly below maxTokens and, when
+// a ratio is configured, no larger than that fraction of maxTokens. A non-positive budget
+// or maxTokens is returned unchanged.
+func CapBudgetToMaxTokens(budget, maxTokens int) int {
+	if budget <= 0 || maxTokens <= 0 {
+		return budget
+	}
+	if ratio := GetBudgetMaxTokensRatio(); ratio > 0 {
+		if limit := int(float64(maxTokens) * ratio); limit > 0 && budget > limit {
+			budget = limit
+		}
+	}
+	if budget >= maxTokens {
+		budget = maxTokens - 1
+	}
+	return budget
+}
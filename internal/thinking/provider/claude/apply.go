@@ -9,6 +9,7 @@ package claude
 import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -95,8 +96,9 @@ func (a *Applier) normalizeClaudeBudget(body []byte, budgetTokens int, modelInfo
 
 	// Ensure the request satisfies Claude constraints:
 	//  1) Determine effective max_tokens (request overrides model default)
-	//  2) If budget_tokens >= max_tokens, reduce budget_tokens to max_tokens-1
-	//  3) If the adjusted budget falls below the model minimum, leave the request unchanged
+	//  2) Cap budget_tokens below max_tokens (and at the configured fraction of it, if any)
+	//  3) If the cap falls below the model minimum, use the minimum when it still fits
+	//     under max_tokens; otherwise leave the request unchanged
 	//  4) If max_tokens came from model default, write it back into the request
 
 	effectiveMax, setDefaultMax := a.effectiveMaxTokens(body, modelInfo)
@@ -104,29 +106,46 @@ func (a *Applier) normalizeClaudeBudget(body []byte, budgetTokens int, modelInfo
 		body, _ = sjson.SetBytes(body, "max_tokens", effectiveMax)
 	}
 
-	// Compute the budget we would apply after enforcing budget_tokens < max_tokens.
-	adjustedBudget := budgetTokens
-	if effectiveMax > 0 && adjustedBudget >= effectiveMax {
-		adjustedBudget = effectiveMax - 1
-	}
+	adjustedBudget := thinking.CapBudgetToMaxTokens(budgetTokens, effectiveMax)
 
 	minBudget := 0
 	if modelInfo != nil && modelInfo.Thinking != nil {
 		minBudget = modelInfo.Thinking.Min
 	}
 	if minBudget > 0 && adjustedBudget > 0 && adjustedBudget < minBudget {
-		// If enforcing the max_tokens constraint would push the budget below the model minimum,
-		// leave the request unchanged.
-		return body
+		if minBudget >= effectiveMax {
+			// Enforcing max_tokens would push the budget below the model minimum.
+			log.WithFields(log.Fields{
+				"model":      modelID(modelInfo),
+				"budget":     budgetTokens,
+				"max_tokens": effectiveMax,
+				"min":        minBudget,
+			}).Warn("thinking: max_tokens too small for the model minimum budget, leaving request unchanged |")
+			return body
+		}
+		adjustedBudget = minBudget
 	}
 
 	if adjustedBudget != budgetTokens {
+		log.WithFields(log.Fields{
+			"model":      modelID(modelInfo),
+			"original":   budgetTokens,
+			"clamped_to": adjustedBudget,
+			"max_tokens": effectiveMax,
+		}).Warn("thinking: budget clamped below max_tokens |")
 		body, _ = sjson.SetBytes(body, "thinking.budget_tokens", adjustedBudget)
 	}
 
 	return body
 }
 
+func modelID(modelInfo *registry.ModelInfo) string {
+	if modelInfo == nil {
+		return ""
+	}
+	return modelInfo.ID
+}
+
 // effectiveMaxTokens returns the max tokens to cap thinking:
 // prefer request-provided max_tokens; otherwise fall back to model default.
 // The boolean indicates whether the value came from the model default (and thus should be written back).
@@ -159,8 +178,19 @@ func applyCompatibleClaude(body []byte, config thinking.ThinkingConfig) ([]byte,
 		result, _ = sjson.DeleteBytes(result, "thinking.budget_tokens")
 		return result, nil
 	default:
+		budget := config.Budget
+		if maxTok := gjson.GetBytes(body, "max_tokens"); maxTok.Exists() && maxTok.Int() > 0 {
+			if capped := thinking.CapBudgetToMaxTokens(budget, int(maxTok.Int())); capped != budget {
+				log.WithFields(log.Fields{
+					"original":   budget,
+					"clamped_to": capped,
+					"max_tokens": maxTok.Int(),
+				}).Warn("thinking: budget clamped below max_tokens |")
+				budget = capped
+			}
+		}
 		result, _ := sjson.SetBytes(body, "thinking.type", "enabled")
-		result, _ = sjson.SetBytes(result, "thinking.budget_tokens", config.Budget)
+		result, _ = sjson.SetBytes(result, "thinking.budget_tokens", budget)
 		return result, nil
 	}
 }
@@ -0,0 +1,95 @@
+// Package thinking provides unified thinking configuration processing.
+//
+// This file implements a dry run of ApplyThinking that reports the effective
+// thinking configuration instead of a request body.
+package thinking
+
+import (
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+)
+
+// ResolvedThinking is a JSON-friendly view of a ThinkingConfig.
+type ResolvedThinking struct {
+	Mode   string `json:"mode"`
+	Level  string `json:"level,omitempty"`
+	Budget *int   `json:"budget,omitempty"`
+}
+
+// Resolution describes how thinking is resolved for a model on one provider.
+type Resolution struct {
+	// Model is the base model name without suffix.
+	Model string `json:"model"`
+	// Provider is the provider key used for the capability lookup and the target format.
+	Provider string `json:"provider"`
+	// Support is the model's thinking capability, nil when it does not support thinking.
+	Support *registry.ThinkingSupport `json:"thinking,omitempty"`
+	// UserDefined reports that the model skips capability validation.
+	UserDefined bool `json:"user_defined,omitempty"`
+	// Default is the configured default thinking value, if any.
+	Default string `json:"default,omitempty"`
+	// Suffix is the raw model suffix that was resolved, if any.
+	Suffix string `json:"suffix,omitempty"`
+	// Source is where the requested config came from: "suffix", "default" or "" when none applies.
+	Source string `json:"source,omitempty"`
+	// Requested is the config before validation and clamping.
+	Requested *ResolvedThinking `json:"requested,omitempty"`
+	// Resolved is the config ApplyThinking writes to the upstream request.
+	Resolved *ResolvedThinking `json:"resolved,omitempty"`
+}
+
+// ResolveThinking reports what ApplyThinking would do for model (optionally carrying a
+// thinking suffix) when sent to provider with no thinking parameters in the body.
+// It runs the real validation and provider applier against an empty request, so the
+// result includes clamping and provider-specific mapping. Validation errors are returned
+// alongside the partial Resolution.
+func ResolveThinking(model, provider string) (*Resolution, error) {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	suffixResult := ParseSuffix(strings.TrimSpace(model))
+	modelInfo := registry.LookupModelInfo(suffixResult.ModelName, provider)
+
+	res := &Resolution{
+		Model:       suffixResult.ModelName,
+		Provider:    provider,
+		UserDefined: IsUserDefinedModel(modelInfo),
+	}
+	if modelInfo != nil {
+		res.Support = modelInfo.Thinking
+		res.Default = strings.TrimSpace(modelInfo.DefaultThinking)
+	}
+
+	var requested ThinkingConfig
+	if suffixResult.HasSuffix {
+		res.Suffix = suffixResult.RawSuffix
+		requested = parseSuffixToConfig(suffixResult.RawSuffix, provider, model)
+		res.Source = "suffix"
+	} else if config, ok := defaultThinkingConfig(modelInfo, provider); ok {
+		requested = config
+		res.Source = "default"
+	}
+	if hasThinkingConfig(requested) {
+		res.Requested = newResolvedThinking(requested)
+	}
+
+	out, err := ApplyThinking([]byte(`{}`), model, provider, provider, provider)
+	if err != nil {
+		return res, err
+	}
+	if applied := extractThinkingConfig(out, provider); hasThinkingConfig(applied) {
+		res.Resolved = newResolvedThinking(applied)
+	}
+	return res, nil
+}
+
+func newResolvedThinking(config ThinkingConfig) *ResolvedThinking {
+	resolved := &ResolvedThinking{Mode: config.Mode.String()}
+	switch config.Mode {
+	case ModeLevel:
+		resolved.Level = string(config.Level)
+	case ModeBudget:
+		budget := config.Budget
+		resolved.Budget = &budget
+	}
+	return resolved
+}
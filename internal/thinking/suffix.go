@@ -7,8 +7,49 @@ package thinking
 import (
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"unicode"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 )
 
+// MalformedSuffixAction controls how ParseSuffix treats a model name whose suffix
+// cannot be parsed.
+type MalformedSuffixAction string
+
+const (
+	// MalformedSuffixIgnore parses a malformed suffix like any other (default), so
+	// "model(med ium)" routes as "model" with an unusable suffix.
+	MalformedSuffixIgnore MalformedSuffixAction = "ignore"
+	// MalformedSuffixStrip removes the malformed suffix and applies no thinking config.
+	MalformedSuffixStrip MalformedSuffixAction = "strip"
+	// MalformedSuffixError strips the suffix for routing and rejects the request in ApplyThinking.
+	MalformedSuffixError MalformedSuffixAction = "error"
+)
+
+var malformedSuffixAction atomic.Value
+
+// SetMalformedSuffixAction sets the global malformed suffix behavior.
+// Unknown or empty values fall back to MalformedSuffixIgnore.
+func SetMalformedSuffixAction(action string) {
+	switch MalformedSuffixAction(strings.ToLower(strings.TrimSpace(action))) {
+	case MalformedSuffixStrip:
+		malformedSuffixAction.Store(MalformedSuffixStrip)
+	case MalformedSuffixError:
+		malformedSuffixAction.Store(MalformedSuffixError)
+	default:
+		malformedSuffixAction.Store(MalformedSuffixIgnore)
+	}
+}
+
+// GetMalformedSuffixAction returns the current global malformed suffix behavior.
+func GetMalformedSuffixAction() MalformedSuffixAction {
+	if action, ok := malformedSuffixAction.Load().(MalformedSuffixAction); ok {
+		return action
+	}
+	return MalformedSuffixIgnore
+}
+
 // ParseSuffix extracts thinking suffix from a model name.
 //
 // The suffix format is: model-name(value)
@@ -17,10 +58,24 @@ import (
 //   - "gpt-5.2(high)" -> ModelName="gpt-5.2", RawSuffix="high"
 //   - "gemini-2.5-pro" -> ModelName="gemini-2.5-pro", HasSuffix=false
 //
+// A model registered under its full parenthesized name (e.g. "foo(bar)") carries no
+// suffix, and a name that would leave nothing before the parenthesis (e.g. "(high)")
+// is returned unchanged, so ModelName is never empty for a non-empty model.
+//
 // This function only extracts the suffix; it does not validate or interpret
 // the suffix content. Use ParseNumericSuffix, ParseLevelSuffix, etc. for
 // content interpretation.
 func ParseSuffix(model string) SuffixResult {
+	if !strings.ContainsAny(model, "()") || registry.LookupModelInfo(model) != nil {
+		return SuffixResult{ModelName: model, HasSuffix: false}
+	}
+	if GetMalformedSuffixAction() != MalformedSuffixIgnore && isMalformedSuffix(model) {
+		if result := parseMalformedSuffix(model); result.ModelName != "" {
+			return result
+		}
+		return SuffixResult{ModelName: model, HasSuffix: false}
+	}
+
 	// Find the last opening parenthesis
 	lastOpen := strings.LastIndex(model, "(")
 	if lastOpen == -1 {
@@ -35,6 +90,9 @@ func ParseSuffix(model string) SuffixResult {
 	// Extract components
 	modelName := model[:lastOpen]
 	rawSuffix := model[lastOpen+1 : len(model)-1]
+	if strings.TrimSpace(modelName) == "" {
+		return SuffixResult{ModelName: model, HasSuffix: false}
+	}
 
 	return SuffixResult{
 		ModelName: modelName,
@@ -144,3 +202,47 @@ func ParseLevelSuffix(rawSuffix string) (level ThinkingLevel, ok bool) {
 		return "", false
 	}
 }
+
+// isMalformedSuffix reports whether model carries a suffix that cannot be parsed:
+// unbalanced parentheses, or a trailing group that is nested or contains whitespace.
+// Parentheses in the middle of a name (e.g. "foo(bar)-baz") are not treated as a suffix, and
+// an empty trailing group keeps resolving to the bare model name.
+func isMalformedSuffix(model string) bool {
+	opens := strings.Count(model, "(")
+	closes := strings.Count(model, ")")
+	if opens == 0 && closes == 0 {
+		return false
+	}
+	if opens != closes {
+		return true
+	}
+	if !strings.HasSuffix(model, ")") {
+		return false
+	}
+	lastOpen := strings.LastIndex(model, "(")
+	if lastOpen == -1 {
+		return true
+	}
+	rawSuffix := model[lastOpen+1 : len(model)-1]
+	if strings.ContainsAny(rawSuffix, "()") {
+		return true
+	}
+	return strings.IndexFunc(rawSuffix, unicode.IsSpace) != -1
+}
+
+// parseMalformedSuffix strips the malformed suffix from model for the strip and error actions.
+func parseMalformedSuffix(model string) SuffixResult {
+	// Cut at the opening parenthesis of the trailing group, including any nested openers.
+	cut := strings.LastIndex(model, "(")
+	for cut > 0 && model[cut-1] == '(' {
+		cut--
+	}
+	if cut == -1 {
+		cut = strings.Index(model, ")")
+	}
+	return SuffixResult{
+		ModelName: strings.TrimSpace(model[:cut]),
+		RawSuffix: model[cut:],
+		Malformed: true,
+	}
+}
@@ -89,6 +89,11 @@ type SuffixResult struct {
 	// RawSuffix is the content inside the parentheses, without the parentheses.
 	// Empty string if HasSuffix is false.
 	RawSuffix string
+
+	// Malformed indicates the model name carried a suffix that could not be parsed,
+	// such as "model(med ium)" or "model(medium". How ModelName was derived depends
+	// on the configured MalformedSuffixAction.
+	Malformed bool
 }
 
 // ProviderApplier defines the interface for provider-specific thinking configuration application.
@@ -4,11 +4,48 @@ package thinking
 import (
 	"fmt"
 	"strings"
+	"sync/atomic"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	log "github.com/sirupsen/logrus"
 )
 
+// AutoFallbackPolicy controls what auto/-1 resolves to on models that do not allow
+// dynamic thinking.
+type AutoFallbackPolicy string
+
+const (
+	// AutoFallbackMid resolves to the medium level or mid-range budget (default).
+	AutoFallbackMid AutoFallbackPolicy = "mid"
+	// AutoFallbackMax resolves to the highest supported level or the maximum budget.
+	AutoFallbackMax AutoFallbackPolicy = "max"
+	// AutoFallbackMin resolves to the lowest supported level or the minimum budget.
+	AutoFallbackMin AutoFallbackPolicy = "min"
+)
+
+var autoFallbackPolicy atomic.Value
+
+// SetAutoFallbackPolicy sets the global auto fallback policy for non-dynamic models.
+// Unknown or empty values fall back to AutoFallbackMid.
+func SetAutoFallbackPolicy(policy string) {
+	switch AutoFallbackPolicy(strings.ToLower(strings.TrimSpace(policy))) {
+	case AutoFallbackMax:
+		autoFallbackPolicy.Store(AutoFallbackMax)
+	case AutoFallbackMin:
+		autoFallbackPolicy.Store(AutoFallbackMin)
+	default:
+		autoFallbackPolicy.Store(AutoFallbackMid)
+	}
+}
+
+// GetAutoFallbackPolicy returns the current global auto fallback policy.
+func GetAutoFallbackPolicy() AutoFallbackPolicy {
+	if policy, ok := autoFallbackPolicy.Load().(AutoFallbackPolicy); ok {
+		return policy
+	}
+	return AutoFallbackMid
+}
+
 // ValidateConfig validates a thinking configuration against model capabilities.
 //
 // This function performs comprehensive validation:
@@ -128,9 +165,9 @@ func ValidateConfig(config ThinkingConfig, modelInfo *registry.ModelInfo, fromFo
 		}
 	}
 
-	// Convert ModeAuto to mid-range if dynamic not allowed
+	// Convert ModeAuto to a fixed value if dynamic not allowed
 	if config.Mode == ModeAuto && !support.DynamicAllowed {
-		config = convertAutoToMidRange(config, support, toFormat, model)
+		config = convertAutoToFixed(config, support, toFormat, model)
 	}
 
 	if config.Mode == ModeNone && toFormat == "claude" {
@@ -154,52 +191,91 @@ func ValidateConfig(config ThinkingConfig, modelInfo *registry.ModelInfo, fromFo
 	return &config, nil
 }
 
-// convertAutoToMidRange converts ModeAuto to a mid-range value when dynamic is not allowed.
+// convertAutoToFixed converts ModeAuto to a fixed value when dynamic is not allowed.
 //
 // This function handles the case where a model does not support dynamic/auto thinking.
-// The auto mode is silently converted to a fixed value based on model capability:
-//   - Level-only models: convert to ModeLevel with LevelMedium
-//   - Budget models: convert to ModeBudget with mid = (Min + Max) / 2
+// The auto mode is silently converted according to the global AutoFallbackPolicy:
+//   - Level-only models: convert to ModeLevel with medium (mid), the highest (max)
+//     or the lowest (min) supported level
+//   - Budget models: convert to ModeBudget with (Min + Max) / 2 (mid), Max (max) or Min (min)
 //
 // Logging:
 //   - Debug level when conversion occurs
-//   - Fields: original_mode, clamped_to, reason
-func convertAutoToMidRange(config ThinkingConfig, support *registry.ThinkingSupport, provider, model string) ThinkingConfig {
-	// For level-only models (has Levels but no Min/Max range), use ModeLevel with medium
+//   - Fields: original_mode, clamped_to, policy
+func convertAutoToFixed(config ThinkingConfig, support *registry.ThinkingSupport, provider, model string) ThinkingConfig {
+	policy := GetAutoFallbackPolicy()
+
+	// For level-only models (has Levels but no Min/Max range), use ModeLevel
 	if len(support.Levels) > 0 && support.Min == 0 && support.Max == 0 {
+		level := LevelMedium
+		switch policy {
+		case AutoFallbackMax:
+			level = extremeSupportedLevel(support.Levels, true)
+		case AutoFallbackMin:
+			level = extremeSupportedLevel(support.Levels, false)
+		}
 		config.Mode = ModeLevel
-		config.Level = LevelMedium
+		config.Level = level
 		config.Budget = 0
 		log.WithFields(log.Fields{
 			"provider":      provider,
 			"model":         model,
 			"original_mode": "auto",
-			"clamped_to":    string(LevelMedium),
-		}).Debug("thinking: mode converted, dynamic not allowed, using medium level |")
+			"clamped_to":    string(level),
+			"policy":        string(policy),
+		}).Debug("thinking: mode converted, dynamic not allowed, using fixed level |")
 		return config
 	}
 
-	// For budget models, use mid-range budget
-	mid := (support.Min + support.Max) / 2
-	if mid <= 0 && support.ZeroAllowed {
+	// For budget models, use a fixed budget
+	target := (support.Min + support.Max) / 2
+	switch policy {
+	case AutoFallbackMax:
+		target = support.Max
+	case AutoFallbackMin:
+		target = support.Min
+	}
+	if target <= 0 && support.ZeroAllowed {
 		config.Mode = ModeNone
 		config.Budget = 0
-	} else if mid <= 0 {
+	} else if target <= 0 {
 		config.Mode = ModeBudget
 		config.Budget = support.Min
 	} else {
 		config.Mode = ModeBudget
-		config.Budget = mid
+		config.Budget = target
 	}
 	log.WithFields(log.Fields{
 		"provider":      provider,
 		"model":         model,
 		"original_mode": "auto",
 		"clamped_to":    config.Budget,
+		"policy":        string(policy),
 	}).Debug("thinking: mode converted, dynamic not allowed |")
 	return config
 }
 
+// extremeSupportedLevel returns the highest or lowest standard level in levels, falling
+// back to medium when none of them is a standard level.
+func extremeSupportedLevel(levels []string, highest bool) ThinkingLevel {
+	found := ThinkingLevel("")
+	for _, level := range standardLevelOrder {
+		for _, candidate := range levels {
+			if !strings.EqualFold(strings.TrimSpace(candidate), string(level)) {
+				continue
+			}
+			if !highest {
+				return level
+			}
+			found = level
+		}
+	}
+	if found == "" {
+		return LevelMedium
+	}
+	return found
+}
+
 // standardLevelOrder defines the canonical ordering of thinking levels from lowest to highest.
 var standardLevelOrder = []ThinkingLevel{LevelMinimal, LevelLow, LevelMedium, LevelHigh, LevelXHigh}
 
@@ -0,0 +1,146 @@
+// Package tokenize provides a provider-agnostic token count estimate for requests whose
+// upstream has no native token counting. Results are approximations and must be reported
+// to clients as estimates.
+package tokenize
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tidwall/gjson"
+)
+
+// charsPerToken is the average number of characters per token for Latin-script text
+// across common BPE vocabularies.
+const charsPerToken = 4
+
+// inlineBinaryTokens is the flat estimate for one inline binary attachment (a base64 image,
+// audio clip or file), roughly what providers charge for a high-detail image. Estimating
+// the encoded text instead would count megabytes of base64 as hundreds of thousands of tokens.
+const inlineBinaryTokens = 1000
+
+// minInlineBinaryLen is the shortest unbroken base64 run treated as binary data when the
+// value is not a data URL.
+const minInlineBinaryLen = 256
+
+// Estimate returns an approximate token count for text.
+// Latin-script words count one token per four characters (at least one per word),
+// CJK and other wide characters count one token each, and every punctuation or
+// symbol character counts as its own token.
+func Estimate(text string) int64 {
+	var tokens int64
+	wordLen := 0
+	flushWord := func() {
+		if wordLen > 0 {
+			tokens += int64((wordLen + charsPerToken - 1) / charsPerToken)
+			wordLen = 0
+		}
+	}
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			flushWord()
+		case isWide(r):
+			flushWord()
+			tokens++
+		case unicode.IsLetter(r), unicode.IsDigit(r):
+			wordLen++
+		default:
+			flushWord()
+			tokens++
+		}
+	}
+	flushWord()
+	return tokens
+}
+
+// nonContentFields lists the object keys whose string values describe the request rather
+// than carry prompt content: model names, roles, block types, identifiers and media types.
+var nonContentFields = map[string]struct{}{
+	"model":         {},
+	"role":          {},
+	"type":          {},
+	"object":        {},
+	"id":            {},
+	"call_id":       {},
+	"tool_call_id":  {},
+	"tool_use_id":   {},
+	"media_type":    {},
+	"mime_type":     {},
+	"mimeType":      {},
+	"stop_reason":   {},
+	"finish_reason": {},
+}
+
+// EstimateJSON returns an approximate token count for a JSON request body by summing
+// the estimates of its content string values. Keys, structure and the values of
+// nonContentFields are ignored so the result tracks the prompt content rather than the
+// request schema. Inline binary data
+// (data URLs and long base64 values such as Claude or Gemini image sources) counts as a
+// flat inlineBinaryTokens per value. Invalid JSON is estimated as plain text.
+func EstimateJSON(payload []byte) int64 {
+	if len(payload) == 0 {
+		return 0
+	}
+	if !gjson.ValidBytes(payload) {
+		return Estimate(string(payload))
+	}
+	var tokens int64
+	var walk func(gjson.Result)
+	walk = func(node gjson.Result) {
+		switch {
+		case node.IsObject():
+			node.ForEach(func(key, value gjson.Result) bool {
+				if _, skip := nonContentFields[key.String()]; !skip {
+					walk(value)
+				}
+				return true
+			})
+		case node.IsArray():
+			node.ForEach(func(_, value gjson.Result) bool {
+				walk(value)
+				return true
+			})
+		case node.Type == gjson.String:
+			if text := node.String(); isInlineBinary(text) {
+				tokens += inlineBinaryTokens
+			} else {
+				tokens += Estimate(text)
+			}
+		}
+	}
+	walk(gjson.ParseBytes(payload))
+	return tokens
+}
+
+// isInlineBinary reports whether value is a base64 data URL or an unbroken base64 run of
+// at least minInlineBinaryLen characters.
+func isInlineBinary(value string) bool {
+	if strings.HasPrefix(value, "data:") {
+		if comma := strings.IndexByte(value, ','); comma > 0 && strings.HasSuffix(value[:comma], ";base64") {
+			return true
+		}
+	}
+	if len(value) < minInlineBinaryLen {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		case c == '+', c == '/', c == '=', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isWide reports whether r belongs to a script that tokenizers typically split per character.
+func isWide(r rune) bool {
+	if r < utf8.RuneSelf {
+		return false
+	}
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul, unicode.Thai)
+}
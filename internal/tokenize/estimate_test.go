@@ -0,0 +1,47 @@
+package tokenize
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimate_Stable(t *testing.T) {
+	const text = "The quick brown fox jumps over the lazy dog. 你好世界!"
+
+	first := Estimate(text)
+	for i := 0; i < 5; i++ {
+		if got := Estimate(text); got != first {
+			t.Fatalf("estimate changed between runs: %d != %d", got, first)
+		}
+	}
+	// 9 words (12 word tokens), 2 punctuation tokens, 4 CJK tokens.
+	if first != 18 {
+		t.Fatalf("Estimate = %d, want 18", first)
+	}
+}
+
+func TestEstimateJSON_CountsContentValuesOnly(t *testing.T) {
+	payload := []byte(`{"model":"gemini-2.5-pro","messages":[{"role":"user","content":[{"type":"text","text":"hello world"}]},{"role":"tool","tool_call_id":"call_1","content":"42"}],"max_tokens":100}`)
+	want := Estimate("hello world") + Estimate("42")
+	if got := EstimateJSON(payload); got != want {
+		t.Fatalf("EstimateJSON = %d, want %d", got, want)
+	}
+	if got := EstimateJSON(nil); got != 0 {
+		t.Fatalf("EstimateJSON(nil) = %d, want 0", got)
+	}
+}
+
+func TestEstimateJSON_InlineBinaryIsFlat(t *testing.T) {
+	base64Data := strings.Repeat("iVBORw0KGgoAAAANSUhEUgAA", 40000)
+	payloads := map[string]string{
+		"openai data url": `{"messages":[{"role":"user","content":[{"type":"text","text":"describe"},{"type":"image_url","image_url":{"url":"data:image/png;base64,` + base64Data + `"}}]}]}`,
+		"claude source":   `{"messages":[{"role":"user","content":[{"type":"text","text":"describe"},{"type":"image","source":{"type":"base64","media_type":"image/png","data":"` + base64Data + `"}}]}]}`,
+		"gemini inline":   `{"contents":[{"role":"user","parts":[{"text":"describe"},{"inlineData":{"mimeType":"image/png","data":"` + base64Data + `"}}]}]}`,
+	}
+	for name, payload := range payloads {
+		got := EstimateJSON([]byte(payload))
+		if got < inlineBinaryTokens || got > inlineBinaryTokens+20 {
+			t.Errorf("%s: EstimateJSON = %d, want about %d", name, got, inlineBinaryTokens)
+		}
+	}
+}
@@ -15,8 +15,10 @@ import (
 	"time"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/cache"
+	translatorcommon "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/common"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -25,20 +27,21 @@ import (
 // This structure tracks the current state of the response translation process to ensure
 // proper sequencing of SSE events and transitions between different content types.
 type Params struct {
-	HasFirstResponse     bool   // Indicates if the initial message_start event has been sent
-	ResponseType         int    // Current response type: 0=none, 1=content, 2=thinking, 3=function
-	ResponseIndex        int    // Index counter for content blocks in the streaming response
-	HasFinishReason      bool   // Tracks whether a finish reason has been observed
-	FinishReason         string // The finish reason string returned by the provider
-	HasUsageMetadata     bool   // Tracks whether usage metadata has been observed
-	PromptTokenCount     int64  // Cached prompt token count from usage metadata
-	CandidatesTokenCount int64  // Cached candidate token count from usage metadata
-	ThoughtsTokenCount   int64  // Cached thinking token count from usage metadata
-	TotalTokenCount      int64  // Cached total token count from usage metadata
-	CachedTokenCount     int64  // Cached content token count (indicates prompt caching)
-	HasSentFinalEvents   bool   // Indicates if final content/message events have been sent
-	HasToolUse           bool   // Indicates if tool use was observed in the stream
-	HasContent           bool   // Tracks whether any content (text, thinking, or tool use) has been output
+	HasFirstResponse     bool         // Indicates if the initial message_start event has been sent
+	ResponseType         int          // Current response type: 0=none, 1=content, 2=thinking, 3=function
+	ResponseIndex        int          // Index counter for content blocks in the streaming response
+	HasFinishReason      bool         // Tracks whether a finish reason has been observed
+	FinishReason         string       // The finish reason string returned by the provider
+	HasUsageMetadata     bool         // Tracks whether usage metadata has been observed
+	PromptTokenCount     int64        // Cached prompt token count from usage metadata
+	CandidatesTokenCount int64        // Cached candidate token count from usage metadata
+	ThoughtsTokenCount   int64        // Cached thinking token count from usage metadata
+	TotalTokenCount      int64        // Cached total token count from usage metadata
+	CachedTokenCount     int64        // Cached content token count (indicates prompt caching)
+	HasSentFinalEvents   bool         // Indicates if final content/message events have been sent
+	HasToolUse           bool         // Indicates if tool use was observed in the stream
+	HasContent           bool         // Tracks whether any content (text, thinking, or tool use) has been output
+	AvgLogprobs          gjson.Result // Candidate avgLogprobs from the finishing chunk, surfaced when configured
 
 	// Signature caching support
 	CurrentThinkingText strings.Builder // Accumulates thinking text for signature caching
@@ -63,7 +66,7 @@ var toolUseIDCounter uint64
 //
 // Returns:
 //   - []string: A slice of strings, each containing a Claude Code-compatible JSON response
-func ConvertAntigravityResponseToClaude(_ context.Context, _ string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) []string {
+func ConvertAntigravityResponseToClaude(ctx context.Context, model string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) []string {
 	if *param == nil {
 		*param = &Params{
 			HasFirstResponse: false,
@@ -87,6 +90,14 @@ func ConvertAntigravityResponseToClaude(_ context.Context, _ string, originalReq
 		return []string{}
 	}
 
+	chunk, out, done := translatorcommon.TranslateCLIChunks(rawJSON, func(chunk []byte) []string {
+		return ConvertAntigravityResponseToClaude(ctx, model, originalRequestRawJSON, requestRawJSON, chunk, param)
+	})
+	if done {
+		return out
+	}
+	rawJSON = chunk
+
 	output := ""
 
 	// Initialize the streaming session with a message_start event
@@ -274,6 +285,7 @@ func ConvertAntigravityResponseToClaude(_ context.Context, _ string, originalReq
 	if finishReasonResult := gjson.GetBytes(rawJSON, "response.candidates.0.finishReason"); finishReasonResult.Exists() {
 		params.HasFinishReason = true
 		params.FinishReason = finishReasonResult.String()
+		params.AvgLogprobs = gjson.GetBytes(rawJSON, "response.candidates.0.avgLogprobs")
 	}
 
 	if usageResult := gjson.GetBytes(rawJSON, "response.usageMetadata"); usageResult.Exists() {
@@ -339,6 +351,7 @@ func appendFinalEvents(params *Params, output *string, force bool) {
 			log.Warnf("antigravity claude response: failed to set cache_read_input_tokens: %v", err)
 		}
 	}
+	delta = util.SetClaudeAvgLogprobs(delta, params.AvgLogprobs)
 	*output = *output + delta + "\n\n\n"
 
 	params.HasSentFinalEvents = true
@@ -373,7 +386,7 @@ func ConvertAntigravityResponseToClaudeNonStream(_ context.Context, _ string, or
 	_ = originalRequestRawJSON
 	modelName := gjson.GetBytes(requestRawJSON, "model").String()
 
-	root := gjson.ParseBytes(rawJSON)
+	root := gjson.ParseBytes(util.WrapCLIResponse(rawJSON))
 	promptTokens := root.Get("response.usageMetadata.promptTokenCount").Int()
 	candidateTokens := root.Get("response.usageMetadata.candidatesTokenCount").Int()
 	thoughtTokens := root.Get("response.usageMetadata.thoughtsTokenCount").Int()
@@ -514,6 +527,7 @@ func ConvertAntigravityResponseToClaudeNonStream(_ context.Context, _ string, or
 			responseJSON, _ = sjson.Delete(responseJSON, "usage")
 		}
 	}
+	responseJSON = util.SetClaudeAvgLogprobs(responseJSON, root.Get("response.candidates.0.avgLogprobs"))
 
 	return responseJSON
 }
@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/cache"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/tidwall/gjson"
 )
 
 // ============================================================================
@@ -244,3 +246,28 @@ func TestConvertAntigravityResponseToClaude_MultipleThinkingBlocks(t *testing.T)
 		t.Error("Second thinking block signature should be cached")
 	}
 }
+
+func TestConvertAntigravityResponseToClaude_AvgLogprobs(t *testing.T) {
+	util.SetClaudeAvgLogprobsLocation(util.AvgLogprobsUsage)
+	t.Cleanup(func() { util.SetClaudeAvgLogprobsLocation("") })
+
+	response := []byte(`{"response":{"candidates":[{"content":{"role":"model","parts":[{"text":"hi"}]},"finishReason":"STOP","avgLogprobs":-0.25}],"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":1,"totalTokenCount":4}}}`)
+
+	out := ConvertAntigravityResponseToClaudeNonStream(context.Background(), "", nil, nil, response, nil)
+	if got := gjson.Get(out, "usage.avg_logprobs"); got.Float() != -0.25 {
+		t.Fatalf("non-stream: expected usage.avg_logprobs = -0.25, got %s", out)
+	}
+
+	var param any
+	chunks := ConvertAntigravityResponseToClaude(context.Background(), "", []byte(`{}`), nil, response, &param)
+	stream := strings.Join(chunks, "")
+	var delta string
+	for _, line := range strings.Split(stream, "\n") {
+		if strings.HasPrefix(line, "data: ") && strings.Contains(line, `"message_delta"`) {
+			delta = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	if got := gjson.Get(delta, "usage.avg_logprobs"); got.Float() != -0.25 {
+		t.Fatalf("stream: expected message_delta usage.avg_logprobs = -0.25, got %s", stream)
+	}
+}
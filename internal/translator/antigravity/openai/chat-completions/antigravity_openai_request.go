@@ -34,6 +34,11 @@ func ConvertOpenAIRequestToAntigravity(modelName string, inputRawJSON []byte, _
 	// Model
 	out, _ = sjson.SetBytes(out, "model", modelName)
 
+	// Context caching: reference a previously created cachedContents/<id> handle.
+	if cc := gjson.GetBytes(rawJSON, "cached_content"); cc.Type == gjson.String && strings.TrimSpace(cc.String()) != "" {
+		out, _ = sjson.SetBytes(out, "request.cachedContent", strings.TrimSpace(cc.String()))
+	}
+
 	// Apply thinking configuration: convert OpenAI reasoning_effort to Gemini CLI thinkingConfig.
 	// Inline translation-only mapping; capability checks happen later in ApplyThinking.
 	re := gjson.GetBytes(rawJSON, "reasoning_effort")
@@ -72,6 +77,14 @@ func ConvertOpenAIRequestToAntigravity(modelName string, inputRawJSON []byte, _
 		}
 	}
 
+	// OpenAI logprobs/top_logprobs -> request.generationConfig.responseLogprobs/logprobs
+	if lp := gjson.GetBytes(rawJSON, "logprobs"); lp.Type == gjson.True {
+		out, _ = sjson.SetBytes(out, "request.generationConfig.responseLogprobs", true)
+		if top := gjson.GetBytes(rawJSON, "top_logprobs"); top.Exists() && top.Type == gjson.Number && top.Int() > 0 {
+			out, _ = sjson.SetBytes(out, "request.generationConfig.logprobs", top.Int())
+		}
+	}
+
 	// Map OpenAI modalities -> Gemini CLI request.generationConfig.responseModalities
 	// e.g. "modalities": ["image", "text"] -> ["IMAGE", "TEXT"]
 	if mods := gjson.GetBytes(rawJSON, "modalities"); mods.Exists() && mods.IsArray() {
@@ -410,7 +423,7 @@ func ConvertOpenAIRequestToAntigravity(modelName string, inputRawJSON []byte, _
 		}
 	}
 
-	return common.AttachDefaultSafetySettings(out, "request.safetySettings")
+	return common.ApplySafetySettings(out, rawJSON, "request.safetySettings")
 }
 
 // itoa converts int to string without strconv import for few usages.
@@ -15,7 +15,10 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	translatorcommon "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/common"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/common"
 	. "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/openai/chat-completions"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -45,7 +48,7 @@ var functionCallIDCounter uint64
 //
 // Returns:
 //   - []string: A slice of strings, each containing an OpenAI-compatible JSON response
-func ConvertAntigravityResponseToOpenAI(_ context.Context, _ string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) []string {
+func ConvertAntigravityResponseToOpenAI(ctx context.Context, modelName string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) []string {
 	if *param == nil {
 		*param = &convertCliResponseToOpenAIChatParams{
 			UnixTimestamp: 0,
@@ -57,6 +60,14 @@ func ConvertAntigravityResponseToOpenAI(_ context.Context, _ string, originalReq
 		return []string{}
 	}
 
+	chunk, out, done := translatorcommon.TranslateCLIChunks(rawJSON, func(chunk []byte) []string {
+		return ConvertAntigravityResponseToOpenAI(ctx, modelName, originalRequestRawJSON, requestRawJSON, chunk, param)
+	})
+	if done {
+		return out
+	}
+	rawJSON = chunk
+
 	// Initialize the OpenAI SSE template.
 	template := `{"id":"","object":"chat.completion.chunk","created":12345,"model":"model","choices":[{"index":0,"delta":{"role":null,"content":null,"reasoning_content":null,"tool_calls":null},"finish_reason":null,"native_finish_reason":null}]}`
 
@@ -195,6 +206,12 @@ func ConvertAntigravityResponseToOpenAI(_ context.Context, _ string, originalReq
 		}
 	}
 
+	if common.LogprobsRequested(originalRequestRawJSON) {
+		if logprobs, ok := common.OpenAILogprobs(gjson.GetBytes(rawJSON, "response.candidates.0")); ok {
+			template, _ = sjson.SetRaw(template, "choices.0.logprobs", logprobs)
+		}
+	}
+
 	// Determine finish_reason only on the final chunk (has both finishReason and usage metadata)
 	params := (*param).(*convertCliResponseToOpenAIChatParams)
 	upstreamFinishReason := params.UpstreamFinishReason
@@ -204,13 +221,11 @@ func ConvertAntigravityResponseToOpenAI(_ context.Context, _ string, originalReq
 	isFinalChunk := upstreamFinishReason != "" && usageExists
 
 	if isFinalChunk {
-		var finishReason string
+		finishReason := "stop"
 		if sawToolCall {
 			finishReason = "tool_calls"
-		} else if upstreamFinishReason == "MAX_TOKENS" {
-			finishReason = "max_tokens"
-		} else {
-			finishReason = "stop"
+		} else if mapped := common.OpenAIFinishReason(upstreamFinishReason); mapped == "length" || mapped == "content_filter" {
+			finishReason = mapped
 		}
 		template, _ = sjson.Set(template, "choices.0.finish_reason", finishReason)
 		template, _ = sjson.Set(template, "choices.0.native_finish_reason", strings.ToLower(upstreamFinishReason))
@@ -233,7 +248,7 @@ func ConvertAntigravityResponseToOpenAI(_ context.Context, _ string, originalReq
 // Returns:
 //   - string: An OpenAI-compatible JSON response containing all message content and metadata
 func ConvertAntigravityResponseToOpenAINonStream(ctx context.Context, modelName string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) string {
-	responseResult := gjson.GetBytes(rawJSON, "response")
+	responseResult := gjson.GetBytes(util.WrapCLIResponse(rawJSON), "response")
 	if responseResult.Exists() {
 		return ConvertGeminiResponseToOpenAINonStream(ctx, modelName, originalRequestRawJSON, requestRawJSON, []byte(responseResult.Raw), param)
 	}
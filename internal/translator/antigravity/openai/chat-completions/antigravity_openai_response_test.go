@@ -76,10 +76,27 @@ func TestFinishReasonMaxTokens(t *testing.T) {
 	chunk2 := []byte(`{"response":{"candidates":[{"finishReason":"MAX_TOKENS"}],"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":100,"totalTokenCount":110}}}`)
 	result2 := ConvertAntigravityResponseToOpenAI(ctx, "model", nil, nil, chunk2, &param)
 
-	// Verify finish_reason is "max_tokens"
+	// Verify finish_reason is OpenAI's "length"
 	fr := gjson.Get(result2[0], "choices.0.finish_reason").String()
-	if fr != "max_tokens" {
-		t.Errorf("Expected finish_reason 'max_tokens', got: %s", fr)
+	if fr != "length" {
+		t.Errorf("Expected finish_reason 'length', got: %s", fr)
+	}
+}
+
+func TestFinishReasonContentFilter(t *testing.T) {
+	ctx := context.Background()
+	var param any
+
+	chunk1 := []byte(`{"response":{"candidates":[{"content":{"parts":[{"text":"Hello"}]}}]}}`)
+	ConvertAntigravityResponseToOpenAI(ctx, "model", nil, nil, chunk1, &param)
+
+	// Final chunk blocked for safety
+	chunk2 := []byte(`{"response":{"candidates":[{"finishReason":"SAFETY"}],"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":1,"totalTokenCount":11}}}`)
+	result2 := ConvertAntigravityResponseToOpenAI(ctx, "model", nil, nil, chunk2, &param)
+
+	fr := gjson.Get(result2[0], "choices.0.finish_reason").String()
+	if fr != "content_filter" {
+		t.Errorf("Expected finish_reason 'content_filter', got: %s", fr)
 	}
 }
 
@@ -95,7 +112,7 @@ func TestToolCallTakesPriorityOverMaxTokens(t *testing.T) {
 	chunk2 := []byte(`{"response":{"candidates":[{"finishReason":"MAX_TOKENS"}],"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":100,"totalTokenCount":110}}}`)
 	result2 := ConvertAntigravityResponseToOpenAI(ctx, "model", nil, nil, chunk2, &param)
 
-	// Verify finish_reason is "tool_calls" (takes priority over max_tokens)
+	// Verify finish_reason is "tool_calls" (takes priority over length)
 	fr := gjson.Get(result2[0], "choices.0.finish_reason").String()
 	if fr != "tool_calls" {
 		t.Errorf("Expected finish_reason 'tool_calls', got: %s", fr)
@@ -126,3 +143,26 @@ func TestNoFinishReasonOnIntermediateChunks(t *testing.T) {
 		t.Errorf("Expected no finish_reason on intermediate chunk, got: %v", fr2)
 	}
 }
+
+func TestConvertAntigravityResponseToOpenAI_StreamCarriesLogprobs(t *testing.T) {
+	chunk := []byte(`{"response":{"candidates":[{"content":{"role":"model","parts":[{"text":"Hi"}]},"avgLogprobs":-0.25,"logprobsResult":{"chosenCandidates":[{"token":"Hi","logProbability":-0.25}],"topCandidates":[{"candidates":[{"token":"Hi","logProbability":-0.25}]}]}}]}}`)
+
+	var param any
+	out := ConvertAntigravityResponseToOpenAI(context.Background(), "gemini-2.5-pro", []byte(`{"logprobs":true}`), nil, chunk, &param)
+	if len(out) != 1 {
+		t.Fatalf("got %d chunks, want 1", len(out))
+	}
+	logprobs := gjson.Get(out[0], "choices.0.logprobs")
+	if got := logprobs.Get("content.0.token").String(); got != "Hi" {
+		t.Fatalf("logprobs token = %q, want Hi: %s", got, out[0])
+	}
+	if got := logprobs.Get("avg_logprob").Float(); got != -0.25 {
+		t.Fatalf("avg_logprob = %v, want -0.25", got)
+	}
+
+	var plainParam any
+	out = ConvertAntigravityResponseToOpenAI(context.Background(), "gemini-2.5-pro", []byte(`{}`), nil, chunk, &plainParam)
+	if gjson.Get(out[0], "choices.0.logprobs").Exists() {
+		t.Fatalf("logprobs should be omitted when not requested: %s", out[0])
+	}
+}
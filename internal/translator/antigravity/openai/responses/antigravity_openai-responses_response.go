@@ -3,11 +3,20 @@ package responses
 import (
 	"context"
 
+	translatorcommon "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/common"
 	. "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/openai/responses"
 	"github.com/tidwall/gjson"
 )
 
 func ConvertAntigravityResponseToOpenAIResponses(ctx context.Context, modelName string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) []string {
+	chunk, out, done := translatorcommon.TranslateCLIChunks(rawJSON, func(chunk []byte) []string {
+		return ConvertAntigravityResponseToOpenAIResponses(ctx, modelName, originalRequestRawJSON, requestRawJSON, chunk, param)
+	})
+	if done {
+		return out
+	}
+	rawJSON = chunk
+
 	responseResult := gjson.GetBytes(rawJSON, "response")
 	if responseResult.Exists() {
 		rawJSON = []byte(responseResult.Raw)
@@ -22,12 +31,12 @@ func ConvertAntigravityResponseToOpenAIResponsesNonStream(ctx context.Context, m
 	}
 
 	requestResult := gjson.GetBytes(originalRequestRawJSON, "request")
-	if responseResult.Exists() {
+	if requestResult.Exists() {
 		originalRequestRawJSON = []byte(requestResult.Raw)
 	}
 
 	requestResult = gjson.GetBytes(requestRawJSON, "request")
-	if responseResult.Exists() {
+	if requestResult.Exists() {
 		requestRawJSON = []byte(requestResult.Raw)
 	}
 
@@ -0,0 +1,27 @@
+package common
+
+import (
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// claudeMaxTemperature is the upper bound of Claude's temperature range. OpenAI and
+// Gemini accept up to 2.
+const claudeMaxTemperature = 1.0
+
+// SetSamplingParams maps sampling parameters from another format onto a Claude request.
+// Temperature is clamped to Claude's [0, 1] range. Claude rejects requests that set both
+// temperature and top_p, so top_p is only kept when temperature is absent. top_k is kept
+// when it is a positive integer. Missing or non-numeric values are skipped; parameters
+// Claude has no equivalent for (penalties, seed) are never passed.
+func SetSamplingParams(out string, temperature, topP, topK gjson.Result) string {
+	if temperature.Type == gjson.Number {
+		out, _ = sjson.Set(out, "temperature", min(max(temperature.Float(), 0), claudeMaxTemperature))
+	} else if topP.Type == gjson.Number {
+		out, _ = sjson.Set(out, "top_p", min(max(topP.Float(), 0), 1))
+	}
+	if topK.Type == gjson.Number && topK.Int() > 0 {
+		out, _ = sjson.Set(out, "top_k", topK.Int())
+	}
+	return out
+}
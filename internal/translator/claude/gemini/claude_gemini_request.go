@@ -15,6 +15,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/claude/common"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
@@ -36,6 +37,7 @@ var (
 // 4. Tool call and tool result handling with FIFO queue for ID matching
 // 5. Image and file data conversion to Claude Code base64 format
 // 6. Tool declaration and tool choice configuration mapping
+// 7. Prompt-caching "cache_control" markers on text parts and system_instruction
 //
 // Parameters:
 //   - modelName: The name of the model to use for the request
@@ -94,13 +96,8 @@ func ConvertGeminiRequestToClaude(modelName string, inputRawJSON []byte, stream
 		if maxTokens := genConfig.Get("maxOutputTokens"); maxTokens.Exists() {
 			out, _ = sjson.Set(out, "max_tokens", maxTokens.Int())
 		}
-		// Temperature setting for controlling response randomness
-		if temp := genConfig.Get("temperature"); temp.Exists() {
-			out, _ = sjson.Set(out, "temperature", temp.Float())
-		} else if topP := genConfig.Get("topP"); topP.Exists() {
-			// Top P setting for nucleus sampling (filtered out if temperature is set)
-			out, _ = sjson.Set(out, "top_p", topP.Float())
-		}
+		// Sampling parameters; penalties and seed have no Claude equivalent and are dropped
+		out = common.SetSamplingParams(out, genConfig.Get("temperature"), genConfig.Get("topP"), genConfig.Get("topK"))
 		// Stop sequences configuration for custom termination conditions
 		if stopSeqs := genConfig.Get("stopSequences"); stopSeqs.Exists() && stopSeqs.IsArray() {
 			var stopSequences []string
@@ -166,12 +163,16 @@ func ConvertGeminiRequestToClaude(modelName string, inputRawJSON []byte, stream
 	if sysInstr := root.Get("system_instruction"); sysInstr.Exists() {
 		if parts := sysInstr.Get("parts"); parts.Exists() && parts.IsArray() {
 			var systemText strings.Builder
+			cacheControl := sysInstr.Get("cache_control")
 			parts.ForEach(func(_, part gjson.Result) bool {
 				if text := part.Get("text"); text.Exists() {
 					if systemText.Len() > 0 {
 						systemText.WriteString("\n")
 					}
 					systemText.WriteString(text.String())
+					if !cacheControl.IsObject() {
+						cacheControl = part.Get("cache_control")
+					}
 				}
 				return true
 			})
@@ -179,6 +180,10 @@ func ConvertGeminiRequestToClaude(modelName string, inputRawJSON []byte, stream
 				// Create system message in Claude Code format
 				systemMessage := `{"role":"user","content":[{"type":"text","text":""}]}`
 				systemMessage, _ = sjson.Set(systemMessage, "content.0.text", systemText.String())
+				if cacheControl.IsObject() {
+					// The parts are merged into one block, so any cached part caches the whole system prompt.
+					systemMessage, _ = sjson.SetRaw(systemMessage, "content.0.cache_control", cacheControl.Raw)
+				}
 				out, _ = sjson.SetRaw(out, "messages.-1", systemMessage)
 			}
 		}
@@ -211,6 +216,9 @@ func ConvertGeminiRequestToClaude(modelName string, inputRawJSON []byte, stream
 					if text := part.Get("text"); text.Exists() {
 						textContent := `{"type":"text","text":""}`
 						textContent, _ = sjson.Set(textContent, "text", text.String())
+						if cacheControl := part.Get("cache_control"); cacheControl.IsObject() {
+							textContent, _ = sjson.SetRaw(textContent, "cache_control", cacheControl.Raw)
+						}
 						msg, _ = sjson.SetRaw(msg, "content.-1", textContent)
 						return true
 					}
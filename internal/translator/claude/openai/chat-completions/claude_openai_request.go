@@ -15,6 +15,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/claude/common"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -34,6 +35,7 @@ var (
 // 3. Tool call and tool result handling with proper ID mapping
 // 4. Image data conversion from OpenAI data URLs to Claude Code base64 format
 // 5. Stop sequence and streaming configuration handling
+// 6. Prompt-caching "cache_control" markers on text parts and messages
 //
 // Parameters:
 //   - modelName: The name of the model to use for the request
@@ -106,13 +108,8 @@ func ConvertOpenAIRequestToClaude(modelName string, inputRawJSON []byte, stream
 		out, _ = sjson.Set(out, "max_tokens", maxTokens.Int())
 	}
 
-	// Temperature setting for controlling response randomness
-	if temp := root.Get("temperature"); temp.Exists() {
-		out, _ = sjson.Set(out, "temperature", temp.Float())
-	} else if topP := root.Get("top_p"); topP.Exists() {
-		// Top P setting for nucleus sampling (filtered out if temperature is set)
-		out, _ = sjson.Set(out, "top_p", topP.Float())
-	}
+	// Sampling parameters; penalties and seed have no Claude equivalent and are dropped
+	out = common.SetSamplingParams(out, root.Get("temperature"), root.Get("top_p"), root.Get("top_k"))
 
 	// Stop sequences configuration for custom termination conditions
 	if stop := root.Get("stop"); stop.Exists() {
@@ -135,34 +132,35 @@ func ConvertOpenAIRequestToClaude(modelName string, inputRawJSON []byte, stream
 
 	// Process messages and transform them to Claude Code format
 	if messages := root.Get("messages"); messages.Exists() && messages.IsArray() {
-		messageIndex := 0
-		systemMessageIndex := -1
 		messages.ForEach(func(_, message gjson.Result) bool {
 			role := message.Get("role").String()
 			contentResult := message.Get("content")
 
 			switch role {
-			case "system":
-				if systemMessageIndex == -1 {
-					systemMsg := `{"role":"user","content":[]}`
-					out, _ = sjson.SetRaw(out, "messages.-1", systemMsg)
-					systemMessageIndex = messageIndex
-					messageIndex++
+			case "system", "developer":
+				// Claude has no developer role; both are merged into the top-level system blocks.
+				if !gjson.Get(out, "system").Exists() {
+					out, _ = sjson.SetRaw(out, "system", "[]")
 				}
+				blocksBefore := len(gjson.Get(out, "system").Array())
 				if contentResult.Exists() && contentResult.Type == gjson.String && contentResult.String() != "" {
 					textPart := `{"type":"text","text":""}`
 					textPart, _ = sjson.Set(textPart, "text", contentResult.String())
-					out, _ = sjson.SetRaw(out, fmt.Sprintf("messages.%d.content.-1", systemMessageIndex), textPart)
+					out, _ = sjson.SetRaw(out, "system.-1", textPart)
 				} else if contentResult.Exists() && contentResult.IsArray() {
 					contentResult.ForEach(func(_, part gjson.Result) bool {
 						if part.Get("type").String() == "text" {
 							textPart := `{"type":"text","text":""}`
 							textPart, _ = sjson.Set(textPart, "text", part.Get("text").String())
-							out, _ = sjson.SetRaw(out, fmt.Sprintf("messages.%d.content.-1", systemMessageIndex), textPart)
+							textPart = copyCacheControl(textPart, part)
+							out, _ = sjson.SetRaw(out, "system.-1", textPart)
 						}
 						return true
 					})
 				}
+				if blocks := len(gjson.Get(out, "system").Array()); blocks > blocksBefore {
+					out = applyMessageCacheControl(out, fmt.Sprintf("system.%d", blocks-1), message)
+				}
 			case "user", "assistant":
 				msg := `{"role":"","content":[]}`
 				msg, _ = sjson.Set(msg, "role", role)
@@ -180,6 +178,7 @@ func ConvertOpenAIRequestToClaude(modelName string, inputRawJSON []byte, stream
 						case "text":
 							textPart := `{"type":"text","text":""}`
 							textPart, _ = sjson.Set(textPart, "text", part.Get("text").String())
+							textPart = copyCacheControl(textPart, part)
 							msg, _ = sjson.SetRaw(msg, "content.-1", textPart)
 
 						case "image_url":
@@ -241,8 +240,11 @@ func ConvertOpenAIRequestToClaude(modelName string, inputRawJSON []byte, stream
 					})
 				}
 
+				if blocks := len(gjson.Get(msg, "content").Array()); blocks > 0 {
+					msg = applyMessageCacheControl(msg, fmt.Sprintf("content.%d", blocks-1), message)
+				}
+
 				out, _ = sjson.SetRaw(out, "messages.-1", msg)
-				messageIndex++
 
 			case "tool":
 				// Handle tool result messages conversion
@@ -253,10 +255,20 @@ func ConvertOpenAIRequestToClaude(modelName string, inputRawJSON []byte, stream
 				msg, _ = sjson.Set(msg, "content.0.tool_use_id", toolCallID)
 				msg, _ = sjson.Set(msg, "content.0.content", content)
 				out, _ = sjson.SetRaw(out, "messages.-1", msg)
-				messageIndex++
 			}
 			return true
 		})
+		if system := gjson.Get(out, "system"); system.Exists() && len(system.Array()) == 0 {
+			out, _ = sjson.Delete(out, "system")
+		}
+		// Claude rejects requests without messages, so a request carrying only system or
+		// developer messages sends them as the user turn instead.
+		if system := gjson.Get(out, "system"); system.Exists() && len(gjson.Get(out, "messages").Array()) == 0 {
+			msg := `{"role":"user","content":[]}`
+			msg, _ = sjson.SetRaw(msg, "content", system.Raw)
+			out, _ = sjson.SetRaw(out, "messages.-1", msg)
+			out, _ = sjson.Delete(out, "system")
+		}
 	}
 
 	// Tools mapping: OpenAI tools -> Claude Code tools
@@ -314,3 +326,24 @@ func ConvertOpenAIRequestToClaude(modelName string, inputRawJSON []byte, stream
 
 	return []byte(out)
 }
+
+// copyCacheControl copies a prompt-caching marker from an OpenAI content part onto a Claude block.
+func copyCacheControl(block string, source gjson.Result) string {
+	cacheControl := source.Get("cache_control")
+	if !cacheControl.IsObject() {
+		return block
+	}
+	block, _ = sjson.SetRaw(block, "cache_control", cacheControl.Raw)
+	return block
+}
+
+// applyMessageCacheControl marks the block at path with the message-level cache_control,
+// unless the block already carries its own marker.
+func applyMessageCacheControl(doc, path string, message gjson.Result) string {
+	cacheControl := message.Get("cache_control")
+	if !cacheControl.IsObject() || gjson.Get(doc, path+".cache_control").Exists() {
+		return doc
+	}
+	doc, _ = sjson.SetRaw(doc, path+".cache_control", cacheControl.Raw)
+	return doc
+}
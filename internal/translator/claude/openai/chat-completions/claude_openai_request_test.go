@@ -0,0 +1,108 @@
+package chat_completions
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertOpenAIRequestToClaude_SystemCacheControl(t *testing.T) {
+	input := []byte(`{
+		"model":"claude-test",
+		"messages":[
+			{"role":"system","content":"You are a careful reviewer.","cache_control":{"type":"ephemeral"}},
+			{"role":"user","content":[
+				{"type":"text","text":"Large shared context","cache_control":{"type":"ephemeral","ttl":"1h"}},
+				{"type":"text","text":"Question"}
+			]}
+		]
+	}`)
+
+	out := ConvertOpenAIRequestToClaude("claude-test", input, false)
+
+	system := gjson.GetBytes(out, "system.0")
+	if system.Get("text").String() != "You are a careful reviewer." {
+		t.Fatalf("system block = %s", system.Raw)
+	}
+	if got := system.Get("cache_control.type").String(); got != "ephemeral" {
+		t.Fatalf("system cache_control.type = %q, want ephemeral: %s", got, out)
+	}
+
+	user := gjson.GetBytes(out, "messages.0.content")
+	if got := user.Get("0.cache_control.ttl").String(); got != "1h" {
+		t.Fatalf("user part cache_control.ttl = %q, want 1h: %s", got, out)
+	}
+	if user.Get("1.cache_control").Exists() {
+		t.Fatalf("unmarked part gained cache_control: %s", out)
+	}
+}
+
+func TestConvertOpenAIRequestToClaude_DeveloperMergedIntoSystem(t *testing.T) {
+	input := []byte(`{
+		"model":"claude-test",
+		"messages":[
+			{"role":"system","content":"Be brief."},
+			{"role":"developer","content":[{"type":"text","text":"Answer in French."}]},
+			{"role":"user","content":"hi"}
+		]
+	}`)
+
+	out := ConvertOpenAIRequestToClaude("claude-test", input, false)
+
+	system := gjson.GetBytes(out, "system")
+	if got := system.Get("#").Int(); got != 2 {
+		t.Fatalf("system blocks = %d, want 2: %s", got, out)
+	}
+	if got := system.Get("1.text").String(); got != "Answer in French." {
+		t.Fatalf("developer block text = %q: %s", got, out)
+	}
+	if got := gjson.GetBytes(out, "messages.#").Int(); got != 1 || gjson.GetBytes(out, "messages.0.role").String() != "user" {
+		t.Fatalf("messages should hold only the user turn: %s", out)
+	}
+}
+
+func TestConvertOpenAIRequestToClaude_MessageCacheControlMarksLastBlock(t *testing.T) {
+	input := []byte(`{
+		"model":"claude-test",
+		"messages":[
+			{"role":"user","cache_control":{"type":"ephemeral"},"content":[
+				{"type":"text","text":"first"},
+				{"type":"text","text":"second"}
+			]}
+		]
+	}`)
+
+	out := ConvertOpenAIRequestToClaude("claude-test", input, false)
+
+	content := gjson.GetBytes(out, "messages.0.content")
+	if content.Get("0.cache_control").Exists() {
+		t.Fatalf("first block should not be marked: %s", out)
+	}
+	if got := content.Get("1.cache_control.type").String(); got != "ephemeral" {
+		t.Fatalf("last block cache_control.type = %q, want ephemeral: %s", got, out)
+	}
+}
+
+func TestConvertOpenAIRequestToClaude_SystemOnlyBecomesUserMessage(t *testing.T) {
+	input := []byte(`{
+		"model":"claude-test",
+		"messages":[
+			{"role":"system","content":"Summarize the policy."},
+			{"role":"developer","content":[{"type":"text","text":"Answer in one line."}]}
+		]
+	}`)
+
+	out := ConvertOpenAIRequestToClaude("claude-test", input, false)
+
+	if gjson.GetBytes(out, "system").Exists() {
+		t.Fatalf("system blocks should move into the user turn: %s", out)
+	}
+	messages := gjson.GetBytes(out, "messages").Array()
+	if len(messages) != 1 || messages[0].Get("role").String() != "user" {
+		t.Fatalf("messages = %s, want a single user message", gjson.GetBytes(out, "messages").Raw)
+	}
+	content := messages[0].Get("content").Array()
+	if len(content) != 2 || content[0].Get("text").String() != "Summarize the policy." || content[1].Get("text").String() != "Answer in one line." {
+		t.Fatalf("user content = %s", messages[0].Get("content").Raw)
+	}
+}
@@ -9,9 +9,11 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -218,6 +220,10 @@ func ConvertClaudeResponseToOpenAI(_ context.Context, modelName string, original
 		// Final message event - no additional output needed
 		return []string{}
 
+	case "stream_interrupted":
+		// The upstream stream failed; tool calls still open were cut off mid-arguments.
+		return flushPendingToolCalls(template, (*param).(*ConvertAnthropicResponseToOpenAIParams))
+
 	case "ping":
 		// Ping events for keeping connection alive - no output needed
 		return []string{}
@@ -254,6 +260,39 @@ func mapAnthropicStopReasonToOpenAI(anthropicReason string) string {
 	}
 }
 
+// flushPendingToolCalls emits tool calls whose content block never stopped. Arguments
+// that end mid-JSON are closed into a best-effort object when util.ToolArgsOnTruncation
+// is "close" and dropped otherwise. No finish chunk is sent: the executor follows up
+// with the stream error, so the response must not look complete.
+func flushPendingToolCalls(template string, param *ConvertAnthropicResponseToOpenAIParams) []string {
+	indexes := make([]int, 0, len(param.ToolCallsAccumulator))
+	for index := range param.ToolCallsAccumulator {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	results := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		accumulator := param.ToolCallsAccumulator[index]
+		delete(param.ToolCallsAccumulator, index)
+		arguments := accumulator.Arguments.String()
+		if arguments == "" {
+			arguments = "{}"
+		}
+		closed, ok := util.FinalizeToolArguments(arguments)
+		if !ok {
+			continue
+		}
+		chunk, _ := sjson.Set(template, "choices.0.delta.tool_calls.0.index", index)
+		chunk, _ = sjson.Set(chunk, "choices.0.delta.tool_calls.0.id", accumulator.ID)
+		chunk, _ = sjson.Set(chunk, "choices.0.delta.tool_calls.0.type", "function")
+		chunk, _ = sjson.Set(chunk, "choices.0.delta.tool_calls.0.function.name", accumulator.Name)
+		chunk, _ = sjson.Set(chunk, "choices.0.delta.tool_calls.0.function.arguments", closed)
+		results = append(results, chunk)
+	}
+	return results
+}
+
 // ConvertClaudeResponseToOpenAINonStream converts a non-streaming Claude Code response to a non-streaming OpenAI response.
 // This function processes the complete Claude Code response and transforms it into a single OpenAI-compatible
 // JSON response. It handles message content, tool calls, reasoning content, and usage metadata, combining all
@@ -0,0 +1,63 @@
+package chat_completions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/tidwall/gjson"
+)
+
+// runInterruptedToolCallStream feeds a tool call whose stream dies mid-arguments,
+// followed by the interruption event the executor feeds on stream failure.
+func runInterruptedToolCallStream(t *testing.T, action string) []string {
+	t.Helper()
+	previous := util.ToolArgsOnTruncation()
+	util.SetToolArgsOnTruncation(action)
+	t.Cleanup(func() { util.SetToolArgsOnTruncation(previous) })
+
+	events := []string{
+		`data: {"type":"message_start","message":{"id":"msg_1","model":"claude-test"}}`,
+		`data: {"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_1","name":"read_file","input":{}}}`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"path\":\"/tmp/fi"}}`,
+		string(util.StreamInterruptedEvent),
+	}
+	var param any
+	var chunks []string
+	for _, event := range events {
+		chunks = append(chunks, ConvertClaudeResponseToOpenAI(context.Background(), "claude-test", nil, nil, []byte(event), &param)...)
+	}
+	return chunks
+}
+
+func TestConvertClaudeResponseToOpenAI_InterruptedToolArgsClose(t *testing.T) {
+	chunks := runInterruptedToolCallStream(t, "close")
+	if len(chunks) != 2 {
+		t.Fatalf("chunks = %d, want 2: %v", len(chunks), chunks)
+	}
+	if got := gjson.Get(chunks[1], "choices.0.delta.tool_calls.0.function.arguments").String(); got != `{"path":"/tmp/fi"}` {
+		t.Fatalf("arguments = %q, want closed object", got)
+	}
+	assertNoFinishReason(t, chunks)
+}
+
+func TestConvertClaudeResponseToOpenAI_InterruptedToolArgsError(t *testing.T) {
+	chunks := runInterruptedToolCallStream(t, "error")
+	if len(chunks) != 1 {
+		t.Fatalf("chunks = %d, want 1: %v", len(chunks), chunks)
+	}
+	if gjson.Get(chunks[0], "choices.0.delta.tool_calls").Exists() {
+		t.Fatalf("truncated tool call must not be forwarded: %s", chunks[0])
+	}
+	assertNoFinishReason(t, chunks)
+}
+
+// assertNoFinishReason fails when an interrupted stream was made to look complete.
+func assertNoFinishReason(t *testing.T, chunks []string) {
+	t.Helper()
+	for _, chunk := range chunks {
+		if finish := gjson.Get(chunk, "choices.0.finish_reason"); finish.Exists() && finish.Type != gjson.Null {
+			t.Fatalf("unexpected finish_reason %q in %s", finish.String(), chunk)
+		}
+	}
+}
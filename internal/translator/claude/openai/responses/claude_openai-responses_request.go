@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/claude/common"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -29,6 +30,7 @@ var (
 // - function_call_output -> user tool_result
 // - tools[].parameters -> tools[].input_schema
 // - max_output_tokens -> max_tokens
+// - temperature/top_p -> temperature/top_p (clamped to Claude's ranges)
 // - stream passthrough via parameter
 func ConvertOpenAIResponsesRequestToClaude(modelName string, inputRawJSON []byte, stream bool) []byte {
 	rawJSON := inputRawJSON
@@ -92,56 +94,54 @@ func ConvertOpenAIResponsesRequestToClaude(modelName string, inputRawJSON []byte
 		out, _ = sjson.Set(out, "max_tokens", mot.Int())
 	}
 
+	// Sampling parameters
+	out = common.SetSamplingParams(out, root.Get("temperature"), root.Get("top_p"), gjson.Result{})
+
 	// Stream
 	out, _ = sjson.Set(out, "stream", stream)
 
-	// instructions -> as a leading message (use role user for Claude API compatibility)
-	instructionsText := ""
-	extractedFromSystem := false
-	if instr := root.Get("instructions"); instr.Exists() && instr.Type == gjson.String {
-		instructionsText = instr.String()
-		if instructionsText != "" {
-			sysMsg := `{"role":"user","content":""}`
-			sysMsg, _ = sjson.Set(sysMsg, "content", instructionsText)
-			out, _ = sjson.SetRaw(out, "messages.-1", sysMsg)
+	// instructions and system/developer input messages -> top-level system blocks
+	appendSystemText := func(text string) {
+		if text == "" {
+			return
+		}
+		block := `{"type":"text","text":""}`
+		block, _ = sjson.Set(block, "text", text)
+		if !gjson.Get(out, "system").Exists() {
+			out, _ = sjson.SetRaw(out, "system", "[]")
 		}
+		out, _ = sjson.SetRaw(out, "system.-1", block)
 	}
-
-	if instructionsText == "" {
-		if input := root.Get("input"); input.Exists() && input.IsArray() {
-			input.ForEach(func(_, item gjson.Result) bool {
-				if strings.EqualFold(item.Get("role").String(), "system") {
-					var builder strings.Builder
-					if parts := item.Get("content"); parts.Exists() && parts.IsArray() {
-						parts.ForEach(func(_, part gjson.Result) bool {
-							textResult := part.Get("text")
-							text := textResult.String()
-							if builder.Len() > 0 && text != "" {
-								builder.WriteByte('\n')
-							}
-							builder.WriteString(text)
-							return true
-						})
-					} else if parts.Type == gjson.String {
-						builder.WriteString(parts.String())
-					}
-					instructionsText = builder.String()
-					if instructionsText != "" {
-						sysMsg := `{"role":"user","content":""}`
-						sysMsg, _ = sjson.Set(sysMsg, "content", instructionsText)
-						out, _ = sjson.SetRaw(out, "messages.-1", sysMsg)
-						extractedFromSystem = true
+	if instr := root.Get("instructions"); instr.Exists() && instr.Type == gjson.String {
+		appendSystemText(instr.String())
+	}
+	if input := root.Get("input"); input.Exists() && input.IsArray() {
+		input.ForEach(func(_, item gjson.Result) bool {
+			if !isSystemRole(item.Get("role").String()) {
+				return true
+			}
+			var builder strings.Builder
+			if parts := item.Get("content"); parts.Exists() && parts.IsArray() {
+				parts.ForEach(func(_, part gjson.Result) bool {
+					text := part.Get("text").String()
+					if builder.Len() > 0 && text != "" {
+						builder.WriteByte('\n')
 					}
-				}
-				return instructionsText == ""
-			})
-		}
+					builder.WriteString(text)
+					return true
+				})
+			} else if parts.Type == gjson.String {
+				builder.WriteString(parts.String())
+			}
+			appendSystemText(builder.String())
+			return true
+		})
 	}
 
 	// input array processing
 	if input := root.Get("input"); input.Exists() && input.IsArray() {
 		input.ForEach(func(_, item gjson.Result) bool {
-			if extractedFromSystem && strings.EqualFold(item.Get("role").String(), "system") {
+			if isSystemRole(item.Get("role").String()) {
 				return true
 			}
 			typ := item.Get("type").String()
@@ -218,7 +218,7 @@ func ConvertOpenAIResponsesRequestToClaude(modelName string, inputRawJSON []byte
 				if role == "" {
 					r := item.Get("role").String()
 					switch r {
-					case "user", "assistant", "system":
+					case "user", "assistant":
 						role = r
 					default:
 						role = "user"
@@ -239,7 +239,7 @@ func ConvertOpenAIResponsesRequestToClaude(modelName string, inputRawJSON []byte
 						}
 					}
 					out, _ = sjson.SetRaw(out, "messages.-1", msg)
-				} else if textAggregate.Len() > 0 || role == "system" {
+				} else if textAggregate.Len() > 0 {
 					msg := `{"role":"","content":""}`
 					msg, _ = sjson.Set(msg, "role", role)
 					msg, _ = sjson.Set(msg, "content", textAggregate.String())
@@ -337,3 +337,9 @@ func ConvertOpenAIResponsesRequestToClaude(modelName string, inputRawJSON []byte
 
 	return []byte(out)
 }
+
+// isSystemRole reports whether an input message role carries instructions. Claude has no
+// developer role, so developer messages are treated like system messages.
+func isSystemRole(role string) bool {
+	return strings.EqualFold(role, "system") || strings.EqualFold(role, "developer")
+}
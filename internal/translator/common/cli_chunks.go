@@ -0,0 +1,21 @@
+// Package common holds helpers shared by the response translators of several providers.
+package common
+
+import "github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+
+// TranslateCLIChunks normalizes a Gemini CLI or Antigravity stream payload with
+// util.CLIResponseChunks. When the payload holds exactly one chunk, it is returned for the
+// caller to translate in place and done is false. alt=json streams deliver every chunk in
+// one array instead; those chunks are passed to translate in order, and the combined
+// output is returned with done set.
+func TranslateCLIChunks(rawJSON []byte, translate func(chunk []byte) []string) (chunk []byte, out []string, done bool) {
+	chunks := util.CLIResponseChunks(rawJSON)
+	if len(chunks) == 1 {
+		return chunks[0], nil, false
+	}
+	out = make([]string, 0, len(chunks))
+	for _, item := range chunks {
+		out = append(out, translate(item)...)
+	}
+	return nil, out, true
+}
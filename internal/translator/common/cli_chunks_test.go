@@ -0,0 +1,23 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTranslateCLIChunks(t *testing.T) {
+	translate := func(chunk []byte) []string { return []string{string(chunk)} }
+
+	chunk, out, done := TranslateCLIChunks([]byte(`{"response":{"responseId":"a"}}`), translate)
+	if done || out != nil || len(chunk) == 0 {
+		t.Fatalf("single chunk: got chunk=%q out=%v done=%v", chunk, out, done)
+	}
+
+	_, out, done = TranslateCLIChunks([]byte(`[{"response":{"responseId":"a"}},{"response":{"responseId":"b"}}]`), translate)
+	if !done || len(out) != 2 {
+		t.Fatalf("alt=json array: got out=%v done=%v", out, done)
+	}
+	if reflect.DeepEqual(out[0], out[1]) {
+		t.Fatalf("alt=json array: chunks not translated in order: %v", out)
+	}
+}
@@ -14,6 +14,8 @@ import (
 	"sync/atomic"
 	"time"
 
+	translatorcommon "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/common"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -47,7 +49,7 @@ var toolUseIDCounter uint64
 //
 // Returns:
 //   - []string: A slice of strings, each containing a Claude Code-compatible JSON response
-func ConvertGeminiCLIResponseToClaude(_ context.Context, _ string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) []string {
+func ConvertGeminiCLIResponseToClaude(ctx context.Context, modelName string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) []string {
 	if *param == nil {
 		*param = &Params{
 			HasFirstResponse: false,
@@ -66,6 +68,14 @@ func ConvertGeminiCLIResponseToClaude(_ context.Context, _ string, originalReque
 		return []string{}
 	}
 
+	chunk, out, done := translatorcommon.TranslateCLIChunks(rawJSON, func(chunk []byte) []string {
+		return ConvertGeminiCLIResponseToClaude(ctx, modelName, originalRequestRawJSON, requestRawJSON, chunk, param)
+	})
+	if done {
+		return out
+	}
+	rawJSON = chunk
+
 	// Track whether tools are being used in this response chunk
 	usedTool := false
 	output := ""
@@ -252,6 +262,7 @@ func ConvertGeminiCLIResponseToClaude(_ context.Context, _ string, originalReque
 				thoughtsTokenCount := usageResult.Get("thoughtsTokenCount").Int()
 				template, _ = sjson.Set(template, "usage.output_tokens", candidatesTokenCountResult.Int()+thoughtsTokenCount)
 				template, _ = sjson.Set(template, "usage.input_tokens", usageResult.Get("promptTokenCount").Int())
+				template = util.SetClaudeAvgLogprobs(template, gjson.GetBytes(rawJSON, "response.candidates.0.avgLogprobs"))
 
 				output = output + template + "\n\n\n"
 			}
@@ -275,7 +286,7 @@ func ConvertGeminiCLIResponseToClaudeNonStream(_ context.Context, _ string, orig
 	_ = originalRequestRawJSON
 	_ = requestRawJSON
 
-	root := gjson.ParseBytes(rawJSON)
+	root := gjson.ParseBytes(util.WrapCLIResponse(rawJSON))
 
 	out := `{"id":"","type":"message","role":"assistant","model":"","content":[],"stop_reason":null,"stop_sequence":null,"usage":{"input_tokens":0,"output_tokens":0}}`
 	out, _ = sjson.Set(out, "id", root.Get("response.responseId").String())
@@ -369,6 +380,7 @@ func ConvertGeminiCLIResponseToClaudeNonStream(_ context.Context, _ string, orig
 	if inputTokens == int64(0) && outputTokens == int64(0) && !root.Get("response.usageMetadata").Exists() {
 		out, _ = sjson.Delete(out, "usage")
 	}
+	out = util.SetClaudeAvgLogprobs(out, root.Get("response.candidates.0.avgLogprobs"))
 
 	return out
 }
@@ -0,0 +1,20 @@
+package chat_completions
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertOpenAIRequestToGeminiCLI_CachedContent(t *testing.T) {
+	input := []byte(`{"model":"gemini-2.5-pro","cached_content":"cachedContents/abc123","messages":[{"role":"user","content":"hi"}]}`)
+	out := ConvertOpenAIRequestToGeminiCLI("gemini-2.5-pro", input, false)
+	if got := gjson.GetBytes(out, "request.cachedContent").String(); got != "cachedContents/abc123" {
+		t.Fatalf("request.cachedContent = %q, want %q; payload=%s", got, "cachedContents/abc123", out)
+	}
+
+	out = ConvertOpenAIRequestToGeminiCLI("gemini-2.5-pro", []byte(`{"messages":[{"role":"user","content":"hi"}]}`), false)
+	if gjson.GetBytes(out, "request.cachedContent").Exists() {
+		t.Fatalf("unexpected cachedContent without a handle: %s", out)
+	}
+}
@@ -13,7 +13,10 @@ import (
 	"sync/atomic"
 	"time"
 
+	translatorcommon "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/common"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/common"
 	. "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/openai/chat-completions"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
@@ -42,7 +45,7 @@ var functionCallIDCounter uint64
 //
 // Returns:
 //   - []string: A slice of strings, each containing an OpenAI-compatible JSON response
-func ConvertCliResponseToOpenAI(_ context.Context, _ string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) []string {
+func ConvertCliResponseToOpenAI(ctx context.Context, modelName string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) []string {
 	if *param == nil {
 		*param = &convertCliResponseToOpenAIChatParams{
 			UnixTimestamp: 0,
@@ -54,6 +57,14 @@ func ConvertCliResponseToOpenAI(_ context.Context, _ string, originalRequestRawJ
 		return []string{}
 	}
 
+	chunk, out, done := translatorcommon.TranslateCLIChunks(rawJSON, func(chunk []byte) []string {
+		return ConvertCliResponseToOpenAI(ctx, modelName, originalRequestRawJSON, requestRawJSON, chunk, param)
+	})
+	if done {
+		return out
+	}
+	rawJSON = chunk
+
 	// Initialize the OpenAI SSE template.
 	template := `{"id":"","object":"chat.completion.chunk","created":12345,"model":"model","choices":[{"index":0,"delta":{"role":null,"content":null,"reasoning_content":null,"tool_calls":null},"finish_reason":null,"native_finish_reason":null}]}`
 
@@ -199,13 +210,20 @@ func ConvertCliResponseToOpenAI(_ context.Context, _ string, originalRequestRawJ
 		}
 	}
 
+	if common.LogprobsRequested(originalRequestRawJSON) {
+		if logprobs, ok := common.OpenAILogprobs(gjson.GetBytes(rawJSON, "response.candidates.0")); ok {
+			template, _ = sjson.SetRaw(template, "choices.0.logprobs", logprobs)
+		}
+	}
+
 	if hasFunctionCall {
 		template, _ = sjson.Set(template, "choices.0.finish_reason", "tool_calls")
 		template, _ = sjson.Set(template, "choices.0.native_finish_reason", "tool_calls")
 	} else if finishReason != "" && (*param).(*convertCliResponseToOpenAIChatParams).FunctionIndex == 0 {
-		// Only pass through specific finish reasons
-		if finishReason == "max_tokens" || finishReason == "stop" {
-			template, _ = sjson.Set(template, "choices.0.finish_reason", finishReason)
+		// Only pass through specific finish reasons; MAX_TOKENS keeps its partial content and
+		// reports OpenAI's "length", and safety blocks report "content_filter".
+		if mapped := common.OpenAIFinishReason(finishReason); mapped == "length" || mapped == "stop" || mapped == "content_filter" {
+			template, _ = sjson.Set(template, "choices.0.finish_reason", mapped)
 			template, _ = sjson.Set(template, "choices.0.native_finish_reason", finishReason)
 		}
 	}
@@ -227,7 +245,7 @@ func ConvertCliResponseToOpenAI(_ context.Context, _ string, originalRequestRawJ
 // Returns:
 //   - string: An OpenAI-compatible JSON response containing all message content and metadata
 func ConvertCliResponseToOpenAINonStream(ctx context.Context, modelName string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) string {
-	responseResult := gjson.GetBytes(rawJSON, "response")
+	responseResult := gjson.GetBytes(util.WrapCLIResponse(rawJSON), "response")
 	if responseResult.Exists() {
 		return ConvertGeminiResponseToOpenAINonStream(ctx, modelName, originalRequestRawJSON, requestRawJSON, []byte(responseResult.Raw), param)
 	}
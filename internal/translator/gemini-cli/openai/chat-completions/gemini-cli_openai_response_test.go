@@ -0,0 +1,118 @@
+package chat_completions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+const wrappedCLIChunk = `{"response":{"responseId":"resp-1","modelVersion":"gemini-2.5-pro","candidates":[{"content":{"role":"model","parts":[{"text":"hello"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":5,"candidatesTokenCount":2,"totalTokenCount":7}},"traceId":"t"}`
+
+func TestConvertCliResponseToOpenAI_WrappedChunk(t *testing.T) {
+	var param any
+	out := ConvertCliResponseToOpenAI(context.Background(), "gemini-2.5-pro", nil, nil, []byte("data: "+wrappedCLIChunk), &param)
+	if len(out) != 1 {
+		t.Fatalf("got %d chunks, want 1", len(out))
+	}
+	chunk := gjson.Parse(out[0])
+	if got := chunk.Get("choices.0.delta.content").String(); got != "hello" {
+		t.Fatalf("content = %q, want hello", got)
+	}
+	if got := chunk.Get("id").String(); got != "resp-1" {
+		t.Fatalf("id = %q, want resp-1", got)
+	}
+	if got := chunk.Get("usage.total_tokens").Int(); got != 7 {
+		t.Fatalf("usage.total_tokens = %d, want 7", got)
+	}
+	if got := chunk.Get("choices.0.finish_reason").String(); got != "stop" {
+		t.Fatalf("finish_reason = %q, want stop", got)
+	}
+}
+
+func TestConvertCliResponseToOpenAI_AltJSONArray(t *testing.T) {
+	raw := `[{"response":{"candidates":[{"content":{"parts":[{"text":"a"}]}}]}},{"response":{"candidates":[{"content":{"parts":[{"text":"b"}]}}],"usageMetadata":{"promptTokenCount":1,"candidatesTokenCount":2,"totalTokenCount":3}}}]`
+	var param any
+	out := ConvertCliResponseToOpenAI(context.Background(), "gemini-2.5-pro", nil, nil, []byte(raw), &param)
+	if len(out) != 2 {
+		t.Fatalf("got %d chunks, want 2: %v", len(out), out)
+	}
+	if got := gjson.Get(out[0], "choices.0.delta.content").String(); got != "a" {
+		t.Fatalf("first content = %q, want a", got)
+	}
+	if got := gjson.Get(out[1], "choices.0.delta.content").String(); got != "b" {
+		t.Fatalf("second content = %q, want b", got)
+	}
+	if got := gjson.Get(out[1], "usage.total_tokens").Int(); got != 3 {
+		t.Fatalf("usage.total_tokens = %d, want 3", got)
+	}
+}
+
+func TestConvertCliResponseToOpenAI_BareChunk(t *testing.T) {
+	raw := `{"candidates":[{"content":{"parts":[{"text":"bare"}]}}],"usageMetadata":{"promptTokenCount":1,"candidatesTokenCount":1,"totalTokenCount":2}}`
+	var param any
+	out := ConvertCliResponseToOpenAI(context.Background(), "gemini-2.5-pro", nil, nil, []byte(raw), &param)
+	if len(out) != 1 {
+		t.Fatalf("got %d chunks, want 1", len(out))
+	}
+	if got := gjson.Get(out[0], "choices.0.delta.content").String(); got != "bare" {
+		t.Fatalf("content = %q, want bare", got)
+	}
+	if got := gjson.Get(out[0], "usage.total_tokens").Int(); got != 2 {
+		t.Fatalf("usage.total_tokens = %d, want 2", got)
+	}
+}
+
+func TestConvertCliResponseToOpenAINonStream_WrappedAndBare(t *testing.T) {
+	bare := gjson.Get(wrappedCLIChunk, "response").Raw
+	for name, raw := range map[string]string{"wrapped": wrappedCLIChunk, "bare": bare} {
+		t.Run(name, func(t *testing.T) {
+			var param any
+			out := ConvertCliResponseToOpenAINonStream(context.Background(), "gemini-2.5-pro", nil, nil, []byte(raw), &param)
+			resp := gjson.Parse(out)
+			if got := resp.Get("choices.0.message.content").String(); got != "hello" {
+				t.Fatalf("content = %q, want hello; out=%s", got, out)
+			}
+			if got := resp.Get("usage.prompt_tokens").Int(); got != 5 {
+				t.Fatalf("usage.prompt_tokens = %d, want 5", got)
+			}
+			if got := resp.Get("usage.completion_tokens").Int(); got != 2 {
+				t.Fatalf("usage.completion_tokens = %d, want 2", got)
+			}
+		})
+	}
+}
+
+func TestConvertCliResponseToOpenAI_StreamCarriesLogprobs(t *testing.T) {
+	chunk := []byte(`{"response":{"candidates":[{"content":{"role":"model","parts":[{"text":"Hi"}]},"avgLogprobs":-0.25,"logprobsResult":{"chosenCandidates":[{"token":"Hi","logProbability":-0.25}],"topCandidates":[{"candidates":[{"token":"Hi","logProbability":-0.25}]}]}}]}}`)
+
+	var param any
+	out := ConvertCliResponseToOpenAI(context.Background(), "gemini-2.5-pro", []byte(`{"logprobs":true}`), nil, chunk, &param)
+	if len(out) != 1 {
+		t.Fatalf("got %d chunks, want 1", len(out))
+	}
+	logprobs := gjson.Get(out[0], "choices.0.logprobs")
+	if got := logprobs.Get("content.0.token").String(); got != "Hi" {
+		t.Fatalf("logprobs token = %q, want Hi: %s", got, out[0])
+	}
+	if got := logprobs.Get("avg_logprob").Float(); got != -0.25 {
+		t.Fatalf("avg_logprob = %v, want -0.25", got)
+	}
+
+	var plainParam any
+	out = ConvertCliResponseToOpenAI(context.Background(), "gemini-2.5-pro", []byte(`{}`), nil, chunk, &plainParam)
+	if gjson.Get(out[0], "choices.0.logprobs").Exists() {
+		t.Fatalf("logprobs should be omitted when not requested: %s", out[0])
+	}
+}
+
+func TestConvertCliResponseToOpenAI_StreamSafetyIsContentFilter(t *testing.T) {
+	var param any
+	out := ConvertCliResponseToOpenAI(context.Background(), "gemini-2.5-pro", nil, nil, []byte(`{"response":{"candidates":[{"content":{"role":"model","parts":[]},"finishReason":"SAFETY"}],"usageMetadata":{"promptTokenCount":5,"totalTokenCount":5}}}`), &param)
+	if len(out) != 1 {
+		t.Fatalf("got %d chunks, want 1", len(out))
+	}
+	if got := gjson.Get(out[0], "choices.0.finish_reason").String(); got != "content_filter" {
+		t.Fatalf("finish_reason = %q, want content_filter; chunk=%s", got, out[0])
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -258,6 +259,7 @@ func ConvertGeminiResponseToClaude(_ context.Context, _ string, originalRequestR
 				thoughtsTokenCount := usageResult.Get("thoughtsTokenCount").Int()
 				template, _ = sjson.Set(template, "usage.output_tokens", candidatesTokenCountResult.Int()+thoughtsTokenCount)
 				template, _ = sjson.Set(template, "usage.input_tokens", usageResult.Get("promptTokenCount").Int())
+				template = util.SetClaudeAvgLogprobs(template, gjson.GetBytes(rawJSON, "candidates.0.avgLogprobs"))
 
 				output = output + template + "\n\n\n"
 			}
@@ -375,6 +377,7 @@ func ConvertGeminiResponseToClaudeNonStream(_ context.Context, _ string, origina
 	if inputTokens == int64(0) && outputTokens == int64(0) && !root.Get("usageMetadata").Exists() {
 		out, _ = sjson.Delete(out, "usage")
 	}
+	out = util.SetClaudeAvgLogprobs(out, root.Get("candidates.0.avgLogprobs"))
 
 	return out
 }
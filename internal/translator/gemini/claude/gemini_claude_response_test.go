@@ -0,0 +1,42 @@
+package claude
+
+import (
+	"context"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/tidwall/gjson"
+)
+
+const avgLogprobsResponse = `{"responseId":"resp-1","modelVersion":"gemini-2.5-pro","candidates":[{"content":{"parts":[{"text":"hi"}]},"finishReason":"STOP","avgLogprobs":-0.25}],"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":1}}`
+
+func TestConvertGeminiResponseToClaudeNonStream_AvgLogprobs(t *testing.T) {
+	t.Cleanup(func() { util.SetClaudeAvgLogprobsLocation("") })
+
+	cases := []struct {
+		name     string
+		location string
+		path     string
+	}{
+		{name: "default drops field", location: "", path: ""},
+		{name: "usage", location: util.AvgLogprobsUsage, path: "usage.avg_logprobs"},
+		{name: "metadata", location: util.AvgLogprobsMetadata, path: "metadata.avg_logprobs"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			util.SetClaudeAvgLogprobsLocation(tc.location)
+			out := ConvertGeminiResponseToClaudeNonStream(context.Background(), "", nil, nil, []byte(avgLogprobsResponse), nil)
+
+			if tc.path == "" {
+				if gjson.Get(out, "usage.avg_logprobs").Exists() || gjson.Get(out, "metadata.avg_logprobs").Exists() {
+					t.Fatalf("expected avg_logprobs to be dropped, got %s", out)
+				}
+				return
+			}
+			if got := gjson.Get(out, tc.path); !got.Exists() || got.Float() != -0.25 {
+				t.Fatalf("expected %s = -0.25, got %s", tc.path, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package common
+
+import "strings"
+
+// OpenAIFinishReason maps a Gemini finishReason to its OpenAI chat-completions equivalent:
+// MAX_TOKENS becomes "length", STOP becomes "stop" and safety blocks become "content_filter".
+// Other reasons are passed through lowercased; an empty reason stays empty.
+func OpenAIFinishReason(reason string) string {
+	switch strings.ToUpper(strings.TrimSpace(reason)) {
+	case "":
+		return ""
+	case "MAX_TOKENS":
+		return "length"
+	case "STOP":
+		return "stop"
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY":
+		return "content_filter"
+	default:
+		return strings.ToLower(strings.TrimSpace(reason))
+	}
+}
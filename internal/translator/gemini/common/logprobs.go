@@ -0,0 +1,53 @@
+package common
+
+import (
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// LogprobsRequested reports whether the client asked for OpenAI logprobs.
+func LogprobsRequested(originalRequestRawJSON []byte) bool {
+	return gjson.GetBytes(originalRequestRawJSON, "logprobs").Type == gjson.True
+}
+
+// OpenAILogprobs builds an OpenAI choice logprobs object from a Gemini candidate.
+// Per-token entries come from logprobsResult when present; avgLogprobs is carried
+// as avg_logprob. It returns false when the candidate has no logprob data at all.
+func OpenAILogprobs(candidate gjson.Result) (string, bool) {
+	chosen := candidate.Get("logprobsResult.chosenCandidates")
+	avg := candidate.Get("avgLogprobs")
+	if !chosen.IsArray() && !avg.Exists() {
+		return "", false
+	}
+
+	out := `{"content":[]}`
+	topCandidates := candidate.Get("logprobsResult.topCandidates").Array()
+	for i, entry := range chosen.Array() {
+		item := logprobEntry(entry)
+		item, _ = sjson.SetRaw(item, "top_logprobs", "[]")
+		if i < len(topCandidates) {
+			for _, top := range topCandidates[i].Get("candidates").Array() {
+				item, _ = sjson.SetRaw(item, "top_logprobs.-1", logprobEntry(top))
+			}
+		}
+		out, _ = sjson.SetRaw(out, "content.-1", item)
+	}
+	if avg.Exists() {
+		out, _ = sjson.Set(out, "avg_logprob", avg.Float())
+	}
+	return out, true
+}
+
+// logprobEntry converts a Gemini logprob candidate into an OpenAI token logprob.
+func logprobEntry(entry gjson.Result) string {
+	token := entry.Get("token").String()
+	item := `{}`
+	item, _ = sjson.Set(item, "token", token)
+	item, _ = sjson.Set(item, "logprob", entry.Get("logProbability").Float())
+	tokenBytes := make([]int, 0, len(token))
+	for _, b := range []byte(token) {
+		tokenBytes = append(tokenBytes, int(b))
+	}
+	item, _ = sjson.Set(item, "bytes", tokenBytes)
+	return item
+}
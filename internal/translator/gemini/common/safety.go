@@ -1,12 +1,33 @@
 package common
 
 import (
+	"sync/atomic"
+
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
 
-// DefaultSafetySettings returns the default Gemini safety configuration we attach to requests.
+var configuredSafetySettings atomic.Value // []map[string]string
+
+// SetDefaultSafetySettings replaces the built-in default safety policy with settings, each
+// holding a "category" and "threshold". An empty list restores the built-in default.
+func SetDefaultSafetySettings(settings []map[string]string) {
+	copied := make([]map[string]string, 0, len(settings))
+	for _, setting := range settings {
+		copied = append(copied, map[string]string{
+			"category":  setting["category"],
+			"threshold": setting["threshold"],
+		})
+	}
+	configuredSafetySettings.Store(copied)
+}
+
+// DefaultSafetySettings returns the default Gemini safety configuration we attach to requests:
+// the configured policy when set, otherwise the built-in one.
 func DefaultSafetySettings() []map[string]string {
+	if configured, ok := configuredSafetySettings.Load().([]map[string]string); ok && len(configured) > 0 {
+		return configured
+	}
 	return []map[string]string{
 		{
 			"category":  "HARM_CATEGORY_HARASSMENT",
@@ -45,3 +66,15 @@ func AttachDefaultSafetySettings(rawJSON []byte, path string) []byte {
 
 	return out
 }
+
+// ApplySafetySettings sets the safety settings at path from the client's safety_settings
+// request extension (an array of Gemini {category, threshold} objects), falling back to
+// the default policy when the client sends none.
+func ApplySafetySettings(out, rawJSON []byte, path string) []byte {
+	if settings := gjson.GetBytes(rawJSON, "safety_settings"); settings.IsArray() {
+		if updated, err := sjson.SetRawBytes(out, path, []byte(settings.Raw)); err == nil {
+			return updated
+		}
+	}
+	return AttachDefaultSafetySettings(out, path)
+}
@@ -34,6 +34,11 @@ func ConvertOpenAIRequestToGemini(modelName string, inputRawJSON []byte, _ bool)
 	// Model
 	out, _ = sjson.SetBytes(out, "model", modelName)
 
+	// Context caching: reference a previously created cachedContents/<id> handle.
+	if cc := gjson.GetBytes(rawJSON, "cached_content"); cc.Type == gjson.String && strings.TrimSpace(cc.String()) != "" {
+		out, _ = sjson.SetBytes(out, "cachedContent", strings.TrimSpace(cc.String()))
+	}
+
 	// Apply thinking configuration: convert OpenAI reasoning_effort to Gemini thinkingConfig.
 	// Inline translation-only mapping; capability checks happen later in ApplyThinking.
 	re := gjson.GetBytes(rawJSON, "reasoning_effort")
@@ -62,6 +67,17 @@ func ConvertOpenAIRequestToGemini(modelName string, inputRawJSON []byte, _ bool)
 		out, _ = sjson.SetBytes(out, "generationConfig.topK", tkr.Num)
 	}
 
+	// Penalties and seed
+	if pp := gjson.GetBytes(rawJSON, "presence_penalty"); pp.Type == gjson.Number {
+		out, _ = sjson.SetBytes(out, "generationConfig.presencePenalty", pp.Num)
+	}
+	if fp := gjson.GetBytes(rawJSON, "frequency_penalty"); fp.Type == gjson.Number {
+		out, _ = sjson.SetBytes(out, "generationConfig.frequencyPenalty", fp.Num)
+	}
+	if seed := gjson.GetBytes(rawJSON, "seed"); seed.Type == gjson.Number {
+		out, _ = sjson.SetBytes(out, "generationConfig.seed", seed.Int())
+	}
+
 	// Candidate count (OpenAI 'n' parameter)
 	if n := gjson.GetBytes(rawJSON, "n"); n.Exists() && n.Type == gjson.Number {
 		if val := n.Int(); val > 1 {
@@ -69,6 +85,14 @@ func ConvertOpenAIRequestToGemini(modelName string, inputRawJSON []byte, _ bool)
 		}
 	}
 
+	// OpenAI logprobs/top_logprobs -> Gemini generationConfig.responseLogprobs/logprobs
+	if lp := gjson.GetBytes(rawJSON, "logprobs"); lp.Type == gjson.True {
+		out, _ = sjson.SetBytes(out, "generationConfig.responseLogprobs", true)
+		if top := gjson.GetBytes(rawJSON, "top_logprobs"); top.Exists() && top.Type == gjson.Number && top.Int() > 0 {
+			out, _ = sjson.SetBytes(out, "generationConfig.logprobs", top.Int())
+		}
+	}
+
 	// Map OpenAI modalities -> Gemini generationConfig.responseModalities
 	// e.g. "modalities": ["image", "text"] -> ["IMAGE", "TEXT"]
 	if mods := gjson.GetBytes(rawJSON, "modalities"); mods.Exists() && mods.IsArray() {
@@ -333,6 +357,10 @@ func ConvertOpenAIRequestToGemini(modelName string, inputRawJSON []byte, _ bool)
 						}
 					}
 					fnRaw, _ = sjson.Delete(fnRaw, "strict")
+					// Gemini rejects several JSON-schema keywords (additionalProperties, $ref, const, ...).
+					if schema := gjson.Get(fnRaw, "parametersJsonSchema"); schema.IsObject() {
+						fnRaw, _ = sjson.SetRaw(fnRaw, "parametersJsonSchema", util.CleanJSONSchemaForGemini(schema.Raw))
+					}
 					if !hasFunction {
 						functionToolNode, _ = sjson.SetRawBytes(functionToolNode, "functionDeclarations", []byte("[]"))
 					}
@@ -394,7 +422,7 @@ func ConvertOpenAIRequestToGemini(modelName string, inputRawJSON []byte, _ bool)
 		}
 	}
 
-	out = common.AttachDefaultSafetySettings(out, "safetySettings")
+	out = common.ApplySafetySettings(out, rawJSON, "safetySettings")
 
 	return out
 }
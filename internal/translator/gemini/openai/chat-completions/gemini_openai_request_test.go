@@ -0,0 +1,109 @@
+package chat_completions
+
+import (
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/common"
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertOpenAIRequestToGemini_CachedContent(t *testing.T) {
+	input := []byte(`{"model":"gemini-2.5-pro","cached_content":"cachedContents/abc123","messages":[{"role":"user","content":"hi"}]}`)
+	out := ConvertOpenAIRequestToGemini("gemini-2.5-pro", input, false)
+	if got := gjson.GetBytes(out, "cachedContent").String(); got != "cachedContents/abc123" {
+		t.Fatalf("cachedContent = %q, want %q; payload=%s", got, "cachedContents/abc123", out)
+	}
+
+	out = ConvertOpenAIRequestToGemini("gemini-2.5-pro", []byte(`{"messages":[{"role":"user","content":"hi"}]}`), false)
+	if gjson.GetBytes(out, "cachedContent").Exists() {
+		t.Fatalf("unexpected cachedContent without a handle: %s", out)
+	}
+}
+
+func TestConvertOpenAIRequestToGemini_Logprobs(t *testing.T) {
+	out := ConvertOpenAIRequestToGemini("gemini-2.5-pro", []byte(`{"logprobs":true,"top_logprobs":3,"messages":[{"role":"user","content":"hi"}]}`), false)
+	if !gjson.GetBytes(out, "generationConfig.responseLogprobs").Bool() {
+		t.Fatalf("responseLogprobs not set: %s", out)
+	}
+	if got := gjson.GetBytes(out, "generationConfig.logprobs").Int(); got != 3 {
+		t.Fatalf("logprobs = %d, want 3", got)
+	}
+
+	out = ConvertOpenAIRequestToGemini("gemini-2.5-pro", []byte(`{"top_logprobs":3,"messages":[{"role":"user","content":"hi"}]}`), false)
+	if gjson.GetBytes(out, "generationConfig.responseLogprobs").Exists() || gjson.GetBytes(out, "generationConfig.logprobs").Exists() {
+		t.Fatalf("logprobs config should be omitted without logprobs=true: %s", out)
+	}
+}
+
+func TestConvertOpenAIRequestToGemini_SafetySettings(t *testing.T) {
+	t.Cleanup(func() { common.SetDefaultSafetySettings(nil) })
+
+	input := []byte(`{"safety_settings":[{"category":"HARM_CATEGORY_HARASSMENT","threshold":"BLOCK_ONLY_HIGH"}],"messages":[{"role":"user","content":"hi"}]}`)
+	out := ConvertOpenAIRequestToGemini("gemini-2.5-pro", input, false)
+	settings := gjson.GetBytes(out, "safetySettings").Array()
+	if len(settings) != 1 || settings[0].Get("threshold").String() != "BLOCK_ONLY_HIGH" {
+		t.Fatalf("safetySettings = %s, want the client's settings", gjson.GetBytes(out, "safetySettings").Raw)
+	}
+	if gjson.GetBytes(out, "safety_settings").Exists() {
+		t.Fatalf("request extension leaked into the Gemini request: %s", out)
+	}
+
+	common.SetDefaultSafetySettings([]map[string]string{{"category": "HARM_CATEGORY_DANGEROUS_CONTENT", "threshold": "BLOCK_MEDIUM_AND_ABOVE"}})
+	out = ConvertOpenAIRequestToGemini("gemini-2.5-pro", []byte(`{"messages":[{"role":"user","content":"hi"}]}`), false)
+	settings = gjson.GetBytes(out, "safetySettings").Array()
+	if len(settings) != 1 || settings[0].Get("category").String() != "HARM_CATEGORY_DANGEROUS_CONTENT" || settings[0].Get("threshold").String() != "BLOCK_MEDIUM_AND_ABOVE" {
+		t.Fatalf("safetySettings = %s, want the configured default", gjson.GetBytes(out, "safetySettings").Raw)
+	}
+}
+
+func TestConvertOpenAIRequestToGemini_DeveloperBecomesSystemInstruction(t *testing.T) {
+	input := []byte(`{"messages":[
+		{"role":"system","content":"Be brief."},
+		{"role":"developer","content":"Answer in French."},
+		{"role":"user","content":"hi"}
+	]}`)
+	out := ConvertOpenAIRequestToGemini("gemini-2.5-pro", input, false)
+	parts := gjson.GetBytes(out, "system_instruction.parts")
+	if got := parts.Get("#").Int(); got != 2 {
+		t.Fatalf("system_instruction parts = %d, want 2: %s", got, out)
+	}
+	if got := parts.Get("1.text").String(); got != "Answer in French." {
+		t.Fatalf("developer text = %q: %s", got, out)
+	}
+	if got := gjson.GetBytes(out, "contents.#").Int(); got != 1 {
+		t.Fatalf("contents = %d, want only the user turn: %s", got, out)
+	}
+}
+
+func TestConvertOpenAIRequestToGemini_ToolSchemaSanitized(t *testing.T) {
+	input := []byte(`{"messages":[{"role":"user","content":"hi"}],"tools":[{"type":"function","function":{
+		"name":"lookup","description":"Look up a record","strict":true,
+		"parameters":{"$schema":"http://json-schema.org/draft-07/schema#","type":"object","additionalProperties":false,
+			"properties":{"id":{"type":"string"},"filter":{"type":"object","additionalProperties":{"type":"string"},"properties":{"kind":{"const":"user"}}}},
+			"required":["id"]}}}]}`)
+	out := ConvertOpenAIRequestToGemini("gemini-2.5-pro", input, false)
+
+	declarations := gjson.GetBytes(out, "tools.0.functionDeclarations").Array()
+	if len(declarations) != 1 {
+		t.Fatalf("functionDeclarations = %s, want one entry", gjson.GetBytes(out, "tools").Raw)
+	}
+	decl := declarations[0]
+	if decl.Get("name").String() != "lookup" || decl.Get("description").String() != "Look up a record" {
+		t.Fatalf("declaration = %s", decl.Raw)
+	}
+	if decl.Get("parameters").Exists() || decl.Get("strict").Exists() {
+		t.Fatalf("OpenAI-only fields leaked into the declaration: %s", decl.Raw)
+	}
+	schema := decl.Get("parametersJsonSchema")
+	if schema.Get("type").String() != "object" || schema.Get("properties.id.type").String() != "string" || schema.Get("required.0").String() != "id" {
+		t.Fatalf("parametersJsonSchema lost its structure: %s", schema.Raw)
+	}
+	for _, path := range []string{"additionalProperties", "$schema", "properties.filter.additionalProperties", "properties.filter.properties.kind.const"} {
+		if schema.Get(path).Exists() {
+			t.Fatalf("unsupported keyword %s kept: %s", path, schema.Raw)
+		}
+	}
+	if got := schema.Get("properties.filter.properties.kind.enum.0").String(); got != "user" {
+		t.Fatalf("const not converted to enum: %s", schema.Raw)
+	}
+}
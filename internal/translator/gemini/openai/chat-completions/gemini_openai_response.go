@@ -13,6 +13,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/common"
 	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
@@ -227,13 +228,20 @@ func ConvertGeminiResponseToOpenAI(_ context.Context, _ string, originalRequestR
 				}
 			}
 
+			if common.LogprobsRequested(originalRequestRawJSON) {
+				if logprobs, ok := common.OpenAILogprobs(candidate); ok {
+					template, _ = sjson.SetRaw(template, "choices.0.logprobs", logprobs)
+				}
+			}
+
 			if hasFunctionCall {
 				template, _ = sjson.Set(template, "choices.0.finish_reason", "tool_calls")
 				template, _ = sjson.Set(template, "choices.0.native_finish_reason", "tool_calls")
 			} else if finishReason != "" {
-				// Only pass through specific finish reasons
-				if finishReason == "max_tokens" || finishReason == "stop" {
-					template, _ = sjson.Set(template, "choices.0.finish_reason", finishReason)
+				// Only pass through specific finish reasons; MAX_TOKENS keeps its partial content and
+				// reports OpenAI's "length", and safety blocks report "content_filter".
+				if mapped := common.OpenAIFinishReason(finishReason); mapped == "length" || mapped == "stop" || mapped == "content_filter" {
+					template, _ = sjson.Set(template, "choices.0.finish_reason", mapped)
 					template, _ = sjson.Set(template, "choices.0.native_finish_reason", finishReason)
 				}
 			}
@@ -323,7 +331,7 @@ func ConvertGeminiResponseToOpenAINonStream(_ context.Context, _ string, origina
 
 			// Set finish reason.
 			if finishReasonResult := candidate.Get("finishReason"); finishReasonResult.Exists() {
-				choiceTemplate, _ = sjson.Set(choiceTemplate, "finish_reason", strings.ToLower(finishReasonResult.String()))
+				choiceTemplate, _ = sjson.Set(choiceTemplate, "finish_reason", common.OpenAIFinishReason(finishReasonResult.String()))
 				choiceTemplate, _ = sjson.Set(choiceTemplate, "native_finish_reason", strings.ToLower(finishReasonResult.String()))
 			}
 
@@ -397,6 +405,12 @@ func ConvertGeminiResponseToOpenAINonStream(_ context.Context, _ string, origina
 				choiceTemplate, _ = sjson.Set(choiceTemplate, "native_finish_reason", "tool_calls")
 			}
 
+			if common.LogprobsRequested(originalRequestRawJSON) {
+				if logprobs, ok := common.OpenAILogprobs(candidate); ok {
+					choiceTemplate, _ = sjson.SetRaw(choiceTemplate, "logprobs", logprobs)
+				}
+			}
+
 			// Append the constructed choice to the main choices array.
 			template, _ = sjson.SetRaw(template, "choices.-1", choiceTemplate)
 			return true
@@ -0,0 +1,85 @@
+package chat_completions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertGeminiResponseToOpenAINonStream_Logprobs(t *testing.T) {
+	response := []byte(`{"responseId":"r1","modelVersion":"gemini-2.5-flash","candidates":[{"index":0,"content":{"role":"model","parts":[{"text":"Hi"}]},"finishReason":"STOP","avgLogprobs":-0.25,"logprobsResult":{"chosenCandidates":[{"token":"Hi","logProbability":-0.25}],"topCandidates":[{"candidates":[{"token":"Hi","logProbability":-0.25},{"token":"Hey","logProbability":-1.5}]}]}}]}`)
+
+	out := ConvertGeminiResponseToOpenAINonStream(context.Background(), "", []byte(`{"logprobs":true,"top_logprobs":2}`), nil, response, nil)
+	logprobs := gjson.Get(out, "choices.0.logprobs")
+	if got := logprobs.Get("avg_logprob").Float(); got != -0.25 {
+		t.Fatalf("avg_logprob = %v, want -0.25; payload=%s", got, out)
+	}
+	if got := logprobs.Get("content.0.token").String(); got != "Hi" {
+		t.Fatalf("content.0.token = %q, want Hi; payload=%s", got, out)
+	}
+	if got := logprobs.Get("content.0.bytes").Raw; got != "[72,105]" {
+		t.Fatalf("content.0.bytes = %s, want [72,105]", got)
+	}
+	if got := logprobs.Get("content.0.top_logprobs.1.logprob").Float(); got != -1.5 {
+		t.Fatalf("top_logprobs.1.logprob = %v, want -1.5; payload=%s", got, out)
+	}
+
+	out = ConvertGeminiResponseToOpenAINonStream(context.Background(), "", []byte(`{}`), nil, response, nil)
+	if gjson.Get(out, "choices.0.logprobs").Exists() {
+		t.Fatalf("logprobs should be omitted when not requested: %s", out)
+	}
+}
+
+func TestConvertGeminiResponseToOpenAINonStream_AvgLogprobsOnly(t *testing.T) {
+	response := []byte(`{"candidates":[{"index":0,"content":{"role":"model","parts":[{"text":"Hi"}]},"finishReason":"STOP","avgLogprobs":-0.5}]}`)
+
+	out := ConvertGeminiResponseToOpenAINonStream(context.Background(), "", []byte(`{"logprobs":true}`), nil, response, nil)
+	logprobs := gjson.Get(out, "choices.0.logprobs")
+	if got := logprobs.Get("avg_logprob").Float(); got != -0.5 {
+		t.Fatalf("avg_logprob = %v, want -0.5; payload=%s", got, out)
+	}
+	if got := logprobs.Get("content").Raw; got != "[]" {
+		t.Fatalf("content = %s, want []", got)
+	}
+}
+
+func TestConvertGeminiResponseToOpenAI_MaxTokensKeepsPartialContent(t *testing.T) {
+	response := []byte(`{"candidates":[{"index":0,"content":{"role":"model","parts":[{"text":"The answer is partly"}]},"finishReason":"MAX_TOKENS"}],"usageMetadata":{"promptTokenCount":5,"candidatesTokenCount":8,"totalTokenCount":13}}`)
+
+	out := ConvertGeminiResponseToOpenAINonStream(context.Background(), "", []byte(`{}`), nil, response, nil)
+	if got := gjson.Get(out, "choices.0.finish_reason").String(); got != "length" {
+		t.Fatalf("finish_reason = %q, want length; payload=%s", got, out)
+	}
+	if got := gjson.Get(out, "choices.0.native_finish_reason").String(); got != "max_tokens" {
+		t.Fatalf("native_finish_reason = %q, want max_tokens", got)
+	}
+	if got := gjson.Get(out, "choices.0.message.content").String(); got != "The answer is partly" {
+		t.Fatalf("content = %q, want the partial text", got)
+	}
+
+	var param any
+	chunks := ConvertGeminiResponseToOpenAI(context.Background(), "", []byte(`{}`), nil, response, &param)
+	if len(chunks) != 1 {
+		t.Fatalf("chunks = %v, want one", chunks)
+	}
+	if got := gjson.Get(chunks[0], "choices.0.finish_reason").String(); got != "length" {
+		t.Fatalf("stream finish_reason = %q, want length; chunk=%s", got, chunks[0])
+	}
+	if got := gjson.Get(chunks[0], "choices.0.delta.content").String(); got != "The answer is partly" {
+		t.Fatalf("stream content = %q, want the partial text", got)
+	}
+}
+
+func TestConvertGeminiResponseToOpenAI_StreamSafetyIsContentFilter(t *testing.T) {
+	response := []byte(`{"candidates":[{"index":0,"content":{"role":"model","parts":[]},"finishReason":"SAFETY"}],"usageMetadata":{"promptTokenCount":5,"totalTokenCount":5}}`)
+
+	var param any
+	chunks := ConvertGeminiResponseToOpenAI(context.Background(), "", []byte(`{}`), nil, response, &param)
+	if len(chunks) != 1 {
+		t.Fatalf("chunks = %v, want one", chunks)
+	}
+	if got := gjson.Get(chunks[0], "choices.0.finish_reason").String(); got != "content_filter" {
+		t.Fatalf("stream finish_reason = %q, want content_filter; chunk=%s", got, chunks[0])
+	}
+}
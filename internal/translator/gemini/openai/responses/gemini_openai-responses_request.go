@@ -116,7 +116,7 @@ func ConvertOpenAIResponsesRequestToGemini(modelName string, inputRawJSON []byte
 
 			switch itemType {
 			case "message":
-				if strings.EqualFold(itemRole, "system") {
+				if strings.EqualFold(itemRole, "system") || strings.EqualFold(itemRole, "developer") {
 					if contentArray := item.Get("content"); contentArray.Exists() {
 						systemInstr := ""
 						if systemInstructionResult := gjson.Get(out, "system_instruction"); systemInstructionResult.Exists() {
@@ -437,6 +437,6 @@ func ConvertOpenAIResponsesRequestToGemini(modelName string, inputRawJSON []byte
 	}
 
 	result := []byte(out)
-	result = common.AttachDefaultSafetySettings(result, "safetySettings")
+	result = common.ApplySafetySettings(result, rawJSON, "safetySettings")
 	return result
 }
@@ -31,10 +31,11 @@ func ConvertClaudeRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 		out, _ = sjson.Set(out, "max_tokens", maxTokens.Int())
 	}
 
-	// Temperature
+	// Temperature and Top P (OpenAI accepts both; top_k has no equivalent)
 	if temp := root.Get("temperature"); temp.Exists() {
 		out, _ = sjson.Set(out, "temperature", temp.Float())
-	} else if topP := root.Get("top_p"); topP.Exists() { // Top P
+	}
+	if topP := root.Get("top_p"); topP.Exists() {
 		out, _ = sjson.Set(out, "top_p", topP.Float())
 	}
 
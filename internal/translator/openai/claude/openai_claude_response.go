@@ -51,6 +51,8 @@ type ConvertOpenAIResponseToAnthropicParams struct {
 	ThinkingContentBlockIndex int
 	// Next available content block index
 	NextContentBlockIndex int
+	// Track if a truncated tool call ended the stream with an error event
+	ToolArgsTruncated bool
 }
 
 // ToolCallAccumulator holds the state for accumulating tool call data
@@ -97,6 +99,16 @@ func ConvertOpenAIResponseToClaude(_ context.Context, _ string, originalRequestR
 	}
 	rawJSON = bytes.TrimSpace(rawJSON[5:])
 
+	// The upstream stream failed: close the tool calls that can be repaired and leave
+	// message_stop out, since the executor returns the stream error next.
+	if util.IsStreamInterrupted(rawJSON) {
+		var results []string
+		if util.ToolArgsOnTruncation() == util.ToolArgsTruncationClose {
+			stopToolCallContentBlocks((*param).(*ConvertOpenAIResponseToAnthropicParams), &results)
+		}
+		return results
+	}
+
 	// Check if this is the [DONE] marker
 	rawStr := strings.TrimSpace(string(rawJSON))
 	if rawStr == "[DONE]" {
@@ -261,33 +273,14 @@ func convertOpenAIStreamingChunkToAnthropic(rawJSON []byte, param *ConvertOpenAI
 		stopTextContentBlock(param, &results)
 
 		// Send content_block_stop for any tool calls
-		if !param.ContentBlocksStopped {
-			for index := range param.ToolCallsAccumulator {
-				accumulator := param.ToolCallsAccumulator[index]
-				blockIndex := param.toolContentBlockIndex(index)
-
-				// Send complete input_json_delta with all accumulated arguments
-				if accumulator.Arguments.Len() > 0 {
-					inputDeltaJSON := `{"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":""}}`
-					inputDeltaJSON, _ = sjson.Set(inputDeltaJSON, "index", blockIndex)
-					inputDeltaJSON, _ = sjson.Set(inputDeltaJSON, "delta.partial_json", util.FixJSON(accumulator.Arguments.String()))
-					results = append(results, "event: content_block_delta\ndata: "+inputDeltaJSON+"\n\n")
-				}
-
-				contentBlockStopJSON := `{"type":"content_block_stop","index":0}`
-				contentBlockStopJSON, _ = sjson.Set(contentBlockStopJSON, "index", blockIndex)
-				results = append(results, "event: content_block_stop\ndata: "+contentBlockStopJSON+"\n\n")
-				delete(param.ToolCallBlockIndexes, index)
-			}
-			param.ContentBlocksStopped = true
-		}
+		stopToolCallContentBlocks(param, &results)
 
 		// Don't send message_delta here - wait for usage info or [DONE]
 	}
 
 	// Handle usage information separately (this comes in a later chunk)
 	// Only process if usage has actual values (not null)
-	if param.FinishReason != "" {
+	if param.FinishReason != "" && !param.ToolArgsTruncated {
 		usage := root.Get("usage")
 		var inputTokens, outputTokens, cachedTokens int64
 		if usage.Exists() && usage.Type != gjson.Null {
@@ -325,24 +318,9 @@ func convertOpenAIDoneToAnthropic(param *ConvertOpenAIResponseToAnthropicParams)
 
 	stopTextContentBlock(param, &results)
 
-	if !param.ContentBlocksStopped {
-		for index := range param.ToolCallsAccumulator {
-			accumulator := param.ToolCallsAccumulator[index]
-			blockIndex := param.toolContentBlockIndex(index)
-
-			if accumulator.Arguments.Len() > 0 {
-				inputDeltaJSON := `{"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":""}}`
-				inputDeltaJSON, _ = sjson.Set(inputDeltaJSON, "index", blockIndex)
-				inputDeltaJSON, _ = sjson.Set(inputDeltaJSON, "delta.partial_json", util.FixJSON(accumulator.Arguments.String()))
-				results = append(results, "event: content_block_delta\ndata: "+inputDeltaJSON+"\n\n")
-			}
-
-			contentBlockStopJSON := `{"type":"content_block_stop","index":0}`
-			contentBlockStopJSON, _ = sjson.Set(contentBlockStopJSON, "index", blockIndex)
-			results = append(results, "event: content_block_stop\ndata: "+contentBlockStopJSON+"\n\n")
-			delete(param.ToolCallBlockIndexes, index)
-		}
-		param.ContentBlocksStopped = true
+	stopToolCallContentBlocks(param, &results)
+	if param.ToolArgsTruncated {
+		return results
 	}
 
 	// If we haven't sent message_delta yet (no usage info was received), send it now
@@ -501,6 +479,44 @@ func stopThinkingContentBlock(param *ConvertOpenAIResponseToAnthropicParams, res
 	param.ThinkingContentBlockIndex = -1
 }
 
+// stopToolCallContentBlocks flushes the accumulated arguments of every tool call and
+// closes its content block. Arguments that end mid-JSON are handled according to
+// util.ToolArgsOnTruncation: repaired when set to "close", otherwise an error event
+// ends the stream.
+func stopToolCallContentBlocks(param *ConvertOpenAIResponseToAnthropicParams, results *[]string) {
+	if param.ContentBlocksStopped {
+		return
+	}
+	for index := range param.ToolCallsAccumulator {
+		accumulator := param.ToolCallsAccumulator[index]
+		blockIndex := param.toolContentBlockIndex(index)
+
+		// Send complete input_json_delta with all accumulated arguments
+		if accumulator.Arguments.Len() > 0 {
+			args, ok := util.FinalizeToolArguments(accumulator.Arguments.String())
+			if !ok {
+				errorJSON := `{"type":"error","error":{"type":"api_error","message":""}}`
+				errorJSON, _ = sjson.Set(errorJSON, "error.message", fmt.Sprintf("tool call %q arguments were truncated", accumulator.Name))
+				*results = append(*results, "event: error\ndata: "+errorJSON+"\n\n")
+				param.ToolArgsTruncated = true
+				param.ContentBlocksStopped = true
+				param.MessageStopSent = true
+				return
+			}
+			inputDeltaJSON := `{"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":""}}`
+			inputDeltaJSON, _ = sjson.Set(inputDeltaJSON, "index", blockIndex)
+			inputDeltaJSON, _ = sjson.Set(inputDeltaJSON, "delta.partial_json", args)
+			*results = append(*results, "event: content_block_delta\ndata: "+inputDeltaJSON+"\n\n")
+		}
+
+		contentBlockStopJSON := `{"type":"content_block_stop","index":0}`
+		contentBlockStopJSON, _ = sjson.Set(contentBlockStopJSON, "index", blockIndex)
+		*results = append(*results, "event: content_block_stop\ndata: "+contentBlockStopJSON+"\n\n")
+		delete(param.ToolCallBlockIndexes, index)
+	}
+	param.ContentBlocksStopped = true
+}
+
 func emitMessageStopIfNeeded(param *ConvertOpenAIResponseToAnthropicParams, results *[]string) {
 	if param.MessageStopSent {
 		return
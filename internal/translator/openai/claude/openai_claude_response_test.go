@@ -0,0 +1,72 @@
+package claude
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/tidwall/gjson"
+)
+
+func runTruncatedToolCallStream(t *testing.T) []string {
+	t.Helper()
+	originalRequest := []byte(`{"model":"gpt-test","stream":true}`)
+	chunks := []string{
+		`data: {"id":"chatcmpl-1","model":"gpt-test","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"read_file","arguments":""}}]}}]}`,
+		`data: {"id":"chatcmpl-1","model":"gpt-test","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"path\":\"/tmp/fi"}}]}}]}`,
+		`data: {"id":"chatcmpl-1","model":"gpt-test","choices":[{"index":0,"delta":{},"finish_reason":"length"}]}`,
+		`data: [DONE]`,
+	}
+	var param any
+	var events []string
+	for _, chunk := range chunks {
+		events = append(events, ConvertOpenAIResponseToClaude(context.Background(), "gpt-test", originalRequest, nil, []byte(chunk), &param)...)
+	}
+	return events
+}
+
+func setToolArgsOnTruncation(t *testing.T, action string) {
+	t.Helper()
+	previous := util.ToolArgsOnTruncation()
+	util.SetToolArgsOnTruncation(action)
+	t.Cleanup(func() { util.SetToolArgsOnTruncation(previous) })
+}
+
+func TestConvertOpenAIResponseToClaude_TruncatedToolArgsError(t *testing.T) {
+	setToolArgsOnTruncation(t, "")
+
+	events := runTruncatedToolCallStream(t)
+	joined := strings.Join(events, "")
+	if !strings.Contains(joined, "event: error\n") {
+		t.Fatalf("expected error event for truncated tool arguments, got %q", joined)
+	}
+	if strings.Contains(joined, "input_json_delta") {
+		t.Fatalf("expected truncated arguments not to be forwarded, got %q", joined)
+	}
+	if strings.Contains(joined, "message_stop") {
+		t.Fatalf("expected no message_stop after error event, got %q", joined)
+	}
+}
+
+func TestConvertOpenAIResponseToClaude_TruncatedToolArgsClose(t *testing.T) {
+	setToolArgsOnTruncation(t, "close")
+
+	events := runTruncatedToolCallStream(t)
+	var partialJSON string
+	for _, event := range events {
+		if !strings.HasPrefix(event, "event: content_block_delta") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(event[strings.Index(event, "data: "):], "data: "))
+		if gjson.Get(data, "delta.type").String() == "input_json_delta" {
+			partialJSON = gjson.Get(data, "delta.partial_json").String()
+		}
+	}
+	if partialJSON != `{"path":"/tmp/fi"}` {
+		t.Fatalf("partial_json = %q, want %q", partialJSON, `{"path":"/tmp/fi"}`)
+	}
+	if joined := strings.Join(events, ""); strings.Contains(joined, "event: error") {
+		t.Fatalf("expected no error event in close mode, got %q", joined)
+	}
+}
@@ -64,6 +64,17 @@ func ConvertGeminiRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 			out, _ = sjson.Set(out, "top_k", topK.Int())
 		}
 
+		// Penalties and seed
+		if v := genConfig.Get("presencePenalty"); v.Type == gjson.Number {
+			out, _ = sjson.Set(out, "presence_penalty", v.Float())
+		}
+		if v := genConfig.Get("frequencyPenalty"); v.Type == gjson.Number {
+			out, _ = sjson.Set(out, "frequency_penalty", v.Float())
+		}
+		if v := genConfig.Get("seed"); v.Type == gjson.Number {
+			out, _ = sjson.Set(out, "seed", v.Int())
+		}
+
 		// Stop sequences
 		if stopSequences := genConfig.Get("stopSequences"); stopSequences.Exists() && stopSequences.IsArray() {
 			var stops []string
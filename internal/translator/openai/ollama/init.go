@@ -0,0 +1,20 @@
+package ollama
+
+import (
+	. "github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/translator"
+)
+
+func init() {
+	translator.Register(
+		OllamaChat,
+		OpenAI,
+		ConvertOllamaRequestToOpenAI,
+		interfaces.TranslateResponse{
+			Stream:     ConvertOpenAIResponseToOllama,
+			NonStream:  ConvertOpenAIResponseToOllamaNonStream,
+			TokenCount: OllamaTokenCount,
+		},
+	)
+}
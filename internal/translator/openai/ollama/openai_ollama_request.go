@@ -0,0 +1,183 @@
+// Package ollama provides request translation functionality for the Ollama /api/chat format
+// to the OpenAI Chat Completions API. It maps Ollama messages, images, tool calls, options and
+// structured output settings onto their OpenAI equivalents.
+package ollama
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ConvertOllamaRequestToOpenAI parses an Ollama /api/chat request and transforms it into an
+// OpenAI Chat Completions request. Ollama tool calls carry no IDs, so IDs are generated per
+// call and tool results are matched back to them by function name.
+func ConvertOllamaRequestToOpenAI(modelName string, inputRawJSON []byte, stream bool) []byte {
+	root := gjson.ParseBytes(inputRawJSON)
+	out := `{"model":"","messages":[]}`
+	out, _ = sjson.Set(out, "model", modelName)
+	out, _ = sjson.Set(out, "stream", stream)
+	if stream {
+		// eval_count and prompt_eval_count need the trailing usage chunk.
+		out, _ = sjson.Set(out, "stream_options.include_usage", true)
+	}
+
+	if options := root.Get("options"); options.IsObject() {
+		if v := options.Get("temperature"); v.Type == gjson.Number {
+			out, _ = sjson.Set(out, "temperature", v.Float())
+		}
+		if v := options.Get("top_p"); v.Type == gjson.Number {
+			out, _ = sjson.Set(out, "top_p", v.Float())
+		}
+		if v := options.Get("top_k"); v.Type == gjson.Number {
+			out, _ = sjson.Set(out, "top_k", v.Int())
+		}
+		if v := options.Get("num_predict"); v.Type == gjson.Number && v.Int() > 0 {
+			out, _ = sjson.Set(out, "max_tokens", v.Int())
+		}
+		if v := options.Get("seed"); v.Type == gjson.Number {
+			out, _ = sjson.Set(out, "seed", v.Int())
+		}
+		if v := options.Get("presence_penalty"); v.Type == gjson.Number {
+			out, _ = sjson.Set(out, "presence_penalty", v.Float())
+		}
+		if v := options.Get("frequency_penalty"); v.Type == gjson.Number {
+			out, _ = sjson.Set(out, "frequency_penalty", v.Float())
+		}
+		if stop := options.Get("stop"); stop.IsArray() && len(stop.Array()) > 0 {
+			out, _ = sjson.SetRaw(out, "stop", stop.Raw)
+		}
+	}
+
+	// format is either "json" or a JSON schema object.
+	if format := root.Get("format"); format.Exists() {
+		switch {
+		case format.IsObject():
+			out, _ = sjson.Set(out, "response_format.type", "json_schema")
+			out, _ = sjson.Set(out, "response_format.json_schema.name", "response")
+			out, _ = sjson.SetRaw(out, "response_format.json_schema.schema", format.Raw)
+		case format.String() == "json":
+			out, _ = sjson.Set(out, "response_format.type", "json_object")
+		}
+	}
+
+	// think is a bool or, for models that support it, a level.
+	if think := root.Get("think"); think.Exists() {
+		switch think.Type {
+		case gjson.True:
+			out, _ = sjson.Set(out, "reasoning_effort", string(thinking.LevelMedium))
+		case gjson.False:
+			out, _ = sjson.Set(out, "reasoning_effort", string(thinking.LevelNone))
+		case gjson.String:
+			if level := strings.ToLower(strings.TrimSpace(think.String())); level != "" {
+				out, _ = sjson.Set(out, "reasoning_effort", level)
+			}
+		}
+	}
+
+	if tools := root.Get("tools"); tools.IsArray() && len(tools.Array()) > 0 {
+		out, _ = sjson.SetRaw(out, "tools", tools.Raw)
+	}
+
+	pendingCallIDs := make(map[string][]string)
+	callCount := 0
+	root.Get("messages").ForEach(func(_, message gjson.Result) bool {
+		role := message.Get("role").String()
+		msg := `{}`
+		msg, _ = sjson.Set(msg, "role", role)
+
+		switch role {
+		case "assistant":
+			if content := message.Get("content").String(); content != "" {
+				msg, _ = sjson.Set(msg, "content", content)
+			}
+			if thinkingText := message.Get("thinking").String(); thinkingText != "" {
+				msg, _ = sjson.Set(msg, "reasoning_content", thinkingText)
+			}
+			message.Get("tool_calls").ForEach(func(_, call gjson.Result) bool {
+				name := call.Get("function.name").String()
+				callCount++
+				id := fmt.Sprintf("call_%d", callCount)
+				pendingCallIDs[name] = append(pendingCallIDs[name], id)
+				args := call.Get("function.arguments")
+				argsJSON := "{}"
+				switch {
+				case args.IsObject():
+					argsJSON = args.Raw
+				case args.Type == gjson.String && args.String() != "":
+					argsJSON = args.String()
+				}
+				toolCall := `{"type":"function","function":{}}`
+				toolCall, _ = sjson.Set(toolCall, "id", id)
+				toolCall, _ = sjson.Set(toolCall, "function.name", name)
+				toolCall, _ = sjson.Set(toolCall, "function.arguments", argsJSON)
+				msg, _ = sjson.SetRaw(msg, "tool_calls.-1", toolCall)
+				return true
+			})
+			if !gjson.Get(msg, "content").Exists() && !gjson.Get(msg, "tool_calls").Exists() {
+				msg, _ = sjson.Set(msg, "content", "")
+			}
+		case "tool":
+			name := message.Get("tool_name").String()
+			if name == "" {
+				name = message.Get("name").String()
+			}
+			id := ""
+			if ids := pendingCallIDs[name]; len(ids) > 0 {
+				id = ids[0]
+				pendingCallIDs[name] = ids[1:]
+			} else {
+				callCount++
+				id = fmt.Sprintf("call_%d", callCount)
+			}
+			msg, _ = sjson.Set(msg, "tool_call_id", id)
+			msg, _ = sjson.Set(msg, "content", message.Get("content").String())
+		default:
+			content := message.Get("content").String()
+			images := message.Get("images").Array()
+			if len(images) == 0 {
+				msg, _ = sjson.Set(msg, "content", content)
+				break
+			}
+			msg, _ = sjson.SetRaw(msg, "content", "[]")
+			if content != "" {
+				part := `{"type":"text","text":""}`
+				part, _ = sjson.Set(part, "text", content)
+				msg, _ = sjson.SetRaw(msg, "content.-1", part)
+			}
+			for _, image := range images {
+				part := `{"type":"image_url","image_url":{"url":""}}`
+				part, _ = sjson.Set(part, "image_url.url", imageDataURL(image.String()))
+				msg, _ = sjson.SetRaw(msg, "content.-1", part)
+			}
+		}
+		out, _ = sjson.SetRaw(out, "messages.-1", msg)
+		return true
+	})
+
+	return []byte(out)
+}
+
+// imageDataURL wraps an Ollama base64 image in a data URL, sniffing its media type.
+func imageDataURL(data string) string {
+	data = strings.TrimSpace(data)
+	if strings.HasPrefix(data, "data:") {
+		return data
+	}
+	mimeType := "image/png"
+	prefix := data
+	if len(prefix) > 64 {
+		prefix = prefix[:64]
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(prefix[:len(prefix)/4*4]); err == nil && len(decoded) > 0 {
+		if detected := http.DetectContentType(decoded); strings.HasPrefix(detected, "image/") {
+			mimeType = detected
+		}
+	}
+	return "data:" + mimeType + ";base64," + data
+}
@@ -0,0 +1,50 @@
+package ollama
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertOllamaRequestToOpenAI(t *testing.T) {
+	input := []byte(`{
+		"model": "llama3.2",
+		"stream": true,
+		"format": "json",
+		"options": {"temperature": 0.2, "num_predict": 128, "stop": ["END"]},
+		"messages": [
+			{"role": "system", "content": "be brief"},
+			{"role": "user", "content": "what is in this image?", "images": ["iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg=="]},
+			{"role": "assistant", "content": "", "tool_calls": [{"function": {"name": "lookup", "arguments": {"q": "cat"}}}]},
+			{"role": "tool", "tool_name": "lookup", "content": "a cat"}
+		]
+	}`)
+
+	out := ConvertOllamaRequestToOpenAI("gpt-4o", input, true)
+	want := map[string]string{
+		"model":                                 "gpt-4o",
+		"stream":                                "true",
+		"stream_options.include_usage":          "true",
+		"temperature":                           "0.2",
+		"max_tokens":                            "128",
+		"stop.0":                                "END",
+		"response_format.type":                  "json_object",
+		"messages.0.content":                    "be brief",
+		"messages.1.content.0.text":             "what is in this image?",
+		"messages.2.tool_calls.0.function.name": "lookup",
+		"messages.2.tool_calls.0.function.arguments": `{"q": "cat"}`,
+		"messages.3.role":    "tool",
+		"messages.3.content": "a cat",
+	}
+	for path, value := range want {
+		if got := gjson.GetBytes(out, path).String(); got != value {
+			t.Fatalf("%s = %q, want %q in %s", path, got, value, out)
+		}
+	}
+	if url := gjson.GetBytes(out, "messages.1.content.1.image_url.url").String(); url[:22] != "data:image/png;base64," {
+		t.Fatalf("image url = %q, want a PNG data URL", url)
+	}
+	if callID := gjson.GetBytes(out, "messages.2.tool_calls.0.id").String(); callID == "" || gjson.GetBytes(out, "messages.3.tool_call_id").String() != callID {
+		t.Fatalf("tool result is not linked to its call: %s", out)
+	}
+}
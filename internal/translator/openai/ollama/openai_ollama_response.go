@@ -0,0 +1,210 @@
+// Package ollama provides response translation functionality for OpenAI to the Ollama /api/chat
+// format. Streaming responses become newline-delimited JSON objects rather than SSE events; the
+// final object carries done, done_reason and the prompt_eval_count/eval_count usage fields.
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// convertOpenAIResponseToOllamaParams holds streaming state between chunks.
+type convertOpenAIResponseToOllamaParams struct {
+	// ToolCalls accumulates streamed tool call deltas by index; Ollama sends each call whole.
+	ToolCalls map[int]*ollamaToolCall
+	// DoneReason is the mapped finish reason, set once the upstream finishes.
+	DoneReason string
+	// PromptTokens and CompletionTokens come from the trailing usage chunk.
+	PromptTokens     int64
+	CompletionTokens int64
+	// Done records that the final object has been emitted.
+	Done bool
+}
+
+type ollamaToolCall struct {
+	Name      string
+	Arguments strings.Builder
+}
+
+// ConvertOpenAIResponseToOllama converts an OpenAI Chat Completions streaming chunk into Ollama
+// /api/chat stream objects, one JSON object per output line. Tool calls are held until the
+// upstream finishes, and the closing done object is emitted after the usage chunk or [DONE].
+//
+// Parameters:
+//   - ctx: The context for the request.
+//   - modelName: The name of the model.
+//   - rawJSON: The raw JSON chunk from the OpenAI API.
+//   - param: A pointer to a parameter object for the conversion.
+//
+// Returns:
+//   - []string: Ollama stream objects, each written as one line.
+func ConvertOpenAIResponseToOllama(_ context.Context, modelName string, originalRequestRawJSON, _, rawJSON []byte, param *any) []string {
+	if *param == nil {
+		*param = &convertOpenAIResponseToOllamaParams{ToolCalls: make(map[int]*ollamaToolCall)}
+	}
+	state := (*param).(*convertOpenAIResponseToOllamaParams)
+	if state.Done {
+		return nil
+	}
+	model := ollamaModelName(modelName, originalRequestRawJSON)
+
+	if bytes.HasPrefix(rawJSON, []byte("data:")) {
+		rawJSON = bytes.TrimSpace(rawJSON[5:])
+	}
+	if strings.TrimSpace(string(rawJSON)) == "[DONE]" {
+		return state.finish(model)
+	}
+
+	root := gjson.ParseBytes(rawJSON)
+	if usage := root.Get("usage"); usage.IsObject() {
+		state.PromptTokens = usage.Get("prompt_tokens").Int()
+		state.CompletionTokens = usage.Get("completion_tokens").Int()
+	}
+
+	var results []string
+	choice := root.Get("choices.0")
+	if choice.Exists() {
+		delta := choice.Get("delta")
+		content := delta.Get("content").String()
+		reasoning := delta.Get("reasoning_content").String()
+		if content != "" || reasoning != "" {
+			message := `{"role":"assistant","content":""}`
+			message, _ = sjson.Set(message, "content", content)
+			if reasoning != "" {
+				message, _ = sjson.Set(message, "thinking", reasoning)
+			}
+			results = append(results, ollamaChunk(model, message, false))
+		}
+		delta.Get("tool_calls").ForEach(func(_, call gjson.Result) bool {
+			index := int(call.Get("index").Int())
+			acc := state.ToolCalls[index]
+			if acc == nil {
+				acc = &ollamaToolCall{}
+				state.ToolCalls[index] = acc
+			}
+			if name := call.Get("function.name").String(); name != "" {
+				acc.Name = name
+			}
+			acc.Arguments.WriteString(call.Get("function.arguments").String())
+			return true
+		})
+		if finish := choice.Get("finish_reason").String(); finish != "" {
+			state.DoneReason = mapOpenAIFinishReasonToOllama(finish)
+			if len(state.ToolCalls) > 0 {
+				message := `{"role":"assistant","content":""}`
+				message, _ = sjson.SetRaw(message, "tool_calls", state.toolCallsJSON())
+				results = append(results, ollamaChunk(model, message, false))
+				state.ToolCalls = make(map[int]*ollamaToolCall)
+			}
+		}
+	}
+	// The usage-only chunk follows the finish reason when include_usage is set.
+	if state.DoneReason != "" && root.Get("usage").IsObject() {
+		results = append(results, state.finish(model)...)
+	}
+	return results
+}
+
+// finish emits the closing done object once.
+func (s *convertOpenAIResponseToOllamaParams) finish(model string) []string {
+	if s.Done {
+		return nil
+	}
+	s.Done = true
+	reason := s.DoneReason
+	if reason == "" {
+		reason = "stop"
+	}
+	out := ollamaChunk(model, `{"role":"assistant","content":""}`, true)
+	out, _ = sjson.Set(out, "done_reason", reason)
+	out, _ = sjson.Set(out, "prompt_eval_count", s.PromptTokens)
+	out, _ = sjson.Set(out, "eval_count", s.CompletionTokens)
+	return []string{out}
+}
+
+func (s *convertOpenAIResponseToOllamaParams) toolCallsJSON() string {
+	indexes := make([]int, 0, len(s.ToolCalls))
+	for index := range s.ToolCalls {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	calls := "[]"
+	for _, index := range indexes {
+		acc := s.ToolCalls[index]
+		calls, _ = sjson.SetRaw(calls, "-1", ollamaToolCallJSON(acc.Name, acc.Arguments.String()))
+	}
+	return calls
+}
+
+// ConvertOpenAIResponseToOllamaNonStream converts a non-streaming OpenAI Chat Completions
+// response into a single Ollama /api/chat response object.
+func ConvertOpenAIResponseToOllamaNonStream(_ context.Context, modelName string, originalRequestRawJSON, _, rawJSON []byte, _ *any) string {
+	root := gjson.ParseBytes(rawJSON)
+	model := ollamaModelName(modelName, originalRequestRawJSON)
+	message := `{"role":"assistant","content":""}`
+	choice := root.Get("choices.0")
+	message, _ = sjson.Set(message, "content", choice.Get("message.content").String())
+	if reasoning := choice.Get("message.reasoning_content").String(); reasoning != "" {
+		message, _ = sjson.Set(message, "thinking", reasoning)
+	}
+	choice.Get("message.tool_calls").ForEach(func(_, call gjson.Result) bool {
+		message, _ = sjson.SetRaw(message, "tool_calls.-1", ollamaToolCallJSON(call.Get("function.name").String(), call.Get("function.arguments").String()))
+		return true
+	})
+
+	out := ollamaChunk(model, message, true)
+	out, _ = sjson.Set(out, "done_reason", mapOpenAIFinishReasonToOllama(choice.Get("finish_reason").String()))
+	out, _ = sjson.Set(out, "prompt_eval_count", root.Get("usage.prompt_tokens").Int())
+	out, _ = sjson.Set(out, "eval_count", root.Get("usage.completion_tokens").Int())
+	return out
+}
+
+// OllamaTokenCount reports a token count in Ollama's usage field.
+func OllamaTokenCount(_ context.Context, count int64) string {
+	out, _ := sjson.Set(`{"prompt_eval_count":0}`, "prompt_eval_count", count)
+	return out
+}
+
+// ollamaChunk builds one Ollama chat object around message.
+func ollamaChunk(model, message string, done bool) string {
+	out := `{"model":"","created_at":"","message":{},"done":false}`
+	out, _ = sjson.Set(out, "model", model)
+	out, _ = sjson.Set(out, "created_at", time.Now().UTC().Format(time.RFC3339Nano))
+	out, _ = sjson.SetRaw(out, "message", message)
+	out, _ = sjson.Set(out, "done", done)
+	return out
+}
+
+// ollamaToolCallJSON converts an OpenAI function call into Ollama's shape, where arguments is
+// an object rather than a JSON string.
+func ollamaToolCallJSON(name, arguments string) string {
+	call := `{"function":{"name":"","arguments":{}}}`
+	call, _ = sjson.Set(call, "function.name", name)
+	if args := gjson.Parse(arguments); args.IsObject() {
+		call, _ = sjson.SetRaw(call, "function.arguments", args.Raw)
+	}
+	return call
+}
+
+// ollamaModelName prefers the model the client asked for.
+func ollamaModelName(modelName string, originalRequestRawJSON []byte) string {
+	if model := gjson.GetBytes(originalRequestRawJSON, "model").String(); model != "" {
+		return model
+	}
+	return modelName
+}
+
+func mapOpenAIFinishReasonToOllama(reason string) string {
+	switch reason {
+	case "length":
+		return "length"
+	default:
+		return "stop"
+	}
+}
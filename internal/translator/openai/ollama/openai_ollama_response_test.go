@@ -0,0 +1,41 @@
+package ollama
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertOpenAIResponseToOllama_StreamShape(t *testing.T) {
+	request := []byte(`{"model":"llama3.2","messages":[{"role":"user","content":"hi"}]}`)
+	chunks := []string{
+		`data: {"id":"c1","model":"gpt-4o","choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"}}]}`,
+		`data: {"id":"c1","model":"gpt-4o","choices":[{"index":0,"delta":{"content":"lo"}}]}`,
+		`data: {"id":"c1","model":"gpt-4o","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		`data: {"id":"c1","model":"gpt-4o","choices":[],"usage":{"prompt_tokens":7,"completion_tokens":2,"total_tokens":9}}`,
+		`data: [DONE]`,
+	}
+
+	var param any
+	var lines []string
+	for _, chunk := range chunks {
+		lines = append(lines, ConvertOpenAIResponseToOllama(context.Background(), "gpt-4o", request, nil, []byte(chunk), &param)...)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("got %d objects, want 2 content objects and 1 done object: %v", len(lines), lines)
+	}
+	for i, want := range []string{"Hel", "lo"} {
+		line := gjson.Parse(lines[i])
+		if line.Get("message.content").String() != want || line.Get("done").Bool() || line.Get("model").String() != "llama3.2" {
+			t.Fatalf("object %d = %s, want content %q not done", i, lines[i], want)
+		}
+	}
+	final := gjson.Parse(lines[2])
+	if !final.Get("done").Bool() || final.Get("done_reason").String() != "stop" {
+		t.Fatalf("final object = %s, want done with done_reason stop", lines[2])
+	}
+	if final.Get("prompt_eval_count").Int() != 7 || final.Get("eval_count").Int() != 2 {
+		t.Fatalf("final object = %s, want prompt_eval_count 7 and eval_count 2", lines[2])
+	}
+}
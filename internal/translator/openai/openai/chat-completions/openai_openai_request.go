@@ -3,6 +3,7 @@
 package chat_completions
 
 import (
+	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
 
@@ -26,5 +27,11 @@ func ConvertOpenAIRequestToOpenAI(modelName string, inputRawJSON []byte, _ bool)
 		// handling mechanism would be needed.
 		return inputRawJSON
 	}
+	// safety_settings is a Gemini-only request extension; OpenAI-compatible upstreams ignore it.
+	if gjson.GetBytes(updatedJSON, "safety_settings").Exists() {
+		if stripped, errDelete := sjson.DeleteBytes(updatedJSON, "safety_settings"); errDelete == nil {
+			updatedJSON = stripped
+		}
+	}
 	return updatedJSON
 }
@@ -68,7 +68,8 @@ func ConvertOpenAIResponsesRequestToOpenAIChatCompletions(modelName string, inpu
 				// Handle regular message conversion
 				role := item.Get("role").String()
 				if role == "developer" {
-					role = "user"
+					// Chat Completions upstreams do not all accept developer; system carries the same weight.
+					role = "system"
 				}
 				message := `{"role":"","content":[]}`
 				message, _ = sjson.Set(message, "role", role)
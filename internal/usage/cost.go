@@ -0,0 +1,76 @@
+package usage
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+var pricingTable atomic.Pointer[[]config.ModelPricing]
+
+// SetPricing replaces the pricing table used to compute request costs.
+func SetPricing(entries []config.ModelPricing) {
+	table := make([]config.ModelPricing, 0, len(entries))
+	for _, entry := range entries {
+		entry.Model = strings.ToLower(strings.TrimSpace(entry.Model))
+		entry.Provider = strings.ToLower(strings.TrimSpace(entry.Provider))
+		if entry.Model == "" {
+			continue
+		}
+		table = append(table, entry)
+	}
+	pricingTable.Store(&table)
+}
+
+// Cost returns the USD cost of tokens for provider/model and whether a price matched.
+// Reasoning tokens are billed at the thinking rate in addition to output tokens, since
+// providers such as Gemini report thoughtsTokenCount separately from candidates.
+func Cost(provider, model string, tokens TokenStats) (float64, bool) {
+	price, ok := lookupPrice(provider, model)
+	if !ok {
+		return 0, false
+	}
+	cost := float64(tokens.InputTokens)*price.InputPerMillion +
+		float64(tokens.OutputTokens)*price.OutputPerMillion +
+		float64(tokens.ReasoningTokens)*price.ThinkingPerMillion
+	return cost / 1_000_000, true
+}
+
+// lookupPrice picks the most specific entry for provider/model: exact model names win
+// over prefix patterns, longer prefixes over shorter ones, and provider-scoped entries
+// over unscoped ones.
+func lookupPrice(provider, model string) (config.ModelPricing, bool) {
+	table := pricingTable.Load()
+	if table == nil {
+		return config.ModelPricing{}, false
+	}
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	model = strings.ToLower(strings.TrimSpace(model))
+
+	var best config.ModelPricing
+	bestScore := -1
+	for _, entry := range *table {
+		if entry.Provider != "" && entry.Provider != provider {
+			continue
+		}
+		score := 0
+		if prefix, isPattern := strings.CutSuffix(entry.Model, "*"); isPattern {
+			if !strings.HasPrefix(model, prefix) {
+				continue
+			}
+			score = len(prefix) * 2
+		} else if entry.Model == model {
+			score = 1 << 20
+		} else {
+			continue
+		}
+		if entry.Provider != "" {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = entry, score
+		}
+	}
+	return best, bestScore >= 0
+}
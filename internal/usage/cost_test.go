@@ -0,0 +1,86 @@
+package usage
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+func TestCost_PricesThinkingTokensSeparately(t *testing.T) {
+	SetPricing([]config.ModelPricing{
+		{Model: "gemini-2.5-*", InputPerMillion: 0.30, OutputPerMillion: 2.50, ThinkingPerMillion: 2.50},
+		{Model: "gemini-2.5-pro", Provider: "gemini", InputPerMillion: 1.25, OutputPerMillion: 10, ThinkingPerMillion: 3.5},
+	})
+	t.Cleanup(func() { SetPricing(nil) })
+
+	// usageMetadata: promptTokenCount=1000, candidatesTokenCount=500, thoughtsTokenCount=200
+	tokens := TokenStats{InputTokens: 1000, OutputTokens: 500, ReasoningTokens: 200}
+	want := (1000*1.25 + 500*10 + 200*3.5) / 1_000_000
+
+	cost, ok := Cost("gemini", "gemini-2.5-pro", tokens)
+	if !ok || math.Abs(cost-want) > 1e-12 {
+		t.Fatalf("Cost(gemini-2.5-pro) = %v, %v; want %v", cost, ok, want)
+	}
+
+	// Other providers fall back to the unscoped prefix entry.
+	wantPrefix := (1000*0.30 + 500*2.50 + 200*2.50) / 1_000_000
+	if cost, ok = Cost("vertex", "gemini-2.5-pro", tokens); !ok || math.Abs(cost-wantPrefix) > 1e-12 {
+		t.Fatalf("Cost(vertex gemini-2.5-pro) = %v, %v; want %v", cost, ok, wantPrefix)
+	}
+	if _, ok = Cost("codex", "gpt-5", tokens); ok {
+		t.Fatal("expected no price for unconfigured model")
+	}
+
+	stats := NewRequestStatistics()
+	stats.Record(context.Background(), coreusage.Record{
+		APIKey:   "key",
+		Provider: "gemini",
+		Model:    "gemini-2.5-pro",
+		Detail:   coreusage.Detail{InputTokens: 1000, OutputTokens: 500, ReasoningTokens: 200},
+	})
+	snapshot := stats.Snapshot()
+	if math.Abs(snapshot.TotalCost-want) > 1e-12 || math.Abs(snapshot.CostByProvider["gemini"]-want) > 1e-12 {
+		t.Fatalf("snapshot cost = %v (by provider %v), want %v", snapshot.TotalCost, snapshot.CostByProvider, want)
+	}
+	model := snapshot.APIs["key"].Models["gemini-2.5-pro"]
+	if math.Abs(model.TotalCost-want) > 1e-12 || math.Abs(model.Details[0].Cost-want) > 1e-12 {
+		t.Fatalf("model cost = %+v, want %v", model, want)
+	}
+
+	// Response cache hits reuse a paid response and add no cost.
+	stats.Record(context.Background(), coreusage.Record{
+		APIKey: "key",
+		Model:  "gemini-2.5-pro",
+		Source: coreusage.SourceResponseCache,
+		Detail: coreusage.Detail{InputTokens: 1000, OutputTokens: 500, ReasoningTokens: 200},
+	})
+	if snapshot = stats.Snapshot(); math.Abs(snapshot.TotalCost-want) > 1e-12 {
+		t.Fatalf("snapshot cost after cache hit = %v, want %v", snapshot.TotalCost, want)
+	}
+}
+
+func TestMergeSnapshotRebuildsCostByProvider(t *testing.T) {
+	SetPricing([]config.ModelPricing{{Model: "gemini-2.5-pro", InputPerMillion: 1, OutputPerMillion: 2}})
+	t.Cleanup(func() { SetPricing(nil) })
+
+	source := NewRequestStatistics()
+	source.Record(context.Background(), coreusage.Record{
+		APIKey:   "key",
+		Provider: "gemini",
+		Model:    "gemini-2.5-pro",
+		Detail:   coreusage.Detail{InputTokens: 1000, OutputTokens: 500},
+	})
+	want := (1000*1.0 + 500*2.0) / 1_000_000
+
+	restored := NewRequestStatistics()
+	if result := restored.MergeSnapshot(source.Snapshot()); result.Added != 1 {
+		t.Fatalf("merge added %d details, want 1", result.Added)
+	}
+	snapshot := restored.Snapshot()
+	if math.Abs(snapshot.TotalCost-want) > 1e-12 || math.Abs(snapshot.CostByProvider["gemini"]-want) > 1e-12 {
+		t.Fatalf("restored cost = %v (by provider %v), want %v under gemini", snapshot.TotalCost, snapshot.CostByProvider, want)
+	}
+}
@@ -6,6 +6,7 @@ package usage
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -13,10 +14,21 @@ import (
 
 	"github.com/gin-gonic/gin"
 	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+	log "github.com/sirupsen/logrus"
 )
 
 var statisticsEnabled atomic.Bool
 
+// toolCallWarnThreshold logs a warning for requests whose tool-call count exceeds it; 0 disables.
+var toolCallWarnThreshold atomic.Int64
+
+const (
+	// DefaultBucketGranularity is the time-series bucket size used when none is configured.
+	DefaultBucketGranularity = time.Hour
+	// DefaultBucketRetention is how long time-series buckets are kept when none is configured.
+	DefaultBucketRetention = 7 * 24 * time.Hour
+)
+
 func init() {
 	statisticsEnabled.Store(true)
 	coreusage.RegisterPlugin(NewLoggerPlugin())
@@ -56,6 +68,10 @@ func SetStatisticsEnabled(enabled bool) { statisticsEnabled.Store(enabled) }
 // StatisticsEnabled reports the current recording state.
 func StatisticsEnabled() bool { return statisticsEnabled.Load() }
 
+// SetToolCallWarnThreshold sets the per-request tool-call count above which a warning is
+// logged. Values <= 0 disable the warning.
+func SetToolCallWarnThreshold(threshold int) { toolCallWarnThreshold.Store(int64(threshold)) }
+
 // RequestStatistics maintains aggregated request metrics in memory.
 type RequestStatistics struct {
 	mu sync.RWMutex
@@ -64,19 +80,40 @@ type RequestStatistics struct {
 	successCount  int64
 	failureCount  int64
 	totalTokens   int64
+	totalCost     float64
+	// totalToolCalls counts tool calls emitted by models across all requests.
+	totalToolCalls int64
 
-	apis map[string]*apiStats
+	apis           map[string]*apiStats
+	costByProvider map[string]float64
 
 	requestsByDay  map[string]int64
 	requestsByHour map[int]int64
 	tokensByDay    map[string]int64
 	tokensByHour   map[int]int64
+
+	// buckets holds the time series keyed by bucket start (unix seconds).
+	buckets     map[int64]*bucketStats
+	granularity time.Duration
+	retention   time.Duration
+	now         func() time.Time
+	// prunedBefore is the cutoff of the last bucket scan. The cutoff only advances once per
+	// bucket interval, so pruneBuckets skips the scan until it does.
+	prunedBefore int64
+}
+
+// bucketStats holds aggregated metrics for a single time-series bucket.
+type bucketStats struct {
+	Requests int64
+	Failures int64
+	Tokens   int64
 }
 
 // apiStats holds aggregated metrics for a single API key.
 type apiStats struct {
 	TotalRequests int64
 	TotalTokens   int64
+	TotalCost     float64
 	Models        map[string]*modelStats
 }
 
@@ -84,16 +121,25 @@ type apiStats struct {
 type modelStats struct {
 	TotalRequests int64
 	TotalTokens   int64
+	TotalCost     float64
 	Details       []RequestDetail
 }
 
 // RequestDetail stores the timestamp and token usage for a single request.
 type RequestDetail struct {
-	Timestamp time.Time  `json:"timestamp"`
-	Source    string     `json:"source"`
-	AuthIndex string     `json:"auth_index"`
-	Tokens    TokenStats `json:"tokens"`
-	Failed    bool       `json:"failed"`
+	Timestamp time.Time `json:"timestamp"`
+	Source    string    `json:"source"`
+	AuthIndex string    `json:"auth_index"`
+	// Provider is the upstream provider that served the request, used to rebuild
+	// cost_by_provider when a snapshot is imported.
+	Provider string     `json:"provider,omitempty"`
+	Tokens   TokenStats `json:"tokens"`
+	Cost     float64    `json:"cost,omitempty"`
+	Failed   bool       `json:"failed"`
+	// ToolCalls is the number of tool calls the model emitted in this request.
+	ToolCalls int64 `json:"tool_calls,omitempty"`
+	// Estimated marks token counts estimated locally because the upstream reported none.
+	Estimated bool `json:"estimated,omitempty"`
 }
 
 // TokenStats captures the token usage breakdown for a request.
@@ -103,6 +149,10 @@ type TokenStats struct {
 	ReasoningTokens int64 `json:"reasoning_tokens"`
 	CachedTokens    int64 `json:"cached_tokens"`
 	TotalTokens     int64 `json:"total_tokens"`
+	// CacheReadTokens is reported by Anthropic and Gemini upstreams; CacheCreationTokens
+	// only by Anthropic.
+	CacheReadTokens     int64 `json:"cache_read_tokens,omitempty"`
+	CacheCreationTokens int64 `json:"cache_creation_tokens,omitempty"`
 }
 
 // StatisticsSnapshot represents an immutable view of the aggregated metrics.
@@ -111,19 +161,37 @@ type StatisticsSnapshot struct {
 	SuccessCount  int64 `json:"success_count"`
 	FailureCount  int64 `json:"failure_count"`
 	TotalTokens   int64 `json:"total_tokens"`
+	// TotalCost is the USD cost of requests matching a configured price.
+	TotalCost float64 `json:"total_cost"`
+	// TotalToolCalls counts tool calls emitted by models across all requests.
+	TotalToolCalls int64 `json:"total_tool_calls"`
 
 	APIs map[string]APISnapshot `json:"apis"`
+	// CostByProvider breaks TotalCost down by upstream provider.
+	CostByProvider map[string]float64 `json:"cost_by_provider,omitempty"`
 
 	RequestsByDay  map[string]int64 `json:"requests_by_day"`
 	RequestsByHour map[string]int64 `json:"requests_by_hour"`
 	TokensByDay    map[string]int64 `json:"tokens_by_day"`
 	TokensByHour   map[string]int64 `json:"tokens_by_hour"`
+
+	BucketGranularity string           `json:"bucket_granularity,omitempty"`
+	Buckets           []BucketSnapshot `json:"buckets,omitempty"`
+}
+
+// BucketSnapshot summarises metrics for a single time-series bucket.
+type BucketSnapshot struct {
+	Start    time.Time `json:"start"`
+	Requests int64     `json:"requests"`
+	Failures int64     `json:"failures"`
+	Tokens   int64     `json:"tokens"`
 }
 
 // APISnapshot summarises metrics for a single API key.
 type APISnapshot struct {
 	TotalRequests int64                    `json:"total_requests"`
 	TotalTokens   int64                    `json:"total_tokens"`
+	TotalCost     float64                  `json:"total_cost"`
 	Models        map[string]ModelSnapshot `json:"models"`
 }
 
@@ -131,6 +199,7 @@ type APISnapshot struct {
 type ModelSnapshot struct {
 	TotalRequests int64           `json:"total_requests"`
 	TotalTokens   int64           `json:"total_tokens"`
+	TotalCost     float64         `json:"total_cost"`
 	Details       []RequestDetail `json:"details"`
 }
 
@@ -143,13 +212,121 @@ func GetRequestStatistics() *RequestStatistics { return defaultRequestStatistics
 func NewRequestStatistics() *RequestStatistics {
 	return &RequestStatistics{
 		apis:           make(map[string]*apiStats),
+		costByProvider: make(map[string]float64),
 		requestsByDay:  make(map[string]int64),
 		requestsByHour: make(map[int]int64),
 		tokensByDay:    make(map[string]int64),
 		tokensByHour:   make(map[int]int64),
+		buckets:        make(map[int64]*bucketStats),
+		granularity:    DefaultBucketGranularity,
+		retention:      DefaultBucketRetention,
+		now:            time.Now,
+	}
+}
+
+// SetBucketing configures the time-series bucket size and retention of the shared store.
+func SetBucketing(granularity, retention time.Duration) {
+	defaultRequestStatistics.SetBucketing(granularity, retention)
+}
+
+// ParseBucketGranularity maps a configured granularity name ("minute" or "hour") to a
+// bucket size. Unknown or empty values return DefaultBucketGranularity.
+func ParseBucketGranularity(value string) time.Duration {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "minute":
+		return time.Minute
+	case "hour":
+		return time.Hour
+	default:
+		return DefaultBucketGranularity
+	}
+}
+
+// SetBucketing configures the time-series bucket size and retention. Non-positive values
+// fall back to the defaults. Existing buckets are rebuilt from the recorded request details.
+func (s *RequestStatistics) SetBucketing(granularity, retention time.Duration) {
+	if s == nil {
+		return
+	}
+	if granularity <= 0 {
+		granularity = DefaultBucketGranularity
+	}
+	if retention <= 0 {
+		retention = DefaultBucketRetention
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.granularity == granularity && s.retention == retention {
+		return
+	}
+	rebuild := s.granularity != granularity
+	s.granularity = granularity
+	s.retention = retention
+	s.prunedBefore = 0
+	if rebuild {
+		s.buckets = make(map[int64]*bucketStats)
+		for _, stats := range s.apis {
+			if stats == nil {
+				continue
+			}
+			for _, modelStatsValue := range stats.Models {
+				if modelStatsValue == nil {
+					continue
+				}
+				for _, detail := range modelStatsValue.Details {
+					s.addToBucket(detail.Timestamp, detail.Tokens.TotalTokens, detail.Failed)
+				}
+			}
+		}
+	}
+	s.pruneBuckets()
+}
+
+// addToBucket adds a request to the bucket containing timestamp. Requests already
+// outside the retention window are ignored. Callers must hold s.mu.
+func (s *RequestStatistics) addToBucket(timestamp time.Time, tokens int64, failed bool) {
+	if timestamp.Before(s.bucketCutoff()) {
+		return
+	}
+	key := timestamp.Truncate(s.granularity).Unix()
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &bucketStats{}
+		s.buckets[key] = bucket
+	}
+	bucket.Requests++
+	if failed {
+		bucket.Failures++
+	}
+	bucket.Tokens += tokens
+}
+
+// pruneBuckets drops buckets that ended before the retention window. It scans at most once
+// per bucket interval: addToBucket never creates a bucket before the cutoff, so nothing new
+// can expire until the cutoff moves. Callers must hold s.mu.
+func (s *RequestStatistics) pruneBuckets() {
+	cutoff := s.bucketCutoff().Truncate(s.granularity).Unix()
+	if cutoff == s.prunedBefore {
+		return
+	}
+	s.prunedBefore = cutoff
+	for key := range s.buckets {
+		if key < cutoff {
+			delete(s.buckets, key)
+		}
 	}
 }
 
+func (s *RequestStatistics) bucketCutoff() time.Time {
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	return now().Add(-s.retention)
+}
+
 // Record ingests a new usage record and updates the aggregates.
 func (s *RequestStatistics) Record(ctx context.Context, record coreusage.Record) {
 	if s == nil {
@@ -177,6 +354,14 @@ func (s *RequestStatistics) Record(ctx context.Context, record coreusage.Record)
 	if modelName == "" {
 		modelName = "unknown"
 	}
+	cost := 0.0
+	if record.Source != coreusage.SourceResponseCache {
+		// Cache hits reuse a response that was already paid for.
+		cost, _ = Cost(record.Provider, modelName, detail)
+	}
+	if threshold := toolCallWarnThreshold.Load(); threshold > 0 && record.Detail.ToolCalls > threshold {
+		log.Warnf("usage: request for model %s emitted %d tool calls, above the threshold of %d", modelName, record.Detail.ToolCalls, threshold)
+	}
 	dayKey := timestamp.Format("2006-01-02")
 	hourKey := timestamp.Hour()
 
@@ -190,6 +375,9 @@ func (s *RequestStatistics) Record(ctx context.Context, record coreusage.Record)
 		s.failureCount++
 	}
 	s.totalTokens += totalTokens
+	s.totalCost += cost
+	s.totalToolCalls += record.Detail.ToolCalls
+	s.addProviderCost(record.Provider, cost)
 
 	stats, ok := s.apis[statsKey]
 	if !ok {
@@ -200,19 +388,39 @@ func (s *RequestStatistics) Record(ctx context.Context, record coreusage.Record)
 		Timestamp: timestamp,
 		Source:    record.Source,
 		AuthIndex: record.AuthIndex,
+		Provider:  record.Provider,
 		Tokens:    detail,
+		Cost:      cost,
 		Failed:    failed,
+		ToolCalls: record.Detail.ToolCalls,
+		Estimated: record.Detail.Estimated,
 	})
 
 	s.requestsByDay[dayKey]++
 	s.requestsByHour[hourKey]++
 	s.tokensByDay[dayKey] += totalTokens
 	s.tokensByHour[hourKey] += totalTokens
+
+	s.addToBucket(timestamp, totalTokens, failed)
+	s.pruneBuckets()
+}
+
+// addProviderCost adds cost to the cost_by_provider total of provider, or "unknown" when
+// the provider is not known (e.g. details imported from older snapshots).
+func (s *RequestStatistics) addProviderCost(provider string, cost float64) {
+	if cost <= 0 {
+		return
+	}
+	if provider == "" {
+		provider = "unknown"
+	}
+	s.costByProvider[provider] += cost
 }
 
 func (s *RequestStatistics) updateAPIStats(stats *apiStats, model string, detail RequestDetail) {
 	stats.TotalRequests++
 	stats.TotalTokens += detail.Tokens.TotalTokens
+	stats.TotalCost += detail.Cost
 	modelStatsValue, ok := stats.Models[model]
 	if !ok {
 		modelStatsValue = &modelStats{}
@@ -220,6 +428,7 @@ func (s *RequestStatistics) updateAPIStats(stats *apiStats, model string, detail
 	}
 	modelStatsValue.TotalRequests++
 	modelStatsValue.TotalTokens += detail.Tokens.TotalTokens
+	modelStatsValue.TotalCost += detail.Cost
 	modelStatsValue.Details = append(modelStatsValue.Details, detail)
 }
 
@@ -237,12 +446,15 @@ func (s *RequestStatistics) Snapshot() StatisticsSnapshot {
 	result.SuccessCount = s.successCount
 	result.FailureCount = s.failureCount
 	result.TotalTokens = s.totalTokens
+	result.TotalCost = s.totalCost
+	result.TotalToolCalls = s.totalToolCalls
 
 	result.APIs = make(map[string]APISnapshot, len(s.apis))
 	for apiName, stats := range s.apis {
 		apiSnapshot := APISnapshot{
 			TotalRequests: stats.TotalRequests,
 			TotalTokens:   stats.TotalTokens,
+			TotalCost:     stats.TotalCost,
 			Models:        make(map[string]ModelSnapshot, len(stats.Models)),
 		}
 		for modelName, modelStatsValue := range stats.Models {
@@ -251,12 +463,20 @@ func (s *RequestStatistics) Snapshot() StatisticsSnapshot {
 			apiSnapshot.Models[modelName] = ModelSnapshot{
 				TotalRequests: modelStatsValue.TotalRequests,
 				TotalTokens:   modelStatsValue.TotalTokens,
+				TotalCost:     modelStatsValue.TotalCost,
 				Details:       requestDetails,
 			}
 		}
 		result.APIs[apiName] = apiSnapshot
 	}
 
+	if len(s.costByProvider) > 0 {
+		result.CostByProvider = make(map[string]float64, len(s.costByProvider))
+		for provider, cost := range s.costByProvider {
+			result.CostByProvider[provider] = cost
+		}
+	}
+
 	result.RequestsByDay = make(map[string]int64, len(s.requestsByDay))
 	for k, v := range s.requestsByDay {
 		result.RequestsByDay[k] = v
@@ -279,6 +499,22 @@ func (s *RequestStatistics) Snapshot() StatisticsSnapshot {
 		result.TokensByHour[key] = v
 	}
 
+	result.BucketGranularity = s.granularity.String()
+	result.Buckets = make([]BucketSnapshot, 0, len(s.buckets))
+	cutoff := s.bucketCutoff().Truncate(s.granularity).Unix()
+	for start, bucket := range s.buckets {
+		if start < cutoff {
+			continue
+		}
+		result.Buckets = append(result.Buckets, BucketSnapshot{
+			Start:    time.Unix(start, 0).UTC(),
+			Requests: bucket.Requests,
+			Failures: bucket.Failures,
+			Tokens:   bucket.Tokens,
+		})
+	}
+	sort.Slice(result.Buckets, func(i, j int) bool { return result.Buckets[i].Start.Before(result.Buckets[j].Start) })
+
 	return result
 }
 
@@ -332,6 +568,9 @@ func (s *RequestStatistics) MergeSnapshot(snapshot StatisticsSnapshot) MergeResu
 			}
 			for _, detail := range modelSnapshot.Details {
 				detail.Tokens = normaliseTokenStats(detail.Tokens)
+				if detail.Cost < 0 {
+					detail.Cost = 0
+				}
 				if detail.Timestamp.IsZero() {
 					detail.Timestamp = time.Now()
 				}
@@ -363,6 +602,9 @@ func (s *RequestStatistics) recordImported(apiName, modelName string, stats *api
 		s.successCount++
 	}
 	s.totalTokens += totalTokens
+	s.totalCost += detail.Cost
+	s.totalToolCalls += detail.ToolCalls
+	s.addProviderCost(detail.Provider, detail.Cost)
 
 	s.updateAPIStats(stats, modelName, detail)
 
@@ -373,6 +615,9 @@ func (s *RequestStatistics) recordImported(apiName, modelName string, stats *api
 	s.requestsByHour[hourKey]++
 	s.tokensByDay[dayKey] += totalTokens
 	s.tokensByHour[hourKey] += totalTokens
+
+	s.addToBucket(detail.Timestamp, totalTokens, detail.Failed)
+	s.pruneBuckets()
 }
 
 func dedupKey(apiName, modelName string, detail RequestDetail) string {
@@ -443,6 +688,9 @@ func normaliseDetail(detail coreusage.Detail) TokenStats {
 		ReasoningTokens: detail.ReasoningTokens,
 		CachedTokens:    detail.CachedTokens,
 		TotalTokens:     detail.TotalTokens,
+
+		CacheReadTokens:     detail.CacheReadTokens,
+		CacheCreationTokens: detail.CacheCreationTokens,
 	}
 	if tokens.TotalTokens == 0 {
 		tokens.TotalTokens = detail.InputTokens + detail.OutputTokens + detail.ReasoningTokens
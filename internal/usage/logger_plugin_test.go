@@ -0,0 +1,102 @@
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+func TestRequestStatisticsBucketing(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 30, 0, 0, time.UTC)
+	stats := NewRequestStatistics()
+	stats.now = func() time.Time { return now }
+	stats.SetBucketing(time.Minute, 2*time.Hour)
+
+	record := func(at time.Time, tokens int64) {
+		stats.Record(context.Background(), coreusage.Record{
+			APIKey:      "key",
+			Model:       "model",
+			RequestedAt: at,
+			Detail:      coreusage.Detail{TotalTokens: tokens},
+		})
+	}
+	record(now.Add(-10*time.Second), 5)
+	record(now.Add(-20*time.Second), 7)
+	record(now.Add(-90*time.Second), 11)
+	record(now.Add(-3*time.Hour), 13) // outside retention
+
+	snapshot := stats.Snapshot()
+	if snapshot.BucketGranularity != time.Minute.String() {
+		t.Fatalf("granularity = %q, want %q", snapshot.BucketGranularity, time.Minute.String())
+	}
+	if len(snapshot.Buckets) != 2 {
+		t.Fatalf("buckets = %+v, want 2 minute buckets", snapshot.Buckets)
+	}
+	if got := snapshot.Buckets[0]; !got.Start.Equal(now.Add(-2*time.Minute)) || got.Requests != 1 || got.Tokens != 11 {
+		t.Fatalf("first bucket = %+v", got)
+	}
+	if got := snapshot.Buckets[1]; !got.Start.Equal(now.Add(-time.Minute)) || got.Requests != 2 || got.Tokens != 12 {
+		t.Fatalf("second bucket = %+v", got)
+	}
+
+	// Switching to hourly buckets folds the minute buckets together.
+	stats.SetBucketing(time.Hour, 2*time.Hour)
+	snapshot = stats.Snapshot()
+	if len(snapshot.Buckets) != 1 || snapshot.Buckets[0].Requests != 3 || snapshot.Buckets[0].Tokens != 23 {
+		t.Fatalf("hourly buckets = %+v", snapshot.Buckets)
+	}
+
+	// Moving the clock past the retention window prunes old buckets on the next record.
+	now = now.Add(4 * time.Hour)
+	record(now, 1)
+	snapshot = stats.Snapshot()
+	if len(snapshot.Buckets) != 1 || !snapshot.Buckets[0].Start.Equal(now.Truncate(time.Hour)) || snapshot.Buckets[0].Requests != 1 {
+		t.Fatalf("buckets after pruning = %+v", snapshot.Buckets)
+	}
+}
+
+func TestRequestStatisticsPrunesOncePerBucketInterval(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 30, 0, 0, time.UTC)
+	stats := NewRequestStatistics()
+	stats.now = func() time.Time { return now }
+	stats.SetBucketing(time.Minute, time.Hour)
+
+	record := func() {
+		stats.Record(context.Background(), coreusage.Record{APIKey: "key", Model: "model", RequestedAt: now, Detail: coreusage.Detail{TotalTokens: 1}})
+	}
+	record()
+
+	// A bucket that expired behind the current cutoff is only noticed once the cutoff
+	// moves, so records within the same interval do not rescan the series.
+	stale := now.Add(-2 * time.Hour).Unix()
+	stats.mu.Lock()
+	stats.buckets[stale] = &bucketStats{Requests: 1}
+	stats.mu.Unlock()
+	now = now.Add(10 * time.Second)
+	record()
+	if _, ok := stats.buckets[stale]; !ok {
+		t.Fatal("bucket scan ran again within the same interval")
+	}
+
+	now = now.Add(time.Minute)
+	record()
+	if _, ok := stats.buckets[stale]; ok {
+		t.Fatal("stale bucket was not pruned after the cutoff advanced")
+	}
+}
+
+func TestRequestStatisticsCountsToolCalls(t *testing.T) {
+	stats := NewRequestStatistics()
+	stats.Record(context.Background(), coreusage.Record{APIKey: "key", Model: "model", Detail: coreusage.Detail{TotalTokens: 3, ToolCalls: 2}})
+	stats.Record(context.Background(), coreusage.Record{APIKey: "key", Model: "model", Detail: coreusage.Detail{TotalTokens: 3}})
+
+	snapshot := stats.Snapshot()
+	if snapshot.TotalToolCalls != 2 {
+		t.Fatalf("total tool calls = %d, want 2", snapshot.TotalToolCalls)
+	}
+	if details := snapshot.APIs["key"].Models["model"].Details; len(details) != 2 || details[0].ToolCalls != 2 {
+		t.Fatalf("details = %+v, want the first request to carry 2 tool calls", details)
+	}
+}
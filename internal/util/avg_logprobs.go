@@ -0,0 +1,54 @@
+package util
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Locations for surfacing Gemini avgLogprobs in Claude responses.
+const (
+	// AvgLogprobsUsage writes the value to usage.avg_logprobs.
+	AvgLogprobsUsage = "usage"
+	// AvgLogprobsMetadata writes the value to metadata.avg_logprobs.
+	AvgLogprobsMetadata = "metadata"
+)
+
+var claudeAvgLogprobsLocation atomic.Value
+
+// SetClaudeAvgLogprobsLocation sets where Gemini avgLogprobs is surfaced in Claude responses.
+// Values other than "usage" or "metadata" drop the field (default).
+func SetClaudeAvgLogprobsLocation(location string) {
+	switch strings.ToLower(strings.TrimSpace(location)) {
+	case AvgLogprobsUsage:
+		claudeAvgLogprobsLocation.Store(AvgLogprobsUsage)
+	case AvgLogprobsMetadata:
+		claudeAvgLogprobsLocation.Store(AvgLogprobsMetadata)
+	default:
+		claudeAvgLogprobsLocation.Store("")
+	}
+}
+
+// ClaudeAvgLogprobsLocation returns the configured avgLogprobs location, or "" when disabled.
+func ClaudeAvgLogprobsLocation() string {
+	if location, ok := claudeAvgLogprobsLocation.Load().(string); ok {
+		return location
+	}
+	return ""
+}
+
+// SetClaudeAvgLogprobs copies avgLogprobs into a Claude response or message_delta JSON
+// at the configured location. The JSON is returned unchanged when disabled or absent.
+func SetClaudeAvgLogprobs(out string, avgLogprobs gjson.Result) string {
+	location := ClaudeAvgLogprobsLocation()
+	if location == "" || !avgLogprobs.Exists() || avgLogprobs.Type != gjson.Number {
+		return out
+	}
+	updated, err := sjson.Set(out, location+".avg_logprobs", avgLogprobs.Float())
+	if err != nil {
+		return out
+	}
+	return updated
+}
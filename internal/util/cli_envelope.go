@@ -0,0 +1,57 @@
+package util
+
+import (
+	"bytes"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// CLIResponseChunks normalizes a Gemini CLI or Antigravity response payload into
+// wrapped {"response":{...}} chunks. It accepts an SSE "data:" line, a wrapped object,
+// a bare Gemini response (wrapped on the fly) and a JSON array of either, which is what
+// the upstream returns for alt=json streams. Payloads that are not JSON (e.g. "[DONE]")
+// are returned unchanged as the only chunk.
+func CLIResponseChunks(rawJSON []byte) [][]byte {
+	trimmed := bytes.TrimSpace(rawJSON)
+	if bytes.HasPrefix(trimmed, []byte("data:")) {
+		trimmed = bytes.TrimSpace(trimmed[5:])
+	}
+	if len(trimmed) == 0 || !gjson.ValidBytes(trimmed) {
+		return [][]byte{rawJSON}
+	}
+
+	root := gjson.ParseBytes(trimmed)
+	switch {
+	case root.IsArray():
+		items := root.Array()
+		chunks := make([][]byte, 0, len(items))
+		for _, item := range items {
+			if item.IsObject() {
+				chunks = append(chunks, WrapCLIResponse([]byte(item.Raw)))
+			}
+		}
+		return chunks
+	case root.IsObject():
+		return [][]byte{WrapCLIResponse(trimmed)}
+	default:
+		return [][]byte{rawJSON}
+	}
+}
+
+// WrapCLIResponse wraps a bare Gemini response (top-level candidates or usageMetadata)
+// as {"response":...}. Already wrapped and unrelated payloads are returned as is.
+func WrapCLIResponse(rawJSON []byte) []byte {
+	root := gjson.ParseBytes(rawJSON)
+	if root.Get("response").Exists() {
+		return rawJSON
+	}
+	if !root.Get("candidates").Exists() && !root.Get("usageMetadata").Exists() {
+		return rawJSON
+	}
+	wrapped, err := sjson.SetRawBytes([]byte(`{}`), "response", rawJSON)
+	if err != nil {
+		return rawJSON
+	}
+	return wrapped
+}
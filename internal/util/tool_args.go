@@ -0,0 +1,152 @@
+package util
+
+import (
+	"bytes"
+	"strings"
+	"sync/atomic"
+
+	"github.com/tidwall/gjson"
+)
+
+// Tool argument truncation actions for streaming translators.
+const (
+	// ToolArgsTruncationError replaces truncated tool arguments with an error event (default).
+	ToolArgsTruncationError = "error"
+	// ToolArgsTruncationClose closes open strings, arrays and objects to produce valid JSON.
+	ToolArgsTruncationClose = "close"
+)
+
+var toolArgsOnTruncation atomic.Value
+
+// SetToolArgsOnTruncation sets how the Claude <-> OpenAI chat-completions streaming
+// translators handle tool-call arguments that end mid-JSON. Unknown or empty values fall
+// back to ToolArgsTruncationError.
+func SetToolArgsOnTruncation(action string) {
+	if strings.EqualFold(strings.TrimSpace(action), ToolArgsTruncationClose) {
+		toolArgsOnTruncation.Store(ToolArgsTruncationClose)
+		return
+	}
+	toolArgsOnTruncation.Store(ToolArgsTruncationError)
+}
+
+// ToolArgsOnTruncation returns the configured tool argument truncation action.
+func ToolArgsOnTruncation() string {
+	if action, ok := toolArgsOnTruncation.Load().(string); ok {
+		return action
+	}
+	return ToolArgsTruncationError
+}
+
+// StreamInterruptedEvent is fed to streaming response translators after the upstream
+// stream failed. Translators finish tool calls cut off mid-arguments per
+// ToolArgsOnTruncation but emit no terminal event; the executor returns the stream error.
+//
+// Only the Claude and OpenAI-compatible executors emit it, and only the Claude <-> OpenAI
+// chat-completions translators act on it, because they are the ones that buffer streamed
+// tool-call arguments. Gemini, Gemini CLI, Vertex and Antigravity stream every functionCall
+// whole, and the Codex translators forward argument deltas unbuffered, so those streams
+// have no pending call to finish and end with the stream error alone.
+var StreamInterruptedEvent = []byte(`data: {"type":"stream_interrupted"}`)
+
+// IsStreamInterrupted reports whether rawJSON is StreamInterruptedEvent, with or without
+// its "data:" prefix.
+func IsStreamInterrupted(rawJSON []byte) bool {
+	rawJSON = bytes.TrimSpace(rawJSON)
+	if bytes.HasPrefix(rawJSON, []byte("data:")) {
+		rawJSON = bytes.TrimSpace(rawJSON[5:])
+	}
+	return bytes.Equal(rawJSON, StreamInterruptedEvent[6:])
+}
+
+// FinalizeToolArguments returns accumulated streamed tool-call arguments as valid JSON.
+// Truncated arguments are closed when the configured action is ToolArgsTruncationClose;
+// otherwise it reports false so callers can surface an error instead of a broken call.
+func FinalizeToolArguments(raw string) (string, bool) {
+	fixed := FixJSON(raw)
+	if gjson.Valid(fixed) {
+		return fixed, true
+	}
+	if ToolArgsOnTruncation() == ToolArgsTruncationClose {
+		if closed, ok := CloseTruncatedJSON(fixed); ok {
+			return closed, true
+		}
+	}
+	return "", false
+}
+
+// CloseTruncatedJSON attempts to turn a JSON document cut off mid-stream into valid JSON
+// by closing an open string and any open arrays or objects. A dangling comma is dropped
+// and a dangling key separator gets a null value. It returns false when the input cannot
+// be repaired this way.
+func CloseTruncatedJSON(input string) (string, bool) {
+	trimmed := strings.TrimSpace(input)
+	if trimmed == "" {
+		return "", false
+	}
+	if gjson.Valid(trimmed) {
+		return trimmed, true
+	}
+
+	var stack []byte
+	inString := false
+	escaped := false
+	for i := 0; i < len(trimmed); i++ {
+		ch := trimmed[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+		switch ch {
+		case '"':
+			inString = true
+		case '{', '[':
+			stack = append(stack, ch)
+		case '}', ']':
+			if len(stack) == 0 {
+				return "", false
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	var out strings.Builder
+	out.WriteString(trimmed)
+	if inString {
+		if escaped {
+			// Drop a dangling backslash so the closing quote is not escaped.
+			repaired := strings.TrimSuffix(out.String(), "\\")
+			out.Reset()
+			out.WriteString(repaired)
+		}
+		out.WriteByte('"')
+	}
+
+	result := strings.TrimRight(out.String(), " \t\r\n")
+	switch {
+	case strings.HasSuffix(result, ","):
+		result = strings.TrimRight(strings.TrimSuffix(result, ","), " \t\r\n")
+	case strings.HasSuffix(result, ":"):
+		result += "null"
+	}
+
+	var closers strings.Builder
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i] == '{' {
+			closers.WriteByte('}')
+		} else {
+			closers.WriteByte(']')
+		}
+	}
+	result += closers.String()
+	if !gjson.Valid(result) {
+		return "", false
+	}
+	return result, true
+}
@@ -63,6 +63,9 @@ func (s *ConfigSynthesizer) synthesizeGeminiKeys(ctx *SynthesisContext) []*corea
 		if entry.Priority != 0 {
 			attrs["priority"] = strconv.Itoa(entry.Priority)
 		}
+		if group := strings.TrimSpace(entry.Group); group != "" {
+			attrs["group"] = group
+		}
 		if base != "" {
 			attrs["base_url"] = base
 		}
@@ -110,6 +113,9 @@ func (s *ConfigSynthesizer) synthesizeClaudeKeys(ctx *SynthesisContext) []*corea
 		if ck.Priority != 0 {
 			attrs["priority"] = strconv.Itoa(ck.Priority)
 		}
+		if group := strings.TrimSpace(ck.Group); group != "" {
+			attrs["group"] = group
+		}
 		if base != "" {
 			attrs["base_url"] = base
 		}
@@ -157,6 +163,9 @@ func (s *ConfigSynthesizer) synthesizeCodexKeys(ctx *SynthesisContext) []*coreau
 		if ck.Priority != 0 {
 			attrs["priority"] = strconv.Itoa(ck.Priority)
 		}
+		if group := strings.TrimSpace(ck.Group); group != "" {
+			attrs["group"] = group
+		}
 		if ck.BaseURL != "" {
 			attrs["base_url"] = ck.BaseURL
 		}
@@ -218,6 +227,9 @@ func (s *ConfigSynthesizer) synthesizeOpenAICompat(ctx *SynthesisContext) []*cor
 			if compat.Priority != 0 {
 				attrs["priority"] = strconv.Itoa(compat.Priority)
 			}
+			if group := strings.TrimSpace(compat.Group); group != "" {
+				attrs["group"] = group
+			}
 			if key != "" {
 				attrs["api_key"] = key
 			}
@@ -252,6 +264,9 @@ func (s *ConfigSynthesizer) synthesizeOpenAICompat(ctx *SynthesisContext) []*cor
 			if compat.Priority != 0 {
 				attrs["priority"] = strconv.Itoa(compat.Priority)
 			}
+			if group := strings.TrimSpace(compat.Group); group != "" {
+				attrs["group"] = group
+			}
 			if hash := diff.ComputeOpenAICompatModelsHash(compat.Models); hash != "" {
 				attrs["models_hash"] = hash
 			}
@@ -297,6 +312,9 @@ func (s *ConfigSynthesizer) synthesizeVertexCompat(ctx *SynthesisContext) []*cor
 		if compat.Priority != 0 {
 			attrs["priority"] = strconv.Itoa(compat.Priority)
 		}
+		if group := strings.TrimSpace(compat.Group); group != "" {
+			attrs["group"] = group
+		}
 		if key != "" {
 			attrs["api_key"] = key
 		}
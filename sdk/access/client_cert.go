@@ -0,0 +1,30 @@
+package access
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ClientCertIdentity returns the caller identity carried by a verified TLS client
+// certificate: the subject common name, falling back to the first DNS, email, or URI
+// SAN. It returns an empty string for plain HTTP requests or unverified certificates,
+// so providers can use it to map mTLS callers to principals.
+func ClientCertIdentity(r *http.Request) string {
+	if r == nil || r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	leaf := r.TLS.VerifiedChains[0][0]
+	if cn := strings.TrimSpace(leaf.Subject.CommonName); cn != "" {
+		return cn
+	}
+	if len(leaf.DNSNames) > 0 {
+		return leaf.DNSNames[0]
+	}
+	if len(leaf.EmailAddresses) > 0 {
+		return leaf.EmailAddresses[0]
+	}
+	if len(leaf.URIs) > 0 {
+		return leaf.URIs[0].String()
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import "strings"
+
+const (
+	// defaultMaxChoiceFanOut bounds n for fanned-out requests when choice-fan-out.max-choices is unset.
+	defaultMaxChoiceFanOut = 8
+)
+
+// defaultSingleCandidateProviders lists providers whose upstream APIs return one completion
+// per request and ignore OpenAI's n parameter. choice-fan-out.providers overrides it.
+var defaultSingleCandidateProviders = []string{"claude", "codex"}
+
+// SupportsMultipleCandidates reports whether every provider serving modelName can return
+// several completions from one upstream call (e.g. Gemini candidateCount). Callers fan
+// n>1 requests out into parallel single-choice calls when it returns false.
+func (h *BaseAPIHandler) SupportsMultipleCandidates(modelName string) bool {
+	providers, _, errMsg := h.getRequestDetails(modelName)
+	if errMsg != nil {
+		// Let the regular execution path report the lookup error.
+		return true
+	}
+	single := defaultSingleCandidateProviders
+	if h.Cfg != nil && len(h.Cfg.ChoiceFanOut.Providers) > 0 {
+		single = h.Cfg.ChoiceFanOut.Providers
+	}
+	for _, provider := range providers {
+		for _, name := range single {
+			if strings.EqualFold(strings.TrimSpace(name), provider) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ChoiceFanOutLimits returns the largest n accepted for a fanned-out request and how many
+// of its upstream calls may run at once. Concurrency never exceeds the choice limit.
+func (h *BaseAPIHandler) ChoiceFanOutLimits() (maxChoices, concurrency int) {
+	maxChoices = defaultMaxChoiceFanOut
+	if h.Cfg != nil && h.Cfg.ChoiceFanOut.MaxChoices > 0 {
+		maxChoices = h.Cfg.ChoiceFanOut.MaxChoices
+	}
+	concurrency = maxChoices
+	if h.Cfg != nil && h.Cfg.ChoiceFanOut.Concurrency > 0 {
+		concurrency = min(h.Cfg.ChoiceFanOut.Concurrency, maxChoices)
+	}
+	return maxChoices, concurrency
+}
@@ -0,0 +1,59 @@
+package claude
+
+import (
+	"bytes"
+
+	"github.com/tidwall/gjson"
+)
+
+// eventFramer normalizes the SSE framing of a Claude-format stream. Chunks may carry whole
+// events (translated streams) or single lines (Claude passthrough), so the framer tracks
+// whether the event in progress already has an "event:" line.
+//
+// In the default mode, a "data:" line without a preceding "event:" line gets one named after
+// its JSON "type". In bare mode, "event:" lines are dropped so OpenAI-style clients only see
+// "data:" lines.
+type eventFramer struct {
+	bare     bool
+	hasEvent bool
+}
+
+func newEventFramer(bare bool) *eventFramer {
+	return &eventFramer{bare: bare}
+}
+
+// Frame returns chunk with its event lines added or removed. It may return an empty slice
+// when a chunk held only event lines in bare mode.
+func (f *eventFramer) Frame(chunk []byte) []byte {
+	if len(chunk) == 0 {
+		return chunk
+	}
+	out := make([]byte, 0, len(chunk)+32)
+	for len(chunk) > 0 {
+		line := chunk
+		if idx := bytes.IndexByte(chunk, '\n'); idx >= 0 {
+			line, chunk = chunk[:idx+1], chunk[idx+1:]
+		} else {
+			chunk = nil
+		}
+		content := bytes.TrimRight(line, "\r\n")
+		switch {
+		case len(content) == 0:
+			f.hasEvent = false
+		case bytes.HasPrefix(content, []byte("event:")):
+			f.hasEvent = true
+			if f.bare {
+				continue
+			}
+		case bytes.HasPrefix(content, []byte("data:")):
+			if !f.bare && !f.hasEvent {
+				if eventType := gjson.GetBytes(bytes.TrimSpace(content[len("data:"):]), "type").String(); eventType != "" {
+					out = append(out, "event: "+eventType+"\n"...)
+				}
+			}
+			f.hasEvent = true
+		}
+		out = append(out, line...)
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+package claude
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// claudeStreamExecutor streams a Claude-format response mixing passthrough lines with a
+// translated event that lacks its "event:" line.
+type claudeStreamExecutor struct{}
+
+func (e *claudeStreamExecutor) Identifier() string { return "claude" }
+
+func (e *claudeStreamExecutor) Execute(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, errors.New("not implemented")
+}
+
+func (e *claudeStreamExecutor) ExecuteStream(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (*coreexecutor.StreamResult, error) {
+	chunks := []string{
+		"event: message_start\n",
+		`data: {"type":"message_start","message":{"id":"msg_1"}}` + "\n",
+		"\n",
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"hi"}}` + "\n\n",
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n",
+	}
+	ch := make(chan coreexecutor.StreamChunk, len(chunks))
+	for _, chunk := range chunks {
+		ch <- coreexecutor.StreamChunk{Payload: []byte(chunk)}
+	}
+	close(ch)
+	return &coreexecutor.StreamResult{Chunks: ch}, nil
+}
+
+func (e *claudeStreamExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *claudeStreamExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, errors.New("not implemented")
+}
+
+func (e *claudeStreamExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+func streamClaudeMessages(t *testing.T, cfg *sdkconfig.SDKConfig) string {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(&claudeStreamExecutor{})
+	auth := &coreauth.Auth{ID: "claude-framing-auth", Provider: "claude", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("Register auth: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "framing-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	h := NewClaudeCodeAPIHandler(handlers.NewBaseAPIHandlers(cfg, manager))
+	router := gin.New()
+	router.POST("/v1/messages", h.ClaudeMessages)
+
+	body := `{"model":"framing-model","stream":true,"max_tokens":16,"messages":[{"role":"user","content":"hi"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	return rec.Body.String()
+}
+
+func TestClaudeMessagesStream_NamesEveryEvent(t *testing.T) {
+	out := streamClaudeMessages(t, &sdkconfig.SDKConfig{})
+
+	for _, name := range []string{"message_start", "content_block_delta", "message_stop"} {
+		if got := strings.Count(out, "event: "+name+"\n"); got != 1 {
+			t.Fatalf("event %s named %d times, want 1:\n%s", name, got, out)
+		}
+	}
+	if !strings.Contains(out, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\"") {
+		t.Fatalf("content_block_delta event name does not precede its data line:\n%s", out)
+	}
+}
+
+func TestClaudeMessagesStream_OpenAIFramingDropsEventNames(t *testing.T) {
+	out := streamClaudeMessages(t, &sdkconfig.SDKConfig{Streaming: sdkconfig.StreamingConfig{ClaudeEventFraming: "openai"}})
+
+	if strings.Contains(out, "event:") {
+		t.Fatalf("bare framing kept event lines:\n%s", out)
+	}
+	if got := strings.Count(out, "data: "); got != 3 {
+		t.Fatalf("data lines = %d, want 3:\n%s", got, out)
+	}
+}
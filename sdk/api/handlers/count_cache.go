@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+const defaultCountTokensCacheTTL = 300 * time.Second
+
+// CountTokensCacheSettings returns the configured count cache size and TTL. A size of 0
+// disables the cache.
+func CountTokensCacheSettings(cfg *config.SDKConfig) (int, time.Duration) {
+	if cfg == nil || cfg.CountTokensCache.Size <= 0 {
+		return 0, 0
+	}
+	ttl := defaultCountTokensCacheTTL
+	if cfg.CountTokensCache.TTLSeconds > 0 {
+		ttl = time.Duration(cfg.CountTokensCache.TTLSeconds) * time.Second
+	}
+	return cfg.CountTokensCache.Size, ttl
+}
+
+// countTokensCacheKey identifies a count request by client format, model and body hash, so
+// a change of model or content never hits an entry recorded for another.
+func countTokensCacheKey(handlerType, model string, payload []byte) string {
+	sum := sha256.New()
+	sum.Write([]byte(handlerType))
+	sum.Write([]byte{0})
+	sum.Write([]byte(model))
+	sum.Write([]byte{0})
+	sum.Write(payload)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// countTokensCache is an LRU cache of token count responses with per-entry expiry.
+// The zero value is ready to use; size and TTL are passed on each call so configuration
+// reloads take effect without rebuilding the cache.
+type countTokensCache struct {
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+	now     func() time.Time
+}
+
+type countTokensCacheEntry struct {
+	key     string
+	payload []byte
+	meta    any
+	expires time.Time
+}
+
+func (c *countTokensCache) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// get returns a copy of the cached payload for key, dropping it when expired.
+func (c *countTokensCache) get(key string) ([]byte, bool) {
+	payload, _, ok := c.getWithMeta(key)
+	return payload, ok
+}
+
+// getWithMeta is get that also returns the value stored with putWithMeta.
+func (c *countTokensCache) getWithMeta(key string) ([]byte, any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, nil, false
+	}
+	entry := elem.Value.(*countTokensCacheEntry)
+	if !c.clock().Before(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, nil, false
+	}
+	c.order.MoveToFront(elem)
+	return cloneBytes(entry.payload), entry.meta, true
+}
+
+// put stores payload for key, evicting least recently used entries beyond size.
+func (c *countTokensCache) put(key string, payload []byte, size int, ttl time.Duration) {
+	c.putWithMeta(key, payload, nil, size, ttl)
+}
+
+// putWithMeta is put that also stores meta, an immutable value returned with the payload.
+func (c *countTokensCache) putWithMeta(key string, payload []byte, meta any, size int, ttl time.Duration) {
+	if size <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]*list.Element)
+		c.order = list.New()
+	}
+	entry := &countTokensCacheEntry{key: key, payload: cloneBytes(payload), meta: meta, expires: c.clock().Add(ttl)}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+	} else {
+		c.entries[key] = c.order.PushFront(entry)
+	}
+	for c.order.Len() > size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*countTokensCacheEntry).key)
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// countingExecutor counts upstream CountTokens calls.
+type countingExecutor struct {
+	calls atomic.Int32
+}
+
+func (e *countingExecutor) Identifier() string { return "codex" }
+
+func (e *countingExecutor) Execute(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, &coreauth.Error{Code: "not_implemented", Message: "Execute not implemented"}
+}
+
+func (e *countingExecutor) ExecuteStream(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (*coreexecutor.StreamResult, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "ExecuteStream not implemented"}
+}
+
+func (e *countingExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *countingExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	e.calls.Add(1)
+	return coreexecutor.Response{Payload: []byte(`{"input_tokens":7}`)}, nil
+}
+
+func (e *countingExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "HttpRequest not implemented", HTTPStatus: http.StatusNotImplemented}
+}
+
+func TestExecuteCountWithAuthManager_CacheHitSkipsUpstream(t *testing.T) {
+	executor := &countingExecutor{}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	auth := &coreauth.Auth{ID: "count-cache-auth", Provider: "codex", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("manager.Register: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "count-model-a"}, {ID: "count-model-b"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	h := NewBaseAPIHandlers(&sdkconfig.SDKConfig{CountTokensCache: sdkconfig.CountTokensCacheConfig{Size: 8}}, manager)
+	count := func(model, body string) {
+		t.Helper()
+		resp, _, errMsg := h.ExecuteCountWithAuthManager(context.Background(), "claude", model, []byte(body), "")
+		if errMsg != nil {
+			t.Fatalf("ExecuteCountWithAuthManager: %v", errMsg.Error)
+		}
+		if string(resp) != `{"input_tokens":7}` {
+			t.Fatalf("response = %s", resp)
+		}
+	}
+
+	body := `{"messages":[{"role":"user","content":"unchanged file context"}]}`
+	count("count-model-a", body)
+	count("count-model-a", body)
+	if got := executor.calls.Load(); got != 1 {
+		t.Fatalf("upstream calls = %d, want 1 after a cache hit", got)
+	}
+
+	count("count-model-b", body)
+	count("count-model-a", `{"messages":[{"role":"user","content":"edited file context"}]}`)
+	if got := executor.calls.Load(); got != 3 {
+		t.Fatalf("upstream calls = %d, want 3 after a model and a content change", got)
+	}
+}
+
+func TestCountTokensCache_EvictsAndExpires(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	cache := &countTokensCache{now: func() time.Time { return now }}
+
+	cache.put("a", []byte("1"), 2, time.Minute)
+	cache.put("b", []byte("2"), 2, time.Minute)
+	if _, ok := cache.get("a"); !ok {
+		t.Fatalf("expected a hit for a")
+	}
+	cache.put("c", []byte("3"), 2, time.Minute)
+	if _, ok := cache.get("b"); ok {
+		t.Fatalf("least recently used entry b was not evicted")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.get("a"); ok {
+		t.Fatalf("expired entry a was returned")
+	}
+}
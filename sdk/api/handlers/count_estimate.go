@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tokenize"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// EstimatedTokenCountHeader is set on count responses computed locally because the
+// provider has no native token counting.
+const EstimatedTokenCountHeader = "X-Token-Count-Estimated"
+
+// estimateTokenCount builds a locally estimated count response when err reports that the
+// provider does not support token counting. It returns false for any other failure and
+// for handler formats without a count endpoint.
+func estimateTokenCount(handlerType string, err error, rawJSON []byte) ([]byte, bool) {
+	if !countTokensUnsupported(err) {
+		return nil, false
+	}
+	count := tokenize.EstimateJSON(rawJSON)
+	switch strings.ToLower(strings.TrimSpace(handlerType)) {
+	case "claude":
+		return []byte(fmt.Sprintf(`{"input_tokens":%d,"estimated":true}`, count)), true
+	case "gemini":
+		return []byte(fmt.Sprintf(`{"totalTokens":%d,"promptTokensDetails":[{"modality":"TEXT","tokenCount":%d}],"estimated":true}`, count, count)), true
+	default:
+		return nil, false
+	}
+}
+
+// countTokensUnsupported reports whether a count failure means the provider lacks support
+// (coreexecutor.ErrCountTokensUnsupported) rather than an upstream or request error, which
+// must reach the client unchanged.
+func countTokensUnsupported(err error) bool {
+	return errors.Is(err, coreexecutor.ErrCountTokensUnsupported)
+}
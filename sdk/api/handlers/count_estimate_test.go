@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/tidwall/gjson"
+)
+
+func TestEstimateTokenCount(t *testing.T) {
+	body := []byte(`{"messages":[{"role":"user","content":"hello world"}]}`)
+	unsupported := fmt.Errorf("my executor: %w", coreexecutor.ErrCountTokensUnsupported)
+
+	out, ok := estimateTokenCount("claude", unsupported, body)
+	if !ok {
+		t.Fatal("expected estimate for unsupported count")
+	}
+	if !gjson.GetBytes(out, "estimated").Bool() || gjson.GetBytes(out, "input_tokens").Int() <= 0 {
+		t.Fatalf("unexpected claude estimate: %s", out)
+	}
+
+	out, ok = estimateTokenCount("gemini", coreexecutor.ErrCountTokensUnsupported, body)
+	if !ok || gjson.GetBytes(out, "totalTokens").Int() <= 0 {
+		t.Fatalf("unexpected gemini estimate: ok=%v out=%s", ok, out)
+	}
+
+	// Real upstream failures must reach the client, even when they look similar.
+	for _, err := range []error{
+		errors.New("rate limited"),
+		&coreauth.Error{Code: "upstream_error", Message: "method not implemented for this region", HTTPStatus: 501},
+		errors.New("no stream translator registered; not implemented"),
+	} {
+		if _, ok = estimateTokenCount("claude", err, body); ok {
+			t.Fatalf("expected no estimate for upstream failure %q", err)
+		}
+	}
+}
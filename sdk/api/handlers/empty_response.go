@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/common"
+	"github.com/tidwall/gjson"
+)
+
+// errEmptyResponse is returned to clients when empty-response is "error" and the upstream
+// produced no assistant content.
+var errEmptyResponse = errors.New("upstream returned an empty response")
+
+// emptyResponsePolicy returns the configured empty-response handling: "pass" (default),
+// "retry" or "error".
+func (h *BaseAPIHandler) emptyResponsePolicy() string {
+	if h == nil || h.Cfg == nil {
+		return "pass"
+	}
+	switch policy := strings.ToLower(strings.TrimSpace(h.Cfg.EmptyResponse)); policy {
+	case "retry", "error":
+		return policy
+	default:
+		return "pass"
+	}
+}
+
+// isEmptyAssistantResponse reports whether a non-streaming response in the client's wire
+// format carries neither non-whitespace text nor tool calls. Reasoning alone does not count
+// as content. Safety and content-filter blocks are deliberate answers, not empty ones, so a
+// retry would only resend the blocked prompt. Unknown formats and unparsable bodies are
+// never treated as empty.
+func isEmptyAssistantResponse(handlerType string, body []byte) bool {
+	if len(body) == 0 {
+		return true
+	}
+	if !gjson.ValidBytes(body) {
+		return false
+	}
+	root := gjson.ParseBytes(body)
+	switch handlerType {
+	case "openai":
+		empty := true
+		root.Get("choices").ForEach(func(_, choice gjson.Result) bool {
+			message := choice.Get("message")
+			if choice.Get("finish_reason").String() == "content_filter" || hasText(message.Get("content")) || message.Get("tool_calls.#").Int() > 0 || message.Get("function_call").Exists() {
+				empty = false
+			}
+			return empty
+		})
+		return empty
+	case "openai-response":
+		if root.Get("incomplete_details.reason").String() == "content_filter" {
+			return false
+		}
+		empty := true
+		root.Get("output").ForEach(func(_, item gjson.Result) bool {
+			switch item.Get("type").String() {
+			case "message":
+				item.Get("content").ForEach(func(_, part gjson.Result) bool {
+					if hasText(part.Get("text")) {
+						empty = false
+					}
+					return empty
+				})
+			case "reasoning":
+			default:
+				// function_call and other tool invocations are content.
+				empty = false
+			}
+			return empty
+		})
+		return empty
+	case "claude":
+		if root.Get("stop_reason").String() == "refusal" {
+			return false
+		}
+		empty := true
+		root.Get("content").ForEach(func(_, block gjson.Result) bool {
+			switch block.Get("type").String() {
+			case "text":
+				if hasText(block.Get("text")) {
+					empty = false
+				}
+			case "thinking", "redacted_thinking":
+			default:
+				empty = false
+			}
+			return empty
+		})
+		return empty
+	case "gemini", "gemini-cli":
+		candidates := root.Get("candidates")
+		promptFeedback := root.Get("promptFeedback")
+		if handlerType == "gemini-cli" && !candidates.Exists() {
+			candidates = root.Get("response.candidates")
+			promptFeedback = root.Get("response.promptFeedback")
+		}
+		if promptFeedback.Get("blockReason").Exists() {
+			return false
+		}
+		empty := true
+		candidates.ForEach(func(_, candidate gjson.Result) bool {
+			if common.OpenAIFinishReason(candidate.Get("finishReason").String()) == "content_filter" {
+				empty = false
+				return false
+			}
+			candidate.Get("content.parts").ForEach(func(_, part gjson.Result) bool {
+				if part.Get("functionCall").Exists() || (!part.Get("thought").Bool() && hasText(part.Get("text"))) {
+					empty = false
+				}
+				return empty
+			})
+			return empty
+		})
+		return empty
+	default:
+		return false
+	}
+}
+
+// hasText reports whether value is a string with non-whitespace content.
+func hasText(value gjson.Result) bool {
+	return value.Type == gjson.String && strings.TrimSpace(value.String()) != ""
+}
+
+// emptyResponseStatus is the HTTP status returned for an empty upstream response.
+const emptyResponseStatus = http.StatusBadGateway
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// scriptedResponseExecutor returns its responses in order, repeating the last one.
+type scriptedResponseExecutor struct {
+	echoExecutor
+	responses []string
+	calls     int32
+}
+
+func (e *scriptedResponseExecutor) Execute(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	n := int(atomic.AddInt32(&e.calls, 1))
+	if n > len(e.responses) {
+		n = len(e.responses)
+	}
+	return coreexecutor.Response{Payload: []byte(e.responses[n-1])}, nil
+}
+
+func TestExecuteWithAuthManager_EmptyResponsePolicy(t *testing.T) {
+	const (
+		emptyResponse = `{"choices":[{"index":0,"message":{"role":"assistant","content":"  \n"},"finish_reason":"stop"}]}`
+		fullResponse  = `{"choices":[{"index":0,"message":{"role":"assistant","content":"hello"},"finish_reason":"stop"}]}`
+	)
+	body := []byte(`{"model":"empty-response-model","messages":[{"role":"user","content":"hi"}]}`)
+
+	cases := []struct {
+		policy     string
+		wantBody   string
+		wantStatus int
+		wantCalls  int32
+	}{
+		{policy: "", wantBody: emptyResponse, wantCalls: 1},
+		{policy: "retry", wantBody: fullResponse, wantCalls: 2},
+		{policy: "error", wantStatus: http.StatusBadGateway, wantCalls: 1},
+	}
+	for _, tc := range cases {
+		t.Run("policy_"+tc.policy, func(t *testing.T) {
+			executor := &scriptedResponseExecutor{responses: []string{emptyResponse, fullResponse}}
+			manager := coreauth.NewManager(nil, nil, nil)
+			manager.RegisterExecutor(executor)
+			auth := &coreauth.Auth{ID: "empty-response-auth-" + tc.policy, Provider: "codex", Status: coreauth.StatusActive}
+			if _, err := manager.Register(context.Background(), auth); err != nil {
+				t.Fatalf("manager.Register: %v", err)
+			}
+			registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "empty-response-model"}})
+			t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+			handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{EmptyResponse: tc.policy}, manager)
+			payload, _, errMsg := handler.ExecuteWithAuthManager(context.Background(), "openai", "empty-response-model", body, "")
+			if tc.wantStatus != 0 {
+				if errMsg == nil || errMsg.StatusCode != tc.wantStatus {
+					t.Fatalf("error = %+v, want status %d", errMsg, tc.wantStatus)
+				}
+			} else {
+				if errMsg != nil {
+					t.Fatalf("unexpected error: %+v", errMsg)
+				}
+				if string(payload) != tc.wantBody {
+					t.Fatalf("payload = %s, want %s", payload, tc.wantBody)
+				}
+			}
+			if calls := atomic.LoadInt32(&executor.calls); calls != tc.wantCalls {
+				t.Fatalf("upstream calls = %d, want %d", calls, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestIsEmptyAssistantResponse(t *testing.T) {
+	cases := []struct {
+		format string
+		body   string
+		want   bool
+	}{
+		{"openai", `{"choices":[{"message":{"content":"","reasoning_content":"thinking"}}]}`, true},
+		{"openai", `{"choices":[{"message":{"content":null,"tool_calls":[{"id":"call_1"}]}}]}`, false},
+		{"openai-response", `{"output":[{"type":"reasoning"},{"type":"message","content":[{"type":"output_text","text":" "}]}]}`, true},
+		{"openai-response", `{"output":[{"type":"function_call","name":"f"}]}`, false},
+		{"claude", `{"content":[{"type":"thinking","thinking":"x"},{"type":"text","text":"\n"}]}`, true},
+		{"claude", `{"content":[{"type":"tool_use","name":"f"}]}`, false},
+		{"gemini", `{"candidates":[{"content":{"parts":[{"text":"x","thought":true}]}}]}`, true},
+		{"gemini", `{"candidates":[{"content":{"parts":[{"text":"answer"}]}}]}`, false},
+		{"gemini-cli", `{"response":{"candidates":[{"content":{"parts":[]}}]}}`, true},
+		{"openai", `{"choices":[{"message":{"content":""},"finish_reason":"content_filter"}]}`, false},
+		{"openai-response", `{"status":"incomplete","incomplete_details":{"reason":"content_filter"},"output":[]}`, false},
+		{"claude", `{"content":[],"stop_reason":"refusal"}`, false},
+		{"gemini", `{"candidates":[{"content":{"parts":[]},"finishReason":"SAFETY"}]}`, false},
+		{"gemini", `{"promptFeedback":{"blockReason":"SAFETY"}}`, false},
+		{"gemini-cli", `{"response":{"promptFeedback":{"blockReason":"PROHIBITED_CONTENT"}}}`, false},
+		{"unknown", `{}`, false},
+	}
+	for _, tc := range cases {
+		if got := isEmptyAssistantResponse(tc.format, []byte(tc.body)); got != tc.want {
+			t.Errorf("isEmptyAssistantResponse(%s, %s) = %v, want %v", tc.format, tc.body, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+const (
+	// ForceProviderHeader restricts an admin request to one provider key.
+	ForceProviderHeader = "X-Force-Provider"
+	// ForceAuthHeader pins an admin request to one auth ID.
+	ForceAuthHeader = "X-Force-Auth"
+)
+
+// IsAdminAPIKey reports whether apiKey is listed in cfg.AdminAPIKeys.
+func IsAdminAPIKey(cfg *config.SDKConfig, apiKey string) bool {
+	if cfg == nil || apiKey == "" {
+		return false
+	}
+	for _, key := range cfg.AdminAPIKeys {
+		if strings.TrimSpace(key) == apiKey {
+			return true
+		}
+	}
+	return false
+}
+
+// applyForcedTarget honors X-Force-Provider and X-Force-Auth for admin callers. It returns the
+// providers to dispatch to, a context pinned to the forced auth, and whether a target was
+// forced. Force headers from other callers are ignored. A forced target that cannot serve
+// model yields a 400.
+func (h *BaseAPIHandler) applyForcedTarget(ctx context.Context, providers []string, model string, lookupErr *interfaces.ErrorMessage) (context.Context, []string, bool, *interfaces.ErrorMessage) {
+	if ctx == nil {
+		return ctx, providers, false, nil
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil || ginCtx.Request == nil {
+		return ctx, providers, false, nil
+	}
+	forceProvider := strings.TrimSpace(ginCtx.GetHeader(ForceProviderHeader))
+	forceAuth := strings.TrimSpace(ginCtx.GetHeader(ForceAuthHeader))
+	if forceProvider == "" && forceAuth == "" {
+		return ctx, providers, false, nil
+	}
+	if !IsAdminAPIKey(h.Cfg, requestAPIKey(ctx)) {
+		return ctx, providers, false, nil
+	}
+	if lookupErr != nil {
+		baseModel := strings.TrimSpace(thinking.ParseSuffix(model).ModelName)
+		return ctx, nil, true, invalidForceTargetError(fmt.Sprintf("model %s is not served by any credential", baseModel))
+	}
+	providers, errMsg := h.ValidateForcedTarget(providers, model, forceProvider, forceAuth)
+	if errMsg != nil {
+		return ctx, nil, true, errMsg
+	}
+	if forceAuth != "" {
+		ctx = WithPinnedAuthID(ctx, forceAuth)
+	}
+	return ctx, providers, true, nil
+}
+
+// ValidateForcedTarget narrows providers, as resolved for model, to a forced provider key
+// and auth ID; empty values force nothing. A target that cannot serve model yields the 400
+// a forced request would get.
+func (h *BaseAPIHandler) ValidateForcedTarget(providers []string, model, forceProvider, forceAuth string) ([]string, *interfaces.ErrorMessage) {
+	forceProvider = strings.TrimSpace(forceProvider)
+	forceAuth = strings.TrimSpace(forceAuth)
+	baseModel := strings.TrimSpace(thinking.ParseSuffix(model).ModelName)
+
+	if forceProvider != "" {
+		matched := ""
+		for _, provider := range providers {
+			if strings.EqualFold(provider, forceProvider) {
+				matched = provider
+				break
+			}
+		}
+		if matched == "" {
+			return nil, invalidForceTargetError(fmt.Sprintf("provider %s does not serve model %s", forceProvider, baseModel))
+		}
+		providers = []string{matched}
+	}
+
+	if forceAuth != "" {
+		if h.AuthManager == nil {
+			return nil, invalidForceTargetError(fmt.Sprintf("auth %s not found", forceAuth))
+		}
+		auth, found := h.AuthManager.GetByID(forceAuth)
+		if !found || auth == nil || auth.Disabled {
+			return nil, invalidForceTargetError(fmt.Sprintf("auth %s not found", forceAuth))
+		}
+		if forceProvider != "" && !strings.EqualFold(auth.Provider, forceProvider) {
+			return nil, invalidForceTargetError(fmt.Sprintf("auth %s does not belong to provider %s", forceAuth, forceProvider))
+		}
+		if !registry.GetGlobalRegistry().ClientSupportsModel(auth.ID, baseModel) {
+			return nil, invalidForceTargetError(fmt.Sprintf("auth %s does not serve model %s", forceAuth, baseModel))
+		}
+		providers = []string{auth.Provider}
+	}
+	return providers, nil
+}
+
+// runForcedTarget has the signature of runWithModelFallbacks but calls run once: a forced
+// target never falls back to another model or provider.
+func runForcedTarget(_ context.Context, _ []byte, _ string, providers []string, model string, _ *interfaces.ErrorMessage, run func(providers []string, model string) error) ([]string, string, error) {
+	return providers, model, run(providers, model)
+}
+
+// invalidForceTargetError builds the 400 returned when a forced target cannot serve the request.
+func invalidForceTargetError(message string) *interfaces.ErrorMessage {
+	return newErrorMessage(http.StatusBadRequest, ErrorDetail{Message: message, Code: "invalid_force_target"})
+}
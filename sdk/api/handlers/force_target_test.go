@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// authIDExecutor answers every request with the ID of the auth that served it.
+type authIDExecutor struct {
+	echoExecutor
+}
+
+func (e *authIDExecutor) Execute(_ context.Context, auth *coreauth.Auth, _ coreexecutor.Request, _ coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{Payload: []byte(auth.ID)}, nil
+}
+
+func contextWithForceHeaders(apiKey string, headers map[string]string) context.Context {
+	ctx := contextWithAPIKey(apiKey)
+	c := ctx.Value("gin").(*gin.Context)
+	for name, value := range headers {
+		c.Request.Header.Set(name, value)
+	}
+	return ctx
+}
+
+func TestExecuteWithAuthManager_ForcedTarget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(&authIDExecutor{})
+	for _, id := range []string{"force-auth-a", "force-auth-b", "force-auth-c"} {
+		auth := &coreauth.Auth{ID: id, Provider: "codex", Status: coreauth.StatusActive}
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("manager.Register: %v", err)
+		}
+		models := []*registry.ModelInfo{{ID: "force-model"}}
+		if id == "force-auth-c" {
+			models = []*registry.ModelInfo{{ID: "force-other-model"}}
+		}
+		registry.GetGlobalRegistry().RegisterClient(id, "codex", models)
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{AdminAPIKeys: []string{"ops-key"}}, manager)
+	body := []byte(`{"model":"force-model","messages":[{"role":"user","content":"hi"}]}`)
+
+	for i := 0; i < 3; i++ {
+		ctx := contextWithForceHeaders("ops-key", map[string]string{ForceAuthHeader: "force-auth-b", ForceProviderHeader: "codex"})
+		payload, _, errMsg := handler.ExecuteWithAuthManager(ctx, "openai", "force-model", body, "")
+		if errMsg != nil {
+			t.Fatalf("forced request failed: %+v", errMsg)
+		}
+		if string(payload) != "force-auth-b" {
+			t.Fatalf("forced request served by %s, want force-auth-b", payload)
+		}
+	}
+
+	invalid := []map[string]string{
+		{ForceAuthHeader: "force-auth-missing"},
+		{ForceAuthHeader: "force-auth-c"},
+		{ForceProviderHeader: "claude"},
+		{ForceAuthHeader: "force-auth-a", ForceProviderHeader: "gemini"},
+	}
+	for _, headers := range invalid {
+		ctx := contextWithForceHeaders("ops-key", headers)
+		_, _, errMsg := handler.ExecuteWithAuthManager(ctx, "openai", "force-model", body, "")
+		if errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+			t.Fatalf("force %v: error = %+v, want 400", headers, errMsg)
+		}
+		if !strings.Contains(errMsg.Error.Error(), "invalid_force_target") {
+			t.Fatalf("force %v: error body = %s", headers, errMsg.Error.Error())
+		}
+	}
+
+	// Non-admin callers have their force headers ignored.
+	ctx := contextWithForceHeaders("team-key", map[string]string{ForceAuthHeader: "force-auth-missing"})
+	if _, _, errMsg := handler.ExecuteWithAuthManager(ctx, "openai", "force-model", body, ""); errMsg != nil {
+		t.Fatalf("non-admin request with force headers failed: %+v", errMsg)
+	}
+}
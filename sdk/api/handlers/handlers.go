@@ -6,6 +6,7 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -16,12 +17,16 @@ import (
 	"github.com/google/uuid"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/transform"
 	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
 )
 
@@ -43,6 +48,12 @@ type ErrorDetail struct {
 
 	// Code is a short code identifying the error, if applicable.
 	Code string `json:"code,omitempty"`
+
+	// Param is the path of the request field the error refers to, if applicable.
+	Param string `json:"param,omitempty"`
+
+	// DidYouMean lists close registered model IDs when the requested model is unknown.
+	DidYouMean []string `json:"did_you_mean,omitempty"`
 }
 
 const idempotencyKeyMetadataKey = "idempotency_key"
@@ -94,17 +105,22 @@ func WithExecutionSessionID(ctx context.Context, sessionID string) context.Conte
 // BuildErrorResponseBody builds an OpenAI-compatible JSON error response body.
 // If errText is already valid JSON, it is returned as-is to preserve upstream error payloads.
 func BuildErrorResponseBody(status int, errText string) []byte {
-	if status <= 0 {
-		status = http.StatusInternalServerError
-	}
-	if strings.TrimSpace(errText) == "" {
-		errText = http.StatusText(status)
-	}
-
 	trimmed := strings.TrimSpace(errText)
 	if trimmed != "" && json.Valid([]byte(trimmed)) {
 		return []byte(trimmed)
 	}
+	return buildErrorDetailBody(status, ErrorDetail{Message: errText})
+}
+
+// buildErrorDetailBody marshals detail as an OpenAI-compatible error body. An empty
+// message, type or code is derived from status.
+func buildErrorDetailBody(status int, detail ErrorDetail) []byte {
+	if status <= 0 {
+		status = http.StatusInternalServerError
+	}
+	if strings.TrimSpace(detail.Message) == "" {
+		detail.Message = http.StatusText(status)
+	}
 
 	errType := "invalid_request_error"
 	var code string
@@ -127,20 +143,26 @@ func BuildErrorResponseBody(status int, errText string) []byte {
 			code = "internal_server_error"
 		}
 	}
+	if detail.Type == "" {
+		detail.Type = errType
+	}
+	if detail.Code == "" {
+		detail.Code = code
+	}
 
-	payload, err := json.Marshal(ErrorResponse{
-		Error: ErrorDetail{
-			Message: errText,
-			Type:    errType,
-			Code:    code,
-		},
-	})
+	payload, err := json.Marshal(ErrorResponse{Error: detail})
 	if err != nil {
-		return []byte(fmt.Sprintf(`{"error":{"message":%q,"type":"server_error","code":"internal_server_error"}}`, errText))
+		return []byte(fmt.Sprintf(`{"error":{"message":%q,"type":"server_error","code":"internal_server_error"}}`, detail.Message))
 	}
 	return payload
 }
 
+// newErrorMessage wraps an error body built by buildErrorDetailBody for the handlers'
+// error paths.
+func newErrorMessage(status int, detail ErrorDetail) *interfaces.ErrorMessage {
+	return &interfaces.ErrorMessage{StatusCode: status, Error: errors.New(string(buildErrorDetailBody(status, detail)))}
+}
+
 // StreamingKeepAliveInterval returns the SSE keep-alive interval for this server.
 // Returning 0 disables keep-alives (default when unset).
 func StreamingKeepAliveInterval(cfg *config.SDKConfig) time.Duration {
@@ -167,6 +189,12 @@ func NonStreamingKeepAliveInterval(cfg *config.SDKConfig) time.Duration {
 	return time.Duration(seconds) * time.Second
 }
 
+// ClaudeBareEventFraming reports whether Claude-format streams should be sent as bare
+// "data:" lines without "event:" names.
+func ClaudeBareEventFraming(cfg *config.SDKConfig) bool {
+	return cfg != nil && strings.EqualFold(strings.TrimSpace(cfg.Streaming.ClaudeEventFraming), "openai")
+}
+
 // StreamingBootstrapRetries returns how many times a streaming request may be retried before any bytes are sent.
 func StreamingBootstrapRetries(cfg *config.SDKConfig) int {
 	retries := defaultStreamingBootstrapRetries
@@ -261,6 +289,18 @@ type BaseAPIHandler struct {
 
 	// Cfg holds the current application configuration.
 	Cfg *config.SDKConfig
+
+	// countCache caches token count responses when count-tokens-cache is enabled.
+	countCache countTokensCache
+
+	// idempotencyCache replays non-streaming responses when idempotency-cache is enabled.
+	idempotencyCache countTokensCache
+
+	// idempotencyInFlight holds retries until the original request with the same key ends.
+	idempotencyInFlight idempotencyInFlight
+
+	// responseCache reuses deterministic non-streaming responses when response-cache is enabled.
+	responseCache countTokensCache
 }
 
 // NewBaseAPIHandlers creates a new API handlers instance.
@@ -468,10 +508,59 @@ func appendAPIResponse(c *gin.Context, data []byte) {
 // ExecuteWithAuthManager executes a non-streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, http.Header, *interfaces.ErrorMessage) {
-	providers, normalizedModel, errMsg := h.getRequestDetails(modelName)
+	providers, normalizedModel, lookupErr := h.fallbackRequestDetails(modelName)
+	if lookupErr != nil && normalizedModel == "" {
+		return nil, nil, lookupErr
+	}
+	errMsg := h.checkModelAccess(ctx, providers, normalizedModel)
+	if errMsg != nil {
+		return nil, nil, errMsg
+	}
+	runTarget := h.runWithModelFallbacks
+	ctx, providers, forced, errMsg := h.applyForcedTarget(ctx, providers, normalizedModel, lookupErr)
 	if errMsg != nil {
 		return nil, nil, errMsg
 	}
+	if forced {
+		runTarget = runForcedTarget
+	}
+	idempotencySize, idempotencyTTL := IdempotencyCacheSettings(h.Cfg)
+	var idempotencyKey string
+	if idempotencySize > 0 {
+		idempotencyKey = idempotencyCacheKey(ctx, handlerType, modelName, alt, rawJSON)
+		for idempotencyKey != "" {
+			if cached, ok := h.idempotencyCache.get(idempotencyKey); ok {
+				return cached, idempotentReplayHeaders(), nil
+			}
+			inFlight, release := h.idempotencyInFlight.begin(idempotencyKey)
+			if release != nil {
+				defer release()
+				break
+			}
+			// The original request is still running; replay its response once it ends, or
+			// run this one when it failed and left nothing in the cache.
+			select {
+			case <-inFlight:
+			case <-ctx.Done():
+				return nil, nil, &interfaces.ErrorMessage{StatusCode: http.StatusRequestTimeout, Error: ctx.Err()}
+			}
+		}
+	}
+	if errMsg = h.checkToolRounds(handlerType, modelName, rawJSON); errMsg != nil {
+		return nil, nil, errMsg
+	}
+	rawJSON = h.stripHistoryThinking(handlerType, rawJSON)
+	if errMsg = h.checkPromptSize(normalizedModel, rawJSON); errMsg != nil {
+		return nil, nil, errMsg
+	}
+	if errMsg = h.checkModeration(ctx, handlerType, modelName, rawJSON); errMsg != nil {
+		return nil, nil, errMsg
+	}
+	transforms := h.transforms()
+	tInfo := transform.Info{Format: handlerType, Model: modelName}
+	if rawJSON, errMsg = applyRequestTransforms(ctx, transforms, tInfo, rawJSON); errMsg != nil {
+		return nil, nil, errMsg
+	}
 	reqMeta := requestExecutionMetadata(ctx)
 	reqMeta[coreexecutor.RequestedModelMetadataKey] = normalizedModel
 	payload := rawJSON
@@ -489,8 +578,50 @@ func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType
 		SourceFormat:    sdktranslator.FromString(handlerType),
 	}
 	opts.Metadata = reqMeta
-	resp, err := h.AuthManager.Execute(ctx, providers, req, opts)
+	responseCacheSize, responseCacheTTL := ResponseCacheSettings(h.Cfg)
+	var responseKey string
+	var responseUsage *usage.Capture
+	// A forced target is a diagnostic request; it always reaches the chosen credential.
+	if responseCacheSize > 0 && !forced {
+		responseKey = responseCacheKey(ctx, handlerType, normalizedModel, alt, rawJSON, h.Cfg.ResponseCache.IncludeTools)
+		if responseKey != "" {
+			if cached, meta, ok := h.responseCache.getWithMeta(responseKey); ok {
+				publishResponseCacheUsage(ctx, meta)
+				cached = newThinkingStripper(h.stripThinkingEnabled(ctx), handlerType).Response(cached)
+				cached = transforms.ApplyResponse(ctx, tInfo, cached)
+				return cached, responseCacheHitHeaders(), nil
+			}
+			responseUsage = &usage.Capture{}
+		}
+	}
+	execCtx := ctx
+	if responseUsage != nil {
+		execCtx = usage.WithCapture(ctx, responseUsage)
+	}
+	var resp coreexecutor.Response
+	execute := func(providers []string, model string) error {
+		req.Model = model
+		reqMeta[coreexecutor.RequestedModelMetadataKey] = model
+		var errExec error
+		resp, errExec = h.AuthManager.Execute(execCtx, providers, req, opts)
+		return errExec
+	}
+	_, _, err := runTarget(ctx, rawJSON, modelName, providers, normalizedModel, lookupErr, execute)
+	empty := err == nil && isEmptyAssistantResponse(handlerType, resp.Payload)
+	if empty {
+		switch h.emptyResponsePolicy() {
+		case "retry":
+			log.Warnf("empty response from upstream for model %s, retrying once", normalizedModel)
+			_, _, err = runTarget(ctx, rawJSON, modelName, providers, normalizedModel, lookupErr, execute)
+			empty = err == nil && isEmptyAssistantResponse(handlerType, resp.Payload)
+		case "error":
+			return nil, nil, &interfaces.ErrorMessage{StatusCode: emptyResponseStatus, Error: errEmptyResponse}
+		}
+	}
 	if err != nil {
+		if errLookup, ok := err.(*lookupError); ok {
+			return nil, nil, errLookup.msg
+		}
 		status := http.StatusInternalServerError
 		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
 			if code := se.StatusCode(); code > 0 {
@@ -505,10 +636,15 @@ func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType
 		}
 		return nil, nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
 	}
-	if !PassthroughHeadersEnabled(h.Cfg) {
-		return resp.Payload, nil, nil
+	if responseKey != "" && !empty {
+		h.responseCache.putWithMeta(responseKey, resp.Payload, responseCacheUsage(responseUsage), responseCacheSize, responseCacheTTL)
 	}
-	return resp.Payload, FilterUpstreamHeaders(resp.Headers), nil
+	resp.Payload = newThinkingStripper(h.stripThinkingEnabled(ctx), handlerType).Response(resp.Payload)
+	resp.Payload = transforms.ApplyResponse(ctx, tInfo, resp.Payload)
+	if idempotencyKey != "" {
+		h.idempotencyCache.put(idempotencyKey, resp.Payload, idempotencySize, idempotencyTTL)
+	}
+	return resp.Payload, UpstreamResponseHeaders(h.Cfg, resp.Headers), nil
 }
 
 // ExecuteCountWithAuthManager executes a non-streaming request via the core auth manager.
@@ -518,6 +654,17 @@ func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handle
 	if errMsg != nil {
 		return nil, nil, errMsg
 	}
+	if errMsg = h.checkModelAccess(ctx, providers, normalizedModel); errMsg != nil {
+		return nil, nil, errMsg
+	}
+	cacheSize, cacheTTL := CountTokensCacheSettings(h.Cfg)
+	var cacheKey string
+	if cacheSize > 0 {
+		cacheKey = countTokensCacheKey(handlerType, normalizedModel, rawJSON)
+		if cached, ok := h.countCache.get(cacheKey); ok {
+			return cached, nil, nil
+		}
+	}
 	reqMeta := requestExecutionMetadata(ctx)
 	reqMeta[coreexecutor.RequestedModelMetadataKey] = normalizedModel
 	payload := rawJSON
@@ -543,6 +690,9 @@ func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handle
 				status = code
 			}
 		}
+		if estimated, ok := estimateTokenCount(handlerType, err, rawJSON); ok {
+			return estimated, http.Header{EstimatedTokenCountHeader: []string{"true"}}, nil
+		}
 		var addon http.Header
 		if he, ok := err.(interface{ Headers() http.Header }); ok && he != nil {
 			if hdr := he.Headers(); hdr != nil {
@@ -551,17 +701,47 @@ func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handle
 		}
 		return nil, nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
 	}
-	if !PassthroughHeadersEnabled(h.Cfg) {
-		return resp.Payload, nil, nil
+	if cacheSize > 0 {
+		h.countCache.put(cacheKey, resp.Payload, cacheSize, cacheTTL)
 	}
-	return resp.Payload, FilterUpstreamHeaders(resp.Headers), nil
+	return resp.Payload, UpstreamResponseHeaders(h.Cfg, resp.Headers), nil
 }
 
 // ExecuteStreamWithAuthManager executes a streaming request via the core auth manager.
 // This path is the only supported execution route.
 // The returned http.Header carries upstream response headers captured before streaming begins.
 func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) (<-chan []byte, http.Header, <-chan *interfaces.ErrorMessage) {
-	providers, normalizedModel, errMsg := h.getRequestDetails(modelName)
+	providers, normalizedModel, lookupErr := h.fallbackRequestDetails(modelName)
+	var errMsg *interfaces.ErrorMessage
+	if lookupErr != nil && normalizedModel == "" {
+		errMsg = lookupErr
+	}
+	if errMsg == nil {
+		errMsg = h.checkModelAccess(ctx, providers, normalizedModel)
+	}
+	runTarget := h.runWithModelFallbacks
+	if errMsg == nil {
+		var forced bool
+		ctx, providers, forced, errMsg = h.applyForcedTarget(ctx, providers, normalizedModel, lookupErr)
+		if forced {
+			runTarget = runForcedTarget
+		}
+	}
+	if errMsg == nil {
+		errMsg = h.checkToolRounds(handlerType, modelName, rawJSON)
+	}
+	if errMsg == nil {
+		rawJSON = h.stripHistoryThinking(handlerType, rawJSON)
+		errMsg = h.checkPromptSize(normalizedModel, rawJSON)
+	}
+	if errMsg == nil {
+		errMsg = h.checkModeration(ctx, handlerType, modelName, rawJSON)
+	}
+	transforms := h.transforms()
+	tInfo := transform.Info{Format: handlerType, Model: modelName, Stream: true}
+	if errMsg == nil {
+		rawJSON, errMsg = applyRequestTransforms(ctx, transforms, tInfo, rawJSON)
+	}
 	if errMsg != nil {
 		errChan := make(chan *interfaces.ErrorMessage, 1)
 		errChan <- errMsg
@@ -585,9 +765,22 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 		SourceFormat:    sdktranslator.FromString(handlerType),
 	}
 	opts.Metadata = reqMeta
-	streamResult, err := h.AuthManager.ExecuteStream(ctx, providers, req, opts)
+	var streamResult *coreexecutor.StreamResult
+	// Bootstrap retries below reuse the providers and request of the attempt that succeeded.
+	providers, _, err := runTarget(ctx, rawJSON, modelName, providers, normalizedModel, lookupErr, func(providers []string, model string) error {
+		req.Model = model
+		reqMeta[coreexecutor.RequestedModelMetadataKey] = model
+		var errExec error
+		streamResult, errExec = h.AuthManager.ExecuteStream(ctx, providers, req, opts)
+		return errExec
+	})
 	if err != nil {
 		errChan := make(chan *interfaces.ErrorMessage, 1)
+		if errLookup, ok := err.(*lookupError); ok {
+			errChan <- errLookup.msg
+			close(errChan)
+			return nil, nil, errChan
+		}
 		status := http.StatusInternalServerError
 		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
 			if code := se.StatusCode(); code > 0 {
@@ -604,16 +797,18 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 		close(errChan)
 		return nil, nil, errChan
 	}
-	passthroughHeadersEnabled := PassthroughHeadersEnabled(h.Cfg)
+	passthroughHeadersEnabled := upstreamHeadersEnabled(h.Cfg)
 	// Capture upstream headers from the initial connection synchronously before the goroutine starts.
 	// Keep a mutable map so bootstrap retries can replace it before first payload is sent.
 	var upstreamHeaders http.Header
 	if passthroughHeadersEnabled {
-		upstreamHeaders = cloneHeader(FilterUpstreamHeaders(streamResult.Headers))
+		upstreamHeaders = cloneHeader(UpstreamResponseHeaders(h.Cfg, streamResult.Headers))
 		if upstreamHeaders == nil {
 			upstreamHeaders = make(http.Header)
 		}
 	}
+	stripper := newThinkingStripper(h.stripThinkingEnabled(ctx), handlerType)
+	progress := newUsageProgress(UsageProgressTokens(h.Cfg), handlerType, alt)
 	chunks := streamResult.Chunks
 	dataChan := make(chan []byte)
 	errChan := make(chan *interfaces.ErrorMessage, 1)
@@ -679,6 +874,11 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 					chunk, ok = <-chunks
 				}
 				if !ok {
+					for _, tail := range transforms.ApplyFinalize(ctx, tInfo) {
+						if okSendData := sendData(tail); !okSendData {
+							return
+						}
+					}
 					return
 				}
 				if chunk.Err != nil {
@@ -691,7 +891,7 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 							retryResult, retryErr := h.AuthManager.ExecuteStream(ctx, providers, req, opts)
 							if retryErr == nil {
 								if passthroughHeadersEnabled {
-									replaceHeader(upstreamHeaders, FilterUpstreamHeaders(retryResult.Headers))
+									replaceHeader(upstreamHeaders, UpstreamResponseHeaders(h.Cfg, retryResult.Headers))
 								}
 								chunks = retryResult.Chunks
 								continue outer
@@ -717,9 +917,19 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 				}
 				if len(chunk.Payload) > 0 {
 					sentPayload = true
-					if okSendData := sendData(cloneBytes(chunk.Payload)); !okSendData {
+					out := stripper.Chunk(cloneBytes(chunk.Payload))
+					if len(out) == 0 {
+						continue
+					}
+					out = transforms.ApplyResponse(ctx, tInfo, out)
+					if okSendData := sendData(out); !okSendData {
 						return
 					}
+					if provisional := progress.Chunk(out); provisional != nil {
+						if okSendData := sendData(provisional); !okSendData {
+							return
+						}
+					}
 				}
 			}
 		}
@@ -767,7 +977,10 @@ func (h *BaseAPIHandler) getRequestDetails(modelName string) (providers []string
 	}
 
 	if len(providers) == 0 {
-		return nil, "", &interfaces.ErrorMessage{StatusCode: http.StatusBadGateway, Error: fmt.Errorf("unknown provider for model %s", modelName)}
+		if registry.GetGlobalRegistry().IsDeclaredUnavailable(baseModel) {
+			return nil, "", modelUnavailableError(modelName)
+		}
+		return nil, "", modelNotFoundError(modelName, baseModel)
 	}
 
 	// The thinking suffix is preserved in the model name itself, so no
@@ -775,6 +988,50 @@ func (h *BaseAPIHandler) getRequestDetails(modelName string) (providers []string
 	return providers, resolvedModelName, nil
 }
 
+// ResolveModel resolves a client model id the same way requests do, including "auto" and
+// thinking suffixes, and returns the providers serving it with the normalized model name.
+// Unknown ids yield the 404, and declared models without a credential the 503, a request
+// for them would get.
+func (h *BaseAPIHandler) ResolveModel(modelName string) ([]string, string, *interfaces.ErrorMessage) {
+	return h.getRequestDetails(modelName)
+}
+
+// LookupModel resolves a client model id the same way requests do, including "auto" and
+// thinking suffixes, and returns the registered model. Unknown ids yield the 404 a request
+// for them would get.
+func (h *BaseAPIHandler) LookupModel(modelName string) (*registry.ModelInfo, *interfaces.ErrorMessage) {
+	_, resolvedModel, errMsg := h.getRequestDetails(modelName)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	baseModel := strings.TrimSpace(thinking.ParseSuffix(resolvedModel).ModelName)
+	info := registry.GetGlobalRegistry().GetModelInfo(baseModel, "")
+	if info == nil {
+		info = registry.GetGlobalRegistry().GetModelInfo(resolvedModel, "")
+	}
+	if info == nil {
+		return nil, modelNotFoundError(modelName, baseModel)
+	}
+	return info, nil
+}
+
+// modelNotFoundError builds a 404 for an unregistered model, listing the closest
+// registered model IDs as did_you_mean suggestions.
+func modelNotFoundError(modelName, baseModel string) *interfaces.ErrorMessage {
+	message := fmt.Sprintf("model %s not found", modelName)
+	suggestions := registry.GetGlobalRegistry().SuggestModels(baseModel)
+	if len(suggestions) > 0 {
+		message = fmt.Sprintf("%s; did you mean %s?", message, strings.Join(suggestions, ", "))
+	}
+	return newErrorMessage(http.StatusNotFound, ErrorDetail{Message: message, Code: "model_not_found", DidYouMean: suggestions})
+}
+
+// modelUnavailableError builds a 503 for a declared model that no credential provides yet.
+func modelUnavailableError(modelName string) *interfaces.ErrorMessage {
+	message := fmt.Sprintf("model %s is declared but no credential currently provides it", modelName)
+	return newErrorMessage(http.StatusServiceUnavailable, ErrorDetail{Message: message, Code: "model_unavailable"})
+}
+
 func cloneBytes(src []byte) []byte {
 	if len(src) == 0 {
 		return nil
@@ -810,8 +1067,13 @@ func (h *BaseAPIHandler) WriteErrorResponse(c *gin.Context, msg *interfaces.Erro
 	if msg != nil && msg.StatusCode > 0 {
 		status = msg.StatusCode
 	}
-	if msg != nil && msg.Addon != nil && PassthroughHeadersEnabled(h.Cfg) {
-		for key, values := range msg.Addon {
+	if msg != nil && msg.Addon != nil && upstreamHeadersEnabled(h.Cfg) {
+		var addon http.Header
+		if PassthroughHeadersEnabled(h.Cfg) {
+			addon = msg.Addon.Clone()
+		}
+		addon = mergeHeaders(addon, forwardedUpstreamHeaders(h.Cfg, FilterUpstreamHeaders(msg.Addon)))
+		for key, values := range addon {
 			if len(values) == 0 {
 				continue
 			}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"net/http"
 	"reflect"
 	"testing"
 	"time"
@@ -8,6 +9,7 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
 )
 
 func TestGetRequestDetails_PreservesSuffix(t *testing.T) {
@@ -116,3 +118,65 @@ func TestGetRequestDetails_PreservesSuffix(t *testing.T) {
 		})
 	}
 }
+
+func TestGetRequestDetails_UnknownModelSuggestsClosest(t *testing.T) {
+	modelRegistry := registry.GetGlobalRegistry()
+	modelRegistry.RegisterClient("test-request-details-suggest", "gemini", []*registry.ModelInfo{
+		{ID: "gemini-2.5-pro"},
+	})
+	t.Cleanup(func() { modelRegistry.UnregisterClient("test-request-details-suggest") })
+
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, coreauth.NewManager(nil, nil, nil))
+	_, _, errMsg := handler.getRequestDetails("gemini-2.5pro")
+	if errMsg == nil {
+		t.Fatal("expected error for unknown model")
+	}
+	if errMsg.StatusCode != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", errMsg.StatusCode, http.StatusNotFound)
+	}
+	body := BuildErrorResponseBody(errMsg.StatusCode, errMsg.Error.Error())
+	if got := gjson.GetBytes(body, "error.code").String(); got != "model_not_found" {
+		t.Fatalf("error.code = %q, want model_not_found; body=%s", got, body)
+	}
+	if got := gjson.GetBytes(body, "error.did_you_mean.0").String(); got != "gemini-2.5-pro" {
+		t.Fatalf("did_you_mean = %s, want [gemini-2.5-pro]", gjson.GetBytes(body, "error.did_you_mean").Raw)
+	}
+}
+
+func TestGetRequestDetails_DeclaredModelWithoutCredential(t *testing.T) {
+	modelRegistry := registry.GetGlobalRegistry()
+	modelRegistry.SetDeclaredModels([]*registry.ModelInfo{{ID: "declared-only-model"}})
+	t.Cleanup(func() { modelRegistry.SetDeclaredModels(nil) })
+
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, coreauth.NewManager(nil, nil, nil))
+	_, _, errMsg := handler.getRequestDetails("declared-only-model")
+	if errMsg == nil {
+		t.Fatal("expected error for declared model without credential")
+	}
+	if errMsg.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", errMsg.StatusCode, http.StatusServiceUnavailable)
+	}
+	body := BuildErrorResponseBody(errMsg.StatusCode, errMsg.Error.Error())
+	if got := gjson.GetBytes(body, "error.code").String(); got != "model_unavailable" {
+		t.Fatalf("error.code = %q, want model_unavailable; body=%s", got, body)
+	}
+}
+
+func TestGetRequestDetails_ParenthesizedModelNameRoutesAsRegistered(t *testing.T) {
+	modelRegistry := registry.GetGlobalRegistry()
+	modelRegistry.RegisterClient("test-request-details-paren-base", "gemini", []*registry.ModelInfo{{ID: "paren-model"}})
+	modelRegistry.RegisterClient("test-request-details-paren-full", "openai", []*registry.ModelInfo{{ID: "paren-model(beta)"}})
+	t.Cleanup(func() {
+		modelRegistry.UnregisterClient("test-request-details-paren-base")
+		modelRegistry.UnregisterClient("test-request-details-paren-full")
+	})
+
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, coreauth.NewManager(nil, nil, nil))
+	providers, model, errMsg := handler.getRequestDetails("paren-model(beta)")
+	if errMsg != nil {
+		t.Fatalf("unexpected error: %v", errMsg.Error)
+	}
+	if !reflect.DeepEqual(providers, []string{"openai"}) || model != "paren-model(beta)" {
+		t.Fatalf("routed to %v/%q, want [openai]/paren-model(beta)", providers, model)
+	}
+}
@@ -3,8 +3,13 @@ package handlers
 import (
 	"net/http"
 	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
 )
 
+// defaultForwardedHeaderPrefix namespaces upstream headers selected by forward-headers.
+const defaultForwardedHeaderPrefix = "X-Upstream-"
+
 // hopByHopHeaders lists RFC 7230 Section 6.1 hop-by-hop headers that MUST NOT
 // be forwarded by proxies, plus security-sensitive headers that should not leak.
 var hopByHopHeaders = map[string]struct{}{
@@ -48,6 +53,74 @@ func FilterUpstreamHeaders(src http.Header) http.Header {
 	return dst
 }
 
+// UpstreamResponseHeaders returns the upstream headers sent to the client: all filtered
+// headers when passthrough-headers is on, plus the forward-headers allowlist renamed into
+// its namespace. Returns nil when there is nothing to send.
+func UpstreamResponseHeaders(cfg *config.SDKConfig, src http.Header) http.Header {
+	filtered := FilterUpstreamHeaders(src)
+	var out http.Header
+	if PassthroughHeadersEnabled(cfg) {
+		out = filtered
+	}
+	return mergeHeaders(out, forwardedUpstreamHeaders(cfg, filtered))
+}
+
+// forwardedUpstreamHeaders returns the headers of src matching forward-headers, renamed
+// with the configured prefix.
+func forwardedUpstreamHeaders(cfg *config.SDKConfig, src http.Header) http.Header {
+	if cfg == nil || len(cfg.ForwardHeaders.Names) == 0 || len(src) == 0 {
+		return nil
+	}
+	prefix := strings.TrimSpace(cfg.ForwardHeaders.Prefix)
+	if prefix == "" {
+		prefix = defaultForwardedHeaderPrefix
+	}
+	var out http.Header
+	for key, values := range src {
+		if !forwardedHeaderAllowed(cfg.ForwardHeaders.Names, key) {
+			continue
+		}
+		if out == nil {
+			out = make(http.Header)
+		}
+		name := http.CanonicalHeaderKey(key)
+		if len(name) > 2 && strings.EqualFold(name[:2], "X-") {
+			name = name[2:]
+		}
+		out[http.CanonicalHeaderKey(prefix+name)] = append([]string(nil), values...)
+	}
+	return out
+}
+
+// mergeHeaders adds the entries of extra to dst, allocating dst when needed.
+func mergeHeaders(dst, extra http.Header) http.Header {
+	if len(extra) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(http.Header, len(extra))
+	}
+	for key, values := range extra {
+		dst[key] = values
+	}
+	return dst
+}
+
+// upstreamHeadersEnabled reports whether any upstream response header can reach the client.
+func upstreamHeadersEnabled(cfg *config.SDKConfig) bool {
+	return PassthroughHeadersEnabled(cfg) || (cfg != nil && len(cfg.ForwardHeaders.Names) > 0)
+}
+
+func forwardedHeaderAllowed(patterns []string, key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range patterns {
+		if matchModelPattern(strings.ToLower(strings.TrimSpace(pattern)), key) {
+			return true
+		}
+	}
+	return false
+}
+
 func connectionScopedHeaders(src http.Header) map[string]struct{} {
 	scoped := make(map[string]struct{})
 	for _, rawValue := range src.Values("Connection") {
@@ -1,8 +1,15 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"reflect"
 	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
 )
 
 func TestFilterUpstreamHeaders_RemovesConnectionScopedHeaders(t *testing.T) {
@@ -53,3 +60,52 @@ func TestFilterUpstreamHeaders_ReturnsNilWhenAllHeadersBlocked(t *testing.T) {
 		t.Fatalf("expected nil when all headers are filtered, got %#v", filtered)
 	}
 }
+
+// headerEchoExecutor echoes requests and returns fixed upstream headers.
+type headerEchoExecutor struct {
+	echoExecutor
+	headers http.Header
+}
+
+func (e *headerEchoExecutor) Execute(ctx context.Context, auth *coreauth.Auth, req coreexecutor.Request, opts coreexecutor.Options) (coreexecutor.Response, error) {
+	resp, err := e.echoExecutor.Execute(ctx, auth, req, opts)
+	resp.Headers = e.headers.Clone()
+	return resp, err
+}
+
+func TestExecuteWithAuthManager_ForwardsAllowlistedHeaders(t *testing.T) {
+	upstream := http.Header{}
+	upstream.Set("X-Request-Id", "req-1")
+	upstream.Set("Anthropic-Ratelimit-Requests-Remaining", "42")
+	upstream.Set("X-Internal-Trace", "secret")
+	upstream.Set("Set-Cookie", "session=secret")
+
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(&headerEchoExecutor{headers: upstream})
+	auth := &coreauth.Auth{ID: "forward-headers-auth", Provider: "codex", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("manager.Register: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "forward-headers-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{
+		ForwardHeaders: sdkconfig.ForwardHeadersConfig{Names: []string{"x-request-id", "anthropic-ratelimit-*", "set-cookie"}},
+	}, manager)
+
+	_, headers, errMsg := handler.ExecuteWithAuthManager(context.Background(), "openai", "forward-headers-model", []byte(`{"model":"forward-headers-model"}`), "")
+	if errMsg != nil {
+		t.Fatalf("ExecuteWithAuthManager error: %+v", errMsg)
+	}
+	want := http.Header{
+		"X-Upstream-Request-Id":                             {"req-1"},
+		"X-Upstream-Anthropic-Ratelimit-Requests-Remaining": {"42"},
+	}
+	if !reflect.DeepEqual(headers, want) {
+		t.Fatalf("forwarded headers = %v, want %v", headers, want)
+	}
+
+	handler.Cfg.ForwardHeaders.Prefix = "X-Provider-"
+	if got := UpstreamResponseHeaders(handler.Cfg, upstream).Get("X-Provider-Request-Id"); got != "req-1" {
+		t.Fatalf("custom prefix header = %q, want req-1", got)
+	}
+}
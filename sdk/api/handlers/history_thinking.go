@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// stripHistoryThinking removes reasoning echoed back in assistant turns that precede the
+// latest user turn, in the client's request format. Final answers, tool calls and the
+// reasoning of the turn in progress (e.g. between tool calls) are kept. It is a no-op
+// unless strip-history-thinking is enabled.
+func (h *BaseAPIHandler) stripHistoryThinking(handlerType string, rawJSON []byte) []byte {
+	if h.Cfg == nil || !h.Cfg.StripHistoryThinking || !gjson.ValidBytes(rawJSON) {
+		return rawJSON
+	}
+	switch handlerType {
+	case "openai":
+		return stripPriorTurns(rawJSON, "messages", isOpenAIUserTurn, func(body []byte, path string, msg gjson.Result) []byte {
+			if msg.Get("role").String() != "assistant" || !msg.Get("reasoning_content").Exists() {
+				return body
+			}
+			if updated, err := sjson.DeleteBytes(body, path+".reasoning_content"); err == nil {
+				return updated
+			}
+			return body
+		})
+	case "claude":
+		return stripPriorTurns(rawJSON, "messages", isClaudeUserTurn, func(body []byte, path string, msg gjson.Result) []byte {
+			if msg.Get("role").String() != "assistant" {
+				return body
+			}
+			return stripArrayKeepingOne(body, path+".content", isClaudeThinkingBlock)
+		})
+	case "gemini", "gemini-cli":
+		path := "contents"
+		if handlerType == "gemini-cli" {
+			path = "request.contents"
+		}
+		return stripPriorTurns(rawJSON, path, isGeminiUserTurn, func(body []byte, path string, content gjson.Result) []byte {
+			if content.Get("role").String() != "model" {
+				return body
+			}
+			return stripArrayKeepingOne(body, path+".parts", isGeminiThoughtPart)
+		})
+	case "openai-response":
+		input := gjson.GetBytes(rawJSON, "input")
+		if !input.IsArray() {
+			return rawJSON
+		}
+		last := lastTurnStart(input.Array(), isResponsesUserTurn)
+		index := 0
+		return stripJSONArrayItems(rawJSON, "input", func(item gjson.Result) bool {
+			prior := index < last
+			index++
+			return prior && item.Get("type").String() == "reasoning"
+		})
+	default:
+		return rawJSON
+	}
+}
+
+// stripPriorTurns applies strip to every item of the array at path that comes before the
+// last item matched by isTurnStart.
+func stripPriorTurns(body []byte, path string, isTurnStart func(gjson.Result) bool, strip func(body []byte, path string, item gjson.Result) []byte) []byte {
+	items := gjson.GetBytes(body, path)
+	if !items.IsArray() {
+		return body
+	}
+	all := items.Array()
+	for i := range lastTurnStart(all, isTurnStart) {
+		body = strip(body, path+"."+strconv.Itoa(i), all[i])
+	}
+	return body
+}
+
+// lastTurnStart returns the index of the last item matched by isTurnStart, or 0.
+func lastTurnStart(items []gjson.Result, isTurnStart func(gjson.Result) bool) int {
+	for i := len(items) - 1; i >= 0; i-- {
+		if isTurnStart(items[i]) {
+			return i
+		}
+	}
+	return 0
+}
+
+// stripArrayKeepingOne drops the items matched by drop unless that would empty the array,
+// since upstreams reject assistant turns without content.
+func stripArrayKeepingOne(body []byte, path string, drop func(gjson.Result) bool) []byte {
+	for _, item := range gjson.GetBytes(body, path).Array() {
+		if !drop(item) {
+			return stripJSONArrayItems(body, path, drop)
+		}
+	}
+	return body
+}
+
+func isOpenAIUserTurn(msg gjson.Result) bool {
+	return msg.Get("role").String() == "user"
+}
+
+// isClaudeUserTurn matches user messages other than those carrying only tool results,
+// which continue the assistant turn they answer.
+func isClaudeUserTurn(msg gjson.Result) bool {
+	if msg.Get("role").String() != "user" {
+		return false
+	}
+	content := msg.Get("content")
+	if !content.IsArray() {
+		return true
+	}
+	for _, block := range content.Array() {
+		if block.Get("type").String() != "tool_result" {
+			return true
+		}
+	}
+	return false
+}
+
+// isGeminiUserTurn matches user contents other than those carrying only function responses.
+func isGeminiUserTurn(content gjson.Result) bool {
+	if role := content.Get("role").String(); role != "user" && role != "" {
+		return false
+	}
+	for _, part := range content.Get("parts").Array() {
+		if !part.Get("functionResponse").Exists() && !part.Get("function_response").Exists() {
+			return true
+		}
+	}
+	return false
+}
+
+func isResponsesUserTurn(item gjson.Result) bool {
+	itemType := item.Get("type").String()
+	return (itemType == "" || itemType == "message") && item.Get("role").String() == "user"
+}
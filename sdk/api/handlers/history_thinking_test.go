@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestExecuteWithAuthManager_StripsPriorTurnThinking(t *testing.T) {
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(&echoExecutor{})
+	auth := &coreauth.Auth{ID: "history-thinking-auth", Provider: "codex", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("manager.Register: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "history-thinking-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{StripHistoryThinking: true}, manager)
+
+	tests := []struct {
+		name   string
+		format string
+		body   string
+		want   map[string]string
+	}{
+		{
+			name:   "gemini",
+			format: "gemini",
+			body: `{"contents":[` +
+				`{"role":"user","parts":[{"text":"q1"}]},` +
+				`{"role":"model","parts":[{"text":"old thoughts","thought":true},{"text":"a1"}]},` +
+				`{"role":"user","parts":[{"text":"q2"}]},` +
+				`{"role":"model","parts":[{"text":"current thoughts","thought":true},{"functionCall":{"name":"f","args":{}}}]},` +
+				`{"role":"user","parts":[{"functionResponse":{"name":"f","response":{}}}]}]}`,
+			want: map[string]string{
+				"contents.1.parts.#":      "1",
+				"contents.1.parts.0.text": "a1",
+				"contents.3.parts.#":      "2",
+			},
+		},
+		{
+			name:   "claude",
+			format: "claude",
+			body: `{"messages":[` +
+				`{"role":"user","content":"q1"},` +
+				`{"role":"assistant","content":[{"type":"thinking","thinking":"old","signature":"s"},{"type":"text","text":"a1"}]},` +
+				`{"role":"user","content":"q2"},` +
+				`{"role":"assistant","content":[{"type":"thinking","thinking":"current","signature":"s"},{"type":"tool_use","id":"t","name":"f","input":{}}]},` +
+				`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t","content":"ok"}]}]}`,
+			want: map[string]string{
+				"messages.1.content.#":      "1",
+				"messages.1.content.0.type": "text",
+				"messages.3.content.0.type": "thinking",
+			},
+		},
+		{
+			name:   "openai",
+			format: "openai",
+			body: `{"messages":[` +
+				`{"role":"user","content":"q1"},` +
+				`{"role":"assistant","content":"a1","reasoning_content":"old"},` +
+				`{"role":"user","content":"q2"}]}`,
+			want: map[string]string{
+				"messages.1.content":           "a1",
+				"messages.1.reasoning_content": "",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, _, errMsg := handler.ExecuteWithAuthManager(context.Background(), tt.format, "history-thinking-model", []byte(tt.body), "")
+			if errMsg != nil {
+				t.Fatalf("ExecuteWithAuthManager: %+v", errMsg)
+			}
+			for path, want := range tt.want {
+				if got := gjson.GetBytes(out, path).String(); got != want {
+					t.Fatalf("%s = %q, want %q in %s", path, got, want, out)
+				}
+			}
+		})
+	}
+}
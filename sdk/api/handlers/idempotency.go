@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client's key for safely retrying a request.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayHeader is set on responses served from the idempotency cache.
+	IdempotentReplayHeader = "Idempotent-Replayed"
+
+	defaultIdempotencyCacheTTL = 60 * time.Second
+)
+
+type idempotencyScopeContextKey struct{}
+
+// WithIdempotencyScope returns a child context that separates one of several upstream
+// calls made for a single client request, so each replays its own cached response.
+func WithIdempotencyScope(ctx context.Context, scope string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, idempotencyScopeContextKey{}, scope)
+}
+
+// IdempotencyCacheSettings returns the configured idempotency cache size and TTL. A size
+// of 0 disables the cache.
+func IdempotencyCacheSettings(cfg *config.SDKConfig) (int, time.Duration) {
+	if cfg == nil || cfg.IdempotencyCache.Size <= 0 {
+		return 0, 0
+	}
+	ttl := defaultIdempotencyCacheTTL
+	if cfg.IdempotencyCache.TTLSeconds > 0 {
+		ttl = time.Duration(cfg.IdempotencyCache.TTLSeconds) * time.Second
+	}
+	return cfg.IdempotencyCache.Size, ttl
+}
+
+// idempotencyCacheKey identifies a retried request by the client's Idempotency-Key, API
+// key, format, model, alt and body hash, so a key reused for different content or by
+// another client never replays someone else's response. It returns "" when the request
+// carries no Idempotency-Key.
+func idempotencyCacheKey(ctx context.Context, handlerType, model, alt string, payload []byte) string {
+	if ctx == nil {
+		return ""
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil || ginCtx.Request == nil {
+		return ""
+	}
+	key := strings.TrimSpace(ginCtx.GetHeader(IdempotencyKeyHeader))
+	if key == "" {
+		return ""
+	}
+	scope, _ := ctx.Value(idempotencyScopeContextKey{}).(string)
+	sum := sha256.New()
+	for _, part := range []string{key, requestAPIKey(ctx), handlerType, model, alt, scope} {
+		sum.Write([]byte(part))
+		sum.Write([]byte{0})
+	}
+	sum.Write(payload)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// idempotentReplayHeaders marks a response as served from the idempotency cache.
+func idempotentReplayHeaders() http.Header {
+	return http.Header{IdempotentReplayHeader: []string{"true"}}
+}
+
+// idempotencyInFlight tracks Idempotency-Key requests whose response is still being
+// produced, so a retry that arrives meanwhile waits for it instead of calling upstream.
+type idempotencyInFlight struct {
+	mu      sync.Mutex
+	pending map[string]chan struct{}
+}
+
+// begin claims key for the caller. When another request already holds it, begin returns
+// that request's done channel and a nil release; otherwise it returns a release func the
+// caller must invoke once its response is cached or the request has failed.
+func (f *idempotencyInFlight) begin(key string) (<-chan struct{}, func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if done, ok := f.pending[key]; ok {
+		return done, nil
+	}
+	if f.pending == nil {
+		f.pending = make(map[string]chan struct{})
+	}
+	done := make(chan struct{})
+	f.pending[key] = done
+	return done, func() {
+		f.mu.Lock()
+		delete(f.pending, key)
+		f.mu.Unlock()
+		close(done)
+	}
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func contextWithIdempotencyKey(key string) context.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	if key != "" {
+		c.Request.Header.Set(IdempotencyKeyHeader, key)
+	}
+	return context.WithValue(context.Background(), "gin", c)
+}
+
+func TestExecuteWithAuthManager_IdempotencyKeyReplaysResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	executor := &countingEchoExecutor{}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	auth := &coreauth.Auth{ID: "idempotency-auth", Provider: "codex", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("manager.Register: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "idempotency-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{IdempotencyCache: sdkconfig.IdempotencyCacheConfig{Size: 8}}, manager)
+	body := []byte(`{"model":"idempotency-model","messages":[{"role":"user","content":"hi"}]}`)
+
+	first, headers, errMsg := handler.ExecuteWithAuthManager(contextWithIdempotencyKey("retry-1"), "openai", "idempotency-model", body, "")
+	if errMsg != nil {
+		t.Fatalf("first call: %+v", errMsg)
+	}
+	if headers.Get(IdempotentReplayHeader) != "" {
+		t.Fatalf("first call marked as replay")
+	}
+	replay, headers, errMsg := handler.ExecuteWithAuthManager(contextWithIdempotencyKey("retry-1"), "openai", "idempotency-model", body, "")
+	if errMsg != nil {
+		t.Fatalf("replay: %+v", errMsg)
+	}
+	if string(replay) != string(first) {
+		t.Fatalf("replay = %s, want %s", replay, first)
+	}
+	if headers.Get(IdempotentReplayHeader) != "true" {
+		t.Fatalf("replay missing %s header", IdempotentReplayHeader)
+	}
+	if calls := atomic.LoadInt32(&executor.calls); calls != 1 {
+		t.Fatalf("upstream calls = %d, want 1 after a replay", calls)
+	}
+
+	// A new key, or none at all, always reaches upstream.
+	if _, _, errMsg = handler.ExecuteWithAuthManager(contextWithIdempotencyKey("retry-2"), "openai", "idempotency-model", body, ""); errMsg != nil {
+		t.Fatalf("new key: %+v", errMsg)
+	}
+	if _, _, errMsg = handler.ExecuteWithAuthManager(contextWithIdempotencyKey(""), "openai", "idempotency-model", body, ""); errMsg != nil {
+		t.Fatalf("no key: %+v", errMsg)
+	}
+	if _, _, errMsg = handler.ExecuteWithAuthManager(contextWithIdempotencyKey(""), "openai", "idempotency-model", body, ""); errMsg != nil {
+		t.Fatalf("no key: %+v", errMsg)
+	}
+	if calls := atomic.LoadInt32(&executor.calls); calls != 4 {
+		t.Fatalf("upstream calls = %d, want 4", calls)
+	}
+}
+
+// gatedEchoExecutor signals each upstream call on started and holds it until release closes.
+type gatedEchoExecutor struct {
+	countingEchoExecutor
+	started chan struct{}
+	release chan struct{}
+}
+
+func (e *gatedEchoExecutor) Execute(ctx context.Context, auth *coreauth.Auth, req coreexecutor.Request, opts coreexecutor.Options) (coreexecutor.Response, error) {
+	e.started <- struct{}{}
+	<-e.release
+	return e.countingEchoExecutor.Execute(ctx, auth, req, opts)
+}
+
+func TestExecuteWithAuthManager_IdempotencyRetryWaitsForInFlightRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	executor := &gatedEchoExecutor{started: make(chan struct{}, 2), release: make(chan struct{})}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	auth := &coreauth.Auth{ID: "idempotency-inflight-auth", Provider: "codex", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("manager.Register: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "idempotency-inflight-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{IdempotencyCache: sdkconfig.IdempotencyCacheConfig{Size: 8}}, manager)
+	body := []byte(`{"model":"idempotency-inflight-model","messages":[{"role":"user","content":"hi"}]}`)
+
+	type result struct {
+		payload []byte
+		headers http.Header
+	}
+	run := func(out chan<- result) {
+		payload, headers, errMsg := handler.ExecuteWithAuthManager(contextWithIdempotencyKey("retry-inflight"), "openai", "idempotency-inflight-model", body, "")
+		if errMsg != nil {
+			t.Errorf("ExecuteWithAuthManager: %+v", errMsg)
+		}
+		out <- result{payload: payload, headers: headers}
+	}
+	original, retry := make(chan result, 1), make(chan result, 1)
+	go run(original)
+	<-executor.started
+	go run(retry)
+
+	select {
+	case <-executor.started:
+		t.Fatal("retry reached upstream while the original request was in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(executor.release)
+
+	first, replay := <-original, <-retry
+	if string(replay.payload) != string(first.payload) {
+		t.Fatalf("retry = %s, want %s", replay.payload, first.payload)
+	}
+	if replay.headers.Get(IdempotentReplayHeader) != "true" {
+		t.Fatalf("retry missing %s header", IdempotentReplayHeader)
+	}
+	if calls := atomic.LoadInt32(&executor.calls); calls != 1 {
+		t.Fatalf("upstream calls = %d, want 1", calls)
+	}
+}
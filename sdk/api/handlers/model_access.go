@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// ModelAllowedForAPIKey reports whether apiKey may use model under cfg.APIKeyModelAccess.
+// It checks a single model name; any thinking suffix is ignored.
+func ModelAllowedForAPIKey(cfg *config.SDKConfig, apiKey, model string) bool {
+	if cfg == nil || len(cfg.APIKeyModelAccess) == 0 || apiKey == "" {
+		return true
+	}
+	baseModel := strings.ToLower(strings.TrimSpace(thinking.ParseSuffix(model).ModelName))
+	for _, rule := range cfg.APIKeyModelAccess {
+		if strings.TrimSpace(rule.APIKey) != apiKey {
+			continue
+		}
+		if matchesAnyModelPattern(rule.Deny, baseModel) {
+			return false
+		}
+		if len(rule.Allow) > 0 && !matchesAnyModelPattern(rule.Allow, baseModel) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkModelAccess returns a 403 error message when the calling API key is not allowed
+// to use model, or any upstream model it resolves to on providers through prefixes,
+// renames or aliases.
+func (h *BaseAPIHandler) checkModelAccess(ctx context.Context, providers []string, model string) *interfaces.ErrorMessage {
+	apiKey := requestAPIKey(ctx)
+	allowed := ModelAllowedForAPIKey(h.Cfg, apiKey, model)
+	if allowed && h.AuthManager != nil && hasModelAccessRules(h.Cfg, apiKey) {
+		for _, upstream := range h.AuthManager.UpstreamModels(providers, thinking.ParseSuffix(model).ModelName) {
+			if !ModelAllowedForAPIKey(h.Cfg, apiKey, upstream) {
+				allowed = false
+				break
+			}
+		}
+	}
+	if allowed {
+		return nil
+	}
+	message := fmt.Sprintf("model %s is not allowed for this API key", thinking.ParseSuffix(model).ModelName)
+	return newErrorMessage(http.StatusForbidden, ErrorDetail{Message: message, Code: "model_not_allowed"})
+}
+
+// hasModelAccessRules reports whether cfg.APIKeyModelAccess has a rule for apiKey.
+func hasModelAccessRules(cfg *config.SDKConfig, apiKey string) bool {
+	if cfg == nil || apiKey == "" {
+		return false
+	}
+	for _, rule := range cfg.APIKeyModelAccess {
+		if strings.TrimSpace(rule.APIKey) == apiKey {
+			return true
+		}
+	}
+	return false
+}
+
+// requestAPIKey returns the client API key set on the gin context by the auth middleware.
+func requestAPIKey(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil {
+		return ""
+	}
+	if value, exists := ginCtx.Get("apiKey"); exists {
+		if apiKey, isString := value.(string); isString {
+			return strings.TrimSpace(apiKey)
+		}
+	}
+	return ""
+}
+
+func matchesAnyModelPattern(patterns []string, model string) bool {
+	for _, pattern := range patterns {
+		if matchModelPattern(strings.ToLower(strings.TrimSpace(pattern)), model) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchModelPattern matches model against pattern, where '*' matches any substring.
+func matchModelPattern(pattern, model string) bool {
+	if pattern == "" {
+		return false
+	}
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == model
+	}
+	if !strings.HasPrefix(model, parts[0]) {
+		return false
+	}
+	model = model[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(model, part)
+		if idx < 0 {
+			return false
+		}
+		model = model[idx+len(part):]
+	}
+	return strings.HasSuffix(model, last)
+}
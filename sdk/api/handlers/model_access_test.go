@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func newModelAccessTestHandler(t *testing.T, rules []sdkconfig.APIKeyModelAccess) *BaseAPIHandler {
+	t.Helper()
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(&echoExecutor{})
+	auth := &coreauth.Auth{ID: "model-access-auth", Provider: "codex", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("manager.Register: %v", err)
+	}
+	manager.SetOAuthModelAlias(map[string][]internalconfig.OAuthModelAlias{
+		"codex": {{Name: "team-model-pro", Alias: "team-model-fast"}},
+	})
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "team-model-mini"}, {ID: "team-model-pro"}, {ID: "team-model-fast"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+	return NewBaseAPIHandlers(&sdkconfig.SDKConfig{APIKeyModelAccess: rules}, manager)
+}
+
+func contextWithAPIKey(apiKey string) context.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	c.Set("apiKey", apiKey)
+	return context.WithValue(context.Background(), "gin", c)
+}
+
+func TestExecuteWithAuthManager_ModelAccessPerAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := newModelAccessTestHandler(t, []sdkconfig.APIKeyModelAccess{
+		{APIKey: "team-a", Allow: []string{"team-model-*"}, Deny: []string{"*-pro"}},
+	})
+
+	// Thinking suffixes are stripped before matching.
+	_, _, errMsg := handler.ExecuteWithAuthManager(contextWithAPIKey("team-a"), "openai", "team-model-mini(high)", []byte(`{"model":"team-model-mini"}`), "")
+	if errMsg != nil {
+		t.Fatalf("allowed model rejected: %+v", errMsg)
+	}
+
+	_, _, errMsg = handler.ExecuteWithAuthManager(contextWithAPIKey("team-a"), "openai", "team-model-pro", []byte(`{"model":"team-model-pro"}`), "")
+	if errMsg == nil || errMsg.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 for denied model, got %+v", errMsg)
+	}
+	if !strings.Contains(errMsg.Error.Error(), "model_not_allowed") {
+		t.Fatalf("error body = %q, want model_not_allowed code", errMsg.Error.Error())
+	}
+
+	// Keys without a rule are unrestricted.
+	if _, _, errMsg = handler.ExecuteWithAuthManager(contextWithAPIKey("team-b"), "openai", "team-model-pro", []byte(`{"model":"team-model-pro"}`), ""); errMsg != nil {
+		t.Fatalf("unrestricted key rejected: %+v", errMsg)
+	}
+}
+
+func TestExecuteStreamWithAuthManager_DeniedModel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := newModelAccessTestHandler(t, []sdkconfig.APIKeyModelAccess{{APIKey: "team-a", Allow: []string{"team-model-mini"}}})
+
+	dataChan, _, errChan := handler.ExecuteStreamWithAuthManager(contextWithAPIKey("team-a"), "openai", "team-model-pro", []byte(`{"model":"team-model-pro"}`), "")
+	if dataChan != nil {
+		t.Fatal("expected no data channel for a denied model")
+	}
+	if errMsg := <-errChan; errMsg == nil || errMsg.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403, got %+v", errMsg)
+	}
+}
+
+func TestExecuteWithAuthManager_DeniedModelBehindAlias(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := newModelAccessTestHandler(t, []sdkconfig.APIKeyModelAccess{{APIKey: "team-a", Deny: []string{"*-pro"}}})
+
+	// team-model-fast is an alias of the denied team-model-pro on the codex channel.
+	_, _, errMsg := handler.ExecuteWithAuthManager(contextWithAPIKey("team-a"), "openai", "team-model-fast", []byte(`{"model":"team-model-fast"}`), "")
+	if errMsg == nil || errMsg.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 for a denied model reached through an alias, got %+v", errMsg)
+	}
+	if _, _, errMsg = handler.ExecuteWithAuthManager(contextWithAPIKey("team-a"), "openai", "team-model-mini", []byte(`{"model":"team-model-mini"}`), ""); errMsg != nil {
+		t.Fatalf("allowed model rejected: %+v", errMsg)
+	}
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// ModelFallbacksForModel returns the fallback targets of the first model-fallbacks entry
+// matching model, or nil when none matches.
+func ModelFallbacksForModel(cfg *config.SDKConfig, model string) []config.ModelFallbackTarget {
+	if cfg == nil {
+		return nil
+	}
+	baseModel := strings.ToLower(strings.TrimSpace(thinking.ParseSuffix(model).ModelName))
+	for _, fallback := range cfg.ModelFallbacks {
+		if matchModelPattern(strings.ToLower(strings.TrimSpace(fallback.Model)), baseModel) {
+			return fallback.Targets
+		}
+	}
+	return nil
+}
+
+// lookupError carries a model lookup failure through the fallback chain so that it is
+// reported with its original status when no fallback target serves the request.
+type lookupError struct {
+	msg *interfaces.ErrorMessage
+}
+
+func (e *lookupError) Error() string {
+	if e.msg == nil || e.msg.Error == nil {
+		return "model lookup failed"
+	}
+	return e.msg.Error.Error()
+}
+
+func (e *lookupError) StatusCode() int {
+	if e.msg == nil {
+		return 0
+	}
+	return e.msg.StatusCode
+}
+
+// modelFallbackEligible reports whether err means the attempted model has no credential
+// able to serve the request: no auth registered or selectable, a model lookup failure,
+// or quota exhaustion.
+func modelFallbackEligible(err error) bool {
+	if err == nil {
+		return false
+	}
+	var lookupErr *lookupError
+	if errors.As(err, &lookupErr) {
+		return true
+	}
+	var authErr *coreauth.Error
+	if errors.As(err, &authErr) {
+		switch authErr.Code {
+		case "auth_not_found", "auth_unavailable":
+			return true
+		}
+	}
+	return statusFromError(err) == http.StatusTooManyRequests
+}
+
+// fallbackRequestDetails resolves modelName like getRequestDetails. When the lookup fails
+// but model-fallbacks covers the model, it also returns the requested name as the normalized
+// model so the fallback chain can serve the request; otherwise normalizedModel is empty.
+func (h *BaseAPIHandler) fallbackRequestDetails(modelName string) (providers []string, normalizedModel string, lookupErr *interfaces.ErrorMessage) {
+	providers, normalizedModel, lookupErr = h.getRequestDetails(modelName)
+	if lookupErr != nil && len(ModelFallbacksForModel(h.Cfg, modelName)) > 0 {
+		normalizedModel = modelName
+	}
+	return providers, normalizedModel, lookupErr
+}
+
+// resolveModelFallback returns the providers and model name serving target, carrying the
+// requested thinking suffix over when the target model has none.
+func (h *BaseAPIHandler) resolveModelFallback(modelName string, target config.ModelFallbackTarget) ([]string, string, bool) {
+	model := strings.TrimSpace(target.Model)
+	if requested := thinking.ParseSuffix(modelName); requested.HasSuffix && !thinking.ParseSuffix(model).HasSuffix {
+		model = fmt.Sprintf("%s(%s)", model, requested.RawSuffix)
+	}
+	providers, normalizedModel, errMsg := h.getRequestDetails(model)
+	if errMsg != nil {
+		return nil, "", false
+	}
+	if want := strings.TrimSpace(target.Provider); want != "" {
+		filtered := make([]string, 0, 1)
+		for _, provider := range providers {
+			if strings.EqualFold(provider, want) {
+				filtered = append(filtered, provider)
+			}
+		}
+		providers = filtered
+	}
+	return providers, normalizedModel, len(providers) > 0
+}
+
+// runWithModelFallbacks calls run against the primary providers and then, while the last
+// failure means no credential could serve the request, against each configured fallback
+// target in order. Targets the caller's API key may not use, or whose prompt limit rawJSON
+// exceeds, are skipped. A non-nil lookupErr skips the primary attempt. It returns the
+// providers and model of the last attempt so callers can retry it.
+func (h *BaseAPIHandler) runWithModelFallbacks(ctx context.Context, rawJSON []byte, modelName string, providers []string, model string, lookupErr *interfaces.ErrorMessage, run func(providers []string, model string) error) ([]string, string, error) {
+	var err error
+	if lookupErr != nil {
+		err = &lookupError{msg: lookupErr}
+	} else {
+		err = run(providers, model)
+	}
+	for _, target := range ModelFallbacksForModel(h.Cfg, modelName) {
+		if !modelFallbackEligible(err) {
+			break
+		}
+		targetProviders, targetModel, ok := h.resolveModelFallback(modelName, target)
+		if !ok {
+			log.WithFields(log.Fields{
+				"model":    modelName,
+				"provider": target.Provider,
+				"target":   target.Model,
+			}).Debug("model fallback target has no provider, skipping")
+			continue
+		}
+		errMsg := h.checkModelAccess(ctx, targetProviders, targetModel)
+		if errMsg == nil {
+			errMsg = h.checkPromptSize(targetModel, rawJSON)
+		}
+		if errMsg != nil {
+			log.WithFields(log.Fields{
+				"model":  modelName,
+				"target": targetModel,
+				"reason": errMsg.Error,
+			}).Debug("model fallback target rejected for this request, skipping")
+			continue
+		}
+		log.WithFields(log.Fields{
+			"model":    modelName,
+			"provider": strings.Join(targetProviders, ","),
+			"target":   targetModel,
+			"reason":   err.Error(),
+		}).Warn("falling back to another model")
+		providers, model = targetProviders, targetModel
+		err = run(providers, model)
+	}
+	return providers, model, err
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// modelRecordingExecutor records the models it is asked to serve and fails with err when set.
+type modelRecordingExecutor struct {
+	id  string
+	err error
+
+	mu     sync.Mutex
+	models []string
+}
+
+func (e *modelRecordingExecutor) Identifier() string { return e.id }
+
+func (e *modelRecordingExecutor) record(model string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.models = append(e.models, model)
+}
+
+func (e *modelRecordingExecutor) seen() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.models...)
+}
+
+func (e *modelRecordingExecutor) Execute(_ context.Context, _ *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (coreexecutor.Response, error) {
+	e.record(req.Model)
+	if e.err != nil {
+		return coreexecutor.Response{}, e.err
+	}
+	return coreexecutor.Response{Payload: []byte(`{"served":"` + e.id + `"}`)}, nil
+}
+
+func (e *modelRecordingExecutor) ExecuteStream(_ context.Context, _ *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (*coreexecutor.StreamResult, error) {
+	e.record(req.Model)
+	if e.err != nil {
+		return nil, e.err
+	}
+	ch := make(chan coreexecutor.StreamChunk, 1)
+	ch <- coreexecutor.StreamChunk{Payload: []byte(e.id)}
+	close(ch)
+	return &coreexecutor.StreamResult{Chunks: ch}, nil
+}
+
+func (e *modelRecordingExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *modelRecordingExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, &coreauth.Error{Code: "not_implemented", Message: "CountTokens not implemented"}
+}
+
+func (e *modelRecordingExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "HttpRequest not implemented", HTTPStatus: http.StatusNotImplemented}
+}
+
+func registerFallbackTestAuth(t *testing.T, manager *coreauth.Manager, executor *modelRecordingExecutor, model string) {
+	t.Helper()
+	manager.RegisterExecutor(executor)
+	auth := &coreauth.Auth{ID: executor.id + "-auth", Provider: executor.id, Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("manager.Register: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: model}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+}
+
+func TestExecuteWithAuthManager_ServedByFallbackWhenPrimaryPoolEmpty(t *testing.T) {
+	manager := coreauth.NewManager(nil, nil, nil)
+	fallback := &modelRecordingExecutor{id: "fallback-provider"}
+	registerFallbackTestAuth(t, manager, fallback, "fallback-model")
+
+	cfg := &sdkconfig.SDKConfig{ModelFallbacks: []sdkconfig.ModelFallback{{
+		Model: "empty-pool-model",
+		Targets: []sdkconfig.ModelFallbackTarget{
+			{Model: "unregistered-model"},
+			{Provider: "fallback-provider", Model: "fallback-model"},
+		},
+	}}}
+	h := NewBaseAPIHandlers(cfg, manager)
+
+	resp, _, errMsg := h.ExecuteWithAuthManager(context.Background(), "openai", "empty-pool-model", []byte(`{"model":"empty-pool-model"}`), "")
+	if errMsg != nil {
+		t.Fatalf("ExecuteWithAuthManager: status %d: %v", errMsg.StatusCode, errMsg.Error)
+	}
+	if string(resp) != `{"served":"fallback-provider"}` {
+		t.Fatalf("response = %s, want the fallback provider's", resp)
+	}
+	if got := fallback.seen(); len(got) != 1 || got[0] != "fallback-model" {
+		t.Fatalf("fallback executor saw models %v, want [fallback-model]", got)
+	}
+
+	data, _, errs := h.ExecuteStreamWithAuthManager(context.Background(), "openai", "empty-pool-model", []byte(`{"model":"empty-pool-model"}`), "")
+	var streamed string
+	for chunk := range data {
+		streamed += string(chunk)
+	}
+	for errMsg := range errs {
+		if errMsg != nil {
+			t.Fatalf("ExecuteStreamWithAuthManager: status %d: %v", errMsg.StatusCode, errMsg.Error)
+		}
+	}
+	if streamed != "fallback-provider" {
+		t.Fatalf("streamed %q, want the fallback provider's output", streamed)
+	}
+}
+
+func TestExecuteWithAuthManager_FallbackOnExhaustedPrimary(t *testing.T) {
+	manager := coreauth.NewManager(nil, nil, nil)
+	primary := &modelRecordingExecutor{id: "exhausted-provider", err: &coreauth.Error{Code: "quota_exceeded", Message: "quota exhausted", HTTPStatus: http.StatusTooManyRequests}}
+	fallback := &modelRecordingExecutor{id: "backup-provider"}
+	registerFallbackTestAuth(t, manager, primary, "exhausted-model")
+	registerFallbackTestAuth(t, manager, fallback, "backup-model")
+
+	cfg := &sdkconfig.SDKConfig{ModelFallbacks: []sdkconfig.ModelFallback{{
+		Model:   "exhausted-*",
+		Targets: []sdkconfig.ModelFallbackTarget{{Model: "backup-model"}},
+	}}}
+	h := NewBaseAPIHandlers(cfg, manager)
+
+	resp, _, errMsg := h.ExecuteWithAuthManager(context.Background(), "openai", "exhausted-model", []byte(`{}`), "")
+	if errMsg != nil {
+		t.Fatalf("ExecuteWithAuthManager: status %d: %v", errMsg.StatusCode, errMsg.Error)
+	}
+	if string(resp) != `{"served":"backup-provider"}` {
+		t.Fatalf("response = %s, want the fallback provider's", resp)
+	}
+	if len(primary.seen()) == 0 {
+		t.Fatalf("primary provider was not tried first")
+	}
+}
+
+func TestExecuteWithAuthManager_NoFallbackKeepsLookupError(t *testing.T) {
+	h := NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, coreauth.NewManager(nil, nil, nil))
+
+	_, _, errMsg := h.ExecuteWithAuthManager(context.Background(), "openai", "empty-pool-model", []byte(`{}`), "")
+	if errMsg == nil || errMsg.StatusCode != http.StatusNotFound {
+		t.Fatalf("errMsg = %+v, want 404", errMsg)
+	}
+}
+
+func TestExecuteWithAuthManager_FallbackSkipsTargetsFailingChecks(t *testing.T) {
+	manager := coreauth.NewManager(nil, nil, nil)
+	primary := &modelRecordingExecutor{id: "drained-provider", err: &coreauth.Error{Code: "quota_exceeded", Message: "quota exhausted", HTTPStatus: http.StatusTooManyRequests}}
+	denied := &modelRecordingExecutor{id: "denied-provider"}
+	small := &modelRecordingExecutor{id: "small-provider"}
+	allowed := &modelRecordingExecutor{id: "allowed-provider"}
+	registerFallbackTestAuth(t, manager, primary, "drained-model")
+	registerFallbackTestAuth(t, manager, denied, "denied-model")
+	registerFallbackTestAuth(t, manager, small, "small-model")
+	registerFallbackTestAuth(t, manager, allowed, "allowed-model")
+
+	cfg := &sdkconfig.SDKConfig{
+		ModelFallbacks: []sdkconfig.ModelFallback{{
+			Model:   "drained-model",
+			Targets: []sdkconfig.ModelFallbackTarget{{Model: "denied-model"}, {Model: "small-model"}, {Model: "allowed-model"}},
+		}},
+		APIKeyModelAccess: []sdkconfig.APIKeyModelAccess{{APIKey: "team-a", Deny: []string{"denied-*"}}},
+		PromptLimits:      []sdkconfig.PromptLimit{{Model: "small-model", MaxTokens: 1}},
+	}
+	h := NewBaseAPIHandlers(cfg, manager)
+
+	resp, _, errMsg := h.ExecuteWithAuthManager(contextWithAPIKey("team-a"), "openai", "drained-model", []byte(`{"messages":[{"role":"user","content":"a prompt over one token"}]}`), "")
+	if errMsg != nil {
+		t.Fatalf("ExecuteWithAuthManager: status %d: %v", errMsg.StatusCode, errMsg.Error)
+	}
+	if string(resp) != `{"served":"allowed-provider"}` {
+		t.Fatalf("response = %s, want the allowed fallback's", resp)
+	}
+	if got := denied.seen(); len(got) != 0 {
+		t.Fatalf("denied fallback was dispatched: %v", got)
+	}
+	if got := small.seen(); len(got) != 0 {
+		t.Fatalf("over-limit fallback was dispatched: %v", got)
+	}
+}
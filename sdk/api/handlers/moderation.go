@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/moderation"
+	log "github.com/sirupsen/logrus"
+)
+
+// moderator resolves the configured moderator, or nil when moderation is disabled. Names
+// without a registered moderator are skipped with a debug log.
+func (h *BaseAPIHandler) moderator() moderation.Moderator {
+	if h.Cfg == nil {
+		return nil
+	}
+	cfg := h.Cfg.Moderation
+	name := strings.TrimSpace(cfg.Moderator)
+	switch name {
+	case "":
+		return nil
+	case moderation.KeywordsModerator:
+		return moderation.Keywords{BlockKeywords: cfg.BlockKeywords, FlagKeywords: cfg.FlagKeywords}
+	}
+	if m, ok := moderation.Lookup(name); ok {
+		return m
+	}
+	log.Debugf("skipping unregistered moderator: %s", name)
+	return nil
+}
+
+// checkModeration runs the configured moderator on the original client payload. Blocked
+// requests are rejected with 403 carrying the reason; flagged requests are logged and
+// forwarded.
+func (h *BaseAPIHandler) checkModeration(ctx context.Context, handlerType, model string, rawJSON []byte) *interfaces.ErrorMessage {
+	m := h.moderator()
+	if m == nil {
+		return nil
+	}
+	decision, err := m.Moderate(ctx, moderation.Info{Format: handlerType, Model: model}, rawJSON)
+	if err != nil {
+		return &interfaces.ErrorMessage{StatusCode: http.StatusInternalServerError, Error: fmt.Errorf("moderation failed: %w", err)}
+	}
+	switch decision.Verdict {
+	case moderation.Block:
+		return moderationBlockedError(decision.Reason)
+	case moderation.Flag:
+		log.WithFields(log.Fields{
+			"request_id": logging.GetRequestID(ctx),
+			"format":     handlerType,
+			"model":      model,
+			"reason":     decision.Reason,
+		}).Warn("moderation: request flagged")
+	}
+	return nil
+}
+
+func moderationBlockedError(reason string) *interfaces.ErrorMessage {
+	message := "request blocked by content moderation"
+	if reason = strings.TrimSpace(reason); reason != "" {
+		message += ": " + reason
+	}
+	return newErrorMessage(http.StatusForbidden, ErrorDetail{Message: message, Type: "invalid_request_error", Code: "content_blocked"})
+}
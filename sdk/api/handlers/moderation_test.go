@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/moderation"
+)
+
+func TestExecuteWithAuthManager_ModerationBlocks(t *testing.T) {
+	handler, executor := newPromptLimitTestHandler(t, &sdkconfig.SDKConfig{
+		Moderation: sdkconfig.ModerationConfig{
+			Moderator:     moderation.KeywordsModerator,
+			BlockKeywords: []string{"Forbidden Topic"},
+			FlagKeywords:  []string{"password"},
+		},
+	})
+
+	blocked := []byte(`{"messages":[{"role":"user","content":"tell me about the forbidden topic"}]}`)
+	_, _, errMsg := handler.ExecuteWithAuthManager(context.Background(), "openai", "limit-model-small", blocked, "")
+	if errMsg == nil || errMsg.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 for blocked request, got %+v", errMsg)
+	}
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	handler.WriteErrorResponse(c, errMsg)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("response status = %d, want 403", rec.Code)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "content_blocked") || !strings.Contains(body, `blocked keyword \"Forbidden Topic\"`) {
+		t.Fatalf("response body = %s, want the block reason", body)
+	}
+	if calls := atomic.LoadInt32(&executor.calls); calls != 0 {
+		t.Fatalf("upstream called %d times for blocked request, want 0", calls)
+	}
+
+	flagged := []byte(`{"messages":[{"role":"user","content":"reset my password"}]}`)
+	if _, _, errMsg = handler.ExecuteWithAuthManager(context.Background(), "openai", "limit-model-small", flagged, ""); errMsg != nil {
+		t.Fatalf("flagged request rejected: %+v", errMsg)
+	}
+	if calls := atomic.LoadInt32(&executor.calls); calls != 1 {
+		t.Fatalf("upstream called %d times, want 1", calls)
+	}
+}
+
+func TestExecuteStreamWithAuthManager_RegisteredModerator(t *testing.T) {
+	moderation.Register("test-block-all", moderation.ModeratorFunc(func(_ context.Context, info moderation.Info, _ []byte) (moderation.Decision, error) {
+		return moderation.Decision{Verdict: moderation.Block, Reason: "no " + info.Format + " traffic"}, nil
+	}))
+	t.Cleanup(func() { moderation.Unregister("test-block-all") })
+	handler, _ := newPromptLimitTestHandler(t, &sdkconfig.SDKConfig{
+		Moderation: sdkconfig.ModerationConfig{Moderator: "test-block-all"},
+	})
+
+	dataChan, _, errChan := handler.ExecuteStreamWithAuthManager(context.Background(), "claude", "limit-model-large", []byte(`{"messages":[]}`), "")
+	if dataChan != nil {
+		t.Fatal("expected no data channel for blocked request")
+	}
+	errMsg := <-errChan
+	if errMsg == nil || errMsg.StatusCode != http.StatusForbidden || !strings.Contains(errMsg.Error.Error(), "no claude traffic") {
+		t.Fatalf("expected 403 with the moderator reason, got %+v", errMsg)
+	}
+}
@@ -0,0 +1,180 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// choiceFanOut returns how many single-choice upstream calls are needed to serve the
+// request's n parameter: 1 when n is unset or the providers honour it natively.
+func (h *OpenAIAPIHandler) choiceFanOut(modelName string, rawJSON []byte) (int, *interfaces.ErrorMessage) {
+	n := gjson.GetBytes(rawJSON, "n")
+	if n.Type != gjson.Number || n.Int() <= 1 || h.SupportsMultipleCandidates(modelName) {
+		return 1, nil
+	}
+	if maxChoices, _ := h.ChoiceFanOutLimits(); n.Int() > int64(maxChoices) {
+		return 0, &interfaces.ErrorMessage{
+			StatusCode: http.StatusBadRequest,
+			Error:      fmt.Errorf("n must be at most %d for model %s", maxChoices, modelName),
+		}
+	}
+	return int(n.Int()), nil
+}
+
+// executeChoiceFanOut issues n parallel single-choice requests and merges their responses
+// into one chat completion with n choices. The first failure cancels the remaining calls.
+func (h *OpenAIAPIHandler) executeChoiceFanOut(ctx context.Context, modelName string, rawJSON []byte, alt string, n int) ([]byte, *interfaces.ErrorMessage) {
+	single, _ := sjson.DeleteBytes(rawJSON, "n")
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr *interfaces.ErrorMessage
+	)
+	responses := make([][]byte, n)
+	_, concurrency := h.ChoiceFanOutLimits()
+	slots := make(chan struct{}, concurrency)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				return
+			}
+			choiceCtx := handlers.WithIdempotencyScope(ctx, fmt.Sprintf("choice-%d", i))
+			resp, _, errMsg := h.ExecuteWithAuthManager(choiceCtx, h.HandlerType(), modelName, single, alt)
+			if errMsg != nil {
+				once.Do(func() {
+					firstErr = errMsg
+					cancel()
+				})
+				return
+			}
+			responses[i] = resp
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, &interfaces.ErrorMessage{StatusCode: http.StatusRequestTimeout, Error: err}
+	}
+	return mergeChatCompletionChoices(responses), nil
+}
+
+// executeStreamChoiceFanOut streams n parallel single-choice requests as one stream, with
+// each sub-stream's choices relabelled to its own index. The first failure cancels the rest.
+func (h *OpenAIAPIHandler) executeStreamChoiceFanOut(ctx context.Context, modelName string, rawJSON []byte, alt string, n int) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
+	single, _ := sjson.DeleteBytes(rawJSON, "n")
+	ctx, cancel := context.WithCancel(ctx)
+
+	out := make(chan []byte)
+	outErrs := make(chan *interfaces.ErrorMessage, n)
+	_, concurrency := h.ChoiceFanOutLimits()
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				return
+			}
+			data, _, errs := h.ExecuteStreamWithAuthManager(ctx, h.HandlerType(), modelName, single, alt)
+			for data != nil || errs != nil {
+				select {
+				case chunk, ok := <-data:
+					if !ok {
+						data = nil
+						continue
+					}
+					select {
+					case out <- relabelChoiceIndex(chunk, i):
+					case <-ctx.Done():
+						return
+					}
+				case errMsg, ok := <-errs:
+					if !ok {
+						errs = nil
+						continue
+					}
+					if errMsg != nil {
+						outErrs <- errMsg
+						cancel()
+						return
+					}
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		cancel()
+		close(out)
+		close(outErrs)
+	}()
+	return out, outErrs
+}
+
+// mergeChatCompletionChoices combines single-choice chat completion responses into the
+// first one, numbering choices in order and summing token usage.
+func mergeChatCompletionChoices(responses [][]byte) []byte {
+	if len(responses) == 0 {
+		return nil
+	}
+	choices := make([]string, 0, len(responses))
+	usage := map[string]int64{}
+	for _, resp := range responses {
+		for _, choice := range gjson.GetBytes(resp, "choices").Array() {
+			relabelled, err := sjson.Set(choice.Raw, "index", len(choices))
+			if err != nil {
+				relabelled = choice.Raw
+			}
+			choices = append(choices, relabelled)
+		}
+		for _, field := range []string{"prompt_tokens", "completion_tokens", "total_tokens"} {
+			if v := gjson.GetBytes(resp, "usage."+field); v.Exists() {
+				usage[field] += v.Int()
+			}
+		}
+	}
+	merged, err := sjson.SetRawBytes(responses[0], "choices", []byte("["+strings.Join(choices, ",")+"]"))
+	if err != nil {
+		return responses[0]
+	}
+	for field, total := range usage {
+		merged, _ = sjson.SetBytes(merged, "usage."+field, total)
+	}
+	return merged
+}
+
+// relabelChoiceIndex sets the index of every choice in a streamed chat completion chunk.
+func relabelChoiceIndex(chunk []byte, index int) []byte {
+	choices := gjson.GetBytes(chunk, "choices")
+	if !choices.IsArray() {
+		return chunk
+	}
+	for i := range len(choices.Array()) {
+		if updated, err := sjson.SetBytes(chunk, fmt.Sprintf("choices.%d.index", i), index); err == nil {
+			chunk = updated
+		}
+	}
+	return chunk
+}
@@ -0,0 +1,152 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+// singleChoiceExecutor stands in for a provider that always returns one choice.
+type singleChoiceExecutor struct {
+	calls      atomic.Int32
+	sawNonUnit atomic.Bool
+}
+
+func (e *singleChoiceExecutor) Identifier() string { return "claude" }
+
+func (e *singleChoiceExecutor) Execute(_ context.Context, _ *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (coreexecutor.Response, error) {
+	e.calls.Add(1)
+	if gjson.GetBytes(req.Payload, "n").Exists() {
+		e.sawNonUnit.Store(true)
+	}
+	return coreexecutor.Response{Payload: []byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"fanout-model","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}`)}, nil
+}
+
+func (e *singleChoiceExecutor) ExecuteStream(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (*coreexecutor.StreamResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (e *singleChoiceExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *singleChoiceExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, errors.New("not implemented")
+}
+
+func (e *singleChoiceExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestChatCompletions_FansOutNForSingleChoiceProviders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	executor := &singleChoiceExecutor{}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+
+	auth := &coreauth.Auth{ID: "fanout-auth", Provider: executor.Identifier(), Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("Register auth: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "fanout-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	h := NewOpenAIAPIHandler(handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, manager))
+	router := gin.New()
+	router.POST("/v1/chat/completions", h.ChatCompletions)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post(`{"model":"fanout-model","n":3,"messages":[{"role":"user","content":"hi"}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if got := executor.calls.Load(); got != 3 {
+		t.Fatalf("upstream calls = %d, want 3", got)
+	}
+	if executor.sawNonUnit.Load() {
+		t.Fatalf("n was forwarded to a fanned-out upstream call")
+	}
+	choices := gjson.Get(rec.Body.String(), "choices").Array()
+	if len(choices) != 3 {
+		t.Fatalf("choices = %d, want 3: %s", len(choices), rec.Body.String())
+	}
+	for i, choice := range choices {
+		if got := choice.Get("index").Int(); got != int64(i) {
+			t.Fatalf("choice %d index = %d", i, got)
+		}
+	}
+	if got := gjson.Get(rec.Body.String(), "usage.total_tokens").Int(); got != 15 {
+		t.Fatalf("usage.total_tokens = %d, want 15", got)
+	}
+
+	if rec = post(`{"model":"fanout-model","n":9,"messages":[{"role":"user","content":"hi"}]}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("n above the fan-out bound: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestChatCompletions_ChoiceFanOutConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	executor := &singleChoiceExecutor{}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+
+	auth := &coreauth.Auth{ID: "fanout-config-auth", Provider: executor.Identifier(), Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("Register auth: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "fanout-config-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	post := func(cfg sdkconfig.ChoiceFanOutConfig, n int) *httptest.ResponseRecorder {
+		h := NewOpenAIAPIHandler(handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{ChoiceFanOut: cfg}, manager))
+		router := gin.New()
+		router.POST("/v1/chat/completions", h.ChatCompletions)
+		body := fmt.Sprintf(`{"model":"fanout-config-model","n":%d,"messages":[{"role":"user","content":"hi"}]}`, n)
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// A provider list without claude passes n through to the upstream in one call.
+	if rec := post(sdkconfig.ChoiceFanOutConfig{Providers: []string{"codex"}}, 3); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if got := executor.calls.Load(); got != 1 || !executor.sawNonUnit.Load() {
+		t.Fatalf("upstream calls = %d (n forwarded: %v), want one call carrying n", got, executor.sawNonUnit.Load())
+	}
+
+	if rec := post(sdkconfig.ChoiceFanOutConfig{MaxChoices: 2}, 3); rec.Code != http.StatusBadRequest {
+		t.Fatalf("n above max-choices: status = %d, want 400", rec.Code)
+	}
+
+	h := handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{ChoiceFanOut: sdkconfig.ChoiceFanOutConfig{MaxChoices: 4, Concurrency: 16}}, manager)
+	if maxChoices, concurrency := h.ChoiceFanOutLimits(); maxChoices != 4 || concurrency != 4 {
+		t.Fatalf("limits = %d/%d, want concurrency capped at max-choices 4", maxChoices, concurrency)
+	}
+	h = handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, manager)
+	if maxChoices, concurrency := h.ChoiceFanOutLimits(); maxChoices != 8 || concurrency != 8 {
+		t.Fatalf("default limits = %d/%d, want 8/8", maxChoices, concurrency)
+	}
+}
@@ -0,0 +1,161 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+// chunkStreamExecutor streams OpenAI chat completion chunks.
+type chunkStreamExecutor struct{}
+
+func (e *chunkStreamExecutor) Identifier() string { return "codex" }
+
+func (e *chunkStreamExecutor) Execute(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, errors.New("not implemented")
+}
+
+func (e *chunkStreamExecutor) ExecuteStream(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (*coreexecutor.StreamResult, error) {
+	ch := make(chan coreexecutor.StreamChunk, 2)
+	ch <- coreexecutor.StreamChunk{Payload: []byte(`{"id":"chatcmpl-1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"hi"}}]}`)}
+	ch <- coreexecutor.StreamChunk{Payload: []byte(`{"id":"chatcmpl-1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`)}
+	close(ch)
+	return &coreexecutor.StreamResult{Chunks: ch}, nil
+}
+
+func (e *chunkStreamExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *chunkStreamExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, errors.New("not implemented")
+}
+
+func (e *chunkStreamExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestChatCompletionsStream_UsesBareDataLines(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(&chunkStreamExecutor{})
+	auth := &coreauth.Auth{ID: "openai-framing-auth", Provider: "codex", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("Register auth: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "openai-framing-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	h := NewOpenAIAPIHandler(handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, manager))
+	router := gin.New()
+	router.POST("/v1/chat/completions", h.ChatCompletions)
+
+	body := `{"model":"openai-framing-model","stream":true,"messages":[{"role":"user","content":"hi"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+
+	out := rec.Body.String()
+	if strings.Contains(out, "event:") {
+		t.Fatalf("openai stream carries event lines:\n%s", out)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line != "" && !strings.HasPrefix(line, "data: ") {
+			t.Fatalf("unexpected line %q in openai stream:\n%s", line, out)
+		}
+	}
+	if !strings.Contains(out, "data: [DONE]") {
+		t.Fatalf("openai stream missing [DONE]:\n%s", out)
+	}
+}
+
+// usageStreamExecutor streams chunks whose terminal chunk carries token usage.
+type usageStreamExecutor struct{ chunkStreamExecutor }
+
+func (e *usageStreamExecutor) Identifier() string { return "claude" }
+
+func (e *usageStreamExecutor) ExecuteStream(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (*coreexecutor.StreamResult, error) {
+	ch := make(chan coreexecutor.StreamChunk, 2)
+	ch <- coreexecutor.StreamChunk{Payload: []byte(`{"id":"chatcmpl-2","object":"chat.completion.chunk","model":"openai-usage-model","choices":[{"index":0,"delta":{"content":"hi"}}]}`)}
+	ch <- coreexecutor.StreamChunk{Payload: []byte(`{"id":"chatcmpl-2","object":"chat.completion.chunk","model":"openai-usage-model","choices":[{"index":0,"delta":{},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}`)}
+	close(ch)
+	return &coreexecutor.StreamResult{Chunks: ch}, nil
+}
+
+func TestChatCompletionsStream_UsageChunkOnlyWhenRequested(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(&usageStreamExecutor{})
+	auth := &coreauth.Auth{ID: "openai-usage-auth", Provider: "claude", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("Register auth: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "openai-usage-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	h := NewOpenAIAPIHandler(handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, manager))
+	router := gin.New()
+	router.POST("/v1/chat/completions", h.ChatCompletions)
+
+	stream := func(body string) []string {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+		}
+		var events []string
+		for _, line := range strings.Split(rec.Body.String(), "\n") {
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				events = append(events, data)
+			}
+		}
+		return events
+	}
+
+	events := stream(`{"model":"openai-usage-model","stream":true,"stream_options":{"include_usage":true},"messages":[{"role":"user","content":"hi"}]}`)
+	if len(events) != 4 || events[3] != "[DONE]" {
+		t.Fatalf("events = %q, want 3 chunks and [DONE]", events)
+	}
+	for _, event := range events[:2] {
+		if gjson.Get(event, "usage").Exists() {
+			t.Fatalf("content chunk carries usage: %s", event)
+		}
+	}
+	final := gjson.Parse(events[2])
+	if n := len(final.Get("choices").Array()); n != 0 || !final.Get("choices").IsArray() {
+		t.Fatalf("usage chunk choices = %s, want []", final.Get("choices").Raw)
+	}
+	if got := final.Get("usage.total_tokens").Int(); got != 5 {
+		t.Fatalf("usage.total_tokens = %d, want 5", got)
+	}
+	if got := final.Get("id").String(); got != "chatcmpl-2" {
+		t.Fatalf("usage chunk id = %q, want chatcmpl-2", got)
+	}
+
+	events = stream(`{"model":"openai-usage-model","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	if len(events) != 3 || events[2] != "[DONE]" {
+		t.Fatalf("events = %q, want 2 chunks and [DONE]", events)
+	}
+	for _, event := range events {
+		if gjson.Get(event, "usage").Exists() {
+			t.Fatalf("usage emitted without include_usage: %s", event)
+		}
+	}
+}
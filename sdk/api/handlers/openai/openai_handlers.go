@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 
 	"github.com/gin-gonic/gin"
@@ -63,6 +64,7 @@ func (h *OpenAIAPIHandler) OpenAIModels(c *gin.Context) {
 	allModels := h.Models()
 
 	// Filter to only include the 4 required fields: id, object, created, owned_by
+	// (plus available for declared models that no credential provides yet)
 	filteredModels := make([]map[string]any, len(allModels))
 	for i, model := range allModels {
 		filteredModel := map[string]any{
@@ -80,6 +82,11 @@ func (h *OpenAIAPIHandler) OpenAIModels(c *gin.Context) {
 			filteredModel["owned_by"] = ownedBy
 		}
 
+		// Keep the availability flag of declared models without credentials
+		if available, exists := model["available"]; exists {
+			filteredModel["available"] = available
+		}
+
 		filteredModels[i] = filteredModel
 	}
 
@@ -89,6 +96,35 @@ func (h *OpenAIAPIHandler) OpenAIModels(c *gin.Context) {
 	})
 }
 
+// OpenAIModel handles the /v1/models/:id endpoint.
+// It returns the details of one model, resolving "auto" and thinking suffixes,
+// or a 404 error when the id is unknown.
+func (h *OpenAIAPIHandler) OpenAIModel(c *gin.Context) {
+	modelID := strings.TrimPrefix(c.Param("id"), "/")
+	info, errMsg := h.LookupModel(modelID)
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		return
+	}
+
+	model := gin.H{
+		"id":       info.ID,
+		"object":   "model",
+		"created":  info.Created,
+		"owned_by": info.OwnedBy,
+	}
+	if info.DisplayName != "" {
+		model["display_name"] = info.DisplayName
+	}
+	if info.Type != "" {
+		model["type"] = info.Type
+	}
+	if info.Thinking != nil {
+		model["thinking"] = info.Thinking
+	}
+	c.JSON(http.StatusOK, model)
+}
+
 // ChatCompletions handles the /v1/chat/completions endpoint.
 // It determines whether the request is for a streaming or non-streaming response
 // and calls the appropriate handler based on the model provider.
@@ -115,9 +151,16 @@ func (h *OpenAIAPIHandler) ChatCompletions(c *gin.Context) {
 	// Some clients send OpenAI Responses-format payloads to /v1/chat/completions.
 	// Convert them to Chat Completions so downstream translators preserve tool metadata.
 	if shouldTreatAsResponsesFormat(rawJSON) {
+		if errMsg := handlers.ValidateRequestBody(OpenaiResponse, rawJSON); errMsg != nil {
+			h.WriteErrorResponse(c, errMsg)
+			return
+		}
 		modelName := gjson.GetBytes(rawJSON, "model").String()
 		rawJSON = responsesconverter.ConvertOpenAIResponsesRequestToOpenAIChatCompletions(modelName, rawJSON, stream)
 		stream = gjson.GetBytes(rawJSON, "stream").Bool()
+	} else if errMsg := handlers.ValidateRequestBody(h.HandlerType(), rawJSON); errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		return
 	}
 
 	if stream {
@@ -430,8 +473,21 @@ func (h *OpenAIAPIHandler) handleNonStreamingResponse(c *gin.Context, rawJSON []
 	c.Header("Content-Type", "application/json")
 
 	modelName := gjson.GetBytes(rawJSON, "model").String()
+	fanOut, errMsg := h.choiceFanOut(modelName, rawJSON)
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		return
+	}
 	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
-	resp, upstreamHeaders, errMsg := h.ExecuteWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, h.GetAlt(c))
+	var (
+		resp            []byte
+		upstreamHeaders http.Header
+	)
+	if fanOut > 1 {
+		resp, errMsg = h.executeChoiceFanOut(cliCtx, modelName, rawJSON, h.GetAlt(c), fanOut)
+	} else {
+		resp, upstreamHeaders, errMsg = h.ExecuteWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, h.GetAlt(c))
+	}
 	if errMsg != nil {
 		h.WriteErrorResponse(c, errMsg)
 		cliCancel(errMsg.Error)
@@ -463,8 +519,22 @@ func (h *OpenAIAPIHandler) handleStreamingResponse(c *gin.Context, rawJSON []byt
 	}
 
 	modelName := gjson.GetBytes(rawJSON, "model").String()
+	fanOut, errMsg := h.choiceFanOut(modelName, rawJSON)
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		return
+	}
 	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
-	dataChan, upstreamHeaders, errChan := h.ExecuteStreamWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, h.GetAlt(c))
+	var (
+		dataChan        <-chan []byte
+		upstreamHeaders http.Header
+		errChan         <-chan *interfaces.ErrorMessage
+	)
+	if fanOut > 1 {
+		dataChan, errChan = h.executeStreamChoiceFanOut(cliCtx, modelName, rawJSON, h.GetAlt(c), fanOut)
+	} else {
+		dataChan, upstreamHeaders, errChan = h.ExecuteStreamWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, h.GetAlt(c))
+	}
 
 	setSSEHeaders := func() {
 		c.Header("Content-Type", "text/event-stream")
@@ -473,6 +543,8 @@ func (h *OpenAIAPIHandler) handleStreamingResponse(c *gin.Context, rawJSON []byt
 		c.Header("Access-Control-Allow-Origin", "*")
 	}
 
+	usageFramer := newStreamUsageFramer(rawJSON, fanOut)
+
 	// Peek at the first chunk to determine success or failure before setting headers
 	for {
 		select {
@@ -508,11 +580,13 @@ func (h *OpenAIAPIHandler) handleStreamingResponse(c *gin.Context, rawJSON []byt
 			setSSEHeaders()
 			handlers.WriteUpstreamHeaders(c.Writer.Header(), upstreamHeaders)
 
-			_, _ = fmt.Fprintf(c.Writer, "data: %s\n\n", string(chunk))
+			if framed := usageFramer.frame(chunk); framed != nil {
+				_, _ = fmt.Fprintf(c.Writer, "data: %s\n\n", string(framed))
+			}
 			flusher.Flush()
 
 			// Continue streaming the rest
-			h.handleStreamResult(c, flusher, func(err error) { cliCancel(err) }, dataChan, errChan)
+			h.handleStreamResult(c, flusher, func(err error) { cliCancel(err) }, dataChan, errChan, usageFramer)
 			return
 		}
 	}
@@ -652,14 +726,20 @@ func (h *OpenAIAPIHandler) handleCompletionsStreamingResponse(c *gin.Context, ra
 			h.handleStreamResult(c, flusher, func(err error) {
 				stop()
 				cliCancel(err)
-			}, convertedChan, errChan)
+			}, convertedChan, errChan, nil)
 			return
 		}
 	}
 }
-func (h *OpenAIAPIHandler) handleStreamResult(c *gin.Context, flusher http.Flusher, cancel func(error), data <-chan []byte, errs <-chan *interfaces.ErrorMessage) {
+
+// handleStreamResult forwards the remaining chat completion chunks. A non-nil usageFramer
+// strips per-chunk usage and emits the usage-only chunk ahead of [DONE] when requested.
+func (h *OpenAIAPIHandler) handleStreamResult(c *gin.Context, flusher http.Flusher, cancel func(error), data <-chan []byte, errs <-chan *interfaces.ErrorMessage, usageFramer *streamUsageFramer) {
 	h.ForwardStream(c, flusher, cancel, data, errs, handlers.StreamForwardOptions{
 		WriteChunk: func(chunk []byte) {
+			if chunk = usageFramer.frame(chunk); chunk == nil {
+				return
+			}
 			_, _ = fmt.Fprintf(c.Writer, "data: %s\n\n", string(chunk))
 		},
 		WriteTerminalError: func(errMsg *interfaces.ErrorMessage) {
@@ -678,6 +758,9 @@ func (h *OpenAIAPIHandler) handleStreamResult(c *gin.Context, flusher http.Flush
 			_, _ = fmt.Fprintf(c.Writer, "data: %s\n\n", string(body))
 		},
 		WriteDone: func() {
+			if usageChunk := usageFramer.final(); usageChunk != nil {
+				_, _ = fmt.Fprintf(c.Writer, "data: %s\n\n", string(usageChunk))
+			}
 			_, _ = fmt.Fprint(c.Writer, "data: [DONE]\n\n")
 		},
 	})
@@ -0,0 +1,68 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func TestOpenAIModelReturnsSingleModel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := coreauth.NewManager(nil, nil, nil)
+	auth := &coreauth.Auth{ID: "model-detail-auth", Provider: "test-provider", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("Register auth: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{
+		ID:          "detail-model",
+		OwnedBy:     "tester",
+		Type:        "openai",
+		DisplayName: "Detail Model",
+		Thinking:    &registry.ThinkingSupport{Levels: []string{"low", "high"}},
+	}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	h := NewOpenAIAPIHandler(handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, manager))
+	router := gin.New()
+	router.GET("/v1/models/*id", h.OpenAIModel)
+	get := func(id string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/models/"+id, nil))
+		return rec
+	}
+
+	// The thinking suffix resolves to the base model.
+	rec := get("detail-model(high)")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var model struct {
+		ID          string                    `json:"id"`
+		Object      string                    `json:"object"`
+		OwnedBy     string                    `json:"owned_by"`
+		Type        string                    `json:"type"`
+		DisplayName string                    `json:"display_name"`
+		Thinking    *registry.ThinkingSupport `json:"thinking"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &model); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if model.ID != "detail-model" || model.Object != "model" || model.OwnedBy != "tester" || model.Type != "openai" || model.DisplayName != "Detail Model" {
+		t.Fatalf("model = %+v", model)
+	}
+	if model.Thinking == nil || len(model.Thinking.Levels) != 2 {
+		t.Fatalf("thinking = %+v, want two levels", model.Thinking)
+	}
+
+	if rec = get("no-such-model"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown model status = %d, want 404; body=%s", rec.Code, rec.Body.String())
+	}
+}
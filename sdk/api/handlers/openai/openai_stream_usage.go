@@ -0,0 +1,94 @@
+package openai
+
+import (
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// streamUsageFramer applies OpenAI's stream_options.include_usage semantics to a chat
+// completion stream: usage is stripped from content chunks and, when requested, emitted
+// once in a trailing chunk with an empty choices array.
+//
+// A nil framer passes chunks through unchanged.
+type streamUsageFramer struct {
+	include bool
+	// perChoice keys usage by choice index so fanned-out sub-streams are summed.
+	perChoice bool
+	usage     map[int64]string
+	order     []int64
+	last      []byte
+}
+
+// newStreamUsageFramer returns a framer for a chat completion request streamed as fanOut
+// single-choice sub-streams (1 when the upstream serves the request directly).
+func newStreamUsageFramer(rawJSON []byte, fanOut int) *streamUsageFramer {
+	return &streamUsageFramer{
+		include:   gjson.GetBytes(rawJSON, "stream_options.include_usage").Bool(),
+		perChoice: fanOut > 1,
+		usage:     make(map[int64]string),
+	}
+}
+
+// frame records and strips the usage carried by chunk. It returns nil when nothing but
+// usage remained, so the caller can skip the chunk. Provisional usage progress chunks are
+// forwarded unchanged and never feed the final usage chunk.
+func (f *streamUsageFramer) frame(chunk []byte) []byte {
+	if f == nil || !gjson.ValidBytes(chunk) {
+		return chunk
+	}
+	if gjson.GetBytes(chunk, "provisional").Bool() {
+		return chunk
+	}
+	f.last = chunk
+	usage := gjson.GetBytes(chunk, "usage")
+	if !usage.Exists() {
+		return chunk
+	}
+	if usage.IsObject() {
+		key := int64(-1)
+		if f.perChoice {
+			key = gjson.GetBytes(chunk, "choices.0.index").Int()
+		}
+		if _, seen := f.usage[key]; !seen {
+			f.order = append(f.order, key)
+		}
+		// Upstream usage is cumulative, so the latest value per stream wins.
+		f.usage[key] = usage.Raw
+	}
+	stripped, err := sjson.DeleteBytes(chunk, "usage")
+	if err != nil {
+		return chunk
+	}
+	if len(gjson.GetBytes(stripped, "choices").Array()) == 0 {
+		return nil
+	}
+	return stripped
+}
+
+// final returns the usage-only chunk to send before [DONE], or nil when usage was not
+// requested or never reported.
+func (f *streamUsageFramer) final() []byte {
+	if f == nil || !f.include || len(f.order) == 0 {
+		return nil
+	}
+	out := []byte(`{"id":"","object":"chat.completion.chunk","created":0,"model":"","choices":[]}`)
+	for _, field := range []string{"id", "created", "model", "system_fingerprint"} {
+		if v := gjson.GetBytes(f.last, field); v.Exists() {
+			out, _ = sjson.SetRawBytes(out, field, []byte(v.Raw))
+		}
+	}
+	if len(f.order) == 1 {
+		out, _ = sjson.SetRawBytes(out, "usage", []byte(f.usage[f.order[0]]))
+		return out
+	}
+	totals := map[string]int64{}
+	for _, key := range f.order {
+		for _, field := range []string{"prompt_tokens", "completion_tokens", "total_tokens"} {
+			totals[field] += gjson.Get(f.usage[key], field).Int()
+		}
+	}
+	for _, field := range []string{"prompt_tokens", "completion_tokens", "total_tokens"} {
+		out, _ = sjson.SetBytes(out, "usage."+field, totals[field])
+	}
+	return out
+}
@@ -0,0 +1,39 @@
+package openai
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestStreamUsageFramer_ForwardsProvisionalUsage(t *testing.T) {
+	f := newStreamUsageFramer([]byte(`{"stream_options":{"include_usage":true}}`), 1)
+
+	content := []byte(`{"id":"chatcmpl-3","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"hi"}}]}`)
+	if got := f.frame(content); string(got) != string(content) {
+		t.Fatalf("content chunk = %s, want unchanged", got)
+	}
+	provisional := []byte(`{"object":"chat.completion.chunk","choices":[],"usage":{"completion_tokens":512},"provisional":true}`)
+	if got := f.frame(provisional); string(got) != string(provisional) {
+		t.Fatalf("provisional chunk = %s, want forwarded unchanged", got)
+	}
+	if got := f.final(); got != nil {
+		t.Fatalf("final() = %s before upstream usage, want nil", got)
+	}
+
+	terminal := []byte(`{"id":"chatcmpl-3","object":"chat.completion.chunk","choices":[],"usage":{"prompt_tokens":4,"completion_tokens":600,"total_tokens":604}}`)
+	if got := f.frame(terminal); got != nil {
+		t.Fatalf("usage-only chunk = %s, want dropped", got)
+	}
+	f.frame(provisional)
+	final := gjson.ParseBytes(f.final())
+	if got := final.Get("usage.completion_tokens").Int(); got != 600 {
+		t.Fatalf("final usage.completion_tokens = %d, want 600", got)
+	}
+	if final.Get("provisional").Exists() {
+		t.Fatalf("final usage chunk marked provisional: %s", final.Raw)
+	}
+	if got := final.Get("id").String(); got != "chatcmpl-3" {
+		t.Fatalf("final id = %q, want chatcmpl-3", got)
+	}
+}
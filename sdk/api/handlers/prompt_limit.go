@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tokenize"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// MaxPromptTokensForModel returns the estimated prompt token cap for model: the first
+// matching prompt-limits entry, otherwise max-prompt-tokens. Returning 0 disables the check.
+func MaxPromptTokensForModel(cfg *config.SDKConfig, model string) int64 {
+	if cfg == nil {
+		return 0
+	}
+	baseModel := strings.ToLower(strings.TrimSpace(thinking.ParseSuffix(model).ModelName))
+	for _, limit := range cfg.PromptLimits {
+		if matchModelPattern(strings.ToLower(strings.TrimSpace(limit.Model)), baseModel) {
+			if limit.MaxTokens <= 0 {
+				return 0
+			}
+			return limit.MaxTokens
+		}
+	}
+	if cfg.MaxPromptTokens <= 0 {
+		return 0
+	}
+	return cfg.MaxPromptTokens
+}
+
+// checkPromptSize rejects a request with 400 when its body exceeds max-prompt-bytes or its
+// locally estimated token count exceeds the cap for model, before any upstream call.
+func (h *BaseAPIHandler) checkPromptSize(model string, rawJSON []byte) *interfaces.ErrorMessage {
+	if h.Cfg == nil {
+		return nil
+	}
+	if limit := h.Cfg.MaxPromptBytes; limit > 0 && int64(len(rawJSON)) > limit {
+		return promptTooLargeError(fmt.Sprintf("request body is %d bytes, exceeding the limit of %d bytes", len(rawJSON), limit))
+	}
+	limit := MaxPromptTokensForModel(h.Cfg, model)
+	if limit <= 0 {
+		return nil
+	}
+	if estimated := tokenize.EstimateJSON(rawJSON); estimated > limit {
+		return promptTooLargeError(fmt.Sprintf("prompt is estimated at %d tokens, exceeding the limit of %d tokens for model %s", estimated, limit, thinking.ParseSuffix(model).ModelName))
+	}
+	return nil
+}
+
+func promptTooLargeError(message string) *interfaces.ErrorMessage {
+	return newErrorMessage(http.StatusBadRequest, ErrorDetail{Message: message, Code: "prompt_too_large"})
+}
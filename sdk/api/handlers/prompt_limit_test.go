@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// countingEchoExecutor echoes requests and counts upstream executions.
+type countingEchoExecutor struct {
+	echoExecutor
+	calls int32
+}
+
+func (e *countingEchoExecutor) Execute(ctx context.Context, auth *coreauth.Auth, req coreexecutor.Request, opts coreexecutor.Options) (coreexecutor.Response, error) {
+	atomic.AddInt32(&e.calls, 1)
+	return e.echoExecutor.Execute(ctx, auth, req, opts)
+}
+
+func newPromptLimitTestHandler(t *testing.T, cfg *sdkconfig.SDKConfig) (*BaseAPIHandler, *countingEchoExecutor) {
+	t.Helper()
+	executor := &countingEchoExecutor{}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	auth := &coreauth.Auth{ID: "prompt-limit-auth", Provider: "codex", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("manager.Register: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "limit-model-small"}, {ID: "limit-model-large"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+	return NewBaseAPIHandlers(cfg, manager), executor
+}
+
+func TestExecuteWithAuthManager_PromptSizePreflight(t *testing.T) {
+	handler, executor := newPromptLimitTestHandler(t, &sdkconfig.SDKConfig{
+		MaxPromptTokens: 20,
+		PromptLimits:    []sdkconfig.PromptLimit{{Model: "limit-model-small", MaxTokens: 5}},
+	})
+	prompt := []byte(`{"messages":[{"role":"user","content":"one two three four five six seven eight nine ten"}]}`)
+
+	_, _, errMsg := handler.ExecuteWithAuthManager(context.Background(), "openai", "limit-model-small", prompt, "")
+	if errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for over-cap prompt, got %+v", errMsg)
+	}
+	if !strings.Contains(errMsg.Error.Error(), "prompt_too_large") {
+		t.Fatalf("error body = %q, want prompt_too_large code", errMsg.Error.Error())
+	}
+	if calls := atomic.LoadInt32(&executor.calls); calls != 0 {
+		t.Fatalf("upstream called %d times for rejected prompt, want 0", calls)
+	}
+
+	// The global cap applies to models without a per-model entry.
+	if _, _, errMsg = handler.ExecuteWithAuthManager(context.Background(), "openai", "limit-model-large", prompt, ""); errMsg != nil {
+		t.Fatalf("under-cap prompt rejected: %+v", errMsg)
+	}
+	if calls := atomic.LoadInt32(&executor.calls); calls != 1 {
+		t.Fatalf("upstream called %d times, want 1", calls)
+	}
+}
+
+func TestExecuteStreamWithAuthManager_PromptBytesPreflight(t *testing.T) {
+	handler, _ := newPromptLimitTestHandler(t, &sdkconfig.SDKConfig{MaxPromptBytes: 16})
+
+	dataChan, _, errChan := handler.ExecuteStreamWithAuthManager(context.Background(), "openai", "limit-model-large", []byte(`{"messages":[{"role":"user","content":"hello"}]}`), "")
+	if dataChan != nil {
+		t.Fatal("expected no data channel for rejected prompt")
+	}
+	errMsg := <-errChan
+	if errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for oversized body, got %+v", errMsg)
+	}
+}
+
+func TestExecuteWithAuthManager_PromptLimitEstimatesImagesFlat(t *testing.T) {
+	handler, executor := newPromptLimitTestHandler(t, &sdkconfig.SDKConfig{MaxPromptTokens: 2000})
+	image := strings.Repeat("iVBORw0KGgoAAAANSUhEUgAA", 20000)
+	prompt := []byte(`{"messages":[{"role":"user","content":[{"type":"text","text":"what is this?"},{"type":"image_url","image_url":{"url":"data:image/png;base64,` + image + `"}}]}]}`)
+
+	if _, _, errMsg := handler.ExecuteWithAuthManager(context.Background(), "openai", "limit-model-large", prompt, ""); errMsg != nil {
+		t.Fatalf("prompt with one image rejected: %+v", errMsg)
+	}
+	if calls := atomic.LoadInt32(&executor.calls); calls != 1 {
+		t.Fatalf("upstream called %d times, want 1", calls)
+	}
+}
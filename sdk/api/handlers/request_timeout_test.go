@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// hangingExecutor simulates an upstream that never answers until the context ends.
+type hangingExecutor struct {
+	echoExecutor
+}
+
+func (e *hangingExecutor) Execute(ctx context.Context, _ *coreauth.Auth, _ coreexecutor.Request, _ coreexecutor.Options) (coreexecutor.Response, error) {
+	<-ctx.Done()
+	return coreexecutor.Response{}, ctx.Err()
+}
+
+func TestExecuteWithAuthManager_RequestTimeoutReturns504(t *testing.T) {
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(&hangingExecutor{})
+	manager.SetConfig(&internalconfig.Config{
+		RequestTimeout:          30,
+		ProviderRequestTimeouts: map[string]int{"Codex": 1},
+	})
+	auth := &coreauth.Auth{ID: "request-timeout-auth", Provider: "codex", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("manager.Register: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "timeout-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, manager)
+
+	started := time.Now()
+	_, _, errMsg := handler.ExecuteWithAuthManager(context.Background(), "openai", "timeout-model", []byte(`{"messages":[{"role":"user","content":"hi"}]}`), "")
+	elapsed := time.Since(started)
+
+	if errMsg == nil || errMsg.StatusCode != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504 after the request timeout, got %+v", errMsg)
+	}
+	if !strings.Contains(errMsg.Error.Error(), "request_timeout") {
+		t.Fatalf("error = %q, want request_timeout code", errMsg.Error.Error())
+	}
+	if elapsed > 10*time.Second {
+		t.Fatalf("request took %s; the provider override of 1s was not applied", elapsed)
+	}
+}
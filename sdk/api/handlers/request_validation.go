@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/tidwall/gjson"
+)
+
+// ValidateRequestBody checks that rawJSON has the structure the given handler format
+// requires before it is translated, so malformed requests fail with a 400 naming the
+// offending field instead of an opaque error from deep inside translation.
+//
+// Checked per format:
+//   - openai: a non-empty messages array whose entries carry a role and content
+//     (assistant messages with tool calls may omit content)
+//   - openai-response: input, when present, is a string or an array of items; message
+//     items carry a role and content
+//   - claude: a non-empty messages array whose entries carry a role and content, with
+//     content blocks typed
+//   - gemini: a non-empty contents array whose entries carry a parts array
+//
+// Other formats are not checked.
+func ValidateRequestBody(handlerType string, rawJSON []byte) *interfaces.ErrorMessage {
+	format := strings.ToLower(strings.TrimSpace(handlerType))
+	switch format {
+	case "openai", "openai-response", "claude", "gemini":
+	default:
+		return nil
+	}
+	if !gjson.ValidBytes(rawJSON) {
+		return invalidRequestBodyError("", "request body is not valid JSON")
+	}
+	root := gjson.ParseBytes(rawJSON)
+	if !root.IsObject() {
+		return invalidRequestBodyError("", "request body must be a JSON object")
+	}
+	switch format {
+	case "openai":
+		return validateOpenAIMessages(root)
+	case "openai-response":
+		return validateOpenAIResponsesInput(root)
+	case "claude":
+		return validateClaudeMessages(root)
+	default:
+		return validateGeminiContents(root)
+	}
+}
+
+func validateOpenAIMessages(root gjson.Result) *interfaces.ErrorMessage {
+	messages, errMsg := requireNonEmptyArray(root, "messages")
+	if errMsg != nil {
+		return errMsg
+	}
+	for i, message := range messages {
+		path := fmt.Sprintf("messages[%d]", i)
+		role, errMsg := requireRole(message, path)
+		if errMsg != nil {
+			return errMsg
+		}
+		content := message.Get("content")
+		if !content.Exists() || content.Type == gjson.Null {
+			if role == "assistant" && (message.Get("tool_calls").Exists() || message.Get("function_call").Exists()) {
+				continue
+			}
+			return invalidRequestBodyError(path+".content", path+".content is required")
+		}
+		if content.Type != gjson.String && !content.IsArray() {
+			return invalidRequestBodyError(path+".content", path+".content must be a string or an array")
+		}
+	}
+	return nil
+}
+
+func validateOpenAIResponsesInput(root gjson.Result) *interfaces.ErrorMessage {
+	input := root.Get("input")
+	if !input.Exists() || input.Type == gjson.String {
+		return nil
+	}
+	if !input.IsArray() {
+		return invalidRequestBodyError("input", "input must be a string or an array")
+	}
+	for i, item := range input.Array() {
+		path := fmt.Sprintf("input[%d]", i)
+		if !item.IsObject() {
+			return invalidRequestBodyError(path, path+" must be an object")
+		}
+		itemType := item.Get("type").String()
+		if itemType != "" && itemType != "message" {
+			continue
+		}
+		if _, errMsg := requireRole(item, path); errMsg != nil {
+			return errMsg
+		}
+		content := item.Get("content")
+		if !content.Exists() || content.Type == gjson.Null {
+			return invalidRequestBodyError(path+".content", path+".content is required")
+		}
+		if content.Type != gjson.String && !content.IsArray() {
+			return invalidRequestBodyError(path+".content", path+".content must be a string or an array")
+		}
+	}
+	return nil
+}
+
+func validateClaudeMessages(root gjson.Result) *interfaces.ErrorMessage {
+	messages, errMsg := requireNonEmptyArray(root, "messages")
+	if errMsg != nil {
+		return errMsg
+	}
+	for i, message := range messages {
+		path := fmt.Sprintf("messages[%d]", i)
+		if _, errMsg = requireRole(message, path); errMsg != nil {
+			return errMsg
+		}
+		content := message.Get("content")
+		if !content.Exists() || content.Type == gjson.Null {
+			return invalidRequestBodyError(path+".content", path+".content is required")
+		}
+		if content.Type == gjson.String {
+			continue
+		}
+		if !content.IsArray() {
+			return invalidRequestBodyError(path+".content", path+".content must be a string or an array")
+		}
+		for j, block := range content.Array() {
+			blockPath := fmt.Sprintf("%s.content[%d]", path, j)
+			if !block.IsObject() || block.Get("type").Type != gjson.String {
+				return invalidRequestBodyError(blockPath+".type", blockPath+".type is required")
+			}
+		}
+	}
+	return nil
+}
+
+func validateGeminiContents(root gjson.Result) *interfaces.ErrorMessage {
+	contents, errMsg := requireNonEmptyArray(root, "contents")
+	if errMsg != nil {
+		return errMsg
+	}
+	for i, content := range contents {
+		path := fmt.Sprintf("contents[%d]", i)
+		if !content.IsObject() {
+			return invalidRequestBodyError(path, path+" must be an object")
+		}
+		if !content.Get("parts").IsArray() {
+			return invalidRequestBodyError(path+".parts", path+".parts must be an array")
+		}
+	}
+	return nil
+}
+
+func requireNonEmptyArray(root gjson.Result, field string) ([]gjson.Result, *interfaces.ErrorMessage) {
+	value := root.Get(field)
+	if !value.Exists() {
+		return nil, invalidRequestBodyError(field, field+" is required")
+	}
+	if !value.IsArray() {
+		return nil, invalidRequestBodyError(field, field+" must be an array")
+	}
+	items := value.Array()
+	if len(items) == 0 {
+		return nil, invalidRequestBodyError(field, field+" must not be empty")
+	}
+	return items, nil
+}
+
+func requireRole(message gjson.Result, path string) (string, *interfaces.ErrorMessage) {
+	if !message.IsObject() {
+		return "", invalidRequestBodyError(path, path+" must be an object")
+	}
+	role := message.Get("role")
+	if role.Type != gjson.String || strings.TrimSpace(role.String()) == "" {
+		return "", invalidRequestBodyError(path+".role", path+".role is required")
+	}
+	return role.String(), nil
+}
+
+func invalidRequestBodyError(param, message string) *interfaces.ErrorMessage {
+	return newErrorMessage(http.StatusBadRequest, ErrorDetail{Message: message, Code: "invalid_request_body", Param: param})
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestValidateRequestBody(t *testing.T) {
+	cases := []struct {
+		name      string
+		format    string
+		body      string
+		wantParam string
+	}{
+		{name: "openai missing messages", format: "openai", body: `{"model":"gpt-4o"}`, wantParam: "messages"},
+		{name: "openai message missing content", format: "openai", body: `{"messages":[{"role":"system","content":"be brief"},{"role":"user"}]}`, wantParam: "messages[1].content"},
+		{name: "openai message missing role", format: "openai", body: `{"messages":[{"content":"hi"}]}`, wantParam: "messages[0].role"},
+		{name: "openai assistant tool call without content", format: "openai", body: `{"messages":[{"role":"user","content":"hi"},{"role":"assistant","content":null,"tool_calls":[{"id":"1"}]}]}`},
+		{name: "openai valid", format: "openai", body: `{"messages":[{"role":"user","content":[{"type":"text","text":"hi"}]}]}`},
+		{name: "invalid json", format: "openai", body: `{"messages":[`, wantParam: ""},
+		{name: "responses input string", format: "openai-response", body: `{"input":"hi"}`},
+		{name: "responses message missing content", format: "openai-response", body: `{"input":[{"type":"function_call_output","call_id":"1","output":"ok"},{"role":"user"}]}`, wantParam: "input[1].content"},
+		{name: "claude missing messages", format: "claude", body: `{"max_tokens":16}`, wantParam: "messages"},
+		{name: "claude untyped block", format: "claude", body: `{"messages":[{"role":"user","content":[{"text":"hi"}]}]}`, wantParam: "messages[0].content[0].type"},
+		{name: "gemini missing parts", format: "gemini", body: `{"contents":[{"role":"user"}]}`, wantParam: "contents[0].parts"},
+		{name: "gemini valid", format: "gemini", body: `{"contents":[{"role":"user","parts":[{"text":"hi"}]}]}`},
+		{name: "unchecked format", format: "gemini-cli", body: `{}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			errMsg := ValidateRequestBody(tc.format, []byte(tc.body))
+			wantErr := tc.wantParam != "" || tc.name == "invalid json"
+			if !wantErr {
+				if errMsg != nil {
+					t.Fatalf("unexpected error: %v", errMsg.Error)
+				}
+				return
+			}
+			if errMsg == nil {
+				t.Fatalf("expected a validation error")
+			}
+			if errMsg.StatusCode != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", errMsg.StatusCode)
+			}
+			body := errMsg.Error.Error()
+			if got := gjson.Get(body, "error.param").String(); got != tc.wantParam {
+				t.Fatalf("error.param = %q, want %q: %s", got, tc.wantParam, body)
+			}
+			if got := gjson.Get(body, "error.code").String(); got != "invalid_request_body" {
+				t.Fatalf("error.code = %q, want invalid_request_body", got)
+			}
+		})
+	}
+}
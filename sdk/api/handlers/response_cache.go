@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// ResponseCacheHeader is set to "hit" on responses served from the response cache.
+	ResponseCacheHeader = "X-Response-Cache"
+
+	defaultResponseCacheTTL = 300 * time.Second
+)
+
+// ResponseCacheSettings returns the configured response cache size and TTL. A size of 0
+// disables the cache.
+func ResponseCacheSettings(cfg *config.SDKConfig) (int, time.Duration) {
+	if cfg == nil || cfg.ResponseCache.Size <= 0 {
+		return 0, 0
+	}
+	ttl := defaultResponseCacheTTL
+	if cfg.ResponseCache.TTLSeconds > 0 {
+		ttl = time.Duration(cfg.ResponseCache.TTLSeconds) * time.Second
+	}
+	return cfg.ResponseCache.Size, ttl
+}
+
+// responseCacheKey identifies a deterministic request by client API key, client format,
+// model, alt and body hash, so one client never sees whether another sent the same prompt.
+// It returns "" when the request must not be cached: sampling is not pinned to temperature
+// 0 or top_k 1, or tools are declared and includeTools is false.
+func responseCacheKey(ctx context.Context, handlerType, model, alt string, payload []byte, includeTools bool) string {
+	if len(payload) == 0 || !isDeterministicRequest(payload) {
+		return ""
+	}
+	if !includeTools && requestDeclaresTools(payload) {
+		return ""
+	}
+	sum := sha256.New()
+	for _, part := range []string{requestAPIKey(ctx), handlerType, model, alt} {
+		sum.Write([]byte(part))
+		sum.Write([]byte{0})
+	}
+	sum.Write(payload)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// isDeterministicRequest reports whether the request pins sampling with temperature 0 or
+// top_k 1, in any of the supported client formats.
+func isDeterministicRequest(payload []byte) bool {
+	for _, path := range []string{"temperature", "generationConfig.temperature"} {
+		if v := gjson.GetBytes(payload, path); v.Type == gjson.Number && v.Float() == 0 {
+			return true
+		}
+	}
+	for _, path := range []string{"top_k", "generationConfig.topK"} {
+		if v := gjson.GetBytes(payload, path); v.Type == gjson.Number && v.Int() == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// requestDeclaresTools reports whether the request offers tools or functions to the model.
+func requestDeclaresTools(payload []byte) bool {
+	for _, path := range []string{"tools", "functions"} {
+		if v := gjson.GetBytes(payload, path); v.IsArray() && len(v.Array()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// responseCacheUsage returns the usage record of the request that produced a cached
+// response: the last successful record it published.
+func responseCacheUsage(capture *usage.Capture) *usage.Record {
+	records := capture.Records()
+	for i := len(records) - 1; i >= 0; i-- {
+		if !records[i].Failed {
+			record := records[i]
+			return &record
+		}
+	}
+	return nil
+}
+
+// publishResponseCacheUsage records a cache hit against the client's API key with the usage
+// of the cached response, so hits count toward per-key usage and quotas. The provider and
+// credential of the original call are cleared so per-credential stats and cost only count
+// upstream calls that actually happened.
+func publishResponseCacheUsage(ctx context.Context, meta any) {
+	original, ok := meta.(*usage.Record)
+	if !ok || original == nil {
+		return
+	}
+	record := *original
+	record.Provider = ""
+	record.AuthID = ""
+	record.AuthIndex = ""
+	record.Source = usage.SourceResponseCache
+	record.APIKey = requestAPIKey(ctx)
+	record.RequestedAt = time.Now()
+	usage.PublishRecord(ctx, record)
+}
+
+// responseCacheHitHeaders marks a response as served from the response cache.
+func responseCacheHitHeaders() http.Header {
+	return http.Header{ResponseCacheHeader: []string{"hit"}}
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func TestExecuteWithAuthManager_ResponseCacheReusesDeterministicResponse(t *testing.T) {
+	executor := &scriptedResponseExecutor{responses: []string{`{"choices":[{"index":0,"message":{"role":"assistant","content":"hello"},"finish_reason":"stop"}]}`}}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	auth := &coreauth.Auth{ID: "response-cache-auth", Provider: "codex", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("manager.Register: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "response-cache-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{ResponseCache: sdkconfig.ResponseCacheConfig{Size: 8}}, manager)
+	call := func(body string) ([]byte, string) {
+		t.Helper()
+		payload, headers, errMsg := handler.ExecuteWithAuthManager(context.Background(), "openai", "response-cache-model", []byte(body), "")
+		if errMsg != nil {
+			t.Fatalf("ExecuteWithAuthManager(%s): %+v", body, errMsg)
+		}
+		return payload, headers.Get(ResponseCacheHeader)
+	}
+
+	deterministic := `{"model":"response-cache-model","temperature":0,"messages":[{"role":"user","content":"hi"}]}`
+	first, marker := call(deterministic)
+	if marker != "" {
+		t.Fatalf("first call marked as cached")
+	}
+	second, marker := call(deterministic)
+	if marker != "hit" {
+		t.Fatalf("repeated deterministic request missing %s: hit", ResponseCacheHeader)
+	}
+	if string(second) != string(first) {
+		t.Fatalf("cached response = %s, want %s", second, first)
+	}
+	if calls := atomic.LoadInt32(&executor.calls); calls != 1 {
+		t.Fatalf("upstream calls = %d, want 1 after a cache hit", calls)
+	}
+
+	// Sampled requests and requests with tools always reach upstream.
+	sampled := `{"model":"response-cache-model","temperature":0.7,"messages":[{"role":"user","content":"hi"}]}`
+	withTools := `{"model":"response-cache-model","temperature":0,"tools":[{"type":"function","function":{"name":"f"}}],"messages":[{"role":"user","content":"hi"}]}`
+	for _, body := range []string{sampled, sampled, withTools, withTools} {
+		if _, marker = call(body); marker != "" {
+			t.Fatalf("request %s served from cache", body)
+		}
+	}
+	if calls := atomic.LoadInt32(&executor.calls); calls != 5 {
+		t.Fatalf("upstream calls = %d, want 5", calls)
+	}
+}
+
+// usageResponseExecutor answers like scriptedResponseExecutor and publishes usage for each call.
+type usageResponseExecutor struct {
+	scriptedResponseExecutor
+}
+
+func (e *usageResponseExecutor) Execute(ctx context.Context, auth *coreauth.Auth, req coreexecutor.Request, opts coreexecutor.Options) (coreexecutor.Response, error) {
+	usage.PublishRecord(ctx, usage.Record{APIKey: requestAPIKey(ctx), Provider: auth.Provider, AuthID: auth.ID, AuthIndex: "1", Model: req.Model, Detail: usage.Detail{InputTokens: 7, OutputTokens: 3, TotalTokens: 10}})
+	return e.scriptedResponseExecutor.Execute(ctx, auth, req, opts)
+}
+
+type responseCacheUsagePlugin struct {
+	records chan usage.Record
+}
+
+func (p *responseCacheUsagePlugin) HandleUsage(_ context.Context, record usage.Record) {
+	if record.Model == "response-cache-usage-model" {
+		p.records <- record
+	}
+}
+
+func TestExecuteWithAuthManager_ResponseCacheIsPerAPIKeyAndRecordsUsage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	executor := &usageResponseExecutor{scriptedResponseExecutor{responses: []string{`{"choices":[{"index":0,"message":{"role":"assistant","content":"hello"},"finish_reason":"stop"}]}`}}}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	auth := &coreauth.Auth{ID: "response-cache-usage-auth", Provider: "codex", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("manager.Register: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "response-cache-usage-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+	plugin := &responseCacheUsagePlugin{records: make(chan usage.Record, 8)}
+	usage.RegisterPlugin(plugin)
+
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{ResponseCache: sdkconfig.ResponseCacheConfig{Size: 8}}, manager)
+	body := []byte(`{"model":"response-cache-usage-model","temperature":0,"messages":[{"role":"user","content":"hi"}]}`)
+	call := func(apiKey string) string {
+		t.Helper()
+		_, headers, errMsg := handler.ExecuteWithAuthManager(contextWithAPIKey(apiKey), "openai", "response-cache-usage-model", body, "")
+		if errMsg != nil {
+			t.Fatalf("ExecuteWithAuthManager(%s): %+v", apiKey, errMsg)
+		}
+		return headers.Get(ResponseCacheHeader)
+	}
+	nextRecord := func() usage.Record {
+		t.Helper()
+		select {
+		case record := <-plugin.records:
+			return record
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a usage record")
+			return usage.Record{}
+		}
+	}
+
+	if marker := call("tenant-a"); marker != "" {
+		t.Fatalf("first call marked as cached")
+	}
+	nextRecord()
+	// Another tenant sending the same prompt must not see tenant-a's response.
+	if marker := call("tenant-b"); marker != "" {
+		t.Fatalf("tenant-b served tenant-a's cached response")
+	}
+	nextRecord()
+	if calls := atomic.LoadInt32(&executor.calls); calls != 2 {
+		t.Fatalf("upstream calls = %d, want 2", calls)
+	}
+
+	if marker := call("tenant-a"); marker != "hit" {
+		t.Fatalf("repeated request from tenant-a missing %s: hit", ResponseCacheHeader)
+	}
+	record := nextRecord()
+	if record.APIKey != "tenant-a" || record.Detail.TotalTokens != 10 {
+		t.Fatalf("cache hit usage = %+v, want tenant-a with the cached response's 10 tokens", record)
+	}
+	if record.Provider != "" || record.AuthID != "" || record.AuthIndex != "" || record.Source != usage.SourceResponseCache {
+		t.Fatalf("cache hit usage = %+v, want no provider or credential and source %q", record, usage.SourceResponseCache)
+	}
+}
@@ -53,6 +53,8 @@ func (h *BaseAPIHandler) ForwardStream(c *gin.Context, flusher http.Flusher, can
 	if opts.KeepAliveInterval != nil {
 		keepAliveInterval = *opts.KeepAliveInterval
 	}
+	// Heartbeats are only emitted after keepAliveInterval of silence; every data chunk
+	// resets the ticker so active streams are never interleaved with comments.
 	var keepAlive *time.Ticker
 	var keepAliveC <-chan time.Time
 	if keepAliveInterval > 0 {
@@ -96,6 +98,9 @@ func (h *BaseAPIHandler) ForwardStream(c *gin.Context, flusher http.Flusher, can
 			}
 			writeChunk(chunk)
 			flusher.Flush()
+			if keepAlive != nil {
+				keepAlive.Reset(keepAliveInterval)
+			}
 		case errMsg, ok := <-errs:
 			if !ok {
 				continue
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func TestForwardStream_EmitsKeepAliveWhileUpstreamStalls(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	data := make(chan []byte)
+	errs := make(chan *interfaces.ErrorMessage)
+	go func() {
+		data <- []byte("first")
+		// Stall long enough for several heartbeats.
+		time.Sleep(150 * time.Millisecond)
+		data <- []byte("second")
+		close(data)
+	}()
+
+	interval := 20 * time.Millisecond
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, nil)
+	handler.ForwardStream(c, c.Writer.(http.Flusher), func(error) {}, data, errs, StreamForwardOptions{
+		KeepAliveInterval: &interval,
+		WriteChunk: func(chunk []byte) {
+			_, _ = c.Writer.Write([]byte("data: " + string(chunk) + "\n\n"))
+		},
+	})
+
+	body := recorder.Body.String()
+	events := strings.Split(strings.TrimSuffix(body, "\n\n"), "\n\n")
+	heartbeats := 0
+	for _, event := range events {
+		switch event {
+		case ": keep-alive":
+			heartbeats++
+		case "data: first", "data: second":
+		default:
+			t.Fatalf("unexpected SSE event %q in body %q", event, body)
+		}
+	}
+	if heartbeats == 0 {
+		t.Fatalf("expected keep-alive heartbeats during stall, body=%q", body)
+	}
+	if events[0] != "data: first" || events[len(events)-1] != "data: second" {
+		t.Fatalf("expected data events to bracket heartbeats, body=%q", body)
+	}
+}
+
+func TestForwardStream_NoKeepAliveWhenDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	data := make(chan []byte)
+	errs := make(chan *interfaces.ErrorMessage)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		data <- []byte("only")
+		close(data)
+	}()
+
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, nil)
+	handler.ForwardStream(c, c.Writer.(http.Flusher), func(error) {}, data, errs, StreamForwardOptions{
+		WriteChunk: func(chunk []byte) {
+			_, _ = c.Writer.Write([]byte("data: " + string(chunk) + "\n\n"))
+		},
+	})
+
+	if body := recorder.Body.String(); strings.Contains(body, "keep-alive") {
+		t.Fatalf("expected no heartbeats when disabled, body=%q", body)
+	}
+}
@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// stripThinkingHeader lets a client override the strip-thinking config for one request.
+const stripThinkingHeader = "X-Strip-Thinking"
+
+// stripThinkingEnabled reports whether reasoning content must be removed from the response.
+// A parseable X-Strip-Thinking header wins over the strip-thinking config value.
+func (h *BaseAPIHandler) stripThinkingEnabled(ctx context.Context) bool {
+	if ctx != nil {
+		if ginCtx, ok := ctx.Value("gin").(*gin.Context); ok && ginCtx != nil && ginCtx.Request != nil {
+			if raw := strings.TrimSpace(ginCtx.GetHeader(stripThinkingHeader)); raw != "" {
+				if enabled, err := strconv.ParseBool(raw); err == nil {
+					return enabled
+				}
+			}
+		}
+	}
+	return h.Cfg != nil && h.Cfg.StripThinking
+}
+
+// thinkingStripper removes reasoning content from translated responses in the client's
+// wire format. Usage counts are left untouched. A stripper is bound to one response;
+// for Claude and OpenAI Responses streams it remembers dropped content blocks or output
+// items so the remaining indexes stay contiguous.
+type thinkingStripper struct {
+	handlerType string
+	// droppedBlocks lists the upstream indexes of removed Claude content blocks or
+	// Responses output items, ascending.
+	droppedBlocks []int64
+}
+
+// newThinkingStripper returns a stripper for handlerType, or nil when stripping is disabled.
+func newThinkingStripper(enabled bool, handlerType string) *thinkingStripper {
+	if !enabled {
+		return nil
+	}
+	return &thinkingStripper{handlerType: handlerType}
+}
+
+// Response strips reasoning content from a complete non-streaming response body.
+func (s *thinkingStripper) Response(body []byte) []byte {
+	if s == nil || len(body) == 0 || !gjson.ValidBytes(body) {
+		return body
+	}
+	switch s.handlerType {
+	case "openai":
+		return stripOpenAIReasoning(body, "message")
+	case "openai-response":
+		return stripJSONArrayItems(body, "output", isResponsesReasoningItem)
+	case "claude":
+		return stripJSONArrayItems(body, "content", isClaudeThinkingBlock)
+	case "gemini", "gemini-cli":
+		return stripGeminiThoughts(body)
+	default:
+		return body
+	}
+}
+
+// Chunk strips reasoning content from one stream chunk. It returns nil when nothing
+// is left to send.
+func (s *thinkingStripper) Chunk(chunk []byte) []byte {
+	if s == nil || len(chunk) == 0 {
+		return chunk
+	}
+	switch s.handlerType {
+	case "openai":
+		if !gjson.ValidBytes(chunk) {
+			return chunk
+		}
+		return stripOpenAIReasoning(chunk, "delta")
+	case "openai-response":
+		return stripSSEEvents(chunk, s.stripResponsesEvent)
+	case "claude":
+		return stripSSEEvents(chunk, s.stripClaudeEvent)
+	case "gemini", "gemini-cli":
+		trimmed := bytes.TrimSpace(chunk)
+		if !gjson.ValidBytes(trimmed) {
+			return chunk
+		}
+		return stripGeminiThoughts(trimmed)
+	default:
+		return chunk
+	}
+}
+
+// stripOpenAIReasoning removes reasoning_content from every choice's message or delta.
+func stripOpenAIReasoning(body []byte, field string) []byte {
+	choices := gjson.GetBytes(body, "choices")
+	if !choices.IsArray() {
+		return body
+	}
+	for i := range choices.Array() {
+		path := "choices." + strconv.Itoa(i) + "." + field + ".reasoning_content"
+		if !gjson.GetBytes(body, path).Exists() {
+			continue
+		}
+		if updated, err := sjson.DeleteBytes(body, path); err == nil {
+			body = updated
+		}
+	}
+	return body
+}
+
+// stripJSONArrayItems rebuilds the array at path without the items matched by drop.
+func stripJSONArrayItems(body []byte, path string, drop func(gjson.Result) bool) []byte {
+	items := gjson.GetBytes(body, path)
+	if !items.IsArray() {
+		return body
+	}
+	kept := make([]byte, 0, len(items.Raw))
+	kept = append(kept, '[')
+	removed := false
+	for _, item := range items.Array() {
+		if drop(item) {
+			removed = true
+			continue
+		}
+		if len(kept) > 1 {
+			kept = append(kept, ',')
+		}
+		kept = append(kept, item.Raw...)
+	}
+	if !removed {
+		return body
+	}
+	kept = append(kept, ']')
+	updated, err := sjson.SetRawBytes(body, path, kept)
+	if err != nil {
+		return body
+	}
+	return updated
+}
+
+func isClaudeThinkingBlock(block gjson.Result) bool {
+	switch block.Get("type").String() {
+	case "thinking", "redacted_thinking":
+		return true
+	}
+	return false
+}
+
+// isGeminiThoughtPart matches thought parts and parts that only carry a thought signature.
+// Signatures attached to function calls are kept because clients must echo them back.
+func isGeminiThoughtPart(part gjson.Result) bool {
+	if part.Get("thought").Bool() {
+		return true
+	}
+	if !part.Get("thoughtSignature").Exists() && !part.Get("thought_signature").Exists() {
+		return false
+	}
+	return !part.Get("text").Exists() && !part.Get("functionCall").Exists() &&
+		!part.Get("inlineData").Exists() && !part.Get("inline_data").Exists()
+}
+
+// stripGeminiThoughts removes thought parts from a Gemini response, a gemini-cli
+// {"response":...} envelope, or an array of either (alt=json streams).
+func stripGeminiThoughts(body []byte) []byte {
+	root := gjson.ParseBytes(body)
+	if root.IsArray() {
+		out := []byte("[]")
+		for _, item := range root.Array() {
+			out, _ = sjson.SetRawBytes(out, "-1", stripGeminiThoughts([]byte(item.Raw)))
+		}
+		return out
+	}
+	prefix := ""
+	if root.Get("response").IsObject() {
+		prefix = "response."
+	}
+	candidates := root.Get(prefix + "candidates")
+	if !candidates.IsArray() {
+		return body
+	}
+	for i := range candidates.Array() {
+		body = stripJSONArrayItems(body, prefix+"candidates."+strconv.Itoa(i)+".content.parts", isGeminiThoughtPart)
+	}
+	return body
+}
+
+// stripSSEEvents filters the SSE events in a stream chunk through strip, which rewrites
+// an event's data or drops the whole event. The chunk keeps its trailing separator.
+func stripSSEEvents(chunk []byte, strip func(data string) (string, bool)) []byte {
+	normalized := strings.ReplaceAll(string(chunk), "\r\n", "\n")
+	kept := make([]string, 0, 1)
+	for _, event := range strings.Split(normalized, "\n\n") {
+		if strings.TrimSpace(event) == "" {
+			continue
+		}
+		lines := strings.Split(strings.Trim(event, "\n"), "\n")
+		keep := true
+		for i, line := range lines {
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+			data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			rewritten, keepEvent := strip(data)
+			if !keepEvent {
+				keep = false
+				break
+			}
+			lines[i] = "data: " + rewritten
+		}
+		if keep {
+			kept = append(kept, strings.Join(lines, "\n"))
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	out := strings.Join(kept, "\n\n")
+	if strings.HasSuffix(normalized, "\n") {
+		out += "\n\n"
+	}
+	return []byte(out)
+}
+
+// stripClaudeEvent decides whether one Claude stream event is kept and rewrites its index.
+func (s *thinkingStripper) stripClaudeEvent(data string) (string, bool) {
+	if !gjson.Valid(data) {
+		return data, true
+	}
+	indexResult := gjson.Get(data, "index")
+	if !indexResult.Exists() {
+		return data, true
+	}
+	index := indexResult.Int()
+	switch gjson.Get(data, "type").String() {
+	case "content_block_start":
+		if isClaudeThinkingBlock(gjson.Get(data, "content_block")) {
+			s.droppedBlocks = append(s.droppedBlocks, index)
+			return data, false
+		}
+	case "content_block_delta", "content_block_stop":
+		if s.isDropped(index) {
+			return data, false
+		}
+	default:
+		return data, true
+	}
+	return s.renumber(data, "index", index), true
+}
+
+// stripResponsesEvent decides whether one OpenAI Responses stream event is kept. Reasoning
+// output items and their summary and text events are dropped, later output_index values
+// are renumbered, and reasoning items are removed from the final response object.
+func (s *thinkingStripper) stripResponsesEvent(data string) (string, bool) {
+	if !gjson.Valid(data) {
+		return data, true
+	}
+	eventType := gjson.Get(data, "type").String()
+	if strings.HasPrefix(eventType, "response.reasoning") {
+		return data, false
+	}
+	if output := gjson.Get(data, "response.output"); output.IsArray() {
+		return string(stripJSONArrayItems([]byte(data), "response.output", isResponsesReasoningItem)), true
+	}
+	indexResult := gjson.Get(data, "output_index")
+	if !indexResult.Exists() {
+		return data, true
+	}
+	index := indexResult.Int()
+	if eventType == "response.output_item.added" && isResponsesReasoningItem(gjson.Get(data, "item")) {
+		s.droppedBlocks = append(s.droppedBlocks, index)
+		return data, false
+	}
+	if s.isDropped(index) {
+		return data, false
+	}
+	return s.renumber(data, "output_index", index), true
+}
+
+func (s *thinkingStripper) isDropped(index int64) bool {
+	for _, dropped := range s.droppedBlocks {
+		if dropped == index {
+			return true
+		}
+	}
+	return false
+}
+
+// renumber rewrites the index at path to skip the dropped blocks before it.
+func (s *thinkingStripper) renumber(data, path string, index int64) string {
+	shift := int64(0)
+	for _, dropped := range s.droppedBlocks {
+		if dropped < index {
+			shift++
+		}
+	}
+	if shift == 0 {
+		return data
+	}
+	rewritten, err := sjson.Set(data, path, index-shift)
+	if err != nil {
+		return data
+	}
+	return rewritten
+}
+
+func isResponsesReasoningItem(item gjson.Result) bool {
+	return item.Get("type").String() == "reasoning"
+}
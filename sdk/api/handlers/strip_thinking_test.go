@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestThinkingStripper_OpenAI(t *testing.T) {
+	s := newThinkingStripper(true, "openai")
+
+	body := []byte(`{"choices":[{"index":0,"message":{"role":"assistant","content":"answer","reasoning_content":"thoughts"}}],"usage":{"completion_tokens_details":{"reasoning_tokens":12}}}`)
+	out := s.Response(body)
+	if gjson.GetBytes(out, "choices.0.message.reasoning_content").Exists() {
+		t.Fatalf("reasoning_content kept: %s", out)
+	}
+	if got := gjson.GetBytes(out, "choices.0.message.content").String(); got != "answer" {
+		t.Fatalf("content = %q, want answer", got)
+	}
+	if got := gjson.GetBytes(out, "usage.completion_tokens_details.reasoning_tokens").Int(); got != 12 {
+		t.Fatalf("reasoning_tokens = %d, want 12", got)
+	}
+
+	chunk := s.Chunk([]byte(`{"choices":[{"index":0,"delta":{"role":"assistant","reasoning_content":"hmm"}}]}`))
+	if gjson.GetBytes(chunk, "choices.0.delta.reasoning_content").Exists() {
+		t.Fatalf("stream reasoning_content kept: %s", chunk)
+	}
+}
+
+func TestThinkingStripper_ClaudeResponse(t *testing.T) {
+	s := newThinkingStripper(true, "claude")
+	body := []byte(`{"content":[{"type":"thinking","thinking":"x","signature":"s"},{"type":"text","text":"answer"},{"type":"redacted_thinking","data":"y"}],"usage":{"output_tokens":30}}`)
+	out := s.Response(body)
+	content := gjson.GetBytes(out, "content").Array()
+	if len(content) != 1 || content[0].Get("type").String() != "text" {
+		t.Fatalf("content = %s, want only the text block", gjson.GetBytes(out, "content").Raw)
+	}
+	if got := gjson.GetBytes(out, "usage.output_tokens").Int(); got != 30 {
+		t.Fatalf("output_tokens = %d, want 30", got)
+	}
+}
+
+func TestThinkingStripper_ClaudeStream(t *testing.T) {
+	s := newThinkingStripper(true, "claude")
+	var out strings.Builder
+	for _, chunk := range []string{
+		"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{}}\n\n",
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"thinking\",\"thinking\":\"\"}}\n\n" +
+			"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"thinking_delta\",\"thinking\":\"x\"}}\n\n\n",
+		"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}\n\n",
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":1,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":1,\"delta\":{\"type\":\"text_delta\",\"text\":\"hi\"}}\n\n",
+		"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":1}\n\n",
+		"event: message_delta\ndata: {\"type\":\"message_delta\",\"usage\":{\"output_tokens\":5}}\n\n",
+	} {
+		out.Write(s.Chunk([]byte(chunk)))
+	}
+	got := out.String()
+	if strings.Contains(got, "thinking") {
+		t.Fatalf("thinking events kept:\n%s", got)
+	}
+	if !strings.Contains(got, `"index":0,"content_block":{"type":"text"`) {
+		t.Fatalf("text block not renumbered to index 0:\n%s", got)
+	}
+	if strings.Contains(got, `"index":1`) {
+		t.Fatalf("stale index 1 left:\n%s", got)
+	}
+	if !strings.Contains(got, `"output_tokens":5`) {
+		t.Fatalf("usage event dropped:\n%s", got)
+	}
+}
+
+func TestThinkingStripper_ResponsesStream(t *testing.T) {
+	s := newThinkingStripper(true, "openai-response")
+	var out strings.Builder
+	for _, chunk := range []string{
+		"event: response.created\ndata: {\"type\":\"response.created\",\"response\":{\"output\":[]}}",
+		"event: response.output_item.added\ndata: {\"type\":\"response.output_item.added\",\"output_index\":0,\"item\":{\"id\":\"rs_1\",\"type\":\"reasoning\"}}",
+		"event: response.reasoning_summary_text.delta\ndata: {\"type\":\"response.reasoning_summary_text.delta\",\"item_id\":\"rs_1\",\"output_index\":0,\"delta\":\"x\"}",
+		"event: response.output_item.done\ndata: {\"type\":\"response.output_item.done\",\"output_index\":0,\"item\":{\"id\":\"rs_1\",\"type\":\"reasoning\"}}",
+		"event: response.output_item.added\ndata: {\"type\":\"response.output_item.added\",\"output_index\":1,\"item\":{\"id\":\"msg_1\",\"type\":\"message\"}}",
+		"event: response.output_text.delta\ndata: {\"type\":\"response.output_text.delta\",\"item_id\":\"msg_1\",\"output_index\":1,\"delta\":\"hi\"}",
+		"event: response.completed\ndata: {\"type\":\"response.completed\",\"response\":{\"output\":[{\"id\":\"rs_1\",\"type\":\"reasoning\"},{\"id\":\"msg_1\",\"type\":\"message\"}],\"usage\":{\"output_tokens\":5}}}",
+	} {
+		if kept := s.Chunk([]byte(chunk)); len(kept) > 0 {
+			out.Write(kept)
+			out.WriteString("\n")
+		}
+	}
+	got := out.String()
+	if strings.Contains(got, "reasoning") || strings.Contains(got, "rs_1") {
+		t.Fatalf("reasoning events kept:\n%s", got)
+	}
+	if !strings.Contains(got, `"output_index":0,"item":{"id":"msg_1"`) || !strings.Contains(got, `"item_id":"msg_1","output_index":0`) {
+		t.Fatalf("message item not renumbered to output_index 0:\n%s", got)
+	}
+	if !strings.Contains(got, `"output_tokens":5`) {
+		t.Fatalf("completed event dropped:\n%s", got)
+	}
+}
+
+func TestThinkingStripper_GeminiCLIEnvelope(t *testing.T) {
+	s := newThinkingStripper(true, "gemini-cli")
+	out := s.Response([]byte(`{"response":{"candidates":[{"content":{"parts":[{"text":"t","thought":true},{"thoughtSignature":"sig"},{"text":"answer"}]}}],"usageMetadata":{"thoughtsTokenCount":4}}}`))
+	parts := gjson.GetBytes(out, "response.candidates.0.content.parts").Array()
+	if len(parts) != 1 || parts[0].Get("text").String() != "answer" {
+		t.Fatalf("parts = %s, want only the answer", gjson.GetBytes(out, "response.candidates.0.content.parts").Raw)
+	}
+	if got := gjson.GetBytes(out, "response.usageMetadata.thoughtsTokenCount").Int(); got != 4 {
+		t.Fatalf("thoughtsTokenCount = %d, want 4", got)
+	}
+}
+
+func TestStripThinkingEnabled_HeaderOverridesConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	withHeader := func(value string) context.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		if value != "" {
+			c.Request.Header.Set(stripThinkingHeader, value)
+		}
+		return context.WithValue(context.Background(), "gin", c)
+	}
+
+	off := NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, nil)
+	on := NewBaseAPIHandlers(&sdkconfig.SDKConfig{StripThinking: true}, nil)
+	if off.stripThinkingEnabled(withHeader("")) {
+		t.Fatal("stripping enabled without config or header")
+	}
+	if !off.stripThinkingEnabled(withHeader("true")) {
+		t.Fatal("X-Strip-Thinking: true ignored")
+	}
+	if on.stripThinkingEnabled(withHeader("false")) {
+		t.Fatal("X-Strip-Thinking: false did not override config")
+	}
+	if !on.stripThinkingEnabled(withHeader("")) {
+		t.Fatal("config strip-thinking ignored")
+	}
+}
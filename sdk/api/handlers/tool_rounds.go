@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+// MaxToolRounds returns the configured tool round limit. Returning 0 disables the check.
+func MaxToolRounds(cfg *config.SDKConfig) int {
+	if cfg == nil || cfg.MaxToolRounds <= 0 {
+		return 0
+	}
+	return cfg.MaxToolRounds
+}
+
+// ToolRoundsWarnOnly reports whether exceeding MaxToolRounds should only be logged.
+func ToolRoundsWarnOnly(cfg *config.SDKConfig) bool {
+	return cfg != nil && strings.EqualFold(strings.TrimSpace(cfg.ToolRoundsAction), "warn")
+}
+
+// CountToolRounds counts tool-result turns in a request payload of the given handler format.
+//
+// A round is one turn that feeds tool output back to the model:
+//   - openai: a run of consecutive "tool"/"function" messages
+//   - openai-response: a run of consecutive function_call_output input items
+//   - claude: a user message carrying at least one tool_result block
+//   - gemini, gemini-cli: a content entry carrying at least one functionResponse part
+func CountToolRounds(handlerType string, rawJSON []byte) int {
+	if len(rawJSON) == 0 || !gjson.ValidBytes(rawJSON) {
+		return 0
+	}
+	root := gjson.ParseBytes(rawJSON)
+	switch strings.ToLower(strings.TrimSpace(handlerType)) {
+	case "openai":
+		return countConsecutiveRuns(root.Get("messages"), func(item gjson.Result) bool {
+			role := item.Get("role").String()
+			return role == "tool" || role == "function"
+		})
+	case "openai-response":
+		return countConsecutiveRuns(root.Get("input"), func(item gjson.Result) bool {
+			itemType := item.Get("type").String()
+			return itemType == "function_call_output" || itemType == "custom_tool_call_output"
+		})
+	case "claude":
+		return countMatchingItems(root.Get("messages"), func(item gjson.Result) bool {
+			return hasArrayItem(item.Get("content"), func(block gjson.Result) bool {
+				return block.Get("type").String() == "tool_result"
+			})
+		})
+	case "gemini":
+		return countGeminiToolRounds(root.Get("contents"))
+	case "gemini-cli":
+		return countGeminiToolRounds(root.Get("request.contents"))
+	default:
+		return 0
+	}
+}
+
+func countGeminiToolRounds(contents gjson.Result) int {
+	return countMatchingItems(contents, func(item gjson.Result) bool {
+		return hasArrayItem(item.Get("parts"), func(part gjson.Result) bool {
+			return part.Get("functionResponse").Exists()
+		})
+	})
+}
+
+func countConsecutiveRuns(items gjson.Result, match func(gjson.Result) bool) int {
+	if !items.IsArray() {
+		return 0
+	}
+	count := 0
+	inRun := false
+	for _, item := range items.Array() {
+		if match(item) {
+			if !inRun {
+				count++
+			}
+			inRun = true
+			continue
+		}
+		inRun = false
+	}
+	return count
+}
+
+func countMatchingItems(items gjson.Result, match func(gjson.Result) bool) int {
+	if !items.IsArray() {
+		return 0
+	}
+	count := 0
+	for _, item := range items.Array() {
+		if match(item) {
+			count++
+		}
+	}
+	return count
+}
+
+func hasArrayItem(items gjson.Result, match func(gjson.Result) bool) bool {
+	if !items.IsArray() {
+		return false
+	}
+	for _, item := range items.Array() {
+		if match(item) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkToolRounds enforces the configured tool round limit for an incoming request.
+// It returns a 400 error message when the limit is exceeded in reject mode.
+func (h *BaseAPIHandler) checkToolRounds(handlerType, modelName string, rawJSON []byte) *interfaces.ErrorMessage {
+	limit := MaxToolRounds(h.Cfg)
+	if limit <= 0 {
+		return nil
+	}
+	rounds := CountToolRounds(handlerType, rawJSON)
+	if rounds <= limit {
+		return nil
+	}
+	if ToolRoundsWarnOnly(h.Cfg) {
+		log.WithFields(log.Fields{
+			"handler": handlerType,
+			"model":   modelName,
+			"rounds":  rounds,
+			"limit":   limit,
+		}).Warn("conversation exceeds max tool rounds")
+		return nil
+	}
+	return &interfaces.ErrorMessage{
+		StatusCode: http.StatusBadRequest,
+		Error:      fmt.Errorf("conversation contains %d tool rounds, exceeding the limit of %d", rounds, limit),
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func TestCountToolRounds_OpenAI(t *testing.T) {
+	payload := []byte(`{"messages":[
+		{"role":"user","content":"hi"},
+		{"role":"assistant","tool_calls":[{"id":"a","type":"function","function":{"name":"f","arguments":"{}"}},{"id":"b","type":"function","function":{"name":"g","arguments":"{}"}}]},
+		{"role":"tool","tool_call_id":"a","content":"1"},
+		{"role":"tool","tool_call_id":"b","content":"2"},
+		{"role":"assistant","tool_calls":[{"id":"c","type":"function","function":{"name":"f","arguments":"{}"}}]},
+		{"role":"tool","tool_call_id":"c","content":"3"},
+		{"role":"assistant","content":"done"}
+	]}`)
+
+	if got := CountToolRounds("openai", payload); got != 2 {
+		t.Fatalf("CountToolRounds(openai) = %d, want %d", got, 2)
+	}
+}
+
+func TestCountToolRounds_Claude(t *testing.T) {
+	payload := []byte(`{"messages":[
+		{"role":"user","content":"hi"},
+		{"role":"assistant","content":[{"type":"tool_use","id":"a","name":"f","input":{}}]},
+		{"role":"user","content":[{"type":"tool_result","tool_use_id":"a","content":"1"}]},
+		{"role":"assistant","content":[{"type":"tool_use","id":"b","name":"f","input":{}}]},
+		{"role":"user","content":[{"type":"tool_result","tool_use_id":"b","content":"2"},{"type":"text","text":"continue"}]},
+		{"role":"assistant","content":[{"type":"tool_use","id":"c","name":"f","input":{}}]},
+		{"role":"user","content":[{"type":"tool_result","tool_use_id":"c","content":"3"}]}
+	]}`)
+
+	if got := CountToolRounds("claude", payload); got != 3 {
+		t.Fatalf("CountToolRounds(claude) = %d, want %d", got, 3)
+	}
+}
+
+func TestCountToolRounds_Gemini(t *testing.T) {
+	payload := []byte(`{"request":{"contents":[
+		{"role":"user","parts":[{"text":"hi"}]},
+		{"role":"model","parts":[{"functionCall":{"name":"f","args":{}}}]},
+		{"role":"user","parts":[{"functionResponse":{"name":"f","response":{}}}]}
+	]}}`)
+
+	if got := CountToolRounds("gemini-cli", payload); got != 1 {
+		t.Fatalf("CountToolRounds(gemini-cli) = %d, want %d", got, 1)
+	}
+}
+
+func TestCheckToolRounds_RejectsOverLimit(t *testing.T) {
+	payload := []byte(`{"messages":[
+		{"role":"user","content":[{"type":"tool_result","tool_use_id":"a","content":"1"}]},
+		{"role":"user","content":[{"type":"tool_result","tool_use_id":"b","content":"2"}]},
+		{"role":"user","content":[{"type":"tool_result","tool_use_id":"c","content":"3"}]}
+	]}`)
+
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{MaxToolRounds: 2}, nil)
+	errMsg := handler.checkToolRounds("claude", "claude-sonnet-4-5", payload)
+	if errMsg == nil {
+		t.Fatal("expected tool round limit error, got nil")
+	}
+	if errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", errMsg.StatusCode, http.StatusBadRequest)
+	}
+
+	handler.Cfg.ToolRoundsAction = "warn"
+	if errMsg = handler.checkToolRounds("claude", "claude-sonnet-4-5", payload); errMsg != nil {
+		t.Fatalf("expected warn mode to allow request, got %v", errMsg.Error)
+	}
+
+	handler.Cfg.ToolRoundsAction = ""
+	handler.Cfg.MaxToolRounds = 3
+	if errMsg = handler.checkToolRounds("claude", "claude-sonnet-4-5", payload); errMsg != nil {
+		t.Fatalf("expected request within limit to pass, got %v", errMsg.Error)
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/transform"
+	log "github.com/sirupsen/logrus"
+)
+
+// transforms resolves the configured transform chain. Names without a registered
+// transform are skipped with a debug log; Builder.Build warns about them at startup.
+func (h *BaseAPIHandler) transforms() transform.Chain {
+	if h.Cfg == nil || len(h.Cfg.Transforms) == 0 {
+		return nil
+	}
+	chain, missing := transform.Resolve(h.Cfg.Transforms)
+	if len(missing) > 0 {
+		log.Debugf("skipping unregistered transforms: %v", missing)
+	}
+	return chain
+}
+
+// applyRequestTransforms runs the request hooks of chain and maps a rejection to 400.
+func applyRequestTransforms(ctx context.Context, chain transform.Chain, info transform.Info, rawJSON []byte) ([]byte, *interfaces.ErrorMessage) {
+	if len(chain) == 0 {
+		return rawJSON, nil
+	}
+	out, err := chain.ApplyRequest(ctx, info, rawJSON)
+	if err != nil {
+		return nil, &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: err}
+	}
+	return out, nil
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/transform"
+	"github.com/tidwall/sjson"
+)
+
+// echoExecutor returns the request payload it received as the response.
+type echoExecutor struct{}
+
+func (e *echoExecutor) Identifier() string { return "codex" }
+
+func (e *echoExecutor) Execute(_ context.Context, _ *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{Payload: req.Payload}, nil
+}
+
+func (e *echoExecutor) ExecuteStream(_ context.Context, _ *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (*coreexecutor.StreamResult, error) {
+	ch := make(chan coreexecutor.StreamChunk, 2)
+	ch <- coreexecutor.StreamChunk{Payload: []byte("a")}
+	ch <- coreexecutor.StreamChunk{Payload: []byte("b")}
+	close(ch)
+	return &coreexecutor.StreamResult{Chunks: ch}, nil
+}
+
+func (e *echoExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *echoExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, &coreauth.Error{Code: "not_implemented", Message: "CountTokens not implemented"}
+}
+
+func (e *echoExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "HttpRequest not implemented", HTTPStatus: http.StatusNotImplemented}
+}
+
+func newTransformTestHandler(t *testing.T, names ...string) *BaseAPIHandler {
+	t.Helper()
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(&echoExecutor{})
+	auth := &coreauth.Auth{ID: "transform-auth", Provider: "codex", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("manager.Register: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "transform-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	transform.Register("strip-user", transform.Transform{
+		Request: func(_ context.Context, _ transform.Info, body []byte) ([]byte, error) {
+			return sjson.DeleteBytes(body, "user")
+		},
+	})
+	transform.Register("disclaimer", transform.Transform{
+		Response: func(_ context.Context, _ transform.Info, body []byte) []byte {
+			return append(body, []byte(" [disclaimer]")...)
+		},
+	})
+	transform.Register("footer", transform.Transform{
+		Finalize: func(_ context.Context, info transform.Info) []byte {
+			if !info.Stream {
+				t.Error("finalize called for a non-streaming response")
+			}
+			return []byte("[footer]")
+		},
+	})
+	t.Cleanup(func() {
+		transform.Unregister("strip-user")
+		transform.Unregister("disclaimer")
+		transform.Unregister("footer")
+	})
+
+	return NewBaseAPIHandlers(&sdkconfig.SDKConfig{Transforms: names}, manager)
+}
+
+func TestExecuteWithAuthManager_AppliesTransforms(t *testing.T) {
+	handler := newTransformTestHandler(t, "strip-user", "disclaimer", "unregistered")
+
+	resp, _, errMsg := handler.ExecuteWithAuthManager(context.Background(), "openai", "transform-model", []byte(`{"model":"transform-model","user":"alice"}`), "")
+	if errMsg != nil {
+		t.Fatalf("unexpected error: %+v", errMsg)
+	}
+	if want := `{"model":"transform-model"} [disclaimer]`; string(resp) != want {
+		t.Fatalf("response = %q, want %q", resp, want)
+	}
+}
+
+func TestExecuteStreamWithAuthManager_AppliesResponseTransformPerChunk(t *testing.T) {
+	handler := newTransformTestHandler(t, "disclaimer")
+
+	dataChan, _, errChan := handler.ExecuteStreamWithAuthManager(context.Background(), "openai", "transform-model", []byte(`{"model":"transform-model"}`), "")
+	var got bytes.Buffer
+	for chunk := range dataChan {
+		got.Write(chunk)
+	}
+	for msg := range errChan {
+		if msg != nil {
+			t.Fatalf("unexpected error: %+v", msg)
+		}
+	}
+	if want := "a [disclaimer]b [disclaimer]"; got.String() != want {
+		t.Fatalf("stream = %q, want %q", got.String(), want)
+	}
+}
+
+func TestExecuteStreamWithAuthManager_AppendsFinalizeChunkAtStreamEnd(t *testing.T) {
+	handler := newTransformTestHandler(t, "footer", "disclaimer")
+
+	dataChan, _, errChan := handler.ExecuteStreamWithAuthManager(context.Background(), "openai", "transform-model", []byte(`{"model":"transform-model"}`), "")
+	var chunks []string
+	for chunk := range dataChan {
+		chunks = append(chunks, string(chunk))
+	}
+	for msg := range errChan {
+		if msg != nil {
+			t.Fatalf("unexpected error: %+v", msg)
+		}
+	}
+	want := []string{"a [disclaimer]", "b [disclaimer]", "[footer]"}
+	if strings.Join(chunks, "|") != strings.Join(want, "|") {
+		t.Fatalf("chunks = %q, want %q", chunks, want)
+	}
+}
+
+func TestExecuteWithAuthManager_SkipsFinalizeForNonStream(t *testing.T) {
+	handler := newTransformTestHandler(t, "footer")
+
+	resp, _, errMsg := handler.ExecuteWithAuthManager(context.Background(), "openai", "transform-model", []byte(`{"model":"transform-model"}`), "")
+	if errMsg != nil {
+		t.Fatalf("unexpected error: %+v", errMsg)
+	}
+	if want := `{"model":"transform-model"}`; string(resp) != want {
+		t.Fatalf("response = %q, want %q", resp, want)
+	}
+}
+
+func TestExecuteWithAuthManager_NoTransformsConfigured(t *testing.T) {
+	handler := newTransformTestHandler(t)
+
+	resp, _, errMsg := handler.ExecuteWithAuthManager(context.Background(), "openai", "transform-model", []byte(`{"model":"transform-model","user":"alice"}`), "")
+	if errMsg != nil {
+		t.Fatalf("unexpected error: %+v", errMsg)
+	}
+	if want := `{"model":"transform-model","user":"alice"}`; string(resp) != want {
+		t.Fatalf("response = %q, want %q", resp, want)
+	}
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tokenize"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+// UsageProgressTokens returns the estimated output token interval between provisional usage
+// events on streams. 0 disables them.
+func UsageProgressTokens(cfg *config.SDKConfig) int64 {
+	if cfg == nil || cfg.Streaming.UsageProgressTokens <= 0 {
+		return 0
+	}
+	return cfg.Streaming.UsageProgressTokens
+}
+
+// usageProgress emits provisional usage events while a stream is generating, for upstreams
+// that only report usage in the terminal chunk. Counts are local estimates of the output
+// streamed so far and are marked "provisional"; the upstream's final usage chunk remains
+// authoritative. A usageProgress is bound to one response.
+type usageProgress struct {
+	handlerType string
+	interval    int64
+	estimated   int64
+	reported    int64
+	// pending holds Claude SSE text after the last event boundary ("\n\n").
+	pending []byte
+}
+
+// newUsageProgress returns a tracker for handlerType, or nil when progress events are
+// disabled or the client format has no place for them (non-SSE Gemini responses).
+func newUsageProgress(interval int64, handlerType, alt string) *usageProgress {
+	if interval <= 0 {
+		return nil
+	}
+	switch handlerType {
+	case "openai", "openai-response", "claude":
+	case "gemini", "gemini-cli":
+		if alt != "" {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return &usageProgress{handlerType: handlerType, interval: interval}
+}
+
+// Chunk accounts for the output text of one client-format stream chunk and returns a
+// provisional usage event to send after it, or nil when the next interval is not reached yet.
+func (p *usageProgress) Chunk(chunk []byte) []byte {
+	if p == nil || len(chunk) == 0 {
+		return nil
+	}
+	if p.handlerType == "claude" {
+		// Claude chunks are raw SSE text that can end inside an event (passthrough forwards
+		// upstream lines as they arrive). Only complete events are counted, and the event is
+		// held back until a chunk ends on an event boundary so it never splits another event.
+		events, atBoundary := p.completeEvents(chunk)
+		for _, data := range streamChunkPayloads(events) {
+			p.estimated += tokenize.Estimate(p.outputText(data))
+		}
+		if !atBoundary {
+			return nil
+		}
+	} else {
+		for _, data := range streamChunkPayloads(chunk) {
+			p.estimated += tokenize.Estimate(p.outputText(data))
+		}
+	}
+	if p.estimated-p.reported < p.interval {
+		return nil
+	}
+	p.reported = p.estimated
+	return p.event(p.estimated)
+}
+
+// completeEvents appends chunk to the pending SSE text and returns the events completed so
+// far, keeping the remainder pending. atBoundary reports whether nothing is left pending.
+func (p *usageProgress) completeEvents(chunk []byte) (events []byte, atBoundary bool) {
+	p.pending = append(p.pending, chunk...)
+	p.pending = bytes.ReplaceAll(p.pending, []byte("\r\n"), []byte("\n"))
+	end := bytes.LastIndex(p.pending, []byte("\n\n"))
+	if end < 0 {
+		return nil, false
+	}
+	events = bytes.Clone(p.pending[:end+2])
+	p.pending = append(p.pending[:0], p.pending[end+2:]...)
+	return events, len(p.pending) == 0
+}
+
+// streamChunkPayloads returns the JSON payloads of a chunk, which is either a bare JSON
+// document or one or more SSE events.
+func streamChunkPayloads(chunk []byte) []string {
+	trimmed := bytes.TrimSpace(chunk)
+	if gjson.ValidBytes(trimmed) {
+		return []string{string(trimmed)}
+	}
+	var payloads []string
+	for _, line := range strings.Split(strings.ReplaceAll(string(chunk), "\r\n", "\n"), "\n") {
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		if data := strings.TrimSpace(strings.TrimPrefix(line, "data:")); gjson.Valid(data) {
+			payloads = append(payloads, data)
+		}
+	}
+	return payloads
+}
+
+// outputText returns the generated text (content, reasoning and tool arguments) carried by
+// one stream payload.
+func (p *usageProgress) outputText(data string) string {
+	var b strings.Builder
+	add := func(values ...gjson.Result) {
+		for _, value := range values {
+			if value.Type == gjson.String {
+				b.WriteString(value.String())
+				b.WriteByte(' ')
+			}
+		}
+	}
+	switch p.handlerType {
+	case "openai":
+		gjson.Get(data, "choices").ForEach(func(_, choice gjson.Result) bool {
+			delta := choice.Get("delta")
+			add(delta.Get("content"), delta.Get("reasoning_content"))
+			delta.Get("tool_calls").ForEach(func(_, call gjson.Result) bool {
+				add(call.Get("function.arguments"))
+				return true
+			})
+			return true
+		})
+	case "openai-response":
+		if strings.HasSuffix(gjson.Get(data, "type").String(), ".delta") {
+			add(gjson.Get(data, "delta"))
+		}
+	case "claude":
+		if gjson.Get(data, "type").String() == "content_block_delta" {
+			delta := gjson.Get(data, "delta")
+			add(delta.Get("text"), delta.Get("thinking"), delta.Get("partial_json"))
+		}
+	case "gemini", "gemini-cli":
+		candidates := gjson.Get(data, "candidates")
+		if !candidates.Exists() {
+			candidates = gjson.Get(data, "response.candidates")
+		}
+		candidates.ForEach(func(_, candidate gjson.Result) bool {
+			candidate.Get("content.parts").ForEach(func(_, part gjson.Result) bool {
+				add(part.Get("text"))
+				if args := part.Get("functionCall.args"); args.Exists() {
+					b.WriteString(args.Raw)
+					b.WriteByte(' ')
+				}
+				return true
+			})
+			return true
+		})
+	}
+	return b.String()
+}
+
+// event formats a provisional usage event in the client's wire format.
+func (p *usageProgress) event(outputTokens int64) []byte {
+	switch p.handlerType {
+	case "openai":
+		return fmt.Appendf(nil, `{"object":"chat.completion.chunk","choices":[],"usage":{"completion_tokens":%d},"provisional":true}`, outputTokens)
+	case "openai-response":
+		return fmt.Appendf(nil, "event: response.usage_progress\ndata: {\"type\":\"response.usage_progress\",\"provisional\":true,\"usage\":{\"output_tokens\":%d}}\n", outputTokens)
+	case "claude":
+		return fmt.Appendf(nil, "event: usage_progress\ndata: {\"type\":\"usage_progress\",\"provisional\":true,\"usage\":{\"output_tokens\":%d}}\n\n", outputTokens)
+	case "gemini":
+		return fmt.Appendf(nil, `{"usageMetadata":{"candidatesTokenCount":%d},"provisional":true}`, outputTokens)
+	case "gemini-cli":
+		return fmt.Appendf(nil, `{"response":{"usageMetadata":{"candidatesTokenCount":%d}},"provisional":true}`, outputTokens)
+	default:
+		return nil
+	}
+}
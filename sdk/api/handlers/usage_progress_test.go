@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+// finalUsageStreamExecutor streams OpenAI chunks and reports usage only in the last one.
+type finalUsageStreamExecutor struct{}
+
+func (e *finalUsageStreamExecutor) Identifier() string { return "codex" }
+
+func (e *finalUsageStreamExecutor) Execute(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, &coreauth.Error{Code: "not_implemented", Message: "Execute not implemented"}
+}
+
+func (e *finalUsageStreamExecutor) ExecuteStream(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (*coreexecutor.StreamResult, error) {
+	ch := make(chan coreexecutor.StreamChunk, 5)
+	for range 4 {
+		ch <- coreexecutor.StreamChunk{Payload: []byte(`{"object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"one two three four five "}}]}`)}
+	}
+	ch <- coreexecutor.StreamChunk{Payload: []byte(`{"object":"chat.completion.chunk","choices":[{"index":0,"delta":{},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":21,"total_tokens":24}}`)}
+	close(ch)
+	return &coreexecutor.StreamResult{Chunks: ch}, nil
+}
+
+func (e *finalUsageStreamExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *finalUsageStreamExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, &coreauth.Error{Code: "not_implemented", Message: "CountTokens not implemented"}
+}
+
+func (e *finalUsageStreamExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "HttpRequest not implemented", HTTPStatus: http.StatusNotImplemented}
+}
+
+func TestExecuteStreamWithAuthManager_ProvisionalUsagePrecedesFinalUsage(t *testing.T) {
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(&finalUsageStreamExecutor{})
+	auth := &coreauth.Auth{ID: "usage-progress-auth", Provider: "codex", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("manager.Register: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "usage-progress-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	cfg := &sdkconfig.SDKConfig{Streaming: sdkconfig.StreamingConfig{UsageProgressTokens: 8}}
+	h := NewBaseAPIHandlers(cfg, manager)
+	dataChan, _, errChan := h.ExecuteStreamWithAuthManager(context.Background(), "openai", "usage-progress-model", []byte(`{"model":"usage-progress-model"}`), "")
+
+	var provisional []int64
+	finalIndex, index := -1, 0
+	for chunk := range dataChan {
+		switch {
+		case gjson.GetBytes(chunk, "provisional").Bool():
+			if finalIndex >= 0 {
+				t.Fatalf("provisional usage sent after the final usage: %s", chunk)
+			}
+			provisional = append(provisional, gjson.GetBytes(chunk, "usage.completion_tokens").Int())
+		case gjson.GetBytes(chunk, "usage.total_tokens").Exists():
+			finalIndex = index
+			if got := gjson.GetBytes(chunk, "usage.completion_tokens").Int(); got != 21 {
+				t.Fatalf("final completion_tokens = %d, want the upstream's 21", got)
+			}
+		}
+		index++
+	}
+	for msg := range errChan {
+		if msg != nil {
+			t.Fatalf("unexpected error: %v", msg.Error)
+		}
+	}
+
+	if finalIndex < 0 {
+		t.Fatalf("final usage chunk was not forwarded")
+	}
+	if len(provisional) != 2 || provisional[0] != 12 || provisional[1] != 24 {
+		t.Fatalf("provisional completion_tokens = %v, want [12 24]", provisional)
+	}
+}
+
+func TestUsageProgress_DisabledOrUnsupported(t *testing.T) {
+	if newUsageProgress(0, "openai", "") != nil {
+		t.Fatalf("expected no tracker when disabled")
+	}
+	if newUsageProgress(8, "gemini", "json") != nil {
+		t.Fatalf("expected no tracker for non-SSE Gemini responses")
+	}
+
+	p := newUsageProgress(2, "claude", "")
+	event := p.Chunk([]byte("event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"hello there\"}}\n\n"))
+	if got := string(event); got != "event: usage_progress\ndata: {\"type\":\"usage_progress\",\"provisional\":true,\"usage\":{\"output_tokens\":4}}\n\n" {
+		t.Fatalf("claude event = %q", got)
+	}
+}
+
+func TestUsageProgress_ClaudeEventSplitAcrossChunks(t *testing.T) {
+	p := newUsageProgress(2, "claude", "")
+
+	// The upstream line arrives without the blank line that ends its event.
+	if event := p.Chunk([]byte("event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"hello there\"}}\n")); event != nil {
+		t.Fatalf("provisional event emitted inside an unfinished event: %q", event)
+	}
+	event := p.Chunk([]byte("\n"))
+	if got := string(event); got != "event: usage_progress\ndata: {\"type\":\"usage_progress\",\"provisional\":true,\"usage\":{\"output_tokens\":4}}\n\n" {
+		t.Fatalf("claude event at boundary = %q", got)
+	}
+
+	// A chunk that completes one event and starts the next is not a boundary either.
+	p = newUsageProgress(2, "claude", "")
+	if event := p.Chunk([]byte("data: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"hello there\"}}\n\nevent: content_block_delta\n")); event != nil {
+		t.Fatalf("provisional event emitted mid-event: %q", event)
+	}
+	if event := p.Chunk([]byte("data: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"more\"}}\n\n")); event == nil {
+		t.Fatal("expected the held provisional event once the stream reached a boundary")
+	}
+}
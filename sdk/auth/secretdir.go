@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher/synthesizer"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// secretDirSyncDelay coalesces bursts of events, such as the symlink swap Kubernetes
+// performs when a mounted secret is updated, into a single rescan.
+const secretDirSyncDelay = 200 * time.Millisecond
+
+// secretAuthIDPrefix namespaces secret credential IDs apart from auth-dir file IDs.
+const secretAuthIDPrefix = "secret:"
+
+// IsSecretDirAuthID reports whether id names a credential loaded from the secret directory.
+func IsSecretDirAuthID(id string) bool {
+	return strings.HasPrefix(id, secretAuthIDPrefix)
+}
+
+// SecretDirLoader serves credentials from a directory of mounted secret files, such as a
+// Kubernetes secret volume, where each top-level *.json file is one credential. The files
+// are never written: loaded auths are marked runtime_only so refreshed state is kept in
+// memory only.
+type SecretDirLoader struct {
+	dir string
+	cfg *config.Config
+
+	mu    sync.Mutex
+	files map[string]secretFile // file name -> last loaded state
+}
+
+// secretFile records what was loaded from one secret file.
+type secretFile struct {
+	hash string   // content hash
+	ids  []string // IDs of the auths built from the file
+}
+
+// NewSecretDirLoader creates a loader for the given secret mount directory. cfg supplies the
+// global OAuth model exclusions applied to the loaded credentials and may be nil.
+func NewSecretDirLoader(dir string, cfg *config.Config) *SecretDirLoader {
+	return &SecretDirLoader{dir: strings.TrimSpace(dir), cfg: cfg, files: make(map[string]secretFile)}
+}
+
+// Dir returns the watched secret directory.
+func (l *SecretDirLoader) Dir() string {
+	return l.dir
+}
+
+// Watch loads the current credentials and keeps them in sync until ctx is cancelled.
+// upsert receives new and changed credentials; remove receives the IDs of credentials
+// whose files disappeared.
+func (l *SecretDirLoader) Watch(ctx context.Context, upsert func(*cliproxyauth.Auth), remove func(id string)) error {
+	if l.dir == "" {
+		return fmt.Errorf("auth secretdir: directory not configured")
+	}
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("auth secretdir: create watcher failed: %w", err)
+	}
+	if err = fsWatcher.Add(l.dir); err != nil {
+		_ = fsWatcher.Close()
+		return fmt.Errorf("auth secretdir: watch %s failed: %w", l.dir, err)
+	}
+	l.Sync(upsert, remove)
+
+	go func() {
+		defer func() { _ = fsWatcher.Close() }()
+		timer := time.NewTimer(secretDirSyncDelay)
+		timer.Stop()
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-fsWatcher.Events:
+				if !ok {
+					return
+				}
+				// Any event may be a secret swap behind a symlink, so rescan the whole mount.
+				timer.Reset(secretDirSyncDelay)
+			case errWatch, ok := <-fsWatcher.Errors:
+				if !ok {
+					return
+				}
+				log.Errorf("auth secretdir: watcher error: %v", errWatch)
+			case <-timer.C:
+				l.Sync(upsert, remove)
+			}
+		}
+	}()
+	return nil
+}
+
+// Sync rescans the directory once, reporting credentials that were added, changed or removed
+// since the previous scan.
+func (l *SecretDirLoader) Sync(upsert func(*cliproxyauth.Auth), remove func(id string)) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		log.Errorf("auth secretdir: read %s failed: %v", l.dir, err)
+		return
+	}
+
+	l.mu.Lock()
+	seen := make(map[string]struct{}, len(entries))
+	var changed []*cliproxyauth.Auth
+	var removed []string
+	for _, entry := range entries {
+		name := entry.Name()
+		// Kubernetes keeps the real files in hidden "..data" directories behind symlinks.
+		if strings.HasPrefix(name, ".") || !strings.HasSuffix(strings.ToLower(name), ".json") {
+			continue
+		}
+		path := filepath.Join(l.dir, name)
+		data, errRead := os.ReadFile(path)
+		if errRead != nil || len(data) == 0 {
+			continue
+		}
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:])
+		if prev, ok := l.files[name]; ok && prev.hash == hash {
+			seen[name] = struct{}{}
+			continue
+		}
+		auths, errParse := secretAuthsFromJSON(l.cfg, path, name, data)
+		if errParse != nil {
+			log.Warnf("auth secretdir: skipping %s: %v", name, errParse)
+			continue
+		}
+		seen[name] = struct{}{}
+		ids := make([]string, 0, len(auths))
+		for _, auth := range auths {
+			ids = append(ids, auth.ID)
+		}
+		// A rewritten file can drop auths, e.g. a Gemini credential that lost a project.
+		removed = append(removed, missingIDs(l.files[name].ids, ids)...)
+		l.files[name] = secretFile{hash: hash, ids: ids}
+		changed = append(changed, auths...)
+	}
+	for name, file := range l.files {
+		if _, ok := seen[name]; !ok {
+			delete(l.files, name)
+			removed = append(removed, file.ids...)
+		}
+	}
+	l.mu.Unlock()
+
+	for _, auth := range changed {
+		log.Infof("auth secretdir: loaded %s", auth.ID)
+		if upsert != nil {
+			upsert(auth)
+		}
+	}
+	for _, id := range removed {
+		log.Infof("auth secretdir: removed %s", id)
+		if remove != nil {
+			remove(id)
+		}
+	}
+}
+
+// secretAuthsFromJSON builds read-only auth records from a mounted credential file through
+// the same path as auth-dir files, under IDs of their own so they cannot collide with them.
+func secretAuthsFromJSON(cfg *config.Config, path, name string, data []byte) ([]*cliproxyauth.Auth, error) {
+	auths, err := synthesizer.SynthesizeAuthFile(cfg, secretAuthIDPrefix+name, path, data, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for _, auth := range auths {
+		auth.FileName = name
+		auth.Attributes["runtime_only"] = "true"
+	}
+	return auths, nil
+}
+
+// missingIDs returns the IDs in prev that are not in next.
+func missingIDs(prev, next []string) []string {
+	var missing []string
+	for _, id := range prev {
+		found := false
+		for _, candidate := range next {
+			if candidate == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
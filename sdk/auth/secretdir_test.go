@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestSecretDirLoader_RegistersAndUnregistersOnFileChanges(t *testing.T) {
+	dir := t.TempDir()
+	// Hidden entries such as Kubernetes "..data" directories must be ignored.
+	if err := os.Mkdir(filepath.Join(dir, "..data"), 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "..data", "hidden.json"), []byte(`{"type":"codex"}`), 0o600); err != nil {
+		t.Fatalf("write hidden: %v", err)
+	}
+
+	upserts := make(chan *cliproxyauth.Auth, 4)
+	removes := make(chan string, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	loader := NewSecretDirLoader(dir, nil)
+	err := loader.Watch(ctx, func(auth *cliproxyauth.Auth) { upserts <- auth }, func(id string) { removes <- id })
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+
+	path := filepath.Join(dir, "codex-user.json")
+	if err = os.WriteFile(path, []byte(`{"type":"codex","email":"user@example.com"}`), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+	select {
+	case auth := <-upserts:
+		if auth.ID != "secret:codex-user.json" || auth.Provider != "codex" || auth.Label != "user@example.com" {
+			t.Fatalf("registered auth = %+v", auth)
+		}
+		if auth.Attributes["runtime_only"] != "true" {
+			t.Fatalf("secret auth must not be persisted, attributes = %v", auth.Attributes)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the secret to be registered")
+	}
+
+	if err = os.Remove(path); err != nil {
+		t.Fatalf("remove secret: %v", err)
+	}
+	select {
+	case id := <-removes:
+		if id != "secret:codex-user.json" {
+			t.Fatalf("removed id = %q, want secret:codex-user.json", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the secret to be unregistered")
+	}
+
+	select {
+	case auth := <-upserts:
+		t.Fatalf("unexpected registration of %s", auth.ID)
+	default:
+	}
+}
+
+func TestSecretDirLoader_BuildsAuthsLikeAuthDirFiles(t *testing.T) {
+	dir := t.TempDir()
+	secret := `{"type":"gemini","email":"user@example.com","project_id":"p1,p2","prefix":"team","proxy_url":"http://proxy:8080","priority":5,"group":"blue"}`
+	if err := os.WriteFile(filepath.Join(dir, "gemini-user.json"), []byte(secret), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+
+	got := make(map[string]*cliproxyauth.Auth)
+	var removed []string
+	loader := NewSecretDirLoader(dir, nil)
+	loader.Sync(func(auth *cliproxyauth.Auth) { got[auth.ID] = auth }, func(id string) { removed = append(removed, id) })
+
+	primary := got["secret:gemini-user.json"]
+	if primary == nil || len(got) != 3 {
+		t.Fatalf("loaded auths = %v, want the primary and two virtual auths", got)
+	}
+	if primary.Provider != "gemini-cli" || primary.Prefix != "team" || primary.ProxyURL != "http://proxy:8080" {
+		t.Fatalf("primary = %+v", primary)
+	}
+	if primary.Attributes["priority"] != "5" || primary.Attributes["group"] != "blue" || primary.Attributes["runtime_only"] != "true" {
+		t.Fatalf("primary attributes = %v", primary.Attributes)
+	}
+	for _, id := range []string{"secret:gemini-user.json::p1", "secret:gemini-user.json::p2"} {
+		if virtual := got[id]; virtual == nil || virtual.Provider != "gemini-cli" || virtual.Attributes["runtime_only"] != "true" {
+			t.Fatalf("virtual auth %s = %+v", id, virtual)
+		}
+	}
+
+	// Dropping a project removes its virtual auth.
+	secret = `{"type":"gemini","email":"user@example.com","project_id":"p1"}`
+	if err := os.WriteFile(filepath.Join(dir, "gemini-user.json"), []byte(secret), 0o600); err != nil {
+		t.Fatalf("rewrite secret: %v", err)
+	}
+	loader.Sync(func(auth *cliproxyauth.Auth) { got[auth.ID] = auth }, func(id string) { removed = append(removed, id) })
+	if len(removed) != 2 {
+		t.Fatalf("removed = %v, want both virtual auths", removed)
+	}
+}
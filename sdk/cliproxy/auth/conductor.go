@@ -73,6 +73,11 @@ func SetQuotaCooldownDisabled(disable bool) {
 	quotaCooldownDisabled.Store(disable)
 }
 
+// QuotaCooldownDisabled reports whether quota cooldown scheduling is globally disabled.
+func QuotaCooldownDisabled() bool {
+	return quotaCooldownDisabled.Load()
+}
+
 func quotaCooldownDisabledForAuth(auth *Auth) bool {
 	if auth != nil {
 		if override, ok := auth.DisableCoolingOverride(); ok {
@@ -151,11 +156,27 @@ type Manager struct {
 	// It is initialized in NewManager; never Load() before first Store().
 	runtimeConfig atomic.Value
 
+	// groupLimits enforces per-group concurrency and RPM limits from routing.groups.
+	groupLimits groupLimiter
+
+	// requestQueue holds requests waiting for group capacity (routing.queue).
+	requestQueue requestQueue
+
+	// disabledProviders holds the providers switched off at runtime (map[string]struct{}).
+	// Writers serialize on providerToggleMu and replace the map.
+	disabledProviders atomic.Value
+	providerToggleMu  sync.Mutex
+
 	// Optional HTTP RoundTripper provider injected by host.
 	rtProvider RoundTripperProvider
 
 	// Auto refresh state
 	refreshCancel context.CancelFunc
+	// refreshWG tracks the refresh loop and in-flight refresh goroutines.
+	refreshWG sync.WaitGroup
+
+	closeOnce sync.Once
+	closed    atomic.Bool
 }
 
 // NewManager constructs a manager with optional custom selector and hook.
@@ -441,6 +462,11 @@ func (m *Manager) Register(ctx context.Context, auth *Auth) (*Auth, error) {
 	}
 	auth.EnsureIndex()
 	m.mu.Lock()
+	var prevSchedule *authSchedule
+	if existing := m.auths[auth.ID]; existing != nil {
+		prevSchedule = existing.schedule
+	}
+	auth.parseSchedule(prevSchedule)
 	m.auths[auth.ID] = auth.Clone()
 	m.mu.Unlock()
 	m.rebuildAPIKeyModelAliasFromRuntimeConfig()
@@ -455,11 +481,16 @@ func (m *Manager) Update(ctx context.Context, auth *Auth) (*Auth, error) {
 		return nil, nil
 	}
 	m.mu.Lock()
-	if existing, ok := m.auths[auth.ID]; ok && existing != nil && !auth.indexAssigned && auth.Index == "" {
-		auth.Index = existing.Index
-		auth.indexAssigned = existing.indexAssigned
+	var prevSchedule *authSchedule
+	if existing, ok := m.auths[auth.ID]; ok && existing != nil {
+		if !auth.indexAssigned && auth.Index == "" {
+			auth.Index = existing.Index
+			auth.indexAssigned = existing.indexAssigned
+		}
+		prevSchedule = existing.schedule
 	}
 	auth.EnsureIndex()
+	auth.parseSchedule(prevSchedule)
 	m.auths[auth.ID] = auth.Clone()
 	m.mu.Unlock()
 	m.rebuildAPIKeyModelAliasFromRuntimeConfig()
@@ -468,6 +499,20 @@ func (m *Manager) Update(ctx context.Context, auth *Auth) (*Auth, error) {
 	return auth.Clone(), nil
 }
 
+// Remove drops an auth from the manager without touching the backing store. It is meant
+// for records whose credential no longer exists under that ID; retire the auth with
+// Update first so hooks release its runtime state.
+func (m *Manager) Remove(id string) bool {
+	m.mu.Lock()
+	_, ok := m.auths[id]
+	delete(m.auths, id)
+	m.mu.Unlock()
+	if ok {
+		m.rebuildAPIKeyModelAliasFromRuntimeConfig()
+	}
+	return ok
+}
+
 // Load resets manager state from the backing store.
 func (m *Manager) Load(ctx context.Context) error {
 	m.mu.Lock()
@@ -485,6 +530,7 @@ func (m *Manager) Load(ctx context.Context) error {
 			continue
 		}
 		auth.EnsureIndex()
+		auth.parseSchedule(nil)
 		m.auths[auth.ID] = auth.Clone()
 	}
 	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
@@ -597,7 +643,7 @@ func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req
 	tried := make(map[string]struct{})
 	var lastErr error
 	for {
-		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, opts, tried)
+		auth, executor, provider, release, errPick := m.pickNextQueued(ctx, providers, routeModel, opts, tried)
 		if errPick != nil {
 			if lastErr != nil {
 				return cliproxyexecutor.Response{}, lastErr
@@ -617,14 +663,23 @@ func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req
 		}
 		execReq := req
 		execReq.Model = rewriteModelForAuth(routeModel, auth)
+		execReq.Model = applyAuthModelRename(auth, execReq.Model)
 		execReq.Model = m.applyOAuthModelAlias(auth, execReq.Model)
 		execReq.Model = m.applyAPIKeyModelAlias(auth, execReq.Model)
-		resp, errExec := executor.Execute(execCtx, auth, execReq, opts)
+		timeout := m.requestTimeoutFor(provider)
+		callCtx, cancelCall := withRequestTimeout(execCtx, timeout)
+		resp, errExec := executor.Execute(callCtx, auth, execReq, opts)
+		release()
+		timedOut := callCtx.Err() != nil && execCtx.Err() == nil
+		cancelCall()
 		result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: errExec == nil}
 		if errExec != nil {
 			if errCtx := execCtx.Err(); errCtx != nil {
 				return cliproxyexecutor.Response{}, errCtx
 			}
+			if timedOut {
+				return cliproxyexecutor.Response{}, requestTimeoutError(provider, timeout)
+			}
 			result.Error = &Error{Message: errExec.Error()}
 			if se, ok := errors.AsType[cliproxyexecutor.StatusError](errExec); ok && se != nil {
 				result.Error.HTTPStatus = se.StatusCode()
@@ -653,7 +708,7 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 	tried := make(map[string]struct{})
 	var lastErr error
 	for {
-		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, opts, tried)
+		auth, executor, provider, release, errPick := m.pickNextQueued(ctx, providers, routeModel, opts, tried)
 		if errPick != nil {
 			if lastErr != nil {
 				return cliproxyexecutor.Response{}, lastErr
@@ -673,14 +728,23 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 		}
 		execReq := req
 		execReq.Model = rewriteModelForAuth(routeModel, auth)
+		execReq.Model = applyAuthModelRename(auth, execReq.Model)
 		execReq.Model = m.applyOAuthModelAlias(auth, execReq.Model)
 		execReq.Model = m.applyAPIKeyModelAlias(auth, execReq.Model)
-		resp, errExec := executor.CountTokens(execCtx, auth, execReq, opts)
+		timeout := m.requestTimeoutFor(provider)
+		callCtx, cancelCall := withRequestTimeout(execCtx, timeout)
+		resp, errExec := executor.CountTokens(callCtx, auth, execReq, opts)
+		release()
+		timedOut := callCtx.Err() != nil && execCtx.Err() == nil
+		cancelCall()
 		result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: errExec == nil}
 		if errExec != nil {
 			if errCtx := execCtx.Err(); errCtx != nil {
 				return cliproxyexecutor.Response{}, errCtx
 			}
+			if timedOut {
+				return cliproxyexecutor.Response{}, requestTimeoutError(provider, timeout)
+			}
 			result.Error = &Error{Message: errExec.Error()}
 			if se, ok := errors.AsType[cliproxyexecutor.StatusError](errExec); ok && se != nil {
 				result.Error.HTTPStatus = se.StatusCode()
@@ -709,7 +773,7 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 	tried := make(map[string]struct{})
 	var lastErr error
 	for {
-		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, opts, tried)
+		auth, executor, provider, release, errPick := m.pickNextQueued(ctx, providers, routeModel, opts, tried)
 		if errPick != nil {
 			if lastErr != nil {
 				return nil, lastErr
@@ -729,10 +793,12 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 		}
 		execReq := req
 		execReq.Model = rewriteModelForAuth(routeModel, auth)
+		execReq.Model = applyAuthModelRename(auth, execReq.Model)
 		execReq.Model = m.applyOAuthModelAlias(auth, execReq.Model)
 		execReq.Model = m.applyAPIKeyModelAlias(auth, execReq.Model)
 		streamResult, errStream := executor.ExecuteStream(execCtx, auth, execReq, opts)
 		if errStream != nil {
+			release()
 			if errCtx := execCtx.Err(); errCtx != nil {
 				return nil, errCtx
 			}
@@ -752,6 +818,7 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 		out := make(chan cliproxyexecutor.StreamChunk)
 		go func(streamCtx context.Context, streamAuth *Auth, streamProvider string, streamChunks <-chan cliproxyexecutor.StreamChunk) {
 			defer close(out)
+			defer release()
 			var failed bool
 			forward := true
 			for chunk := range streamChunks {
@@ -1105,6 +1172,46 @@ func (m *Manager) normalizeProviders(providers []string) []string {
 	return result
 }
 
+// requestTimeoutFor returns the limit for one non-streaming upstream attempt against
+// provider, or 0 when the attempt is unbounded.
+func (m *Manager) requestTimeoutFor(provider string) time.Duration {
+	if m == nil {
+		return 0
+	}
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil {
+		return 0
+	}
+	seconds := cfg.RequestTimeout
+	for key, value := range cfg.ProviderRequestTimeouts {
+		if strings.EqualFold(strings.TrimSpace(key), provider) {
+			seconds = value
+			break
+		}
+	}
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// withRequestTimeout bounds ctx by timeout when it is positive.
+func withRequestTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// requestTimeoutError reports an upstream attempt cut off by request-timeout.
+func requestTimeoutError(provider string, timeout time.Duration) *Error {
+	return &Error{
+		Code:       "request_timeout",
+		Message:    "upstream " + provider + " did not complete the request within " + timeout.String(),
+		HTTPStatus: http.StatusGatewayTimeout,
+	}
+}
+
 func (m *Manager) retrySettings() (int, time.Duration) {
 	if m == nil {
 		return 0, 0
@@ -1325,6 +1432,60 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 	m.hook.OnResult(ctx, result)
 }
 
+// ClearQuotaCooldowns lifts the quota cooldowns currently scheduled on auths that follow the
+// global cooldown switch, so they are selectable immediately. It is used when quota cooldown
+// is disabled at runtime and returns the number of auths changed.
+func (m *Manager) ClearQuotaCooldowns(ctx context.Context) int {
+	type cooledModel struct{ authID, model string }
+	var resumed []cooledModel
+	var changed []*Auth
+	now := time.Now()
+
+	m.mu.Lock()
+	for _, auth := range m.auths {
+		if auth == nil {
+			continue
+		}
+		if override, ok := auth.DisableCoolingOverride(); ok && !override {
+			continue
+		}
+		touched := false
+		for model, state := range auth.ModelStates {
+			if state == nil || !state.Quota.Exceeded {
+				continue
+			}
+			resetModelState(state, now)
+			resumed = append(resumed, cooledModel{authID: auth.ID, model: model})
+			touched = true
+		}
+		if touched {
+			updateAggregatedAvailability(auth, now)
+			if !hasModelError(auth, now) {
+				auth.LastError = nil
+				auth.StatusMessage = ""
+				auth.Status = StatusActive
+			}
+		} else if auth.Quota.Exceeded {
+			clearAuthStateOnSuccess(auth, now)
+			touched = true
+		}
+		if touched {
+			auth.UpdatedAt = now
+			changed = append(changed, auth.Clone())
+		}
+	}
+	m.mu.Unlock()
+
+	for _, ref := range resumed {
+		registry.GetGlobalRegistry().ClearModelQuotaExceeded(ref.authID, ref.model)
+		registry.GetGlobalRegistry().ResumeClientModel(ref.authID, ref.model)
+	}
+	for _, auth := range changed {
+		_ = m.persist(ctx, auth)
+	}
+	return len(changed)
+}
+
 func ensureModelState(auth *Auth, model string) *ModelState {
 	if auth == nil || model == "" {
 		return nil
@@ -1661,6 +1822,22 @@ func (m *Manager) CloseExecutionSession(sessionID string) {
 	}
 }
 
+// modelPrefixGroupLocked returns the credential prefix named by model, e.g. "teamA" for
+// "teamA/gemini-2.5-pro", when at least one registered auth uses it; otherwise "".
+// Requests for a prefixed model are served only by auths of that group. Callers must hold m.mu.
+func (m *Manager) modelPrefixGroupLocked(model string) string {
+	prefix, _, ok := strings.Cut(model, "/")
+	if !ok || prefix == "" {
+		return ""
+	}
+	for _, auth := range m.auths {
+		if auth != nil && strings.TrimSpace(auth.Prefix) == prefix {
+			return prefix
+		}
+	}
+	return ""
+}
+
 func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, error) {
 	pinnedAuthID := pinnedAuthIDFromMetadata(opts.Metadata)
 
@@ -1679,11 +1856,15 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cli
 			modelKey = strings.TrimSpace(parsed.ModelName)
 		}
 	}
+	prefixGroup := m.modelPrefixGroupLocked(modelKey)
 	registryRef := registry.GetGlobalRegistry()
 	for _, candidate := range m.auths {
 		if candidate.Provider != provider || candidate.Disabled {
 			continue
 		}
+		if prefixGroup != "" && strings.TrimSpace(candidate.Prefix) != prefixGroup {
+			continue
+		}
 		if pinnedAuthID != "" && candidate.ID != pinnedAuthID {
 			continue
 		}
@@ -1721,19 +1902,27 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cli
 	return authCopy, executor, nil
 }
 
-func (m *Manager) pickNextMixed(ctx context.Context, providers []string, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, string, error) {
+func (m *Manager) pickNextMixed(ctx context.Context, providers []string, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, string, func(), error) {
 	pinnedAuthID := pinnedAuthIDFromMetadata(opts.Metadata)
 
 	providerSet := make(map[string]struct{}, len(providers))
+	var disabled []string
 	for _, provider := range providers {
 		p := strings.TrimSpace(strings.ToLower(provider))
 		if p == "" {
 			continue
 		}
+		if !m.ProviderEnabled(p) {
+			disabled = append(disabled, p)
+			continue
+		}
 		providerSet[p] = struct{}{}
 	}
 	if len(providerSet) == 0 {
-		return nil, nil, "", &Error{Code: "provider_not_found", Message: "no provider supplied"}
+		if len(disabled) > 0 {
+			return nil, nil, "", nil, errProvidersDisabled(disabled)
+		}
+		return nil, nil, "", nil, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
 
 	m.mu.RLock()
@@ -1746,11 +1935,15 @@ func (m *Manager) pickNextMixed(ctx context.Context, providers []string, model s
 			modelKey = strings.TrimSpace(parsed.ModelName)
 		}
 	}
+	prefixGroup := m.modelPrefixGroupLocked(modelKey)
 	registryRef := registry.GetGlobalRegistry()
 	for _, candidate := range m.auths {
 		if candidate == nil || candidate.Disabled {
 			continue
 		}
+		if prefixGroup != "" && strings.TrimSpace(candidate.Prefix) != prefixGroup {
+			continue
+		}
 		if pinnedAuthID != "" && candidate.ID != pinnedAuthID {
 			continue
 		}
@@ -1774,22 +1967,23 @@ func (m *Manager) pickNextMixed(ctx context.Context, providers []string, model s
 	}
 	if len(candidates) == 0 {
 		m.mu.RUnlock()
-		return nil, nil, "", &Error{Code: "auth_not_found", Message: "no auth available"}
+		return nil, nil, "", nil, &Error{Code: "auth_not_found", Message: "no auth available"}
 	}
-	selected, errPick := m.selector.Pick(ctx, "mixed", model, opts, candidates)
+	selected, release, errPick := m.pickWithGroupLimits(ctx, "mixed", model, opts, candidates)
 	if errPick != nil {
 		m.mu.RUnlock()
-		return nil, nil, "", errPick
+		return nil, nil, "", nil, errPick
 	}
 	if selected == nil {
 		m.mu.RUnlock()
-		return nil, nil, "", &Error{Code: "auth_not_found", Message: "selector returned no auth"}
+		return nil, nil, "", nil, &Error{Code: "auth_not_found", Message: "selector returned no auth"}
 	}
 	providerKey := strings.TrimSpace(strings.ToLower(selected.Provider))
 	executor, okExecutor := m.executors[providerKey]
 	if !okExecutor {
 		m.mu.RUnlock()
-		return nil, nil, "", &Error{Code: "executor_not_found", Message: "executor not registered"}
+		release()
+		return nil, nil, "", nil, &Error{Code: "executor_not_found", Message: "executor not registered"}
 	}
 	authCopy := selected.Clone()
 	m.mu.RUnlock()
@@ -1801,7 +1995,7 @@ func (m *Manager) pickNextMixed(ctx context.Context, providers []string, model s
 		}
 		m.mu.Unlock()
 	}
-	return authCopy, executor, providerKey, nil
+	return authCopy, executor, providerKey, release, nil
 }
 
 func (m *Manager) persist(ctx context.Context, auth *Auth) error {
@@ -1828,6 +2022,9 @@ func (m *Manager) persist(ctx context.Context, auth *Auth) error {
 // every few seconds and triggers refresh operations when required.
 // Only one loop is kept alive; starting a new one cancels the previous run.
 func (m *Manager) StartAutoRefresh(parent context.Context, interval time.Duration) {
+	if m.closed.Load() {
+		return
+	}
 	if interval <= 0 || interval > refreshCheckInterval {
 		interval = refreshCheckInterval
 	} else {
@@ -1839,7 +2036,9 @@ func (m *Manager) StartAutoRefresh(parent context.Context, interval time.Duratio
 	}
 	ctx, cancel := context.WithCancel(parent)
 	m.refreshCancel = cancel
+	m.refreshWG.Add(1)
 	go func() {
+		defer m.refreshWG.Done()
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 		m.checkRefreshes(ctx)
@@ -1862,6 +2061,42 @@ func (m *Manager) StopAutoRefresh() {
 	}
 }
 
+// Close stops the background refresh loop, waits for in-flight refreshes to finish and
+// flushes the store when it implements StoreFlusher. It is safe to call multiple times;
+// only the first call does any work. The context bounds how long Close waits.
+func (m *Manager) Close(ctx context.Context) error {
+	if m == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var closeErr error
+	m.closeOnce.Do(func() {
+		m.closed.Store(true)
+		m.StopAutoRefresh()
+
+		done := make(chan struct{})
+		go func() {
+			m.refreshWG.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			closeErr = ctx.Err()
+			return
+		}
+
+		if flusher, ok := m.store.(StoreFlusher); ok && flusher != nil {
+			if errFlush := flusher.Flush(ctx); errFlush != nil {
+				closeErr = errFlush
+			}
+		}
+	})
+	return closeErr
+}
+
 func (m *Manager) checkRefreshes(ctx context.Context) {
 	// log.Debugf("checking refreshes")
 	now := time.Now()
@@ -1880,7 +2115,11 @@ func (m *Manager) checkRefreshes(ctx context.Context) {
 			if !m.markRefreshPending(a.ID, now) {
 				continue
 			}
-			go m.refreshAuth(ctx, a.ID)
+			m.refreshWG.Add(1)
+			go func(id string) {
+				defer m.refreshWG.Done()
+				m.refreshAuth(ctx, id)
+			}(a.ID)
 		}
 	}
 }
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+type blockingRefreshExecutor struct {
+	started  chan struct{}
+	finished atomic.Bool
+}
+
+func (e *blockingRefreshExecutor) Identifier() string { return "close-test" }
+
+func (e *blockingRefreshExecutor) Execute(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, nil
+}
+
+func (e *blockingRefreshExecutor) ExecuteStream(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (*cliproxyexecutor.StreamResult, error) {
+	ch := make(chan cliproxyexecutor.StreamChunk)
+	close(ch)
+	return &cliproxyexecutor.StreamResult{Chunks: ch}, nil
+}
+
+// Refresh blocks until the refresh context is cancelled.
+func (e *blockingRefreshExecutor) Refresh(ctx context.Context, auth *Auth) (*Auth, error) {
+	select {
+	case e.started <- struct{}{}:
+	default:
+	}
+	<-ctx.Done()
+	e.finished.Store(true)
+	return nil, ctx.Err()
+}
+
+func (e *blockingRefreshExecutor) CountTokens(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, nil
+}
+
+func (e *blockingRefreshExecutor) HttpRequest(context.Context, *Auth, *http.Request) (*http.Response, error) {
+	return nil, nil
+}
+
+type flushCountingStore struct {
+	flushes atomic.Int32
+}
+
+func (s *flushCountingStore) List(context.Context) ([]*Auth, error) { return nil, nil }
+
+func (s *flushCountingStore) Save(_ context.Context, auth *Auth) (string, error) {
+	return auth.ID, nil
+}
+
+func (s *flushCountingStore) Delete(context.Context, string) error { return nil }
+
+func (s *flushCountingStore) Flush(context.Context) error {
+	s.flushes.Add(1)
+	return nil
+}
+
+func TestManagerCloseStopsBackgroundRefresher(t *testing.T) {
+	store := &flushCountingStore{}
+	manager := NewManager(store, nil, nil)
+	executor := &blockingRefreshExecutor{started: make(chan struct{}, 1)}
+	manager.RegisterExecutor(executor)
+
+	auth := &Auth{
+		ID:       "close-test-auth",
+		Provider: "close-test",
+		Metadata: map[string]any{"refresh_interval_seconds": 1},
+	}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("register auth: %v", err)
+	}
+
+	manager.StartAutoRefresh(context.Background(), time.Second)
+	select {
+	case <-executor.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected background refresh to start")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := manager.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if !executor.finished.Load() {
+		t.Fatal("expected in-flight refresh to finish before Close returned")
+	}
+	if got := store.flushes.Load(); got != 1 {
+		t.Fatalf("store flushes = %d, want 1", got)
+	}
+
+	// Close is idempotent and the manager refuses to restart refreshing.
+	if err := manager.Close(ctx); err != nil {
+		t.Fatalf("second Close() error = %v", err)
+	}
+	if got := store.flushes.Load(); got != 1 {
+		t.Fatalf("store flushes after second Close = %d, want 1", got)
+	}
+	manager.StartAutoRefresh(context.Background(), time.Second)
+	if manager.refreshCancel != nil {
+		t.Fatal("expected StartAutoRefresh to be a no-op after Close")
+	}
+}
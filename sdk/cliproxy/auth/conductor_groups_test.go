@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestManagerPickNextMixed_EnforcesGroupConcurrency(t *testing.T) {
+	manager := NewManager(nil, nil, nil)
+	manager.RegisterExecutor(&replaceAwareExecutor{id: "gemini"})
+	manager.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{
+		Groups: []internalconfig.AuthGroup{{Name: "premium", Tier: 10, MaxConcurrency: 1}},
+	}})
+
+	auths := []*Auth{
+		{ID: "group-premium-1", Provider: "gemini", Attributes: map[string]string{"group": "premium"}},
+		{ID: "group-premium-2", Provider: "gemini", Metadata: map[string]any{"group": "premium"}},
+		{ID: "group-none", Provider: "gemini"},
+	}
+	for _, auth := range auths {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register %s: %v", auth.ID, err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "group-model"}})
+		id := auth.ID
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+
+	pick := func() (*Auth, func()) {
+		t.Helper()
+		auth, _, _, release, err := manager.pickNextMixed(context.Background(), []string{"gemini"}, "group-model", cliproxyexecutor.Options{}, map[string]struct{}{})
+		if err != nil {
+			t.Fatalf("pickNextMixed: %v", err)
+		}
+		return auth, release
+	}
+
+	first, releaseFirst := pick()
+	if !strings.HasPrefix(first.ID, "group-premium-") {
+		t.Fatalf("first pick = %s, want the higher-tier premium group", first.ID)
+	}
+	// The premium group is at its limit even though its second credential is idle;
+	// ungrouped credentials are not limited.
+	for i := 0; i < 2; i++ {
+		next, release := pick()
+		if next.ID != "group-none" {
+			t.Fatalf("pick %d while premium is busy = %s, want group-none", i+2, next.ID)
+		}
+		release()
+	}
+
+	releaseFirst()
+	next, release := pick()
+	defer release()
+	if !strings.HasPrefix(next.ID, "group-premium-") {
+		t.Fatalf("pick after release = %s, want premium again", next.ID)
+	}
+}
+
+func TestManagerPickNextMixed_WeightedModelConsumesSlots(t *testing.T) {
+	manager := NewManager(nil, nil, nil)
+	manager.RegisterExecutor(&replaceAwareExecutor{id: "gemini"})
+	manager.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{
+		Groups: []internalconfig.AuthGroup{{Name: "premium", Tier: 10, MaxConcurrency: 3}},
+	}})
+
+	auths := []*Auth{
+		{ID: "weight-premium", Provider: "gemini", Attributes: map[string]string{"group": "premium"}},
+		{ID: "weight-none", Provider: "gemini"},
+	}
+	models := []*registry.ModelInfo{{ID: "weight-heavy", ConcurrencyWeight: 2}, {ID: "weight-light"}}
+	for _, auth := range auths {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register %s: %v", auth.ID, err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, models)
+		id := auth.ID
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+
+	pick := func(model string) (*Auth, func()) {
+		t.Helper()
+		auth, _, _, release, err := manager.pickNextMixed(context.Background(), []string{"gemini"}, model, cliproxyexecutor.Options{}, map[string]struct{}{})
+		if err != nil {
+			t.Fatalf("pickNextMixed(%s): %v", model, err)
+		}
+		return auth, release
+	}
+	inflight := func() int {
+		manager.groupLimits.mu.Lock()
+		defer manager.groupLimits.mu.Unlock()
+		return manager.groupLimits.inflight["premium"]
+	}
+
+	heavy, releaseHeavy := pick("weight-heavy")
+	if heavy.ID != "weight-premium" {
+		t.Fatalf("heavy pick = %s, want weight-premium", heavy.ID)
+	}
+	if got := inflight(); got != 2 {
+		t.Fatalf("in-flight slots after heavy request = %d, want 2", got)
+	}
+	// One slot is left: a light request fits, a second heavy one does not.
+	if next, release := pick("weight-heavy"); next.ID != "weight-none" {
+		t.Fatalf("second heavy pick = %s, want weight-none", next.ID)
+	} else {
+		release()
+	}
+	light, releaseLight := pick("weight-light")
+	if light.ID != "weight-premium" {
+		t.Fatalf("light pick = %s, want weight-premium", light.ID)
+	}
+	if got := inflight(); got != 3 {
+		t.Fatalf("in-flight slots = %d, want 3", got)
+	}
+
+	releaseHeavy()
+	releaseLight()
+	if got := inflight(); got != 0 {
+		t.Fatalf("in-flight slots after release = %d, want 0", got)
+	}
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestManagerPickNextMixed_ModelPrefixNarrowsAuthPool(t *testing.T) {
+	manager := NewManager(nil, nil, nil)
+	manager.RegisterExecutor(&replaceAwareExecutor{id: "gemini"})
+
+	auths := []*Auth{
+		{ID: "prefix-team-a-1", Provider: "gemini", Prefix: "teamA"},
+		{ID: "prefix-team-a-2", Provider: "gemini", Prefix: "teamA"},
+		{ID: "prefix-team-b", Provider: "gemini", Prefix: "teamB"},
+		{ID: "prefix-shared", Provider: "gemini"},
+	}
+	for _, auth := range auths {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register %s: %v", auth.ID, err)
+		}
+		// Every credential advertises the prefixed ID as well, so only the manager's
+		// prefix matching can keep the groups apart.
+		registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{
+			{ID: "gemini-2.5-pro"},
+			{ID: "teamA/gemini-2.5-pro"},
+		})
+		id := auth.ID
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+
+	pickAll := func(model string) []string {
+		tried := make(map[string]struct{})
+		var picked []string
+		for {
+			auth, _, _, _, err := manager.pickNextMixed(context.Background(), []string{"gemini"}, model, cliproxyexecutor.Options{}, tried)
+			if err != nil {
+				break
+			}
+			tried[auth.ID] = struct{}{}
+			picked = append(picked, auth.ID)
+		}
+		slices.Sort(picked)
+		return picked
+	}
+
+	if got, want := pickAll("teamA/gemini-2.5-pro"), []string{"prefix-team-a-1", "prefix-team-a-2"}; !slices.Equal(got, want) {
+		t.Fatalf("teamA candidates = %v, want %v", got, want)
+	}
+	if got := pickAll("gemini-2.5-pro"); len(got) != len(auths) {
+		t.Fatalf("unprefixed model candidates = %v, want all %d auths", got, len(auths))
+	}
+	if got := rewriteModelForAuth("teamA/gemini-2.5-pro", auths[0]); got != "gemini-2.5-pro" {
+		t.Fatalf("upstream model = %q, want the prefix stripped", got)
+	}
+}
@@ -0,0 +1,269 @@
+package auth
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// Exclusion reasons reported by ExplainRoute.
+const (
+	RouteReasonProviderDisabled  = "provider_disabled"
+	RouteReasonDisabled          = "disabled"
+	RouteReasonPrefixMismatch    = "prefix_mismatch"
+	RouteReasonNotPinned         = "not_pinned"
+	RouteReasonNoExecutor        = "no_executor"
+	RouteReasonModelNotSupported = "model_not_supported"
+	RouteReasonStandby           = "standby"
+	RouteReasonOutsideWindow     = "outside_active_window"
+	RouteReasonCooldown          = "cooldown"
+	RouteReasonUnavailable       = "unavailable"
+	RouteReasonGroupAtLimit      = "group_at_limit"
+	RouteReasonLowerTier         = "lower_tier"
+	RouteReasonLowerPriority     = "lower_priority"
+	RouteReasonNotSelected       = "not_selected"
+)
+
+// routeNoteNoPreview explains an empty selection when the selector cannot report its next pick.
+const routeNoteNoPreview = "the configured selector cannot preview its next pick"
+
+// RouteCandidate describes one auth of a requested provider and why it could or could not
+// serve the request.
+type RouteCandidate struct {
+	ID            string     `json:"id"`
+	Provider      string     `json:"provider"`
+	Label         string     `json:"label,omitempty"`
+	Group         string     `json:"group,omitempty"`
+	Priority      int        `json:"priority"`
+	UpstreamModel string     `json:"upstream_model,omitempty"`
+	Eligible      bool       `json:"eligible"`
+	Reason        string     `json:"reason,omitempty"`
+	RetryAfter    *time.Time `json:"retry_after,omitempty"`
+}
+
+// RouteExplanation is the routing decision trace for a sample request.
+type RouteExplanation struct {
+	Model      string           `json:"model"`
+	Providers  []string         `json:"providers"`
+	PinnedAuth string           `json:"pinned_auth,omitempty"`
+	Candidates []RouteCandidate `json:"candidates"`
+	Selected   string           `json:"selected,omitempty"`
+	Note       string           `json:"note,omitempty"`
+}
+
+// selectionPeeker is implemented by selectors that can report their next pick without
+// advancing their state.
+type selectionPeeker interface {
+	Peek(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error)
+}
+
+// ExplainRoute walks the same checks as request dispatch for model on providers and reports,
+// for every auth of those providers, whether it is eligible and why not. pinnedAuthID narrows
+// the choice like a pinned request. Nothing is executed and no selector or group state changes.
+func (m *Manager) ExplainRoute(ctx context.Context, providers []string, model, pinnedAuthID string) RouteExplanation {
+	modelKey := strings.TrimSpace(model)
+	if parsed := thinking.ParseSuffix(modelKey); parsed.ModelName != "" {
+		modelKey = strings.TrimSpace(parsed.ModelName)
+	}
+	explanation := RouteExplanation{Model: model, PinnedAuth: pinnedAuthID, Candidates: []RouteCandidate{}}
+
+	requested := make(map[string]bool, len(providers))
+	enabled := make(map[string]bool, len(providers))
+	for _, provider := range providers {
+		p := strings.TrimSpace(strings.ToLower(provider))
+		if p == "" || requested[p] {
+			continue
+		}
+		requested[p] = true
+		explanation.Providers = append(explanation.Providers, p)
+		enabled[p] = m.ProviderEnabled(p)
+	}
+
+	now := time.Now()
+	registryRef := registry.GetGlobalRegistry()
+	m.mu.RLock()
+	prefixGroup := m.modelPrefixGroupLocked(modelKey)
+	selector := m.selector
+	var eligible []*Auth
+	for _, auth := range m.auths {
+		if auth == nil {
+			continue
+		}
+		providerKey := strings.TrimSpace(strings.ToLower(auth.Provider))
+		if !requested[providerKey] {
+			continue
+		}
+		candidate := RouteCandidate{
+			ID:            auth.ID,
+			Provider:      providerKey,
+			Label:         auth.Label,
+			Group:         authGroup(auth),
+			Priority:      authPriority(auth),
+			UpstreamModel: m.applyOAuthModelAlias(auth, applyAuthModelRename(auth, rewriteModelForAuth(model, auth))),
+		}
+		_, hasExecutor := m.executors[providerKey]
+		switch {
+		case !enabled[providerKey]:
+			candidate.Reason = RouteReasonProviderDisabled
+		case auth.Disabled || auth.Status == StatusDisabled:
+			candidate.Reason = RouteReasonDisabled
+		case prefixGroup != "" && strings.TrimSpace(auth.Prefix) != prefixGroup:
+			candidate.Reason = RouteReasonPrefixMismatch
+		case pinnedAuthID != "" && auth.ID != pinnedAuthID:
+			candidate.Reason = RouteReasonNotPinned
+		case !hasExecutor:
+			candidate.Reason = RouteReasonNoExecutor
+		case modelKey != "" && registryRef != nil && !registryRef.ClientSupportsModel(auth.ID, modelKey):
+			candidate.Reason = RouteReasonModelNotSupported
+		case pinnedAuthID == "" && authStandby(auth):
+			candidate.Reason = RouteReasonStandby
+		case !authActiveAt(auth, now):
+			candidate.Reason = RouteReasonOutsideWindow
+		default:
+			if blocked, reason, next := isAuthBlockedForModel(auth, model, now); blocked {
+				candidate.Reason = RouteReasonUnavailable
+				switch reason {
+				case blockReasonCooldown:
+					candidate.Reason = RouteReasonCooldown
+				case blockReasonDisabled:
+					candidate.Reason = RouteReasonDisabled
+				}
+				if !next.IsZero() {
+					candidate.RetryAfter = &next
+				}
+			} else {
+				candidate.Eligible = true
+				eligible = append(eligible, auth.Clone())
+			}
+		}
+		explanation.Candidates = append(explanation.Candidates, candidate)
+	}
+	m.mu.RUnlock()
+	sort.Slice(explanation.Candidates, func(i, j int) bool {
+		return explanation.Candidates[i].ID < explanation.Candidates[j].ID
+	})
+	index := make(map[string]int, len(explanation.Candidates))
+	for i, candidate := range explanation.Candidates {
+		index[candidate.ID] = i
+	}
+	exclude := func(auth *Auth, reason string) {
+		candidate := &explanation.Candidates[index[auth.ID]]
+		candidate.Eligible = false
+		candidate.Reason = reason
+	}
+
+	// Group limits: drop groups at capacity, then keep only the highest tier left.
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if groups := authGroupsFromConfig(cfg); len(groups) > 0 && len(eligible) > 0 {
+		byTier := make(map[int][]*Auth)
+		m.groupLimits.mu.Lock()
+		for _, auth := range eligible {
+			name := authGroup(auth)
+			limits, ok := groups[name]
+			if ok && !m.groupLimits.hasCapacityLocked(name, limits, modelConcurrencySlots(model, auth), now) {
+				exclude(auth, RouteReasonGroupAtLimit)
+				continue
+			}
+			byTier[limits.Tier] = append(byTier[limits.Tier], auth)
+		}
+		m.groupLimits.mu.Unlock()
+		bestTier, found := 0, false
+		for tier := range byTier {
+			if !found || tier > bestTier {
+				bestTier, found = tier, true
+			}
+		}
+		eligible = byTier[bestTier]
+		for tier, auths := range byTier {
+			if tier == bestTier {
+				continue
+			}
+			for _, auth := range auths {
+				exclude(auth, RouteReasonLowerTier)
+			}
+		}
+	}
+
+	// The selector only considers the highest priority among the remaining auths.
+	bestPriority, found := 0, false
+	for _, auth := range eligible {
+		if priority := authPriority(auth); !found || priority > bestPriority {
+			bestPriority, found = priority, true
+		}
+	}
+	top := make([]*Auth, 0, len(eligible))
+	for _, auth := range eligible {
+		if authPriority(auth) != bestPriority {
+			exclude(auth, RouteReasonLowerPriority)
+			continue
+		}
+		top = append(top, auth)
+	}
+	if len(top) == 0 {
+		return explanation
+	}
+
+	peeker, ok := selector.(selectionPeeker)
+	if !ok {
+		explanation.Note = routeNoteNoPreview
+		return explanation
+	}
+	opts := cliproxyexecutor.Options{}
+	if pinnedAuthID != "" {
+		opts.Metadata = map[string]any{cliproxyexecutor.PinnedAuthMetadataKey: pinnedAuthID}
+	}
+	selected, err := peeker.Peek(ctx, "mixed", model, opts, top)
+	if err != nil || selected == nil {
+		return explanation
+	}
+	explanation.Selected = selected.ID
+	for _, auth := range top {
+		if auth.ID != selected.ID {
+			explanation.Candidates[index[auth.ID]].Reason = RouteReasonNotSelected
+		}
+	}
+	return explanation
+}
+
+// UpstreamModels returns the distinct upstream model names model resolves to across the auths
+// of providers, after prefix stripping, per-auth renames and model aliases. Callers that gate
+// models by name use it so an alias cannot reach a model the name itself would be denied.
+func (m *Manager) UpstreamModels(providers []string, model string) []string {
+	if m == nil || strings.TrimSpace(model) == "" {
+		return nil
+	}
+	requested := make(map[string]bool, len(providers))
+	for _, provider := range providers {
+		requested[strings.TrimSpace(strings.ToLower(provider))] = true
+	}
+	m.mu.RLock()
+	auths := make([]*Auth, 0, len(m.auths))
+	for _, auth := range m.auths {
+		if auth != nil && requested[strings.TrimSpace(strings.ToLower(auth.Provider))] {
+			auths = append(auths, auth)
+		}
+	}
+	m.mu.RUnlock()
+
+	seen := make(map[string]struct{}, len(auths))
+	var out []string
+	for _, auth := range auths {
+		upstream := rewriteModelForAuth(model, auth)
+		upstream = applyAuthModelRename(auth, upstream)
+		upstream = m.applyOAuthModelAlias(auth, upstream)
+		upstream = m.applyAPIKeyModelAlias(auth, upstream)
+		if _, ok := seen[upstream]; ok || upstream == "" {
+			continue
+		}
+		seen[upstream] = struct{}{}
+		out = append(out, upstream)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// groupRPMWindow is the sliding window used for per-group RPM limits.
+const groupRPMWindow = time.Minute
+
+// groupsAtLimitMessage identifies the error returned when every group is at its limit.
+const groupsAtLimitMessage = "all credential groups are at their limits"
+
+// groupLimiter tracks in-flight requests and recent request starts per credential group.
+// The zero value is ready to use.
+type groupLimiter struct {
+	mu       sync.Mutex
+	inflight map[string]int
+	started  map[string][]time.Time
+}
+
+// authGroup returns the routing group of the auth from its attributes, falling back to metadata.
+func authGroup(auth *Auth) string {
+	if auth == nil {
+		return ""
+	}
+	if group := strings.TrimSpace(auth.Attributes["group"]); group != "" {
+		return group
+	}
+	if raw, ok := auth.Metadata["group"].(string); ok {
+		return strings.TrimSpace(raw)
+	}
+	return ""
+}
+
+// authGroupsFromConfig indexes the configured routing groups by name.
+func authGroupsFromConfig(cfg *internalconfig.Config) map[string]internalconfig.AuthGroup {
+	if cfg == nil || len(cfg.Routing.Groups) == 0 {
+		return nil
+	}
+	groups := make(map[string]internalconfig.AuthGroup, len(cfg.Routing.Groups))
+	for _, group := range cfg.Routing.Groups {
+		name := strings.TrimSpace(group.Name)
+		if name == "" {
+			continue
+		}
+		groups[name] = group
+	}
+	return groups
+}
+
+// modelConcurrencySlots returns how many group concurrency slots a request for model
+// consumes on the auth's provider, as configured by routing.model-weights.
+func modelConcurrencySlots(model string, auth *Auth) int {
+	model = strings.TrimSpace(model)
+	if parsed := thinking.ParseSuffix(model); parsed.ModelName != "" {
+		model = strings.TrimSpace(parsed.ModelName)
+	}
+	provider := ""
+	if auth != nil {
+		provider = auth.Provider
+	}
+	return registry.LookupModelInfo(model, provider).ConcurrencySlots()
+}
+
+// groupSlots caps slots at the group's concurrency limit so a heavy model can still run
+// once the group is otherwise idle.
+func groupSlots(limits internalconfig.AuthGroup, slots int) int {
+	if slots < 1 {
+		return 1
+	}
+	if limits.MaxConcurrency > 0 && slots > limits.MaxConcurrency {
+		return limits.MaxConcurrency
+	}
+	return slots
+}
+
+// hasCapacityLocked reports whether a request occupying slots may start in the group.
+// Callers hold l.mu.
+func (l *groupLimiter) hasCapacityLocked(name string, limits internalconfig.AuthGroup, slots int, now time.Time) bool {
+	if limits.MaxConcurrency > 0 && l.inflight[name]+groupSlots(limits, slots) > limits.MaxConcurrency {
+		return false
+	}
+	if limits.RPM > 0 {
+		started := l.started[name]
+		cutoff := now.Add(-groupRPMWindow)
+		kept := started[:0]
+		for _, ts := range started {
+			if ts.After(cutoff) {
+				kept = append(kept, ts)
+			}
+		}
+		if l.started == nil {
+			l.started = make(map[string][]time.Time)
+		}
+		l.started[name] = kept
+		if len(kept) >= limits.RPM {
+			return false
+		}
+	}
+	return true
+}
+
+// acquireLocked records a request start in the group and returns the function releasing
+// its concurrency slots. Callers hold l.mu.
+func (l *groupLimiter) acquireLocked(name string, limits internalconfig.AuthGroup, slots int, now time.Time) func() {
+	if limits.RPM > 0 {
+		if l.started == nil {
+			l.started = make(map[string][]time.Time)
+		}
+		l.started[name] = append(l.started[name], now)
+	}
+	if limits.MaxConcurrency <= 0 {
+		return func() {}
+	}
+	if l.inflight == nil {
+		l.inflight = make(map[string]int)
+	}
+	slots = groupSlots(limits, slots)
+	l.inflight[name] += slots
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.inflight[name] -= slots
+			if l.inflight[name] < 0 {
+				l.inflight[name] = 0
+			}
+			l.mu.Unlock()
+		})
+	}
+}
+
+// pickWithGroupLimits runs the selector over candidates, skipping credentials whose group
+// is at its concurrency or RPM limit and trying higher-tier groups first. The returned
+// release function must be called once the request finishes.
+func (m *Manager) pickWithGroupLimits(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, candidates []*Auth) (*Auth, func(), error) {
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	groups := authGroupsFromConfig(cfg)
+	if len(groups) == 0 {
+		selected, errPick := m.selector.Pick(ctx, provider, model, opts, candidates)
+		return selected, func() {}, errPick
+	}
+
+	slots := make(map[*Auth]int, len(candidates))
+	for _, candidate := range candidates {
+		slots[candidate] = modelConcurrencySlots(model, candidate)
+	}
+
+	m.groupLimits.mu.Lock()
+	defer m.groupLimits.mu.Unlock()
+	now := time.Now()
+	byTier := make(map[int][]*Auth)
+	for _, candidate := range candidates {
+		name := authGroup(candidate)
+		limits, ok := groups[name]
+		if ok && !m.groupLimits.hasCapacityLocked(name, limits, slots[candidate], now) {
+			continue
+		}
+		byTier[limits.Tier] = append(byTier[limits.Tier], candidate)
+	}
+	if len(byTier) == 0 {
+		return nil, nil, &Error{Code: "auth_unavailable", Message: groupsAtLimitMessage, Retryable: true, HTTPStatus: 429}
+	}
+	tiers := make([]int, 0, len(byTier))
+	for tier := range byTier {
+		tiers = append(tiers, tier)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(tiers)))
+
+	var lastErr error
+	for _, tier := range tiers {
+		selected, errPick := m.selector.Pick(ctx, provider, model, opts, byTier[tier])
+		if errPick != nil {
+			lastErr = errPick
+			continue
+		}
+		if selected == nil {
+			continue
+		}
+		name := authGroup(selected)
+		limits, ok := groups[name]
+		if !ok {
+			return selected, func() {}, nil
+		}
+		release := m.groupLimits.acquireLocked(name, limits, slots[selected], now)
+		return selected, func() {
+			release()
+			m.requestQueue.notify()
+		}, nil
+	}
+	return nil, nil, lastErr
+}
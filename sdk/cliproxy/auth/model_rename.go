@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+)
+
+// modelRenameMetadataKey is the auth metadata key holding a per-credential model rename map.
+// Keys are the upstream model IDs exposed by the account; values are the names clients see.
+const modelRenameMetadataKey = "model_rename"
+
+// ModelRenames returns the auth's model_rename metadata as upstream ID -> client-facing name.
+// Unlike global model aliases it applies to this credential only.
+func ModelRenames(auth *Auth) map[string]string {
+	if auth == nil || len(auth.Metadata) == 0 {
+		return nil
+	}
+	out := make(map[string]string)
+	add := func(upstream, name string) {
+		upstream = strings.TrimSpace(upstream)
+		name = strings.TrimSpace(name)
+		if upstream == "" || name == "" || strings.EqualFold(upstream, name) {
+			return
+		}
+		out[upstream] = name
+	}
+	switch v := auth.Metadata[modelRenameMetadataKey].(type) {
+	case map[string]any:
+		for upstream, name := range v {
+			if s, ok := name.(string); ok {
+				add(upstream, s)
+			}
+		}
+	case map[string]string:
+		for upstream, name := range v {
+			add(upstream, name)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// applyAuthModelRename maps a client-facing model name back to the upstream ID using the
+// auth's model_rename metadata, preserving any thinking suffix on the request.
+func applyAuthModelRename(auth *Auth, requestedModel string) string {
+	renames := ModelRenames(auth)
+	if len(renames) == 0 {
+		return requestedModel
+	}
+	parsed := thinking.ParseSuffix(requestedModel)
+	base := strings.TrimSpace(parsed.ModelName)
+	for upstream, name := range renames {
+		if !strings.EqualFold(name, base) {
+			continue
+		}
+		if parsed.HasSuffix && parsed.RawSuffix != "" {
+			return upstream + "(" + parsed.RawSuffix + ")"
+		}
+		return upstream
+	}
+	return requestedModel
+}
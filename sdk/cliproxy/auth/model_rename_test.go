@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+type modelRecordingExecutor struct {
+	replaceAwareExecutor
+
+	mu     sync.Mutex
+	models map[string]string
+}
+
+func (e *modelRecordingExecutor) Execute(_ context.Context, auth *Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.models[auth.ID] = req.Model
+	return cliproxyexecutor.Response{}, nil
+}
+
+func TestManagerExecute_AppliesPerAuthModelRename(t *testing.T) {
+	manager := NewManager(nil, nil, nil)
+	executor := &modelRecordingExecutor{replaceAwareExecutor: replaceAwareExecutor{id: "codex"}, models: make(map[string]string)}
+	manager.RegisterExecutor(executor)
+
+	auths := []*Auth{
+		{ID: "rename-vendor", Provider: "codex", Metadata: map[string]any{"model_rename": map[string]any{"vendor-gpt-5-2025": "gpt-5"}}},
+		{ID: "rename-plain", Provider: "codex"},
+	}
+	for _, auth := range auths {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register %s: %v", auth.ID, err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "gpt-5"}})
+		id := auth.ID
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+
+	for i := 0; i < len(auths); i++ {
+		if _, err := manager.Execute(context.Background(), []string{"codex"}, cliproxyexecutor.Request{Model: "gpt-5(high)"}, cliproxyexecutor.Options{}); err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+	}
+	if got := executor.models["rename-vendor"]; got != "vendor-gpt-5-2025(high)" {
+		t.Fatalf("renamed auth upstream model = %q, want vendor-gpt-5-2025(high)", got)
+	}
+	if got := executor.models["rename-plain"]; got != "gpt-5(high)" {
+		t.Fatalf("plain auth upstream model = %q, want gpt-5(high)", got)
+	}
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// SetProviderEnabled enables or disables every auth of a provider at runtime without
+// touching the credentials. Requests routed only to disabled providers fail with 503.
+func (m *Manager) SetProviderEnabled(provider string, enabled bool) {
+	if m == nil {
+		return
+	}
+	key := strings.ToLower(strings.TrimSpace(provider))
+	if key == "" {
+		return
+	}
+	m.providerToggleMu.Lock()
+	defer m.providerToggleMu.Unlock()
+	current, _ := m.disabledProviders.Load().(map[string]struct{})
+	next := make(map[string]struct{}, len(current)+1)
+	for name := range current {
+		next[name] = struct{}{}
+	}
+	if enabled {
+		delete(next, key)
+	} else {
+		next[key] = struct{}{}
+	}
+	m.disabledProviders.Store(next)
+}
+
+// ProviderEnabled reports whether the provider is enabled for dispatch.
+func (m *Manager) ProviderEnabled(provider string) bool {
+	if m == nil {
+		return true
+	}
+	disabled, _ := m.disabledProviders.Load().(map[string]struct{})
+	_, off := disabled[strings.ToLower(strings.TrimSpace(provider))]
+	return !off
+}
+
+// DisabledProviders lists the providers disabled at runtime, sorted by name.
+func (m *Manager) DisabledProviders() []string {
+	if m == nil {
+		return nil
+	}
+	disabled, _ := m.disabledProviders.Load().(map[string]struct{})
+	out := make([]string, 0, len(disabled))
+	for name := range disabled {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// errProvidersDisabled is returned when every provider serving a request is disabled.
+func errProvidersDisabled(providers []string) *Error {
+	return &Error{
+		Code:       "provider_disabled",
+		Message:    "provider disabled: " + strings.Join(providers, ", "),
+		HTTPStatus: http.StatusServiceUnavailable,
+	}
+}
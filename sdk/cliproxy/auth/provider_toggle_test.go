@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestManagerSetProviderEnabled_BlocksDispatch(t *testing.T) {
+	manager := NewManager(nil, nil, nil)
+	manager.RegisterExecutor(&replaceAwareExecutor{id: "codex"})
+	auth := &Auth{ID: "toggle-codex", Provider: "codex"}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "toggle-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	execute := func() error {
+		_, err := manager.Execute(context.Background(), []string{"codex"}, cliproxyexecutor.Request{Model: "toggle-model"}, cliproxyexecutor.Options{})
+		return err
+	}
+	if err := execute(); err != nil {
+		t.Fatalf("execute with provider enabled: %v", err)
+	}
+
+	manager.SetProviderEnabled("Codex", false)
+	if manager.ProviderEnabled("codex") {
+		t.Fatal("provider still reported enabled")
+	}
+	var authErr *Error
+	if err := execute(); !errors.As(err, &authErr) || authErr.HTTPStatus != http.StatusServiceUnavailable || authErr.Code != "provider_disabled" {
+		t.Fatalf("execute with provider disabled: %v, want provider_disabled 503", err)
+	}
+	if got := manager.DisabledProviders(); len(got) != 1 || got[0] != "codex" {
+		t.Fatalf("disabled providers = %v, want [codex]", got)
+	}
+
+	manager.SetProviderEnabled("codex", true)
+	if err := execute(); err != nil {
+		t.Fatalf("execute after re-enabling: %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+const (
+	// defaultQueueMaxWait applies when routing.queue.max-wait-ms is unset.
+	defaultQueueMaxWait = 10 * time.Second
+	// queuePollInterval re-checks capacity that frees up without a release, such as an
+	// RPM window sliding forward.
+	queuePollInterval = 250 * time.Millisecond
+)
+
+// requestQueue keeps one FIFO of waiting requests per provider set. Only the head of a
+// queue competes for capacity, so requests are served in arrival order.
+// The zero value is ready to use.
+type requestQueue struct {
+	mu      sync.Mutex
+	waiters map[string][]*queueWaiter
+}
+
+// queueWaiter is a queued request; ready is signalled when it should retry.
+type queueWaiter struct {
+	ready chan struct{}
+}
+
+// enqueue appends a waiter unless the queue already holds depth requests.
+func (q *requestQueue) enqueue(key string, depth int) (*queueWaiter, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.waiters[key]) >= depth {
+		return nil, false
+	}
+	if q.waiters == nil {
+		q.waiters = make(map[string][]*queueWaiter)
+	}
+	w := &queueWaiter{ready: make(chan struct{}, 1)}
+	q.waiters[key] = append(q.waiters[key], w)
+	return w, true
+}
+
+// remove drops the waiter and wakes the next head of its queue.
+func (q *requestQueue) remove(key string, w *queueWaiter) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	queue := q.waiters[key]
+	for i, candidate := range queue {
+		if candidate != w {
+			continue
+		}
+		queue = append(queue[:i], queue[i+1:]...)
+		break
+	}
+	if len(queue) == 0 {
+		delete(q.waiters, key)
+		return
+	}
+	q.waiters[key] = queue
+	queue[0].signal()
+}
+
+// pending reports how many requests wait in the queue.
+func (q *requestQueue) pending(key string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.waiters[key])
+}
+
+// isHead reports whether w is first in its queue.
+func (q *requestQueue) isHead(key string, w *queueWaiter) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	queue := q.waiters[key]
+	return len(queue) > 0 && queue[0] == w
+}
+
+// notify wakes the head of every queue after capacity was released.
+func (q *requestQueue) notify() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, queue := range q.waiters {
+		if len(queue) > 0 {
+			queue[0].signal()
+		}
+	}
+}
+
+func (w *queueWaiter) signal() {
+	select {
+	case w.ready <- struct{}{}:
+	default:
+	}
+}
+
+// queueSettings returns the configured queue depth and wait. A depth of 0 disables queueing.
+func (m *Manager) queueSettings() (int, time.Duration) {
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil || cfg.Routing.Queue.MaxDepth <= 0 {
+		return 0, 0
+	}
+	wait := defaultQueueMaxWait
+	if cfg.Routing.Queue.MaxWaitMs > 0 {
+		wait = time.Duration(cfg.Routing.Queue.MaxWaitMs) * time.Millisecond
+	}
+	return cfg.Routing.Queue.MaxDepth, wait
+}
+
+// pickNextQueued picks like pickNextMixed but, when routing.queue is enabled and every
+// credential group is at its limit, waits in the providers' FIFO queue for capacity.
+// Requests beyond the queue depth or waiting longer than the max wait fail with 503.
+func (m *Manager) pickNextQueued(ctx context.Context, providers []string, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, string, func(), error) {
+	depth, maxWait := m.queueSettings()
+	key := strings.Join(providers, ",")
+	if depth <= 0 || len(tried) > 0 {
+		return m.pickNextMixed(ctx, providers, model, opts, tried)
+	}
+	// Newcomers only bypass the queue while nobody is waiting.
+	if m.requestQueue.pending(key) == 0 {
+		auth, executor, provider, release, errPick := m.pickNextMixed(ctx, providers, model, opts, tried)
+		if errPick == nil || !isGroupLimitError(errPick) {
+			return auth, executor, provider, release, errPick
+		}
+	}
+
+	waiter, ok := m.requestQueue.enqueue(key, depth)
+	if !ok {
+		return nil, nil, "", nil, &queueRejectedError{providers: key, retryAfter: maxWait, full: true}
+	}
+	defer m.requestQueue.remove(key, waiter)
+	if m.requestQueue.isHead(key, waiter) {
+		waiter.signal()
+	}
+
+	timer := time.NewTimer(maxWait)
+	defer timer.Stop()
+	ticker := time.NewTicker(queuePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil, "", nil, ctx.Err()
+		case <-timer.C:
+			return nil, nil, "", nil, &queueRejectedError{providers: key, retryAfter: maxWait}
+		case <-waiter.ready:
+		case <-ticker.C:
+		}
+		if !m.requestQueue.isHead(key, waiter) {
+			continue
+		}
+		auth, executor, provider, release, errPick := m.pickNextMixed(ctx, providers, model, opts, tried)
+		if errPick == nil || !isGroupLimitError(errPick) {
+			return auth, executor, provider, release, errPick
+		}
+	}
+}
+
+// isGroupLimitError reports whether err means every credential group is at its limit.
+func isGroupLimitError(err error) bool {
+	var authErr *Error
+	return errors.As(err, &authErr) && authErr.Message == groupsAtLimitMessage
+}
+
+// queueRejectedError is returned when the request queue is full or a queued request
+// waited too long. It carries a Retry-After hint.
+type queueRejectedError struct {
+	providers  string
+	retryAfter time.Duration
+	full       bool
+}
+
+func (e *queueRejectedError) Error() string {
+	if e.full {
+		return fmt.Sprintf("request queue for provider %s is full", e.providers)
+	}
+	return fmt.Sprintf("timed out waiting in the request queue for provider %s", e.providers)
+}
+
+func (e *queueRejectedError) StatusCode() int {
+	return http.StatusServiceUnavailable
+}
+
+func (e *queueRejectedError) Headers() http.Header {
+	headers := make(http.Header)
+	seconds := int(math.Ceil(e.retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	headers.Set("Retry-After", strconv.Itoa(seconds))
+	return headers
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestManagerPickNextQueued_QueuesUpToDepth(t *testing.T) {
+	manager := NewManager(nil, nil, nil)
+	manager.RegisterExecutor(&replaceAwareExecutor{id: "gemini"})
+	manager.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{
+		Groups: []internalconfig.AuthGroup{{Name: "premium", MaxConcurrency: 1}},
+		Queue:  internalconfig.RequestQueueConfig{MaxDepth: 1, MaxWaitMs: 5000},
+	}})
+	auth := &Auth{ID: "queue-premium", Provider: "gemini", Attributes: map[string]string{"group": "premium"}}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "queue-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	type picked struct {
+		auth    *Auth
+		release func()
+		err     error
+	}
+	pick := func() picked {
+		selected, _, _, release, err := manager.pickNextQueued(context.Background(), []string{"gemini"}, "queue-model", cliproxyexecutor.Options{}, map[string]struct{}{})
+		return picked{auth: selected, release: release, err: err}
+	}
+
+	first := pick()
+	if first.err != nil {
+		t.Fatalf("first pick: %v", first.err)
+	}
+
+	queued := make(chan picked, 1)
+	go func() { queued <- pick() }()
+	deadline := time.Now().Add(2 * time.Second)
+	for manager.requestQueue.pending("gemini") != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("second request never entered the queue")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// The queue is at its depth, so a third request is rejected right away.
+	excess := pick()
+	var rejected *queueRejectedError
+	if !errors.As(excess.err, &rejected) {
+		t.Fatalf("excess pick error = %v, want queue rejection", excess.err)
+	}
+	if rejected.StatusCode() != http.StatusServiceUnavailable {
+		t.Fatalf("rejection status = %d, want 503", rejected.StatusCode())
+	}
+	if got := rejected.Headers().Get("Retry-After"); got != "5" {
+		t.Fatalf("Retry-After = %q, want 5", got)
+	}
+
+	first.release()
+	select {
+	case second := <-queued:
+		if second.err != nil || second.auth == nil || second.auth.ID != auth.ID {
+			t.Fatalf("queued pick = %+v", second)
+		}
+		second.release()
+	case <-time.After(2 * time.Second):
+		t.Fatal("queued request was not served after capacity was released")
+	}
+	if pending := manager.requestQueue.pending("gemini"); pending != 0 {
+		t.Fatalf("queue still holds %d requests", pending)
+	}
+}
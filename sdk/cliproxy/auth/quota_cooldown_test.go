@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestManagerClearQuotaCooldowns_MakesCoolingAuthSelectable(t *testing.T) {
+	manager := NewManager(nil, nil, nil)
+	manager.RegisterExecutor(&replaceAwareExecutor{id: "codex"})
+	next := time.Now().Add(time.Hour)
+	cooling := &Auth{
+		ID:       "cooldown-codex",
+		Provider: "codex",
+		Status:   StatusError,
+		ModelStates: map[string]*ModelState{
+			"cooldown-model": {
+				Status:         StatusError,
+				Unavailable:    true,
+				NextRetryAfter: next,
+				Quota:          QuotaState{Exceeded: true, Reason: "quota", NextRecoverAt: next},
+			},
+		},
+	}
+	pinned := &Auth{
+		ID:       "cooldown-codex-pinned",
+		Provider: "codex",
+		Status:   StatusError,
+		Metadata: map[string]any{"disable_cooling": false},
+		ModelStates: map[string]*ModelState{
+			"cooldown-model": {
+				Status:         StatusError,
+				Unavailable:    true,
+				NextRetryAfter: next,
+				Quota:          QuotaState{Exceeded: true, Reason: "quota", NextRecoverAt: next},
+			},
+		},
+	}
+	for _, auth := range []*Auth{cooling, pinned} {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register %s: %v", auth.ID, err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "cooldown-model"}})
+		id := auth.ID
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+
+	execute := func() error {
+		_, err := manager.Execute(context.Background(), []string{"codex"}, cliproxyexecutor.Request{Model: "cooldown-model"}, cliproxyexecutor.Options{})
+		return err
+	}
+	if err := execute(); err == nil {
+		t.Fatal("execute should fail while every auth is cooling down")
+	}
+
+	if cleared := manager.ClearQuotaCooldowns(context.Background()); cleared != 1 {
+		t.Fatalf("cleared = %d, want 1 (the auth with cooling forced on keeps its cooldown)", cleared)
+	}
+	if err := execute(); err != nil {
+		t.Fatalf("execute after clearing cooldowns: %v", err)
+	}
+	if auth, _ := manager.GetByID(pinned.ID); !auth.ModelStates["cooldown-model"].NextRetryAfter.Equal(next) {
+		t.Fatalf("auth with cooling forced on lost its cooldown: %+v", auth.ModelStates["cooldown-model"])
+	}
+}
@@ -0,0 +1,214 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// activeWindowsKey names the attribute or metadata entry holding an auth's active time windows,
+// a JSON list such as
+//
+//	[{"start":"09:00","end":"18:00","timezone":"Asia/Shanghai","days":["mon","tue","wed","thu","fri"]}]
+//
+// Outside every window the auth is not selected. An end at or before start spans midnight;
+// equal start and end cover the whole day. Days default to every day and refer to the day the
+// window starts; the timezone defaults to the server's local time.
+const activeWindowsKey = "active_windows"
+
+// activeWindowSpec is the serialized form of one active time window.
+type activeWindowSpec struct {
+	Start    string   `json:"start"`
+	End      string   `json:"end"`
+	Timezone string   `json:"timezone,omitempty"`
+	Days     []string `json:"days,omitempty"`
+}
+
+// activeWindow is one parsed daily time range during which an auth may be selected.
+type activeWindow struct {
+	start    int // minutes since midnight
+	end      int
+	location *time.Location
+	days     map[time.Weekday]bool // nil means every day
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// authSchedule caches an auth's parsed active windows so selection does not re-parse them.
+type authSchedule struct {
+	windows []activeWindow
+	// invalid is the parse error of windows that were ignored, kept so it is logged once.
+	invalid string
+}
+
+// parseSchedule parses the auth's active windows and caches them on the auth. It runs when
+// the manager stores an auth; prev is the schedule of the record being replaced, if any.
+// Invalid windows are logged unless prev already reported the same error.
+func (a *Auth) parseSchedule(prev *authSchedule) {
+	if a == nil {
+		return
+	}
+	windows, err := authActiveWindows(a)
+	if err != nil {
+		schedule := &authSchedule{invalid: err.Error()}
+		if prev == nil || prev.invalid != schedule.invalid {
+			log.Warnf("auth %s: ignoring invalid %s: %v", a.ID, activeWindowsKey, err)
+		}
+		a.schedule = schedule
+		return
+	}
+	a.schedule = &authSchedule{windows: windows}
+}
+
+// authActiveAt reports whether now falls inside one of the auth's active windows. Auths without
+// windows, or whose windows cannot be parsed, are always active.
+func authActiveAt(auth *Auth, now time.Time) bool {
+	if auth == nil {
+		return true
+	}
+	schedule := auth.schedule
+	if schedule == nil {
+		// Not stored by a manager yet; parse without caching.
+		windows, err := authActiveWindows(auth)
+		if err != nil {
+			return true
+		}
+		schedule = &authSchedule{windows: windows}
+	}
+	if len(schedule.windows) == 0 {
+		return true
+	}
+	for _, window := range schedule.windows {
+		if window.contains(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// authActiveWindows reads the active windows from the auth attributes, falling back to metadata.
+func authActiveWindows(auth *Auth) ([]activeWindow, error) {
+	if auth == nil {
+		return nil, nil
+	}
+	var raw []byte
+	if value := strings.TrimSpace(auth.Attributes[activeWindowsKey]); value != "" {
+		raw = []byte(value)
+	} else if value, ok := auth.Metadata[activeWindowsKey]; ok && value != nil {
+		if text, isString := value.(string); isString {
+			raw = []byte(text)
+		} else {
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			raw = encoded
+		}
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var specs []activeWindowSpec
+	if err := json.Unmarshal(raw, &specs); err != nil {
+		return nil, err
+	}
+	windows := make([]activeWindow, 0, len(specs))
+	for i, spec := range specs {
+		window, err := parseActiveWindow(spec)
+		if err != nil {
+			return nil, fmt.Errorf("window %d: %w", i, err)
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+func parseActiveWindow(spec activeWindowSpec) (activeWindow, error) {
+	var window activeWindow
+	var err error
+	if window.start, err = parseClockMinutes(spec.Start); err != nil {
+		return window, fmt.Errorf("start: %w", err)
+	}
+	if window.end, err = parseClockMinutes(spec.End); err != nil {
+		return window, fmt.Errorf("end: %w", err)
+	}
+	window.location = time.Local
+	if tz := strings.TrimSpace(spec.Timezone); tz != "" {
+		if window.location, err = loadLocation(tz); err != nil {
+			return window, err
+		}
+	}
+	for _, day := range spec.Days {
+		name := strings.ToLower(strings.TrimSpace(day))
+		if len(name) > 3 {
+			name = name[:3]
+		}
+		weekday, ok := weekdayNames[name]
+		if !ok {
+			return window, fmt.Errorf("unknown day %q", day)
+		}
+		if window.days == nil {
+			window.days = make(map[time.Weekday]bool, len(spec.Days))
+		}
+		window.days[weekday] = true
+	}
+	return window, nil
+}
+
+// locationCache memoizes time.LoadLocation, which reads the zoneinfo database on every call.
+var locationCache sync.Map // name -> locationResult
+
+type locationResult struct {
+	location *time.Location
+	err      error
+}
+
+func loadLocation(name string) (*time.Location, error) {
+	if cached, ok := locationCache.Load(name); ok {
+		result := cached.(locationResult)
+		return result.location, result.err
+	}
+	location, err := time.LoadLocation(name)
+	locationCache.Store(name, locationResult{location: location, err: err})
+	return location, err
+}
+
+// parseClockMinutes parses "HH:MM" into minutes since midnight. "24:00" is accepted as the
+// end of the day.
+func parseClockMinutes(value string) (int, error) {
+	var hours, minutes int
+	if _, err := fmt.Sscanf(strings.TrimSpace(value), "%d:%d", &hours, &minutes); err != nil {
+		return 0, fmt.Errorf("invalid time %q, want HH:MM", value)
+	}
+	if hours < 0 || minutes < 0 || minutes > 59 || hours > 24 || (hours == 24 && minutes != 0) {
+		return 0, fmt.Errorf("invalid time %q, want HH:MM", value)
+	}
+	return hours*60 + minutes, nil
+}
+
+// contains reports whether now falls inside the window.
+func (w activeWindow) contains(now time.Time) bool {
+	local := now.In(w.location)
+	minute := local.Hour()*60 + local.Minute()
+	if w.start < w.end {
+		return w.onDay(local.Weekday()) && minute >= w.start && minute < w.end
+	}
+	// The window spans midnight (or the whole day when start equals end): the part after
+	// start belongs to today, the part before end to a window that started yesterday.
+	if minute >= w.start && w.onDay(local.Weekday()) {
+		return true
+	}
+	yesterday := local.AddDate(0, 0, -1).Weekday()
+	return minute < w.end && w.onDay(yesterday)
+}
+
+func (w activeWindow) onDay(day time.Weekday) bool {
+	return w.days == nil || w.days[day]
+}
@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestGetAvailableAuths_RespectsActiveWindows(t *testing.T) {
+	t.Parallel()
+
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	auths := []*Auth{
+		// Weekday office hours in Shanghai, plus a late window that spans midnight.
+		{ID: "office", Metadata: map[string]any{"active_windows": []any{
+			map[string]any{"start": "09:00", "end": "18:00", "timezone": "Asia/Shanghai", "days": []any{"mon", "tue", "wed", "thu", "fri"}},
+			map[string]any{"start": "22:00", "end": "02:00", "timezone": "Asia/Shanghai"},
+		}}},
+		{ID: "always"},
+	}
+
+	cases := []struct {
+		name   string
+		now    time.Time
+		wantIn bool
+	}{
+		{"weekday office hours", time.Date(2026, 10, 14, 10, 30, 0, 0, shanghai), true},
+		{"weekday evening", time.Date(2026, 10, 14, 19, 0, 0, 0, shanghai), false},
+		{"saturday office hours", time.Date(2026, 10, 17, 10, 30, 0, 0, shanghai), false},
+		{"after midnight", time.Date(2026, 10, 15, 1, 30, 0, 0, shanghai), true},
+		{"same instant in UTC", time.Date(2026, 10, 14, 2, 30, 0, 0, time.UTC), true},
+	}
+	for _, tc := range cases {
+		available, errPick := getAvailableAuths(auths, "gemini", "", tc.now, false)
+		if errPick != nil {
+			t.Fatalf("%s: getAvailableAuths() error = %v", tc.name, errPick)
+		}
+		gotIn := false
+		for _, auth := range available {
+			if auth.ID == "office" {
+				gotIn = true
+			}
+		}
+		if gotIn != tc.wantIn {
+			t.Fatalf("%s: office selectable = %v, want %v", tc.name, gotIn, tc.wantIn)
+		}
+	}
+
+	outside := time.Date(2026, 10, 14, 19, 0, 0, 0, shanghai)
+	if _, err = getAvailableAuths(auths[:1], "gemini", "", outside, false); err == nil {
+		t.Fatal("auth outside its window was selectable")
+	}
+
+	attr := &Auth{ID: "attr", Attributes: map[string]string{"active_windows": `[{"start":"08:00","end":"12:00","timezone":"UTC"}]`}}
+	if !authActiveAt(attr, time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)) || authActiveAt(attr, time.Date(2026, 10, 14, 13, 0, 0, 0, time.UTC)) {
+		t.Fatal("attribute windows not honored")
+	}
+	invalid := &Auth{ID: "invalid", Attributes: map[string]string{"active_windows": `[{"start":"9am","end":"18:00"}]`}}
+	if !authActiveAt(invalid, outside) {
+		t.Fatal("an invalid schedule should leave the auth active")
+	}
+}
+
+func TestManagerCachesActiveWindowsAndLogsInvalidOnce(t *testing.T) {
+	hook := test.NewLocal(log.StandardLogger())
+	manager := NewManager(nil, nil, nil)
+	ctx := context.Background()
+	at := func(hour int) time.Time { return time.Date(2026, 10, 14, hour, 0, 0, 0, time.UTC) }
+	stored := func(id string) *Auth {
+		manager.mu.RLock()
+		defer manager.mu.RUnlock()
+		return manager.auths[id]
+	}
+
+	auth := &Auth{ID: "sched-cached", Attributes: map[string]string{"active_windows": `[{"start":"08:00","end":"12:00","timezone":"UTC"}]`}}
+	if _, err := manager.Register(ctx, auth); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	current := stored("sched-cached")
+	// Selection uses the windows parsed at registration, not the raw attribute.
+	current.Attributes["active_windows"] = `[{"start":"12:00","end":"16:00","timezone":"UTC"}]`
+	if !authActiveAt(current, at(9)) || authActiveAt(current, at(13)) {
+		t.Fatal("selection did not use the windows cached at registration")
+	}
+	if _, err := manager.Update(ctx, current.Clone()); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if current = stored("sched-cached"); authActiveAt(current, at(9)) || !authActiveAt(current, at(13)) {
+		t.Fatal("Update did not re-parse the active windows")
+	}
+
+	invalid := &Auth{ID: "sched-invalid", Attributes: map[string]string{"active_windows": `[{"start":"9am","end":"18:00"}]`}}
+	if _, err := manager.Register(ctx, invalid); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := manager.Update(ctx, stored("sched-invalid").Clone()); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if !authActiveAt(stored("sched-invalid"), at(20)) {
+			t.Fatal("an invalid schedule should leave the auth active")
+		}
+	}
+	warnings := 0
+	for _, entry := range hook.AllEntries() {
+		if strings.Contains(entry.Message, "sched-invalid") {
+			warnings++
+		}
+	}
+	if warnings != 1 {
+		t.Fatalf("invalid windows logged %d times, want once", warnings)
+	}
+}
@@ -135,11 +135,22 @@ func canonicalModelKey(model string) string {
 }
 
 func authWebsocketsEnabled(auth *Auth) bool {
+	return authBoolFlag(auth, "websockets")
+}
+
+// authStandby reports whether auth is on standby: enabled, but left out of normal
+// rotation. A standby auth is only selected when a request pins it.
+func authStandby(auth *Auth) bool {
+	return authBoolFlag(auth, "standby")
+}
+
+// authBoolFlag reads a boolean flag from the auth attributes, falling back to metadata.
+func authBoolFlag(auth *Auth, key string) bool {
 	if auth == nil {
 		return false
 	}
 	if len(auth.Attributes) > 0 {
-		if raw := strings.TrimSpace(auth.Attributes["websockets"]); raw != "" {
+		if raw := strings.TrimSpace(auth.Attributes[key]); raw != "" {
 			parsed, errParse := strconv.ParseBool(raw)
 			if errParse == nil {
 				return parsed
@@ -149,7 +160,7 @@ func authWebsocketsEnabled(auth *Auth) bool {
 	if len(auth.Metadata) == 0 {
 		return false
 	}
-	raw, ok := auth.Metadata["websockets"]
+	raw, ok := auth.Metadata[key]
 	if !ok || raw == nil {
 		return false
 	}
@@ -190,10 +201,13 @@ func preferCodexWebsocketAuths(ctx context.Context, provider string, available [
 	return available
 }
 
-func collectAvailableByPriority(auths []*Auth, model string, now time.Time) (available map[int][]*Auth, cooldownCount int, earliest time.Time) {
+func collectAvailableByPriority(auths []*Auth, model string, now time.Time, includeStandby bool) (available map[int][]*Auth, cooldownCount int, earliest time.Time) {
 	available = make(map[int][]*Auth)
 	for i := 0; i < len(auths); i++ {
 		candidate := auths[i]
+		if !includeStandby && authStandby(candidate) {
+			continue
+		}
 		blocked, reason, next := isAuthBlockedForModel(candidate, model, now)
 		if !blocked {
 			priority := authPriority(candidate)
@@ -210,12 +224,14 @@ func collectAvailableByPriority(auths []*Auth, model string, now time.Time) (ava
 	return available, cooldownCount, earliest
 }
 
-func getAvailableAuths(auths []*Auth, provider, model string, now time.Time) ([]*Auth, error) {
+// getAvailableAuths returns the unblocked auths of the highest priority. Standby auths are
+// skipped unless includeStandby is set, which callers do for pinned requests.
+func getAvailableAuths(auths []*Auth, provider, model string, now time.Time, includeStandby bool) ([]*Auth, error) {
 	if len(auths) == 0 {
 		return nil, &Error{Code: "auth_not_found", Message: "no auth candidates"}
 	}
 
-	availableByPriority, cooldownCount, earliest := collectAvailableByPriority(auths, model, now)
+	availableByPriority, cooldownCount, earliest := collectAvailableByPriority(auths, model, now, includeStandby)
 	if len(availableByPriority) == 0 {
 		if cooldownCount == len(auths) && !earliest.IsZero() {
 			providerForError := provider
@@ -249,9 +265,8 @@ func getAvailableAuths(auths []*Auth, provider, model string, now time.Time) ([]
 
 // Pick selects the next available auth for the provider in a round-robin manner.
 func (s *RoundRobinSelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
-	_ = opts
 	now := time.Now()
-	available, err := getAvailableAuths(auths, provider, model, now)
+	available, err := getAvailableAuths(auths, provider, model, now, pinnedAuthIDFromMetadata(opts.Metadata) != "")
 	if err != nil {
 		return nil, err
 	}
@@ -280,11 +295,26 @@ func (s *RoundRobinSelector) Pick(ctx context.Context, provider, model string, o
 	return available[index%len(available)], nil
 }
 
+// Peek returns the auth Pick would select next without advancing the cursor.
+func (s *RoundRobinSelector) Peek(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	available, err := getAvailableAuths(auths, provider, model, time.Now(), pinnedAuthIDFromMetadata(opts.Metadata) != "")
+	if err != nil {
+		return nil, err
+	}
+	available = preferCodexWebsocketAuths(ctx, provider, available)
+	s.mu.Lock()
+	index := s.cursors[provider+":"+canonicalModelKey(model)]
+	s.mu.Unlock()
+	if index >= 2_147_483_640 {
+		index = 0
+	}
+	return available[index%len(available)], nil
+}
+
 // Pick selects the first available auth for the provider in a deterministic manner.
 func (s *FillFirstSelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
-	_ = opts
 	now := time.Now()
-	available, err := getAvailableAuths(auths, provider, model, now)
+	available, err := getAvailableAuths(auths, provider, model, now, pinnedAuthIDFromMetadata(opts.Metadata) != "")
 	if err != nil {
 		return nil, err
 	}
@@ -292,6 +322,11 @@ func (s *FillFirstSelector) Pick(ctx context.Context, provider, model string, op
 	return available[0], nil
 }
 
+// Peek returns the auth Pick would select; fill-first selection has no state to advance.
+func (s *FillFirstSelector) Peek(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	return s.Pick(ctx, provider, model, opts, auths)
+}
+
 func isAuthBlockedForModel(auth *Auth, model string, now time.Time) (bool, blockReason, time.Time) {
 	if auth == nil {
 		return true, blockReasonOther, time.Time{}
@@ -299,6 +334,10 @@ func isAuthBlockedForModel(auth *Auth, model string, now time.Time) (bool, block
 	if auth.Disabled || auth.Status == StatusDisabled {
 		return true, blockReasonDisabled, time.Time{}
 	}
+	if !authActiveAt(auth, now) {
+		// Outside its active windows; no retry time so callers never wait for the window.
+		return true, blockReasonOther, time.Time{}
+	}
 	if model != "" {
 		if len(auth.ModelStates) > 0 {
 			state, ok := auth.ModelStates[model]
@@ -87,6 +87,40 @@ func TestRoundRobinSelectorPick_PriorityBuckets(t *testing.T) {
 	}
 }
 
+func TestSelectorPick_SkipsStandbyUnlessPinned(t *testing.T) {
+	t.Parallel()
+
+	auths := []*Auth{
+		{ID: "a", Attributes: map[string]string{"priority": "10"}, Metadata: map[string]any{"standby": true}},
+		{ID: "b"},
+		{ID: "c"},
+	}
+
+	roundRobin := &RoundRobinSelector{}
+	for i, id := range []string{"b", "c", "b"} {
+		got, err := roundRobin.Pick(context.Background(), "gemini", "", cliproxyexecutor.Options{}, auths)
+		if err != nil {
+			t.Fatalf("RoundRobin Pick() #%d error = %v", i, err)
+		}
+		if got.ID != id {
+			t.Fatalf("RoundRobin Pick() #%d auth.ID = %q, want %q", i, got.ID, id)
+		}
+	}
+	got, err := (&FillFirstSelector{}).Pick(context.Background(), "gemini", "", cliproxyexecutor.Options{}, auths)
+	if err != nil || got.ID != "b" {
+		t.Fatalf("FillFirst Pick() = %v, %v; want b", got, err)
+	}
+
+	standbyOnly := auths[:1]
+	if _, err = roundRobin.Pick(context.Background(), "gemini", "", cliproxyexecutor.Options{}, standbyOnly); err == nil {
+		t.Fatalf("Pick() selected a standby auth without pinning")
+	}
+	pinned := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.PinnedAuthMetadataKey: "a"}}
+	if got, err = roundRobin.Pick(context.Background(), "gemini", "", pinned, standbyOnly); err != nil || got.ID != "a" {
+		t.Fatalf("pinned Pick() = %v, %v; want the standby auth", got, err)
+	}
+}
+
 func TestFillFirstSelectorPick_PriorityFallbackCooldown(t *testing.T) {
 	t.Parallel()
 
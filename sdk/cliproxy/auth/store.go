@@ -11,3 +11,9 @@ type Store interface {
 	// Delete removes the auth record identified by id.
 	Delete(ctx context.Context, id string) error
 }
+
+// StoreFlusher is implemented by stores that buffer writes and need an explicit flush
+// before shutdown. Manager.Close calls Flush when the configured store supports it.
+type StoreFlusher interface {
+	Flush(ctx context.Context) error
+}
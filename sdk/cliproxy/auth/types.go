@@ -63,6 +63,8 @@ type Auth struct {
 	Runtime any `json:"-"`
 
 	indexAssigned bool `json:"-"`
+	// schedule caches the parsed active windows; see parseSchedule.
+	schedule *authSchedule
 }
 
 // QuotaState contains limiter tracking data for a credential.
@@ -7,12 +7,16 @@ import (
 	"fmt"
 	"strings"
 
+	certaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/cert_access"
 	configaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/config_access"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/api"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/moderation"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/transform"
+	log "github.com/sirupsen/logrus"
 )
 
 // Builder constructs a Service instance with customizable providers.
@@ -48,6 +52,9 @@ type Builder struct {
 
 	// serverOptions contains additional server configuration options.
 	serverOptions []api.ServerOption
+
+	// store is the token store registered at build time, if any.
+	store coreauth.Store
 }
 
 // Hooks allows callers to plug into service lifecycle stages.
@@ -153,6 +160,20 @@ func (b *Builder) WithLocalManagementPassword(password string) *Builder {
 	return b
 }
 
+// WithTransform registers a named request/response transform. It only runs when its name
+// is listed under "transforms" in the configuration.
+func (b *Builder) WithTransform(name string, t transform.Transform) *Builder {
+	transform.Register(name, t)
+	return b
+}
+
+// WithModerator registers a named moderator. It only runs when "moderation.moderator" in
+// the configuration names it.
+func (b *Builder) WithModerator(name string, m moderation.Moderator) *Builder {
+	moderation.Register(name, m)
+	return b
+}
+
 // Build validates inputs, applies defaults, and returns a ready-to-run service.
 func (b *Builder) Build() (*Service, error) {
 	if b.cfg == nil {
@@ -188,8 +209,29 @@ func (b *Builder) Build() (*Service, error) {
 	}
 
 	configaccess.Register(&b.cfg.SDKConfig)
+	certaccess.Register(b.cfg.TLS)
+	if _, missing := transform.Resolve(b.cfg.Transforms); len(missing) > 0 {
+		log.Warnf("configured transforms are not registered and will be skipped: %v", missing)
+	}
+	if name := strings.TrimSpace(b.cfg.Moderation.Moderator); name != "" && name != moderation.KeywordsModerator {
+		if _, ok := moderation.Lookup(name); !ok {
+			log.Warnf("configured moderator %q is not registered; moderation is disabled", name)
+		}
+	}
 	accessManager.SetProviders(sdkaccess.RegisteredProviders())
 
+	if b.store != nil {
+		sdkAuth.RegisterTokenStore(b.store)
+		if dirProvider, ok := b.store.(interface{ AuthDir() string }); ok {
+			if dir := strings.TrimSpace(dirProvider.AuthDir()); dir != "" {
+				b.cfg.AuthDir = dir
+			}
+		}
+		if dirSetter, ok := b.store.(interface{ SetBaseDir(string) }); ok {
+			dirSetter.SetBaseDir(b.cfg.AuthDir)
+		}
+	}
+
 	coreManager := b.coreManager
 	if coreManager == nil {
 		tokenStore := sdkAuth.GetTokenStore()
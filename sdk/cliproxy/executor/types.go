@@ -1,6 +1,7 @@
 package executor
 
 import (
+	"errors"
 	"net/http"
 	"net/url"
 
@@ -21,6 +22,11 @@ const (
 	ExecutionSessionMetadataKey = "execution_session_id"
 )
 
+// ErrCountTokensUnsupported is returned (optionally wrapped) by CountTokens when the
+// provider has no token counting. The API then answers with a local estimate instead
+// of an error.
+var ErrCountTokensUnsupported = errors.New("count tokens not supported by provider")
+
 // Request encapsulates the translated payload that will be sent to a provider executor.
 type Request struct {
 	// Model is the upstream model identifier after translation.
@@ -15,7 +15,10 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/api"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	geminicommon "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/common"
 	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/wsrelay"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
@@ -23,6 +26,7 @@ import (
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -87,6 +91,10 @@ type Service struct {
 	// shutdownOnce ensures shutdown is called only once.
 	shutdownOnce sync.Once
 
+	// shutdownReason records why Run stopped; it is logged by Shutdown.
+	shutdownMu     sync.Mutex
+	shutdownReason string
+
 	// wsGateway manages websocket Gemini providers.
 	wsGateway *wsrelay.Manager
 }
@@ -331,6 +339,20 @@ func (s *Service) applyCoreAuthRemoval(ctx context.Context, id string) {
 	}
 }
 
+// startSecretDirLoader registers credentials from the read-only secret directory and keeps
+// them in sync through the auth update queue.
+func (s *Service) startSecretDirLoader(ctx context.Context, dir string) error {
+	if resolved, errResolve := util.ResolveAuthDir(dir); errResolve == nil && resolved != "" {
+		dir = resolved
+	}
+	loader := sdkAuth.NewSecretDirLoader(dir, s.cfg)
+	return loader.Watch(ctx, func(auth *coreauth.Auth) {
+		s.emitAuthUpdate(ctx, watcher.AuthUpdate{Action: watcher.AuthUpdateActionAdd, ID: auth.ID, Auth: auth})
+	}, func(id string) {
+		s.emitAuthUpdate(ctx, watcher.AuthUpdate{Action: watcher.AuthUpdateActionDelete, ID: id})
+	})
+}
+
 func (s *Service) applyRetryConfig(cfg *config.Config) {
 	if s == nil || s.coreManager == nil || cfg == nil {
 		return
@@ -339,6 +361,100 @@ func (s *Service) applyRetryConfig(cfg *config.Config) {
 	s.coreManager.SetRetryConfig(cfg.RequestRetry, maxInterval)
 }
 
+func (s *Service) applyThinkingConfig(cfg *config.Config) {
+	if s == nil || cfg == nil {
+		return
+	}
+	thinking.SetMalformedSuffixAction(cfg.Thinking.MalformedSuffix)
+	thinking.SetBudgetMaxTokensRatio(cfg.Thinking.BudgetMaxTokensRatio)
+	thinking.SetAutoFallbackPolicy(cfg.Thinking.AutoFallback)
+}
+
+func (s *Service) applyTranslatorConfig(cfg *config.Config) {
+	if s == nil || cfg == nil {
+		return
+	}
+	util.SetToolArgsOnTruncation(cfg.Streaming.ToolArgsOnTruncation)
+	util.SetClaudeAvgLogprobsLocation(cfg.Claude.IncludeAvgLogprobs)
+	safetySettings := make([]map[string]string, 0, len(cfg.GeminiSafetySettings))
+	for _, setting := range cfg.GeminiSafetySettings {
+		safetySettings = append(safetySettings, map[string]string{
+			"category":  strings.TrimSpace(setting.Category),
+			"threshold": strings.TrimSpace(setting.Threshold),
+		})
+	}
+	geminicommon.SetDefaultSafetySettings(safetySettings)
+	targets := make([]sdktranslator.Format, 0, len(cfg.MergeConsecutiveRoles))
+	for _, target := range cfg.MergeConsecutiveRoles {
+		targets = append(targets, sdktranslator.FromString(target))
+	}
+	sdktranslator.SetMergeConsecutiveRoles(targets...)
+	sdktranslator.SetUnknownFieldActions(cfg.UnknownRequestFields)
+}
+
+// translatorSelfTestClients are the client formats served by the public API handlers.
+var translatorSelfTestClients = []sdktranslator.Format{
+	sdktranslator.FormatOpenAI,
+	sdktranslator.FormatOpenAIResponse,
+	sdktranslator.FormatClaude,
+	sdktranslator.FormatGemini,
+}
+
+// translatorSelfTestProviders are the formats spoken by the built-in executors.
+var translatorSelfTestProviders = []sdktranslator.Format{
+	sdktranslator.FormatOpenAI,
+	sdktranslator.FormatClaude,
+	sdktranslator.FormatCodex,
+	sdktranslator.FormatGemini,
+	sdktranslator.FormatGeminiCLI,
+	sdktranslator.FormatAntigravity,
+}
+
+// runTranslatorSelfTest checks the registered translators per translator-self-test and
+// returns an error only in "fail" mode.
+func runTranslatorSelfTest(cfg *config.Config) error {
+	if cfg == nil {
+		return nil
+	}
+	mode := strings.ToLower(strings.TrimSpace(cfg.TranslatorSelfTest))
+	if mode == "" || mode == "off" {
+		return nil
+	}
+	missing := sdktranslator.CheckPairs(translatorSelfTestClients, translatorSelfTestProviders)
+	if len(missing) == 0 {
+		log.Info("translator self-test passed")
+		return nil
+	}
+	pairs := make([]string, 0, len(missing))
+	for _, pair := range missing {
+		pairs = append(pairs, pair.String())
+	}
+	msg := fmt.Sprintf("translator self-test: missing translators: %s", strings.Join(pairs, "; "))
+	if mode == "fail" {
+		return fmt.Errorf("cliproxy: %s", msg)
+	}
+	log.Warn(msg)
+	return nil
+}
+
+func (s *Service) applyDeclaredModels(cfg *config.Config) {
+	if s == nil || cfg == nil {
+		return
+	}
+	models := make([]*ModelInfo, 0, len(cfg.DeclaredModels))
+	for _, declared := range cfg.DeclaredModels {
+		models = append(models, &ModelInfo{
+			ID:          declared.ID,
+			Object:      "model",
+			OwnedBy:     declared.OwnedBy,
+			Type:        declared.Type,
+			DisplayName: declared.DisplayName,
+			Description: declared.Description,
+		})
+	}
+	registry.GetGlobalRegistry().SetDeclaredModels(models)
+}
+
 func openAICompatInfoFromAuth(a *coreauth.Auth) (providerKey string, compatName string, ok bool) {
 	if a == nil {
 		return "", "", false
@@ -477,6 +593,12 @@ func (s *Service) Run(ctx context.Context) error {
 	}
 
 	s.applyRetryConfig(s.cfg)
+	s.applyThinkingConfig(s.cfg)
+	s.applyTranslatorConfig(s.cfg)
+	if err := runTranslatorSelfTest(s.cfg); err != nil {
+		return err
+	}
+	s.applyDeclaredModels(s.cfg)
 
 	if s.coreManager != nil {
 		if errLoad := s.coreManager.Load(ctx); errLoad != nil {
@@ -509,6 +631,8 @@ func (s *Service) Run(ctx context.Context) error {
 		s.authManager = newDefaultAuthManager()
 	}
 
+	s.server.SetAuthModelRefresher(s.refreshModelsForAuth)
+
 	s.ensureWebsocketGateway()
 	if s.server != nil && s.wsGateway != nil {
 		s.server.AttachWebsocketRoute(s.wsGateway.Path(), s.wsGateway.Handler())
@@ -593,6 +717,9 @@ func (s *Service) Run(ctx context.Context) error {
 		}
 
 		s.applyRetryConfig(newCfg)
+		s.applyThinkingConfig(newCfg)
+		s.applyTranslatorConfig(newCfg)
+		s.applyDeclaredModels(newCfg)
 		s.applyPprofConfig(newCfg)
 		if s.server != nil {
 			s.server.UpdateClients(newCfg)
@@ -625,6 +752,14 @@ func (s *Service) Run(ctx context.Context) error {
 	}
 	log.Info("file watcher started for config and auth directory changes")
 
+	if dir := strings.TrimSpace(s.cfg.AuthSecretDir); dir != "" {
+		if errSecrets := s.startSecretDirLoader(watcherCtx, dir); errSecrets != nil {
+			log.Errorf("failed to watch auth secret directory: %v", errSecrets)
+		} else {
+			log.Infof("watching read-only auth secret directory: %s", dir)
+		}
+	}
+
 	// Prefer core auth manager auto refresh if available.
 	if s.coreManager != nil {
 		interval := 15 * time.Minute
@@ -635,12 +770,23 @@ func (s *Service) Run(ctx context.Context) error {
 	select {
 	case <-ctx.Done():
 		log.Debug("service context cancelled, shutting down...")
+		s.setShutdownReason(fmt.Sprintf("context done: %v", context.Cause(ctx)))
 		return ctx.Err()
 	case err = <-s.serverErr:
+		s.setShutdownReason(fmt.Sprintf("server error: %v", err))
 		return err
 	}
 }
 
+// setShutdownReason records the first reason the service is stopping.
+func (s *Service) setShutdownReason(reason string) {
+	s.shutdownMu.Lock()
+	defer s.shutdownMu.Unlock()
+	if s.shutdownReason == "" {
+		s.shutdownReason = reason
+	}
+}
+
 // Shutdown gracefully stops background workers and the HTTP server.
 // It ensures all resources are properly cleaned up and connections are closed.
 // The shutdown is idempotent and can be called multiple times safely.
@@ -659,14 +805,20 @@ func (s *Service) Shutdown(ctx context.Context) error {
 		if ctx == nil {
 			ctx = context.Background()
 		}
-
-		// legacy refresh loop removed; only stopping core auth manager below
+		s.setShutdownReason("shutdown requested")
+		s.shutdownMu.Lock()
+		reason := s.shutdownReason
+		s.shutdownMu.Unlock()
+		log.WithField("reason", reason).Info("shutting down service")
 
 		if s.watcherCancel != nil {
 			s.watcherCancel()
 		}
 		if s.coreManager != nil {
-			s.coreManager.StopAutoRefresh()
+			if err := s.coreManager.Close(ctx); err != nil {
+				log.Errorf("failed to close core auth manager: %v", err)
+				shutdownErr = err
+			}
 		}
 		if s.watcher != nil {
 			if err := s.watcher.Stop(); err != nil {
@@ -697,7 +849,12 @@ func (s *Service) Shutdown(ctx context.Context) error {
 		// no legacy clients to persist
 
 		if s.server != nil {
-			shutdownCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			// The grace period starts once the drain delay has passed.
+			shutdownTimeout := 30 * time.Second
+			if s.cfg != nil {
+				shutdownTimeout += time.Duration(s.cfg.ShutdownDrainDelaySeconds) * time.Second
+			}
+			shutdownCtx, cancel := context.WithTimeout(ctx, shutdownTimeout)
 			defer cancel()
 			if err := s.server.Stop(shutdownCtx); err != nil {
 				log.Errorf("error stopping API server: %v", err)
@@ -903,6 +1060,8 @@ func (s *Service) registerModelsForAuth(a *coreauth.Auth) {
 						if providerKey == "" {
 							providerKey = "openai-compatibility"
 						}
+						ms = applyThinkingDefaults(s.cfg, ms)
+						ms = applyModelWeights(s.cfg, ms)
 						GlobalModelRegistry().RegisterClient(a.ID, providerKey, applyModelPrefixes(ms, a.Prefix, s.cfg.ForceModelPrefix))
 					} else {
 						// Ensure stale registrations are cleared when model list becomes empty.
@@ -919,6 +1078,9 @@ func (s *Service) registerModelsForAuth(a *coreauth.Auth) {
 		}
 	}
 	models = applyOAuthModelAlias(s.cfg, provider, authKind, models)
+	models = applyAuthModelRenames(a, models)
+	models = applyThinkingDefaults(s.cfg, models)
+	models = applyModelWeights(s.cfg, models)
 	if len(models) > 0 {
 		key := provider
 		if key == "" {
@@ -931,6 +1093,16 @@ func (s *Service) registerModelsForAuth(a *coreauth.Auth) {
 	GlobalModelRegistry().UnregisterClient(a.ID)
 }
 
+// refreshModelsForAuth re-registers the models of a single auth, re-querying the upstream
+// for providers that list models dynamically, and returns the resulting registration.
+func (s *Service) refreshModelsForAuth(a *coreauth.Auth) []*ModelInfo {
+	if a == nil {
+		return nil
+	}
+	s.registerModelsForAuth(a)
+	return registry.GetGlobalRegistry().GetModelsForClient(a.ID)
+}
+
 func (s *Service) resolveConfigClaudeKey(auth *coreauth.Auth) *config.ClaudeKey {
 	if auth == nil || s.cfg == nil {
 		return nil
@@ -1146,6 +1318,66 @@ func applyModelPrefixes(models []*ModelInfo, prefix string, forceModelPrefix boo
 	return out
 }
 
+// applyThinkingDefaults sets DefaultThinking on models matching a configured thinking default.
+// Matching models are cloned so shared static definitions are never mutated.
+func applyThinkingDefaults(cfg *config.Config, models []*ModelInfo) []*ModelInfo {
+	if cfg == nil || len(cfg.ThinkingDefaults) == 0 || len(models) == 0 {
+		return models
+	}
+	out := make([]*ModelInfo, 0, len(models))
+	for _, model := range models {
+		if model == nil {
+			continue
+		}
+		id := strings.ToLower(strings.TrimSpace(model.ID))
+		matched := false
+		for _, entry := range cfg.ThinkingDefaults {
+			if !matchWildcard(strings.ToLower(entry.Model), id) {
+				continue
+			}
+			clone := *model
+			clone.DefaultThinking = entry.Thinking
+			out = append(out, &clone)
+			matched = true
+			break
+		}
+		if !matched {
+			out = append(out, model)
+		}
+	}
+	return out
+}
+
+// applyModelWeights sets ConcurrencyWeight on models matching a configured routing weight.
+// Matching models are cloned so shared static definitions are never mutated.
+func applyModelWeights(cfg *config.Config, models []*ModelInfo) []*ModelInfo {
+	if cfg == nil || len(cfg.Routing.ModelWeights) == 0 || len(models) == 0 {
+		return models
+	}
+	out := make([]*ModelInfo, 0, len(models))
+	for _, model := range models {
+		if model == nil {
+			continue
+		}
+		id := strings.ToLower(strings.TrimSpace(model.ID))
+		matched := false
+		for _, entry := range cfg.Routing.ModelWeights {
+			if entry.Weight < 1 || !matchWildcard(strings.ToLower(entry.Model), id) {
+				continue
+			}
+			clone := *model
+			clone.ConcurrencyWeight = entry.Weight
+			out = append(out, &clone)
+			matched = true
+			break
+		}
+		if !matched {
+			out = append(out, model)
+		}
+	}
+	return out
+}
+
 // matchWildcard performs case-insensitive wildcard matching where '*' matches any substring.
 func matchWildcard(pattern, value string) bool {
 	if pattern == "" {
@@ -1294,6 +1526,35 @@ func rewriteModelInfoName(name, oldID, newID string) string {
 	return name
 }
 
+// applyAuthModelRenames lists models under the names from the auth's model_rename metadata.
+func applyAuthModelRenames(a *coreauth.Auth, models []*ModelInfo) []*ModelInfo {
+	renames := coreauth.ModelRenames(a)
+	if len(renames) == 0 || len(models) == 0 {
+		return models
+	}
+	out := make([]*ModelInfo, 0, len(models))
+	for _, model := range models {
+		if model == nil {
+			continue
+		}
+		renamed := model
+		for upstream, name := range renames {
+			if !strings.EqualFold(upstream, strings.TrimSpace(model.ID)) {
+				continue
+			}
+			clone := *model
+			clone.ID = name
+			if clone.Name != "" {
+				clone.Name = rewriteModelInfoName(clone.Name, model.ID, name)
+			}
+			renamed = &clone
+			break
+		}
+		out = append(out, renamed)
+	}
+	return out
+}
+
 func applyOAuthModelAlias(cfg *config.Config, provider, authKind string, models []*ModelInfo) []*ModelInfo {
 	if cfg == nil || len(models) == 0 {
 		return models
@@ -0,0 +1,32 @@
+package cliproxy
+
+import (
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func TestApplyThinkingDefaults_FirstMatchWinsAndClones(t *testing.T) {
+	cfg := &config.Config{
+		ThinkingDefaults: []config.ThinkingDefault{
+			{Model: "gpt-5*", Thinking: "high"},
+			{Model: "gpt-5-mini", Thinking: "low"},
+		},
+	}
+	shared := &ModelInfo{ID: "gpt-5-mini"}
+	other := &ModelInfo{ID: "claude-sonnet-4-5"}
+
+	out := applyThinkingDefaults(cfg, []*ModelInfo{shared, other})
+	if len(out) != 2 {
+		t.Fatalf("expected 2 models, got %d", len(out))
+	}
+	if out[0].DefaultThinking != "high" {
+		t.Fatalf("DefaultThinking = %q, want %q", out[0].DefaultThinking, "high")
+	}
+	if shared.DefaultThinking != "" {
+		t.Fatalf("expected source model to stay unmodified, got %q", shared.DefaultThinking)
+	}
+	if out[1] != other || out[1].DefaultThinking != "" {
+		t.Fatalf("expected unmatched model to pass through unchanged")
+	}
+}
@@ -0,0 +1,365 @@
+package cliproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/store"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// storeOpenTimeout bounds each connect/bootstrap step while opening a remote store.
+	storeOpenTimeout = 30 * time.Second
+	// storeFallbackRetryInterval is how often a degraded store retries the remote backend.
+	storeFallbackRetryInterval = 30 * time.Second
+)
+
+// WithStore sets the token store used by the built service. Build registers it as the
+// global token store (see sdkAuth.RegisterTokenStore) and points it at the configured
+// auth directory. Stores that manage their own workspace (Postgres, object storage)
+// report it through AuthDir, which then replaces cfg.AuthDir.
+func (b *Builder) WithStore(s coreauth.Store) *Builder {
+	b.store = s
+	return b
+}
+
+// StoreEnv is the token store selection read from the environment by LoadStoreEnv.
+type StoreEnv struct {
+	// Kind is "pgstore", "objectstore" or "gitstore"; empty selects the file store.
+	Kind string
+	// Root is the local workspace of the selected store.
+	Root string
+	// Fallback is the TOKENSTORE_FALLBACK mode ("read-only" or "read-write"), if any.
+	Fallback string
+	// Mirrors lists the stores named in TOKENSTORE_MIRRORS.
+	Mirrors []string
+
+	pgDSN    string
+	pgSchema string
+
+	objectEndpoint  string
+	objectAccessKey string
+	objectSecretKey string
+	objectBucket    string
+	objectRoot      string
+
+	gitURL      string
+	gitUsername string
+	gitToken    string
+	gitRoot     string
+}
+
+// LoadStoreEnv reads the token store settings used by the server binary: PGSTORE_DSN (with
+// PGSTORE_SCHEMA, PGSTORE_LOCAL_PATH), OBJECTSTORE_ENDPOINT (with OBJECTSTORE_ACCESS_KEY,
+// OBJECTSTORE_SECRET_KEY, OBJECTSTORE_BUCKET, OBJECTSTORE_LOCAL_PATH) or GITSTORE_GIT_URL
+// (with GITSTORE_GIT_USERNAME, GITSTORE_GIT_TOKEN, GITSTORE_LOCAL_PATH), checked in that
+// order, plus TOKENSTORE_FALLBACK and TOKENSTORE_MIRRORS. Lower-case names are accepted
+// too. Workspaces default to the writable path, then workDir (the working directory when
+// empty). Nothing is opened or contacted.
+func LoadStoreEnv(workDir string) (*StoreEnv, error) {
+	if workDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		workDir = wd
+	}
+	writableBase := util.WritablePath()
+	localRoot := func(name string, keys ...string) string {
+		if value, ok := lookupStoreEnv(keys...); ok {
+			return filepath.Join(value, name)
+		}
+		if writableBase != "" {
+			return filepath.Join(writableBase, name)
+		}
+		return filepath.Join(workDir, name)
+	}
+
+	env := &StoreEnv{
+		objectRoot: localRoot("objectstore", "OBJECTSTORE_LOCAL_PATH", "objectstore_local_path"),
+		gitRoot:    localRoot("gitstore", "GITSTORE_LOCAL_PATH", "gitstore_local_path"),
+	}
+	env.pgDSN, _ = lookupStoreEnv("PGSTORE_DSN", "pgstore_dsn")
+	env.pgSchema, _ = lookupStoreEnv("PGSTORE_SCHEMA", "pgstore_schema")
+	env.objectEndpoint, _ = lookupStoreEnv("OBJECTSTORE_ENDPOINT", "objectstore_endpoint")
+	env.objectAccessKey, _ = lookupStoreEnv("OBJECTSTORE_ACCESS_KEY", "objectstore_access_key")
+	env.objectSecretKey, _ = lookupStoreEnv("OBJECTSTORE_SECRET_KEY", "objectstore_secret_key")
+	env.objectBucket, _ = lookupStoreEnv("OBJECTSTORE_BUCKET", "objectstore_bucket")
+	env.gitURL, _ = lookupStoreEnv("GITSTORE_GIT_URL", "gitstore_git_url")
+	env.gitUsername, _ = lookupStoreEnv("GITSTORE_GIT_USERNAME", "gitstore_git_username")
+	env.gitToken, _ = lookupStoreEnv("GITSTORE_GIT_TOKEN", "gitstore_git_token")
+
+	switch {
+	case env.pgDSN != "":
+		env.Kind, env.Root = "pgstore", localRoot("pgstore", "PGSTORE_LOCAL_PATH", "pgstore_local_path")
+	case env.objectEndpoint != "":
+		env.Kind, env.Root = "objectstore", env.objectRoot
+	case env.gitURL != "":
+		env.Kind, env.Root = "gitstore", env.gitRoot
+	}
+
+	if value, ok := lookupStoreEnv("TOKENSTORE_FALLBACK", "tokenstore_fallback"); ok {
+		switch mode := strings.ToLower(value); mode {
+		case "read-only", "read-write":
+			env.Fallback = mode
+		default:
+			log.Warnf("ignoring TOKENSTORE_FALLBACK=%q (supported: read-only, read-write)", value)
+		}
+	}
+	if value, ok := lookupStoreEnv("TOKENSTORE_MIRRORS", "tokenstore_mirrors"); ok {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				env.Mirrors = append(env.Mirrors, name)
+			}
+		}
+	}
+	return env, nil
+}
+
+// ConfigPath returns the configuration file inside the store workspace, or "" for the
+// file store.
+func (e *StoreEnv) ConfigPath() string {
+	if e.Kind == "" {
+		return ""
+	}
+	return filepath.Join(e.Root, "config", "config.yaml")
+}
+
+// AuthDir returns the auth directory inside the store workspace, or "" for the file store.
+func (e *StoreEnv) AuthDir() string {
+	if e.Kind == "" {
+		return ""
+	}
+	return filepath.Join(e.Root, "auths")
+}
+
+// Probe checks that the selected backend is reachable without bootstrapping, cloning or
+// writing anything. It is a no-op for the file store.
+func (e *StoreEnv) Probe(ctx context.Context) error {
+	switch e.Kind {
+	case "pgstore":
+		return store.ProbePostgres(ctx, e.pgDSN)
+	case "objectstore":
+		return store.ProbeObjectStore(ctx, e.objectEndpoint, e.objectAccessKey, e.objectSecretKey, e.objectBucket)
+	case "gitstore":
+		return store.ProbeGitRemote(ctx, e.gitURL, e.gitUsername, e.gitToken)
+	}
+	return nil
+}
+
+// FallbackAvailable reports whether opening may continue from the local spool after the
+// remote backend failed: a fallback mode must be enabled and a previous run must have
+// left a configuration behind.
+func (e *StoreEnv) FallbackAvailable() bool {
+	if e.Fallback == "" || e.Kind == "" {
+		return false
+	}
+	info, err := os.Stat(e.ConfigPath())
+	return err == nil && !info.IsDir() && info.Size() > 0
+}
+
+// Open connects the selected store and synchronizes its records to the local workspace,
+// seeding an empty backend from exampleConfigPath when set. When the backend is
+// unreachable and FallbackAvailable reports true, the spool is served instead and the
+// remote is retried in the background until ctx is done. Configured mirrors wrap the
+// result. It returns nil, nil for the file store.
+func (e *StoreEnv) Open(ctx context.Context, exampleConfigPath string) (coreauth.Store, error) {
+	var primary coreauth.Store
+	switch e.Kind {
+	case "pgstore":
+		pgCfg := store.PostgresStoreConfig{DSN: e.pgDSN, Schema: e.pgSchema, SpoolDir: e.Root}
+		pg, err := e.openPostgres(ctx, pgCfg, exampleConfigPath)
+		if err != nil {
+			return e.openFallback(ctx, exampleConfigPath, err, func(ctx context.Context) (coreauth.Store, error) {
+				remote, errOpen := store.NewPostgresStore(ctx, pgCfg)
+				if errOpen != nil {
+					return nil, errOpen
+				}
+				if errSchema := remote.EnsureSchema(ctx); errSchema != nil {
+					_ = remote.Close()
+					return nil, errSchema
+				}
+				return remote, nil
+			})
+		}
+		log.Infof("postgres-backed token store enabled, workspace path: %s", pg.WorkDir())
+		primary = pg
+	case "objectstore":
+		obj, err := store.NewObjectTokenStoreFromEndpoint(e.Root, e.objectEndpoint, e.objectAccessKey, e.objectSecretKey, e.objectBucket)
+		if err != nil {
+			return nil, fmt.Errorf("cliproxy: initialize object token store: %w", err)
+		}
+		bootstrapCtx, cancel := context.WithTimeout(ctx, storeOpenTimeout)
+		err = obj.Bootstrap(bootstrapCtx, exampleConfigPath)
+		cancel()
+		if err != nil {
+			return e.openFallback(ctx, exampleConfigPath, fmt.Errorf("cliproxy: bootstrap object token store: %w", err), func(ctx context.Context) (coreauth.Store, error) {
+				if errBucket := obj.EnsureBucket(ctx); errBucket != nil {
+					return nil, errBucket
+				}
+				return obj, nil
+			})
+		}
+		log.Infof("object-backed token store enabled, bucket: %s", e.objectBucket)
+		primary = obj
+	case "gitstore":
+		git, err := e.openGit(ctx, exampleConfigPath)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("git-backed token store enabled, repository path: %s", e.Root)
+		primary = git
+	default:
+		return nil, nil
+	}
+	return e.withMirrors(ctx, primary, exampleConfigPath), nil
+}
+
+func (e *StoreEnv) openPostgres(ctx context.Context, cfg store.PostgresStoreConfig, exampleConfigPath string) (*store.PostgresStore, error) {
+	openCtx, cancel := context.WithTimeout(ctx, storeOpenTimeout)
+	pg, err := store.NewPostgresStore(openCtx, cfg)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("cliproxy: initialize postgres token store: %w", err)
+	}
+	bootstrapCtx, cancel := context.WithTimeout(ctx, storeOpenTimeout)
+	err = pg.Bootstrap(bootstrapCtx, exampleConfigPath)
+	cancel()
+	if err != nil {
+		_ = pg.Close()
+		return nil, fmt.Errorf("cliproxy: bootstrap postgres token store: %w", err)
+	}
+	return pg, nil
+}
+
+func (e *StoreEnv) openGit(ctx context.Context, exampleConfigPath string) (*store.GitTokenStore, error) {
+	git := store.NewGitTokenStore(e.gitURL, e.gitUsername, e.gitToken)
+	git.SetBaseDir(filepath.Join(e.Root, "auths"))
+	if err := git.EnsureRepository(); err != nil {
+		return nil, fmt.Errorf("cliproxy: prepare git token store: %w", err)
+	}
+	if exampleConfigPath == "" {
+		return git, nil
+	}
+	configPath := git.ConfigPath()
+	if configPath == "" {
+		configPath = e.ConfigPath()
+	}
+	if _, err := os.Stat(configPath); err == nil {
+		return git, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("cliproxy: inspect git-backed config: %w", err)
+	}
+	if _, err := os.Stat(exampleConfigPath); err != nil {
+		return nil, fmt.Errorf("cliproxy: find template config file: %w", err)
+	}
+	if err := misc.CopyConfigTemplate(exampleConfigPath, configPath); err != nil {
+		return nil, fmt.Errorf("cliproxy: bootstrap git-backed config: %w", err)
+	}
+	if err := git.PersistConfig(ctx); err != nil {
+		return nil, fmt.Errorf("cliproxy: commit initial git-backed config: %w", err)
+	}
+	log.Infof("git-backed config initialized from template: %s", configPath)
+	return git, nil
+}
+
+// openFallback serves the spool through a file store after the remote failed with cause,
+// or returns cause when no fallback is available. The file store's directory is set later
+// from cfg.AuthDir like any other store.
+func (e *StoreEnv) openFallback(ctx context.Context, exampleConfigPath string, cause error, connect store.FallbackConnector) (coreauth.Store, error) {
+	if !e.FallbackAvailable() {
+		return nil, cause
+	}
+	log.Warnf("%v; serving last-known credentials from local spool %s (%s)", cause, e.Root, e.Fallback)
+	fallback := store.NewFallbackStore(sdkAuth.NewFileTokenStore(), connect, e.Fallback == "read-only")
+	fallback.Start(ctx, storeFallbackRetryInterval)
+	return e.withMirrors(ctx, fallback, exampleConfigPath), nil
+}
+
+// withMirrors wraps primary so configured mirrors receive a best-effort copy of every
+// write; reads stay on the primary. Mirrors that cannot be opened are skipped.
+func (e *StoreEnv) withMirrors(ctx context.Context, primary coreauth.Store, exampleConfigPath string) coreauth.Store {
+	var mirrors []coreauth.Store
+	for _, name := range e.Mirrors {
+		if name == e.Kind {
+			log.Warnf("token store mirror %q is already the primary store, skipping", name)
+			continue
+		}
+		switch name {
+		case "objectstore":
+			if e.objectEndpoint == "" {
+				log.Warn("token store mirror objectstore requires OBJECTSTORE_ENDPOINT, skipping")
+				continue
+			}
+			mirror, err := store.NewObjectTokenStoreFromEndpoint(e.objectRoot, e.objectEndpoint, e.objectAccessKey, e.objectSecretKey, e.objectBucket)
+			if err != nil {
+				log.Errorf("failed to initialize object store mirror: %v", err)
+				continue
+			}
+			bootstrapCtx, cancel := context.WithTimeout(ctx, storeOpenTimeout)
+			err = mirror.Bootstrap(bootstrapCtx, exampleConfigPath)
+			cancel()
+			if err != nil {
+				log.Errorf("failed to bootstrap object store mirror: %v", err)
+				continue
+			}
+			mirrors = append(mirrors, mirror)
+			log.Infof("object store mirror enabled, bucket: %s", e.objectBucket)
+		case "gitstore":
+			if e.gitURL == "" {
+				log.Warn("token store mirror gitstore requires GITSTORE_GIT_URL, skipping")
+				continue
+			}
+			mirror := store.NewGitTokenStore(e.gitURL, e.gitUsername, e.gitToken)
+			mirror.SetBaseDir(filepath.Join(e.gitRoot, "auths"))
+			if err := mirror.EnsureRepository(); err != nil {
+				log.Errorf("failed to prepare git store mirror: %v", err)
+				continue
+			}
+			mirrors = append(mirrors, mirror)
+			log.Infof("git store mirror enabled, repository: %s", e.gitURL)
+		default:
+			log.Warnf("unknown token store mirror %q (supported: objectstore, gitstore)", name)
+		}
+	}
+	if len(mirrors) == 0 {
+		return primary
+	}
+	return store.NewMultiStore(primary, mirrors...)
+}
+
+// StoreFromEnv opens the token store selected by the environment, exactly as the server
+// binary does: it is LoadStoreEnv followed by Open, so TOKENSTORE_FALLBACK and
+// TOKENSTORE_MIRRORS are honoured as well. It returns nil, nil when no store is
+// configured, so the builder keeps the default file store. When the store serves a
+// degraded spool, set cfg.AuthDir to the AuthDir reported by LoadStoreEnv.
+func StoreFromEnv(ctx context.Context) (coreauth.Store, error) {
+	env, err := LoadStoreEnv("")
+	if err != nil {
+		return nil, err
+	}
+	return env.Open(ctx, "")
+}
+
+// lookupStoreEnv returns the first non-empty value among keys.
+func lookupStoreEnv(keys ...string) (string, bool) {
+	for _, key := range keys {
+		if value, ok := os.LookupEnv(key); ok {
+			if trimmed := strings.TrimSpace(value); trimmed != "" {
+				return trimmed, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,46 @@
+package usage
+
+import (
+	"context"
+	"sync"
+)
+
+type captureKey struct{}
+
+// Capture collects the records published under a context, e.g. so a cached response can
+// account for the usage of the request that produced it.
+type Capture struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+// WithCapture returns a child context whose published records are also added to capture.
+func WithCapture(ctx context.Context, capture *Capture) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, captureKey{}, capture)
+}
+
+// Records returns the records captured so far.
+func (c *Capture) Records() []Record {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Record(nil), c.records...)
+}
+
+func captureRecord(ctx context.Context, record Record) {
+	if ctx == nil {
+		return
+	}
+	capture, ok := ctx.Value(captureKey{}).(*Capture)
+	if !ok || capture == nil {
+		return
+	}
+	capture.mu.Lock()
+	capture.records = append(capture.records, record)
+	capture.mu.Unlock()
+}
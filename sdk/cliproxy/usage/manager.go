@@ -21,6 +21,10 @@ type Record struct {
 	Detail      Detail
 }
 
+// SourceResponseCache marks a Record for a response served from the response cache. Such
+// records carry no provider or credential, as no upstream call was made.
+const SourceResponseCache = "response-cache"
+
 // Detail holds the token usage breakdown.
 type Detail struct {
 	InputTokens     int64
@@ -28,6 +32,15 @@ type Detail struct {
 	ReasoningTokens int64
 	CachedTokens    int64
 	TotalTokens     int64
+	// CacheReadTokens and CacheCreationTokens split prompt-cache usage. Anthropic
+	// reports both; Gemini reports reads only, as caches are created out of band.
+	CacheReadTokens     int64
+	CacheCreationTokens int64
+	// ToolCalls counts the tool/function calls the model emitted in the response.
+	ToolCalls int64
+	// Estimated marks token counts computed locally because the upstream stream ended
+	// without reporting usage.
+	Estimated bool
 }
 
 // Plugin consumes usage records emitted by the proxy runtime.
@@ -109,6 +122,7 @@ func (m *Manager) Publish(ctx context.Context, record Record) {
 	if m == nil {
 		return
 	}
+	captureRecord(ctx, record)
 	// ensure worker is running even if Start was not called explicitly
 	m.Start(context.Background())
 	m.mu.Lock()
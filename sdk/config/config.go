@@ -11,14 +11,40 @@ type SDKConfig = internalconfig.SDKConfig
 type Config = internalconfig.Config
 
 type StreamingConfig = internalconfig.StreamingConfig
+type APIKeyModelAccess = internalconfig.APIKeyModelAccess
+type PromptLimit = internalconfig.PromptLimit
+type ModelFallback = internalconfig.ModelFallback
+type CountTokensCacheConfig = internalconfig.CountTokensCacheConfig
+type IdempotencyCacheConfig = internalconfig.IdempotencyCacheConfig
+type ResponseCacheConfig = internalconfig.ResponseCacheConfig
+type ChoiceFanOutConfig = internalconfig.ChoiceFanOutConfig
+type ModelFallbackTarget = internalconfig.ModelFallbackTarget
 type TLSConfig = internalconfig.TLSConfig
 type RemoteManagement = internalconfig.RemoteManagement
+type ManagementToken = internalconfig.ManagementToken
 type AmpCode = internalconfig.AmpCode
 type OAuthModelAlias = internalconfig.OAuthModelAlias
 type PayloadConfig = internalconfig.PayloadConfig
 type PayloadRule = internalconfig.PayloadRule
 type PayloadFilterRule = internalconfig.PayloadFilterRule
 type PayloadModelRule = internalconfig.PayloadModelRule
+type ThinkingDefault = internalconfig.ThinkingDefault
+type DeclaredModel = internalconfig.DeclaredModel
+type AntigravityConfig = internalconfig.AntigravityConfig
+type GeminiCLIConfig = internalconfig.GeminiCLIConfig
+type GeminiSafetySetting = internalconfig.GeminiSafetySetting
+type AuthGroup = internalconfig.AuthGroup
+type ModelWeight = internalconfig.ModelWeight
+type RequestQueueConfig = internalconfig.RequestQueueConfig
+type ThinkingConfig = internalconfig.ThinkingConfig
+type UpstreamConfig = internalconfig.UpstreamConfig
+type ClaudeConfig = internalconfig.ClaudeConfig
+type UsageConfig = internalconfig.UsageConfig
+type ModerationConfig = internalconfig.ModerationConfig
+type ForwardHeadersConfig = internalconfig.ForwardHeadersConfig
+type ModelPricing = internalconfig.ModelPricing
+type SecretResolver = internalconfig.SecretResolver
+type SecretResolverFunc = internalconfig.SecretResolverFunc
 
 type GeminiKey = internalconfig.GeminiKey
 type CodexKey = internalconfig.CodexKey
@@ -41,6 +67,11 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 	return internalconfig.LoadConfigOptional(configFile, optional)
 }
 
+// RegisterSecretResolver registers resolver for ${scheme:reference} values in config files.
+func RegisterSecretResolver(scheme string, resolver SecretResolver) {
+	internalconfig.RegisterSecretResolver(scheme, resolver)
+}
+
 func SaveConfigPreserveComments(configFile string, cfg *Config) error {
 	return internalconfig.SaveConfigPreserveComments(configFile, cfg)
 }
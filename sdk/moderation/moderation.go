@@ -0,0 +1,143 @@
+// Package moderation provides the inbound content-moderation hook run on every proxied
+// request before it is forwarded upstream. A moderator inspects the original client payload
+// and allows, blocks or flags it. Moderators are registered by name (typically through
+// cliproxy.Builder) and selected by the "moderation.moderator" config value; the built-in
+// "keywords" moderator is configured from keyword lists.
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/tidwall/gjson"
+)
+
+// KeywordsModerator is the name of the built-in keyword-list moderator.
+const KeywordsModerator = "keywords"
+
+// Verdict is the outcome of a moderation check.
+type Verdict int
+
+const (
+	// Allow forwards the request unchanged.
+	Allow Verdict = iota
+	// Block rejects the request with 403 and the decision reason.
+	Block
+	// Flag forwards the request and logs the decision reason.
+	Flag
+)
+
+// Decision is the result returned by a Moderator.
+type Decision struct {
+	Verdict Verdict
+	// Reason explains a Block or Flag verdict. Blocked clients receive it in the error body.
+	Reason string
+}
+
+// Info describes the request being moderated.
+type Info struct {
+	// Format is the client-facing handler format, e.g. "openai", "claude" or "gemini".
+	Format string
+	// Model is the model name requested by the client.
+	Model string
+}
+
+// Moderator inspects a client request body before it is forwarded. Returning an error
+// fails the request with 500; it is not treated as a block.
+type Moderator interface {
+	Moderate(ctx context.Context, info Info, body []byte) (Decision, error)
+}
+
+// ModeratorFunc adapts a function to the Moderator interface.
+type ModeratorFunc func(ctx context.Context, info Info, body []byte) (Decision, error)
+
+// Moderate calls f.
+func (f ModeratorFunc) Moderate(ctx context.Context, info Info, body []byte) (Decision, error) {
+	return f(ctx, info, body)
+}
+
+// NoOp allows every request. It is used when moderation is not configured.
+var NoOp Moderator = ModeratorFunc(func(context.Context, Info, []byte) (Decision, error) {
+	return Decision{Verdict: Allow}, nil
+})
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Moderator)
+)
+
+// Register adds or replaces the moderator stored under name.
+func Register(name string, m Moderator) {
+	name = strings.TrimSpace(name)
+	if name == "" || m == nil {
+		return
+	}
+	registryMu.Lock()
+	registry[name] = m
+	registryMu.Unlock()
+}
+
+// Unregister removes the moderator stored under name.
+func Unregister(name string) {
+	registryMu.Lock()
+	delete(registry, strings.TrimSpace(name))
+	registryMu.Unlock()
+}
+
+// Lookup returns the moderator registered under name.
+func Lookup(name string) (Moderator, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	m, ok := registry[strings.TrimSpace(name)]
+	return m, ok
+}
+
+// Keywords is a Moderator that matches keywords, case-insensitively, against every string
+// value in the request body. Block keywords take precedence over flag keywords.
+type Keywords struct {
+	BlockKeywords []string
+	FlagKeywords  []string
+}
+
+// Moderate implements Moderator.
+func (k Keywords) Moderate(_ context.Context, _ Info, body []byte) (Decision, error) {
+	if len(k.BlockKeywords) == 0 && len(k.FlagKeywords) == 0 {
+		return Decision{Verdict: Allow}, nil
+	}
+	var text strings.Builder
+	collectStrings(gjson.ParseBytes(body), &text)
+	content := strings.ToLower(text.String())
+	if keyword := firstMatch(content, k.BlockKeywords); keyword != "" {
+		return Decision{Verdict: Block, Reason: fmt.Sprintf("request contains blocked keyword %q", keyword)}, nil
+	}
+	if keyword := firstMatch(content, k.FlagKeywords); keyword != "" {
+		return Decision{Verdict: Flag, Reason: fmt.Sprintf("request contains flagged keyword %q", keyword)}, nil
+	}
+	return Decision{Verdict: Allow}, nil
+}
+
+// collectStrings appends every string value under node to out, one per line.
+func collectStrings(node gjson.Result, out *strings.Builder) {
+	switch {
+	case node.IsObject() || node.IsArray():
+		node.ForEach(func(_, value gjson.Result) bool {
+			collectStrings(value, out)
+			return true
+		})
+	case node.Type == gjson.String:
+		out.WriteString(node.Str)
+		out.WriteByte('\n')
+	}
+}
+
+func firstMatch(content string, keywords []string) string {
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword != "" && strings.Contains(content, strings.ToLower(keyword)) {
+			return keyword
+		}
+	}
+	return ""
+}
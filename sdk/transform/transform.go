@@ -0,0 +1,133 @@
+// Package transform provides named request and response transforms that run around every
+// proxied request, for cross-cutting rewrites such as stripping client fields or appending a
+// disclaimer. Transforms are registered by name (typically through cliproxy.Builder) and
+// enabled in order by the "transforms" config list.
+package transform
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Info describes the request a transform is applied to.
+type Info struct {
+	// Format is the client-facing handler format, e.g. "openai", "claude" or "gemini".
+	Format string
+	// Model is the model name requested by the client.
+	Model string
+	// Stream reports whether the response is delivered as a stream of chunks.
+	Stream bool
+}
+
+// Transform holds optional request and response hooks. Nil hooks are skipped.
+type Transform struct {
+	// Request rewrites the client request body before it is executed.
+	// Returning an error rejects the request with 400.
+	Request func(ctx context.Context, info Info, body []byte) ([]byte, error)
+	// Response rewrites a non-streaming response body, or a single stream chunk in the
+	// handler's wire format when info.Stream is true.
+	Response func(ctx context.Context, info Info, body []byte) []byte
+	// Finalize returns a chunk to append when a stream ends cleanly, after the last
+	// upstream chunk and before the handler's terminal marker such as OpenAI's [DONE].
+	// The chunk is in the handler's wire format, like the chunks passed to Response.
+	// Returning nil appends nothing. It is not called for non-streaming responses.
+	Finalize func(ctx context.Context, info Info) []byte
+}
+
+type namedTransform struct {
+	name      string
+	transform Transform
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Transform)
+)
+
+// Register adds or replaces the transform stored under name.
+func Register(name string, t Transform) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return
+	}
+	registryMu.Lock()
+	registry[name] = t
+	registryMu.Unlock()
+}
+
+// Unregister removes the transform stored under name.
+func Unregister(name string) {
+	registryMu.Lock()
+	delete(registry, strings.TrimSpace(name))
+	registryMu.Unlock()
+}
+
+// Chain is an ordered list of resolved transforms.
+type Chain []namedTransform
+
+// Resolve returns the chain for the configured names in order, together with any names
+// that have no registered transform.
+func Resolve(names []string) (Chain, []string) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	chain := make(Chain, 0, len(names))
+	var missing []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		t, ok := registry[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		chain = append(chain, namedTransform{name: name, transform: t})
+	}
+	return chain, missing
+}
+
+// ApplyRequest runs every request hook in order.
+func (c Chain) ApplyRequest(ctx context.Context, info Info, body []byte) ([]byte, error) {
+	for _, entry := range c {
+		if entry.transform.Request == nil {
+			continue
+		}
+		out, err := entry.transform.Request(ctx, info, body)
+		if err != nil {
+			return nil, fmt.Errorf("transform %s: %w", entry.name, err)
+		}
+		body = out
+	}
+	return body, nil
+}
+
+// ApplyResponse runs every response hook in order.
+func (c Chain) ApplyResponse(ctx context.Context, info Info, body []byte) []byte {
+	for _, entry := range c {
+		if entry.transform.Response == nil {
+			continue
+		}
+		body = entry.transform.Response(ctx, info, body)
+	}
+	return body
+}
+
+// ApplyFinalize runs every finalize hook in order and returns the non-empty chunks.
+func (c Chain) ApplyFinalize(ctx context.Context, info Info) [][]byte {
+	var tails [][]byte
+	for _, entry := range c {
+		if entry.transform.Finalize == nil {
+			continue
+		}
+		if tail := entry.transform.Finalize(ctx, info); len(tail) > 0 {
+			tails = append(tails, tail)
+		}
+	}
+	return tails
+}
@@ -0,0 +1,67 @@
+package translator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// BatchResult is the outcome of translating one request in TranslateRequestBatch.
+type BatchResult struct {
+	// Payload is the translated request; nil when Err is set.
+	Payload []byte
+	// Err reports why this request could not be translated.
+	Err error
+}
+
+// TranslateRequestBatch converts many stored requests with TranslateRequestByFormatName,
+// spreading the work over at most GOMAXPROCS workers. Results are returned in input order.
+// A request that is not valid JSON, makes its translator panic, or translates to invalid
+// JSON gets an Err and does not affect the others. When no translator is registered for
+// from/to, every request gets an Err rather than being passed through untranslated.
+func TranslateRequestBatch(from, to Format, model string, requests [][]byte, stream bool) []BatchResult {
+	results := make([]BatchResult, len(requests))
+	if len(requests) == 0 {
+		return results
+	}
+	workers := min(runtime.GOMAXPROCS(0), len(requests))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = translateBatchItem(from, to, model, requests[i], stream)
+			}
+		}()
+	}
+	for i := range requests {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+func translateBatchItem(from, to Format, model string, rawJSON []byte, stream bool) (result BatchResult) {
+	if !json.Valid(rawJSON) {
+		return BatchResult{Err: errors.New("request is not valid JSON")}
+	}
+	if from != to && !HasRequestTransformer(from, to) {
+		return BatchResult{Err: fmt.Errorf("translate %s to %s: no request translator registered", from, to)}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			result = BatchResult{Err: fmt.Errorf("translate %s to %s: panic: %v", from, to, r)}
+		}
+	}()
+	out := TranslateRequestByFormatName(from, to, model, rawJSON, stream)
+	if !json.Valid(out) {
+		return BatchResult{Err: fmt.Errorf("translate %s to %s: result is not valid JSON", from, to)}
+	}
+	return BatchResult{Payload: out}
+}
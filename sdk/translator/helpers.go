@@ -7,11 +7,21 @@ func TranslateRequestByFormatName(from, to Format, model string, rawJSON []byte,
 	return TranslateRequest(from, to, model, rawJSON, stream)
 }
 
+// HasRequestTransformerByFormatName reports whether a request translator exists between two schemas.
+func HasRequestTransformerByFormatName(from, to Format) bool {
+	return HasRequestTransformer(from, to)
+}
+
 // HasResponseTransformerByFormatName reports whether a response translator exists between two schemas.
 func HasResponseTransformerByFormatName(from, to Format) bool {
 	return HasResponseTransformer(from, to)
 }
 
+// HasStreamTransformerByFormatName reports whether a streaming response translator exists between two schemas.
+func HasStreamTransformerByFormatName(from, to Format) bool {
+	return HasStreamTransformer(from, to)
+}
+
 // TranslateStreamByFormatName converts streaming responses between schemas by their string identifiers.
 func TranslateStreamByFormatName(ctx context.Context, from, to Format, model string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) []string {
 	return TranslateStream(ctx, from, to, model, originalRequestRawJSON, requestRawJSON, rawJSON, param)
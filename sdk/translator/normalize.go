@@ -0,0 +1,206 @@
+package translator
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// mergeRoleTargets holds the set of target formats whose requests get adjacent
+// same-role messages merged after translation.
+var mergeRoleTargets atomic.Value // map[Format]struct{}
+
+// SetMergeConsecutiveRoles enables merging of adjacent messages that share a role in
+// requests translated to the given target formats. Calling it without formats disables
+// the step. Supported targets are claude, openai, gemini, gemini-cli and antigravity.
+func SetMergeConsecutiveRoles(targets ...Format) {
+	set := make(map[Format]struct{}, len(targets))
+	for _, target := range targets {
+		if target = FromString(strings.ToLower(strings.TrimSpace(target.String()))); target != "" {
+			set[target] = struct{}{}
+		}
+	}
+	mergeRoleTargets.Store(set)
+}
+
+func mergeConsecutiveRolesEnabled(to Format) bool {
+	set, _ := mergeRoleTargets.Load().(map[Format]struct{})
+	_, ok := set[to]
+	return ok
+}
+
+// MergeConsecutiveRoles merges adjacent messages sharing a role in a request body of
+// format to, concatenating their content while keeping tool calls and tool results.
+// Bodies of other formats are returned unchanged.
+func MergeConsecutiveRoles(to Format, rawJSON []byte) []byte {
+	switch to {
+	case FormatClaude:
+		return mergeAdjacentByRole(rawJSON, "messages", mergeClaudeMessages)
+	case FormatOpenAI:
+		return mergeAdjacentByRole(rawJSON, "messages", mergeOpenAIMessages)
+	case FormatGemini:
+		return mergeAdjacentByRole(rawJSON, "contents", mergeGeminiContents)
+	case FormatGeminiCLI, FormatAntigravity:
+		return mergeAdjacentByRole(rawJSON, "request.contents", mergeGeminiContents)
+	default:
+		return rawJSON
+	}
+}
+
+// mergeAdjacentByRole folds each message into its predecessor when both carry the same
+// non-empty role and merge accepts the pair. merge returns the combined message JSON and
+// false when the pair must stay separate.
+func mergeAdjacentByRole(rawJSON []byte, path string, merge func(prev, next gjson.Result) (string, bool)) []byte {
+	items := gjson.GetBytes(rawJSON, path)
+	if !items.IsArray() {
+		return rawJSON
+	}
+	messages := items.Array()
+	if len(messages) < 2 {
+		return rawJSON
+	}
+	out := make([]string, 0, len(messages))
+	changed := false
+	for _, message := range messages {
+		if n := len(out); n > 0 {
+			prev := gjson.Parse(out[n-1])
+			role := message.Get("role").String()
+			if role != "" && role == prev.Get("role").String() {
+				if combined, ok := merge(prev, message); ok {
+					out[n-1] = combined
+					changed = true
+					continue
+				}
+			}
+		}
+		out = append(out, message.Raw)
+	}
+	if !changed {
+		return rawJSON
+	}
+	updated, err := sjson.SetRawBytes(rawJSON, path, []byte("["+strings.Join(out, ",")+"]"))
+	if err != nil {
+		return rawJSON
+	}
+	return updated
+}
+
+// mergeClaudeMessages concatenates the content blocks of two Claude messages. In user
+// messages tool_result blocks are moved ahead of other blocks, as Claude requires them
+// to lead the turn that answers a tool_use.
+func mergeClaudeMessages(prev, next gjson.Result) (string, bool) {
+	blocks := append(claudeContentBlocks(prev.Get("content")), claudeContentBlocks(next.Get("content"))...)
+	if prev.Get("role").String() == "user" {
+		results := make([]string, 0, len(blocks))
+		others := make([]string, 0, len(blocks))
+		for _, block := range blocks {
+			if gjson.Get(block, "type").String() == "tool_result" {
+				results = append(results, block)
+			} else {
+				others = append(others, block)
+			}
+		}
+		blocks = append(results, others...)
+	}
+	combined, err := sjson.SetRaw(prev.Raw, "content", "["+strings.Join(blocks, ",")+"]")
+	if err != nil {
+		return "", false
+	}
+	return combined, true
+}
+
+func claudeContentBlocks(content gjson.Result) []string {
+	if content.IsArray() {
+		blocks := make([]string, 0, len(content.Array()))
+		for _, block := range content.Array() {
+			blocks = append(blocks, block.Raw)
+		}
+		return blocks
+	}
+	if text := content.String(); content.Type == gjson.String && text != "" {
+		return []string{textBlock(text)}
+	}
+	return nil
+}
+
+// mergeOpenAIMessages joins the content and tool_calls of two OpenAI chat messages.
+// Tool and function messages answer a single call each and are never merged.
+func mergeOpenAIMessages(prev, next gjson.Result) (string, bool) {
+	switch prev.Get("role").String() {
+	case "tool", "function":
+		return "", false
+	}
+	prevContent, nextContent := prev.Get("content"), next.Get("content")
+	var content string
+	if !prevContent.IsArray() && !nextContent.IsArray() {
+		texts := make([]string, 0, 2)
+		for _, part := range []gjson.Result{prevContent, nextContent} {
+			if text := part.String(); part.Type == gjson.String && text != "" {
+				texts = append(texts, text)
+			}
+		}
+		content = "null"
+		if len(texts) > 0 {
+			joined, _ := sjson.Set(`{}`, "v", strings.Join(texts, "\n\n"))
+			content = gjson.Get(joined, "v").Raw
+		}
+	} else {
+		parts := append(openAIContentParts(prevContent), openAIContentParts(nextContent)...)
+		content = "[" + strings.Join(parts, ",") + "]"
+	}
+	combined, err := sjson.SetRaw(prev.Raw, "content", content)
+	if err != nil {
+		return "", false
+	}
+	prevCalls, nextCalls := prev.Get("tool_calls"), next.Get("tool_calls")
+	if nextCalls.IsArray() && len(nextCalls.Array()) > 0 {
+		calls := make([]string, 0, len(prevCalls.Array())+len(nextCalls.Array()))
+		for _, call := range prevCalls.Array() {
+			calls = append(calls, call.Raw)
+		}
+		for _, call := range nextCalls.Array() {
+			calls = append(calls, call.Raw)
+		}
+		if combined, err = sjson.SetRaw(combined, "tool_calls", "["+strings.Join(calls, ",")+"]"); err != nil {
+			return "", false
+		}
+	}
+	return combined, true
+}
+
+func openAIContentParts(content gjson.Result) []string {
+	if content.IsArray() {
+		parts := make([]string, 0, len(content.Array()))
+		for _, part := range content.Array() {
+			parts = append(parts, part.Raw)
+		}
+		return parts
+	}
+	if text := content.String(); content.Type == gjson.String && text != "" {
+		return []string{textBlock(text)}
+	}
+	return nil
+}
+
+// mergeGeminiContents concatenates the parts of two Gemini contents entries.
+func mergeGeminiContents(prev, next gjson.Result) (string, bool) {
+	parts := make([]string, 0, len(prev.Get("parts").Array())+len(next.Get("parts").Array()))
+	for _, part := range prev.Get("parts").Array() {
+		parts = append(parts, part.Raw)
+	}
+	for _, part := range next.Get("parts").Array() {
+		parts = append(parts, part.Raw)
+	}
+	combined, err := sjson.SetRaw(prev.Raw, "parts", "["+strings.Join(parts, ",")+"]")
+	if err != nil {
+		return "", false
+	}
+	return combined, true
+}
+
+func textBlock(text string) string {
+	block, _ := sjson.Set(`{"type":"text"}`, "text", text)
+	return block
+}
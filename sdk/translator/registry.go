@@ -2,6 +2,7 @@ package translator
 
 import (
 	"context"
+	"sort"
 	"sync"
 )
 
@@ -39,17 +40,35 @@ func (r *Registry) Register(from, to Format, request RequestTransform, response
 }
 
 // TranslateRequest converts a payload between schemas, returning the original payload
-// if no translator is registered.
+// if no translator is registered. Adjacent same-role messages are merged afterwards when
+// enabled for the target format via SetMergeConsecutiveRoles.
 func (r *Registry) TranslateRequest(from, to Format, model string, rawJSON []byte, stream bool) []byte {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	translated := rawJSON
 	if byTarget, ok := r.requests[from]; ok {
 		if fn, isOk := byTarget[to]; isOk && fn != nil {
-			return fn(model, rawJSON, stream)
+			translated = fn(model, rawJSON, stream)
 		}
 	}
-	return rawJSON
+	if mergeConsecutiveRolesEnabled(to) {
+		translated = MergeConsecutiveRoles(to, translated)
+	}
+	return translated
+}
+
+// HasRequestTransformer indicates whether a request translator exists.
+func (r *Registry) HasRequestTransformer(from, to Format) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if byTarget, ok := r.requests[from]; ok {
+		if fn, isOk := byTarget[to]; isOk && fn != nil {
+			return true
+		}
+	}
+	return false
 }
 
 // HasResponseTransformer indicates whether a response translator exists.
@@ -65,30 +84,62 @@ func (r *Registry) HasResponseTransformer(from, to Format) bool {
 	return false
 }
 
-// TranslateStream applies the registered streaming response translator.
+// HasStreamTransformer indicates whether a streaming response translator exists.
+func (r *Registry) HasStreamTransformer(from, to Format) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if byTarget, ok := r.responses[from]; ok {
+		if fn, isOk := byTarget[to]; isOk && fn.Stream != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ResponseTargets lists the formats that have a response translator registered for from, sorted by name.
+func (r *Registry) ResponseTargets(from Format) []Format {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	targets := make([]Format, 0, len(r.responses[from]))
+	for to := range r.responses[from] {
+		targets = append(targets, to)
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i] < targets[j] })
+	return targets
+}
+
+// TranslateStream applies the registered streaming response translator. Tool calls in the
+// output are added to the ToolCallCounter carried by ctx.
 func (r *Registry) TranslateStream(ctx context.Context, from, to Format, model string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) []string {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	out := []string{string(rawJSON)}
 	if byTarget, ok := r.responses[to]; ok {
 		if fn, isOk := byTarget[from]; isOk && fn.Stream != nil {
-			return fn.Stream(ctx, model, originalRequestRawJSON, requestRawJSON, rawJSON, param)
+			out = fn.Stream(ctx, model, originalRequestRawJSON, requestRawJSON, rawJSON, param)
 		}
 	}
-	return []string{string(rawJSON)}
+	countTranslatedToolCalls(ctx, out...)
+	return out
 }
 
-// TranslateNonStream applies the registered non-stream response translator.
+// TranslateNonStream applies the registered non-stream response translator. Tool calls in
+// the output are added to the ToolCallCounter carried by ctx.
 func (r *Registry) TranslateNonStream(ctx context.Context, from, to Format, model string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) string {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	out := string(rawJSON)
 	if byTarget, ok := r.responses[to]; ok {
 		if fn, isOk := byTarget[from]; isOk && fn.NonStream != nil {
-			return fn.NonStream(ctx, model, originalRequestRawJSON, requestRawJSON, rawJSON, param)
+			out = fn.NonStream(ctx, model, originalRequestRawJSON, requestRawJSON, rawJSON, param)
 		}
 	}
-	return string(rawJSON)
+	countTranslatedToolCalls(ctx, out)
+	return out
 }
 
 // TranslateNonStream applies the registered non-stream response translator.
@@ -121,11 +172,26 @@ func TranslateRequest(from, to Format, model string, rawJSON []byte, stream bool
 	return defaultRegistry.TranslateRequest(from, to, model, rawJSON, stream)
 }
 
+// HasRequestTransformer inspects the default registry.
+func HasRequestTransformer(from, to Format) bool {
+	return defaultRegistry.HasRequestTransformer(from, to)
+}
+
 // HasResponseTransformer inspects the default registry.
 func HasResponseTransformer(from, to Format) bool {
 	return defaultRegistry.HasResponseTransformer(from, to)
 }
 
+// HasStreamTransformer inspects the default registry.
+func HasStreamTransformer(from, to Format) bool {
+	return defaultRegistry.HasStreamTransformer(from, to)
+}
+
+// ResponseTargets lists response translator targets for from on the default registry.
+func ResponseTargets(from Format) []Format {
+	return defaultRegistry.ResponseTargets(from)
+}
+
 // TranslateStream is a helper on the default registry.
 func TranslateStream(ctx context.Context, from, to Format, model string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) []string {
 	return defaultRegistry.TranslateStream(ctx, from, to, model, originalRequestRawJSON, requestRawJSON, rawJSON, param)
@@ -0,0 +1,95 @@
+package translator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// StreamRecordingHeader describes how a recorded upstream stream was translated.
+// It is stored as the first line of a recording, followed by one line per payload
+// handed to the stream translator, each encoded as a JSON string.
+type StreamRecordingHeader struct {
+	// SourceFormat is the client-facing schema the stream was translated into.
+	SourceFormat Format `json:"source_format"`
+	// ProviderFormat is the upstream schema of the recorded bytes.
+	ProviderFormat Format `json:"provider_format"`
+	// Model is the model name passed to the stream translator.
+	Model string `json:"model"`
+	// OriginalRequest is the inbound client request prior to translation.
+	OriginalRequest string `json:"original_request,omitempty"`
+	// Request is the translated request sent upstream.
+	Request string `json:"request,omitempty"`
+}
+
+// WriteStreamRecordingHeader writes the recording header line to w.
+func WriteStreamRecordingHeader(w io.Writer, header StreamRecordingHeader) error {
+	raw, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(raw, '\n'))
+	return err
+}
+
+// WriteStreamRecordingPayload appends one stream translator input to a recording.
+// Payloads are JSON-encoded so embedded newlines and empty payloads survive the round trip.
+func WriteStreamRecordingPayload(w io.Writer, payload []byte) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(string(payload)); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// ReplayStream reads a stream recording from r and runs every recorded payload through
+// TranslateStreamByFormatName, returning the translated chunks in order.
+func ReplayStream(ctx context.Context, r io.Reader) ([]string, error) {
+	reader := bufio.NewReader(r)
+	headerLine, err := reader.ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("read stream recording header: %w", err)
+	}
+	var header StreamRecordingHeader
+	if err = json.Unmarshal(headerLine, &header); err != nil {
+		return nil, fmt.Errorf("decode stream recording header: %w", err)
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, 52_428_800) // 50MB
+	var param any
+	out := make([]string, 0)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var payload string
+		if err = json.Unmarshal(line, &payload); err != nil {
+			return out, fmt.Errorf("decode stream recording payload: %w", err)
+		}
+		chunks := TranslateStreamByFormatName(ctx, header.ProviderFormat, header.SourceFormat, header.Model, []byte(header.OriginalRequest), []byte(header.Request), []byte(payload), &param)
+		out = append(out, chunks...)
+	}
+	if err = scanner.Err(); err != nil {
+		return out, fmt.Errorf("read stream recording: %w", err)
+	}
+	return out, nil
+}
+
+// ReplayStreamFile replays the stream recording stored at path.
+func ReplayStreamFile(ctx context.Context, path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	return ReplayStream(ctx, file)
+}
@@ -0,0 +1,53 @@
+package translator
+
+import "fmt"
+
+// MissingPair describes a client -> provider format pair without a complete translator.
+type MissingPair struct {
+	From Format
+	To   Format
+	// MissingRequest and MissingResponse report which half of the pair is absent.
+	MissingRequest  bool
+	MissingResponse bool
+}
+
+// String renders the pair and the missing halves, e.g. "claude -> codex (request, response)".
+func (p MissingPair) String() string {
+	switch {
+	case p.MissingRequest && p.MissingResponse:
+		return fmt.Sprintf("%s -> %s (request, response)", p.From, p.To)
+	case p.MissingRequest:
+		return fmt.Sprintf("%s -> %s (request)", p.From, p.To)
+	default:
+		return fmt.Sprintf("%s -> %s (response)", p.From, p.To)
+	}
+}
+
+// CheckPairs verifies that every client format in clients can be translated to every
+// provider format in providers and back, returning the pairs lacking a request or response
+// translator. Identical formats need no translator and are skipped.
+func (r *Registry) CheckPairs(clients, providers []Format) []MissingPair {
+	var missing []MissingPair
+	for _, from := range clients {
+		for _, to := range providers {
+			if from == to {
+				continue
+			}
+			pair := MissingPair{
+				From:            from,
+				To:              to,
+				MissingRequest:  !r.HasRequestTransformer(from, to),
+				MissingResponse: !r.HasResponseTransformer(from, to),
+			}
+			if pair.MissingRequest || pair.MissingResponse {
+				missing = append(missing, pair)
+			}
+		}
+	}
+	return missing
+}
+
+// CheckPairs runs the translator self-test against the default registry.
+func CheckPairs(clients, providers []Format) []MissingPair {
+	return defaultRegistry.CheckPairs(clients, providers)
+}
@@ -0,0 +1,26 @@
+package translator
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRegistryCheckPairs_DetectsMissingPair(t *testing.T) {
+	registry := NewRegistry()
+	request := func(_ string, rawJSON []byte, _ bool) []byte { return rawJSON }
+	nonStream := func(context.Context, string, []byte, []byte, []byte, *any) string { return "" }
+	registry.Register(FormatClaude, FormatOpenAI, request, ResponseTransform{NonStream: nonStream})
+	// Response-only registration: the request half is deliberately missing.
+	registry.Register(FormatGemini, FormatOpenAI, nil, ResponseTransform{NonStream: nonStream})
+
+	missing := registry.CheckPairs([]Format{FormatOpenAI, FormatClaude, FormatGemini, FormatCodex}, []Format{FormatOpenAI})
+	if len(missing) != 2 {
+		t.Fatalf("missing = %v, want gemini and codex pairs", missing)
+	}
+	if got := missing[0]; got.From != FormatGemini || !got.MissingRequest || got.MissingResponse {
+		t.Fatalf("missing[0] = %+v, want gemini -> openai missing only its request translator", got)
+	}
+	if got := missing[1].String(); got != "codex -> openai (request, response)" {
+		t.Fatalf("missing[1] = %q", got)
+	}
+}
@@ -0,0 +1,131 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"sync/atomic"
+
+	"github.com/tidwall/gjson"
+)
+
+// ToolCallCounter accumulates the tool calls that translated responses hand to the client
+// during one upstream request.
+type ToolCallCounter struct {
+	n atomic.Int64
+}
+
+// Load returns the number of tool calls counted so far.
+func (c *ToolCallCounter) Load() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.n.Load()
+}
+
+// Reset discards the calls counted so far, e.g. when a stream is re-sent from the start.
+func (c *ToolCallCounter) Reset() {
+	if c != nil {
+		c.n.Store(0)
+	}
+}
+
+type toolCallCounterKey struct{}
+
+// WithToolCallCounter returns a child context whose response translations add the tool
+// calls they emit to counter.
+func WithToolCallCounter(ctx context.Context, counter *ToolCallCounter) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, toolCallCounterKey{}, counter)
+}
+
+// countTranslatedToolCalls adds the tool calls in translated response chunks to the
+// counter carried by ctx, if any.
+func countTranslatedToolCalls(ctx context.Context, chunks ...string) {
+	if ctx == nil {
+		return
+	}
+	counter, ok := ctx.Value(toolCallCounterKey{}).(*ToolCallCounter)
+	if !ok || counter == nil {
+		return
+	}
+	for _, chunk := range chunks {
+		if n := countToolCalls([]byte(chunk)); n > 0 {
+			counter.n.Add(n)
+		}
+	}
+}
+
+// countToolCalls counts the tool calls started in a response payload, either a full
+// response or stream chunks (bare JSON or SSE "data:" lines), across the OpenAI, Claude,
+// Gemini and Responses formats. Streamed OpenAI calls are counted on their first delta,
+// which carries the call ID.
+func countToolCalls(payload []byte) int64 {
+	payload = bytes.TrimSpace(payload)
+	if len(payload) == 0 {
+		return 0
+	}
+	if gjson.ValidBytes(payload) {
+		return countToolCallsIn(gjson.ParseBytes(payload))
+	}
+	var n int64
+	for _, line := range bytes.Split(payload, []byte("\n")) {
+		data, ok := bytes.CutPrefix(bytes.TrimSpace(line), []byte("data:"))
+		if !ok {
+			continue
+		}
+		if data = bytes.TrimSpace(data); gjson.ValidBytes(data) {
+			n += countToolCallsIn(gjson.ParseBytes(data))
+		}
+	}
+	return n
+}
+
+func countToolCallsIn(root gjson.Result) int64 {
+	var n int64
+	if root.IsArray() {
+		root.ForEach(func(_, item gjson.Result) bool {
+			n += countToolCallsIn(item)
+			return true
+		})
+		return n
+	}
+	root.Get("choices").ForEach(func(_, choice gjson.Result) bool {
+		n += int64(len(choice.Get("message.tool_calls").Array()))
+		choice.Get("delta.tool_calls").ForEach(func(_, call gjson.Result) bool {
+			if call.Get("id").String() != "" {
+				n++
+			}
+			return true
+		})
+		return true
+	})
+	switch root.Get("type").String() {
+	case "content_block_start":
+		if root.Get("content_block.type").String() == "tool_use" {
+			n++
+		}
+	case "message":
+		n += int64(len(root.Get(`content.#(type=="tool_use")#`).Array()))
+	}
+	candidates := root.Get("candidates")
+	if !candidates.Exists() {
+		candidates = root.Get("response.candidates")
+	}
+	candidates.ForEach(func(_, candidate gjson.Result) bool {
+		candidate.Get("content.parts").ForEach(func(_, part gjson.Result) bool {
+			if part.Get("functionCall").Exists() {
+				n++
+			}
+			return true
+		})
+		return true
+	})
+	output := root.Get("response.output")
+	if !output.Exists() && root.Get("object").String() == "response" {
+		output = root.Get("output")
+	}
+	n += int64(len(output.Get(`#(type=="function_call")#`).Array()))
+	return n
+}
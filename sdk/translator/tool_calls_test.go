@@ -0,0 +1,50 @@
+package translator
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRegistryTranslate_CountsToolCalls(t *testing.T) {
+	registry := NewRegistry()
+	client, upstream := Format("tool-call-client"), Format("tool-call-upstream")
+	registry.Register(client, upstream, nil, ResponseTransform{
+		Stream: func(_ context.Context, _ string, _, _, rawJSON []byte, _ *any) []string {
+			return []string{
+				"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"tool_use\",\"id\":\"t1\",\"name\":\"f\",\"input\":{}}}\n\n",
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"{}\"}}\n\n",
+			}
+		},
+		NonStream: func(_ context.Context, _ string, _, _, rawJSON []byte, _ *any) string {
+			return `{"choices":[{"message":{"role":"assistant","tool_calls":[{"id":"a","type":"function","function":{"name":"f","arguments":"{}"}},{"id":"b","type":"function","function":{"name":"g","arguments":"{}"}}]},"finish_reason":"tool_calls"}]}`
+		},
+	})
+
+	counter := &ToolCallCounter{}
+	ctx := WithToolCallCounter(context.Background(), counter)
+	registry.TranslateStream(ctx, upstream, client, "m", nil, nil, []byte(`{}`), nil)
+	if got := counter.Load(); got != 1 {
+		t.Fatalf("streamed tool calls = %d, want 1", got)
+	}
+	registry.TranslateNonStream(ctx, upstream, client, "m", nil, nil, []byte(`{}`), nil)
+	if got := counter.Load(); got != 3 {
+		t.Fatalf("tool calls after non-stream response = %d, want 3", got)
+	}
+
+	// Untranslated OpenAI deltas count a call on the delta carrying its ID.
+	counter.Reset()
+	for _, chunk := range []string{
+		`{"choices":[{"delta":{"tool_calls":[{"index":0,"id":"c1","function":{"name":"f","arguments":""}}]}}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{}"}}]}}]}`,
+	} {
+		registry.TranslateStream(ctx, upstream, upstream, "m", nil, nil, []byte(chunk), nil)
+	}
+	if got := counter.Load(); got != 1 {
+		t.Fatalf("passthrough tool calls = %d, want 1", got)
+	}
+
+	registry.TranslateStream(context.Background(), upstream, client, "m", nil, nil, []byte(`{}`), nil)
+	if got := counter.Load(); got != 1 {
+		t.Fatalf("translation without a counter changed it to %d", got)
+	}
+}
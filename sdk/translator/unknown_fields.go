@@ -0,0 +1,184 @@
+package translator
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Unknown request field actions.
+const (
+	// UnknownFieldsPassthrough forwards fields the target format does not define.
+	UnknownFieldsPassthrough = "passthrough"
+	// UnknownFieldsStrip removes fields the target format does not define.
+	UnknownFieldsStrip = "strip"
+	// UnknownFieldsError rejects requests carrying fields the target format does not define.
+	UnknownFieldsError = "error"
+)
+
+// knownRequestFields lists the top-level request fields each target format defines.
+// Formats without an entry are never checked.
+var knownRequestFields = map[Format]map[string]struct{}{
+	FormatClaude: fieldSet(
+		"model", "messages", "system", "max_tokens", "metadata", "stop_sequences", "stream",
+		"temperature", "top_p", "top_k", "tools", "tool_choice", "thinking", "service_tier",
+		"container", "mcp_servers", "context_management", "output_format", "output_config",
+		// Consumed by the Claude executor and sent as a header.
+		"betas",
+	),
+	FormatCodex: fieldSet(
+		"model", "input", "instructions", "tools", "tool_choice", "parallel_tool_calls",
+		"reasoning", "store", "stream", "stream_options", "include", "text", "temperature",
+		"top_p", "top_logprobs", "max_output_tokens", "max_tool_calls", "previous_response_id",
+		"metadata", "service_tier", "truncation", "user", "prompt", "prompt_cache_key",
+		"prompt_cache_retention", "safety_identifier", "background", "conversation",
+	),
+	FormatGemini: fieldSet(
+		"model", "contents", "systemInstruction", "system_instruction", "tools", "toolConfig",
+		"tool_config", "safetySettings", "safety_settings", "generationConfig",
+		"generation_config", "cachedContent", "cached_content", "labels",
+	),
+	FormatOpenAI: fieldSet(
+		"model", "messages", "stream", "stream_options", "temperature", "top_p", "n", "stop",
+		"max_tokens", "max_completion_tokens", "presence_penalty", "frequency_penalty",
+		"logit_bias", "logprobs", "top_logprobs", "user", "tools", "tool_choice",
+		"parallel_tool_calls", "response_format", "seed", "reasoning_effort", "service_tier",
+		"store", "metadata", "modalities", "audio", "prediction", "web_search_options",
+		"functions", "function_call", "verbosity", "prompt_cache_key", "safety_identifier",
+	),
+}
+
+func fieldSet(fields ...string) map[string]struct{} {
+	set := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		set[field] = struct{}{}
+	}
+	return set
+}
+
+// strictProviders lists providers whose upstream APIs reject unknown request fields, so
+// their requests are stripped unless configured otherwise.
+var strictProviders = map[string]struct{}{
+	"claude":   {},
+	"codex":    {},
+	"gemini":   {},
+	"vertex":   {},
+	"aistudio": {},
+}
+
+// unknownFieldActions holds the configured action per provider.
+var unknownFieldActions atomic.Value // map[string]string
+
+// SetUnknownFieldActions configures, per provider key, how request fields the target format
+// does not define are handled: "passthrough", "strip" or "error". Providers without an entry
+// strip when their upstream is known to be strict and pass through otherwise, so fields a
+// lenient upstream adds after knownRequestFields was written keep working.
+func SetUnknownFieldActions(actions map[string]string) {
+	set := make(map[string]string, len(actions))
+	for provider, action := range actions {
+		provider = strings.ToLower(strings.TrimSpace(provider))
+		action = strings.ToLower(strings.TrimSpace(action))
+		if provider == "" || action == "" {
+			continue
+		}
+		set[provider] = action
+	}
+	unknownFieldActions.Store(set)
+}
+
+// UnknownFieldAction returns the action applied to unknown request fields for provider.
+func UnknownFieldAction(provider string) string {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	actions, _ := unknownFieldActions.Load().(map[string]string)
+	switch actions[provider] {
+	case UnknownFieldsPassthrough:
+		return UnknownFieldsPassthrough
+	case UnknownFieldsStrip:
+		return UnknownFieldsStrip
+	case UnknownFieldsError:
+		return UnknownFieldsError
+	}
+	if _, ok := strictProviders[provider]; ok {
+		return UnknownFieldsStrip
+	}
+	return UnknownFieldsPassthrough
+}
+
+// UnknownRequestFieldsError reports request fields a strict target format does not define.
+type UnknownRequestFieldsError struct {
+	Format Format
+	Fields []string
+}
+
+func (e *UnknownRequestFieldsError) Error() string {
+	return fmt.Sprintf("request fields not supported by %s: %s", e.Format, strings.Join(e.Fields, ", "))
+}
+
+// StatusCode reports the error as a client error.
+func (e *UnknownRequestFieldsError) StatusCode() int { return http.StatusBadRequest }
+
+// UnknownRequestFields returns the sorted top-level fields of rawJSON that format to does
+// not define. It returns nil for formats without a known field list.
+func UnknownRequestFields(to Format, rawJSON []byte) []string {
+	known, ok := knownRequestFields[to]
+	if !ok || !gjson.ValidBytes(rawJSON) {
+		return nil
+	}
+	var unknown []string
+	gjson.ParseBytes(rawJSON).ForEach(func(key, _ gjson.Result) bool {
+		if _, isKnown := known[key.String()]; !isKnown {
+			unknown = append(unknown, key.String())
+		}
+		return true
+	})
+	sort.Strings(unknown)
+	return unknown
+}
+
+// TranslateRequestForProvider translates rawJSON from format from to format to like
+// TranslateRequest and then applies the unknown-field action configured for provider.
+// Executors use it for the request body they send upstream.
+func TranslateRequestForProvider(provider string, from, to Format, model string, rawJSON []byte, stream bool) ([]byte, error) {
+	return ApplyUnknownFieldAction(provider, to, TranslateRequest(from, to, model, rawJSON, stream))
+}
+
+// ApplyUnknownFieldAction handles the request fields of a body translated to format to that
+// the format does not define, following the action configured for provider. In error mode
+// it returns an *UnknownRequestFieldsError.
+func ApplyUnknownFieldAction(provider string, to Format, rawJSON []byte) ([]byte, error) {
+	action := UnknownFieldAction(provider)
+	if action == UnknownFieldsPassthrough {
+		return rawJSON, nil
+	}
+	unknown := UnknownRequestFields(to, rawJSON)
+	if len(unknown) == 0 {
+		return rawJSON, nil
+	}
+	if action == UnknownFieldsError {
+		return rawJSON, &UnknownRequestFieldsError{Format: to, Fields: unknown}
+	}
+	for _, field := range unknown {
+		if updated, err := sjson.DeleteBytes(rawJSON, gjsonEscape(field)); err == nil {
+			rawJSON = updated
+		}
+	}
+	return rawJSON, nil
+}
+
+// gjsonEscape escapes path syntax characters so field is addressed as a single key.
+func gjsonEscape(field string) string {
+	var b strings.Builder
+	for _, r := range field {
+		switch r {
+		case '.', '*', '?', '|', '#', '@', '\\', '!', '=', '<', '>', '%', ':':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,82 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/executor"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func TestIFlowReasoningSplitStreamToClaude(t *testing.T) {
+	upstream := []string{
+		`data: {"id":"c1","model":"minimax-m2","choices":[{"index":0,"delta":{"role":"assistant","reasoning_details":[{"type":"reasoning.text","id":"r1","format":"MiniMax-response-v1","index":0,"text":"Let me "}]}}]}`,
+		`data: {"id":"c1","model":"minimax-m2","choices":[{"index":0,"delta":{"reasoning_details":[{"type":"reasoning.text","id":"r1","format":"MiniMax-response-v1","index":0,"text":"think."}]}}]}`,
+		`data: {"id":"c1","model":"minimax-m2","choices":[{"index":0,"delta":{"content":"Hello"}}]}`,
+		`data: {"id":"c1","model":"minimax-m2","choices":[{"index":0,"delta":{},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":4,"total_tokens":7}}`,
+		`data: [DONE]`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(strings.Join(upstream, "\n\n") + "\n\n"))
+	}))
+	defer server.Close()
+
+	exec := executor.NewIFlowExecutor(&config.Config{})
+	auth := &cliproxyauth.Auth{Attributes: map[string]string{
+		"api_key":  "test",
+		"base_url": server.URL,
+	}}
+	payload := []byte(`{"model":"minimax-m2","max_tokens":64,"stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	result, err := exec.ExecuteStream(context.Background(), auth, cliproxyexecutor.Request{
+		Model:   "minimax-m2",
+		Payload: payload,
+	}, cliproxyexecutor.Options{
+		Stream:          true,
+		SourceFormat:    sdktranslator.FromString("claude"),
+		OriginalRequest: payload,
+	})
+	if err != nil {
+		t.Fatalf("ExecuteStream error: %v", err)
+	}
+
+	var thinking, text strings.Builder
+	var blockTypes []string
+	for chunk := range result.Chunks {
+		if chunk.Err != nil {
+			t.Fatalf("stream error: %v", chunk.Err)
+		}
+		for _, line := range strings.Split(string(chunk.Payload), "\n") {
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			event := gjson.Parse(strings.TrimPrefix(line, "data: "))
+			switch event.Get("type").String() {
+			case "content_block_start":
+				blockTypes = append(blockTypes, event.Get("content_block.type").String())
+			case "content_block_delta":
+				thinking.WriteString(event.Get("delta.thinking").String())
+				text.WriteString(event.Get("delta.text").String())
+			}
+		}
+	}
+
+	if strings.Join(blockTypes, ",") != "thinking,text" {
+		t.Fatalf("content blocks = %v, want [thinking text]", blockTypes)
+	}
+	if got := thinking.String(); got != "Let me think." {
+		t.Fatalf("thinking = %q, want %q", got, "Let me think.")
+	}
+	if got := text.String(); got != "Hello" {
+		t.Fatalf("text = %q, want %q", got, "Hello")
+	}
+}
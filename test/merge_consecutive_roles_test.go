@@ -0,0 +1,77 @@
+package test
+
+import (
+	"testing"
+
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator"
+
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func TestOpenAIToClaude_MergesConsecutiveUserMessages(t *testing.T) {
+	sdktranslator.SetMergeConsecutiveRoles(sdktranslator.FormatClaude)
+	t.Cleanup(func() { sdktranslator.SetMergeConsecutiveRoles() })
+
+	in := []byte(`{
+		"model":"claude-sonnet-4-5",
+		"messages":[
+			{"role":"user","content":"first question"},
+			{"role":"user","content":[{"type":"text","text":"second question"}]},
+			{"role":"assistant","content":"answer"}
+		]
+	}`)
+
+	out := sdktranslator.TranslateRequest(sdktranslator.FormatOpenAI, sdktranslator.FormatClaude, "claude-sonnet-4-5", in, false)
+
+	if got := gjson.GetBytes(out, "messages.#").Int(); got != 2 {
+		t.Fatalf("expected 2 messages after merging, got %d: %s", got, string(out))
+	}
+	if got := gjson.GetBytes(out, "messages.0.role").String(); got != "user" {
+		t.Fatalf("messages[0].role = %q, want user", got)
+	}
+	var texts []string
+	for _, block := range gjson.GetBytes(out, "messages.0.content").Array() {
+		texts = append(texts, block.Get("text").String())
+	}
+	if len(texts) != 2 || texts[0] != "first question" || texts[1] != "second question" {
+		t.Fatalf("merged user content = %v, want both questions in order: %s", texts, string(out))
+	}
+	if got := gjson.GetBytes(out, "messages.1.role").String(); got != "assistant" {
+		t.Fatalf("messages[1].role = %q, want assistant", got)
+	}
+}
+
+func TestClaudePassthrough_MergeKeepsToolResultsFirst(t *testing.T) {
+	sdktranslator.SetMergeConsecutiveRoles(sdktranslator.FormatClaude)
+	t.Cleanup(func() { sdktranslator.SetMergeConsecutiveRoles() })
+
+	in := []byte(`{"messages":[
+		{"role":"assistant","content":[{"type":"tool_use","id":"toolu_1","name":"lookup","input":{}}]},
+		{"role":"user","content":"also consider this"},
+		{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"42"}]}
+	]}`)
+
+	out := sdktranslator.TranslateRequest(sdktranslator.FormatClaude, sdktranslator.FormatClaude, "claude-sonnet-4-5", in, false)
+
+	if got := gjson.GetBytes(out, "messages.#").Int(); got != 2 {
+		t.Fatalf("expected 2 messages after merging, got %d: %s", got, string(out))
+	}
+	if got := gjson.GetBytes(out, "messages.1.content.0.type").String(); got != "tool_result" {
+		t.Fatalf("first block = %q, want tool_result: %s", got, string(out))
+	}
+	if got := gjson.GetBytes(out, "messages.1.content.1.text").String(); got != "also consider this" {
+		t.Fatalf("second block text = %q: %s", got, string(out))
+	}
+}
+
+func TestMergeConsecutiveRolesDisabledByDefault(t *testing.T) {
+	sdktranslator.SetMergeConsecutiveRoles()
+
+	in := []byte(`{"messages":[{"role":"user","content":"a"},{"role":"user","content":"b"}]}`)
+	out := sdktranslator.TranslateRequest(sdktranslator.FormatClaude, sdktranslator.FormatClaude, "claude-sonnet-4-5", in, false)
+
+	if got := gjson.GetBytes(out, "messages.#").Int(); got != 2 {
+		t.Fatalf("messages were merged without opting in: %s", string(out))
+	}
+}
@@ -0,0 +1,45 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator"
+
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func TestOpenAIToGemini_NMapsToCandidateCount(t *testing.T) {
+	in := []byte(`{"model":"gemini-2.5-pro","n":2,"messages":[{"role":"user","content":"name a color"}]}`)
+
+	req := sdktranslator.TranslateRequest(sdktranslator.FormatOpenAI, sdktranslator.FormatGemini, "gemini-2.5-pro", in, false)
+	if got := gjson.GetBytes(req, "generationConfig.candidateCount").Int(); got != 2 {
+		t.Fatalf("generationConfig.candidateCount = %d, want 2: %s", got, string(req))
+	}
+
+	upstream := []byte(`{
+		"candidates":[
+			{"index":0,"content":{"role":"model","parts":[{"text":"red"}]},"finishReason":"STOP"},
+			{"index":1,"content":{"role":"model","parts":[{"text":"blue"}]},"finishReason":"STOP"}
+		],
+		"usageMetadata":{"promptTokenCount":4,"candidatesTokenCount":2,"totalTokenCount":6},
+		"modelVersion":"gemini-2.5-pro",
+		"responseId":"resp-1"
+	}`)
+	var param any
+	out := sdktranslator.TranslateNonStream(context.Background(), sdktranslator.FormatGemini, sdktranslator.FormatOpenAI, "gemini-2.5-pro", in, req, upstream, &param)
+
+	choices := gjson.Get(out, "choices").Array()
+	if len(choices) != 2 {
+		t.Fatalf("choices = %d, want 2: %s", len(choices), out)
+	}
+	for i, want := range []string{"red", "blue"} {
+		if got := choices[i].Get("index").Int(); got != int64(i) {
+			t.Fatalf("choices[%d].index = %d", i, got)
+		}
+		if got := choices[i].Get("message.content").String(); got != want {
+			t.Fatalf("choices[%d].message.content = %q, want %q", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package test
+
+import (
+	"testing"
+
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator"
+
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+const samplingOpenAIRequest = `{"model":"m","temperature":1.5,"top_p":0.9,"top_k":40,"frequency_penalty":0.5,"presence_penalty":0.3,"seed":42,"messages":[{"role":"user","content":"hi"}]}`
+
+func TestSamplingParams_OpenAIToGemini(t *testing.T) {
+	out := sdktranslator.TranslateRequest(sdktranslator.FormatOpenAI, sdktranslator.FormatGemini, "gemini-2.5-pro", []byte(samplingOpenAIRequest), false)
+
+	gen := gjson.GetBytes(out, "generationConfig")
+	if got := gen.Get("temperature").Float(); got != 1.5 {
+		t.Fatalf("generationConfig.temperature = %v, want 1.5: %s", got, out)
+	}
+	if got := gen.Get("topP").Float(); got != 0.9 {
+		t.Fatalf("generationConfig.topP = %v, want 0.9: %s", got, out)
+	}
+	if got := gen.Get("topK").Int(); got != 40 {
+		t.Fatalf("generationConfig.topK = %v, want 40: %s", got, out)
+	}
+	if got := gen.Get("frequencyPenalty").Float(); got != 0.5 {
+		t.Fatalf("generationConfig.frequencyPenalty = %v, want 0.5: %s", got, out)
+	}
+	if got := gen.Get("presencePenalty").Float(); got != 0.3 {
+		t.Fatalf("generationConfig.presencePenalty = %v, want 0.3: %s", got, out)
+	}
+	if got := gen.Get("seed").Int(); got != 42 {
+		t.Fatalf("generationConfig.seed = %v, want 42: %s", got, out)
+	}
+	for _, field := range []string{"top_p", "frequency_penalty", "presence_penalty", "seed"} {
+		if gjson.GetBytes(out, field).Exists() {
+			t.Fatalf("OpenAI field %s was passed to Gemini untranslated: %s", field, out)
+		}
+	}
+}
+
+func TestSamplingParams_GeminiOpenAIRoundTrip(t *testing.T) {
+	gemini := sdktranslator.TranslateRequest(sdktranslator.FormatOpenAI, sdktranslator.FormatGemini, "gemini-2.5-pro", []byte(samplingOpenAIRequest), false)
+	out := sdktranslator.TranslateRequest(sdktranslator.FormatGemini, sdktranslator.FormatOpenAI, "gpt-4o", gemini, false)
+
+	for field, want := range map[string]float64{"frequency_penalty": 0.5, "presence_penalty": 0.3, "seed": 42} {
+		if got := gjson.GetBytes(out, field).Float(); got != want {
+			t.Fatalf("%s = %v after round trip, want %v: %s", field, got, want, out)
+		}
+	}
+}
+
+func TestSamplingParams_OpenAIToClaude(t *testing.T) {
+	out := sdktranslator.TranslateRequest(sdktranslator.FormatOpenAI, sdktranslator.FormatClaude, "claude-sonnet-4-5", []byte(samplingOpenAIRequest), false)
+
+	if got := gjson.GetBytes(out, "temperature").Float(); got != 1 {
+		t.Fatalf("temperature = %v, want 1.5 clamped to Claude's maximum 1: %s", got, out)
+	}
+	if gjson.GetBytes(out, "top_p").Exists() {
+		t.Fatalf("top_p must be dropped when temperature is set for Claude: %s", out)
+	}
+	if got := gjson.GetBytes(out, "top_k").Int(); got != 40 {
+		t.Fatalf("top_k = %v, want 40: %s", got, out)
+	}
+	for _, field := range []string{"frequency_penalty", "presence_penalty"} {
+		if gjson.GetBytes(out, field).Exists() {
+			t.Fatalf("unsupported field %s was passed to Claude: %s", field, out)
+		}
+	}
+
+	topPOnly := `{"model":"m","top_p":0.8,"messages":[{"role":"user","content":"hi"}]}`
+	out = sdktranslator.TranslateRequest(sdktranslator.FormatOpenAI, sdktranslator.FormatClaude, "claude-sonnet-4-5", []byte(topPOnly), false)
+	if got := gjson.GetBytes(out, "top_p").Float(); got != 0.8 || gjson.GetBytes(out, "temperature").Exists() {
+		t.Fatalf("top_p-only request mapped to %s, want top_p 0.8 without temperature", out)
+	}
+}
+
+func TestSamplingParams_OpenAIResponsesToClaude(t *testing.T) {
+	in := `{"model":"m","temperature":0.4,"top_p":0.7,"input":"hi"}`
+	out := sdktranslator.TranslateRequest(sdktranslator.FormatOpenAIResponse, sdktranslator.FormatClaude, "claude-sonnet-4-5", []byte(in), false)
+
+	if got := gjson.GetBytes(out, "temperature").Float(); got != 0.4 {
+		t.Fatalf("temperature = %v, want 0.4: %s", got, out)
+	}
+	if gjson.GetBytes(out, "top_p").Exists() {
+		t.Fatalf("top_p must be dropped when temperature is set for Claude: %s", out)
+	}
+}
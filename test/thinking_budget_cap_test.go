@@ -0,0 +1,66 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/tidwall/gjson"
+)
+
+func TestApplyThinking_ClaudeBudgetClampedBelowMaxTokens(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	uid := fmt.Sprintf("thinking-budget-cap-%d", time.Now().UnixNano())
+	reg.RegisterClient(uid, "claude", getTestModels())
+	defer reg.UnregisterClient(uid)
+
+	body := []byte(`{"model":"claude-budget-model","max_tokens":4096,"messages":[{"role":"user","content":"hi"}]}`)
+
+	cases := []struct {
+		name  string
+		ratio float64
+		want  int64
+	}{
+		{name: "strict", ratio: 0, want: 4095},
+		{name: "ratio", ratio: 0.5, want: 2048},
+		// A ratio cap below the model minimum falls back to the minimum.
+		{name: "ratio below minimum", ratio: 0.1, want: 1024},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			thinking.SetBudgetMaxTokensRatio(tc.ratio)
+			t.Cleanup(func() { thinking.SetBudgetMaxTokensRatio(0) })
+
+			out, err := thinking.ApplyThinking(body, "claude-budget-model(32000)", "claude", "claude", "claude")
+			if err != nil {
+				t.Fatalf("ApplyThinking: %v", err)
+			}
+			budget := gjson.GetBytes(out, "thinking.budget_tokens").Int()
+			maxTokens := gjson.GetBytes(out, "max_tokens").Int()
+			if maxTokens != 4096 {
+				t.Fatalf("max_tokens = %d, want the request value 4096", maxTokens)
+			}
+			if budget != tc.want || budget >= maxTokens {
+				t.Fatalf("budget_tokens = %d, want %d (< max_tokens %d): %s", budget, tc.want, maxTokens, string(out))
+			}
+		})
+	}
+}
+
+func TestApplyThinking_ClaudeBudgetBelowMaxTokensUnchanged(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	uid := fmt.Sprintf("thinking-budget-cap-unchanged-%d", time.Now().UnixNano())
+	reg.RegisterClient(uid, "claude", getTestModels())
+	defer reg.UnregisterClient(uid)
+
+	body := []byte(`{"model":"claude-budget-model","max_tokens":64000,"messages":[{"role":"user","content":"hi"}]}`)
+	out, err := thinking.ApplyThinking(body, "claude-budget-model(8192)", "claude", "claude", "claude")
+	if err != nil {
+		t.Fatalf("ApplyThinking: %v", err)
+	}
+	if got := gjson.GetBytes(out, "thinking.budget_tokens").Int(); got != 8192 {
+		t.Fatalf("budget_tokens = %d, want 8192 unchanged", got)
+	}
+}
@@ -2719,6 +2719,208 @@ func TestThinkingE2EClaudeAdaptive_Body(t *testing.T) {
 	runThinkingTests(t, cases)
 }
 
+// TestThinkingE2EModelDefault tests per-model default thinking (ModelInfo.DefaultThinking).
+// The default applies only when neither suffix nor body carries thinking config,
+// and is clamped to the model's thinking support.
+func TestThinkingE2EModelDefault(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	uid := fmt.Sprintf("thinking-e2e-model-default-%d", time.Now().UnixNano())
+
+	reg.RegisterClient(uid, "test", getDefaultThinkingTestModels())
+	defer reg.UnregisterClient(uid)
+
+	cases := []thinkingTestCase{
+		// D1: No suffix, no body → model default low
+		{
+			name:        "D1",
+			from:        "openai",
+			to:          "openai",
+			model:       "default-low-level-model",
+			inputJSON:   `{"model":"default-low-level-model","messages":[{"role":"user","content":"hi"}]}`,
+			expectField: "reasoning_effort",
+			expectValue: "low",
+			expectErr:   false,
+		},
+		// D2: Suffix overrides model default
+		{
+			name:        "D2",
+			from:        "openai",
+			to:          "openai",
+			model:       "default-low-level-model(high)",
+			inputJSON:   `{"model":"default-low-level-model(high)","messages":[{"role":"user","content":"hi"}]}`,
+			expectField: "reasoning_effort",
+			expectValue: "high",
+			expectErr:   false,
+		},
+		// D3: Body param overrides model default
+		{
+			name:        "D3",
+			from:        "openai",
+			to:          "openai",
+			model:       "default-low-level-model",
+			inputJSON:   `{"model":"default-low-level-model","messages":[{"role":"user","content":"hi"}],"reasoning_effort":"high"}`,
+			expectField: "reasoning_effort",
+			expectValue: "high",
+			expectErr:   false,
+		},
+		// D4: Budget model, default low → 1024
+		{
+			name:            "D4",
+			from:            "gemini",
+			to:              "gemini",
+			model:           "default-low-budget-model",
+			inputJSON:       `{"contents":[{"role":"user","parts":[{"text":"hi"}]}]}`,
+			expectField:     "generationConfig.thinkingConfig.thinkingBudget",
+			expectValue:     "1024",
+			includeThoughts: "true",
+			expectErr:       false,
+		},
+		// D5: Claude budget model, default low → 1024
+		{
+			name:        "D5",
+			from:        "claude",
+			to:          "claude",
+			model:       "default-low-claude-model",
+			inputJSON:   `{"model":"default-low-claude-model","messages":[{"role":"user","content":"hi"}]}`,
+			expectField: "thinking.budget_tokens",
+			expectValue: "1024",
+			expectErr:   false,
+		},
+		// D6: Default xhigh on low/high model → clamped to high
+		{
+			name:        "D6",
+			from:        "openai",
+			to:          "openai",
+			model:       "default-xhigh-subset-model",
+			inputJSON:   `{"model":"default-xhigh-subset-model","messages":[{"role":"user","content":"hi"}]}`,
+			expectField: "reasoning_effort",
+			expectValue: "high",
+			expectErr:   false,
+		},
+		// D7: Default budget above max → clamped to max
+		{
+			name:            "D7",
+			from:            "gemini",
+			to:              "gemini",
+			model:           "default-over-budget-model",
+			inputJSON:       `{"contents":[{"role":"user","parts":[{"text":"hi"}]}]}`,
+			expectField:     "generationConfig.thinkingConfig.thinkingBudget",
+			expectValue:     "20000",
+			includeThoughts: "true",
+			expectErr:       false,
+		},
+	}
+
+	runThinkingTests(t, cases)
+}
+
+// TestThinkingE2EAutoFallbackMax tests auto/-1 on models without dynamic thinking under
+// the "max" auto fallback policy.
+func TestThinkingE2EAutoFallbackMax(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	uid := fmt.Sprintf("thinking-e2e-auto-fallback-%d", time.Now().UnixNano())
+
+	reg.RegisterClient(uid, "test", getTestModels())
+	defer reg.UnregisterClient(uid)
+
+	thinking.SetAutoFallbackPolicy("max")
+	defer thinking.SetAutoFallbackPolicy("")
+
+	cases := []thinkingTestCase{
+		// F1: Level auto → DynamicAllowed=false → high (highest supported level)
+		{
+			name:        "F1",
+			from:        "openai",
+			to:          "codex",
+			model:       "level-model(auto)",
+			inputJSON:   `{"model":"level-model(auto)","messages":[{"role":"user","content":"hi"}]}`,
+			expectField: "reasoning.effort",
+			expectValue: "high",
+			expectErr:   false,
+		},
+		// F2: Budget -1 → auto → DynamicAllowed=false → high
+		{
+			name:        "F2",
+			from:        "gemini",
+			to:          "codex",
+			model:       "level-model(-1)",
+			inputJSON:   `{"model":"level-model(-1)","contents":[{"role":"user","parts":[{"text":"hi"}]}]}`,
+			expectField: "reasoning.effort",
+			expectValue: "high",
+			expectErr:   false,
+		},
+		// F3: Budget -1 → auto → DynamicAllowed=false → 128000 (max budget)
+		{
+			name:        "F3",
+			from:        "claude",
+			to:          "claude",
+			model:       "claude-budget-model(-1)",
+			inputJSON:   `{"model":"claude-budget-model(-1)","messages":[{"role":"user","content":"hi"}]}`,
+			expectField: "thinking.budget_tokens",
+			expectValue: "128000",
+			expectErr:   false,
+		},
+	}
+
+	runThinkingTests(t, cases)
+}
+
+// getDefaultThinkingTestModels returns model definitions carrying DefaultThinking.
+func getDefaultThinkingTestModels() []*registry.ModelInfo {
+	return []*registry.ModelInfo{
+		{
+			ID:              "default-low-level-model",
+			Object:          "model",
+			Created:         1700000000,
+			OwnedBy:         "test",
+			Type:            "openai",
+			DisplayName:     "Default Low Level Model",
+			Thinking:        &registry.ThinkingSupport{Levels: []string{"minimal", "low", "medium", "high"}, ZeroAllowed: false, DynamicAllowed: false},
+			DefaultThinking: "low",
+		},
+		{
+			ID:              "default-low-budget-model",
+			Object:          "model",
+			Created:         1700000000,
+			OwnedBy:         "test",
+			Type:            "gemini",
+			DisplayName:     "Default Low Budget Model",
+			Thinking:        &registry.ThinkingSupport{Min: 128, Max: 20000, ZeroAllowed: false, DynamicAllowed: true},
+			DefaultThinking: "low",
+		},
+		{
+			ID:              "default-low-claude-model",
+			Object:          "model",
+			Created:         1700000000,
+			OwnedBy:         "test",
+			Type:            "claude",
+			DisplayName:     "Default Low Claude Model",
+			Thinking:        &registry.ThinkingSupport{Min: 1024, Max: 128000, ZeroAllowed: true, DynamicAllowed: false},
+			DefaultThinking: "low",
+		},
+		{
+			ID:              "default-xhigh-subset-model",
+			Object:          "model",
+			Created:         1700000000,
+			OwnedBy:         "test",
+			Type:            "openai",
+			DisplayName:     "Default Xhigh Subset Model",
+			Thinking:        &registry.ThinkingSupport{Levels: []string{"low", "high"}, ZeroAllowed: false, DynamicAllowed: false},
+			DefaultThinking: "xhigh",
+		},
+		{
+			ID:              "default-over-budget-model",
+			Object:          "model",
+			Created:         1700000000,
+			OwnedBy:         "test",
+			Type:            "gemini",
+			DisplayName:     "Default Over Budget Model",
+			Thinking:        &registry.ThinkingSupport{Min: 128, Max: 20000, ZeroAllowed: false, DynamicAllowed: true},
+			DefaultThinking: "64000",
+		},
+	}
+}
+
 // getTestModels returns the shared model definitions for E2E tests.
 func getTestModels() []*registry.ModelInfo {
 	return []*registry.ModelInfo{
@@ -0,0 +1,148 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+)
+
+func setMalformedSuffixAction(t *testing.T, action string) {
+	t.Helper()
+	previous := thinking.GetMalformedSuffixAction()
+	thinking.SetMalformedSuffixAction(action)
+	t.Cleanup(func() { thinking.SetMalformedSuffixAction(string(previous)) })
+}
+
+func TestParseSuffix_MalformedSuffixActions(t *testing.T) {
+	cases := []struct {
+		action    string
+		input     string
+		wantModel string
+	}{
+		{action: "strip", input: "level-model(med ium)", wantModel: "level-model"},
+		{action: "strip", input: "level-model(medium", wantModel: "level-model"},
+		{action: "strip", input: "level-model((high))", wantModel: "level-model"},
+		{action: "error", input: "level-model(med ium)", wantModel: "level-model"},
+		{action: "error", input: "level-model)", wantModel: "level-model"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(fmt.Sprintf("%s_%s", tc.action, tc.input), func(t *testing.T) {
+			setMalformedSuffixAction(t, tc.action)
+			result := thinking.ParseSuffix(tc.input)
+			if !result.Malformed {
+				t.Fatalf("expected %q to be reported as malformed", tc.input)
+			}
+			if result.HasSuffix {
+				t.Fatalf("expected HasSuffix=false for malformed input %q", tc.input)
+			}
+			if result.ModelName != tc.wantModel {
+				t.Fatalf("ModelName = %q, want %q", result.ModelName, tc.wantModel)
+			}
+		})
+	}
+}
+
+func TestParseSuffix_MalformedSuffixIgnoreKeepsBaselineParsing(t *testing.T) {
+	setMalformedSuffixAction(t, "ignore")
+
+	cases := []struct {
+		input      string
+		wantModel  string
+		wantSuffix string
+		hasSuffix  bool
+	}{
+		{input: "level-model(med ium)", wantModel: "level-model", wantSuffix: "med ium", hasSuffix: true},
+		{input: "level-model((high))", wantModel: "level-model(", wantSuffix: "high)", hasSuffix: true},
+		{input: "level-model()", wantModel: "level-model", hasSuffix: true},
+		{input: "level-model(medium", wantModel: "level-model(medium"},
+		{input: "level-model)", wantModel: "level-model)"},
+	}
+	for _, tc := range cases {
+		result := thinking.ParseSuffix(tc.input)
+		if result.Malformed || result.HasSuffix != tc.hasSuffix || result.ModelName != tc.wantModel || result.RawSuffix != tc.wantSuffix {
+			t.Fatalf("ParseSuffix(%q) = %+v, want model %q suffix %q", tc.input, result, tc.wantModel, tc.wantSuffix)
+		}
+	}
+}
+
+func TestParseSuffix_WellFormedNamesUnaffected(t *testing.T) {
+	for _, action := range []string{"ignore", "strip", "error"} {
+		setMalformedSuffixAction(t, action)
+
+		if result := thinking.ParseSuffix("level-model(high)"); !result.HasSuffix || result.Malformed || result.ModelName != "level-model" || result.RawSuffix != "high" {
+			t.Fatalf("[%s] well-formed suffix parsed as %+v", action, result)
+		}
+		if result := thinking.ParseSuffix("foo(bar)-baz"); result.HasSuffix || result.Malformed || result.ModelName != "foo(bar)-baz" {
+			t.Fatalf("[%s] mid-name parentheses parsed as %+v", action, result)
+		}
+		if result := thinking.ParseSuffix("level-model()"); result.Malformed || result.ModelName != "level-model" {
+			t.Fatalf("[%s] empty suffix parsed as %+v", action, result)
+		}
+	}
+}
+
+func TestParseSuffix_RegisteredParenthesizedNameHasNoSuffix(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	uid := fmt.Sprintf("thinking-suffix-registered-paren-%d", time.Now().UnixNano())
+	reg.RegisterClient(uid, "test", []*registry.ModelInfo{
+		{ID: "paren-model(beta)", Object: "model", OwnedBy: "test"},
+		{ID: "legacy paren model (v1 beta)", Object: "model", OwnedBy: "test"},
+	})
+	defer reg.UnregisterClient(uid)
+
+	for _, action := range []string{"ignore", "strip", "error"} {
+		setMalformedSuffixAction(t, action)
+		for _, model := range []string{"paren-model(beta)", "legacy paren model (v1 beta)"} {
+			if result := thinking.ParseSuffix(model); result.HasSuffix || result.Malformed || result.ModelName != model {
+				t.Fatalf("[%s] registered %q parsed as %+v", action, model, result)
+			}
+		}
+	}
+}
+
+func TestParseSuffix_NeverReturnsEmptyModelName(t *testing.T) {
+	for _, action := range []string{"ignore", "strip", "error"} {
+		setMalformedSuffixAction(t, action)
+		for _, model := range []string{"(model", "(high)", "()", ")model"} {
+			if result := thinking.ParseSuffix(model); result.ModelName == "" {
+				t.Fatalf("[%s] ParseSuffix(%q) returned an empty model name: %+v", action, model, result)
+			}
+		}
+	}
+}
+
+func TestApplyThinking_MalformedSuffixError(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	uid := fmt.Sprintf("thinking-malformed-suffix-apply-%d", time.Now().UnixNano())
+	reg.RegisterClient(uid, "test", getTestModels())
+	defer reg.UnregisterClient(uid)
+
+	body := []byte(`{"model":"level-model","messages":[{"role":"user","content":"hi"}]}`)
+
+	setMalformedSuffixAction(t, "error")
+	_, err := thinking.ApplyThinking(body, "level-model(med ium)", "openai", "openai", "openai")
+	var thinkingErr *thinking.ThinkingError
+	if !errors.As(err, &thinkingErr) || thinkingErr.Code != thinking.ErrInvalidSuffix {
+		t.Fatalf("expected ErrInvalidSuffix, got %v", err)
+	}
+
+	setMalformedSuffixAction(t, "strip")
+	if _, err = thinking.ApplyThinking(body, "level-model(med ium)", "openai", "openai", "openai"); err != nil {
+		t.Fatalf("expected strip mode to succeed, got %v", err)
+	}
+
+	// A model registered under a parenthesized name is not a malformed suffix.
+	legacyUID := fmt.Sprintf("thinking-malformed-suffix-legacy-%d", time.Now().UnixNano())
+	reg.RegisterClient(legacyUID, "test", []*registry.ModelInfo{{ID: "legacy model (v1 beta)", Object: "model", OwnedBy: "test"}})
+	defer reg.UnregisterClient(legacyUID)
+	setMalformedSuffixAction(t, "error")
+	if _, err = thinking.ApplyThinking(body, "legacy model (v1 beta)", "openai", "openai", "openai"); err != nil {
+		t.Fatalf("registered parenthesized model rejected: %v", err)
+	}
+}
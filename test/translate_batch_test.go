@@ -0,0 +1,56 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator"
+
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func TestTranslateRequestBatch_OrderAndErrors(t *testing.T) {
+	requests := make([][]byte, 50)
+	for i := range requests {
+		requests[i] = fmt.Appendf(nil, `{"model":"gpt-4o","messages":[{"role":"user","content":"message %d"}]}`, i)
+	}
+	requests[7] = []byte(`{"model":"gpt-4o","messages":[`)
+
+	results := sdktranslator.TranslateRequestBatch(sdktranslator.FormatOpenAI, sdktranslator.FormatGemini, "gemini-2.5-pro", requests, false)
+	if len(results) != len(requests) {
+		t.Fatalf("results = %d, want %d", len(results), len(requests))
+	}
+	for i, result := range results {
+		if i == 7 {
+			if result.Err == nil || result.Payload != nil {
+				t.Fatalf("result[7] = %+v, want an error for invalid JSON", result)
+			}
+			continue
+		}
+		if result.Err != nil {
+			t.Fatalf("result[%d] error: %v", i, result.Err)
+		}
+		want := fmt.Sprintf("message %d", i)
+		if got := gjson.GetBytes(result.Payload, "contents.0.parts.0.text").String(); got != want {
+			t.Fatalf("result[%d] text = %q, want %q: %s", i, got, want, result.Payload)
+		}
+	}
+
+	if results = sdktranslator.TranslateRequestBatch(sdktranslator.FormatOpenAI, sdktranslator.FormatGemini, "gemini-2.5-pro", nil, false); len(results) != 0 {
+		t.Fatalf("empty batch returned %d results", len(results))
+	}
+}
+
+func TestTranslateRequestBatch_UnsupportedPair(t *testing.T) {
+	requests := [][]byte{[]byte(`{"model":"gpt-4o","messages":[]}`)}
+	results := sdktranslator.TranslateRequestBatch(sdktranslator.FormatOpenAI, sdktranslator.FromString("unknown-format"), "gpt-4o", requests, false)
+	if len(results) != 1 || results[0].Err == nil || results[0].Payload != nil {
+		t.Fatalf("results = %+v, want an error for an unsupported format pair", results)
+	}
+
+	results = sdktranslator.TranslateRequestBatch(sdktranslator.FormatOpenAI, sdktranslator.FormatOpenAI, "gpt-4o", requests, false)
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("results = %+v, want same-format requests to pass through", results)
+	}
+}
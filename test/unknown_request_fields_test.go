@@ -0,0 +1,71 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator"
+
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func TestUnknownRequestFields_StrippedForStrictTargetPreservedForLenient(t *testing.T) {
+	sdktranslator.SetUnknownFieldActions(nil)
+	t.Cleanup(func() { sdktranslator.SetUnknownFieldActions(nil) })
+
+	claudeIn := []byte(`{"model":"claude-sonnet-4-5","max_tokens":64,"foo":"bar","messages":[{"role":"user","content":"hi"}]}`)
+	out, err := sdktranslator.TranslateRequestForProvider("claude", sdktranslator.FormatClaude, sdktranslator.FormatClaude, "claude-sonnet-4-5", claudeIn, false)
+	if err != nil {
+		t.Fatalf("strict target: %v", err)
+	}
+	if gjson.GetBytes(out, "foo").Exists() {
+		t.Fatalf("unknown field foo kept for a strict target: %s", out)
+	}
+	if gjson.GetBytes(out, "max_tokens").Int() != 64 || !gjson.GetBytes(out, "messages").IsArray() {
+		t.Fatalf("known fields were altered: %s", out)
+	}
+
+	openAIIn := []byte(`{"model":"gpt-4o","foo":"bar","messages":[{"role":"user","content":"hi"}]}`)
+	out, err = sdktranslator.TranslateRequestForProvider("my-openai-compat", sdktranslator.FormatOpenAI, sdktranslator.FormatOpenAI, "gpt-4o", openAIIn, false)
+	if err != nil {
+		t.Fatalf("lenient target: %v", err)
+	}
+	if got := gjson.GetBytes(out, "foo").String(); got != "bar" {
+		t.Fatalf("unknown field foo = %q for a lenient target, want preserved: %s", got, out)
+	}
+}
+
+func TestUnknownRequestFields_ConfiguredPassthroughOverridesStrictDefault(t *testing.T) {
+	sdktranslator.SetUnknownFieldActions(map[string]string{"claude": "passthrough"})
+	t.Cleanup(func() { sdktranslator.SetUnknownFieldActions(nil) })
+
+	claudeIn := []byte(`{"model":"claude-sonnet-4-5","max_tokens":64,"foo":"bar","messages":[{"role":"user","content":"hi"}]}`)
+	out, err := sdktranslator.TranslateRequestForProvider("claude", sdktranslator.FormatClaude, sdktranslator.FormatClaude, "claude-sonnet-4-5", claudeIn, false)
+	if err != nil {
+		t.Fatalf("passthrough action: %v", err)
+	}
+	if got := gjson.GetBytes(out, "foo").String(); got != "bar" {
+		t.Fatalf("unknown field foo = %q with passthrough configured, want preserved: %s", got, out)
+	}
+}
+
+func TestUnknownRequestFields_ConfiguredActions(t *testing.T) {
+	sdktranslator.SetUnknownFieldActions(map[string]string{"claude": "error", "my-openai-compat": "strip"})
+	t.Cleanup(func() { sdktranslator.SetUnknownFieldActions(nil) })
+
+	_, err := sdktranslator.ApplyUnknownFieldAction("claude", sdktranslator.FormatClaude, []byte(`{"model":"m","foo":1,"messages":[]}`))
+	var fieldsErr *sdktranslator.UnknownRequestFieldsError
+	if !errors.As(err, &fieldsErr) {
+		t.Fatalf("error mode: err = %v, want *UnknownRequestFieldsError", err)
+	}
+	if fieldsErr.StatusCode() != http.StatusBadRequest || len(fieldsErr.Fields) != 1 || fieldsErr.Fields[0] != "foo" {
+		t.Fatalf("error = %+v, want 400 naming foo", fieldsErr)
+	}
+
+	out, err := sdktranslator.ApplyUnknownFieldAction("my-openai-compat", sdktranslator.FormatOpenAI, []byte(`{"model":"m","foo":1,"messages":[]}`))
+	if err != nil || gjson.GetBytes(out, "foo").Exists() {
+		t.Fatalf("configured strip: out=%s err=%v", out, err)
+	}
+}